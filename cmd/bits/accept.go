@@ -0,0 +1,43 @@
+package main
+
+import (
+	"strconv"
+
+	"github.com/spf13/cobra"
+)
+
+// acceptCmd implements 'bits accept'.
+func acceptCmd() *cobra.Command {
+	var undo bool
+	cmd := &cobra.Command{
+		Use:   "accept <id> <n>...",
+		Short: "Check off acceptance criteria",
+		Long: `Check off a task's acceptance criteria by number, as listed by 'bits show'.
+Criteria are added with 'bits add --accept'. --undo unchecks them again.`,
+		Args: cobra.MinimumNArgs(2), //nolint:mnd // a task ID and at least one criterion
+		Run: func(_ *cobra.Command, args []string) {
+			store, err := getStore()
+			if err != nil {
+				printError(err)
+			}
+			lockStore(store)
+			t, err := store.Load(args[0])
+			if err != nil {
+				printError(err)
+			}
+
+			for _, arg := range args[1:] {
+				n, convErr := strconv.Atoi(arg)
+				if convErr != nil || !t.SetCriterion(n, !undo) {
+					printError(CriterionNotFoundError{ID: t.ID, Value: arg, Count: len(t.Acceptance)})
+				}
+			}
+			if err = store.Save(t); err != nil {
+				printError(err)
+			}
+			printOutput(formatter.FormatTask(t))
+		},
+	}
+	cmd.Flags().BoolVar(&undo, "undo", false, "Uncheck the criteria instead")
+	return cmd
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"maps"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/abatilo/bits/internal/plan"
+	"github.com/abatilo/bits/internal/storage"
+	"github.com/abatilo/bits/internal/task"
+)
+
+const planFormat = `A plan file lists the desired tasks by name:
+
+  tasks:
+    - name: api
+      title: Build the API
+      priority: high
+      tags: [feature]
+    - name: ui
+      title: Build the UI
+      depends_on: [api]
+    - name: spike
+      title: Evaluate frameworks
+      closed: true
+
+Names identify tasks across applies (each is stored as the task's key) and are
+what depends_on refers to; GitHub issue and pull request URLs work there too.
+Planned fields are title, description, priority, tags, and depends_on.
+
+Applying creates tasks missing from the store, updates planned fields that
+differ, and closes unfinished tasks marked closed or removed from the plan.
+Tasks without a key, such as those made with 'bits add', are never touched.`
+
+// applyCmd implements 'bits apply'.
+func applyCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "apply <plan>",
+		Short: "Converge the store on a declarative plan file",
+		Long: "Create, update, and close tasks so the store matches a plan file, all or\n" +
+			"nothing. Use 'bits plan-diff' to preview the changes.\n\n" + planFormat,
+		Args: cobra.ExactArgs(1),
+		Run: func(_ *cobra.Command, args []string) {
+			store, err := getStore()
+			if err != nil {
+				printError(err)
+			}
+			p, err := loadPlan(args[0])
+			if err != nil {
+				printError(err)
+			}
+			lockStore(store)
+
+			changes, err := applyPlan(store, p)
+			if err != nil {
+				printError(err)
+			}
+			printOutput(formatter.FormatPlan(changes))
+		},
+	}
+}
+
+// planDiffCmd implements 'bits plan-diff'.
+func planDiffCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "plan-diff <plan>",
+		Short: "Show the changes 'bits apply' would make",
+		Long:  "Show the creates, updates, and closes 'bits apply' would make for a plan file.\n\n" + planFormat,
+		Args:  cobra.ExactArgs(1),
+		Run: func(_ *cobra.Command, args []string) {
+			store, err := getStore()
+			if err != nil {
+				printError(err)
+			}
+			p, err := loadPlan(args[0])
+			if err != nil {
+				printError(err)
+			}
+			tasks, err := store.List(storage.StatusFilter{})
+			if err != nil {
+				printError(err)
+			}
+			printOutput(formatter.FormatPlan(plan.Diff(p, tasks)))
+		},
+	}
+}
+
+// loadPlan reads and validates a plan file.
+func loadPlan(path string) (*plan.Plan, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return plan.Parse(data)
+}
+
+// applyPlan stages the changes that converge the store on p and commits them
+// together, returning the changes with the IDs of created tasks filled in.
+func applyPlan(store *storage.Store, p *plan.Plan) ([]plan.Change, error) {
+	b, err := newBatch(store)
+	if err != nil {
+		return nil, err
+	}
+	changes := plan.Diff(p, slices.Collect(maps.Values(b.tasks)))
+	ids := plan.Keys(slices.Collect(maps.Values(b.tasks)))
+
+	// Create first, so dependencies between new tasks resolve to their IDs
+	for i, c := range changes {
+		if c.Kind != plan.KindCreate {
+			continue
+		}
+		t, buildErr := buildTask(store, c.Title, addOptions{})
+		if buildErr != nil {
+			return nil, buildErr
+		}
+		t.Key = c.Name
+		b.tasks[t.ID] = t
+		ids[c.Name] = t.ID
+		changes[i].ID = t.ID
+	}
+
+	now := time.Now().UTC()
+	for _, c := range changes {
+		t := b.tasks[c.ID]
+		if pt, ok := p.Find(c.Name); ok {
+			pt.Apply(t, ids)
+			if err = cfg.Limits.Check(t); err != nil {
+				return nil, err
+			}
+		}
+		if c.Kind == plan.KindClose {
+			t.Close(now, c.Reason, currentActor(store))
+			b.closed = append(b.closed, t)
+		}
+		b.touch(t)
+	}
+
+	if err = b.commit(); err != nil {
+		return nil, err
+	}
+	for _, t := range b.closed {
+		runLifecycle(task.EventClose, t)
+	}
+	return changes, nil
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/abatilo/bits/internal/assign"
+	"github.com/abatilo/bits/internal/deps"
+	"github.com/abatilo/bits/internal/task"
+)
+
+// assignCmd implements 'bits assign'.
+func assignCmd() *cobra.Command {
+	var strategy string
+	var agents []string
+	var auto bool
+	cmd := &cobra.Command{
+		Use:   "assign",
+		Short: "Distribute ready tasks across agents",
+		Long: `Queue unassigned open tasks on agents by setting their assignee, listing the
+tasks assigned. Agents come from --agents, or the agents list in the config
+file.
+
+The round-robin strategy deals the ready tasks to the agents in turn, starting
+with the agents that have the fewest unfinished tasks. A blocked task goes to
+the agent whose queue holds every task blocking it, so no agent waits on
+another agent's work; tasks blocked across queues stay unassigned until a
+later run. Tasks that already have an assignee are left alone.
+
+With --auto, the assign.rules in the config file route tasks first, matching
+on tags or title scope; only the tasks no rule matches are dealt to agents,
+and then only if any are given or configured.`,
+		Args: cobra.NoArgs,
+		Run: func(_ *cobra.Command, _ []string) {
+			if !slices.Contains(assign.Strategies(), strategy) {
+				printError(UnknownStrategyError{Value: strategy, Valid: assign.Strategies()})
+			}
+			names := agentNames(agents)
+			if len(names) == 0 && !auto {
+				printError(MissingAgentsError{})
+			}
+
+			store, err := getStore()
+			if err != nil {
+				printError(err)
+			}
+			lockStore(store)
+
+			b, err := newBatch(store)
+			if err != nil {
+				printError(err)
+			}
+			tasks := slices.Collect(maps.Values(b.tasks))
+			graph := newGraph(store, tasks)
+			deps.SortByPriority(tasks)
+			if auto {
+				for _, t := range tasks {
+					if t.Status != task.StatusOpen || t.Assignee != "" {
+						continue
+					}
+					if t.Assignee = cfg.Assign.Route(t); t.Assignee != "" {
+						b.touch(t)
+					}
+				}
+			}
+			for id, agent := range assign.RoundRobin(graph, tasks, names) {
+				t := b.tasks[id]
+				t.Assignee = agent
+				b.touch(t)
+			}
+			if err = b.commit(); err != nil {
+				printError(err)
+			}
+			graph.SortByReadiness(b.changed)
+			printOutput(formatter.FormatTaskList(b.changed))
+		},
+	}
+	cmd.Flags().StringVar(&strategy, "strategy", assign.StrategyRoundRobin,
+		"Assignment strategy ("+strings.Join(assign.Strategies(), ", ")+")")
+	cmd.Flags().StringSliceVar(&agents, "agents", nil,
+		"Comma-separated agents to assign to (default: agents from config)")
+	cmd.Flags().BoolVar(&auto, "auto", false, "Route tasks by the assign.rules in the config file first")
+	return cmd
+}
+
+// agentNames returns the agents given on the command line, or the configured
+// ones, trimmed and without duplicates.
+func agentNames(flag []string) []string {
+	if len(flag) == 0 {
+		flag = cfg.Agents
+	}
+	var names []string
+	for _, name := range flag {
+		if name = strings.TrimSpace(name); name != "" && !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+)
+
+// attachCmd implements 'bits attach'.
+func attachCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "attach <id> <file>",
+		Short: "Copy a file into a task's attachments",
+		Long: `Copy a file into the store's attachments/<id>/ directory under its base name
+and list it on the task. 'bits show' lists attachments, backups include them,
+and 'bits rm' and 'bits prune' remove them with the task. Attaching a second
+file with the same name is refused.`,
+		Args: cobra.ExactArgs(2), //nolint:mnd // CLI takes 2 positional args
+		Run: func(_ *cobra.Command, args []string) {
+			store, err := getStore()
+			if err != nil {
+				printError(err)
+			}
+			lockStore(store)
+
+			t, err := store.Load(args[0])
+			if err != nil {
+				printError(err)
+			}
+			path, err := store.Attach(t, args[1])
+			if err != nil {
+				printError(err)
+			}
+			verb := "Attached"
+			if dryRun {
+				verb = "Would attach"
+			}
+			msg := fmt.Sprintf("%s %s to %s (%s)", verb, filepath.Base(args[1]), t.ID, path)
+			printOutput(formatter.FormatMessage(msg))
+		},
+	}
+}
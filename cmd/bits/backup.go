@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/abatilo/bits/internal/storage"
+)
+
+// backupCmd implements 'bits backup' command group.
+func backupCmd() *cobra.Command {
+	var keep int
+	var dir string
+	cmd := &cobra.Command{
+		Use:   "backup",
+		Short: "Write a compressed backup of the task directory",
+		Long: `Write a compressed tar.gz backup of all task files, their attachments, and
+the archive bundles 'bits compact' wrote, keeping the newest --keep archives.
+Backups are stored beside the task directory (not inside it) unless
+--dir is given, so the command is suitable for a cron entry:
+
+  0 * * * * cd ~/src/myapp && bits backup --keep 48`,
+		Run: func(_ *cobra.Command, _ []string) {
+			store, err := getStore()
+			if err != nil {
+				printError(err)
+			}
+
+			backup, err := store.Backup(backupDirOrDefault(store, dir), keep)
+			if err != nil {
+				printError(err)
+			}
+			verb := "Backed up"
+			if dryRun {
+				verb = "Would back up"
+			}
+			printOutput(formatter.FormatMessage(fmt.Sprintf("%s %d task(s) to %s", verb, backup.Tasks, backup.Name)))
+		},
+	}
+	cmd.PersistentFlags().StringVar(&dir, "dir", "", "Backup directory (default: beside the task directory)")
+	cmd.Flags().IntVar(&keep, "keep", 0, "Number of backups to keep (0 keeps all)")
+
+	cmd.AddCommand(
+		backupListCmd(&dir),
+		backupRestoreCmd(&dir),
+	)
+
+	return cmd
+}
+
+// backupListCmd implements 'bits backup list'.
+func backupListCmd(dir *string) *cobra.Command {
+	return &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List backups (newest first)",
+		Run: func(_ *cobra.Command, _ []string) {
+			store, err := getStore()
+			if err != nil {
+				printError(err)
+			}
+
+			backups, err := storage.ListBackups(backupDirOrDefault(store, *dir))
+			if err != nil {
+				printError(err)
+			}
+			printOutput(formatter.FormatSnapshots(backups))
+		},
+	}
+}
+
+// backupRestoreCmd implements 'bits backup restore'.
+func backupRestoreCmd(dir *string) *cobra.Command {
+	return &cobra.Command{
+		Use:   "restore <name>",
+		Short: "Restore tasks from a backup",
+		Args:  cobra.ExactArgs(1),
+		Run: func(_ *cobra.Command, args []string) {
+			store, err := getStore()
+			if err != nil {
+				printError(err)
+			}
+			lockStore(store)
+
+			if err = store.RestoreBackup(backupDirOrDefault(store, *dir), args[0]); err != nil {
+				printError(err)
+			}
+			printOutput(formatter.FormatMessage(fmt.Sprintf("Restored backup %s", args[0])))
+		},
+	}
+}
+
+func backupDirOrDefault(store *storage.Store, dir string) string {
+	if dir != "" {
+		return dir
+	}
+	return store.DefaultBackupDir()
+}
@@ -0,0 +1,297 @@
+package main
+
+import (
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/abatilo/bits/internal/deps"
+	"github.com/abatilo/bits/internal/storage"
+	"github.com/abatilo/bits/internal/task"
+)
+
+// batchCmd implements 'bits batch'.
+func batchCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "batch [file]",
+		Short: "Apply a list of operations atomically",
+		Long: `Apply a list of add, dep, close, and priority operations from a YAML or JSON
+file, or stdin when no file (or "-") is given.
+
+Every operation is validated against the store as the earlier ones leave it
+before anything is written; if any fails, nothing is applied. The store is
+locked for the whole batch so other bits processes can't interleave.
+
+An add may set a ref, which later operations use in place of the new task's ID:
+
+  - op: add
+    ref: api
+    title: Build the API
+    priority: high
+  - op: add
+    ref: ui
+    title: Build the UI
+  - op: dep
+    id: ui
+    on: [api]
+  - op: priority
+    id: abc123
+    priority: low
+  - op: close
+    id: def456
+    reason: Superseded by the API work
+
+add takes the same fields as 'bits add' (description, priority, tags, due,
+verify, accept, on_claim, on_close). dep takes any, hint, and weight like
+'bits dep'. Closes run the task's acceptance and verify checks.`,
+		Args: cobra.MaximumNArgs(1),
+		Run: func(_ *cobra.Command, args []string) {
+			store, err := getStore()
+			if err != nil {
+				printError(err)
+			}
+			ops, err := readBatch(args)
+			if err != nil {
+				printError(err)
+			}
+
+			lockStore(store)
+
+			b, err := newBatch(store)
+			if err != nil {
+				printError(err)
+			}
+			for i, op := range ops {
+				if err = b.apply(op); err != nil {
+					printError(BatchOpError{Index: i + 1, Op: op.Op, Err: err})
+				}
+			}
+			if err = b.commit(); err != nil {
+				printError(err)
+			}
+			for _, t := range b.closed {
+				runLifecycle(task.EventClose, t)
+			}
+			printOutput(formatter.FormatTaskList(b.changed))
+		},
+	}
+}
+
+// batchOp is one operation in a 'bits batch' file.
+type batchOp struct {
+	Op     string   `yaml:"op"`     // add, dep, close, or priority
+	Ref    string   `yaml:"ref"`    // add: name for the new task in later operations
+	Title  string   `yaml:"title"`  // add
+	ID     string   `yaml:"id"`     // dep, close, priority: the task (or ref) to change
+	On     []string `yaml:"on"`     // dep: tasks (or refs) to depend on
+	Any    bool     `yaml:"any"`    // dep
+	Hint   bool     `yaml:"hint"`   // dep
+	Weight int      `yaml:"weight"` // dep
+	Type   string   `yaml:"type"`   // dep: link type, as with bits dep --type
+	Reason string   `yaml:"reason"` // close
+
+	addOptions `yaml:",inline"` // add; priority also sets the new priority
+}
+
+// readBatch decodes the operations in the named file, or stdin. YAML is a
+// superset of JSON, so one decoder reads both.
+func readBatch(args []string) ([]batchOp, error) {
+	var data []byte
+	var err error
+	if len(args) == 0 || args[0] == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(args[0])
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var ops []batchOp
+	if err = yaml.Unmarshal(data, &ops); err != nil {
+		return nil, err
+	}
+	return ops, nil
+}
+
+// batch stages operations against an in-memory copy of the store.
+type batch struct {
+	store   *storage.Store
+	tasks   map[string]*task.Task
+	refs    map[string]string // ref -> ID of a task added by the batch
+	changed []*task.Task      // In first-touched order
+	closed  []*task.Task
+}
+
+func newBatch(store *storage.Store) (*batch, error) {
+	tasks, err := store.List(storage.StatusFilter{})
+	if err != nil {
+		return nil, err
+	}
+	b := &batch{store: store, tasks: make(map[string]*task.Task, len(tasks)), refs: make(map[string]string)}
+	for _, t := range tasks {
+		b.tasks[t.ID] = t
+	}
+	return b, nil
+}
+
+// resolve returns the task ID a ref stands for, or id itself.
+func (b *batch) resolve(id string) string {
+	if resolved, ok := b.refs[id]; ok {
+		return resolved
+	}
+	return id
+}
+
+// task returns the staged task for an ID or ref.
+func (b *batch) task(id string) (*task.Task, error) {
+	t, ok := b.tasks[b.resolve(id)]
+	if !ok {
+		return nil, storage.TaskNotFoundError{ID: id}
+	}
+	return t, nil
+}
+
+// touch records that t must be written on commit.
+func (b *batch) touch(t *task.Task) {
+	if !slices.Contains(b.changed, t) {
+		b.changed = append(b.changed, t)
+	}
+}
+
+// depend validates and stages dependencies, hints, or links from t to ids.
+func (b *batch) depend(t *task.Task, ids []string, opts depOptions) error {
+	graph := deps.NewGraph(slices.Collect(maps.Values(b.tasks)))
+	if err := validateDeps(graph, t.ID, ids, opts); err != nil {
+		return err
+	}
+	if !applyDeps(t, ids, opts) {
+		return nil
+	}
+	b.touch(t)
+	return cfg.Limits.Check(t)
+}
+
+// apply validates op and stages its effect.
+func (b *batch) apply(op batchOp) error {
+	switch op.Op {
+	case "add":
+		return b.add(op)
+	case "dep":
+		t, err := b.task(op.ID)
+		if err != nil {
+			return err
+		}
+		ids := normalizeDeps(op.On)
+		for i, id := range ids {
+			ids[i] = b.resolve(id)
+		}
+		lt := task.LinkType(op.Type)
+		if lt == "" {
+			lt = task.LinkDependsOn
+		}
+		if err = checkLinkType(lt, op.Any || op.Hint, false); err != nil {
+			return err
+		}
+		opts := depOptions{AnyOf: op.Any, Hint: op.Hint, Weight: op.Weight}
+		if !lt.Blocking() {
+			opts.Link = lt
+		}
+		if lt != task.LinkBlocks {
+			return b.depend(t, ids, opts)
+		}
+		for _, id := range ids {
+			blocked, taskErr := b.task(id)
+			if taskErr != nil {
+				return taskErr
+			}
+			if err = b.depend(blocked, []string{t.ID}, opts); err != nil {
+				return err
+			}
+		}
+		return nil
+	case "close":
+		t, err := b.task(op.ID)
+		if err != nil {
+			return err
+		}
+		note, err := checkClose(t, op.Reason, false, false)
+		if err != nil {
+			return err
+		}
+		t.Close(time.Now().UTC(), op.Reason, currentActor(b.store))
+		t.Annotate(note)
+		b.touch(t)
+		b.closed = append(b.closed, t)
+		return nil
+	case "priority":
+		t, err := b.task(op.ID)
+		if err != nil {
+			return err
+		}
+		p := task.Priority(op.Priority)
+		if !task.IsValidPriority(p) {
+			return InvalidPriorityError{Value: op.Priority}
+		}
+		if t.Priority != p {
+			t.Priority = p
+			b.touch(t)
+		}
+		return nil
+	default:
+		return UnknownBatchOpError{Op: op.Op}
+	}
+}
+
+// add stages a new task, registering its ref.
+func (b *batch) add(op batchOp) error {
+	if op.Title == "" {
+		return MissingTitleError{}
+	}
+	if op.Ref != "" {
+		if _, ok := b.tasks[op.Ref]; ok {
+			return DuplicateRefError{Ref: op.Ref}
+		}
+		if _, ok := b.refs[op.Ref]; ok {
+			return DuplicateRefError{Ref: op.Ref}
+		}
+	}
+
+	t, err := buildTask(b.store, op.Title, op.addOptions)
+	if err != nil {
+		return err
+	}
+	b.tasks[t.ID] = t
+	if op.Ref != "" {
+		b.refs[op.Ref] = t.ID
+	}
+	b.touch(t)
+	return nil
+}
+
+// commit writes every staged task. A snapshot is taken first and restored if
+// a write fails, so the store is left as it was. Webhooks for the tasks it
+// created are sent once all are written.
+func (b *batch) commit() error {
+	if len(b.changed) == 0 {
+		return nil
+	}
+	var created []*task.Task
+	for _, t := range b.changed {
+		if !b.store.Exists(t.ID) {
+			created = append(created, t)
+		}
+	}
+	if err := b.store.SaveAll("batch", b.changed); err != nil {
+		return err
+	}
+	for _, t := range created {
+		sendWebhooks(webhookCreate, t)
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/abatilo/bits/internal/storage"
+)
+
+// blockedCmd implements 'bits blocked'.
+func blockedCmd() *cobra.Command {
+	var checkExternal bool
+	cmd := &cobra.Command{
+		Use:   "blocked",
+		Short: "List blocked tasks and what blocks them",
+		Long: `List the open tasks that aren't ready, most urgent first, each with the
+unclosed dependencies holding it back and their status, so one command
+explains what 'bits ready' leaves out. For an any-of group with no closed
+member every member is listed. --check-external refreshes GitHub issue and
+pull request dependencies first, as for 'bits ready'.`,
+		Args: cobra.NoArgs,
+		Run: func(_ *cobra.Command, _ []string) {
+			store, err := getStore()
+			if err != nil {
+				printError(err)
+			}
+
+			tasks, err := store.List(storage.StatusFilter{Open: true, Active: true})
+			if err != nil {
+				printError(err)
+			}
+			if checkExternal {
+				if err = refreshExternal(store, tasks); err != nil {
+					printError(err)
+				}
+			}
+			printOutput(formatter.FormatBlocked(newGraph(store, tasks).Blocked()))
+		},
+	}
+	cmd.Flags().BoolVar(&checkExternal, "check-external", false,
+		"Refresh the state of GitHub issue and PR dependencies")
+	return cmd
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/abatilo/bits/internal/git"
+	"github.com/abatilo/bits/internal/task"
+)
+
+const branchPrefix = "bits/"
+
+// branchCmd implements 'bits branch'.
+func branchCmd() *cobra.Command {
+	var claim bool
+	cmd := &cobra.Command{
+		Use:   "branch <id>",
+		Short: "Create and check out a git branch for a task",
+		Long: `Check out a branch named bits/<id>-<slugified-title>, creating it from HEAD if
+needed, and record the branch name on the task. A task that already has a
+branch reuses it.
+
+With --claim, the task is claimed first; if it can't be claimed, no branch
+is created.`,
+		Args: cobra.ExactArgs(1),
+		Run: func(_ *cobra.Command, args []string) {
+			store, err := getStore()
+			if err != nil {
+				printError(err)
+			}
+			lockStore(store)
+
+			t, err := store.Load(args[0])
+			if err != nil {
+				printError(err)
+			}
+
+			if claim {
+				if err = claimTask(store, t, false); err != nil {
+					printError(err)
+				}
+			}
+
+			if t.Branch == "" {
+				t.Branch = branchName(t)
+			}
+			if _, err = git.CheckoutBranch("", t.Branch); err != nil {
+				printError(err)
+			}
+			if err = store.Save(t); err != nil {
+				printError(err)
+			}
+			printOutput(formatter.FormatTask(t))
+		},
+	}
+	cmd.Flags().BoolVar(&claim, "claim", false, "Claim the task as well")
+	return cmd
+}
+
+// branchName returns the default branch name for a task.
+func branchName(t *task.Task) string {
+	if slug := task.Slug(t.Title); slug != "" {
+		return branchPrefix + t.ID + "-" + slug
+	}
+	return branchPrefix + t.ID
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/abatilo/bits/internal/storage"
+	"github.com/abatilo/bits/internal/task"
+)
+
+// selector picks the tasks a bulk command acts on: the IDs given, or with
+// --all-matching every task in the command's statuses whose title,
+// description, or close reason contains the query, ignoring case.
+type selector struct {
+	matching string
+}
+
+// addFlag registers --all-matching, naming the tasks it selects ("open task").
+func (s *selector) addFlag(cmd *cobra.Command, which string) {
+	cmd.Flags().StringVar(&s.matching, "all-matching", "", "Act on every "+which+" whose text contains this")
+}
+
+// args returns a positional argument validator for a command taking fixed
+// arguments after its task IDs: with --all-matching exactly fixed, since the
+// query replaces the IDs, and otherwise at least one ID more.
+func (s *selector) args(fixed int) cobra.PositionalArgs {
+	return func(cmd *cobra.Command, args []string) error {
+		if s.matching != "" {
+			return cobra.ExactArgs(fixed)(cmd, args)
+		}
+		return cobra.MinimumNArgs(fixed+1)(cmd, args)
+	}
+}
+
+// bulk reports whether the command acts on more than one task, or on a query.
+func (s *selector) bulk(ids []string) bool {
+	return s.matching != "" || len(ids) > 1
+}
+
+// pick returns the staged tasks to act on: those named by ids in order, or
+// with --all-matching those in statuses matching the query, most urgent first.
+func (s *selector) pick(b *batch, ids []string, statuses storage.StatusFilter) ([]*task.Task, error) {
+	if s.matching == "" {
+		return storage.SelectTasks(b.tasks, ids, statuses, nil)
+	}
+	match, err := storage.TextMatcher(s.matching, false, false)
+	if err != nil {
+		return nil, err
+	}
+	return storage.SelectTasks(b.tasks, nil, statuses, func(t *task.Task) bool { return storage.MatchesText(t, match) })
+}
+
+// bulkClose closes the selected active tasks together, all or nothing.
+func bulkClose(store *storage.Store, sel *selector, ids []string, reason string, verify, noVerify bool) (
+	[]*task.Task, error,
+) {
+	b, err := newBatch(store)
+	if err != nil {
+		return nil, err
+	}
+	targets, err := sel.pick(b, ids, storage.StatusFilter{Active: true})
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	for _, t := range targets {
+		note, checkErr := checkClose(t, reason, verify, noVerify)
+		if checkErr != nil {
+			return nil, checkErr
+		}
+		t.Close(now, reason, currentActor(store))
+		t.Annotate(note)
+		b.touch(t)
+		b.closed = append(b.closed, t)
+	}
+	// Subtasks closed alongside their parent don't count as unfinished
+	for _, t := range b.closed {
+		children, childErr := store.Children(t.ID, storage.StatusFilter{})
+		if childErr != nil {
+			return nil, childErr
+		}
+		unfinished := slices.DeleteFunc(children, func(id string) bool {
+			return b.tasks[id] == nil || b.tasks[id].Status == task.StatusClosed
+		})
+		if len(unfinished) > 0 {
+			printWarning(OpenChildrenError{ID: t.ID, Children: unfinished})
+		}
+	}
+
+	if err = b.commit(); err != nil {
+		return nil, err
+	}
+	for _, t := range b.closed {
+		runLifecycle(task.EventClose, t)
+	}
+	return b.changed, nil
+}
+
+// bulkClaim claims the selected open tasks together, all or nothing. Each
+// claim counts toward the WIP limits of the ones after it.
+func bulkClaim(store *storage.Store, sel *selector, ids []string, force bool) ([]*task.Task, error) {
+	b, err := newBatch(store)
+	if err != nil {
+		return nil, err
+	}
+	targets, err := sel.pick(b, ids, storage.StatusFilter{Open: true})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, t := range targets {
+		live := slices.DeleteFunc(slices.Collect(maps.Values(b.tasks)), func(lt *task.Task) bool {
+			return lt.Status == task.StatusClosed
+		})
+		if err = stageClaim(store, t, live, force); err != nil {
+			return nil, err
+		}
+		b.touch(t)
+	}
+
+	if err = b.commit(); err != nil {
+		return nil, err
+	}
+	for _, t := range b.changed {
+		runLifecycle(task.EventClaim, t)
+	}
+	return b.changed, nil
+}
+
+// bulkDepend makes each selected unfinished task depend on depIDs, all or
+// nothing, returning the tasks that changed.
+func bulkDepend(store *storage.Store, sel *selector, ids, depIDs []string, opts depOptions) ([]*task.Task, error) {
+	b, err := newBatch(store)
+	if err != nil {
+		return nil, err
+	}
+	targets, err := sel.pick(b, ids, storage.StatusFilter{Open: true, Active: true})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, t := range targets {
+		op := batchOp{Op: "dep", ID: t.ID, On: depIDs, Any: opts.AnyOf, Hint: opts.Hint, Weight: opts.Weight}
+		op.Type = string(opts.Link)
+		if err = b.apply(op); err != nil {
+			return nil, err
+		}
+	}
+	if err = b.commit(); err != nil {
+		return nil, err
+	}
+	return b.changed, nil
+}
+
+// bulkRemove removes the selected tasks and their references, all or nothing:
+// a snapshot is taken first and restored if a removal fails.
+func bulkRemove(store *storage.Store, sel *selector, ids []string) ([]string, error) {
+	b, err := newBatch(store)
+	if err != nil {
+		return nil, err
+	}
+	targets, err := sel.pick(b, ids, storage.StatusFilter{})
+	if err != nil || len(targets) == 0 {
+		return nil, err
+	}
+
+	removed := make([]string, len(targets))
+	for i, t := range targets {
+		removed[i] = t.ID
+	}
+	if err = store.RemoveAll(removed); err != nil {
+		return nil, err
+	}
+	return removed, nil
+}
+
+// removedMessage summarizes a bulk removal.
+func removedMessage(ids []string) string {
+	if len(ids) == 0 {
+		return "No tasks matched"
+	}
+	verb := "Removed"
+	if dryRun {
+		verb = "Would remove"
+	}
+	return fmt.Sprintf("%s %d task(s): %s", verb, len(ids), strings.Join(ids, ", "))
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/abatilo/bits/internal/report"
+)
+
+// compactCmd implements 'bits compact'.
+func compactCmd() *cobra.Command {
+	var olderThan string
+	cmd := &cobra.Command{
+		Use:   "compact",
+		Short: "Roll old closed tasks into monthly archive bundles",
+		Long: `Move tasks closed before --older-than (an age such as 90d, or a date) out of
+the store into one archive file per month of closing, archive/YYYY-MM.md,
+keeping directory scans and file counts bounded over years of use.
+
+Archived tasks no longer appear in list, show, or reports, but 'bits search
+--archived' still finds them, and their IDs are never reused. A snapshot is
+taken first.`,
+		Args: cobra.NoArgs,
+		Run: func(_ *cobra.Command, _ []string) {
+			store, err := getStore()
+			if err != nil {
+				printError(err)
+			}
+			cutoff, err := report.ParseSince(olderThan, time.Now().UTC())
+			if err != nil {
+				printError(err)
+			}
+			lockStore(store)
+
+			if _, err = store.Snapshot("compact"); err != nil {
+				printError(err)
+			}
+			archived, err := store.Compact(cutoff)
+			if err != nil {
+				printError(err)
+			}
+			verb := "Archived"
+			if dryRun {
+				verb = "Would archive"
+			}
+			printOutput(formatter.FormatMessage(fmt.Sprintf("%s %d closed task(s)", verb, len(archived))))
+		},
+	}
+	cmd.Flags().StringVar(&olderThan, "older-than", "90d", "Archive tasks closed before this age (e.g. 30d) or date")
+	return cmd
+}
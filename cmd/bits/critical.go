@@ -0,0 +1,46 @@
+package main
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/abatilo/bits/internal/storage"
+)
+
+// criticalCmd implements 'bits critical'.
+func criticalCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "critical [id]",
+		Short: "List the longest chain of unfinished dependencies",
+		Long: `List the critical path: the longest chain of unclosed tasks that have to be
+done one after another, in the order to do them. However much else is worked
+on in parallel, the project can't finish sooner than this chain. Given a task,
+list the longest chain that ends with it instead.
+
+An any-of group counts its shortest chain, or none once a member is closed.
+Chains of equal length are ranked by total estimate, then by priority.`,
+		Args: cobra.MaximumNArgs(1),
+		Run: func(_ *cobra.Command, args []string) {
+			store, err := getStore()
+			if err != nil {
+				printError(err)
+			}
+
+			tasks, err := store.List(storage.StatusFilter{Open: true, Active: true})
+			if err != nil {
+				printError(err)
+			}
+			graph := newGraph(store, tasks)
+			goal := ""
+			if len(args) == 1 {
+				goal = args[0]
+				if graph.Get(goal) == nil {
+					// A closed task has nothing left gating it
+					if _, err = store.Load(goal); err != nil {
+						printError(err)
+					}
+				}
+			}
+			printOutput(formatter.FormatTaskList(graph.CriticalPath(goal)))
+		},
+	}
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/abatilo/bits/internal/output"
+	"github.com/abatilo/bits/internal/rpc"
+	"github.com/abatilo/bits/internal/storage"
+	"github.com/abatilo/bits/internal/task"
+)
+
+const (
+	daemonSocketName  = "daemon.sock"
+	noDaemonEnv       = "BITS_NO_DAEMON"
+	daemonDialTimeout = 200 * time.Millisecond
+)
+
+// daemonSocket returns the path of the store's daemon socket.
+func daemonSocket(store *storage.Store) string {
+	return filepath.Join(store.BasePath(), daemonSocketName)
+}
+
+// daemonCmd implements 'bits daemon'.
+func daemonCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "daemon",
+		Short: "Serve the task store over a unix socket",
+		Long: `Serve the current project's task store over a unix socket in the store
+directory, speaking the same JSON-RPC methods as 'bits rpc'. Requests from all
+clients are handled one at a time.
+
+While the daemon runs, show, list, ready, add, claim, release, and close send
+their work to it instead of reading the task files themselves, which keeps
+frequent calls from hooks cheap. Other commands, or any command with
+` + noDaemonEnv + `=1 set, use the files directly. Warnings and script output
+appear on the daemon's stderr. Stop the daemon with Ctrl-C or SIGTERM.
+
+'bits events' streams the changes the daemon makes as they happen.`,
+		Args: cobra.NoArgs,
+		Run: func(_ *cobra.Command, _ []string) {
+			store, err := getStore()
+			if err != nil {
+				printError(err)
+			}
+			if err = store.EnsureInitialized(); err != nil {
+				printError(err)
+			}
+
+			socket := daemonSocket(store)
+			if client, dialErr := rpc.Dial(socket, daemonDialTimeout); dialErr == nil {
+				_ = client.Close()
+				printError(DaemonRunningError{Socket: socket})
+			}
+			_ = os.Remove(socket) // Left behind by a daemon that didn't shut down cleanly
+
+			listener, err := net.Listen("unix", socket)
+			if err != nil {
+				printError(err)
+			}
+			if err = os.Chmod(socket, 0o600); err != nil {
+				printError(err)
+			}
+
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			go func() {
+				<-ctx.Done()
+				_ = listener.Close()
+			}()
+
+			printOutput(formatter.FormatMessage("Serving " + store.BasePath() + " on " + socket))
+			finishTelemetry(nil) // Each request is traced on its own from here on
+			if err = newRPCServer(store).ServeListener(listener); err != nil {
+				printError(err)
+			}
+		},
+	}
+}
+
+// eventsCmd implements 'bits events'.
+func eventsCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "events",
+		Short: "Stream task events from the daemon",
+		Long: `Subscribe to the running daemon and print each task event as one JSON object
+per line, as it happens: {"type", "at", "task"}, where type is create, claim,
+release, close, or dep and task has the same shape as --json.
+
+Only changes made through the daemon are seen. Runs until interrupted or the
+daemon stops.`,
+		Args: cobra.NoArgs,
+		Run: func(_ *cobra.Command, _ []string) {
+			store, err := getStore()
+			if err != nil {
+				printError(err)
+			}
+			socket := daemonSocket(store)
+			client, err := rpc.Dial(socket, daemonDialTimeout)
+			if err != nil {
+				printError(DaemonNotRunningError{Socket: socket})
+			}
+			defer client.Close()
+
+			if err = client.Call(rpc.SubscribeMethod, nil, nil); err != nil {
+				printError(err)
+			}
+			for {
+				n, recvErr := client.Receive()
+				if errors.Is(recvErr, io.EOF) {
+					return
+				}
+				if recvErr != nil {
+					printError(recvErr)
+				}
+				if n.Method == eventMethod {
+					printOutput(string(n.Params) + "\n")
+				}
+			}
+		},
+	}
+}
+
+// daemonCall runs method on the store's daemon, decoding its result into
+// result. It returns false if no daemon is running, or for --dry-run (the daemon
+// always writes), so the caller should use the store directly. An error from
+// the daemon is fatal.
+func daemonCall(store *storage.Store, method string, params, result any) bool {
+	if dryRun || os.Getenv(noDaemonEnv) != "" {
+		return false
+	}
+	client, err := rpc.Dial(daemonSocket(store), daemonDialTimeout)
+	if err != nil {
+		return false
+	}
+	defer client.Close()
+
+	if err = client.Call(method, params, result); err != nil {
+		printError(err)
+	}
+	return true
+}
+
+// daemonTask is daemonCall for methods that return a task.
+func daemonTask(store *storage.Store, method string, params any) (*task.Task, bool) {
+	var raw json.RawMessage
+	if !daemonCall(store, method, params, &raw) {
+		return nil, false
+	}
+	t, err := output.ParseTask(raw)
+	if err != nil {
+		printError(err)
+	}
+	return t, true
+}
+
+// daemonTasks is daemonCall for methods that return a task list.
+func daemonTasks(store *storage.Store, method string, params any) ([]*task.Task, bool) {
+	var raw json.RawMessage
+	if !daemonCall(store, method, params, &raw) {
+		return nil, false
+	}
+	tasks, err := output.ParseTaskList(raw)
+	if err != nil {
+		printError(err)
+	}
+	return tasks, true
+}
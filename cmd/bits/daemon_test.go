@@ -0,0 +1,61 @@
+//nolint:testpackage // Tests require internal access for thorough testing
+package main
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/abatilo/bits/internal/rpc"
+	"github.com/abatilo/bits/internal/storage"
+	"github.com/abatilo/bits/internal/task"
+)
+
+func TestDaemonSeesDirectWrites(t *testing.T) {
+	store := newTestStore(t)
+	socket := daemonSocket(store)
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+	defer listener.Close()
+	go func() { _ = newRPCServer(store).ServeListener(listener) }()
+
+	client, err := rpc.Dial(socket, time.Second)
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	defer client.Close()
+
+	var a, c struct {
+		ID string `json:"id"`
+	}
+	if err = client.Call("create", createParams{Title: "A"}, &a); err != nil {
+		t.Fatalf("create A failed: %v", err)
+	}
+
+	// A command run with BITS_NO_DAEMON=1 writes the files itself.
+	direct := storage.NewStoreWithPath(store.BasePath())
+	b, err := direct.CreateTask("B", "", task.PriorityMedium)
+	if err != nil {
+		t.Fatalf("CreateTask failed: %v", err)
+	}
+	b.DependsOn = []string{a.ID}
+	if err = direct.Save(b); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	if err = client.Call("create", createParams{Title: "C"}, &c); err != nil {
+		t.Fatalf("create C failed: %v", err)
+	}
+	if c.ID == b.ID {
+		t.Errorf("daemon gave C the ID %s already taken by B", b.ID)
+	}
+	issues, err := storage.NewStoreWithPath(store.BasePath()).Verify()
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if len(issues) != 0 {
+		t.Errorf("Verify = %+v, want the daemon to keep the direct write indexed", issues)
+	}
+}
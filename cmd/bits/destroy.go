@@ -0,0 +1,80 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/abatilo/bits/internal/storage"
+)
+
+// destroyCmd implements 'bits destroy'.
+func destroyCmd() *cobra.Command {
+	var yes bool
+	cmd := &cobra.Command{
+		Use:   "destroy",
+		Short: "Delete the project's task directory",
+		Long: `Delete the project's task directory, snapshots included, after writing a
+final backup beside it (see 'bits backup'). Restore it with 'bits backup
+restore' if the deletion was a mistake.
+
+The deletion must be confirmed at the prompt, or with --yes when there is no
+one to answer it.`,
+		Run: func(_ *cobra.Command, _ []string) {
+			store, err := getStore()
+			if err != nil {
+				printError(err)
+			}
+			if !store.IsInitialized() {
+				printError(storage.NotInitializedError{Path: store.BasePath()})
+			}
+			lockStore(store)
+
+			ids, err := store.AllIDs()
+			if err != nil {
+				printError(err)
+			}
+			if !yes && !dryRun && !confirm(fmt.Sprintf("Delete %s and its %d task(s)?", store.BasePath(), len(ids))) {
+				printError(NotConfirmedError{Action: "destroy"})
+			}
+
+			dir := store.DefaultBackupDir()
+			if dryRun {
+				printDryRun("back up %d task(s) to %s", len(ids), dir)
+			} else {
+				backup, backupErr := store.Backup(dir, 0)
+				if backupErr != nil {
+					printError(backupErr)
+				}
+				dir = filepath.Join(dir, backup.Name)
+			}
+			if err = store.Destroy(); err != nil {
+				printError(err)
+			}
+			if dryRun {
+				printOutput(formatter.FormatMessage("Would destroy " + store.BasePath()))
+				return
+			}
+			printOutput(formatter.FormatMessage(fmt.Sprintf("Destroyed %s; final backup: %s", store.BasePath(), dir)))
+		},
+	}
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Skip the confirmation prompt")
+	return cmd
+}
+
+// confirm asks a yes/no question on stderr and reads the answer from stdin.
+// Anything but y or yes, including no answer at all, declines.
+func confirm(question string) bool {
+	fmt.Fprintf(os.Stderr, "%s [y/N] ", question)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"os"
+	"sort"
+
+	"github.com/spf13/cobra"
+
+	"github.com/abatilo/bits/internal/storage"
+)
+
+// doctorCmd implements 'bits doctor'.
+func doctorCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "doctor",
+		Short: "Check the store for problems and tasks over the configured limits",
+		Long: `Run the checks of 'bits verify', and warn about tasks whose title,
+description, or dependency count exceeds the limits in the config file, such
+as ones written before a limit was lowered or edited by hand.
+
+Exits non-zero if verify finds an issue; limit warnings alone don't fail.`,
+		Args: cobra.NoArgs,
+		Run: func(_ *cobra.Command, _ []string) {
+			store, err := getStore()
+			if err != nil {
+				printError(err)
+			}
+
+			issues, err := store.Verify()
+			if err != nil {
+				printError(err)
+			}
+			failed := len(issues) > 0
+
+			tasks, err := store.List(storage.StatusFilter{})
+			if err != nil {
+				printError(err)
+			}
+			for _, t := range tasks {
+				for _, v := range cfg.Limits.Violations(t) {
+					path, _ := store.TaskPath(t.ID)
+					issues = append(issues, storage.Issue{
+						ID: t.ID, File: path, Kind: storage.IssueOversize, Detail: v.Reason(),
+					})
+				}
+			}
+			sort.SliceStable(issues, func(i, j int) bool {
+				return issues[i].ID < issues[j].ID
+			})
+
+			printOutput(formatter.FormatIssues(issues))
+			if failed {
+				os.Exit(1)
+			}
+		},
+	}
+}
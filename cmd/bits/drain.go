@@ -9,6 +9,7 @@ import (
 
 	"github.com/abatilo/bits/internal/session"
 	"github.com/abatilo/bits/internal/storage"
+	"github.com/abatilo/bits/internal/task"
 )
 
 // drainCmd implements 'bits drain' command group.
@@ -97,16 +98,14 @@ func drainReleaseCmd() *cobra.Command {
 			}
 
 			// Check for remaining tasks before allowing release
-			activeTasks, err := store.List(storage.StatusFilter{Active: true})
-			if err != nil {
-				printError(err)
-			}
-			openTasks, err := store.List(storage.StatusFilter{Open: true})
+			remaining, err := store.List(storage.StatusFilter{Open: true, Active: true})
 			if err != nil {
 				printError(err)
 			}
+			activeCount := task.CountByStatus(remaining, task.StatusActive)
+			openCount := task.CountByStatus(remaining, task.StatusOpen)
 
-			if len(activeTasks) > 0 || len(openTasks) > 0 {
+			if activeCount > 0 || openCount > 0 {
 				msg := fmt.Sprintf(
 					`Claude, you attempted to release drain mode but there are still %d active and %d open tasks remaining.
 
@@ -118,8 +117,8 @@ Please:
 3. Continue working until all tasks are complete
 
 Drain mode should only be released when ALL tasks are finished, not when you want to pause or ask the user a question.`,
-					len(activeTasks),
-					len(openTasks),
+					activeCount,
+					openCount,
 				)
 
 				resp := drainResponse{
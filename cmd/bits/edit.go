@@ -0,0 +1,195 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"runtime"
+
+	"github.com/spf13/cobra"
+
+	"github.com/abatilo/bits/internal/script"
+	"github.com/abatilo/bits/internal/storage"
+	"github.com/abatilo/bits/internal/task"
+)
+
+// defaultEditor is run when $EDITOR is unset, and windowsEditor in its place
+// on Windows.
+const (
+	defaultEditor = "vi"
+	windowsEditor = "notepad"
+)
+
+// editCmd implements 'bits edit'.
+func editCmd() *cobra.Command {
+	var title, description, priority string
+	cmd := &cobra.Command{
+		Use:   "edit <id>",
+		Short: "Change a task's title, description, or priority",
+		Long: `Change a task's title, description, or priority in place. Without flags,
+open the task's markdown in $EDITOR (default vi, or notepad on Windows) and
+save it when the editor exits. The edit is checked like a new task would be;
+its ID and status can't change (use claim, release, and close for that). The
+store isn't locked while the editor is open, so if the task changes meanwhile
+the edit is rejected rather than overwrite the change. A rejected edit is kept
+in a temporary file so it isn't lost.`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			store, err := getStore()
+			if err != nil {
+				printError(err)
+			}
+
+			flags := cmd.Flags()
+			if !flags.Changed("title") && !flags.Changed("description") && !flags.Changed("priority") {
+				t, editErr := editInEditor(store, args[0])
+				if editErr != nil {
+					printError(editErr)
+				}
+				if t == nil {
+					printOutput(formatter.FormatMessage("No changes."))
+					return
+				}
+				printOutput(formatter.FormatTask(t))
+				return
+			}
+
+			lockStore(store)
+			t, err := store.Load(args[0])
+			if err == nil {
+				if flags.Changed("title") {
+					t.Title = title
+				}
+				if flags.Changed("description") {
+					t.Description = description
+				}
+				if flags.Changed("priority") {
+					t.Priority = task.Priority(priority)
+				}
+				err = validateEdit(t)
+			}
+			if err != nil {
+				printError(err)
+			}
+			if err = store.Update(args[0], t); err != nil {
+				printError(err)
+			}
+			printOutput(formatter.FormatTask(t))
+		},
+	}
+	cmd.Flags().StringVar(&title, "title", "", "New title")
+	cmd.Flags().StringVarP(&description, "description", "d", "", "New description")
+	cmd.Flags().StringVarP(&priority, "priority", "p", "", "New priority: critical, high, medium, or low")
+	return cmd
+}
+
+// validateEdit checks an edited task's title, priority, and size limits.
+func validateEdit(t *task.Task) error {
+	if t.Title == "" {
+		return MissingTitleError{}
+	}
+	if !task.IsValidPriority(t.Priority) {
+		return InvalidPriorityError{Value: string(t.Priority)}
+	}
+	return cfg.Limits.Check(t)
+}
+
+// editInEditor opens the markdown of task id in the user's editor and saves
+// the edit, returning the edited task, or nil if the file was saved unchanged.
+// The store is locked only once the editor exits; the edit is rejected if the
+// task changed while it was open. A rejected edit is left in its temporary
+// file, which the error names.
+func editInEditor(store *storage.Store, id string) (*task.Task, error) {
+	t, err := store.Load(id)
+	if err != nil {
+		return nil, err
+	}
+	content, err := storage.SerializeMarkdown(t)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.CreateTemp("", "bits-"+t.ID+"-*.md")
+	if err != nil {
+		return nil, err
+	}
+	path := f.Name()
+	_, err = f.Write(content)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(path)
+		return nil, err
+	}
+
+	if err = runEditor(path); err != nil {
+		return nil, InvalidEditError{Path: path, Reason: err.Error()}
+	}
+	edited, err := os.ReadFile(path) //nolint:gosec // G304: the temporary file made above
+	if err != nil {
+		return nil, err
+	}
+	if bytes.Equal(edited, content) {
+		_ = os.Remove(path)
+		return nil, nil //nolint:nilnil // A nil task means nothing changed
+	}
+
+	et, err := storage.ParseMarkdown(edited)
+	if err == nil {
+		err = checkEdit(t, et)
+	}
+	if err == nil {
+		lockStore(store)
+		err = checkUnchanged(store, t.ID, content)
+	}
+	if err == nil {
+		err = store.Update(t.ID, et)
+	}
+	if err != nil {
+		return nil, InvalidEditError{Path: path, Reason: err.Error()}
+	}
+	_ = os.Remove(path)
+	return et, nil
+}
+
+// checkUnchanged reports an EditConflictError if task id no longer has the
+// content an edit started from.
+func checkUnchanged(store *storage.Store, id string, content []byte) error {
+	current, err := store.Load(id)
+	if err != nil {
+		return err
+	}
+	now, err := storage.SerializeMarkdown(current)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(now, content) {
+		return EditConflictError{ID: id}
+	}
+	return nil
+}
+
+// checkEdit validates an edited task against the original.
+func checkEdit(orig, edited *task.Task) error {
+	if err := storage.CheckEdit(orig, edited); err != nil {
+		return err
+	}
+	return validateEdit(edited)
+}
+
+// runEditor opens path in $EDITOR, which may include arguments and is run
+// through the shell, attached to the terminal.
+func runEditor(path string) error {
+	editor := os.Getenv("EDITOR")
+	if editor == "" {
+		editor = defaultEditor
+		if runtime.GOOS == "windows" {
+			editor = windowsEditor
+		}
+	}
+	cmd := script.Command(context.Background(), editor, path)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stderr // Keep stdout for the command's output
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
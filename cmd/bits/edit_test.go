@@ -0,0 +1,74 @@
+//nolint:testpackage // Tests require internal access for thorough testing
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/abatilo/bits/internal/task"
+)
+
+func TestEditInEditor(t *testing.T) {
+	store := newTestStore(t)
+	t.Cleanup(unlockStore)
+	tk, err := store.CreateTask("Old title", "", task.PriorityMedium)
+	if err != nil {
+		t.Fatalf("CreateTask failed: %v", err)
+	}
+	t.Setenv("EDITOR", "sed -i s/Old/New/")
+
+	edited, err := editInEditor(store, tk.ID)
+	if err != nil {
+		t.Fatalf("editInEditor failed: %v", err)
+	}
+	if edited.Title != "New title" {
+		t.Errorf("edited title = %q, want %q", edited.Title, "New title")
+	}
+	loaded, err := store.Load(tk.ID)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if loaded.Title != "New title" {
+		t.Errorf("saved title = %q, want %q", loaded.Title, "New title")
+	}
+}
+
+func TestEditInEditorRejectsConflict(t *testing.T) {
+	store := newTestStore(t)
+	t.Cleanup(unlockStore)
+	tk, err := store.CreateTask("Old title", "", task.PriorityMedium)
+	if err != nil {
+		t.Fatalf("CreateTask failed: %v", err)
+	}
+	// The "editor" also changes the task in the store, as another writer
+	// could while the real editor is open.
+	taskFile := filepath.Join(store.BasePath(), tk.ID+".md")
+	t.Setenv("EDITOR", "sed -i s/Old/Other/ "+taskFile+" && sed -i s/Old/New/")
+
+	_, err = editInEditor(store, tk.ID)
+	var editErr InvalidEditError
+	if !errors.As(err, &editErr) {
+		t.Fatalf("editInEditor error = %v, want InvalidEditError", err)
+	}
+	if !strings.Contains(editErr.Reason, "changed while it was being edited") {
+		t.Errorf("Reason = %q, want a conflict", editErr.Reason)
+	}
+	kept, err := os.ReadFile(editErr.Path)
+	if err != nil {
+		t.Fatalf("rejected edit not kept: %v", err)
+	}
+	_ = os.Remove(editErr.Path)
+	if !strings.Contains(string(kept), "New title") {
+		t.Errorf("kept edit lacks the new title:\n%s", kept)
+	}
+	loaded, err := store.Load(tk.ID)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if loaded.Title != "Other title" {
+		t.Errorf("saved title = %q, want the concurrent %q", loaded.Title, "Other title")
+	}
+}
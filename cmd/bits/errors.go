@@ -1,6 +1,12 @@
 package main
 
-import "fmt"
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/abatilo/bits/internal/task"
+)
 
 // InvalidStatusError indicates the task has the wrong status for the operation.
 type InvalidStatusError struct {
@@ -29,12 +35,489 @@ func (e InvalidPriorityError) Error() string {
 	return fmt.Sprintf("invalid priority: %s (valid: critical, high, medium, low)", e.Value)
 }
 
-// ActiveTaskExistsError indicates a task is already active.
-type ActiveTaskExistsError struct {
+// WIPLimitExceededError indicates a claim would put more tasks in progress
+// than the WIP limit allows.
+type WIPLimitExceededError struct {
+	Limit  int
+	Active []*task.Task
+	Agent  string // Set when the per-agent limit was reached
+}
+
+func (e WIPLimitExceededError) Error() string {
+	active := make([]string, len(e.Active))
+	for i, t := range e.Active {
+		active[i] = fmt.Sprintf("%s (%s)", t.ID, t.Title)
+	}
+	key := "wip.limit"
+	if e.Agent != "" {
+		key = "wip.per_agent"
+	}
+	return fmt.Sprintf("%s; active: %s; release or close one first (or raise %s)",
+		e.Reached(), strings.Join(active, ", "), key)
+}
+
+// Reached names the limit that was reached.
+func (e WIPLimitExceededError) Reached() string {
+	if e.Agent != "" {
+		return fmt.Sprintf("per-agent WIP limit of %d reached for %s", e.Limit, e.Agent)
+	}
+	return fmt.Sprintf("WIP limit of %d reached", e.Limit)
+}
+
+// InvalidOutputFormatError indicates an unknown --output or --format value.
+type InvalidOutputFormatError struct {
+	Value string
+	Valid []string
+}
+
+func (e InvalidOutputFormatError) Error() string {
+	return fmt.Sprintf("invalid output format: %s (valid: %s)", e.Value, strings.Join(e.Valid, ", "))
+}
+
+// InvalidURISchemeError indicates an unknown --uri value.
+type InvalidURISchemeError struct {
+	Value string
+}
+
+func (e InvalidURISchemeError) Error() string {
+	return fmt.Sprintf("invalid uri scheme: %s (valid: file, bits)", e.Value)
+}
+
+// UnsupportedHookError indicates bits has no script for the named git hook.
+type UnsupportedHookError struct {
+	Name string
+}
+
+func (e UnsupportedHookError) Error() string {
+	return fmt.Sprintf("unsupported git hook: %s", e.Name)
+}
+
+// HookExistsError indicates a git hook not installed by bits is already present.
+type HookExistsError struct {
+	Path string
+}
+
+func (e HookExistsError) Error() string {
+	return fmt.Sprintf("hook %s already exists (use --force to overwrite)", e.Path)
+}
+
+// MissingTaskReferenceError indicates a drain-mode commit doesn't mention the active task.
+type MissingTaskReferenceError struct {
+	ID      string
+	SkipEnv string
+}
+
+func (e MissingTaskReferenceError) Error() string {
+	return fmt.Sprintf("commit message must reference active task %s (add 'Bits-Task: %s' or set %s=1)",
+		e.ID, e.ID, e.SkipEnv)
+}
+
+// InvalidTagError indicates a tag is empty or contains whitespace or commas.
+type InvalidTagError struct {
+	Value string
+}
+
+func (e InvalidTagError) Error() string {
+	return fmt.Sprintf("invalid tag: %q (tags cannot be empty or contain spaces or commas)", e.Value)
+}
+
+// UnfinishedTasksError indicates a push was rejected by the pre-push hook.
+type UnfinishedTasksError struct {
+	IDs []string
+}
+
+func (e UnfinishedTasksError) Error() string {
+	return fmt.Sprintf("push blocked by unfinished tasks: %s (set hooks.pre_push to warn or off to allow)",
+		strings.Join(e.IDs, ", "))
+}
+
+// ReminderTimeError indicates remind was called without exactly one of --in and --at.
+type ReminderTimeError struct{}
+
+func (e ReminderTimeError) Error() string {
+	return "exactly one of --in or --at is required"
+}
+
+// InvalidOrderError indicates an unknown --order value.
+type InvalidOrderError struct {
+	Value string
+}
+
+func (e InvalidOrderError) Error() string {
+	return fmt.Sprintf("invalid order: %s (valid: ready, priority, created)", e.Value)
+}
+
+// MissingVerifyCommandError indicates close --verify found no command to run.
+type MissingVerifyCommandError struct{}
+
+func (e MissingVerifyCommandError) Error() string {
+	return "no verify command: set one with 'bits add --verify' or verify.command in the config file"
+}
+
+// VerifyFailedError indicates the verify command failed, so the task stays open.
+type VerifyFailedError struct {
+	Command  string
+	ExitCode int
+	Summary  string
+}
+
+func (e VerifyFailedError) Error() string {
+	msg := fmt.Sprintf("verify command `%s` exited %d; task not closed", e.Command, e.ExitCode)
+	if e.Summary != "" {
+		msg += ": " + e.Summary
+	}
+	return msg
+}
+
+// CriterionNotFoundError indicates accept was given a criterion number the task doesn't have.
+type CriterionNotFoundError struct {
 	ID    string
+	Value string
+	Count int
+}
+
+func (e CriterionNotFoundError) Error() string {
+	return fmt.Sprintf("task %s has no acceptance criterion %s (it has %d)", e.ID, e.Value, e.Count)
+}
+
+// UncheckedCriteriaError indicates a task still has acceptance criteria to check off.
+type UncheckedCriteriaError struct {
+	ID       string
+	Criteria []int
+}
+
+func (e UncheckedCriteriaError) Error() string {
+	numbers := make([]string, len(e.Criteria))
+	for i, n := range e.Criteria {
+		numbers[i] = strconv.Itoa(n)
+	}
+	return fmt.Sprintf("task %s has unchecked acceptance criteria: %s (check off with 'bits accept %s <n>')",
+		e.ID, strings.Join(numbers, ", "), e.ID)
+}
+
+// ScriptFailedError indicates a lifecycle script exited non-zero or timed out.
+type ScriptFailedError struct {
+	Event   string
+	Command string
+	Reason  string
+}
+
+func (e ScriptFailedError) Error() string {
+	return fmt.Sprintf("on_%s script `%s` failed: %s", e.Event, e.Command, e.Reason)
+}
+
+// DaemonRunningError indicates a daemon is already serving the store.
+type DaemonRunningError struct {
+	Socket string
+}
+
+func (e DaemonRunningError) Error() string {
+	return fmt.Sprintf("a daemon is already running on %s", e.Socket)
+}
+
+// DaemonNotRunningError indicates a command needs a daemon but none is serving the store.
+type DaemonNotRunningError struct {
+	Socket string
+}
+
+func (e DaemonNotRunningError) Error() string {
+	return fmt.Sprintf("no daemon is running on %s (start one with 'bits daemon')", e.Socket)
+}
+
+// BatchOpError identifies the operation in a batch that failed validation.
+type BatchOpError struct {
+	Index int // 1-based position in the batch
+	Op    string
+	Err   error
+}
+
+func (e BatchOpError) Error() string {
+	return fmt.Sprintf("batch operation %d (%s): %v; nothing was applied", e.Index, e.Op, e.Err)
+}
+
+func (e BatchOpError) Unwrap() error {
+	return e.Err
+}
+
+// UnknownBatchOpError indicates a batch operation of an unsupported kind.
+type UnknownBatchOpError struct {
+	Op string
+}
+
+func (e UnknownBatchOpError) Error() string {
+	return fmt.Sprintf("unknown operation: %q (valid: add, dep, close, priority)", e.Op)
+}
+
+// MissingTitleError indicates a task was added without a title.
+type MissingTitleError struct{}
+
+func (e MissingTitleError) Error() string {
+	return "title is required"
+}
+
+// DuplicateRefError indicates a batch ref names an existing task or an
+// earlier ref.
+type DuplicateRefError struct {
+	Ref string
+}
+
+func (e DuplicateRefError) Error() string {
+	return fmt.Sprintf("ref %q is already in use", e.Ref)
+}
+
+// DuplicateTaskError indicates unfinished tasks with titles similar to a new one.
+type DuplicateTaskError struct {
+	Title   string
+	Matches []*task.Task
+	Blocked bool // The add was refused
+}
+
+func (e DuplicateTaskError) Error() string {
+	matches := make([]string, len(e.Matches))
+	for i, t := range e.Matches {
+		matches[i] = fmt.Sprintf("%s %q", t.ID, t.Title)
+	}
+	msg := fmt.Sprintf("%q looks like existing task(s) %s", e.Title, strings.Join(matches, ", "))
+	if e.Blocked {
+		msg += " (pass --allow-duplicate to add it anyway)"
+	}
+	return msg
+}
+
+// UnknownStrategyError indicates an unsupported --strategy value.
+type UnknownStrategyError struct {
+	Value string
+	Valid []string
+}
+
+func (e UnknownStrategyError) Error() string {
+	return fmt.Sprintf("unknown strategy %q (valid: %s)", e.Value, strings.Join(e.Valid, ", "))
+}
+
+// MissingAgentsError indicates assign was run with no agents to assign to.
+type MissingAgentsError struct{}
+
+func (e MissingAgentsError) Error() string {
+	return "no agents to assign to (pass --agents or set agents in the config file)"
+}
+
+// MissingNotifyCommandError indicates --notify was given without a
+// notify.command to send through.
+type MissingNotifyCommandError struct{}
+
+func (e MissingNotifyCommandError) Error() string {
+	return "no notifier configured (set notify.command in the config file)"
+}
+
+// NotConfirmedError indicates a destructive command was not confirmed.
+type NotConfirmedError struct {
+	Action string
+}
+
+func (e NotConfirmedError) Error() string {
+	return e.Action + " not confirmed (pass --yes to skip the prompt)"
+}
+
+// TrailerCloseError indicates a task named in a Closes-Bits trailer failed
+// the close checks and was left open.
+type TrailerCloseError struct {
+	ID  string
+	SHA string
+	Err error
+}
+
+func (e TrailerCloseError) Error() string {
+	return fmt.Sprintf("not closing task %s from commit %.7s: %v", e.ID, e.SHA, e.Err)
+}
+
+func (e TrailerCloseError) Unwrap() error {
+	return e.Err
+}
+
+// EditConflictError indicates a task changed while it was open in $EDITOR.
+type EditConflictError struct {
+	ID string
+}
+
+func (e EditConflictError) Error() string {
+	return fmt.Sprintf("task %s changed while it was being edited; run 'bits edit' again", e.ID)
+}
+
+// InvalidEditError indicates an edit made in $EDITOR was rejected. The edit is
+// left at Path.
+type InvalidEditError struct {
+	Path   string
+	Reason string
+}
+
+func (e InvalidEditError) Error() string {
+	return fmt.Sprintf("edit rejected: %s (your changes are saved in %s)", e.Reason, e.Path)
+}
+
+// OpenChildrenError warns that a task was closed with subtasks unfinished.
+type OpenChildrenError struct {
+	ID       string
+	Children []string
+}
+
+func (e OpenChildrenError) Error() string {
+	return fmt.Sprintf("task %s still has unfinished subtasks: %s", e.ID, strings.Join(e.Children, ", "))
+}
+
+// MissingNoteError indicates a note was added without text.
+type MissingNoteError struct{}
+
+func (e MissingNoteError) Error() string {
+	return "note text is required"
+}
+
+// DepArgsError indicates 'bits dep' was given "--" with nothing on one side.
+type DepArgsError struct{}
+
+func (e DepArgsError) Error() string {
+	return `list the tasks before "--" and their dependencies after it`
+}
+
+// ImportTaskError identifies the task in an import file that failed
+// validation.
+type ImportTaskError struct {
+	Index int // 1-based position in the file
 	Title string
+	Err   error
+}
+
+func (e ImportTaskError) Error() string {
+	return fmt.Sprintf("task %d (%q): %v; nothing was imported", e.Index, e.Title, e.Err)
+}
+
+func (e ImportTaskError) Unwrap() error {
+	return e.Err
+}
+
+// InvalidTaskStatusError indicates an imported task has an unknown status.
+type InvalidTaskStatusError struct {
+	Value string
+}
+
+func (e InvalidTaskStatusError) Error() string {
+	return fmt.Sprintf("invalid status: %s (valid: open, active, closed)", e.Value)
+}
+
+// DuplicateImportIDError indicates an import file holds two tasks with one ID.
+type DuplicateImportIDError struct {
+	ID string
+}
+
+func (e DuplicateImportIDError) Error() string {
+	return fmt.Sprintf("ID %s is used by more than one task in the file", e.ID)
+}
+
+// UnknownImportReferenceError indicates an imported task refers to a task
+// that is neither in the file nor in the store.
+type UnknownImportReferenceError struct {
+	Ref string
+}
+
+func (e UnknownImportReferenceError) Error() string {
+	return fmt.Sprintf("refers to task %s, which is neither in the file nor in the store", e.Ref)
+}
+
+// ImportRenamedError warns that an imported task got a new ID, since its own
+// was taken or isn't a bits ID.
+type ImportRenamedError struct {
+	From string
+	To   string
+}
+
+func (e ImportRenamedError) Error() string {
+	return fmt.Sprintf("task %s was imported as %s", e.From, e.To)
+}
+
+// StoreLeftBehindError warns that 'bits init --local' or '--home' left tasks
+// in the project's other store.
+type StoreLeftBehindError struct {
+	Path  string
+	Count int
+}
+
+func (e StoreLeftBehindError) Error() string {
+	return fmt.Sprintf("%d task(s) in %s stay there; move them with 'bits export --format json' and 'bits import file'",
+		e.Count, e.Path)
+}
+
+// InvalidJournalSeqError indicates an undo argument that isn't an entry number.
+type InvalidJournalSeqError struct {
+	Value string
+}
+
+func (e InvalidJournalSeqError) Error() string {
+	return fmt.Sprintf("invalid journal entry %q: expected a number from 'bits history'", e.Value)
+}
+
+// InvalidLinkTypeError indicates an unknown dep --type.
+type InvalidLinkTypeError struct {
+	Value string
+}
+
+func (e InvalidLinkTypeError) Error() string {
+	return fmt.Sprintf("invalid link type %q: must be depends-on, blocks, relates-to, or duplicates", e.Value)
+}
+
+// LinkTypeFlagsError indicates a dep --type that can't combine with a flag.
+type LinkTypeFlagsError struct {
+	Type task.LinkType
+	Flag string
+}
+
+func (e LinkTypeFlagsError) Error() string {
+	return fmt.Sprintf("--type %s can't be combined with %s", e.Type, e.Flag)
+}
+
+// NoReadyTasksError indicates 'bits next' found nothing ready to work on.
+type NoReadyTasksError struct{}
+
+func (e NoReadyTasksError) Error() string {
+	return "no ready tasks"
+}
+
+// MissingLinearKeyError indicates 'bits sync linear' has no API key to use.
+type MissingLinearKeyError struct{}
+
+func (e MissingLinearKeyError) Error() string {
+	return "no Linear API key (set " + linearKeyEnv + " to a personal API key)"
+}
+
+// LinearIssueError indicates a Linear issue that couldn't be synced.
+type LinearIssueError struct {
+	Issue string
+	Err   error
+}
+
+func (e LinearIssueError) Error() string {
+	return fmt.Sprintf("linear issue %s: %v", e.Issue, e.Err)
+}
+
+func (e LinearIssueError) Unwrap() error {
+	return e.Err
+}
+
+// MissingJiraURLError indicates 'bits import jira' doesn't know which site to search.
+type MissingJiraURLError struct{}
+
+func (e MissingJiraURLError) Error() string {
+	return "no Jira site (pass --url or set " + jiraURLEnv + ")"
+}
+
+// JiraIssueError indicates a Jira issue that couldn't be imported.
+type JiraIssueError struct {
+	Key string
+	Err error
+}
+
+func (e JiraIssueError) Error() string {
+	return fmt.Sprintf("jira issue %s: %v", e.Key, e.Err)
 }
 
-func (e ActiveTaskExistsError) Error() string {
-	return fmt.Sprintf("task %s (%s) is already active; release or close it first", e.ID, e.Title)
+func (e JiraIssueError) Unwrap() error {
+	return e.Err
 }
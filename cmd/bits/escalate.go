@@ -0,0 +1,96 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/abatilo/bits/internal/notify"
+	"github.com/abatilo/bits/internal/storage"
+	"github.com/abatilo/bits/internal/task"
+)
+
+// escalateCmd implements 'bits escalate'.
+func escalateCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "escalate",
+		Short: "Raise the priority of overdue tasks",
+		Long: `Raise every unfinished task past its due date to at least the priority set by
+escalate.priority in the config file (default high), so overdue work floats
+to the top of 'bits ready'. With escalate.notify, each escalation is sent to
+notify.command. With escalate.auto, 'bits list' and 'bits ready' escalate
+before listing.`,
+		Args: cobra.NoArgs,
+		Run: func(_ *cobra.Command, _ []string) {
+			store, err := getStore()
+			if err != nil {
+				printError(err)
+			}
+			tasks, err := store.List(storage.StatusFilter{Open: true, Active: true})
+			if err != nil {
+				printError(err)
+			}
+
+			escalated, err := escalateOverdue(store, tasks)
+			if err != nil {
+				printError(err)
+			}
+			printOutput(formatter.FormatTaskList(escalated))
+		},
+	}
+}
+
+// escalateOverdue applies the escalation policy to tasks in place, saving and
+// optionally notifying for each task whose priority was raised. The store is
+// locked once there's something to save, and each task reloaded under the
+// lock so a write made since tasks were listed isn't lost.
+func escalateOverdue(store *storage.Store, tasks []*task.Task) ([]*task.Task, error) {
+	now := time.Now().UTC()
+	target := cfg.Escalate.TargetPriority()
+
+	var escalated []*task.Task
+	for _, t := range tasks {
+		if !t.Escalate(now, target) {
+			continue
+		}
+		lockStore(store)
+		fresh, err := store.Load(t.ID)
+		if err != nil {
+			return escalated, err
+		}
+		from := fresh.Priority
+		if !fresh.Escalate(now, target) {
+			*t = *fresh // Escalated, or changed, by someone else meanwhile
+			continue
+		}
+		if err = store.Save(fresh); err != nil {
+			return escalated, err
+		}
+		*t = *fresh
+		escalated = append(escalated, t)
+
+		if cfg.Escalate.Notify {
+			msg := fmt.Sprintf("Task %s (%s) is overdue; priority raised from %s to %s", t.ID, t.Title, from, target)
+			err := notify.Send(cfg.Notify.Command, notify.Notification{
+				Kind:    "escalate",
+				TaskID:  t.ID,
+				Title:   t.Title,
+				Message: msg,
+			})
+			if err != nil {
+				return escalated, err
+			}
+		}
+	}
+	return escalated, nil
+}
+
+// autoEscalate runs escalateOverdue when escalate.auto is set.
+func autoEscalate(store *storage.Store, tasks []*task.Task) error {
+	if !cfg.Escalate.Auto {
+		return nil
+	}
+	_, err := escalateOverdue(store, tasks)
+	return err
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"sort"
+
+	"github.com/spf13/cobra"
+
+	"github.com/abatilo/bits/internal/output"
+	"github.com/abatilo/bits/internal/plan"
+	"github.com/abatilo/bits/internal/storage"
+)
+
+// exportCmd implements 'bits export'.
+func exportCmd() *cobra.Command {
+	var seed, closed bool
+	var format string
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export every task in the store",
+		Long: `Print every task in the store, oldest first, in the output format (use --json
+or -o yaml for a machine-readable dump).
+
+--format json or --format csv writes a dump that 'bits import file' reads
+back, whatever the output format, for backups and moving a store to another
+machine. JSON keeps every field; CSV keeps the ones a spreadsheet needs (ID,
+title, status, priority, timestamps, close reason, dependencies, parent,
+tags, assignee, and description). --closed adds the closed tasks that 'bits
+compact' archived, so the dump covers the store's whole history.
+
+With --seed, print a plan file instead: the open and active tasks with their
+titles, descriptions, priorities, tags, and dependencies, referenced by name
+rather than ID. 'bits apply' instantiates it into a fresh store, which makes
+it useful for project templates and test fixtures:
+
+  bits export --seed > template.yaml
+  bits apply template.yaml          # in another repository`,
+		Example: "  bits export --format json --closed > backup.json\n" +
+			"  bits export --format csv > tasks.csv",
+		Args: cobra.NoArgs,
+		Run: func(_ *cobra.Command, _ []string) {
+			store, err := getStore()
+			if err != nil {
+				printError(err)
+			}
+			switch format {
+			case "", "json", "csv":
+			default:
+				printError(InvalidOutputFormatError{Value: format, Valid: []string{"json", "csv"}})
+			}
+
+			filter := storage.StatusFilter{}
+			if seed {
+				filter = storage.StatusFilter{Open: true, Active: true}
+			}
+			tasks, err := listTasks(store, filter, "created")
+			if err != nil {
+				printError(err)
+			}
+			if seed {
+				data, marshalErr := plan.FromTasks(tasks).Marshal()
+				if marshalErr != nil {
+					printError(marshalErr)
+				}
+				printOutput(string(data))
+				return
+			}
+
+			if closed {
+				archived, archiveErr := store.Archived()
+				if archiveErr != nil {
+					printError(archiveErr)
+				}
+				tasks = append(archived, tasks...)
+				sort.SliceStable(tasks, func(i, j int) bool { return tasks[i].CreatedAt.Before(tasks[j].CreatedAt) })
+			}
+			switch format {
+			case "json":
+				printOutput(output.NewJSONFormatter().FormatTaskList(tasks))
+			case "csv":
+				printOutput(output.FormatTasksCSV(tasks))
+			default:
+				printOutput(formatter.FormatTaskList(tasks))
+			}
+		},
+	}
+	cmd.Flags().StringVar(&format, "format", "", "Write a dump for 'bits import file': json or csv")
+	cmd.Flags().BoolVar(&closed, "closed", false, "Include closed tasks archived by 'bits compact'")
+	cmd.Flags().BoolVar(&seed, "seed", false, "Print a plan file of the unfinished tasks for 'bits apply'")
+	return cmd
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"sort"
+
+	"github.com/spf13/cobra"
+
+	"github.com/abatilo/bits/internal/deps"
+	"github.com/abatilo/bits/internal/storage"
+	"github.com/abatilo/bits/internal/task"
+)
+
+const defaultFindLimit = 10
+
+// findCmd implements 'bits find'.
+func findCmd() *cobra.Command {
+	var closed bool
+	var limit int
+	cmd := &cobra.Command{
+		Use:   "find <text>",
+		Short: "Fuzzy-find tasks by title",
+		Long: `List the unfinished tasks whose titles best match text, best first. Each word
+of text must appear in the title as a subsequence, ignoring case, so
+"lgn bug" finds "Fix the login bug". Tighter matches rank higher, and ties
+go to the more urgent task.
+
+Use it to turn "the task about X" into an ID; 'bits search' matches exact text
+in descriptions too.`,
+		Args: cobra.ExactArgs(1),
+		Run: func(_ *cobra.Command, args []string) {
+			store, err := getStore()
+			if err != nil {
+				printError(err)
+			}
+			tasks, err := store.List(storage.StatusFilter{Open: true, Active: true, Closed: closed})
+			if err != nil {
+				printError(err)
+			}
+			printOutput(formatter.FormatTaskList(findTasks(tasks, args[0], limit)))
+		},
+	}
+	cmd.Flags().BoolVar(&closed, "closed", false, "Include closed tasks")
+	cmd.Flags().IntVarP(&limit, "limit", "n", defaultFindLimit, "Maximum number of candidates (0 for all)")
+	return cmd
+}
+
+// findTasks returns the tasks whose titles fuzzy-match query, best match
+// first, keeping at most limit of them when limit is positive.
+func findTasks(tasks []*task.Task, query string, limit int) []*task.Task {
+	scores := make(map[string]int)
+	var matches []*task.Task
+	for _, t := range tasks {
+		if score, ok := task.FuzzyScore(query, t.Title); ok {
+			scores[t.ID] = score
+			matches = append(matches, t)
+		}
+	}
+	deps.SortByPriority(matches)
+	sort.SliceStable(matches, func(i, j int) bool {
+		return scores[matches[i].ID] > scores[matches[j].ID]
+	})
+	if limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches
+}
@@ -0,0 +1,371 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/abatilo/bits/internal/config"
+	"github.com/abatilo/bits/internal/git"
+	"github.com/abatilo/bits/internal/session"
+	"github.com/abatilo/bits/internal/storage"
+	"github.com/abatilo/bits/internal/task"
+)
+
+const (
+	hookMarker         = "# Installed by bits"
+	skipCommitCheckEnv = "BITS_SKIP_COMMIT_CHECK"
+)
+
+// hookCommands maps each git hook bits can install to the command it runs.
+func hookCommands() map[string]string {
+	return map[string]string{
+		"prepare-commit-msg": `bits commit-trailer "$1" || true`,
+		"commit-msg":         `bits githook commit-msg "$1"`,
+		"post-commit":        "bits githook post-commit || true",
+		"pre-push":           `bits githook pre-push "$@"`,
+	}
+}
+
+// commitTrailerCmd implements 'bits commit-trailer'.
+func commitTrailerCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "commit-trailer [message-file]",
+		Short: "Print or append the Bits-Task trailer for the active task",
+		Long: `Print a "Bits-Task: <id>" trailer for the active task. Given a commit message
+file (as passed to a prepare-commit-msg hook), append the trailer to it instead
+unless it is already present. Does nothing when no task is active.`,
+		Args: cobra.MaximumNArgs(1),
+		Run: func(_ *cobra.Command, args []string) {
+			store, err := getStore()
+			if err != nil {
+				printError(err)
+			}
+
+			tasks, err := store.List(storage.StatusFilter{Active: true})
+			if err != nil {
+				printError(err)
+			}
+			active := task.FindActive(tasks)
+			if active == nil {
+				return
+			}
+
+			if len(args) == 0 {
+				printOutput(formatter.FormatMessage(git.TaskTrailer + ": " + active.ID))
+				return
+			}
+			if err = git.AddTrailer("", args[0], git.TaskTrailer, active.ID); err != nil {
+				printError(err)
+			}
+		},
+	}
+}
+
+// githookCmd implements 'bits githook' command group.
+func githookCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "githook",
+		Short: "Install and run git hooks that link commits to tasks",
+	}
+
+	cmd.AddCommand(
+		githookInstallCmd(),
+		githookCommitMsgCmd(),
+		githookPostCommitCmd(),
+		githookPrePushCmd(),
+	)
+
+	return cmd
+}
+
+// githookInstallCmd implements 'bits githook install'.
+func githookInstallCmd() *cobra.Command {
+	var force bool
+	commands := hookCommands()
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	cmd := &cobra.Command{
+		Use:   "install [hook...]",
+		Short: "Install bits git hooks (default: all)",
+		Long: fmt.Sprintf(`Install git hooks that call bits. Supported hooks: %s.
+
+Existing hooks not installed by bits are left alone unless --force is given.`,
+			strings.Join(names, ", ")),
+		Run: func(_ *cobra.Command, args []string) {
+			hooks := args
+			if len(hooks) == 0 {
+				hooks = names
+			}
+
+			dir, err := git.HooksDir("")
+			if err != nil {
+				printError(err)
+			}
+
+			var installed []string
+			for _, hook := range hooks {
+				if err = installHook(dir, hook, force); err != nil {
+					printError(err)
+				}
+				installed = append(installed, hook)
+			}
+			printOutput(formatter.FormatMessage(
+				fmt.Sprintf("Installed %s in %s", strings.Join(installed, ", "), dir)))
+		},
+	}
+	cmd.Flags().BoolVarP(&force, "force", "f", false, "Overwrite existing hooks")
+	return cmd
+}
+
+// installHook writes the script for a supported hook into dir.
+func installHook(dir, hook string, force bool) error {
+	command, ok := hookCommands()[hook]
+	if !ok {
+		return UnsupportedHookError{Name: hook}
+	}
+
+	path := filepath.Join(dir, hook)
+	if existing, err := os.ReadFile(path); err == nil { //nolint:gosec // G304: path is within the hooks directory
+		if !force && !strings.Contains(string(existing), hookMarker) {
+			return HookExistsError{Path: path}
+		}
+	}
+
+	//nolint:gosec // G301: hooks directory uses git's default permissions
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	script := fmt.Sprintf("#!/bin/sh\n%s\ncommand -v bits >/dev/null 2>&1 || exit 0\n%s\n", hookMarker, command)
+	//nolint:gosec // G306: hooks must be executable
+	if err := os.WriteFile(path, []byte(script), 0o755); err != nil {
+		return err
+	}
+	return os.Chmod(path, 0o755) //nolint:gosec // G302: hooks must be executable
+}
+
+// githookCommitMsgCmd implements 'bits githook commit-msg'.
+func githookCommitMsgCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "commit-msg <message-file>",
+		Short: "Reject drain-mode commits that don't reference the active task",
+		Long: `Check a commit message (as passed to a commit-msg hook). While drain mode is
+active and a task is claimed, the message must mention the active task ID,
+for example in a Bits-Task trailer. Otherwise the commit is rejected.
+
+Set ` + skipCommitCheckEnv + `=1 to skip the check for a single commit.`,
+		Args: cobra.ExactArgs(1),
+		Run: func(_ *cobra.Command, args []string) {
+			if os.Getenv(skipCommitCheckEnv) != "" {
+				return
+			}
+
+			store, err := getStore()
+			if err != nil {
+				printError(err)
+			}
+			sess, err := session.Load(store.BasePath())
+			if err != nil || !sess.DrainActive {
+				return
+			}
+
+			tasks, err := store.List(storage.StatusFilter{Active: true})
+			if err != nil {
+				printError(err)
+			}
+			active := task.FindActive(tasks)
+			if active == nil {
+				return
+			}
+
+			message, err := os.ReadFile(args[0])
+			if err != nil {
+				printError(err)
+			}
+			if !git.MentionsTask(string(message), active.ID) {
+				printError(MissingTaskReferenceError{ID: active.ID, SkipEnv: skipCommitCheckEnv})
+			}
+		},
+	}
+}
+
+// githookPostCommitCmd implements 'bits githook post-commit'.
+func githookPostCommitCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "post-commit [rev]",
+		Short: "Link and close tasks named in a commit's trailers",
+		Long: `Process the trailers of a commit (default HEAD):
+
+  Bits-Task: <id>             records the commit on the task
+  Closes-Bits: <id> [reason]  records the commit and closes the task
+
+A task closed without a reason is closed with "Closed by commit <sha>". Each
+close runs the acceptance and verify checks 'bits close' does; a task that
+fails them is left open with a warning.`,
+		Args: cobra.MaximumNArgs(1),
+		Run: func(_ *cobra.Command, args []string) {
+			rev := "HEAD"
+			if len(args) > 0 {
+				rev = args[0]
+			}
+
+			store, err := getStore()
+			if err != nil {
+				printError(err)
+			}
+			lockStore(store)
+
+			sha, err := git.ResolveRev("", rev)
+			if err != nil {
+				printError(err)
+			}
+			linked, err := linkCommit(store, sha)
+			if err != nil {
+				printError(err)
+			}
+			closed, err := closeFromCommit(store, sha)
+			if err != nil {
+				printError(err)
+			}
+
+			for _, t := range linked {
+				printOutput(formatter.FormatMessage(fmt.Sprintf("Linked commit %.7s to task %s", sha, t.ID)))
+			}
+			for _, t := range closed {
+				printOutput(formatter.FormatMessage(fmt.Sprintf("Closed task %s: %s", t.ID, *t.CloseReason)))
+			}
+		},
+	}
+}
+
+// linkCommit records the commit on every existing task named in its
+// Bits-Task trailers, returning the tasks that changed.
+func linkCommit(store *storage.Store, sha string) ([]*task.Task, error) {
+	ids, err := git.Trailers("", sha, git.TaskTrailer)
+	if err != nil {
+		return nil, err
+	}
+
+	var linked []*task.Task
+	for _, id := range ids {
+		t, loadErr := store.Load(id)
+		if loadErr != nil {
+			continue // Trailers may name tasks from another store
+		}
+		if slices.Contains(t.Commits, sha) {
+			continue
+		}
+		t.Commits = append(t.Commits, sha)
+		if err = store.Save(t); err != nil {
+			return linked, err
+		}
+		linked = append(linked, t)
+	}
+	return linked, nil
+}
+
+// closeFromCommit closes every open or active task named in the commit's
+// Closes-Bits trailers, recording the commit on it. Each close passes the
+// acceptance and verify checks 'bits close' runs; a task that fails them is
+// left alone with a warning. It returns the tasks closed.
+func closeFromCommit(store *storage.Store, sha string) ([]*task.Task, error) {
+	values, err := git.Trailers("", sha, git.CloseTrailer)
+	if err != nil {
+		return nil, err
+	}
+
+	check := func(t *task.Task) (string, error) {
+		note, checkErr := closeGate(t, false, false)
+		if checkErr == nil {
+			checkErr = warnOpenChildren(store, t)
+		}
+		if checkErr != nil {
+			printWarning(TrailerCloseError{ID: t.ID, SHA: sha, Err: checkErr})
+		}
+		return note, checkErr
+	}
+	closed, err := store.CloseFromTrailers(sha, values, currentActor(store), check)
+	for _, t := range closed {
+		runLifecycle(task.EventClose, t)
+	}
+	return closed, err
+}
+
+// githookPrePushCmd implements 'bits githook pre-push'.
+func githookPrePushCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "pre-push [remote] [url]",
+		Short: "Warn about unfinished tasks before pushing",
+		Long: `Check the refs a pre-push hook reads on stdin. The push is flagged while a
+task is still active, or while a critical task is open that is either on one
+of the pushed branches or not tied to any branch.
+
+By default this only warns. Set hooks.pre_push in the config file to "block"
+to reject the push, or "off" to skip the check.`,
+		Args: cobra.MaximumNArgs(2), //nolint:mnd // git passes the remote name and URL
+		Run: func(_ *cobra.Command, _ []string) {
+			mode := cfg.Hooks.PrePushMode()
+			if mode == config.PrePushOff {
+				return
+			}
+
+			branches, err := git.PushedBranches(os.Stdin)
+			if err != nil {
+				printError(err)
+			}
+			if len(branches) == 0 {
+				return
+			}
+
+			store, err := getStore()
+			if err != nil {
+				printError(err)
+			}
+			tasks, err := store.List(storage.StatusFilter{Open: true, Active: true})
+			if err != nil {
+				printError(err)
+			}
+
+			unfinished := pushBlockers(tasks, branches)
+			if len(unfinished) == 0 {
+				return
+			}
+
+			lines := make([]string, 0, len(unfinished))
+			ids := make([]string, 0, len(unfinished))
+			for _, t := range unfinished {
+				lines = append(lines, fmt.Sprintf("  %s [%s, %s] %s", t.ID, t.Status, t.Priority, t.Title))
+				ids = append(ids, t.ID)
+			}
+			printOutput(formatter.FormatMessage("Unfinished tasks:\n" + strings.Join(lines, "\n")))
+			if mode == config.PrePushBlock {
+				printError(UnfinishedTasksError{IDs: ids})
+			}
+		},
+	}
+}
+
+// pushBlockers returns the tasks that should hold up a push of branches: every
+// active task, and open critical tasks on a pushed branch or on no branch.
+func pushBlockers(tasks []*task.Task, branches []string) []*task.Task {
+	var blockers []*task.Task
+	for _, t := range tasks {
+		switch {
+		case t.Status == task.StatusActive:
+			blockers = append(blockers, t)
+		case t.Status == task.StatusOpen && t.Priority == task.PriorityCritical &&
+			(t.Branch == "" || slices.Contains(branches, t.Branch)):
+			blockers = append(blockers, t)
+		}
+	}
+	return blockers
+}
@@ -0,0 +1,71 @@
+//nolint:testpackage // Tests require internal access for thorough testing
+package main
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/abatilo/bits/internal/git"
+	"github.com/abatilo/bits/internal/task"
+)
+
+// commitClosing makes an empty commit in a new repository, which becomes the
+// working directory, with a Closes-Bits trailer per value. It returns the sha.
+func commitClosing(t *testing.T, values ...string) string {
+	t.Helper()
+	message := "Fix\n"
+	for _, value := range values {
+		message += "\n" + git.CloseTrailer + ": " + value
+	}
+
+	t.Chdir(t.TempDir())
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"config", "user.email", "test@example.com"},
+		{"config", "user.name", "Test"},
+		{"commit", "-q", "--allow-empty", "-m", message},
+	} {
+		if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v: %s", args, err, out)
+		}
+	}
+	sha, err := git.ResolveRev("", "HEAD")
+	if err != nil {
+		t.Fatalf("ResolveRev failed: %v", err)
+	}
+	return sha
+}
+
+func TestCloseFromCommitChecks(t *testing.T) {
+	store := newTestStore(t)
+	failing, _ := store.CreateTask("Failing", "", task.PriorityMedium)
+	failing.Verify = "false"
+	passing, _ := store.CreateTask("Passing", "", task.PriorityMedium)
+	passing.Verify = "true"
+	unchecked, _ := store.CreateTask("Unchecked", "", task.PriorityMedium)
+	unchecked.AddCriterion("Tested")
+	for _, tk := range []*task.Task{failing, passing, unchecked} {
+		if err := store.Save(tk); err != nil {
+			t.Fatalf("Save failed: %v", err)
+		}
+	}
+	cfg.Acceptance.OnClose = "block"
+
+	sha := commitClosing(t, failing.ID, passing.ID, unchecked.ID)
+	closed, err := closeFromCommit(store, sha)
+	if err != nil {
+		t.Fatalf("closeFromCommit failed: %v", err)
+	}
+	if len(closed) != 1 || closed[0].ID != passing.ID {
+		t.Fatalf("closed %d task(s), want only %s", len(closed), passing.ID)
+	}
+	for _, tk := range []*task.Task{failing, unchecked} {
+		if loaded, _ := store.Load(tk.ID); loaded.Status != task.StatusOpen {
+			t.Errorf("%s status = %s, want open", tk.Title, loaded.Status)
+		}
+	}
+	if loaded, _ := store.Load(passing.ID); !strings.Contains(loaded.History[len(loaded.History)-1].Note, "true") {
+		t.Errorf("close note = %q, want the verify summary", loaded.History[len(loaded.History)-1].Note)
+	}
+}
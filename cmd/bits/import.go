@@ -0,0 +1,445 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/abatilo/bits/internal/deps"
+	"github.com/abatilo/bits/internal/external"
+	"github.com/abatilo/bits/internal/importer"
+	"github.com/abatilo/bits/internal/jira"
+	"github.com/abatilo/bits/internal/output"
+	"github.com/abatilo/bits/internal/task"
+)
+
+// importCmd implements 'bits import'.
+func importCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "import",
+		Short: "Import tasks from other tools",
+	}
+	cmd.AddCommand(importDirCmd(), importFileCmd(), importJiraCmd())
+	return cmd
+}
+
+// importDirCmd implements 'bits import dir'.
+func importDirCmd() *cobra.Command {
+	var specs []string
+	cmd := &cobra.Command{
+		Use:   "dir <path>",
+		Short: "Import a directory of markdown or YAML task files",
+		Long: `Create a task for every markdown (.md) and YAML (.yaml, .yml) file under a
+directory, such as a folder of Obsidian task notes or a beorg export. Hidden
+files and directories are skipped. The import is all or nothing.
+
+Markdown frontmatter supplies the task fields and the body its description;
+a note without a title uses its first heading, then its file name. A YAML file
+holds one task or a list of them.
+
+Fields are read from their usual keys:
+
+  title        title, name, summary
+  description  description, body, notes
+  status       status, state, done, completed
+  priority     priority
+  tags         tags, labels
+  due          due, due_at, due_date, deadline, scheduled
+  created      created, created_at, date
+
+Pass --map field=key to read a field from another key, or field=- to skip
+it. Finished statuses (done, completed, true, ...) import as closed tasks and
+everything else as open. Priorities accept bits' names, P0-P3, and 1-4.`,
+		Example: "  bits import dir ~/vault/Tasks --map title=task,due=when\n" +
+			"  bits import dir ./backlog --map description=- --dry-run",
+		Args: cobra.ExactArgs(1),
+		Run: func(_ *cobra.Command, args []string) {
+			store, err := getStore()
+			if err != nil {
+				printError(err)
+			}
+			mapping, err := importer.ParseMapping(specs)
+			if err != nil {
+				printError(err)
+			}
+			records, err := importer.ReadDir(args[0], mapping)
+			if err != nil {
+				printError(err)
+			}
+			lockStore(store)
+
+			b, err := newBatch(store)
+			if err != nil {
+				printError(err)
+			}
+			for _, r := range records {
+				if err = b.importRecord(r); err != nil {
+					printError(importer.FileError{Path: r.Path, Err: err})
+				}
+			}
+			if err = b.commit(); err != nil {
+				printError(err)
+			}
+			printOutput(formatter.FormatTaskList(b.changed))
+		},
+	}
+	cmd.Flags().StringSliceVar(&specs, "map", nil, "Read a field from another key, as field=key (repeatable)")
+	return cmd
+}
+
+// importRecord stages a task for an imported record. Finished work is closed
+// without running lifecycle scripts, since it was done before the import.
+func (b *batch) importRecord(r importer.Record) error {
+	t, err := buildTask(b.store, r.Title, addOptions{
+		Description: r.Description,
+		Priority:    string(r.Priority),
+		Tags:        r.Tags,
+	})
+	if err != nil {
+		return err
+	}
+	if r.Created != nil {
+		t.CreatedAt = *r.Created
+	}
+	t.DueAt = r.Due
+	if r.Status == task.StatusClosed {
+		t.Close(time.Now().UTC(), "Imported as done from "+r.Path, currentActor(b.store))
+	}
+	b.tasks[t.ID] = t
+	b.touch(t)
+	return nil
+}
+
+// importFileCmd implements 'bits import file'.
+func importFileCmd() *cobra.Command {
+	var format string
+	cmd := &cobra.Command{
+		Use:   "file <path>",
+		Short: "Recreate tasks from a JSON or CSV export",
+		Long: `Recreate the tasks in a file written by 'bits export --format json' or
+'--format csv', or read from stdin when the path is "-". This restores
+backups, moves a store to another machine, and loads plans generated by other
+tools. The format follows the file's extension unless --format is given, and
+defaults to JSON.
+
+Tasks keep their IDs, status, timestamps, and history. A task whose ID is
+taken in this store, or isn't a bits ID (like API-12), gets a new one, and
+the dependencies, ordering hints, and parents that referred to it are
+rewritten to match; each is reported on stderr. Every reference must be to a
+task in the file or the store, and dependencies can't form a cycle.
+
+Files from other tools need only a title: a missing status imports as open
+and a missing priority as the default (medium, or defaults.priority).
+Lifecycle scripts don't run, and attachments, which exports don't carry, are
+dropped. The import is all or nothing.`,
+		Example: "  bits export --format json --closed > backup.json\n" +
+			"  bits import file backup.json      # on the other machine\n" +
+			"  generate-plan | bits import file - --format csv",
+		Args: cobra.ExactArgs(1),
+		Run: func(_ *cobra.Command, args []string) {
+			store, err := getStore()
+			if err != nil {
+				printError(err)
+			}
+			tasks, err := readExport(args[0], format)
+			if err != nil {
+				printError(err)
+			}
+			lockStore(store)
+
+			b, err := newBatch(store)
+			if err != nil {
+				printError(err)
+			}
+			renamed, err := b.importTasks(tasks)
+			if err != nil {
+				printError(err)
+			}
+			if err = b.commit(); err != nil {
+				printError(err)
+			}
+			for _, t := range tasks {
+				if from, ok := renamed[t.ID]; ok {
+					printWarning(ImportRenamedError{From: from, To: t.ID})
+				}
+			}
+			printOutput(formatter.FormatTaskList(b.changed))
+		},
+	}
+	cmd.Flags().StringVar(&format, "format", "", "File format: json or csv (default from the extension)")
+	return cmd
+}
+
+// readExport decodes the tasks in the named export file, or stdin for "-".
+func readExport(path, format string) ([]*task.Task, error) {
+	if format == "" {
+		format = "json"
+		if strings.EqualFold(filepath.Ext(path), ".csv") {
+			format = "csv"
+		}
+	}
+	if format != "json" && format != "csv" {
+		return nil, InvalidOutputFormatError{Value: format, Valid: []string{"json", "csv"}}
+	}
+
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path) //nolint:gosec // G304: the file the user asked to import
+	}
+	if err != nil {
+		return nil, err
+	}
+	if format == "csv" {
+		return output.ParseTasksCSV(bytes.NewReader(data))
+	}
+	return output.ParseTaskList(data)
+}
+
+// importTasks stages tasks read from an export. Each keeps its ID if it is
+// free here and gets a new one otherwise; references to renamed tasks are
+// then rewritten and every reference checked. Returns the original IDs of the
+// renamed tasks, keyed by their new ones.
+func (b *batch) importTasks(tasks []*task.Task) (map[string]string, error) {
+	ids := make(map[string]string) // Original ID -> new ID
+	renamed := make(map[string]string)
+	seen := make(map[string]bool, len(tasks))
+	for i, t := range tasks {
+		from := t.ID
+		if from != "" && seen[from] {
+			return nil, ImportTaskError{Index: i + 1, Title: t.Title, Err: DuplicateImportIDError{ID: from}}
+		}
+		seen[from] = true
+		if err := b.importTask(t); err != nil {
+			return nil, ImportTaskError{Index: i + 1, Title: t.Title, Err: err}
+		}
+		if from != "" && from != t.ID {
+			ids[from] = t.ID
+			renamed[t.ID] = from
+		}
+	}
+
+	archived, err := b.store.ArchivedIDs()
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range tasks {
+		t.RemapIDs(ids)
+	}
+	graph := deps.NewGraph(slices.Collect(maps.Values(b.tasks)))
+	for i, t := range tasks {
+		refs := t.Dependencies()
+		for _, h := range t.After {
+			refs = append(refs, h.ID)
+		}
+		for _, l := range t.Links {
+			refs = append(refs, l.ID)
+		}
+		if t.Parent != "" {
+			refs = append(refs, t.Parent)
+		}
+		for _, ref := range refs {
+			if b.tasks[ref] == nil && !archived[ref] && !external.IsRef(ref) {
+				return nil, ImportTaskError{Index: i + 1, Title: t.Title, Err: UnknownImportReferenceError{Ref: ref}}
+			}
+		}
+		for _, dep := range t.Dependencies() {
+			if graph.WouldCreateCycle(t.ID, dep) {
+				return nil, ImportTaskError{Index: i + 1, Title: t.Title, Err: deps.CycleError{From: t.ID, To: dep}}
+			}
+		}
+	}
+	return renamed, nil
+}
+
+// importTask validates an exported task and stages it, giving it a new ID if
+// its own is taken or malformed. Lifecycle scripts don't run.
+func (b *batch) importTask(t *task.Task) error {
+	if t.Title == "" {
+		return MissingTitleError{}
+	}
+	if t.Status == "" {
+		t.Status = task.StatusOpen
+	}
+	if !task.IsValidStatus(t.Status) {
+		return InvalidTaskStatusError{Value: string(t.Status)}
+	}
+	if t.Priority == "" {
+		t.Priority = cfg.Defaults.TaskPriority()
+	}
+	if !task.IsValidPriority(t.Priority) {
+		return InvalidPriorityError{Value: string(t.Priority)}
+	}
+	var err error
+	if t.Tags, err = normalizeTags(t.Tags); err != nil {
+		return err
+	}
+	if err = cfg.Limits.Check(t); err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	if t.CreatedAt.IsZero() {
+		t.CreatedAt = now
+	}
+	if t.Status == task.StatusClosed && t.ClosedAt == nil {
+		t.ClosedAt = &now
+	}
+
+	keep := task.IsValidID(t.ID)
+	if keep {
+		if keep, err = b.store.ReserveID(t.ID); err != nil {
+			return err
+		}
+	}
+	if !keep {
+		fresh, newErr := b.store.NewTask(t.Title, "", t.Priority)
+		if newErr != nil {
+			return newErr
+		}
+		t.ID = fresh.ID
+	}
+	// Derived or stored beside the task file, so not carried over
+	t.Children, t.Attachments, t.BodyFile = nil, nil, ""
+	b.tasks[t.ID] = t
+	b.touch(t)
+	return nil
+}
+
+// jiraURLEnv, jiraEmailEnv, and jiraTokenEnv configure 'bits import jira'.
+const (
+	jiraURLEnv   = "JIRA_URL"
+	jiraEmailEnv = "JIRA_EMAIL"
+	jiraTokenEnv = "JIRA_API_TOKEN"
+)
+
+// importJiraCmd implements 'bits import jira'.
+func importJiraCmd() *cobra.Command {
+	var jql, site string
+	cmd := &cobra.Command{
+		Use:   "jira --jql <query>",
+		Short: "Import Jira issues as tasks",
+		Long: `Create a task for every Jira issue a JQL query matches, such as the stories
+of an epic, with the links between them as dependencies. The site is --url
+or $` + jiraURLEnv + `; Jira Cloud authenticates with $` + jiraEmailEnv + ` and an API
+token in $` + jiraTokenEnv + `, and Jira Data Center with a personal access token in
+$` + jiraTokenEnv + ` alone.
+
+Each task gets the issue's summary as its title, its description followed by
+a link back to the issue, its priority (Highest or Blocker is critical,
+Lowest or Trivial low), and its labels as tags. Issues in a done status
+import as closed. Between the imported issues, "is blocked by" links become
+dependencies, duplicates links duplicates, and other links relates-to; a
+parent, such as an epic, becomes the task's parent when it is imported too.
+Links to issues the query didn't match are dropped, and a link that would
+make a dependency cycle is skipped with a warning.
+
+The import is all or nothing, and lifecycle scripts don't run. Running it
+again imports the issues again.`,
+		Example: "  bits import jira --jql 'parent = ENG-100 ORDER BY rank'\n" +
+			"  bits import jira --url https://acme.atlassian.net --jql 'sprint in openSprints()' --dry-run",
+		Args: cobra.NoArgs,
+		Run: func(_ *cobra.Command, _ []string) {
+			store, err := getStore()
+			if err != nil {
+				printError(err)
+			}
+			if site == "" {
+				site = os.Getenv(jiraURLEnv)
+			}
+			if site == "" {
+				printError(MissingJiraURLError{})
+			}
+			client := jira.NewClient(site, os.Getenv(jiraEmailEnv), os.Getenv(jiraTokenEnv))
+			issues, err := client.Search(context.Background(), jql)
+			if err != nil {
+				printError(err)
+			}
+			lockStore(store)
+
+			b, err := newBatch(store)
+			if err != nil {
+				printError(err)
+			}
+			if err = b.importJira(client, issues); err != nil {
+				printError(err)
+			}
+			if err = b.commit(); err != nil {
+				printError(err)
+			}
+			printOutput(formatter.FormatTaskList(b.changed))
+		},
+	}
+	cmd.Flags().StringVar(&jql, "jql", "", "JQL query selecting the issues to import")
+	cmd.Flags().StringVar(&site, "url", "", "Jira site, such as https://acme.atlassian.net (default $"+jiraURLEnv+")")
+	_ = cmd.MarkFlagRequired("jql") // The flag is defined just above
+	return cmd
+}
+
+// importJira stages a task for each Jira issue, then the parents, dependencies,
+// and links between them.
+func (b *batch) importJira(client *jira.Client, issues []jira.Issue) error {
+	ids := make(map[string]string) // Issue key -> task ID
+	now := time.Now().UTC()
+	for _, issue := range issues {
+		if ids[issue.Key] != "" {
+			continue
+		}
+		var tags []string
+		for _, label := range issue.Fields.Labels {
+			if tag, ok := task.NormalizeTag(label); ok {
+				tags = append(tags, tag)
+			}
+		}
+		t, err := buildTask(b.store, issue.Fields.Summary, addOptions{
+			Description: strings.TrimSpace(issue.Fields.Description + "\n\n" + client.BrowseURL(issue.Key)),
+			Priority:    string(issue.TaskPriority()),
+			Tags:        tags,
+		})
+		if err != nil {
+			return JiraIssueError{Key: issue.Key, Err: err}
+		}
+		if issue.Done() {
+			t.Close(now, "Imported as done from "+client.BrowseURL(issue.Key), currentActor(b.store))
+		}
+		b.tasks[t.ID] = t
+		b.touch(t)
+		ids[issue.Key] = t.ID
+	}
+
+	for _, issue := range issues {
+		if parent := ids[issue.ParentKey()]; parent != "" {
+			b.tasks[ids[issue.Key]].Parent = parent
+		}
+	}
+	for _, e := range jira.Edges(issues) {
+		from, to := ids[e.From], ids[e.To]
+		if from == "" || to == "" {
+			continue
+		}
+		var opts depOptions
+		if !e.Type.Blocking() {
+			opts.Link = e.Type
+		}
+		err := b.depend(b.tasks[from], []string{to}, opts)
+		var cycle deps.CycleError
+		if errors.As(err, &cycle) {
+			printWarning(JiraIssueError{Key: e.From, Err: err})
+			continue
+		}
+		if err != nil {
+			return JiraIssueError{Key: e.From, Err: err}
+		}
+	}
+	return nil
+}
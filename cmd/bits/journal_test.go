@@ -0,0 +1,90 @@
+//nolint:testpackage // Tests require internal access for thorough testing
+package main
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/abatilo/bits/internal/rpc"
+	"github.com/abatilo/bits/internal/storage"
+	"github.com/abatilo/bits/internal/task"
+)
+
+// undoLatest undoes the newest journal entry as 'bits undo' would, from a
+// store of its own, and returns the entry undone.
+func undoLatest(t *testing.T, path string) *storage.JournalEntry {
+	t.Helper()
+	store := storage.NewStoreWithPath(path)
+	unlock, err := store.Lock()
+	if err != nil {
+		t.Fatalf("Lock failed: %v", err)
+	}
+	defer unlock()
+	store.BeginOp("undo", "tester")
+	entry, err := store.Undo(0, false)
+	if err != nil {
+		t.Fatalf("Undo failed: %v", err)
+	}
+	if err = store.EndOp(); err != nil {
+		t.Fatalf("EndOp failed: %v", err)
+	}
+	return entry
+}
+
+func TestUndoDaemonWrite(t *testing.T) {
+	store := newTestStore(t)
+	socket := daemonSocket(store)
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+	defer listener.Close()
+	go func() { _ = newRPCServer(store).ServeListener(listener) }()
+
+	client, err := rpc.Dial(socket, time.Second)
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	defer client.Close()
+	var created struct {
+		ID string `json:"id"`
+	}
+	if err = client.Call("create", createParams{Title: "A", Actor: "agent:one"}, &created); err != nil {
+		t.Fatalf("create failed: %v", err)
+	}
+
+	entry := undoLatest(t, store.BasePath())
+	if entry.Op != "add" || entry.Actor != "agent:one" {
+		t.Errorf("undone entry = %s by %s, want add by agent:one", entry.Op, entry.Actor)
+	}
+	if storage.NewStoreWithPath(store.BasePath()).Exists(created.ID) {
+		t.Errorf("task %s still exists after undoing its creation", created.ID)
+	}
+}
+
+func TestUndoHookClose(t *testing.T) {
+	store := newTestStore(t)
+	tk, err := store.CreateTask("A", "", task.PriorityMedium)
+	if err != nil {
+		t.Fatalf("CreateTask failed: %v", err)
+	}
+
+	sha := commitClosing(t, tk.ID+" Fixed")
+
+	// As 'bits githook post-commit' does
+	journalOp = "githook post-commit"
+	lockStore(store)
+	closed, err := closeFromCommit(store, sha)
+	unlockStore()
+	if err != nil || len(closed) != 1 {
+		t.Fatalf("closeFromCommit = %d task(s), %v; want A closed", len(closed), err)
+	}
+
+	if entry := undoLatest(t, store.BasePath()); entry.Op != journalOp {
+		t.Errorf("undone entry = %s, want %s", entry.Op, journalOp)
+	}
+	if loaded, _ := storage.NewStoreWithPath(store.BasePath()).Load(tk.ID); loaded.Status != task.StatusOpen {
+		t.Errorf("A status = %s after undoing the hook's close, want open", loaded.Status)
+	}
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/abatilo/bits/internal/script"
+	"github.com/abatilo/bits/internal/task"
+)
+
+const scriptSummaryLines = 3
+
+// runLifecycle runs the configured and then the task's own scripts for event,
+// then sends its webhooks. It is called after the task is saved, so failures
+// and timeouts are reported as warnings rather than undoing the change. Script
+// output goes to stderr. With --dry-run the scripts are only reported.
+func runLifecycle(event task.EventAction, t *task.Task) {
+	var commands []string
+	switch event {
+	case task.EventClaim:
+		commands = []string{cfg.Scripts.OnClaim, t.OnClaim}
+	case task.EventClose:
+		commands = []string{cfg.Scripts.OnClose, t.OnClose}
+	}
+
+	timeout, _ := cfg.Scripts.TimeoutDuration() // Validated when the config is loaded
+	env := lifecycleEnv(event, t)
+	for _, command := range commands {
+		if command == "" {
+			continue
+		}
+		if dryRun {
+			printDryRun("run %s script `%s`", event, command)
+			continue
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		result, err := script.Run(ctx, "", command, env, os.Stderr)
+		cancel()
+
+		var timeoutErr script.TimeoutError
+		switch {
+		case errors.As(err, &timeoutErr):
+			reason := "timed out after " + timeout.String()
+			printWarning(ScriptFailedError{Event: string(event), Command: command, Reason: reason})
+		case err != nil:
+			printWarning(ScriptFailedError{Event: string(event), Command: command, Reason: err.Error()})
+		case !result.OK():
+			reason := fmt.Sprintf("exited %d", result.ExitCode)
+			if summary := result.Summary(scriptSummaryLines); summary != "" {
+				reason += ": " + summary
+			}
+			printWarning(ScriptFailedError{Event: string(event), Command: command, Reason: reason})
+		}
+	}
+	sendWebhooks(string(event), t)
+}
+
+// lifecycleEnv returns the environment variables describing t to its scripts.
+func lifecycleEnv(event task.EventAction, t *task.Task) []string {
+	env := []string{
+		"BITS_EVENT=" + string(event),
+		"BITS_TASK_ID=" + t.ID,
+		"BITS_TASK_TITLE=" + t.Title,
+		"BITS_TASK_STATUS=" + string(t.Status),
+		"BITS_TASK_PRIORITY=" + string(t.Priority),
+		"BITS_TASK_TAGS=" + strings.Join(t.Tags, ","),
+		"BITS_TASK_BRANCH=" + t.Branch,
+	}
+	if last := t.LastEvent(event); last != nil {
+		env = append(env, "BITS_ACTOR="+last.Actor)
+	}
+	if t.CloseReason != nil {
+		env = append(env, "BITS_CLOSE_REASON="+*t.CloseReason)
+	}
+	return env
+}
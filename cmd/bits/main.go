@@ -1,24 +1,52 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/url"
 	"os"
+	"os/user"
+	"path/filepath"
 	"slices"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
 
+	"github.com/abatilo/bits/internal/config"
 	"github.com/abatilo/bits/internal/deps"
+	"github.com/abatilo/bits/internal/external"
 	"github.com/abatilo/bits/internal/output"
+	"github.com/abatilo/bits/internal/plan"
+	"github.com/abatilo/bits/internal/report"
+	"github.com/abatilo/bits/internal/script"
+	"github.com/abatilo/bits/internal/session"
 	"github.com/abatilo/bits/internal/storage"
 	"github.com/abatilo/bits/internal/task"
+	"github.com/abatilo/bits/internal/telemetry"
 )
 
-//nolint:gochecknoglobals // CLI flags and formatter are package-level by design
+//nolint:gochecknoglobals // CLI flags, config, formatter, tracing, and the store lock are package-level by design
 var (
-	jsonOutput bool
-	formatter  output.Formatter
+	jsonOutput    bool
+	outputFormat  string
+	absoluteTimes bool
+	wideOutput    bool
+	actorFlag     string
+	dryRun        bool
+	noHooks       bool
+	fieldsFlag    []string
+	queryFlag     string
+	formatter     output.Formatter
+	cfg           *config.Config
+	tracer        *telemetry.Tracer
+	exporter      *telemetry.Exporter
+	commandSpan   *telemetry.Span
+	releaseLock   func() // Set by lockStore
+	journalOp     string // The command as the journal records it, like "dep add"
 )
 
 func main() {
@@ -26,158 +54,841 @@ func main() {
 		Use:   "bits",
 		Short: "A minimal, file-based task tracker",
 		Long:  "bits - A minimal, file-based task tracker optimized for AI agents.",
-		PersistentPreRun: func(_ *cobra.Command, _ []string) {
-			if jsonOutput {
-				formatter = output.NewJSONFormatter()
-			} else {
-				formatter = output.NewHumanFormatter()
-			}
+		PersistentPreRun: func(cmd *cobra.Command, _ []string) {
+			setup()
+			commandSpan = tracer.Start(cmd.CommandPath())
+			journalOp = strings.TrimPrefix(cmd.CommandPath(), cmd.Root().Name()+" ")
+		},
+		PersistentPostRun: func(_ *cobra.Command, _ []string) {
+			unlockStore()
+			drainWebhooks()
+			finishTelemetry(nil)
 		},
 	}
 
-	rootCmd.PersistentFlags().BoolVar(&jsonOutput, "json", false, "Output in JSON format")
+	rootCmd.PersistentFlags().BoolVar(&jsonOutput, "json", false, "Output in JSON format (same as --output json)")
+	rootCmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", "",
+		"Output format: human, json, yaml (default human, or defaults.output)")
+	rootCmd.PersistentFlags().
+		BoolVar(&absoluteTimes, "absolute", false, "Show absolute timestamps instead of relative ages")
+	rootCmd.PersistentFlags().
+		StringVar(&actorFlag, "actor", "", "Who to record in task history (default: $BITS_ACTOR or the OS user)")
+	rootCmd.PersistentFlags().BoolVar(&wideOutput, "wide", false, "Don't truncate or wrap output to the terminal width")
+	rootCmd.PersistentFlags().
+		BoolVar(&dryRun, "dry-run", false, "Validate and report what would change without writing or running scripts")
+	rootCmd.PersistentFlags().BoolVar(&noHooks, "no-hooks", false, "Don't send the configured webhooks")
+	rootCmd.PersistentFlags().
+		StringSliceVar(&fieldsFlag, "fields", nil, "Keep only these fields of each object in structured output")
+	rootCmd.PersistentFlags().
+		StringVar(&queryFlag, "query", "", "Select part of structured output with a path like .[0].tags or .[].id")
 
 	rootCmd.AddCommand(
 		initCmd(),
 		addCmd(),
 		listCmd(),
 		showCmd(),
+		logCmd(),
+		editCmd(),
+		noteCmd(),
+		attachCmd(),
+		pathCmd(),
+		promptCmd(),
+		commitTrailerCmd(),
+		githookCmd(),
+		branchCmd(),
+		changelogCmd(),
+		reportCmd(),
+		statsCmd(),
+		worklogCmd(),
+		dueCmd(),
+		matrixCmd(),
+		remindCmd(),
+		escalateCmd(),
+		staleCmd(),
 		readyCmd(),
+		nextCmd(),
+		blockedCmd(),
+		graphCmd(),
+		criticalCmd(),
+		orderCmd(),
+		assignCmd(),
 		claimCmd(),
 		releaseCmd(),
 		closeCmd(),
+		acceptCmd(),
 		depCmd(),
 		undepCmd(),
+		batchCmd(),
+		applyCmd(),
+		scanCmd(),
+		planDiffCmd(),
+		exportCmd(),
+		importCmd(),
+		searchCmd(),
+		findCmd(),
 		pruneCmd(),
+		compactCmd(),
 		rmCmd(),
+		destroyCmd(),
+		undoCmd(),
+		historyCmd(),
 		sessionCmd(),
 		drainCmd(),
+		mergeDriverCmd(),
+		syncCmd(),
+		verifyCmd(),
+		doctorCmd(),
+		snapshotCmd(),
+		backupCmd(),
+		migrateHomeCmd(),
+		migrateKeyCmd(),
+		migrateCmd(),
+		pluginsCmd(),
+		rpcCmd(),
+		mcpCmd(),
+		daemonCmd(),
+		serveCmd(),
+		eventsCmd(),
 	)
 
+	dispatchPlugin(rootCmd, os.Args[1:])
 	if err := rootCmd.Execute(); err != nil {
 		os.Exit(1)
 	}
 }
 
+// setup loads the config and builds the formatter once global flags are parsed.
+func setup() {
+	var err, formatErr error
+	projectRoot, _ := storage.FindProjectRoot() // Outside a repository only the user config applies
+	cfg, err = config.Load(projectRoot)
+	formatter, formatErr = newFormatter()
+	if err == nil {
+		err = formatErr
+	}
+	if err != nil {
+		printError(err)
+	}
+	if exp, ok := telemetry.ExporterFromEnv(cfg.Telemetry.Endpoint); ok {
+		tracer = telemetry.NewTracer("bits")
+		exporter = exp
+	}
+}
+
+// finishTelemetry ends the command's span, marking it failed with err, and
+// exports the spans recorded so far. Export problems never fail the command.
+func finishTelemetry(err error) {
+	if tracer == nil {
+		return
+	}
+	commandSpan.RecordError(err)
+	commandSpan.End()
+	flushTelemetry()
+}
+
+// flushTelemetry exports the spans finished so far, if tracing is enabled.
+func flushTelemetry() {
+	if tracer == nil {
+		return
+	}
+	if err := exporter.Flush(context.Background(), tracer); err != nil {
+		printWarning(err)
+	}
+}
+
 func getStore() (*storage.Store, error) {
-	return storage.NewStore()
+	root, err := cfg.Storage.RootPath() // Validated when the config is loaded
+	if err != nil {
+		return nil, err
+	}
+	store, err := storage.NewStoreWithMode(root, storage.StoreMode(cfg.Storage.Mode))
+	if err != nil {
+		return nil, err
+	}
+	configureStore(store)
+	return store, nil
+}
+
+// configureStore applies the config file and global flags to store.
+func configureStore(store *storage.Store) {
+	store.SetModes(cfg.Permissions.DirMode(), cfg.Permissions.FileMode())
+	store.SetWIPLimits(storage.WIPLimits{Total: cfg.WIP.ActiveLimit(), PerAgent: cfg.WIP.AgentLimit()})
+	store.SetLockStrategy(storage.LockStrategy(cfg.Lock.StrategyMode()))
+	store.SetMinIDLength(cfg.IDs.MinLength)
+	store.SetTracer(tracer)
+	if dryRun {
+		store.SetDryRun(os.Stderr)
+	}
+}
+
+// currentActor identifies who is running the command, for task history:
+// --actor, then $BITS_ACTOR, then the session when run inside a Claude Code
+// session (CLAUDECODE is set), then the OS user.
+func currentActor(store *storage.Store) string {
+	if actorFlag != "" {
+		return actorFlag
+	}
+	if actor := os.Getenv("BITS_ACTOR"); actor != "" {
+		return actor
+	}
+	if os.Getenv("CLAUDECODE") != "" {
+		if sess, err := session.Load(store.BasePath()); err == nil {
+			return "session:" + sess.SessionID
+		}
+	}
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return task.UserActorPrefix + u.Username
+	}
+	if name := os.Getenv("USER"); name != "" {
+		return task.UserActorPrefix + name
+	}
+	return ""
+}
+
+// newFormatter builds the formatter selected by --output (or --json). An
+// unknown format yields a human formatter alongside the error so it can be reported.
+// --fields and --query project structured output, switching human output to JSON.
+func newFormatter() (output.Formatter, error) {
+	format := outputFormat
+	if format == "" {
+		format = "human"
+		if cfg != nil {
+			format = cfg.Defaults.OutputFormat()
+		}
+	}
+	if jsonOutput {
+		format = "json"
+	}
+
+	projection, err := output.NewProjection(queryFlag, fieldsFlag)
+	if err != nil {
+		return output.NewJSONFormatter(), err
+	}
+	if !projection.Empty() && format == "human" {
+		format = "json"
+	}
+
+	switch format {
+	case "json":
+		return output.NewJSONFormatter().Project(projection), nil
+	case "yaml":
+		return output.NewYAMLFormatter().Project(projection), nil
+	}
+
+	opts := output.HumanOptions{Absolute: absoluteTimes, ASCIIOnly: !output.UnicodeSupported()}
+	if !wideOutput {
+		opts.Width = output.TerminalWidth()
+	}
+	if cfg != nil {
+		opts.StatusGlyphs = cfg.Glyphs.StatusGlyphs()
+		opts.PriorityGlyphs = cfg.Glyphs.PriorityGlyphs()
+		opts.SLA, _ = cfg.SLA.Targets() // Validated when the config is loaded
+	}
+	human := output.NewHumanFormatter(opts)
+	if format != "human" {
+		return human, InvalidOutputFormatError{Value: format, Valid: []string{"human", "json", "yaml"}}
+	}
+	return human, nil
 }
 
 func printOutput(s string) {
 	os.Stdout.WriteString(s) //nolint:gosec // stdout write errors are unrecoverable
 }
 
+// printWarning reports a non-fatal problem on stderr, keeping stdout parseable.
+func printWarning(err error) {
+	os.Stderr.WriteString("Warning: " + err.Error() + "\n") //nolint:gosec // stderr write errors are unrecoverable
+}
+
+// lockStore takes the store's write lock for the rest of the command, so
+// concurrent writers such as 'bits batch' can't interleave with it. What the
+// command changes meanwhile is journaled as one entry for 'bits undo'. A store
+// already locked, by an earlier call or a server's locked handler, is left to
+// its holder.
+func lockStore(store *storage.Store) {
+	if store.Locked() {
+		return
+	}
+	unlock, err := store.Lock()
+	if err != nil {
+		printError(err)
+	}
+	if releaseLock == nil {
+		store.BeginOp(journalOp, currentActor(store))
+		releaseLock = func() {
+			if endErr := store.EndOp(); endErr != nil {
+				printWarning(endErr)
+			}
+			unlock()
+		}
+	}
+}
+
+// unlockStore releases the lock taken by lockStore. An flock dies with the
+// process, but an owner file (see 'lock.strategy') would hold up other hosts
+// until it went stale.
+func unlockStore() {
+	if releaseLock != nil {
+		releaseLock()
+		releaseLock = nil
+	}
+}
+
+// printDryRun reports on stderr a side effect that --dry-run skipped.
+func printDryRun(format string, args ...any) {
+	fmt.Fprintf(os.Stderr, "Dry run: would "+format+"\n", args...)
+}
+
 func printError(err error) {
 	os.Stdout.WriteString(formatter.FormatError(err)) //nolint:gosec // stdout write errors are unrecoverable
+	unlockStore()
+	drainWebhooks()
+	finishTelemetry(err)
 	os.Exit(1)
 }
 
 // initCmd implements 'bits init'.
 func initCmd() *cobra.Command {
-	var force bool
+	var force, local, home bool
+	var from string
 	cmd := &cobra.Command{
 		Use:   "init",
 		Short: "Initialize bits task directory",
+		Long: `Initialize the bits task directory for the current project.
+
+Tasks live under the data directory (~/.bits or $XDG_DATA_HOME/bits) by
+default, private to this machine. With --local they live in the project's own
+.bits directory instead, to be committed and shared with teammates. Its
+.gitignore keeps locks, the index, snapshots, and sessions out of git, so only
+the task files are shared. --home picks the data directory explicitly. Run in
+a terminal with neither flag, before the project has a store or a recorded
+choice, init asks which to use.
+
+The choice is recorded as storage.mode in the project's .bits.yaml, and every
+bits command run in the project then uses that store. Tasks already in the
+other store stay there; move them with 'bits export --format json' and 'bits
+import file'.
+
+With --from, the store is also seeded from a plan file in the same step, as
+'bits apply' would, and the tasks created are listed. The plan is validated
+before anything is written, so project templates can ship a ready-made
+backlog. See 'bits apply --help' for the plan format.`,
 		Run: func(_ *cobra.Command, _ []string) {
 			store, err := getStore()
 			if err != nil {
 				printError(err)
 			}
+			mode := chooseStoreMode(store, local, home)
+			if mode != "" {
+				if store, err = switchStore(store, mode); err != nil {
+					printError(err)
+				}
+			}
+			var seed *plan.Plan
+			if from != "" {
+				if seed, err = loadPlan(from); err != nil {
+					printError(err)
+				}
+			}
 			if force {
+				if store.IsInitialized() {
+					lockStore(store)
+					if _, err = store.Snapshot("init"); err != nil {
+						printError(err)
+					}
+				}
 				if err = store.Init(true); err != nil {
 					printError(err)
 				}
-				printOutput(formatter.FormatMessage(fmt.Sprintf("Reinitialized bits at %s", store.BasePath())))
-			} else {
-				// Ensure initialized (implicit init)
-				if err = store.EnsureInitialized(); err != nil {
+			} else if err = store.EnsureInitialized(); err != nil { // Implicit init
+				printError(err)
+			}
+			if store.IsLocal() {
+				if err = store.InitLocal(); err != nil {
 					printError(err)
 				}
+			}
+			if mode != "" {
+				recordStoreMode(mode)
+			}
+
+			if seed != nil {
+				lockStore(store)
+				changes, applyErr := applyPlan(store, seed)
+				if applyErr != nil {
+					printError(applyErr)
+				}
+				printOutput(formatter.FormatPlan(changes))
+				return
+			}
+			if force && dryRun {
+				printOutput(formatter.FormatMessage(fmt.Sprintf("Would reinitialize bits at %s", store.BasePath())))
+			} else if force {
+				printOutput(formatter.FormatMessage(fmt.Sprintf("Reinitialized bits at %s", store.BasePath())))
+			} else {
 				printOutput(formatter.FormatMessage(fmt.Sprintf("bits storage: %s", store.BasePath())))
 			}
 		},
 	}
 	cmd.Flags().BoolVarP(&force, "force", "f", false, "Wipe and reinitialize")
+	cmd.Flags().StringVar(&from, "from", "", "Seed the store with the tasks in a plan file")
+	cmd.Flags().BoolVar(&local, "local", false, "Keep tasks in the project's .bits directory, to commit and share")
+	cmd.Flags().BoolVar(&home, "home", false, "Keep tasks under the data directory, private to this machine")
+	cmd.MarkFlagsMutuallyExclusive("local", "home")
 	return cmd
 }
 
+// chooseStoreMode returns the store mode 'bits init' should record: the one
+// --local or --home names, else the user's answer when init runs in a
+// terminal for a project with no store or recorded mode yet. It returns ""
+// when there's nothing to decide.
+func chooseStoreMode(store *storage.Store, local, home bool) storage.StoreMode {
+	switch {
+	case local:
+		return storage.StoreLocal
+	case home:
+		return storage.StoreHome
+	case cfg.Storage.Mode != "" || store.IsInitialized() || !stdinIsTerminal():
+		return ""
+	case confirm("Keep tasks in the project's .bits directory, to commit and share with teammates?"):
+		return storage.StoreLocal
+	default:
+		return storage.StoreHome
+	}
+}
+
+// switchStore returns the project's store for mode in place of store,
+// warning about any tasks that stay behind in store.
+func switchStore(store *storage.Store, mode storage.StoreMode) (*storage.Store, error) {
+	root, err := cfg.Storage.RootPath()
+	if err != nil {
+		return nil, err
+	}
+	next, err := storage.NewStoreWithMode(root, mode)
+	if err != nil {
+		return nil, err
+	}
+	if next.BasePath() == store.BasePath() {
+		return store, nil
+	}
+	if store.IsInitialized() {
+		if ids, idsErr := store.AllIDs(); idsErr == nil && len(ids) > 0 {
+			printWarning(StoreLeftBehindError{Path: store.BasePath(), Count: len(ids)})
+		}
+	}
+	configureStore(next)
+	return next, nil
+}
+
+// recordStoreMode writes mode to the project's .bits.yaml so later commands
+// open the same store.
+func recordStoreMode(mode storage.StoreMode) {
+	projectRoot, err := storage.FindProjectRoot()
+	if err != nil {
+		printError(err)
+	}
+	if dryRun {
+		printDryRun("record storage.mode: %s in %s", mode, filepath.Join(projectRoot, config.ProjectFileName))
+		return
+	}
+	if err = config.SaveStorageMode(projectRoot, string(mode)); err != nil {
+		printError(err)
+	}
+}
+
+// migrateHomeCmd implements 'bits migrate-home'.
+func migrateHomeCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "migrate-home",
+		Short: "Move ~/.bits to the XDG data directory",
+		Run: func(_ *cobra.Command, _ []string) {
+			from, to, err := storage.MigrateHome()
+			if err != nil {
+				printError(err)
+			}
+			printOutput(formatter.FormatMessage(fmt.Sprintf("Moved %s to %s", from, to)))
+		},
+	}
+}
+
+// migrateKeyCmd implements 'bits migrate-key'.
+func migrateKeyCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "migrate-key",
+		Short: "Rename the task directory to its collision-safe name",
+		Long: `Rename the project's task directory, and its backups, from the sanitized
+project path older versions of bits used to the sanitized path plus a hash of
+the project path. Projects such as /a/b-c and /a/b/c otherwise share one store.
+Until this is run, bits keeps using the old directory.`,
+		Args: cobra.NoArgs,
+		Run: func(_ *cobra.Command, _ []string) {
+			from, to, err := storage.MigrateStoreKey(dryRun)
+			if err != nil {
+				printError(err)
+			}
+			if dryRun {
+				printOutput(formatter.FormatMessage(fmt.Sprintf("Would move %s to %s", from, to)))
+				return
+			}
+			printOutput(formatter.FormatMessage(fmt.Sprintf("Moved %s to %s", from, to)))
+		},
+	}
+}
+
+// migrateCmd implements 'bits migrate'.
+func migrateCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "migrate",
+		Short: "Upgrade task files to the current schema",
+		Long: "Rewrite task files written by an older bits in the current format, and move\n" +
+			"closed tasks saved before they were kept apart into closed/. Older files\n" +
+			"remain readable without migrating; this makes the upgrade permanent so the\n" +
+			"files diff cleanly. A snapshot is taken first.",
+		Args: cobra.NoArgs,
+		Run: func(_ *cobra.Command, _ []string) {
+			store, err := getStore()
+			if err != nil {
+				printError(err)
+			}
+			lockStore(store)
+
+			tasks, err := store.Outdated()
+			if err != nil {
+				printError(err)
+			}
+			if len(tasks) == 0 {
+				printOutput(formatter.FormatMessage(
+					fmt.Sprintf("All task files are at schema %d", storage.SchemaVersion)))
+				return
+			}
+
+			if _, err = store.Snapshot("migrate"); err != nil {
+				printError(err)
+			}
+			for _, t := range tasks {
+				if err = store.Rewrite(t); err != nil {
+					printError(err)
+				}
+			}
+			verb := "Migrated"
+			if dryRun {
+				verb = "Would migrate"
+			}
+			printOutput(formatter.FormatMessage(
+				fmt.Sprintf("%s %d task file(s) to schema %d", verb, len(tasks), storage.SchemaVersion)))
+		},
+	}
+}
+
 // addCmd implements 'bits add'.
 func addCmd() *cobra.Command {
-	var description string
-	var priority string
+	var opts addOptions
+	var allowDuplicate bool
 	cmd := &cobra.Command{
 		Use:   "add <title>",
 		Short: "Add a new task",
-		Args:  cobra.ExactArgs(1),
+		Long: `Add a new open task.
+
+If an open or active task has a similar title, bits warns and prints its ID,
+since re-filing work that is already tracked is an easy mistake. Set
+duplicates.on_add to block in the config file to refuse such adds instead.
+
+Without --assignee, the first assign.rules entry in the config file matching
+the task's tags or title scope (as in "fix(ui): ...") picks its assignee.`,
+		Args: cobra.ExactArgs(1),
 		Run: func(_ *cobra.Command, args []string) {
 			store, err := getStore()
 			if err != nil {
 				printError(err)
 			}
+			if !allowDuplicate {
+				if err = checkDuplicate(store, args[0]); err != nil {
+					printError(err)
+				}
+			}
 
-			p := task.Priority(priority)
-			if !task.IsValidPriority(p) {
-				printError(InvalidPriorityError{Value: priority})
+			if t, ok := daemonTask(store, "create", createParams{
+				Title: args[0], addOptions: opts, Actor: currentActor(store), NoHooks: noHooks,
+			}); ok {
+				printOutput(formatter.FormatTask(t))
+				return
 			}
 
-			t, err := store.CreateTask(args[0], description, p)
+			lockStore(store)
+			t, err := addTask(store, args[0], opts)
 			if err != nil {
 				printError(err)
 			}
 			printOutput(formatter.FormatTask(t))
 		},
 	}
-	cmd.Flags().StringVarP(&description, "description", "d", "", "Task description")
-	cmd.Flags().StringVarP(&priority, "priority", "p", "medium", "Priority (critical, high, medium, low)")
+	cmd.Flags().StringVarP(&opts.Description, "description", "d", "", "Task description")
+	cmd.Flags().StringVarP(&opts.Priority, "priority", "p", "",
+		"Priority: critical, high, medium, or low (default medium, or defaults.priority)")
+	cmd.Flags().StringArrayVarP(&opts.Tags, "tag", "t", nil, "Tag (label) for the task; repeatable")
+	cmd.Flags().StringVar(&opts.Due, "due", "", "Due date (2006-01-02) or duration from now (3d, 2w)")
+	cmd.Flags().StringVar(&opts.Verify, "verify", "", "Shell command that must pass before the task can be closed")
+	cmd.Flags().StringVar(&opts.OnClaim, "on-claim", "", "Shell command to run after the task is claimed")
+	cmd.Flags().StringVar(&opts.OnClose, "on-close", "", "Shell command to run after the task is closed")
+	cmd.Flags().StringArrayVar(&opts.Accept, "accept", nil,
+		"Acceptance criterion to check off before closing; repeatable")
+	cmd.Flags().StringVar(&opts.Estimate, "estimate", "", "Expected working time (90m, 4h, 2d)")
+	cmd.Flags().StringVar(&opts.Assignee, "assignee", "", "Agent or person to assign; overrides assign.rules")
+	cmd.Flags().StringVar(&opts.Parent, "parent", "", "Make the task a subtask of this task ID")
+	cmd.Flags().BoolVar(&allowDuplicate, "allow-duplicate", false,
+		"Add the task even if an unfinished task has a similar title")
 	return cmd
 }
 
+// checkDuplicate looks for unfinished tasks with a title similar to title,
+// warning about them or, with duplicates.on_add set to block, refusing.
+func checkDuplicate(store *storage.Store, title string) error {
+	mode := cfg.Duplicates.OnAddMode()
+	if mode == config.DuplicatesOff {
+		return nil
+	}
+	tasks, err := store.List(storage.StatusFilter{Open: true, Active: true})
+	if err != nil {
+		return err
+	}
+	var matches []*task.Task
+	for _, t := range tasks {
+		if task.SimilarTitles(title, t.Title) {
+			matches = append(matches, t)
+		}
+	}
+	if len(matches) == 0 {
+		return nil
+	}
+	dupErr := DuplicateTaskError{Title: title, Matches: matches, Blocked: mode == config.DuplicatesBlock}
+	if dupErr.Blocked {
+		return dupErr
+	}
+	printWarning(dupErr)
+	return nil
+}
+
+// addOptions holds the optional fields of a new task.
+type addOptions struct {
+	Description string   `json:"description" yaml:"description"`
+	Priority    string   `json:"priority"    yaml:"priority"`
+	Tags        []string `json:"tags"        yaml:"tags"`
+	Due         string   `json:"due"         yaml:"due"`
+	Verify      string   `json:"verify"      yaml:"verify"`
+	OnClaim     string   `json:"on_claim"    yaml:"on_claim"`
+	OnClose     string   `json:"on_close"    yaml:"on_close"`
+	Accept      []string `json:"accept"      yaml:"accept"`
+	Assignee    string   `json:"assignee"    yaml:"assignee"`
+	Estimate    string   `json:"estimate"    yaml:"estimate"`
+	Parent      string   `json:"parent"      yaml:"parent"`
+}
+
+// addTask creates and saves a new task. An empty priority means medium.
+func addTask(store *storage.Store, title string, opts addOptions) (*task.Task, error) {
+	t, err := buildTask(store, title, opts)
+	if err != nil {
+		return nil, err
+	}
+	if err = store.Save(t); err != nil {
+		return nil, err
+	}
+	sendWebhooks(webhookCreate, t)
+	return t, nil
+}
+
+// buildTask returns a new task with a fresh ID and the given options, without
+// saving it.
+func buildTask(store *storage.Store, title string, opts addOptions) (*task.Task, error) {
+	p := task.Priority(opts.Priority)
+	if p == "" {
+		p = cfg.Defaults.TaskPriority()
+	}
+	if !task.IsValidPriority(p) {
+		return nil, InvalidPriorityError{Value: opts.Priority}
+	}
+	if err := cfg.Limits.Check(&task.Task{Title: title, Description: opts.Description}); err != nil {
+		return nil, err
+	}
+	if opts.Parent != "" && !store.Exists(opts.Parent) {
+		return nil, storage.TaskNotFoundError{ID: opts.Parent}
+	}
+
+	t, err := store.NewTask(title, opts.Description, p)
+	if err != nil {
+		return nil, err
+	}
+	if t.Tags, err = normalizeTags(opts.Tags); err != nil {
+		return nil, err
+	}
+	t.Verify = opts.Verify
+	t.OnClaim = opts.OnClaim
+	t.OnClose = opts.OnClose
+	for _, text := range opts.Accept {
+		t.AddCriterion(text)
+	}
+	if opts.Due != "" {
+		dueAt, dueErr := report.ParseDue(opts.Due, time.Now().UTC())
+		if dueErr != nil {
+			return nil, dueErr
+		}
+		t.DueAt = &dueAt
+	}
+	if opts.Estimate != "" {
+		if t.Estimate, err = report.ParseAge(opts.Estimate); err != nil {
+			return nil, err
+		}
+	}
+	t.Assignee = opts.Assignee
+	t.Parent = opts.Parent
+	if t.Assignee == "" {
+		t.Assignee = cfg.Assign.Route(t)
+	}
+	return t, nil
+}
+
+// normalizeTags normalizes raw tags, dropping duplicates.
+func normalizeTags(raw []string) ([]string, error) {
+	var tags []string
+	for _, r := range raw {
+		tag, ok := task.NormalizeTag(r)
+		if !ok {
+			return nil, InvalidTagError{Value: r}
+		}
+		if !slices.Contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	return tags, nil
+}
+
 // listCmd implements 'bits list'.
 func listCmd() *cobra.Command {
-	var showOpen, showActive, showClosed bool
+	var showOpen, showActive, showClosed, overdue bool
+	var order string
+	var rawTags []string
 	cmd := &cobra.Command{
 		Use:     "list",
 		Aliases: []string{"ls"},
 		Short:   "List tasks",
+		Long: `List tasks with optional status and tag filters. Repeating --tag lists
+only tasks with every tag given, and --overdue only unfinished tasks past their
+due date.
+
+--order selects the sort:
+  ready     unblocked tasks first, then blocked ones in dependency order (default)
+  priority  priority, then creation time, ignoring dependencies
+  created   oldest first`,
 		Run: func(_ *cobra.Command, _ []string) {
 			store, err := getStore()
 			if err != nil {
 				printError(err)
 			}
 
-			// Load all tasks to build complete dependency graph
-			allTasks, err := store.List(storage.StatusFilter{})
+			tags, err := normalizeTags(rawTags)
 			if err != nil {
 				printError(err)
 			}
-
-			graph := deps.NewGraph(allTasks)
-
-			// Apply status filter
 			filter := storage.StatusFilter{
 				Open:   showOpen,
 				Active: showActive,
 				Closed: showClosed,
 			}
-			var filtered []*task.Task
-			for _, t := range allTasks {
-				if filter.Matches(t.Status) {
-					filtered = append(filtered, t)
-				}
+			params := listParams{StatusFilter: filter, Order: order, Tags: tags, Overdue: overdue}
+			if tasks, ok := daemonTasks(store, "list", params); ok {
+				printOutput(formatter.FormatTaskList(tasks))
+				return
 			}
 
-			// Sort: unblocked first, then priority, then created_at
-			graph.SortByReadiness(filtered)
-
-			printOutput(formatter.FormatTaskList(filtered))
+			tasks, err := listTasks(store, filter, order)
+			if err != nil {
+				printError(err)
+			}
+			tasks = withTags(tasks, tags)
+			if overdue {
+				tasks = overdueTasks(tasks, time.Now())
+			}
+			printOutput(formatter.FormatTaskList(tasks))
 		},
 	}
 	cmd.Flags().BoolVar(&showOpen, "open", false, "Show only open tasks")
 	cmd.Flags().BoolVar(&showActive, "active", false, "Show only active tasks")
 	cmd.Flags().BoolVar(&showClosed, "closed", false, "Show only closed tasks")
+	cmd.Flags().StringVar(&order, "order", "ready", "Sort order: ready, priority, or created")
+	cmd.Flags().StringArrayVarP(&rawTags, "tag", "t", nil, "Show only tasks with this tag; repeatable")
+	cmd.Flags().BoolVar(&overdue, "overdue", false, "Show only unfinished tasks past their due date")
 	return cmd
 }
 
+// overdueTasks returns the tasks overdue at now, keeping their order.
+func overdueTasks(tasks []*task.Task, now time.Time) []*task.Task {
+	var overdue []*task.Task
+	for _, t := range tasks {
+		if t.IsOverdue(now) {
+			overdue = append(overdue, t)
+		}
+	}
+	return overdue
+}
+
+// withTags returns the tasks that have every one of tags, keeping their order.
+func withTags(tasks []*task.Task, tags []string) []*task.Task {
+	if len(tags) == 0 {
+		return tasks
+	}
+	var tagged []*task.Task
+	for _, t := range tasks {
+		if t.HasTags(tags) {
+			tagged = append(tagged, t)
+		}
+	}
+	return tagged
+}
+
+// listTasks returns the tasks matching filter, sorted by order (ready,
+// priority, or created).
+func listTasks(store *storage.Store, filter storage.StatusFilter, order string) ([]*task.Task, error) {
+	// Closed dependencies never block, so closed tasks are only read when listed
+	load := storage.StatusFilter{}
+	if !filter.Matches(task.StatusClosed) {
+		load = storage.StatusFilter{Open: true, Active: true}
+	}
+	allTasks, err := store.List(load)
+	if err != nil {
+		return nil, err
+	}
+	if err = autoEscalate(store, allTasks); err != nil {
+		return nil, err
+	}
+	if err = notifySLABreaches(store, allTasks); err != nil {
+		return nil, err
+	}
+
+	var filtered []*task.Task
+	for _, t := range allTasks {
+		if filter.Matches(t.Status) {
+			filtered = append(filtered, t)
+		}
+	}
+
+	switch order {
+	case "ready":
+		// Unblocked first, then ordering hints, priority, and created_at
+		graph := newGraph(store, allTasks)
+		span := tracer.Start("graph.sort")
+		graph.SortByReadiness(filtered)
+		span.End()
+	case "priority":
+		deps.SortByPriority(filtered)
+	case "created":
+		sort.SliceStable(filtered, func(i, j int) bool {
+			return filtered[i].CreatedAt.Before(filtered[j].CreatedAt)
+		})
+	default:
+		return nil, InvalidOrderError{Value: order}
+	}
+	return filtered, nil
+}
+
 // showCmd implements 'bits show'.
 func showCmd() *cobra.Command {
 	return &cobra.Command{
@@ -190,7 +901,12 @@ func showCmd() *cobra.Command {
 				printError(err)
 			}
 
-			t, err := store.Load(args[0])
+			if t, ok := daemonTask(store, "show", idParams{ID: args[0]}); ok {
+				printOutput(formatter.FormatTask(t))
+				return
+			}
+
+			t, err := loadWithChildren(store, args[0])
 			if err != nil {
 				printError(err)
 			}
@@ -199,78 +915,340 @@ func showCmd() *cobra.Command {
 	}
 }
 
+// logCmd implements 'bits log'.
+func logCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "log <id>",
+		Short: "Show when a task was created, claimed, released, and closed",
+		Long: `Show a task's lifecycle, oldest first: when it was created, then each claim,
+release, and close with who made it (an agent session, --actor, or the OS
+user) and any note, such as constraints a forced claim overrode. Tasks moved
+out by 'bits compact' are found in the archive.`,
+		Args: cobra.ExactArgs(1),
+		Run: func(_ *cobra.Command, args []string) {
+			store, err := getStore()
+			if err != nil {
+				printError(err)
+			}
+
+			t, err := loadOrArchived(store, args[0])
+			if err != nil {
+				printError(err)
+			}
+			printOutput(formatter.FormatTaskLog(t))
+		},
+	}
+}
+
+// loadOrArchived loads a task, falling back to the archive for one that
+// compaction moved out of the store.
+func loadOrArchived(store *storage.Store, id string) (*task.Task, error) {
+	t, err := store.Load(id)
+	if !errors.As(err, &storage.TaskNotFoundError{}) {
+		return t, err
+	}
+	archived, archiveErr := store.Archived()
+	if archiveErr != nil {
+		return nil, archiveErr
+	}
+	for _, at := range archived {
+		if at.ID == id {
+			return at, nil
+		}
+	}
+	return nil, err
+}
+
+// loadWithChildren loads a task with its subtasks filled in.
+func loadWithChildren(store *storage.Store, id string) (*task.Task, error) {
+	t, err := store.Load(id)
+	if err != nil {
+		return nil, err
+	}
+	if t.Children, err = store.Children(t.ID, storage.StatusFilter{}); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// pathCmd implements 'bits path'.
+func pathCmd() *cobra.Command {
+	var uri string
+	cmd := &cobra.Command{
+		Use:   "path <id>",
+		Short: "Print the file path or URI of a task",
+		Long: `Print the absolute path of a task's markdown file.
+
+With --uri file, print a file:// URI instead. With --uri bits, print a
+bits://<store>/<id> URI that identifies the task independent of where the
+store lives on disk.`,
+		Args: cobra.ExactArgs(1),
+		Run: func(_ *cobra.Command, args []string) {
+			store, err := getStore()
+			if err != nil {
+				printError(err)
+			}
+
+			path, err := store.TaskPath(args[0])
+			if err != nil {
+				printError(err)
+			}
+
+			switch uri {
+			case "":
+			case "file":
+				slashed := filepath.ToSlash(path)
+				if !strings.HasPrefix(slashed, "/") {
+					slashed = "/" + slashed // Windows drive paths need a leading slash
+				}
+				path = (&url.URL{Scheme: "file", Path: slashed}).String()
+			case "bits":
+				path = taskURI(store, args[0])
+			default:
+				printError(InvalidURISchemeError{Value: uri})
+			}
+			printOutput(formatter.FormatMessage(path))
+		},
+	}
+	cmd.Flags().StringVar(&uri, "uri", "", "Print a URI instead of a path: file or bits")
+	return cmd
+}
+
+// taskURI returns the bits://<store>/<id> URI of the task id.
+func taskURI(store *storage.Store, id string) string {
+	return (&url.URL{Scheme: "bits", Host: filepath.Base(store.BasePath()), Path: "/" + id}).String()
+}
+
+// promptCmd implements 'bits prompt'.
+func promptCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "prompt",
+		Short: "Print a compact status segment for shell prompts",
+		Long: `Print open and active task counts and the active task ID, e.g. "3o/1a abc123",
+for use in shell prompts such as starship or powerlevel10k.
+
+Counts come from the index, so no task files are parsed. Outside a git
+repository or an initialized bits project, nothing is printed.`,
+		Run: func(_ *cobra.Command, _ []string) {
+			store, err := getStore()
+			if err != nil || !store.IsInitialized() {
+				return
+			}
+
+			summary, err := store.Summary()
+			if err != nil {
+				return
+			}
+			printOutput(formatter.FormatSummary(summary))
+		},
+	}
+}
+
 // readyCmd implements 'bits ready'.
 func readyCmd() *cobra.Command {
-	return &cobra.Command{
+	var checkExternal bool
+	cmd := &cobra.Command{
 		Use:   "ready",
 		Short: "List tasks ready to be worked on",
+		Long: `List open tasks whose dependencies are all closed, overdue ones first.
+Dependencies on GitHub issue or pull request URLs block until they are known
+to be closed or merged; --check-external queries GitHub ($GITHUB_TOKEN or
+$GH_TOKEN) and caches the result for later commands.`,
 		Run: func(_ *cobra.Command, _ []string) {
 			store, err := getStore()
 			if err != nil {
 				printError(err)
 			}
 
-			tasks, err := store.List(storage.StatusFilter{})
+			if !checkExternal {
+				if ready, ok := daemonTasks(store, "ready", struct{}{}); ok {
+					printOutput(formatter.FormatTaskList(ready))
+					return
+				}
+			}
+
+			// Closed dependencies never block, so closed tasks needn't be read
+			tasks, err := store.List(storage.StatusFilter{Open: true, Active: true})
 			if err != nil {
 				printError(err)
 			}
+			if err = autoEscalate(store, tasks); err != nil {
+				printError(err)
+			}
+			if err = notifySLABreaches(store, tasks); err != nil {
+				printError(err)
+			}
 
-			graph := deps.NewGraph(tasks)
+			if checkExternal {
+				if err = refreshExternal(store, tasks); err != nil {
+					printError(err)
+				}
+			}
+
+			graph := newGraph(store, tasks)
 			ready := graph.Ready()
 			printOutput(formatter.FormatTaskList(ready))
 		},
 	}
+	cmd.Flags().BoolVar(&checkExternal, "check-external", false,
+		"Refresh the state of GitHub issue and PR dependencies")
+	return cmd
 }
 
 // claimCmd implements 'bits claim'.
 func claimCmd() *cobra.Command {
-	return &cobra.Command{
-		Use:   "claim <id>",
-		Short: "Claim a task (mark as active)",
-		Args:  cobra.ExactArgs(1),
+	var force bool
+	var sel selector
+	cmd := &cobra.Command{
+		Use:   "claim <id>...",
+		Short: "Claim tasks (mark as active)",
+		Long: `Claim an open task. It must not be blocked by open dependencies, and claiming
+it must not put more tasks in progress than wip.limit in the config file
+allows (default 1, so no other task may be active). Claims by an agent
+identity (--actor, $BITS_ACTOR, or a session) count only the agent's own
+active tasks, against wip.per_agent (default 1).
+
+--force overrides both checks, for when a blocker is stale or another task
+must run alongside the active ones. The override is recorded in the task's
+history.
+
+Several IDs, or --all-matching for every open task whose text contains a
+query, are claimed together: if any claim fails, none is made. Each claim
+counts toward the WIP limits of the next, so this usually needs --force.`,
+		Args: sel.args(0),
 		Run: func(_ *cobra.Command, args []string) {
 			store, err := getStore()
 			if err != nil {
 				printError(err)
 			}
-
-			t, err := store.Load(args[0])
-			if err != nil {
-				printError(err)
+			if sel.bulk(args) {
+				lockStore(store)
+				claimed, bulkErr := bulkClaim(store, &sel, args, force)
+				if bulkErr != nil {
+					printError(bulkErr)
+				}
+				printOutput(formatter.FormatTaskList(claimed))
+				return
 			}
 
-			if t.Status != task.StatusOpen {
-				printError(InvalidStatusError{
-					ID:       t.ID,
-					Current:  string(t.Status),
-					Expected: string(task.StatusOpen),
-				})
+			params := claimParams{ID: args[0], Force: force, Actor: currentActor(store), NoHooks: noHooks}
+			if t, ok := daemonTask(store, "claim", params); ok {
+				printOutput(formatter.FormatTask(t))
+				return
 			}
 
-			// Check dependencies and active tasks
-			tasks, err := store.List(storage.StatusFilter{})
+			lockStore(store)
+			t, err := store.Load(args[0])
 			if err != nil {
 				printError(err)
 			}
 
-			// Check if another task is already active
-			if active := task.FindActive(tasks); active != nil {
-				printError(ActiveTaskExistsError{ID: active.ID, Title: active.Title})
-			}
-
-			graph := deps.NewGraph(tasks)
-			blockers := graph.BlockedBy(t.ID)
-			if len(blockers) > 0 {
-				printError(deps.BlockedError{ID: t.ID, BlockedBy: blockers})
-			}
-
-			t.Status = task.StatusActive
-			if err = store.Save(t); err != nil {
+			if err = claimTask(store, t, force); err != nil {
 				printError(err)
 			}
 			printOutput(formatter.FormatTask(t))
 		},
 	}
+	cmd.Flags().BoolVarP(&force, "force", "f", false, "Claim even if blocked or the WIP limit is reached")
+	sel.addFlag(cmd, "open task")
+	return cmd
+}
+
+// newGraph builds the dependency graph for tasks, with external dependencies
+// resolved from the store's cache.
+func newGraph(store *storage.Store, tasks []*task.Task) *deps.Graph {
+	defer tracer.Start("graph.build", telemetry.Int("bits.tasks", len(tasks))).End()
+	graph := deps.NewGraph(tasks)
+	graph.SetExternalClosed(external.LoadCache(store.BasePath()).Closed())
+	return graph
+}
+
+// refreshExternal queries GitHub for the external dependencies of unfinished
+// tasks and saves their state to the store's cache.
+func refreshExternal(store *storage.Store, tasks []*task.Task) error {
+	var refs []string
+	for _, t := range tasks {
+		if t.Status == task.StatusClosed {
+			continue
+		}
+		for _, dep := range t.Dependencies() {
+			if external.IsRef(dep) {
+				refs = append(refs, dep)
+			}
+		}
+	}
+	if len(refs) == 0 {
+		return nil
+	}
+
+	cache := external.LoadCache(store.BasePath())
+	if err := cache.Refresh(context.Background(), external.NewChecker(), refs, time.Now().UTC()); err != nil {
+		return err
+	}
+	return cache.Save(store.BasePath())
+}
+
+// claimTask marks an open task active after checking that the store's WIP
+// limit leaves room for it and that its dependencies are closed. With force, failed checks are
+// overridden and recorded in the task's history instead.
+func claimTask(store *storage.Store, t *task.Task, force bool) error {
+	// Check dependencies and active tasks; closed ones can't block or be active
+	tasks, err := store.List(storage.StatusFilter{Open: true, Active: true})
+	if err != nil {
+		return err
+	}
+	if err = stageClaim(store, t, tasks, force); err != nil {
+		return err
+	}
+	if err = store.Save(t); err != nil {
+		return err
+	}
+	runLifecycle(task.EventClaim, t)
+	return nil
+}
+
+// stageClaim runs claimTask's checks on t against the unfinished tasks and
+// claims it in memory, without saving.
+func stageClaim(store *storage.Store, t *task.Task, tasks []*task.Task, force bool) error {
+	if t.Status != task.StatusOpen {
+		return InvalidStatusError{
+			ID:       t.ID,
+			Current:  string(t.Status),
+			Expected: string(task.StatusOpen),
+		}
+	}
+
+	var overridden []string
+
+	// Check that claiming stays within the WIP limits
+	actor := currentActor(store)
+	if limit, active, exceeded := store.WIPLimits().Exceeded(tasks, actor); exceeded {
+		wipErr := WIPLimitExceededError{Limit: limit, Active: active}
+		if task.IsAgentActor(actor) {
+			wipErr.Agent = actor
+		}
+		if !force {
+			return wipErr
+		}
+		overridden = append(overridden, wipErr.Reached())
+	}
+
+	graph := newGraph(store, tasks)
+	blockers := graph.BlockedBy(t.ID)
+	if len(blockers) > 0 {
+		if !force {
+			return deps.BlockedError{ID: t.ID, BlockedBy: blockers}
+		}
+		overridden = append(overridden, "blocked by "+strings.Join(blockers, ", "))
+	}
+
+	now := time.Now().UTC()
+	if len(overridden) > 0 {
+		t.ForceClaim(now, actor, strings.Join(overridden, "; "))
+	} else {
+		t.Claim(now, actor)
+	}
+	return nil
 }
 
 // releaseCmd implements 'bits release'.
@@ -285,21 +1263,17 @@ func releaseCmd() *cobra.Command {
 				printError(err)
 			}
 
+			if t, ok := daemonTask(store, "release", idParams{ID: args[0], Actor: currentActor(store)}); ok {
+				printOutput(formatter.FormatTask(t))
+				return
+			}
+
+			lockStore(store)
 			t, err := store.Load(args[0])
 			if err != nil {
 				printError(err)
 			}
-
-			if t.Status != task.StatusActive {
-				printError(InvalidStatusError{
-					ID:       t.ID,
-					Current:  string(t.Status),
-					Expected: string(task.StatusActive),
-				})
-			}
-
-			t.Status = task.StatusOpen
-			if err = store.Save(t); err != nil {
+			if err = releaseTask(store, t); err != nil {
 				printError(err)
 			}
 			printOutput(formatter.FormatTask(t))
@@ -307,106 +1281,434 @@ func releaseCmd() *cobra.Command {
 	}
 }
 
+// releaseTask returns an active task to open.
+func releaseTask(store *storage.Store, t *task.Task) error {
+	if t.Status != task.StatusActive {
+		return InvalidStatusError{
+			ID:       t.ID,
+			Current:  string(t.Status),
+			Expected: string(task.StatusActive),
+		}
+	}
+	t.Release(time.Now().UTC(), currentActor(store))
+	return store.Save(t)
+}
+
 // closeCmd implements 'bits close'.
 func closeCmd() *cobra.Command {
-	return &cobra.Command{
-		Use:   "close <id> <reason>",
-		Short: "Close a task",
-		Args:  cobra.ExactArgs(2), //nolint:mnd // CLI takes 2 positional args
+	var verify, noVerify bool
+	var sel selector
+	cmd := &cobra.Command{
+		Use:   "close <id>... <reason>",
+		Short: "Close tasks",
+		Long: `Close an active task with a reason.
+
+A task with its own verify command (bits add --verify) runs it before closing,
+and --verify runs verify.command from the config file for tasks without one.
+The close is refused unless the command exits 0; a summary of its output is
+recorded with the close. --no-verify skips the check.
+
+Closing a task with unchecked acceptance criteria warns; set
+acceptance.on_close in the config file to "block" to refuse, or "off".
+
+Several IDs, or --all-matching for every active task whose text contains a
+query, are closed together with the same reason: if any check fails, none is
+closed.`,
+		Args: sel.args(1),
 		Run: func(_ *cobra.Command, args []string) {
 			store, err := getStore()
 			if err != nil {
 				printError(err)
 			}
-
-			t, err := store.Load(args[0])
-			if err != nil {
-				printError(err)
+			ids, reason := args[:len(args)-1], args[len(args)-1]
+			if sel.bulk(ids) {
+				lockStore(store)
+				closed, bulkErr := bulkClose(store, &sel, ids, reason, verify, noVerify)
+				if bulkErr != nil {
+					printError(bulkErr)
+				}
+				printOutput(formatter.FormatTaskList(closed))
+				return
 			}
 
-			if t.Status != task.StatusActive {
-				printError(InvalidStatusError{
-					ID:       t.ID,
-					Current:  string(t.Status),
-					Expected: string(task.StatusActive),
-				})
+			if t, ok := daemonTask(store, "close", closeParams{
+				ID: args[0], Reason: args[1], Verify: verify, NoVerify: noVerify,
+				Actor: currentActor(store), NoHooks: noHooks,
+			}); ok {
+				printOutput(formatter.FormatTask(t))
+				return
 			}
 
-			reason := args[1]
-			if reason == "" {
-				printError(MissingReasonError{})
+			lockStore(store)
+			t, err := store.Load(args[0])
+			if err != nil {
+				printError(err)
 			}
-
-			now := time.Now().UTC()
-			t.Status = task.StatusClosed
-			t.ClosedAt = &now
-			t.CloseReason = &reason
-
-			if err = store.Save(t); err != nil {
+			if err = closeTask(store, t, args[1], verify, noVerify); err != nil {
 				printError(err)
 			}
 			printOutput(formatter.FormatTask(t))
 		},
 	}
+	cmd.Flags().BoolVar(&verify, "verify", false, "Run the configured verify command before closing")
+	cmd.Flags().BoolVar(&noVerify, "no-verify", false, "Skip the task's verify command")
+	cmd.MarkFlagsMutuallyExclusive("verify", "no-verify")
+	sel.addFlag(cmd, "active task")
+	return cmd
+}
+
+// closeTask closes an active task after its acceptance and verify checks,
+// then runs its close scripts. verify requires a verify command to run, and
+// noVerify skips the task's own.
+func closeTask(store *storage.Store, t *task.Task, reason string, verify, noVerify bool) error {
+	note, err := checkClose(t, reason, verify, noVerify)
+	if err != nil {
+		return err
+	}
+	if err = warnOpenChildren(store, t); err != nil {
+		return err
+	}
+
+	t.Close(time.Now().UTC(), reason, currentActor(store))
+	t.Annotate(note)
+	if err = store.Save(t); err != nil {
+		return err
+	}
+	runLifecycle(task.EventClose, t)
+	return nil
+}
+
+// checkClose runs closeTask's checks on t without changing it, returning the
+// note to record with the close.
+func checkClose(t *task.Task, reason string, verify, noVerify bool) (string, error) {
+	if t.Status != task.StatusActive {
+		return "", InvalidStatusError{
+			ID:       t.ID,
+			Current:  string(t.Status),
+			Expected: string(task.StatusActive),
+		}
+	}
+	if reason == "" {
+		return "", MissingReasonError{}
+	}
+	return closeGate(t, verify, noVerify)
+}
+
+// closeGate runs the acceptance and verify checks any close of t must pass,
+// returning the note to record with the close.
+func closeGate(t *task.Task, verify, noVerify bool) (string, error) {
+	if unchecked := t.UncheckedCriteria(); len(unchecked) > 0 {
+		uncheckedErr := UncheckedCriteriaError{ID: t.ID, Criteria: unchecked}
+		switch cfg.Acceptance.OnCloseMode() {
+		case config.AcceptanceBlock:
+			return "", uncheckedErr
+		case config.AcceptanceWarn:
+			printWarning(uncheckedErr)
+		}
+	}
+
+	if noVerify {
+		return "", nil
+	}
+	return verifyClose(t, verify)
+}
+
+// warnOpenChildren warns if t has subtasks that are still open or active.
+func warnOpenChildren(store *storage.Store, t *task.Task) error {
+	unfinished, err := store.Children(t.ID, storage.StatusFilter{Open: true, Active: true})
+	if err != nil {
+		return err
+	}
+	if len(unfinished) > 0 {
+		printWarning(OpenChildrenError{ID: t.ID, Children: unfinished})
+	}
+	return nil
+}
+
+const verifySummaryLines = 3
+
+// verifyClose runs the task's verify command, or with required the configured
+// one, returning a note summarizing a passing run. It returns an empty note if
+// there is nothing to run.
+func verifyClose(t *task.Task, required bool) (string, error) {
+	command := t.Verify
+	if command == "" && required {
+		if command = cfg.Verify.Command; command == "" {
+			return "", MissingVerifyCommandError{}
+		}
+	}
+	if command == "" {
+		return "", nil
+	}
+	if dryRun {
+		printDryRun("run verify command `%s`", command)
+		return "", nil
+	}
+
+	result, err := script.Run(context.Background(), "", command, []string{"BITS_TASK_ID=" + t.ID}, os.Stderr)
+	if err != nil {
+		return "", err
+	}
+	if !result.OK() {
+		return "", VerifyFailedError{
+			Command:  command,
+			ExitCode: result.ExitCode,
+			Summary:  result.Summary(verifySummaryLines),
+		}
+	}
+	note := fmt.Sprintf("`%s` passed", command)
+	if summary := result.Summary(verifySummaryLines); summary != "" {
+		note += ": " + summary
+	}
+	return note, nil
 }
 
 // depCmd implements 'bits dep'.
 func depCmd() *cobra.Command {
-	return &cobra.Command{
-		Use:   "dep <id> <depends-on-id>",
-		Short: "Add a dependency",
-		Args:  cobra.ExactArgs(2), //nolint:mnd // CLI takes 2 positional args
-		Run: func(_ *cobra.Command, args []string) {
+	var anyOf, hint bool
+	var weight int
+	var linkType string
+	var sel selector
+	cmd := &cobra.Command{
+		Use:   "dep <id> <depends-on-id>...",
+		Short: "Add dependencies",
+		Long: `Make a task depend on other tasks, or on a GitHub issue or pull request URL
+(https://github.com/owner/repo/issues/12). External dependencies block until
+'bits ready --check-external' finds them closed or merged.
+
+With --any, the dependencies form a group that is satisfied as soon as any one
+of them is closed, for tasks that need only one of several alternatives.
+
+With --hint, the other tasks are only ordering hints: the task is listed after
+them when possible but is never blocked. --weight strengthens a hint relative
+to others.
+
+--type names the kind of link: depends-on (the default), blocks (the other
+tasks depend on this one), relates-to, or duplicates. Only depends-on and
+blocks hold tasks back; the others are shown by 'bits show' and 'bits graph'
+but never affect 'bits ready'.
+
+To change several tasks at once, list them before "--" (bits dep a b -- c d),
+or select every unfinished task whose text contains a query with
+--all-matching, in which case every argument is a dependency. All the tasks
+change, or none do.`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if sel.matching != "" {
+				return cobra.MinimumNArgs(1)(cmd, args)
+			}
+			return cobra.MinimumNArgs(2)(cmd, args) //nolint:mnd // a task and at least one dependency
+		},
+		Run: func(cmd *cobra.Command, args []string) {
 			store, err := getStore()
 			if err != nil {
 				printError(err)
 			}
+			lockStore(store)
 
-			taskID := args[0]
-			depID := args[1]
-
-			// Load all tasks for cycle detection
-			tasks, err := store.List(storage.StatusFilter{})
-			if err != nil {
+			lt := task.LinkType(linkType)
+			if err = checkLinkType(lt, anyOf || hint, sel.matching != ""); err != nil {
 				printError(err)
 			}
-
-			graph := deps.NewGraph(tasks)
-			if err = graph.ValidateAddDep(taskID, depID); err != nil {
-				printError(err)
+			opts := depOptions{AnyOf: anyOf, Hint: hint, Weight: weight}
+			if !lt.Blocking() {
+				opts.Link = lt
+			}
+			ids, depIDs := args[:1], args[1:]
+			switch dash := cmd.ArgsLenAtDash(); {
+			case sel.matching != "":
+				ids, depIDs = nil, args
+			case dash >= 0:
+				ids, depIDs = args[:dash], args[dash:]
+				if len(ids) == 0 || len(depIDs) == 0 {
+					printError(DepArgsError{})
+				}
+			}
+			if lt == task.LinkBlocks {
+				ids, depIDs = depIDs, ids
+			}
+			if sel.bulk(ids) {
+				changed, bulkErr := bulkDepend(store, &sel, ids, depIDs, opts)
+				if bulkErr != nil {
+					printError(bulkErr)
+				}
+				printOutput(formatter.FormatTaskList(changed))
+				return
 			}
 
-			t, err := store.Load(taskID)
+			t, changed, err := dependOn(store, ids[0], depIDs, opts)
 			if err != nil {
 				printError(err)
 			}
-
-			// Check if already depends on
-			if slices.Contains(t.DependsOn, depID) {
+			if !changed {
 				printOutput(formatter.FormatMessage("Dependency already exists"))
 				return
 			}
-
-			t.DependsOn = append(t.DependsOn, depID)
-			if err = store.Save(t); err != nil {
-				printError(err)
-			}
 			printOutput(formatter.FormatTask(t))
 		},
 	}
+	cmd.Flags().BoolVar(&anyOf, "any", false, "Unblock when any one of the dependencies is closed")
+	cmd.Flags().BoolVar(&hint, "hint", false, "Add ordering-only hints that never block")
+	cmd.Flags().IntVar(&weight, "weight", 1, "Strength of --hint ordering hints")
+	cmd.Flags().StringVar(&linkType, "type", string(task.LinkDependsOn),
+		"Kind of link: depends-on, blocks, relates-to, or duplicates")
+	cmd.MarkFlagsMutuallyExclusive("any", "hint")
+	sel.addFlag(cmd, "unfinished task")
+	return cmd
+}
+
+// depOptions selects the kind of dependency dependOn adds.
+type depOptions struct {
+	AnyOf  bool          // Add the dependencies as one any-of group
+	Hint   bool          // Add ordering-only hints instead
+	Weight int           // Strength of hints
+	Link   task.LinkType // Add non-blocking links of this type instead
+}
+
+// checkLinkType validates a --type for dep. Only plain dependencies combine
+// with --any or --hint, and blocks, which turns the arguments around, can't
+// combine with --all-matching.
+func checkLinkType(lt task.LinkType, grouped, matching bool) error {
+	switch {
+	case !task.IsValidLinkType(lt):
+		return InvalidLinkTypeError{Value: string(lt)}
+	case lt != task.LinkDependsOn && grouped:
+		return LinkTypeFlagsError{Type: lt, Flag: "--any or --hint"}
+	case lt == task.LinkBlocks && matching:
+		return LinkTypeFlagsError{Type: lt, Flag: "--all-matching"}
+	}
+	return nil
+}
+
+// dependOn validates and adds dependencies (or hints) from taskID to depIDs,
+// saving the task if it changed. GitHub URLs are normalized.
+func dependOn(store *storage.Store, taskID string, depIDs []string, opts depOptions) (*task.Task, bool, error) {
+	ids := normalizeDeps(depIDs)
+
+	// Load all tasks for cycle detection
+	tasks, err := store.List(storage.StatusFilter{})
+	if err != nil {
+		return nil, false, err
+	}
+	if err = validateDeps(deps.NewGraph(tasks), taskID, ids, opts); err != nil {
+		return nil, false, err
+	}
+
+	t, err := store.Load(taskID)
+	if err != nil {
+		return nil, false, err
+	}
+	if !applyDeps(t, ids, opts) {
+		return t, false, nil
+	}
+	if err = cfg.Limits.Check(t); err != nil {
+		return nil, false, err
+	}
+	return t, true, store.Save(t)
+}
+
+// normalizeDeps normalizes GitHub URLs among depIDs and drops duplicates.
+func normalizeDeps(depIDs []string) []string {
+	var ids []string
+	for _, depID := range depIDs {
+		if ref, ok := external.ParseRef(depID); ok {
+			depID = ref.String()
+		}
+		if !slices.Contains(ids, depID) {
+			ids = append(ids, depID)
+		}
+	}
+	return ids
+}
+
+// validateDeps checks that taskID can depend on each of ids without creating
+// a cycle, or for hints and typed links, which never block, that the tasks
+// exist.
+func validateDeps(graph *deps.Graph, taskID string, ids []string, opts depOptions) error {
+	for _, depID := range ids {
+		var err error
+		if opts.Hint || opts.Link != "" {
+			err = graph.ValidateAddHint(taskID, depID)
+		} else {
+			err = graph.ValidateAddDep(taskID, depID)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyDeps adds validated dependencies, hints, or links to t, reporting
+// whether it changed.
+func applyDeps(t *task.Task, ids []string, opts depOptions) bool {
+	if opts.Hint {
+		return addHints(t, ids, opts.Weight)
+	}
+	if opts.Link != "" {
+		return t.AddLinks(ids, opts.Link)
+	}
+	return addDependencies(t, ids, opts.AnyOf && len(ids) > 1)
+}
+
+// addDependencies adds depIDs to a task, as one any-of group or as individual
+// dependencies, reporting whether the task changed.
+func addDependencies(t *task.Task, depIDs []string, anyOf bool) bool {
+	if anyOf {
+		for _, group := range t.DependsAny {
+			if len(group) == len(depIDs) && !slices.ContainsFunc(depIDs, func(id string) bool {
+				return !slices.Contains(group, id)
+			}) {
+				return false
+			}
+		}
+		t.DependsAny = append(t.DependsAny, depIDs)
+		return true
+	}
+
+	changed := false
+	for _, depID := range depIDs {
+		if !slices.Contains(t.DependsOn, depID) {
+			t.DependsOn = append(t.DependsOn, depID)
+			changed = true
+		}
+	}
+	return changed
+}
+
+// addHints adds or reweights ordering hints on a task, reporting whether the
+// task changed.
+func addHints(t *task.Task, ids []string, weight int) bool {
+	changed := false
+	for _, id := range ids {
+		i := slices.IndexFunc(t.After, func(h task.Hint) bool { return h.ID == id })
+		h := task.Hint{ID: id}
+		if weight > 1 {
+			h.Weight = weight
+		}
+		switch {
+		case i < 0:
+			t.After = append(t.After, h)
+			changed = true
+		case t.After[i] != h:
+			t.After[i] = h
+			changed = true
+		}
+	}
+	return changed
 }
 
 // undepCmd implements 'bits undep'.
 func undepCmd() *cobra.Command {
 	return &cobra.Command{
 		Use:   "undep <id> <depends-on-id>",
-		Short: "Remove a dependency",
+		Short: "Remove a dependency, including from any-of groups",
 		Args:  cobra.ExactArgs(2), //nolint:mnd // CLI takes 2 positional args
 		Run: func(_ *cobra.Command, args []string) {
 			store, err := getStore()
 			if err != nil {
 				printError(err)
 			}
+			lockStore(store)
 
 			t, err := store.Load(args[0])
 			if err != nil {
@@ -414,11 +1716,10 @@ func undepCmd() *cobra.Command {
 			}
 
 			depID := args[1]
-			originalLen := len(t.DependsOn)
-			t.DependsOn = slices.DeleteFunc(t.DependsOn, func(d string) bool {
-				return d == depID
-			})
-			if len(t.DependsOn) == originalLen {
+			if ref, ok := external.ParseRef(depID); ok {
+				depID = ref.String()
+			}
+			if !t.RemoveDependency(depID) {
 				printOutput(formatter.FormatMessage("Dependency not found"))
 				return
 			}
@@ -440,6 +1741,7 @@ func pruneCmd() *cobra.Command {
 			if err != nil {
 				printError(err)
 			}
+			lockStore(store)
 
 			tasks, err := store.List(storage.StatusFilter{Closed: true})
 			if err != nil {
@@ -450,28 +1752,50 @@ func pruneCmd() *cobra.Command {
 				printOutput(formatter.FormatMessage("No closed tasks to prune"))
 				return
 			}
+			verb := "Pruned"
+			if dryRun {
+				verb = "Would prune"
+			}
+
+			if _, err = store.Snapshot("prune"); err != nil {
+				printError(err)
+			}
 
 			for _, t := range tasks {
 				if err = store.Delete(t.ID); err != nil {
 					printError(err)
 				}
 			}
-			printOutput(formatter.FormatMessage(fmt.Sprintf("Pruned %d closed task(s)", len(tasks))))
+			printOutput(formatter.FormatMessage(fmt.Sprintf("%s %d closed task(s)", verb, len(tasks))))
 		},
 	}
 }
 
 // rmCmd implements 'bits rm'.
 func rmCmd() *cobra.Command {
-	return &cobra.Command{
-		Use:   "rm <id>",
-		Short: "Remove a task",
-		Args:  cobra.ExactArgs(1),
+	var sel selector
+	cmd := &cobra.Command{
+		Use:   "rm <id>...",
+		Short: "Remove tasks",
+		Long: `Remove tasks and clean up references to them in other tasks. Several IDs, or
+--all-matching for every task whose text contains a query, are removed
+together: if any removal fails, the store is restored.`,
+		Args: sel.args(0),
 		Run: func(_ *cobra.Command, args []string) {
 			store, err := getStore()
 			if err != nil {
 				printError(err)
 			}
+			lockStore(store)
+
+			if sel.bulk(args) {
+				removed, bulkErr := bulkRemove(store, &sel, args)
+				if bulkErr != nil {
+					printError(bulkErr)
+				}
+				printOutput(formatter.FormatMessage(removedMessage(removed)))
+				return
+			}
 
 			taskID := args[0]
 
@@ -481,6 +1805,10 @@ func rmCmd() *cobra.Command {
 				printError(err)
 			}
 
+			if _, err = store.Snapshot("rm"); err != nil {
+				printError(err)
+			}
+
 			// Remove from other tasks' dependencies
 			if err = store.RemoveDependency(taskID); err != nil {
 				printError(err)
@@ -490,9 +1818,15 @@ func rmCmd() *cobra.Command {
 			if err = store.Delete(taskID); err != nil {
 				printError(err)
 			}
+			if dryRun {
+				printOutput(formatter.FormatMessage("Would remove task " + taskID))
+				return
+			}
 			printOutput(formatter.FormatMessage(fmt.Sprintf("Removed task %s", taskID)))
 		},
 	}
+	sel.addFlag(cmd, "task")
+	return cmd
 }
 
 type hookResponse struct {
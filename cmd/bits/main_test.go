@@ -0,0 +1,23 @@
+//nolint:testpackage // Tests require internal access for thorough testing
+package main
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/abatilo/bits/internal/config"
+	"github.com/abatilo/bits/internal/storage"
+)
+
+// newTestStore returns an empty store in a temporary directory, with the
+// globals commands rely on set as they would be for a bare 'bits' invocation.
+func newTestStore(t *testing.T) *storage.Store {
+	t.Helper()
+	t.Setenv("BITS_ACTOR", "tester")
+	cfg = &config.Config{}
+	store := storage.NewStoreWithPath(filepath.Join(t.TempDir(), ".bits"))
+	if err := store.EnsureInitialized(); err != nil {
+		t.Fatalf("EnsureInitialized failed: %v", err)
+	}
+	return store
+}
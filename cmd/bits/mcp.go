@@ -0,0 +1,278 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"runtime/debug"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/abatilo/bits/internal/rpc"
+	"github.com/abatilo/bits/internal/storage"
+	"github.com/abatilo/bits/internal/task"
+)
+
+// mcpVersions returns the Model Context Protocol revisions 'bits mcp' speaks,
+// newest first.
+func mcpVersions() []string {
+	return []string{"2025-06-18", "2025-03-26", "2024-11-05"}
+}
+
+// mcpResourceNotFound is the MCP error code for a resource that doesn't exist.
+const mcpResourceNotFound = -32002
+
+// mcpCmd implements 'bits mcp'.
+func mcpCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "mcp",
+		Short: "Serve the store to AI agents over the Model Context Protocol",
+		Long: `Serve the store over the Model Context Protocol on stdin and stdout, so
+agents can read and update tasks through tools rather than the shell. Register
+it with an MCP client as the command "bits mcp", run in the repository.
+
+Tools mirror the CLI and 'bits rpc', returning tasks in the same shape as
+--json: add, list, ready, claim, close, and dep. A tool that fails reports the
+error as its result, so the agent can correct the call.
+
+Each task is also a resource at its bits://<store>/<id> URI, as printed by
+'bits path --uri bits'. Unfinished tasks are listed; any task can be read.
+
+Warnings and script output go to stderr. The server exits at end of input.`,
+		Args: cobra.NoArgs,
+		Run: func(_ *cobra.Command, _ []string) {
+			store, err := getStore()
+			if err != nil {
+				printError(err)
+			}
+			if err = newMCPServer(store).Serve(os.Stdin, os.Stdout); err != nil {
+				printError(err)
+			}
+		},
+	}
+}
+
+// mcpTool is a tool offered by 'bits mcp', run by the 'bits rpc' method of
+// the same params.
+type mcpTool struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description"`
+	InputSchema map[string]any `json:"inputSchema"`
+	method      string
+}
+
+// mcpContent is one block of a tool result or resource.
+type mcpContent struct {
+	Type     string `json:"type,omitempty"`
+	URI      string `json:"uri,omitempty"`
+	MimeType string `json:"mimeType,omitempty"`
+	Text     string `json:"text"`
+}
+
+// mcpTools returns the tools of 'bits mcp', in the order they are listed.
+func mcpTools() []mcpTool {
+	str := func(desc string) map[string]any { return map[string]any{"type": "string", "description": desc} }
+	flag := func(desc string) map[string]any { return map[string]any{"type": "boolean", "description": desc} }
+	strs := func(desc string) map[string]any {
+		return map[string]any{"type": "array", "items": map[string]any{"type": "string"}, "description": desc}
+	}
+	object := func(props map[string]any, required ...string) map[string]any {
+		schema := map[string]any{"type": "object", "properties": props, "additionalProperties": false}
+		if len(required) > 0 {
+			schema["required"] = required
+		}
+		return schema
+	}
+	actor := str("Who to record in task history, instead of the server's own identity")
+
+	return []mcpTool{
+		{
+			Name:        "add",
+			Description: "Create a task and return it",
+			method:      "create",
+			InputSchema: object(map[string]any{
+				"title":       str("Title of the task"),
+				"description": str("Longer description, in markdown"),
+				"priority":    str("critical, high, medium (the default), or low"),
+				"tags":        strs("Tags to give the task"),
+				"due":         str("Due date (2006-01-02) or duration from now (3d, 2w)"),
+				"parent":      str("ID of the parent task"),
+				"actor":       actor,
+			}, "title"),
+		},
+		{
+			Name:        "list",
+			Description: "List tasks, by default the unfinished ones in ready order",
+			method:      "list",
+			InputSchema: object(map[string]any{
+				"open":    flag("Include open tasks"),
+				"active":  flag("Include active tasks"),
+				"closed":  flag("Include closed tasks"),
+				"order":   map[string]any{"type": "string", "enum": []string{"ready", "priority", "created"}},
+				"tags":    strs("Only tasks with every one of these tags"),
+				"overdue": flag("Only tasks past their due date"),
+			}),
+		},
+		{
+			Name:        "ready",
+			Description: "List the open tasks whose dependencies are all closed, most urgent first",
+			method:      "ready",
+			InputSchema: object(map[string]any{}),
+		},
+		{
+			Name:        "claim",
+			Description: "Claim an open task, marking it active, and return it",
+			method:      "claim",
+			InputSchema: object(map[string]any{
+				"id":    str("ID of the task"),
+				"force": flag("Claim even if dependencies are unfinished or WIP limits are reached"),
+				"actor": actor,
+			}, "id"),
+		},
+		{
+			Name:        "close",
+			Description: "Close an active task with a reason and return it",
+			method:      "close",
+			InputSchema: object(map[string]any{
+				"id":        str("ID of the task"),
+				"reason":    str("Why the task is done"),
+				"verify":    flag("Run the task's verify command first"),
+				"no_verify": flag("Skip the task's verify command"),
+				"actor":     actor,
+			}, "id", "reason"),
+		},
+		{
+			Name:        "dep",
+			Description: "Make a task depend on others and return it",
+			method:      "dep",
+			InputSchema: object(map[string]any{
+				"id":         str("ID of the dependent task"),
+				"depends_on": strs("IDs of the tasks it depends on"),
+				"any":        flag("Depend on any one of them rather than all"),
+				"hint":       flag("Add ordering-only hints that never block"),
+				"weight":     map[string]any{"type": "integer", "description": "Strength of hint ordering"},
+			}, "id", "depends_on"),
+		},
+	}
+}
+
+// newMCPServer registers the Model Context Protocol methods served by
+// 'bits mcp', which run on the task methods of 'bits rpc'.
+func newMCPServer(store *storage.Store) *rpc.Server {
+	methods := newRPCServer(store)
+	tools := mcpTools()
+	prefix := taskURI(store, "")
+
+	server := rpc.NewServer()
+	server.Handle("initialize", func(params json.RawMessage) (any, error) {
+		var p struct {
+			ProtocolVersion string `json:"protocolVersion"`
+		}
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, rpc.Error{Code: rpc.CodeInvalidParams, Message: "invalid params: " + err.Error()}
+		}
+		versions := mcpVersions()
+		version := versions[0] // Offered to clients asking for a revision we don't speak
+		if slices.Contains(versions, p.ProtocolVersion) {
+			version = p.ProtocolVersion
+		}
+		return map[string]any{
+			"protocolVersion": version,
+			"capabilities":    map[string]any{"tools": map[string]any{}, "resources": map[string]any{}},
+			"serverInfo":      map[string]any{"name": "bits", "version": buildVersion()},
+		}, nil
+	})
+	server.Handle("ping", func(json.RawMessage) (any, error) {
+		return map[string]any{}, nil
+	})
+
+	server.Handle("tools/list", func(json.RawMessage) (any, error) {
+		return map[string]any{"tools": tools}, nil
+	})
+	server.Handle("tools/call", func(params json.RawMessage) (any, error) {
+		var p struct {
+			Name      string          `json:"name"`
+			Arguments json.RawMessage `json:"arguments"`
+		}
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, rpc.Error{Code: rpc.CodeInvalidParams, Message: "invalid params: " + err.Error()}
+		}
+		i := slices.IndexFunc(tools, func(t mcpTool) bool { return t.Name == p.Name })
+		if i < 0 {
+			return nil, rpc.Error{Code: rpc.CodeInvalidParams, Message: "unknown tool: " + p.Name}
+		}
+		result, err := methods.Call(tools[i].method, p.Arguments)
+		var text string
+		if err == nil {
+			var data []byte
+			data, err = json.Marshal(result)
+			text = string(data)
+		}
+		if err != nil {
+			text = err.Error()
+		}
+		return map[string]any{"content": []mcpContent{{Type: "text", Text: text}}, "isError": err != nil}, nil
+	})
+
+	server.Handle("resources/list", func(json.RawMessage) (any, error) {
+		live, err := store.List(storage.StatusFilter{Open: true, Active: true})
+		if err != nil {
+			return nil, err
+		}
+		resources := make([]map[string]any, len(live))
+		for i, t := range live {
+			resources[i] = map[string]any{
+				"uri":      taskURI(store, t.ID),
+				"name":     t.ID,
+				"title":    t.Title,
+				"mimeType": "application/json",
+			}
+		}
+		return map[string]any{"resources": resources}, nil
+	})
+	server.Handle("resources/templates/list", func(json.RawMessage) (any, error) {
+		return map[string]any{"resourceTemplates": []map[string]any{{
+			"uriTemplate": prefix + "{id}",
+			"name":        "task",
+			"description": "A task by ID",
+			"mimeType":    "application/json",
+		}}}, nil
+	})
+	server.Handle("resources/read", func(params json.RawMessage) (any, error) {
+		var p struct {
+			URI string `json:"uri"`
+		}
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, rpc.Error{Code: rpc.CodeInvalidParams, Message: "invalid params: " + err.Error()}
+		}
+		id, ok := strings.CutPrefix(p.URI, prefix)
+		if !ok || !task.IsValidID(id) || !store.Exists(id) {
+			return nil, rpc.Error{Code: mcpResourceNotFound, Message: "resource not found: " + p.URI}
+		}
+		arg, err := json.Marshal(idParams{ID: id})
+		if err != nil {
+			return nil, err
+		}
+		result, err := methods.Call("show", arg)
+		if err != nil {
+			return nil, err
+		}
+		data, err := json.Marshal(result)
+		if err != nil {
+			return nil, err
+		}
+		content := mcpContent{URI: p.URI, MimeType: "application/json", Text: string(data)}
+		return map[string]any{"contents": []mcpContent{content}}, nil
+	})
+	return server
+}
+
+// buildVersion returns the module version bits was built at, "(devel)" for
+// a local build.
+func buildVersion() string {
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" {
+		return info.Main.Version
+	}
+	return "(devel)"
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/abatilo/bits/internal/storage"
+	"github.com/abatilo/bits/internal/task"
+)
+
+// mergeDriverCmd implements 'bits merge-driver' for use as a git merge driver.
+func mergeDriverCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "merge-driver <base> <ours> <theirs>",
+		Short: "Three-way merge task files (git merge driver)",
+		Long: `Three-way merge concurrent edits to a task file, writing the result to <ours>.
+
+Frontmatter is merged field by field: status transitions win over stale values
+and dependency lists are merged as sets. Configure it as a git merge driver:
+
+  git config merge.bits.driver "bits merge-driver %O %A %B"
+  echo "*.md merge=bits" >> .gitattributes`,
+		Args: cobra.ExactArgs(3), //nolint:mnd // git passes base, ours, and theirs
+		Run: func(_ *cobra.Command, args []string) {
+			base, err := readTaskFile(args[0], true)
+			if err != nil {
+				printError(err)
+			}
+			ours, err := readTaskFile(args[1], false)
+			if err != nil {
+				printError(err)
+			}
+			theirs, err := readTaskFile(args[2], false)
+			if err != nil {
+				printError(err)
+			}
+
+			content, err := storage.SerializeMarkdown(storage.MergeTasks(base, ours, theirs))
+			if err != nil {
+				printError(err)
+			}
+			//nolint:gosec // G306: 0644 is appropriate for user-readable task files
+			if err = os.WriteFile(args[1], content, 0o644); err != nil {
+				printError(err)
+			}
+		},
+	}
+}
+
+// readTaskFile parses a task file. With allowEmpty, an empty file (no common
+// ancestor) yields a nil task.
+func readTaskFile(path string, allowEmpty bool) (*task.Task, error) {
+	content, err := os.ReadFile(path) //nolint:gosec // G304: path is supplied by git
+	if err != nil {
+		return nil, err
+	}
+	if allowEmpty && len(content) == 0 {
+		return nil, nil //nolint:nilnil // An empty file means the task has no ancestor
+	}
+	return storage.ParseMarkdown(content)
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/abatilo/bits/internal/storage"
+)
+
+// nextCmd implements 'bits next'.
+func nextCmd() *cobra.Command {
+	var claim bool
+	cmd := &cobra.Command{
+		Use:   "next",
+		Short: "Show the top ready task, and optionally claim it",
+		Long: `Show the task 'bits ready' lists first: open, unblocked, overdue work first,
+then by ordering hints, priority, and age. Tasks queued on another agent by
+'bits assign' are skipped. Fails when nothing is ready.
+
+With --claim, claim it too, under the store's lock, so two agents running
+'bits next --claim' at once never get the same task. Claiming is checked
+against the WIP limits like 'bits claim'.`,
+		Example: "  id=$(bits next --claim --json | jq -r .id)",
+		Args:    cobra.NoArgs,
+		Run: func(_ *cobra.Command, _ []string) {
+			store, err := getStore()
+			if err != nil {
+				printError(err)
+			}
+			if claim {
+				lockStore(store)
+			}
+
+			tasks, err := store.List(storage.StatusFilter{Open: true, Active: true})
+			if err != nil {
+				printError(err)
+			}
+			t := storage.NextTask(newGraph(store, tasks).Ready(), currentActor(store))
+			if t == nil {
+				printError(NoReadyTasksError{})
+			}
+			if claim {
+				if err = claimTask(store, t, false); err != nil {
+					printError(err)
+				}
+			}
+			printOutput(formatter.FormatTask(t))
+		},
+	}
+	cmd.Flags().BoolVar(&claim, "claim", false, "Claim the task as well")
+	return cmd
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// noteCmd implements 'bits note'.
+func noteCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "note <id> <text>",
+		Short: "Add a timestamped note to a task",
+		Long: `Append a note to a task, stamped with the time and actor (--actor,
+$BITS_ACTOR, or a session). Notes record progress without touching the
+description; they are kept under a "## Notes" heading after it in the task
+file and listed by 'bits show'.`,
+		Args: cobra.ExactArgs(2), //nolint:mnd // a task ID and the note
+		Run: func(_ *cobra.Command, args []string) {
+			text := strings.TrimSpace(args[1])
+			if text == "" {
+				printError(MissingNoteError{})
+			}
+			store, err := getStore()
+			if err != nil {
+				printError(err)
+			}
+			lockStore(store)
+			t, err := store.Load(args[0])
+			if err != nil {
+				printError(err)
+			}
+
+			t.AddNote(time.Now().UTC(), currentActor(store), text)
+			if err = store.Save(t); err != nil {
+				printError(err)
+			}
+			printOutput(formatter.FormatTask(t))
+		},
+	}
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/abatilo/bits/internal/storage"
+)
+
+// orderCmd implements 'bits order'.
+func orderCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "order",
+		Short: "List every unfinished task in dependency order",
+		Long: `List the open and active tasks as one linear plan: each task after everything it
+depends on, and among the tasks free to go next, higher priority first, then
+older. Unlike 'bits ready', which shows only what can start now, this covers
+the whole backlog, for planning a batch of work up front.
+
+An any-of group is satisfied by whichever member is listed first. External
+dependencies don't hold a task back, and tasks caught in a dependency cycle
+are listed last.`,
+		Args: cobra.NoArgs,
+		Run: func(_ *cobra.Command, _ []string) {
+			store, err := getStore()
+			if err != nil {
+				printError(err)
+			}
+
+			tasks, err := store.List(storage.StatusFilter{Open: true, Active: true})
+			if err != nil {
+				printError(err)
+			}
+			printOutput(formatter.FormatTaskList(newGraph(store, tasks).Order()))
+		},
+	}
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/abatilo/bits/internal/plugin"
+)
+
+// pluginsCmd implements 'bits plugins'.
+func pluginsCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "plugins",
+		Short: "List plugin commands found on PATH",
+		Long: `List the bits-<name> executables on PATH. Running 'bits <name>' for a name
+that is not a built-in command runs bits-<name> with the remaining arguments.
+
+Plugins receive the global flags and store location in the environment:
+
+  BITS_BIN       path of the bits executable
+  BITS_STORE     task store directory (unset outside a project)
+  BITS_OUTPUT    output format: human, json, or yaml
+  BITS_ACTOR     actor to record in task history
+  BITS_ABSOLUTE  "1" with --absolute
+  BITS_WIDE      "1" with --wide`,
+		Args: cobra.NoArgs,
+		Run: func(_ *cobra.Command, _ []string) {
+			printOutput(formatter.FormatPlugins(plugin.List(os.Getenv("PATH"))))
+		},
+	}
+}
+
+// dispatchPlugin runs bits-<name> when args name a subcommand bits doesn't
+// have, exiting with the plugin's status. It returns without doing anything
+// when args are for a built-in command or no such plugin exists, leaving
+// cobra to run or reject them.
+func dispatchPlugin(root *cobra.Command, args []string) {
+	globals, name, rest := splitPluginArgs(root, args)
+	if name == "" || strings.HasPrefix(name, "__") {
+		return
+	}
+	root.InitDefaultHelpCmd()
+	root.InitDefaultCompletionCmd()
+	if _, _, err := root.Find(args); err == nil {
+		return
+	}
+	path, err := plugin.Find(name)
+	if err != nil {
+		return
+	}
+
+	if err = root.PersistentFlags().Parse(globals); err != nil {
+		return
+	}
+	setup()
+
+	code, err := plugin.Run(path, rest, pluginEnv())
+	if err != nil {
+		printError(err)
+	}
+	os.Exit(code)
+}
+
+// splitPluginArgs splits args into the global flags before the subcommand,
+// the subcommand name, and the arguments after it. The name is empty if
+// there is no subcommand or a flag isn't a global one.
+func splitPluginArgs(root *cobra.Command, args []string) ([]string, string, []string) {
+	flags := root.PersistentFlags()
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if !strings.HasPrefix(arg, "-") {
+			return args[:i], arg, args[i+1:]
+		}
+		if arg == "--" || arg == "-" {
+			return nil, "", nil
+		}
+
+		name, _, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		flag := flags.Lookup(name)
+		if !strings.HasPrefix(arg, "--") {
+			flag = flags.ShorthandLookup(name[:1])
+			hasValue = hasValue || len(name) > 1
+		}
+		if flag == nil {
+			return nil, "", nil
+		}
+		if flag.NoOptDefVal == "" && !hasValue {
+			i++ // Value is the next argument
+		}
+	}
+	return nil, "", nil
+}
+
+// pluginEnv describes the global flags and store location to a plugin.
+func pluginEnv() []string {
+	format := outputFormat
+	if jsonOutput {
+		format = "json"
+	}
+	env := []string{"BITS_OUTPUT=" + format}
+	if bin, err := os.Executable(); err == nil {
+		env = append(env, "BITS_BIN="+bin)
+	}
+	if absoluteTimes {
+		env = append(env, "BITS_ABSOLUTE=1")
+	}
+	if wideOutput {
+		env = append(env, "BITS_WIDE=1")
+	}
+
+	store, err := getStore()
+	if err != nil {
+		return env
+	}
+	env = append(env, "BITS_STORE="+store.BasePath())
+	if actor := currentActor(store); actor != "" {
+		env = append(env, "BITS_ACTOR="+actor)
+	}
+	return env
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/abatilo/bits/internal/notify"
+	"github.com/abatilo/bits/internal/report"
+	"github.com/abatilo/bits/internal/storage"
+	"github.com/abatilo/bits/internal/task"
+)
+
+// remindCmd implements 'bits remind'.
+func remindCmd() *cobra.Command {
+	var in, at, note string
+	cmd := &cobra.Command{
+		Use:   "remind <id>",
+		Short: "Schedule a reminder about a task",
+		Long: `Schedule a reminder about a task, either --in a duration (2h, 3d) or --at a
+date or RFC3339 time. Reminders are emitted by 'bits remind fire', which is
+meant to run from cron or a daemon.`,
+		Args: cobra.ExactArgs(1),
+		Run: func(_ *cobra.Command, args []string) {
+			if (in == "") == (at == "") {
+				printError(ReminderTimeError{})
+			}
+
+			now := time.Now().UTC()
+			var when time.Time
+			if in != "" {
+				d, err := report.ParseAge(in)
+				if err != nil {
+					printError(err)
+				}
+				when = now.Add(d)
+			} else {
+				var err error
+				if when, err = report.ParseDue(at, now); err != nil {
+					printError(err)
+				}
+			}
+
+			store, err := getStore()
+			if err != nil {
+				printError(err)
+			}
+			lockStore(store)
+			t, err := store.Load(args[0])
+			if err != nil {
+				printError(err)
+			}
+			t.AddReminder(when, note)
+			if err = store.Save(t); err != nil {
+				printError(err)
+			}
+			printOutput(formatter.FormatTask(t))
+		},
+	}
+	cmd.Flags().StringVar(&in, "in", "", "Remind after a duration (2h, 3d, 1w)")
+	cmd.Flags().StringVar(&at, "at", "", "Remind at a date (2006-01-02) or RFC3339 time")
+	cmd.Flags().StringVarP(&note, "message", "m", "", "Note to include in the reminder")
+
+	cmd.AddCommand(remindFireCmd())
+	return cmd
+}
+
+// remindFireCmd implements 'bits remind fire'.
+func remindFireCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "fire",
+		Short: "Emit reminders that are due",
+		Long: `Emit every reminder scheduled at or before now and remove it from its task.
+Each reminder is printed and sent to notify.command from the config file, if
+set. Reminders on closed tasks are discarded without notifying.`,
+		Args: cobra.NoArgs,
+		Run: func(_ *cobra.Command, _ []string) {
+			store, err := getStore()
+			if err != nil {
+				printError(err)
+			}
+			lockStore(store)
+			tasks, err := store.List(storage.StatusFilter{})
+			if err != nil {
+				printError(err)
+			}
+
+			now := time.Now().UTC()
+			for _, t := range tasks {
+				due := t.TakeDueReminders(now)
+				if len(due) == 0 {
+					continue
+				}
+				if t.Status != task.StatusClosed {
+					for _, r := range due {
+						if err = fireReminder(t, r); err != nil {
+							printError(err) // Not saved, so the reminder fires again next time
+						}
+					}
+				}
+				if err = store.Rewrite(t); err != nil { // Firing isn't activity on the task
+					printError(err)
+				}
+			}
+		},
+	}
+}
+
+// fireReminder prints a reminder and sends it to the configured notify command.
+func fireReminder(t *task.Task, r task.Reminder) error {
+	msg := fmt.Sprintf("Reminder for task %s (%s)", t.ID, t.Title)
+	if r.Note != "" {
+		msg += ": " + r.Note
+	}
+	printOutput(formatter.FormatMessage(msg))
+	return notify.Send(cfg.Notify.Command, notify.Notification{
+		Kind:    "reminder",
+		TaskID:  t.ID,
+		Title:   t.Title,
+		Message: msg,
+	})
+}
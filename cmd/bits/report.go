@@ -0,0 +1,423 @@
+package main
+
+import (
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/abatilo/bits/internal/git"
+	"github.com/abatilo/bits/internal/notify"
+	"github.com/abatilo/bits/internal/output"
+	"github.com/abatilo/bits/internal/report"
+	"github.com/abatilo/bits/internal/storage"
+)
+
+// changelogCmd implements 'bits changelog'.
+func changelogCmd() *cobra.Command {
+	var since, format string
+	cmd := &cobra.Command{
+		Use:   "changelog",
+		Short: "Summarize closed tasks as release notes grouped by tag",
+		Long: `Print closed tasks grouped into changelog sections by tag. Sections and the
+tags they collect come from the changelog section of the config file; the
+defaults are Features (feature, feat, enhancement), Fixes (bug, fix), and
+Chores (chore, docs, refactor). Unmatched tasks are listed under Other.
+
+--since accepts a date (2006-01-02), an age (7d, 2w), or a git revision such
+as the previous release tag.`,
+		Run: func(_ *cobra.Command, _ []string) {
+			store, err := getStore()
+			if err != nil {
+				printError(err)
+			}
+
+			start, err := resolveSince(since)
+			if err != nil {
+				printError(err)
+			}
+
+			f := formatter
+			switch format {
+			case "markdown":
+			case "json":
+				f = output.NewJSONFormatter()
+			case "yaml":
+				f = output.NewYAMLFormatter()
+			default:
+				printError(InvalidOutputFormatError{Value: format, Valid: []string{"markdown", "json", "yaml"}})
+			}
+
+			tasks, err := store.List(storage.StatusFilter{Closed: true})
+			if err != nil {
+				printError(err)
+			}
+			printOutput(f.FormatChangelog(report.Changelog(tasks, cfg.Changelog, start)))
+		},
+	}
+	cmd.Flags().StringVar(&since, "since", "", "Only tasks closed since a date, age, or git revision")
+	cmd.Flags().StringVar(&format, "format", "markdown", "Output format: markdown, json, or yaml")
+	return cmd
+}
+
+// dueCmd implements 'bits due'.
+func dueCmd() *cobra.Command {
+	var within string
+	cmd := &cobra.Command{
+		Use:   "due",
+		Short: "List overdue tasks and tasks due soon",
+		Long: `List unfinished tasks that are overdue or due within --within (default 7d),
+soonest first. Set a due date with 'bits add --due'.`,
+		Args: cobra.NoArgs,
+		Run: func(_ *cobra.Command, _ []string) {
+			store, err := getStore()
+			if err != nil {
+				printError(err)
+			}
+
+			window, err := report.ParseAge(within)
+			if err != nil {
+				printError(err)
+			}
+
+			tasks, err := store.List(storage.StatusFilter{Open: true, Active: true})
+			if err != nil {
+				printError(err)
+			}
+			printOutput(formatter.FormatTaskList(report.Due(tasks, time.Now().UTC(), window)))
+		},
+	}
+	cmd.Flags().StringVar(&within, "within", "7d", "Include tasks due within this long from now")
+	return cmd
+}
+
+// reportWeeklyCmd implements 'bits report weekly'.
+func reportWeeklyCmd() *cobra.Command {
+	var format string
+	var send bool
+	cmd := &cobra.Command{
+		Use:   "weekly",
+		Short: "Compile the past week's closes, new tasks, and blockers into a document",
+		Long: `Compile a report on the past seven days: tasks closed and created, blockers
+created before the week that still hold up other work, and closed and created
+counts and median lead and cycle time next to the week before.
+
+With --notify, the rendered report is also sent to notify.command from the
+config file as BITS_MESSAGE, with BITS_NOTIFY_KIND set to weekly-report.`,
+		Args: cobra.NoArgs,
+		Run: func(_ *cobra.Command, _ []string) {
+			f := formatter
+			switch format {
+			case "markdown":
+			case "json":
+				f = output.NewJSONFormatter()
+			case "yaml":
+				f = output.NewYAMLFormatter()
+			default:
+				printError(InvalidOutputFormatError{Value: format, Valid: []string{"markdown", "json", "yaml"}})
+			}
+			if send && cfg.Notify.Command == "" {
+				printError(MissingNotifyCommandError{})
+			}
+
+			store, err := getStore()
+			if err != nil {
+				printError(err)
+			}
+			tasks, err := store.List(storage.StatusFilter{})
+			if err != nil {
+				printError(err)
+			}
+
+			doc := f.FormatWeekly(report.Weekly(tasks, newGraph(store, tasks), time.Now().UTC()))
+			if send {
+				err = notify.Send(cfg.Notify.Command, notify.Notification{
+					Kind:    "weekly-report",
+					Title:   "Weekly report",
+					Message: doc,
+				})
+				if err != nil {
+					printError(err)
+				}
+			}
+			printOutput(doc)
+		},
+	}
+	cmd.Flags().StringVar(&format, "format", "markdown", "Output format: markdown, json, or yaml")
+	cmd.Flags().BoolVar(&send, "notify", false, "Also send the report to notify.command")
+	return cmd
+}
+
+// reportReasonsCmd implements 'bits report reasons'.
+func reportReasonsCmd() *cobra.Command {
+	var since string
+	cmd := &cobra.Command{
+		Use:   "reasons",
+		Short: "Group close reasons to review how tasks were resolved",
+		Long: `Group the close reasons of tasks closed since --since, most common first,
+with how many tasks of each priority and tag were closed for each. Reasons
+worded alike, ignoring case, punctuation, and word order, are counted together,
+so patterns such as repeated "couldn't reproduce" closes stand out.
+
+--since accepts a date (2006-01-02), an age (7d, 2w), or a git revision.`,
+		Args: cobra.NoArgs,
+		Run: func(_ *cobra.Command, _ []string) {
+			store, err := getStore()
+			if err != nil {
+				printError(err)
+			}
+
+			start, err := resolveSince(since)
+			if err != nil {
+				printError(err)
+			}
+
+			tasks, err := store.List(storage.StatusFilter{Closed: true})
+			if err != nil {
+				printError(err)
+			}
+			printOutput(formatter.FormatReasons(report.Reasons(tasks, start)))
+		},
+	}
+	cmd.Flags().StringVar(&since, "since", "30d", "Only tasks closed since a date, age, or git revision")
+	return cmd
+}
+
+// statsCmd implements 'bits stats'.
+func statsCmd() *cobra.Command {
+	var since string
+	cmd := &cobra.Command{
+		Use:   "stats",
+		Short: "Show lead time and cycle time for closed tasks",
+		Long: `Show lead time (creation to close) and cycle time (first claim to close)
+percentiles for tasks closed since --since, overall and broken down by
+priority and by tag. Tasks closed without ever being claimed count toward lead
+time only. With sla targets in the config file, the SLA column shows the share
+of tasks closed within the target for their priority.
+
+--since accepts a date (2006-01-02), an age (7d, 2w), or a git revision.`,
+		Args: cobra.NoArgs,
+		Run: func(_ *cobra.Command, _ []string) {
+			store, err := getStore()
+			if err != nil {
+				printError(err)
+			}
+
+			start, err := resolveSince(since)
+			if err != nil {
+				printError(err)
+			}
+
+			tasks, err := store.List(storage.StatusFilter{Closed: true})
+			if err != nil {
+				printError(err)
+			}
+			sla, _ := cfg.SLA.Targets() // Validated when the config is loaded
+			printOutput(formatter.FormatFlow(report.Flow(tasks, start, sla)))
+		},
+	}
+	cmd.Flags().StringVar(&since, "since", "30d", "Only tasks closed since a date, age, or git revision")
+	return cmd
+}
+
+// matrixCmd implements 'bits matrix'.
+func matrixCmd() *cobra.Command {
+	var within, age string
+	cmd := &cobra.Command{
+		Use:   "matrix",
+		Short: "Show open tasks in an urgency/importance grid",
+		Long: `Sort open tasks into an Eisenhower matrix to triage what to work on next.
+Critical and high priority tasks are important. A task is urgent when it is
+overdue or due within --within, or, without a due date, when it is older than
+--age. Within each quadrant, tasks are listed in ready order.`,
+		Args: cobra.NoArgs,
+		Run: func(_ *cobra.Command, _ []string) {
+			store, err := getStore()
+			if err != nil {
+				printError(err)
+			}
+
+			dueWithin, err := report.ParseAge(within)
+			if err != nil {
+				printError(err)
+			}
+			oldAfter, err := report.ParseAge(age)
+			if err != nil {
+				printError(err)
+			}
+
+			tasks, err := listTasks(store, storage.StatusFilter{Open: true}, "ready")
+			if err != nil {
+				printError(err)
+			}
+			printOutput(formatter.FormatMatrix(report.Matrix(tasks, time.Now().UTC(), dueWithin, oldAfter)))
+		},
+	}
+	cmd.Flags().StringVar(&within, "within", "7d", "Tasks due within this long from now are urgent")
+	cmd.Flags().StringVar(&age, "age", "14d", "Tasks without a due date older than this are urgent")
+	return cmd
+}
+
+// reportCmd implements 'bits report' command group.
+func reportCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "report",
+		Short: "Reports built from task history",
+	}
+
+	cmd.AddCommand(
+		reportContributorsCmd(),
+		reportCapacityCmd(),
+		reportWeeklyCmd(),
+		reportReasonsCmd(),
+	)
+
+	return cmd
+}
+
+// reportContributorsCmd implements 'bits report contributors'.
+func reportContributorsCmd() *cobra.Command {
+	var since string
+	cmd := &cobra.Command{
+		Use:   "contributors",
+		Short: "Summarize closes, active time, and time-to-close per actor",
+		Long: `Summarize work per actor (agent session or human) from task history: tasks
+closed, total time spent with tasks claimed, and the average time from
+creation to close of the tasks they closed.
+
+Actors are recorded on claim, release, and close from --actor, $BITS_ACTOR,
+the agent session, or the OS user.`,
+		Run: func(_ *cobra.Command, _ []string) {
+			store, err := getStore()
+			if err != nil {
+				printError(err)
+			}
+
+			start, err := resolveSince(since)
+			if err != nil {
+				printError(err)
+			}
+
+			tasks, err := store.List(storage.StatusFilter{})
+			if err != nil {
+				printError(err)
+			}
+			printOutput(formatter.FormatContributors(report.Contributors(tasks, start, time.Now().UTC())))
+		},
+	}
+	cmd.Flags().StringVar(&since, "since", "30d", "Window start: a date, age, or git revision")
+	return cmd
+}
+
+// reportCapacityCmd implements 'bits report capacity'.
+func reportCapacityCmd() *cobra.Command {
+	var since string
+	cmd := &cobra.Command{
+		Use:   "capacity",
+		Short: "Project how long each assignee's backlog will take",
+		Long: `Project how long the unfinished tasks assigned to each agent or person will
+take. Estimated tasks (see 'bits add --estimate') are split across as many at
+once as the assignee's WIP limit allows; unestimated tasks take as long as the
+assignee's close rate since --since says. Unestimated work by an assignee who
+closed nothing in the window cannot be projected and is counted separately.
+
+Unassigned tasks are left out; see 'bits assign'.`,
+		Args: cobra.NoArgs,
+		Run: func(_ *cobra.Command, _ []string) {
+			store, err := getStore()
+			if err != nil {
+				printError(err)
+			}
+
+			start, err := resolveSince(since)
+			if err != nil {
+				printError(err)
+			}
+
+			tasks, err := store.List(storage.StatusFilter{})
+			if err != nil {
+				printError(err)
+			}
+			capacities := report.Capacities(tasks, store.WIPLimits().For, start, time.Now().UTC())
+			printOutput(formatter.FormatCapacity(capacities))
+		},
+	}
+	cmd.Flags().StringVar(&since, "since", "14d", "Close rate window start: a date, age, or git revision")
+	return cmd
+}
+
+// worklogCmd implements 'bits worklog' command group.
+func worklogCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "worklog",
+		Short: "Time tracked from task claims",
+	}
+
+	cmd.AddCommand(
+		worklogExportCmd(),
+	)
+
+	return cmd
+}
+
+// worklogExportCmd implements 'bits worklog export'.
+func worklogExportCmd() *cobra.Command {
+	var since, format string
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Export time-tracking intervals as CSV or JSON",
+		Long: `Export each span of time a task was claimed, from claim to release or close,
+with the actor who held it. Intervals still in progress end now; intervals
+that began before --since are clipped to it.
+
+--since accepts a date (2006-01-02), an age (7d, 2w), or a git revision.`,
+		Run: func(_ *cobra.Command, _ []string) {
+			store, err := getStore()
+			if err != nil {
+				printError(err)
+			}
+
+			start, err := resolveSince(since)
+			if err != nil {
+				printError(err)
+			}
+
+			tasks, err := store.List(storage.StatusFilter{})
+			if err != nil {
+				printError(err)
+			}
+			entries := report.Worklog(tasks, start, time.Now().UTC())
+
+			switch format {
+			case "csv":
+				printOutput(output.FormatWorklogCSV(entries))
+			case "json":
+				printOutput(output.NewJSONFormatter().FormatWorklog(entries))
+			case "yaml":
+				printOutput(output.NewYAMLFormatter().FormatWorklog(entries))
+			case "human":
+				printOutput(formatter.FormatWorklog(entries))
+			default:
+				printError(InvalidOutputFormatError{Value: format, Valid: []string{"csv", "json", "yaml", "human"}})
+			}
+		},
+	}
+	cmd.Flags().StringVar(&since, "since", "", "Only time since a date, age, or git revision")
+	cmd.Flags().StringVar(&format, "format", "csv", "Output format: csv, json, yaml, or human")
+	return cmd
+}
+
+// resolveSince parses a --since value, falling back to the commit time of a
+// git revision. An empty value means the beginning of time.
+func resolveSince(since string) (time.Time, error) {
+	if since == "" {
+		return time.Time{}, nil
+	}
+	start, err := report.ParseSince(since, time.Now().UTC())
+	if err == nil {
+		return start, nil
+	}
+	if commitTime, gitErr := git.CommitTime("", since); gitErr == nil {
+		return commitTime, nil
+	}
+	return time.Time{}, err
+}
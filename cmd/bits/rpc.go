@@ -0,0 +1,335 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/abatilo/bits/internal/output"
+	"github.com/abatilo/bits/internal/rpc"
+	"github.com/abatilo/bits/internal/storage"
+	"github.com/abatilo/bits/internal/task"
+)
+
+// rpcCmd implements 'bits rpc'.
+func rpcCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "rpc",
+		Short: "Serve JSON-RPC requests on stdin and stdout",
+		Long: `Speak newline-delimited JSON-RPC 2.0 on stdin and stdout, one request or
+response per line, so long-running tools can query and update tasks without
+starting a process per command. Methods mirror the CLI and return tasks in the
+same shape as --json:
+
+  list     {"open", "active", "closed": bool, "order": "ready|priority|created"}
+  ready    {}
+  show     {"id"}
+  create   {"title", "description", "priority", "tags", "due", "verify",
+            "on_claim", "on_close", "accept"}
+  claim    {"id", "force": bool}
+  release  {"id"}
+  close    {"id", "reason", "verify": bool, "no_verify": bool}
+  dep      {"id", "depends_on": [ids], "any": bool, "hint": bool, "weight": int}
+
+create, claim, release, and close also take an "actor" to record in task
+history instead of the server's own; create, claim, and close take "no_hooks":
+true to skip the webhooks, as --no-hooks does.
+
+After a "subscribe" request, the connection also receives an "event"
+notification for each task created, claimed, released, closed, or given
+dependencies through the server: {"type", "at", "task"}.
+
+Warnings and script output go to stderr. The server exits at end of input.`,
+		Args: cobra.NoArgs,
+		Run: func(_ *cobra.Command, _ []string) {
+			store, err := getStore()
+			if err != nil {
+				printError(err)
+			}
+			if err = newRPCServer(store).Serve(os.Stdin, os.Stdout); err != nil {
+				printError(err)
+			}
+		},
+	}
+}
+
+// idParams are the params of methods that act on one task. Actor, if set,
+// is recorded in task history in place of the server's own identity.
+type idParams struct {
+	ID    string `json:"id"`
+	Actor string `json:"actor,omitempty"`
+}
+
+// listParams are the params of the list method.
+type listParams struct {
+	storage.StatusFilter
+	Order   string   `json:"order,omitempty"`
+	Tags    []string `json:"tags,omitempty"` // Tasks must have every one
+	Overdue bool     `json:"overdue,omitempty"`
+}
+
+// createParams are the params of the create method. NoHooks, like
+// --no-hooks, skips the webhooks for the change.
+type createParams struct {
+	Title string `json:"title"`
+	addOptions
+	Actor   string `json:"actor,omitempty"`
+	NoHooks bool   `json:"no_hooks,omitempty"`
+}
+
+// claimParams are the params of the claim method.
+type claimParams struct {
+	ID      string `json:"id"`
+	Force   bool   `json:"force,omitempty"`
+	Actor   string `json:"actor,omitempty"`
+	NoHooks bool   `json:"no_hooks,omitempty"`
+}
+
+// closeParams are the params of the close method.
+type closeParams struct {
+	ID       string `json:"id"`
+	Reason   string `json:"reason"`
+	Verify   bool   `json:"verify,omitempty"`
+	NoVerify bool   `json:"no_verify,omitempty"`
+	Actor    string `json:"actor,omitempty"`
+	NoHooks  bool   `json:"no_hooks,omitempty"`
+}
+
+// eventMethod is the notification method subscribers receive task events on.
+const eventMethod = "event"
+
+// taskEvent is a task change broadcast to subscribers.
+type taskEvent struct {
+	Type string          `json:"type"` // create, claim, release, close, or dep
+	At   string          `json:"at"`
+	Task json.RawMessage `json:"task"`
+}
+
+// asCaller runs fn with actor, if set, recorded in task history, and with
+// webhooks skipped if the caller passed --no-hooks. Requests are handled one
+// at a time, so swapping the globals is safe.
+func asCaller(actor string, skipHooks bool, fn func() error) error {
+	savedActor, savedNoHooks := actorFlag, noHooks
+	defer func() { actorFlag, noHooks = savedActor, savedNoHooks }()
+	if actor != "" {
+		actorFlag = actor
+	}
+	noHooks = noHooks || skipHooks
+	return fn()
+}
+
+// newRPCServer registers the task methods served by 'bits rpc'.
+func newRPCServer(store *storage.Store) *rpc.Server {
+	tasks := output.NewJSONFormatter()
+	taskResult := func(t *task.Task) json.RawMessage {
+		return json.RawMessage(tasks.FormatTask(t))
+	}
+	listResult := func(ts []*task.Task) json.RawMessage {
+		return json.RawMessage(tasks.FormatTaskList(ts))
+	}
+	load := func(params json.RawMessage, p any, id *string) (*task.Task, error) {
+		if err := rpc.DecodeParams(params, p); err != nil {
+			return nil, err
+		}
+		if *id == "" {
+			return nil, rpc.Error{Code: rpc.CodeInvalidParams, Message: "invalid params: id is required"}
+		}
+		return store.Load(*id)
+	}
+
+	server := rpc.NewServer()
+	s := tracedServer{server}
+	publish := func(kind string, t *task.Task) {
+		_ = server.Broadcast(eventMethod, taskEvent{
+			Type: kind,
+			At:   time.Now().UTC().Format(time.RFC3339),
+			Task: taskResult(t),
+		}) // A task result always marshals
+	}
+
+	s.Handle("list", escalating(store, func(params json.RawMessage) (any, error) {
+		p := listParams{Order: "ready"}
+		if err := rpc.DecodeParams(params, &p); err != nil {
+			return nil, err
+		}
+		tags, err := normalizeTags(p.Tags)
+		if err != nil {
+			return nil, err
+		}
+		ts, err := listTasks(store, p.StatusFilter, p.Order)
+		if err != nil {
+			return nil, err
+		}
+		ts = withTags(ts, tags)
+		if p.Overdue {
+			ts = overdueTasks(ts, time.Now())
+		}
+		return listResult(ts), nil
+	}))
+	s.Handle("ready", escalating(store, func(params json.RawMessage) (any, error) {
+		if err := rpc.DecodeParams(params, &struct{}{}); err != nil {
+			return nil, err
+		}
+		live, err := store.List(storage.StatusFilter{Open: true, Active: true})
+		if err != nil {
+			return nil, err
+		}
+		if err = autoEscalate(store, live); err != nil {
+			return nil, err
+		}
+		return listResult(newGraph(store, live).Ready()), nil
+	}))
+	s.Handle("show", func(params json.RawMessage) (any, error) {
+		var p idParams
+		t, err := load(params, &p, &p.ID)
+		if err != nil {
+			return nil, err
+		}
+		if t.Children, err = store.Children(t.ID, storage.StatusFilter{}); err != nil {
+			return nil, err
+		}
+		return taskResult(t), nil
+	})
+	s.Handle("create", locked(store, "add", func(params json.RawMessage) (any, error) {
+		var p createParams
+		if err := rpc.DecodeParams(params, &p); err != nil {
+			return nil, err
+		}
+		if p.Title == "" {
+			return nil, rpc.Error{Code: rpc.CodeInvalidParams, Message: "invalid params: title is required"}
+		}
+		var t *task.Task
+		err := asCaller(p.Actor, p.NoHooks, func() error {
+			var addErr error
+			t, addErr = addTask(store, p.Title, p.addOptions)
+			return addErr
+		})
+		if err != nil {
+			return nil, err
+		}
+		publish("create", t)
+		return taskResult(t), nil
+	}))
+	s.Handle("claim", locked(store, "claim", func(params json.RawMessage) (any, error) {
+		var p claimParams
+		t, err := load(params, &p, &p.ID)
+		if err == nil {
+			err = asCaller(p.Actor, p.NoHooks, func() error { return claimTask(store, t, p.Force) })
+		}
+		if err != nil {
+			return nil, err
+		}
+		publish(string(task.EventClaim), t)
+		return taskResult(t), nil
+	}))
+	s.Handle("release", locked(store, "release", func(params json.RawMessage) (any, error) {
+		var p idParams
+		t, err := load(params, &p, &p.ID)
+		if err == nil {
+			err = asCaller(p.Actor, false, func() error { return releaseTask(store, t) })
+		}
+		if err != nil {
+			return nil, err
+		}
+		publish(string(task.EventRelease), t)
+		return taskResult(t), nil
+	}))
+	s.Handle("close", locked(store, "close", func(params json.RawMessage) (any, error) {
+		var p closeParams
+		t, err := load(params, &p, &p.ID)
+		if err == nil {
+			err = asCaller(p.Actor, p.NoHooks, func() error {
+				return closeTask(store, t, p.Reason, p.Verify, p.NoVerify)
+			})
+		}
+		if err != nil {
+			return nil, err
+		}
+		publish(string(task.EventClose), t)
+		return taskResult(t), nil
+	}))
+	s.Handle("dep", locked(store, "dep", func(params json.RawMessage) (any, error) {
+		var p struct {
+			ID        string   `json:"id"`
+			DependsOn []string `json:"depends_on"`
+			Any       bool     `json:"any"`
+			Hint      bool     `json:"hint"`
+			Weight    int      `json:"weight"`
+		}
+		if err := rpc.DecodeParams(params, &p); err != nil {
+			return nil, err
+		}
+		if p.ID == "" || len(p.DependsOn) == 0 {
+			return nil, rpc.Error{
+				Code:    rpc.CodeInvalidParams,
+				Message: "invalid params: id and depends_on are required",
+			}
+		}
+		t, changed, err := dependOn(store, p.ID, p.DependsOn, depOptions{AnyOf: p.Any, Hint: p.Hint, Weight: p.Weight})
+		if err != nil {
+			return nil, err
+		}
+		if changed {
+			publish("dep", t)
+		}
+		return taskResult(t), nil
+	}))
+	return server
+}
+
+// locked wraps a handler that writes to the store so it holds the store's
+// lock and journals its changes as op, like the CLI's own writes. The entry's
+// actor is the request's "actor" param, if any.
+func locked(store *storage.Store, op string, h rpc.Handler) rpc.Handler {
+	return func(params json.RawMessage) (any, error) {
+		unlock, err := store.Lock()
+		if err != nil {
+			return nil, err
+		}
+		defer unlock()
+
+		var p struct {
+			Actor string `json:"actor"`
+		}
+		_ = json.Unmarshal(params, &p) // The handler reports malformed params
+		actor := p.Actor
+		if actor == "" {
+			actor = currentActor(store)
+		}
+		store.BeginOp(op, actor)
+		result, err := h(params)
+		if endErr := store.EndOp(); endErr != nil {
+			printWarning(endErr)
+		}
+		return result, err
+	}
+}
+
+// escalating is locked for read handlers that escalate overdue tasks or mark
+// SLA breaches as they list, when the config has them do so.
+func escalating(store *storage.Store, h rpc.Handler) rpc.Handler {
+	if !cfg.Escalate.Auto && !cfg.SLA.Notify {
+		return h
+	}
+	return locked(store, "escalate", h)
+}
+
+// tracedServer registers handlers that each record a span, exported as soon
+// as the request finishes.
+type tracedServer struct {
+	*rpc.Server
+}
+
+// Handle registers h for method, traced as "rpc <method>".
+func (s tracedServer) Handle(method string, h rpc.Handler) {
+	s.Server.Handle(method, func(params json.RawMessage) (any, error) {
+		span := tracer.Start("rpc " + method)
+		result, err := h(params)
+		span.RecordError(err)
+		span.End()
+		flushTelemetry()
+		return result, err
+	})
+}
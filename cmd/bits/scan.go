@@ -0,0 +1,120 @@
+package main
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/abatilo/bits/internal/git"
+	"github.com/abatilo/bits/internal/scan"
+	"github.com/abatilo/bits/internal/storage"
+	"github.com/abatilo/bits/internal/task"
+)
+
+// scanCmd implements 'bits scan'.
+func scanCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "scan",
+		Short: "Track TODO(bits) and FIXME comments as tasks",
+		Long: `Walk the files git tracks (and untracked ones it doesn't ignore) for
+TODO(bits) and FIXME comments, and keep one task per comment, all or nothing:
+
+  // TODO(bits): retry when the upload times out
+  # FIXME(alice) handle an empty config
+
+A new comment gets a task titled with its text and tagged todo or fixme, with
+the file and line as its source. Running scan again skips comments already
+tracked, updates the line of ones that moved, and closes tasks whose comment
+is gone. Closing a task by hand while its comment stays keeps it closed.`,
+		Args: cobra.NoArgs,
+		Run: func(_ *cobra.Command, _ []string) {
+			store, err := getStore()
+			if err != nil {
+				printError(err)
+			}
+			root, err := storage.FindProjectRoot()
+			if err != nil {
+				printError(err)
+			}
+			comments, err := scanComments(root)
+			if err != nil {
+				printError(err)
+			}
+			lockStore(store)
+
+			changes, err := applyScan(store, comments)
+			if err != nil {
+				printError(err)
+			}
+			printOutput(formatter.FormatScan(changes))
+		},
+	}
+}
+
+// scanComments finds the TODO(bits) and FIXME comments in the project at root.
+// Files git lists but that are gone from the work tree are skipped.
+func scanComments(root string) ([]scan.Comment, error) {
+	files, err := git.Files(root)
+	if err != nil {
+		return nil, err
+	}
+	var comments []scan.Comment
+	for _, file := range files {
+		content, readErr := os.ReadFile(filepath.Join(root, filepath.FromSlash(file)))
+		if os.IsNotExist(readErr) {
+			continue
+		}
+		if readErr != nil {
+			return nil, readErr
+		}
+		comments = append(comments, scan.Find(file, content)...)
+	}
+	return comments, nil
+}
+
+// applyScan stages the changes that make the store track comments and commits
+// them together, returning the changes with the IDs of created tasks filled in.
+func applyScan(store *storage.Store, comments []scan.Comment) ([]scan.Change, error) {
+	b, err := newBatch(store)
+	if err != nil {
+		return nil, err
+	}
+	changes := scan.Reconcile(comments, slices.Collect(maps.Values(b.tasks)))
+
+	now := time.Now().UTC()
+	for i, c := range changes {
+		switch c.Kind {
+		case scan.KindCreate:
+			t, buildErr := buildTask(store, c.Title, addOptions{Tags: []string{strings.ToLower(c.Marker)}})
+			if buildErr != nil {
+				return nil, buildErr
+			}
+			t.Source = c.Source()
+			b.tasks[t.ID] = t
+			changes[i].ID = t.ID
+			b.touch(t)
+		case scan.KindMove:
+			t := b.tasks[c.ID]
+			t.Source = c.Source()
+			b.touch(t)
+		case scan.KindClose:
+			t := b.tasks[c.ID]
+			t.Close(now, scan.ReasonRemoved, currentActor(store))
+			b.closed = append(b.closed, t)
+			b.touch(t)
+		}
+	}
+
+	if err = b.commit(); err != nil {
+		return nil, err
+	}
+	for _, t := range b.closed {
+		runLifecycle(task.EventClose, t)
+	}
+	return changes, nil
+}
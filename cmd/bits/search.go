@@ -0,0 +1,40 @@
+package main
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/abatilo/bits/internal/storage"
+)
+
+// searchCmd implements 'bits search'.
+func searchCmd() *cobra.Command {
+	var archived, regex, caseSensitive bool
+	cmd := &cobra.Command{
+		Use:   "search <query>",
+		Short: "Find tasks by text",
+		Long: `List tasks whose title, description, or close reason contains the query,
+ignoring case unless --case-sensitive is set. With --regex the query is a Go
+regular expression (https://pkg.go.dev/regexp/syntax), such as 'time ?outs?'.
+--archived also searches tasks moved out by 'bits compact'.`,
+		Args: cobra.ExactArgs(1),
+		Run: func(_ *cobra.Command, args []string) {
+			match, err := storage.TextMatcher(args[0], regex, caseSensitive)
+			if err != nil {
+				printError(err)
+			}
+			store, err := getStore()
+			if err != nil {
+				printError(err)
+			}
+			matches, err := store.Search(match, archived)
+			if err != nil {
+				printError(err)
+			}
+			printOutput(formatter.FormatTaskList(matches))
+		},
+	}
+	cmd.Flags().BoolVar(&archived, "archived", false, "Also search archived tasks")
+	cmd.Flags().BoolVarP(&regex, "regex", "E", false, "Treat the query as a regular expression")
+	cmd.Flags().BoolVarP(&caseSensitive, "case-sensitive", "s", false, "Match case exactly")
+	return cmd
+}
@@ -0,0 +1,340 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/abatilo/bits/internal/output"
+	"github.com/abatilo/bits/internal/rpc"
+	"github.com/abatilo/bits/internal/storage"
+	"github.com/abatilo/bits/internal/task"
+)
+
+const (
+	serveBodyLimit     = 1 << 20
+	serveHeaderTimeout = 10 * time.Second
+)
+
+// serveCmd implements 'bits serve'.
+func serveCmd() *cobra.Command {
+	var addr string
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Serve the task store as a JSON API over HTTP",
+		Long: `Serve the current project's task store as a JSON API over HTTP, for
+dashboards, editor plugins, and other programs that would otherwise run a
+command per request. Requests are handled one at a time, and tasks come back
+in the same shape as --json.
+
+  GET    /tasks                ?status=open,active,closed  &order=  &tag=  &overdue=true
+  POST   /tasks                {"title", "description", "priority", "tags", "due", ...}
+  GET    /tasks/{id}
+  PATCH  /tasks/{id}           {"title", "description", "priority"}
+  DELETE /tasks/{id}
+  POST   /tasks/{id}/claim     {"force", "actor"}
+  POST   /tasks/{id}/release   {"actor"}
+  POST   /tasks/{id}/close     {"reason", "verify", "no_verify", "actor"}
+  POST   /tasks/{id}/deps      {"depends_on", "any", "hint", "weight"}
+  GET    /ready
+  GET    /graph                ?all=true
+  GET    /events
+
+Bodies take the same params as the 'bits rpc' methods. Errors come back as
+{"error"} with status 400 for a malformed request, 404 for an unknown task,
+and 409 when bits refuses the change. GET /events is a server-sent event
+stream of the changes made through the API, each a 'bits events' line. The
+API has no authentication, so it
+listens on localhost unless --addr says otherwise. Stop it with Ctrl-C or
+SIGTERM.`,
+		Example: "  bits serve\n" +
+			"  curl localhost:8080/ready",
+		Args: cobra.NoArgs,
+		Run: func(_ *cobra.Command, _ []string) {
+			store, err := getStore()
+			if err != nil {
+				printError(err)
+			}
+			if err = store.EnsureInitialized(); err != nil {
+				printError(err)
+			}
+
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			server := &http.Server{
+				Addr:              addr,
+				Handler:           newRESTHandler(ctx, store),
+				ReadHeaderTimeout: serveHeaderTimeout,
+			}
+			go func() {
+				<-ctx.Done()
+				_ = server.Shutdown(context.Background())
+			}()
+
+			printOutput(formatter.FormatMessage("Serving " + store.BasePath() + " on http://" + addr))
+			finishTelemetry(nil) // Each request is traced on its own from here on
+			if err = server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+				printError(err)
+			}
+		},
+	}
+	cmd.Flags().StringVar(&addr, "addr", "localhost:8080", "Address to listen on, such as :8080 for every interface")
+	return cmd
+}
+
+// updateParams are the params of the update method; fields left out keep
+// their values.
+type updateParams struct {
+	ID          string  `json:"id"`
+	Title       *string `json:"title,omitempty"`
+	Description *string `json:"description,omitempty"`
+	Priority    *string `json:"priority,omitempty"`
+}
+
+// newRESTHandler routes the endpoints of 'bits serve' to the methods of
+// 'bits rpc', plus update, remove, and graph. Event streams end with ctx, so
+// they don't hold up shutdown.
+func newRESTHandler(ctx context.Context, store *storage.Store) http.Handler {
+	methods := newRPCServer(store)
+	tasks := output.NewJSONFormatter()
+	s := tracedServer{methods}
+	s.Handle("update", locked(store, "edit", func(params json.RawMessage) (any, error) {
+		var p updateParams
+		if err := rpc.DecodeParams(params, &p); err != nil {
+			return nil, err
+		}
+		t, err := store.Load(p.ID)
+		if err != nil {
+			return nil, err
+		}
+		if p.Title != nil {
+			t.Title = *p.Title
+		}
+		if p.Description != nil {
+			t.Description = *p.Description
+		}
+		if p.Priority != nil {
+			t.Priority = task.Priority(*p.Priority)
+		}
+		if err = validateEdit(t); err != nil {
+			return nil, err
+		}
+		if err = store.Save(t); err != nil {
+			return nil, err
+		}
+		return json.RawMessage(tasks.FormatTask(t)), nil
+	}))
+	s.Handle("remove", locked(store, "rm", func(params json.RawMessage) (any, error) {
+		var p idParams
+		if err := rpc.DecodeParams(params, &p); err != nil {
+			return nil, err
+		}
+		if _, err := store.Load(p.ID); err != nil {
+			return nil, err
+		}
+		if _, err := store.Snapshot("rm"); err != nil {
+			return nil, err
+		}
+		if err := store.RemoveDependency(p.ID); err != nil {
+			return nil, err
+		}
+		return nil, store.Delete(p.ID)
+	}))
+	s.Handle("graph", escalating(store, func(params json.RawMessage) (any, error) {
+		var p struct {
+			All bool `json:"all"`
+		}
+		if err := rpc.DecodeParams(params, &p); err != nil {
+			return nil, err
+		}
+		filter := storage.StatusFilter{Open: true, Active: true}
+		if p.All {
+			filter = storage.StatusFilter{}
+		}
+		ts, err := listTasks(store, filter, "created")
+		if err != nil {
+			return nil, err
+		}
+		return json.RawMessage(tasks.FormatGraph(ts)), nil
+	}))
+
+	mux := http.NewServeMux()
+	route := func(pattern, method string, status int, params func(*http.Request) (map[string]any, error)) {
+		mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+			p, err := params(r)
+			var raw []byte
+			if err == nil {
+				if id := r.PathValue("id"); id != "" {
+					p["id"] = id
+				}
+				raw, err = json.Marshal(p)
+			}
+			if err != nil {
+				writeREST(w, restStatus(err), errorBody(tasks, err))
+				return
+			}
+			result, err := methods.Call(method, raw)
+			if err != nil {
+				writeREST(w, restStatus(err), errorBody(tasks, err))
+				return
+			}
+			if result == nil {
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+			body, err := json.Marshal(result)
+			if err != nil {
+				writeREST(w, http.StatusInternalServerError, errorBody(tasks, err))
+				return
+			}
+			writeREST(w, status, body)
+		})
+	}
+	none := func(*http.Request) (map[string]any, error) { return map[string]any{}, nil }
+
+	route("GET /tasks", "list", http.StatusOK, listQuery)
+	route("POST /tasks", "create", http.StatusCreated, restBody)
+	route("GET /tasks/{id}", "show", http.StatusOK, none)
+	route("PATCH /tasks/{id}", "update", http.StatusOK, restBody)
+	route("DELETE /tasks/{id}", "remove", http.StatusNoContent, none)
+	route("POST /tasks/{id}/claim", "claim", http.StatusOK, restBody)
+	route("POST /tasks/{id}/release", "release", http.StatusOK, restBody)
+	route("POST /tasks/{id}/close", "close", http.StatusOK, restBody)
+	route("POST /tasks/{id}/deps", "dep", http.StatusOK, restBody)
+	route("GET /ready", "ready", http.StatusOK, none)
+	route("GET /graph", "graph", http.StatusOK, func(r *http.Request) (map[string]any, error) {
+		all, err := queryBool(r, "all")
+		return map[string]any{"all": all}, err
+	})
+	mux.HandleFunc("GET /events", func(w http.ResponseWriter, r *http.Request) {
+		streamEvents(ctx, w, r, methods)
+	})
+	return mux
+}
+
+// streamEvents relays the task events broadcast by methods to the client as
+// server-sent events, until the client disconnects or ctx ends.
+func streamEvents(ctx context.Context, w http.ResponseWriter, r *http.Request, methods *rpc.Server) {
+	events, unsubscribe := methods.Subscribe()
+	defer unsubscribe()
+	flusher, _ := w.(http.Flusher) // Every ResponseWriter from net/http is one
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-r.Context().Done():
+			return
+		case n := <-events:
+			if n.Method != eventMethod {
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "data: %s\n\n", n.Params); err != nil {
+				return // The client hung up
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// restBody decodes a request's JSON object body into params. An empty body
+// has none.
+func restBody(r *http.Request) (map[string]any, error) {
+	params := map[string]any{}
+	data, err := io.ReadAll(io.LimitReader(r.Body, serveBodyLimit))
+	if err != nil || len(strings.TrimSpace(string(data))) == 0 {
+		return params, err
+	}
+	if err = json.Unmarshal(data, &params); err != nil || params == nil {
+		return nil, invalidParams("body must be a JSON object")
+	}
+	return params, nil
+}
+
+// listQuery returns the list params given by the query of GET /tasks.
+func listQuery(r *http.Request) (map[string]any, error) {
+	query := r.URL.Query()
+	params := map[string]any{}
+	for _, s := range strings.Split(query.Get("status"), ",") {
+		switch s {
+		case "":
+		case string(task.StatusOpen), string(task.StatusActive), string(task.StatusClosed):
+			params[s] = true
+		default:
+			return nil, invalidParams("invalid status: " + s)
+		}
+	}
+	if order := query.Get("order"); order != "" {
+		params["order"] = order
+	}
+	if tags := query["tag"]; len(tags) > 0 {
+		params["tags"] = tags
+	}
+	overdue, err := queryBool(r, "overdue")
+	if overdue {
+		params["overdue"] = true
+	}
+	return params, err
+}
+
+// queryBool reads a true or false query parameter, false when missing.
+func queryBool(r *http.Request, name string) (bool, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return false, nil
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, invalidParams("invalid " + name + ": " + value)
+	}
+	return b, nil
+}
+
+// invalidParams returns the error for a request the API can't understand.
+func invalidParams(message string) error {
+	return rpc.Error{Code: rpc.CodeInvalidParams, Message: message}
+}
+
+// restStatus returns the HTTP status for a method's error.
+func restStatus(err error) int {
+	var notFound storage.TaskNotFoundError
+	var rpcErr rpc.Error
+	switch {
+	case errors.As(err, &notFound):
+		return http.StatusNotFound
+	case errors.As(err, &rpcErr) && rpcErr.Code == rpc.CodeInvalidParams:
+		return http.StatusBadRequest
+	default:
+		return http.StatusConflict
+	}
+}
+
+// errorBody returns the JSON body reporting err, as --json prints errors.
+func errorBody(f *output.JSONFormatter, err error) []byte {
+	var body bytes.Buffer
+	_ = json.Compact(&body, []byte(f.FormatError(err))) // The formatter's JSON is always valid
+	return body.Bytes()
+}
+
+// writeREST writes a JSON response.
+func writeREST(w http.ResponseWriter, status int, body []byte) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, _ = w.Write(body) // The client hung up
+}
@@ -0,0 +1,113 @@
+//nolint:testpackage // Tests require internal access for thorough testing
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestServeRoutes(t *testing.T) {
+	store := newTestStore(t)
+	server := httptest.NewServer(newRESTHandler(context.Background(), store))
+	defer server.Close()
+
+	do := func(method, path, body string) (int, string) {
+		t.Helper()
+		req, err := http.NewRequest(method, server.URL+path, strings.NewReader(body))
+		if err != nil {
+			t.Fatalf("NewRequest failed: %v", err)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("%s %s failed: %v", method, path, err)
+		}
+		defer resp.Body.Close()
+		data, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatalf("reading %s %s failed: %v", method, path, err)
+		}
+		return resp.StatusCode, string(data)
+	}
+	var created struct {
+		ID     string `json:"id"`
+		Status string `json:"status"`
+	}
+
+	status, body := do(http.MethodPost, "/tasks", `{"title": "Fix login", "priority": "high"}`)
+	if status != http.StatusCreated {
+		t.Fatalf("POST /tasks = %d %s, want 201", status, body)
+	}
+	if err := json.Unmarshal([]byte(body), &created); err != nil || created.ID == "" {
+		t.Fatalf("POST /tasks body = %s, want a task: %v", body, err)
+	}
+
+	tests := []struct {
+		name, method, path, body string
+		status                   int
+		contains                 string
+	}{
+		{"list", http.MethodGet, "/tasks?status=open", "", http.StatusOK, `"title":"Fix login"`},
+		{"list closed", http.MethodGet, "/tasks?status=closed", "", http.StatusOK, `[]`},
+		{"show", http.MethodGet, "/tasks/" + created.ID, "", http.StatusOK, `"priority":"high"`},
+		{"ready", http.MethodGet, "/ready", "", http.StatusOK, created.ID},
+		{"bad status", http.MethodGet, "/tasks?status=done", "", http.StatusBadRequest, `invalid status: done`},
+		{"bad body", http.MethodPost, "/tasks", `[1]`, http.StatusBadRequest, `body must be a JSON object`},
+		{"missing title", http.MethodPost, "/tasks", `{}`, http.StatusBadRequest, `title is required`},
+		{"unknown task", http.MethodGet, "/tasks/zzz", "", http.StatusNotFound, `"error"`},
+		{"close open task", http.MethodPost, "/tasks/" + created.ID + "/close", `{"reason": "Done"}`,
+			http.StatusConflict, `"error"`},
+		{"claim", http.MethodPost, "/tasks/" + created.ID + "/claim", "", http.StatusOK, `"status":"active"`},
+		{"close", http.MethodPost, "/tasks/" + created.ID + "/close", `{"reason": "Done"}`,
+			http.StatusOK, `"status":"closed"`},
+		{"remove", http.MethodDelete, "/tasks/" + created.ID, "", http.StatusNoContent, ""},
+		{"remove again", http.MethodDelete, "/tasks/" + created.ID, "", http.StatusNotFound, `"error"`},
+	}
+	for _, tt := range tests {
+		status, body = do(tt.method, tt.path, tt.body)
+		if status != tt.status || !strings.Contains(body, tt.contains) {
+			t.Errorf("%s: %s %s = %d %s, want %d containing %s", tt.name, tt.method, tt.path, status, body,
+				tt.status, tt.contains)
+		}
+	}
+}
+
+func TestServeEvents(t *testing.T) {
+	store := newTestStore(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	server := httptest.NewServer(newRESTHandler(ctx, store))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/events")
+	if err != nil {
+		t.Fatalf("GET /events failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if got := resp.Header.Get("Content-Type"); got != "text/event-stream" {
+		t.Errorf("Content-Type = %q, want text/event-stream", got)
+	}
+
+	created, err := http.Post(server.URL+"/tasks", "application/json", strings.NewReader(`{"title": "A"}`))
+	if err != nil {
+		t.Fatalf("POST /tasks failed: %v", err)
+	}
+	created.Body.Close()
+
+	line, err := bufio.NewReader(resp.Body).ReadString('\n')
+	if err != nil {
+		t.Fatalf("reading event failed: %v", err)
+	}
+	var event taskEvent
+	if err = json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &event); err != nil {
+		t.Fatalf("event %q isn't a task event: %v", line, err)
+	}
+	if event.Type != webhookCreate || !strings.Contains(string(event.Task), `"title":"A"`) {
+		t.Errorf("event = %+v, want the creation of A", event)
+	}
+}
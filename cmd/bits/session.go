@@ -8,6 +8,7 @@ import (
 
 	"github.com/abatilo/bits/internal/session"
 	"github.com/abatilo/bits/internal/storage"
+	"github.com/abatilo/bits/internal/task"
 )
 
 // sessionCmd implements 'bits session' command group.
@@ -159,18 +160,20 @@ func sessionHookCmd() *cobra.Command {
 			}
 
 			// Drain mode is active for primary session - check for remaining tasks
-			// Check for active tasks first
-			activeTasks, err := store.List(storage.StatusFilter{Active: true})
-			if err == nil && len(activeTasks) > 0 {
-				_, _ = os.Stdout.WriteString(formatActiveBlock(activeTasks[0]) + "\n")
-				return
-			}
-
-			// Check for open tasks
-			openTasks, err := store.List(storage.StatusFilter{Open: true})
-			if err == nil && len(openTasks) > 0 {
-				_, _ = os.Stdout.WriteString(formatOpenBlock(len(openTasks)) + "\n")
-				return
+			// in a single pass over the store
+			remaining, err := store.List(storage.StatusFilter{Open: true, Active: true})
+			if err == nil {
+				// Check for active tasks first
+				if active := task.FindActive(remaining); active != nil {
+					_, _ = os.Stdout.WriteString(formatActiveBlock(active) + "\n")
+					return
+				}
+
+				// Check for open tasks
+				if openCount := task.CountByStatus(remaining, task.StatusOpen); openCount > 0 {
+					_, _ = os.Stdout.WriteString(formatOpenBlock(openCount) + "\n")
+					return
+				}
 			}
 
 			// All tasks complete - deactivate drain mode and allow stop
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/abatilo/bits/internal/notify"
+	"github.com/abatilo/bits/internal/output"
+	"github.com/abatilo/bits/internal/storage"
+	"github.com/abatilo/bits/internal/task"
+)
+
+// notifySLABreaches sends a notification for each unfinished task newly past
+// the SLA target for its priority when sla.notify is set, marking the task so
+// the breach is only reported once. The task is reloaded and marked under the
+// store's lock, so two commands never both report it.
+func notifySLABreaches(store *storage.Store, tasks []*task.Task) error {
+	if !cfg.SLA.Notify {
+		return nil
+	}
+	targets, _ := cfg.SLA.Targets() // Validated when the config is loaded
+	now := time.Now().UTC()
+	for _, t := range tasks {
+		target, ok := targets[t.Priority]
+		if !ok || t.SLABreached || t.Status == task.StatusClosed || !t.BreachesSLA(target, now) {
+			continue
+		}
+		lockStore(store)
+		fresh, err := store.Load(t.ID)
+		if err != nil {
+			return err
+		}
+		if fresh.SLABreached || fresh.Priority != t.Priority || fresh.Status == task.StatusClosed {
+			*t = *fresh // Reported or changed by someone else meanwhile
+			continue
+		}
+		msg := fmt.Sprintf("Task %s (%s) is past its %s SLA of %s",
+			t.ID, t.Title, t.Priority, output.RelativeDuration(target))
+		err = notify.Send(cfg.Notify.Command, notify.Notification{
+			Kind:    "sla-breach",
+			TaskID:  t.ID,
+			Title:   t.Title,
+			Message: msg,
+		})
+		if err != nil {
+			return err
+		}
+		fresh.SLABreached = true
+		if err = store.Rewrite(fresh); err != nil {
+			return err
+		}
+		*t = *fresh
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// snapshotCmd implements 'bits snapshot' command group.
+func snapshotCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "snapshot",
+		Short: "Safety snapshots taken before destructive operations",
+	}
+
+	cmd.AddCommand(
+		snapshotListCmd(),
+		snapshotRestoreCmd(),
+	)
+
+	return cmd
+}
+
+// snapshotListCmd implements 'bits snapshot list'.
+func snapshotListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List available snapshots (newest first)",
+		Run: func(_ *cobra.Command, _ []string) {
+			store, err := getStore()
+			if err != nil {
+				printError(err)
+			}
+
+			snapshots, err := store.Snapshots()
+			if err != nil {
+				printError(err)
+			}
+			printOutput(formatter.FormatSnapshots(snapshots))
+		},
+	}
+}
+
+// snapshotRestoreCmd implements 'bits snapshot restore'.
+func snapshotRestoreCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "restore <name>",
+		Short: "Restore tasks from a snapshot",
+		Args:  cobra.ExactArgs(1),
+		Run: func(_ *cobra.Command, args []string) {
+			store, err := getStore()
+			if err != nil {
+				printError(err)
+			}
+			lockStore(store)
+
+			if err = store.RestoreSnapshot(args[0]); err != nil {
+				printError(err)
+			}
+			printOutput(formatter.FormatMessage(fmt.Sprintf("Restored snapshot %s", args[0])))
+		},
+	}
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/abatilo/bits/internal/storage"
+	"github.com/abatilo/bits/internal/task"
+)
+
+const defaultStaleDays = 30
+
+// staleCmd implements 'bits stale'.
+func staleCmd() *cobra.Command {
+	var days int
+	var tag string
+	var raise bool
+	cmd := &cobra.Command{
+		Use:   "stale",
+		Short: "List unfinished tasks nobody has touched in a while",
+		Long: `List open and active tasks with no activity (a status change, edit, note, or
+any other saved change) for --days, oldest activity first.
+
+--tag labels each stale task and --raise lifts it to at least the
+escalate.priority from the config file (default high), flagging it for review.
+Neither counts as activity, so the tasks stay stale until someone acts on them.`,
+		Args: cobra.NoArgs,
+		Run: func(_ *cobra.Command, _ []string) {
+			store, err := getStore()
+			if err != nil {
+				printError(err)
+			}
+			var normalized string
+			if tag != "" {
+				var ok bool
+				if normalized, ok = task.NormalizeTag(tag); !ok {
+					printError(InvalidTagError{Value: tag})
+				}
+			}
+			if normalized != "" || raise {
+				lockStore(store)
+			}
+
+			tasks, err := store.List(storage.StatusFilter{Open: true, Active: true})
+			if err != nil {
+				printError(err)
+			}
+			stale := staleTasks(tasks, time.Now().UTC().AddDate(0, 0, -days))
+			for _, t := range stale {
+				if markStale(t, normalized, raise) {
+					if err = store.Rewrite(t); err != nil {
+						printError(err)
+					}
+				}
+			}
+			printOutput(formatter.FormatTaskList(stale))
+		},
+	}
+	cmd.Flags().IntVar(&days, "days", defaultStaleDays, "Days without activity before a task is stale")
+	cmd.Flags().StringVar(&tag, "tag", "", "Add this tag to each stale task")
+	cmd.Flags().BoolVar(&raise, "raise", false, "Raise each stale task to at least escalate.priority")
+	return cmd
+}
+
+// staleTasks returns the tasks with no activity since cutoff, least recently
+// active first.
+func staleTasks(tasks []*task.Task, cutoff time.Time) []*task.Task {
+	var stale []*task.Task
+	for _, t := range tasks {
+		if t.IsStale(cutoff) {
+			stale = append(stale, t)
+		}
+	}
+	sort.SliceStable(stale, func(i, j int) bool {
+		return stale[i].LastActivity().Before(stale[j].LastActivity())
+	})
+	return stale
+}
+
+// markStale tags and raises a stale task as requested, reporting whether it
+// changed.
+func markStale(t *task.Task, tag string, raise bool) bool {
+	changed := false
+	if tag != "" && !slices.Contains(t.Tags, tag) {
+		t.Tags = append(t.Tags, tag)
+		changed = true
+	}
+	if target := cfg.Escalate.TargetPriority(); raise && task.PriorityOrder(t.Priority) > task.PriorityOrder(target) {
+		t.Priority = target
+		changed = true
+	}
+	return changed
+}
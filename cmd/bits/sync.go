@@ -0,0 +1,198 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/abatilo/bits/internal/linear"
+	"github.com/abatilo/bits/internal/storage"
+	"github.com/abatilo/bits/internal/task"
+)
+
+// syncCmd implements 'bits sync'.
+func syncCmd() *cobra.Command {
+	var remote string
+	cmd := &cobra.Command{
+		Use:   "sync",
+		Short: "Sync tasks with a remote directory",
+		Long: `Replay queued mutations to the sync remote and pull remote changes.
+
+Every mutation is recorded in a local outbox while a remote is configured, so
+work done while the remote is unreachable is replayed on the next sync.
+Operations carry unique IDs, so an interrupted sync never applies one twice.`,
+		Run: func(_ *cobra.Command, _ []string) {
+			store, err := getStore()
+			if err != nil {
+				printError(err)
+			}
+
+			if remote != "" {
+				if err = store.SetSyncRemote(remote); err != nil {
+					printError(err)
+				}
+			}
+
+			result, err := store.Sync()
+			if err != nil {
+				printError(err)
+			}
+			message := fmt.Sprintf("Synced with %s: pushed %d, pulled %d", store.SyncRemote(), result.Pushed, result.Pulled)
+			if result.Queued > 0 {
+				message += fmt.Sprintf("; %d local-only task(s) queued for the next sync", result.Queued)
+			}
+			printOutput(formatter.FormatMessage(message))
+		},
+	}
+	cmd.Flags().StringVar(&remote, "remote", "", "Configure the sync remote directory")
+	cmd.AddCommand(syncLinearCmd())
+	return cmd
+}
+
+// linearKeyEnv holds the API key 'bits sync linear' authenticates with.
+const linearKeyEnv = "LINEAR_API_KEY"
+
+// syncLinearCmd implements 'bits sync linear'.
+func syncLinearCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "linear",
+		Short: "Mirror your assigned Linear issues as tasks",
+		Long: `Pull the unfinished Linear issues assigned to you into the store, and push
+the status of tasks already pulled back to Linear. Each task records its
+issue in a linear_id field. Authenticates with a personal API key in
+` + linearKeyEnv + `.
+
+New issues become open tasks with their title, description, and priority
+(urgent is critical, no priority is medium). For a task already linked, the
+side changed last wins: a task claimed or closed since the issue was last
+updated moves the issue to its team's first started or completed state (or
+back to unstarted when released), and an issue completed or canceled in
+Linear since the task last changed closes the task. Closing this way doesn't
+run lifecycle scripts.
+
+With --dry-run, nothing is written here or in Linear.`,
+		Example: "  export " + linearKeyEnv + "=lin_api_...\n" +
+			"  bits sync linear",
+		Args: cobra.NoArgs,
+		Run: func(_ *cobra.Command, _ []string) {
+			store, err := getStore()
+			if err != nil {
+				printError(err)
+			}
+			key := os.Getenv(linearKeyEnv)
+			if key == "" {
+				printError(MissingLinearKeyError{})
+			}
+			lockStore(store)
+
+			result, err := syncLinear(store, linear.NewClient(key))
+			if err != nil {
+				printError(err)
+			}
+			printOutput(formatter.FormatMessage(fmt.Sprintf(
+				"Synced with Linear: created %d, closed %d, pushed %d",
+				result.Created, result.Closed, result.Pushed,
+			)))
+		},
+	}
+}
+
+// linearResult counts what 'bits sync linear' changed.
+type linearResult struct {
+	Created int // Tasks created for new issues
+	Closed  int // Tasks closed because their issue was
+	Pushed  int // Issues moved to match their task
+}
+
+// syncLinear pulls assigned issues into the store and reconciles the status
+// of linked tasks with their issues. Local changes are committed together
+// before any issue is updated.
+func syncLinear(store *storage.Store, client *linear.Client) (linearResult, error) {
+	var result linearResult
+	ctx := context.Background()
+	b, err := newBatch(store)
+	if err != nil {
+		return result, err
+	}
+	linked := make(map[string]*task.Task)
+	for _, t := range b.tasks {
+		if t.LinearID != "" {
+			linked[t.LinearID] = t
+		}
+	}
+	issues, err := client.Issues(ctx, slices.Sorted(maps.Keys(linked)))
+	if err != nil {
+		return result, err
+	}
+
+	type push struct {
+		issue linear.Issue
+		state linear.State
+	}
+	var pushes []push
+	now := time.Now().UTC()
+	for _, issue := range issues {
+		t := linked[issue.ID]
+		remote := issue.State.Status()
+		if t == nil || remote == t.Status {
+			continue
+		}
+		switch {
+		case t.UpdatedAt != nil && t.UpdatedAt.After(issue.UpdatedAt):
+			state, stateErr := issue.StateFor(t.Status)
+			if stateErr != nil {
+				printWarning(stateErr)
+				continue
+			}
+			pushes = append(pushes, push{issue: issue, state: state})
+		case remote == task.StatusClosed:
+			t.Close(now, "Closed in Linear as "+issue.State.Name, currentActor(store))
+			b.touch(t)
+			result.Closed++
+		}
+	}
+
+	assigned, err := client.AssignedIssues(ctx)
+	if err != nil {
+		return result, err
+	}
+	for _, issue := range assigned {
+		if linked[issue.ID] != nil {
+			continue
+		}
+		description := issue.Description
+		if issue.URL != "" {
+			description = strings.TrimSpace(description + "\n\n" + issue.URL)
+		}
+		t, buildErr := buildTask(store, issue.Title, addOptions{
+			Description: description,
+			Priority:    string(issue.TaskPriority()),
+		})
+		if buildErr != nil {
+			return result, LinearIssueError{Issue: issue.Identifier, Err: buildErr}
+		}
+		t.LinearID = issue.ID
+		b.tasks[t.ID] = t
+		b.touch(t)
+		result.Created++
+	}
+	if err = b.commit(); err != nil {
+		return result, err
+	}
+
+	for _, p := range pushes {
+		if dryRun {
+			printDryRun("move Linear issue %s to %s", p.issue.Identifier, p.state.Name)
+		} else if err = client.SetState(ctx, p.issue.ID, p.state.ID); err != nil {
+			return result, LinearIssueError{Issue: p.issue.Identifier, Err: err}
+		}
+		result.Pushed++
+	}
+	return result, nil
+}
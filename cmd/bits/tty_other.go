@@ -0,0 +1,9 @@
+//go:build !unix
+
+package main
+
+// stdinIsTerminal reports false on platforms without terminal detection, so
+// commands there never prompt and take their flags instead.
+func stdinIsTerminal() bool {
+	return false
+}
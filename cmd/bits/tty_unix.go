@@ -0,0 +1,18 @@
+//go:build unix
+
+package main
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// stdinIsTerminal reports whether stdin is a terminal, so a user is there to
+// answer prompts. /dev/null is a character device too, so this asks the
+// terminal for its size rather than checking the file mode.
+func stdinIsTerminal() bool {
+	fd := int(os.Stdin.Fd()) //nolint:gosec // G115: file descriptors fit in an int
+	_, err := unix.IoctlGetWinsize(fd, unix.TIOCGWINSZ)
+	return err == nil
+}
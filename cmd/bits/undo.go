@@ -0,0 +1,100 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/abatilo/bits/internal/storage"
+)
+
+// defaultHistoryLimit is how many journal entries 'bits history' shows.
+const defaultHistoryLimit = 20
+
+// undoCmd implements 'bits undo'.
+func undoCmd() *cobra.Command {
+	var force bool
+	cmd := &cobra.Command{
+		Use:   "undo [seq]",
+		Short: "Revert the last change to the store",
+		Long: `Revert the most recent command that changed tasks, restoring every task it
+created, changed, or removed, as recorded in the store's journal (see 'bits
+history'). Give a journal entry's number to revert that one instead. Running
+undo again reverts the command before, and an undo can itself be undone by
+number.
+
+Undo refuses when a task has changed since the entry, so later work isn't
+lost; undo the later entries first, or pass --force to restore the task
+anyway. Compaction isn't journaled, and the journal keeps the last 100 or so
+entries.`,
+		Args: cobra.MaximumNArgs(1),
+		Run: func(_ *cobra.Command, args []string) {
+			seq := 0
+			if len(args) == 1 {
+				n, convErr := strconv.Atoi(strings.TrimPrefix(args[0], "#"))
+				if convErr != nil || n < 1 {
+					printError(InvalidJournalSeqError{Value: args[0]})
+				}
+				seq = n
+			}
+
+			store, err := getStore()
+			if err != nil {
+				printError(err)
+			}
+			lockStore(store)
+			entry, err := store.Undo(seq, force)
+			if err != nil {
+				printError(err)
+			}
+			verb := "Undid"
+			if dryRun {
+				verb = "Would undo"
+			}
+			printOutput(formatter.FormatMessage(fmt.Sprintf("%s #%d %s: %s", verb, entry.Seq, entry.Op,
+				strings.Join(entry.TaskIDs(), ", "))))
+		},
+	}
+	cmd.Flags().BoolVar(&force, "force", false, "Undo even if the tasks changed since")
+	return cmd
+}
+
+// historyCmd implements 'bits history'.
+func historyCmd() *cobra.Command {
+	var limit int
+	var id string
+	cmd := &cobra.Command{
+		Use:   "history",
+		Short: "List recent changes to the store (newest first)",
+		Long: `List the store's journal, newest first: each command that changed tasks, with
+its number, time, actor, and the tasks it touched. Entries reverted by 'bits
+undo' are marked undone.`,
+		Args: cobra.NoArgs,
+		Run: func(_ *cobra.Command, _ []string) {
+			store, err := getStore()
+			if err != nil {
+				printError(err)
+			}
+
+			entries, err := store.Journal()
+			if err != nil {
+				printError(err)
+			}
+			if id != "" {
+				entries = slices.DeleteFunc(entries, func(e storage.JournalEntry) bool {
+					return !slices.Contains(e.TaskIDs(), id)
+				})
+			}
+			if limit > 0 && len(entries) > limit {
+				entries = entries[:limit]
+			}
+			printOutput(formatter.FormatJournal(entries))
+		},
+	}
+	cmd.Flags().IntVarP(&limit, "limit", "n", defaultHistoryLimit, "Maximum number of entries (0 for all)")
+	cmd.Flags().StringVar(&id, "task", "", "Only entries that changed this task")
+	return cmd
+}
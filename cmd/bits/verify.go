@@ -0,0 +1,44 @@
+package main
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+// verifyCmd implements 'bits verify'.
+func verifyCmd() *cobra.Command {
+	var accept bool
+	cmd := &cobra.Command{
+		Use:   "verify",
+		Short: "Check task files for corruption and out-of-band edits",
+		Long: `Compare every task file against the checksum recorded in the index and check
+task invariants. Exits non-zero if any issue is found.
+
+Use --accept after intentional manual edits to record the current files as
+the new baseline.`,
+		Run: func(_ *cobra.Command, _ []string) {
+			store, err := getStore()
+			if err != nil {
+				printError(err)
+			}
+
+			if accept {
+				if err = store.RebuildIndex(); err != nil {
+					printError(err)
+				}
+			}
+
+			issues, err := store.Verify()
+			if err != nil {
+				printError(err)
+			}
+			printOutput(formatter.FormatIssues(issues))
+			if len(issues) > 0 {
+				os.Exit(1)
+			}
+		},
+	}
+	cmd.Flags().BoolVar(&accept, "accept", false, "Record current files as the new baseline before verifying")
+	return cmd
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/abatilo/bits/internal/notify"
+	"github.com/abatilo/bits/internal/output"
+	"github.com/abatilo/bits/internal/task"
+)
+
+const (
+	// webhookCreate is the webhook event for a new task; claims and closes use
+	// their history actions.
+	webhookCreate = "create"
+	// webhookDeadline bounds a delivery, retries included.
+	webhookDeadline = 30 * time.Second
+)
+
+// webhooks tracks the deliveries still in flight; see drainWebhooks.
+var webhooks sync.WaitGroup //nolint:gochecknoglobals // Shared by every command's deliveries, like the flags
+
+// sendWebhooks posts event for t to each configured webhook, unless
+// --no-hooks is set. The payload has the shape of a 'bits events' line. Like
+// lifecycle scripts, it runs after the change is saved, so delivery failures
+// are only warned about. Posts are made in the background, so a slow endpoint
+// doesn't hold the store lock or stall the daemon's other clients. With
+// --dry-run the posts are only reported.
+func sendWebhooks(event string, t *task.Task) {
+	if noHooks || !cfg.Webhooks.Fires(event) {
+		return
+	}
+	payload := taskEvent{
+		Type: event,
+		At:   time.Now().UTC().Format(time.RFC3339),
+		Task: json.RawMessage(output.NewJSONFormatter().FormatTask(t)),
+	}
+	timeout, _ := cfg.Webhooks.TimeoutDuration() // Validated when the config is loaded
+	for _, url := range cfg.Webhooks.URLs {
+		if dryRun {
+			printDryRun("post %s webhook to %s", event, url)
+			continue
+		}
+		hook := notify.NewWebhook(url, cfg.Webhooks.RetryCount(), timeout)
+		webhooks.Go(func() {
+			ctx, cancel := context.WithTimeout(context.Background(), webhookDeadline)
+			defer cancel()
+			if err := hook.Post(ctx, payload); err != nil {
+				printWarning(err)
+			}
+		})
+	}
+}
+
+// drainWebhooks waits for the webhooks still being delivered, which is
+// bounded by webhookDeadline. It is called once the store is unlocked.
+func drainWebhooks() {
+	webhooks.Wait()
+}
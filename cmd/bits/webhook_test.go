@@ -0,0 +1,85 @@
+//nolint:testpackage // Tests require internal access for thorough testing
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/abatilo/bits/internal/task"
+)
+
+func TestSendWebhooksInBackground(t *testing.T) {
+	store := newTestStore(t)
+	var received atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+		received.Add(1)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+	cfg.Webhooks.URLs = []string{server.URL}
+
+	tk, err := store.CreateTask("A", "", task.PriorityMedium)
+	if err != nil {
+		t.Fatalf("CreateTask failed: %v", err)
+	}
+	start := time.Now()
+	sendWebhooks(webhookCreate, tk)
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Errorf("sendWebhooks took %s, want it to return before the post finishes", elapsed)
+	}
+	drainWebhooks()
+	if received.Load() != 1 {
+		t.Errorf("webhook received %d post(s) after draining, want 1", received.Load())
+	}
+}
+
+func TestDaemonHonorsNoHooks(t *testing.T) {
+	store := newTestStore(t)
+	var received atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		received.Add(1)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+	cfg.Webhooks.URLs = []string{server.URL}
+	methods := newRPCServer(store)
+	call := func(method string, params any) json.RawMessage {
+		t.Helper()
+		raw, err := json.Marshal(params)
+		if err != nil {
+			t.Fatalf("Marshal failed: %v", err)
+		}
+		result, err := methods.Call(method, raw)
+		if err != nil {
+			t.Fatalf("%s failed: %v", method, err)
+		}
+		return result.(json.RawMessage) //nolint:forcetypeassert // Task methods return raw JSON
+	}
+
+	var created struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(call("create", createParams{Title: "A", NoHooks: true}), &created); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	call("claim", claimParams{ID: created.ID, NoHooks: true})
+	call("close", closeParams{ID: created.ID, Reason: "Done", NoHooks: true})
+	drainWebhooks()
+	if received.Load() != 0 {
+		t.Errorf("webhooks received %d post(s) for no_hooks calls, want none", received.Load())
+	}
+	if noHooks {
+		t.Error("a no_hooks call left --no-hooks set for later requests")
+	}
+
+	call("create", createParams{Title: "B"})
+	drainWebhooks()
+	if received.Load() != 1 {
+		t.Errorf("webhooks received %d post(s) for a create with hooks, want 1", received.Load())
+	}
+}
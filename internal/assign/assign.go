@@ -0,0 +1,113 @@
+package assign
+
+import (
+	"slices"
+
+	"github.com/abatilo/bits/internal/deps"
+	"github.com/abatilo/bits/internal/task"
+)
+
+// Assignment strategies.
+const (
+	StrategyRoundRobin = "round-robin"
+)
+
+// Strategies returns the supported strategy names.
+func Strategies() []string {
+	return []string{StrategyRoundRobin}
+}
+
+// RoundRobin queues unassigned open tasks on agents, returning the new
+// assignee of each task it assigns keyed by task ID.
+//
+// Ready tasks are dealt to the agents in turn, in ready order, beginning with
+// the agents that already have the fewest unfinished tasks so repeated runs
+// stay fair. A blocked task joins the queue of the one agent that holds every open
+// task blocking it, so no agent waits on another agent's queue; a task whose
+// blockers span agents, or include unassigned work, is left for a later run.
+func RoundRobin(graph *deps.Graph, tasks []*task.Task, agents []string) map[string]string {
+	assigned := make(map[string]string)
+	if len(agents) == 0 {
+		return assigned
+	}
+
+	owner := func(id string) string {
+		if agent, ok := assigned[id]; ok {
+			return agent
+		}
+		t := graph.Get(id)
+		if t == nil {
+			return ""
+		}
+		if t.Assignee != "" {
+			return t.Assignee
+		}
+		return t.Holder()
+	}
+
+	order := byLoad(tasks, agents)
+	next := 0
+	for _, t := range graph.Ready() {
+		if t.Assignee != "" {
+			continue
+		}
+		assigned[t.ID] = order[next%len(order)]
+		next++
+	}
+
+	// Follow dependency chains until no more blocked tasks can be placed
+	for changed := true; changed; {
+		changed = false
+		for _, t := range tasks {
+			if t.Status != task.StatusOpen || t.Assignee != "" || assigned[t.ID] != "" {
+				continue
+			}
+			blockers := graph.BlockedBy(t.ID)
+			if len(blockers) == 0 {
+				continue
+			}
+			agent := soleOwner(blockers, owner)
+			if !slices.Contains(agents, agent) {
+				continue
+			}
+			assigned[t.ID] = agent
+			changed = true
+		}
+	}
+	return assigned
+}
+
+// byLoad returns agents ordered by how many unfinished tasks they're assigned
+// or hold, fewest first, keeping the given order among equals.
+func byLoad(tasks []*task.Task, agents []string) []string {
+	load := make(map[string]int)
+	for _, t := range tasks {
+		if t.Status == task.StatusClosed {
+			continue
+		}
+		if t.Assignee != "" {
+			load[t.Assignee]++
+		} else if holder := t.Holder(); holder != "" {
+			load[holder]++
+		}
+	}
+	order := slices.Clone(agents)
+	slices.SortStableFunc(order, func(a, b string) int {
+		return load[a] - load[b]
+	})
+	return order
+}
+
+// soleOwner returns the agent owning every blocker, or "" if they don't
+// share one.
+func soleOwner(blockers []string, owner func(string) string) string {
+	agent := ""
+	for _, id := range blockers {
+		o := owner(id)
+		if o == "" || (agent != "" && o != agent) {
+			return ""
+		}
+		agent = o
+	}
+	return agent
+}
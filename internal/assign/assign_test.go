@@ -0,0 +1,48 @@
+//nolint:testpackage // Tests require internal access for thorough testing
+package assign
+
+import (
+	"testing"
+	"time"
+
+	"github.com/abatilo/bits/internal/deps"
+	"github.com/abatilo/bits/internal/task"
+)
+
+func TestRoundRobin(t *testing.T) {
+	created := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	newTask := func(id string, dependsOn ...string) *task.Task {
+		created = created.Add(time.Minute)
+		return &task.Task{
+			ID: id, Status: task.StatusOpen, Priority: task.PriorityMedium, CreatedAt: created, DependsOn: dependsOn,
+		}
+	}
+	api := newTask("api")
+	docs := newTask("docs")
+	tests := newTask("tests")
+	ui := newTask("ui", "api")              // Follows api's agent
+	release := newTask("rel", "ui", "docs") // Blocked across two queues
+	owned := newTask("own")
+	owned.Assignee = "b"
+	tasks := []*task.Task{api, docs, tests, ui, release, owned}
+
+	got := RoundRobin(deps.NewGraph(tasks), tasks, []string{"a", "b"})
+
+	// b already has a task, so a is dealt to first
+	want := map[string]string{"api": "a", "docs": "b", "tests": "a", "ui": "a"}
+	if len(got) != len(want) {
+		t.Errorf("RoundRobin = %v, want %v", got, want)
+	}
+	for id, agent := range want {
+		if got[id] != agent {
+			t.Errorf("%s assigned to %q, want %q", id, got[id], agent)
+		}
+	}
+	if _, ok := got["rel"]; ok {
+		t.Error("task blocked by two agents' queues was assigned")
+	}
+
+	if got = RoundRobin(deps.NewGraph(tasks), tasks, nil); len(got) != 0 {
+		t.Errorf("RoundRobin with no agents = %v, want none", got)
+	}
+}
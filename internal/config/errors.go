@@ -0,0 +1,52 @@
+package config
+
+import "fmt"
+
+// InvalidConfigError indicates the config file could not be parsed or holds invalid values.
+type InvalidConfigError struct {
+	Path   string
+	Reason string
+}
+
+func (e InvalidConfigError) Error() string {
+	return fmt.Sprintf("invalid config %s: %s", e.Path, e.Reason)
+}
+
+// InvalidModeError indicates a permission value is not an octal mode.
+type InvalidModeError struct {
+	Value string
+}
+
+func (e InvalidModeError) Error() string {
+	return fmt.Sprintf("invalid mode %q (expected octal such as 0700)", e.Value)
+}
+
+// InvalidDurationError indicates a timeout is not a positive Go duration.
+type InvalidDurationError struct {
+	Value string
+}
+
+func (e InvalidDurationError) Error() string {
+	return fmt.Sprintf("invalid duration %q (expected a positive duration such as 30s or 2m)", e.Value)
+}
+
+// LimitExceededError indicates task content over a configured limit.
+type LimitExceededError struct {
+	ID    string // Empty for a task not yet created
+	Field string // Config key under limits
+	Unit  string
+	Size  int
+	Limit int
+}
+
+func (e LimitExceededError) Error() string {
+	if e.ID == "" {
+		return e.Reason()
+	}
+	return fmt.Sprintf("task %s: %s", e.ID, e.Reason())
+}
+
+// Reason describes the violation without naming the task.
+func (e LimitExceededError) Reason() string {
+	return fmt.Sprintf("%s has %d %s, over the limit of %d (limits.%s)", e.Field, e.Size, e.Unit, e.Limit, e.Field)
+}
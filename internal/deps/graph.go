@@ -3,27 +3,56 @@ package deps
 import (
 	"slices"
 	"sort"
+	"time"
 
+	"github.com/abatilo/bits/internal/external"
 	"github.com/abatilo/bits/internal/storage"
 	"github.com/abatilo/bits/internal/task"
 )
 
 // Graph represents the dependency relationships between tasks.
 type Graph struct {
-	tasks map[string]*task.Task
+	tasks      map[string]*task.Task
+	dependents map[string][]string
+	external   map[string]bool // External dependencies known to be closed
+	precedence map[string]int  // Total weight of ordering hints asking for a task to go first
+	now        time.Time       // When tasks are judged overdue
 }
 
 // NewGraph creates a Graph from a list of tasks.
 func NewGraph(tasks []*task.Task) *Graph {
 	g := &Graph{
-		tasks: make(map[string]*task.Task),
+		tasks:      make(map[string]*task.Task),
+		dependents: make(map[string][]string),
+		precedence: make(map[string]int),
+		now:        time.Now(),
 	}
 	for _, t := range tasks {
 		g.tasks[t.ID] = t
+		for _, depID := range t.Dependencies() {
+			g.dependents[depID] = append(g.dependents[depID], t.ID)
+		}
+		if t.Status != task.StatusClosed {
+			for _, h := range t.After {
+				g.precedence[h.ID] += h.EffectiveWeight()
+			}
+		}
 	}
 	return g
 }
 
+// SetExternalClosed records which external dependencies (GitHub issue and
+// pull request URLs) are closed. Any other external dependency blocks.
+func (g *Graph) SetExternalClosed(closed map[string]bool) {
+	g.external = closed
+}
+
+// SetNow sets the time Ready judges tasks overdue at, which defaults to when
+// the graph was built.
+func (g *Graph) SetNow(now time.Time) {
+	g.now = now
+}
+
 // Get returns a task by ID.
 func (g *Graph) Get(id string) *task.Task {
 	return g.tasks[id]
@@ -34,7 +63,8 @@ func (g *Graph) IsBlocked(id string) bool {
 	return len(g.BlockedBy(id)) > 0
 }
 
-// BlockedBy returns the IDs of unclosed tasks that block this task.
+// BlockedBy returns the IDs of unclosed dependencies that block this task.
+// For an any-of group with no closed member, every member is a blocker.
 func (g *Graph) BlockedBy(id string) []string {
 	t := g.tasks[id]
 	if t == nil {
@@ -42,17 +72,28 @@ func (g *Graph) BlockedBy(id string) []string {
 	}
 	var blockers []string
 	for _, depID := range t.DependsOn {
-		dep := g.tasks[depID]
-		if dep == nil {
-			continue
-		}
-		if dep.Status != task.StatusClosed {
+		if g.isOpen(depID) {
 			blockers = append(blockers, depID)
 		}
 	}
+	for _, group := range t.DependsAny {
+		if !slices.ContainsFunc(group, func(depID string) bool { return !g.isOpen(depID) }) {
+			blockers = append(blockers, group...)
+		}
+	}
 	return blockers
 }
 
+// isOpen reports whether a dependency still blocks: an unclosed task, or an
+// external item not known to be closed. Unknown task IDs don't block.
+func (g *Graph) isOpen(depID string) bool {
+	if external.IsRef(depID) {
+		return !g.external[depID]
+	}
+	dep := g.tasks[depID]
+	return dep != nil && dep.Status != task.StatusClosed
+}
+
 // WouldCreateCycle checks if adding a dependency from -> to would create a cycle.
 // Uses BFS from 'to' to see if we can reach 'from'.
 func (g *Graph) WouldCreateCycle(from, to string) bool {
@@ -77,12 +118,13 @@ func (g *Graph) WouldCreateCycle(from, to string) bool {
 		if t == nil {
 			continue
 		}
-		queue = append(queue, t.DependsOn...)
+		queue = append(queue, t.Dependencies()...)
 	}
 	return false
 }
 
-// Ready returns all tasks that are open and have all dependencies closed.
+// Ready returns all tasks that are open and have all dependencies closed,
+// overdue ones first.
 func (g *Graph) Ready() []*task.Task {
 	var ready []*task.Task
 	for _, t := range g.tasks {
@@ -94,9 +136,13 @@ func (g *Graph) Ready() []*task.Task {
 		}
 	}
 
-	// Sort by priority then created_at
+	// Sort overdue tasks first, then by ordering hints, priority, and created_at
 	sort.Slice(ready, func(i, j int) bool {
-		return taskLess(ready[i], ready[j])
+		iOverdue, jOverdue := ready[i].IsOverdue(g.now), ready[j].IsOverdue(g.now)
+		if iOverdue != jOverdue {
+			return iOverdue
+		}
+		return g.hintedLess(ready[i], ready[j])
 	})
 
 	return ready
@@ -104,13 +150,7 @@ func (g *Graph) Ready() []*task.Task {
 
 // Dependents returns IDs of tasks that depend on the given task.
 func (g *Graph) Dependents(id string) []string {
-	var dependents []string
-	for _, t := range g.tasks {
-		if slices.Contains(t.DependsOn, id) {
-			dependents = append(dependents, t.ID)
-		}
-	}
-	return dependents
+	return slices.Clone(g.dependents[id])
 }
 
 // ValidateAddDep validates adding a dependency from -> to.
@@ -118,6 +158,9 @@ func (g *Graph) ValidateAddDep(from, to string) error {
 	if g.tasks[from] == nil {
 		return storage.TaskNotFoundError{ID: from}
 	}
+	if external.IsRef(to) {
+		return nil
+	}
 	if g.tasks[to] == nil {
 		return storage.TaskNotFoundError{ID: to}
 	}
@@ -127,6 +170,28 @@ func (g *Graph) ValidateAddDep(from, to string) error {
 	return nil
 }
 
+// ValidateAddHint validates adding an ordering hint that from should follow to.
+// Hints never block, so cycles are harmless, but both tasks must exist.
+func (g *Graph) ValidateAddHint(from, to string) error {
+	if g.tasks[from] == nil {
+		return storage.TaskNotFoundError{ID: from}
+	}
+	if g.tasks[to] == nil {
+		return storage.TaskNotFoundError{ID: to}
+	}
+	if from == to {
+		return CycleError{From: from, To: to}
+	}
+	return nil
+}
+
+// SortByPriority sorts tasks by priority, then by creation time.
+func SortByPriority(tasks []*task.Task) {
+	sort.SliceStable(tasks, func(i, j int) bool {
+		return taskLess(tasks[i], tasks[j])
+	})
+}
+
 // taskLess returns true if task a should be sorted before task b.
 // Sorts by priority first (critical < high < medium < low), then by creation time.
 func taskLess(a, b *task.Task) bool {
@@ -138,6 +203,21 @@ func taskLess(a, b *task.Task) bool {
 	return a.CreatedAt.Before(b.CreatedAt)
 }
 
+// hintedLess orders tasks by ordering hints before falling back to taskLess:
+// a task another task asked to follow comes first, then the task with the
+// greater total hint weight from unfinished tasks.
+func (g *Graph) hintedLess(a, b *task.Task) bool {
+	aFirst := slices.ContainsFunc(b.After, func(h task.Hint) bool { return h.ID == a.ID })
+	bFirst := slices.ContainsFunc(a.After, func(h task.Hint) bool { return h.ID == b.ID })
+	if aFirst != bFirst {
+		return aFirst
+	}
+	if g.precedence[a.ID] != g.precedence[b.ID] {
+		return g.precedence[a.ID] > g.precedence[b.ID]
+	}
+	return taskLess(a, b)
+}
+
 // dependsOn returns true if task 'from' depends on task 'to' (directly or transitively).
 func (g *Graph) dependsOn(from, to string) bool {
 	visited := make(map[string]bool)
@@ -157,7 +237,7 @@ func (g *Graph) dependsOn(from, to string) bool {
 			continue
 		}
 
-		for _, depID := range t.DependsOn {
+		for _, depID := range t.Dependencies() {
 			if depID == to {
 				return true
 			}
@@ -169,7 +249,7 @@ func (g *Graph) dependsOn(from, to string) bool {
 
 // SortByReadiness sorts tasks: unblocked first, then blocked.
 // Within blocked group, respects dependency order (blockers before blocked tasks),
-// then sorts by priority, then by created_at.
+// then ordering hints, then priority, then created_at.
 func (g *Graph) SortByReadiness(tasks []*task.Task) {
 	sort.Slice(tasks, func(i, j int) bool {
 		iBlocked := g.IsBlocked(tasks[i].ID)
@@ -187,6 +267,217 @@ func (g *Graph) SortByReadiness(tasks []*task.Task) {
 			}
 		}
 
-		return taskLess(tasks[i], tasks[j])
+		return g.hintedLess(tasks[i], tasks[j])
 	})
 }
+
+// CriticalPath returns the longest chain of unclosed tasks that must finish,
+// one after another, before goal can, ending with goal. With goal "" it is
+// the longest such chain among all unclosed tasks, the sequence that gates
+// finishing everything. An any-of group adds its shortest member's chain, or
+// nothing when a member is closed. Equal chains are broken by total
+// estimate, then by priority and age of the last task. The chain runs from
+// the task to start first to the goal.
+func (g *Graph) CriticalPath(goal string) []*task.Task {
+	chains := make(map[string][]*task.Task)
+	visiting := make(map[string]bool)
+	var chain func(id string) []*task.Task
+	chain = func(id string) []*task.Task {
+		if c, ok := chains[id]; ok {
+			return c
+		}
+		t := g.tasks[id]
+		if t == nil || t.Status == task.StatusClosed || visiting[id] {
+			return nil
+		}
+		visiting[id] = true
+		var longest []*task.Task
+		for _, depID := range t.DependsOn {
+			if c := chain(depID); chainLess(longest, c) {
+				longest = c
+			}
+		}
+		for _, group := range t.DependsAny {
+			if slices.ContainsFunc(group, func(depID string) bool { return !g.isOpen(depID) }) {
+				continue
+			}
+			var shortest []*task.Task
+			for i, depID := range group {
+				if c := chain(depID); i == 0 || chainLess(c, shortest) {
+					shortest = c
+				}
+			}
+			if chainLess(longest, shortest) {
+				longest = shortest
+			}
+		}
+		visiting[id] = false
+		chains[id] = append(slices.Clone(longest), t)
+		return chains[id]
+	}
+
+	if goal != "" {
+		return chain(goal)
+	}
+	var longest []*task.Task
+	for _, t := range g.tasks {
+		if c := chain(t.ID); chainLess(longest, c) {
+			longest = c
+		}
+	}
+	return longest
+}
+
+// chainLess reports whether chain a is shorter than b: fewer tasks, then less
+// total estimate, then a later last task by priority and age, so ties go to
+// the more urgent work.
+func chainLess(a, b []*task.Task) bool {
+	if len(a) != len(b) {
+		return len(a) < len(b)
+	}
+	if len(a) == 0 {
+		return false
+	}
+	if ea, eb := chainEstimate(a), chainEstimate(b); ea != eb {
+		return ea < eb
+	}
+	return taskLess(b[len(b)-1], a[len(a)-1])
+}
+
+// chainEstimate returns the total estimate of a chain's tasks.
+func chainEstimate(chain []*task.Task) time.Duration {
+	var total time.Duration
+	for _, t := range chain {
+		total += t.Estimate
+	}
+	return total
+}
+
+// Order returns every unclosed task in an order that puts each after the
+// tasks it depends on, for planning a whole batch of work rather than only
+// the ready frontier. Among the tasks free to go next, higher priority comes
+// first, then older. An any-of group is satisfied by whichever member comes
+// first; external dependencies don't hold a task back. Tasks caught in a
+// dependency cycle come last.
+func (g *Graph) Order() []*task.Task {
+	unfinished := func(id string) bool {
+		dep := g.tasks[id]
+		return dep != nil && dep.Status != task.StatusClosed
+	}
+	pending := make(map[string]int)            // Requirements each task still waits on
+	satisfied := make(map[string]map[int]bool) // Any-of groups met, by index
+	var next []*task.Task
+	for _, t := range g.tasks {
+		if t.Status == task.StatusClosed {
+			continue
+		}
+		satisfied[t.ID] = make(map[int]bool)
+		for _, depID := range t.DependsOn {
+			if unfinished(depID) {
+				pending[t.ID]++
+			}
+		}
+		for i, group := range t.DependsAny {
+			if slices.ContainsFunc(group, func(depID string) bool { return !unfinished(depID) }) {
+				satisfied[t.ID][i] = true
+			} else {
+				pending[t.ID]++
+			}
+		}
+		if pending[t.ID] == 0 {
+			next = append(next, t)
+		}
+	}
+	SortByPriority(next)
+
+	order := make([]*task.Task, 0, len(satisfied))
+	for len(next) > 0 {
+		t := next[0]
+		next = next[1:]
+		order = append(order, t)
+		for _, id := range g.dependents[t.ID] {
+			d := g.tasks[id]
+			if d == nil || d.Status == task.StatusClosed {
+				continue
+			}
+			waiting := pending[id]
+			if slices.Contains(d.DependsOn, t.ID) {
+				pending[id]--
+			}
+			for i, group := range d.DependsAny {
+				if !satisfied[id][i] && slices.Contains(group, t.ID) {
+					satisfied[id][i] = true
+					pending[id]--
+				}
+			}
+			if waiting > 0 && pending[id] == 0 {
+				i, _ := slices.BinarySearchFunc(next, d, compareTasks)
+				next = slices.Insert(next, i, d)
+			}
+		}
+	}
+
+	if len(order) < len(satisfied) {
+		var cyclic []*task.Task
+		for id := range satisfied {
+			if pending[id] > 0 {
+				cyclic = append(cyclic, g.tasks[id])
+			}
+		}
+		SortByPriority(cyclic)
+		order = append(order, cyclic...)
+	}
+	return order
+}
+
+// compareTasks orders tasks like taskLess, for sorted insertion.
+func compareTasks(a, b *task.Task) int {
+	switch {
+	case taskLess(a, b):
+		return -1
+	case taskLess(b, a):
+		return 1
+	default:
+		return 0
+	}
+}
+
+// Blocker is an unclosed dependency holding a task back. An external one, a
+// GitHub issue or pull request not known to be closed, has only an ID.
+type Blocker struct {
+	ID       string
+	Title    string
+	Status   task.Status
+	External bool
+}
+
+// BlockedTask is an open task and what blocks it.
+type BlockedTask struct {
+	Task     *task.Task
+	Blockers []Blocker
+}
+
+// Blocked returns every open task IsBlocked reports, most urgent first, with
+// the dependencies blocking it.
+func (g *Graph) Blocked() []BlockedTask {
+	var open []*task.Task
+	for _, t := range g.tasks {
+		if t.Status == task.StatusOpen && g.IsBlocked(t.ID) {
+			open = append(open, t)
+		}
+	}
+	SortByPriority(open)
+
+	blocked := make([]BlockedTask, len(open))
+	for i, t := range open {
+		blocked[i].Task = t
+		for _, id := range g.BlockedBy(t.ID) {
+			b := Blocker{ID: id, External: external.IsRef(id)}
+			if dep := g.tasks[id]; dep != nil {
+				b.Title, b.Status = dep.Title, dep.Status
+			}
+			blocked[i].Blockers = append(blocked[i].Blockers, b)
+		}
+	}
+	return blocked
+}
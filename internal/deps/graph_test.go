@@ -2,6 +2,7 @@
 package deps
 
 import (
+	"strings"
 	"testing"
 	"time"
 
@@ -63,6 +64,96 @@ func TestBlockedBy(t *testing.T) {
 	}
 }
 
+func TestAnyOfDependencies(t *testing.T) {
+	blocked := makeTask("a", task.StatusOpen)
+	blocked.DependsAny = [][]string{{"x", "y"}}
+	ready := makeTask("b", task.StatusOpen)
+	ready.DependsAny = [][]string{{"x", "z"}}
+
+	g := NewGraph([]*task.Task{
+		blocked,
+		ready,
+		makeTask("x", task.StatusOpen),
+		makeTask("y", task.StatusOpen),
+		makeTask("z", task.StatusClosed),
+	})
+
+	if blockers := g.BlockedBy("a"); len(blockers) != 2 {
+		t.Errorf("BlockedBy(a) = %v, want [x y]", blockers)
+	}
+	if g.IsBlocked("b") {
+		t.Error("b should be ready: z in its any-of group is closed")
+	}
+	if !g.WouldCreateCycle("x", "a") {
+		t.Error("any-of members should count as edges for cycle detection")
+	}
+	if deps := g.Dependents("x"); len(deps) != 2 {
+		t.Errorf("Dependents(x) = %v, want [a b]", deps)
+	}
+}
+
+func TestOrderingHints(t *testing.T) {
+	feature := makeTask("feature", task.StatusOpen)
+	feature.Priority = task.PriorityHigh
+	feature.After = []task.Hint{{ID: "refactor"}}
+	docs := makeTask("docs", task.StatusOpen)
+	docs.After = []task.Hint{{ID: "cleanup", Weight: 3}}
+	tasks := []*task.Task{
+		feature,
+		docs,
+		makeTask("refactor", task.StatusOpen),
+		makeTask("cleanup", task.StatusOpen),
+		makeTask("other", task.StatusOpen),
+	}
+	for i, tk := range tasks {
+		tk.CreatedAt = time.Date(2024, 1, 15, i, 0, 0, 0, time.UTC)
+	}
+
+	g := NewGraph(tasks)
+	if g.IsBlocked("feature") {
+		t.Error("ordering hints must not block")
+	}
+
+	var ids []string
+	for _, r := range g.Ready() {
+		ids = append(ids, r.ID)
+	}
+	// cleanup (weight 3) and refactor (weight 1) are pulled ahead; refactor
+	// precedes the high-priority feature that asked to follow it
+	want := []string{"cleanup", "refactor", "feature", "docs", "other"}
+	if strings.Join(ids, ",") != strings.Join(want, ",") {
+		t.Errorf("Ready order = %v, want %v", ids, want)
+	}
+
+	if err := g.ValidateAddHint("feature", "missing"); err == nil {
+		t.Error("ValidateAddHint accepted a missing task")
+	}
+}
+
+func TestExternalDependencies(t *testing.T) {
+	const (
+		issue = "https://github.com/acme/api/issues/12"
+		pull  = "https://github.com/acme/api/pull/34"
+	)
+	tasks := []*task.Task{
+		makeTask("a", task.StatusOpen, issue),
+		makeTask("b", task.StatusOpen, pull),
+	}
+
+	g := NewGraph(tasks)
+	if blockers := g.BlockedBy("a"); len(blockers) != 1 || blockers[0] != issue {
+		t.Errorf("BlockedBy(a) with unknown state = %v, want [%s]", blockers, issue)
+	}
+
+	g.SetExternalClosed(map[string]bool{pull: true})
+	if !g.IsBlocked("a") || g.IsBlocked("b") {
+		t.Errorf("IsBlocked = a:%v b:%v, want a blocked and b ready", g.IsBlocked("a"), g.IsBlocked("b"))
+	}
+	if err := g.ValidateAddDep("b", issue); err != nil {
+		t.Errorf("ValidateAddDep with external URL failed: %v", err)
+	}
+}
+
 func TestWouldCreateCycle(t *testing.T) {
 	// a -> b -> c (a depends on b, b depends on c)
 	tasks := []*task.Task{
@@ -118,6 +209,34 @@ func TestReady(t *testing.T) {
 	}
 }
 
+func TestReadyOverdueFirst(t *testing.T) {
+	now := time.Date(2025, 1, 15, 12, 0, 0, 0, time.UTC)
+	past, future := now.Add(-time.Hour), now.Add(time.Hour)
+	tasks := []*task.Task{
+		makeTask("critical", task.StatusOpen),
+		makeTask("late", task.StatusOpen),
+		makeTask("soon", task.StatusOpen),
+	}
+	tasks[0].Priority = task.PriorityCritical
+	tasks[1].Priority = task.PriorityLow
+	tasks[1].DueAt = &past
+	tasks[2].DueAt = &future
+
+	g := NewGraph(tasks)
+	g.SetNow(now)
+	ready := g.Ready()
+
+	var ids []string
+	for _, r := range ready {
+		ids = append(ids, r.ID)
+	}
+	// The overdue task leads despite its low priority
+	want := []string{"late", "critical", "soon"}
+	if strings.Join(ids, ",") != strings.Join(want, ",") {
+		t.Errorf("Ready order = %v, want %v", ids, want)
+	}
+}
+
 func TestDependents(t *testing.T) {
 	tasks := []*task.Task{
 		makeTask("a", task.StatusOpen),
@@ -276,3 +395,109 @@ func TestSortByReadinessBlockedChain(t *testing.T) {
 		}
 	}
 }
+
+func TestSortByPriority(t *testing.T) {
+	blocker := makeTask("blocker", task.StatusOpen)
+	blocker.Priority = task.PriorityLow
+	urgent := makeTask("urgent", task.StatusOpen, "blocker")
+	urgent.Priority = task.PriorityCritical
+	tasks := []*task.Task{blocker, urgent}
+
+	// Unlike SortByReadiness, dependencies don't affect the order
+	SortByPriority(tasks)
+	if tasks[0].ID != "urgent" || tasks[1].ID != "blocker" {
+		t.Errorf("SortByPriority = [%s %s], want [urgent blocker]", tasks[0].ID, tasks[1].ID)
+	}
+}
+
+func TestCriticalPath(t *testing.T) {
+	anyOf := makeTask("f", task.StatusOpen, "e")
+	anyOf.DependsAny = [][]string{{"b", "d"}} // d's chain is shorter
+	tasks := []*task.Task{
+		makeTask("a", task.StatusOpen),
+		makeTask("b", task.StatusActive, "a"),
+		makeTask("c", task.StatusClosed),
+		makeTask("d", task.StatusOpen, "c"),
+		makeTask("e", task.StatusOpen),
+		anyOf,
+		makeTask("g", task.StatusOpen, "b"),
+		makeTask("h", task.StatusOpen, "g", "f"),
+	}
+	g := NewGraph(tasks)
+
+	ids := func(chain []*task.Task) string {
+		var parts []string
+		for _, t := range chain {
+			parts = append(parts, t.ID)
+		}
+		return strings.Join(parts, " ")
+	}
+	if got := ids(g.CriticalPath("")); got != "a b g h" {
+		t.Errorf("CriticalPath() = %q, want %q", got, "a b g h")
+	}
+	if got := ids(g.CriticalPath("f")); got != "e f" && got != "d f" {
+		t.Errorf("CriticalPath(f) = %q, want a two-task chain ending in f", got)
+	}
+	if got := g.CriticalPath("c"); len(got) != 0 {
+		t.Errorf("CriticalPath(closed) = %q, want empty", ids(got))
+	}
+}
+
+func TestOrder(t *testing.T) {
+	base := time.Now()
+	mk := func(id string, p task.Priority, age int, deps ...string) *task.Task {
+		tk := makeTask(id, task.StatusOpen, deps...)
+		tk.Priority = p
+		tk.CreatedAt = base.Add(-time.Duration(age) * time.Hour)
+		return tk
+	}
+	anyOf := mk("f", task.PriorityCritical, 0)
+	anyOf.DependsAny = [][]string{{"d", "e"}}
+	tasks := []*task.Task{
+		mk("a", task.PriorityLow, 5),
+		mk("b", task.PriorityLow, 9),
+		mk("c", task.PriorityCritical, 1, "a"),
+		mk("d", task.PriorityMedium, 2),
+		mk("e", task.PriorityMedium, 3, "c"),
+		anyOf,
+		makeTask("g", task.StatusClosed),
+		mk("h", task.PriorityHigh, 1, "g", "https://github.com/o/r/issues/1"),
+	}
+	g := NewGraph(tasks)
+
+	var ids []string
+	for _, tk := range g.Order() {
+		ids = append(ids, tk.ID)
+	}
+	// h is free (closed and external deps); d frees f; a frees c, then c frees e
+	want := "h d f b a c e"
+	if got := strings.Join(ids, " "); got != want {
+		t.Errorf("Order() = %q, want %q", got, want)
+	}
+}
+
+func TestBlocked(t *testing.T) {
+	urgent := makeTask("d", task.StatusOpen, "b", "https://github.com/o/r/issues/1")
+	urgent.Priority = task.PriorityHigh
+	tasks := []*task.Task{
+		makeTask("a", task.StatusClosed),
+		makeTask("b", task.StatusActive),
+		makeTask("c", task.StatusOpen, "a", "b"),
+		urgent,
+		makeTask("e", task.StatusOpen, "a"), // Ready
+	}
+	g := NewGraph(tasks)
+
+	blocked := g.Blocked()
+	if len(blocked) != 2 || blocked[0].Task.ID != "d" || blocked[1].Task.ID != "c" {
+		t.Fatalf("Blocked() = %+v, want d then c", blocked)
+	}
+	want := []Blocker{{ID: "b", Title: "Task b", Status: task.StatusActive}, {ID: "https://github.com/o/r/issues/1",
+		External: true}}
+	if len(blocked[0].Blockers) != 2 || blocked[0].Blockers[0] != want[0] || blocked[0].Blockers[1] != want[1] {
+		t.Errorf("Blockers of d = %+v, want %+v", blocked[0].Blockers, want)
+	}
+	if len(blocked[1].Blockers) != 1 || blocked[1].Blockers[0].ID != "b" {
+		t.Errorf("Blockers of c = %+v, want only b", blocked[1].Blockers)
+	}
+}
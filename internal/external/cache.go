@@ -0,0 +1,83 @@
+package external
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const cacheFile = "external.json"
+
+// Cache records the last known state of external dependencies so readiness
+// can be evaluated without querying GitHub on every command.
+type Cache struct {
+	Entries map[string]Entry `json:"entries"`
+}
+
+// Entry is the cached state of one external dependency.
+type Entry struct {
+	Closed    bool      `json:"closed"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// LoadCache reads the cache from a store directory. A missing or unreadable
+// cache yields an empty one.
+func LoadCache(basePath string) *Cache {
+	c := &Cache{Entries: make(map[string]Entry)}
+	data, err := os.ReadFile(filepath.Join(basePath, cacheFile))
+	if err != nil || json.Unmarshal(data, c) != nil || c.Entries == nil {
+		return &Cache{Entries: make(map[string]Entry)}
+	}
+	return c
+}
+
+// Save writes the cache to a store directory, with the directory's
+// permissions minus execute bits.
+func (c *Cache) Save(basePath string) error {
+	info, err := os.Stat(basePath)
+	if err != nil {
+		return err
+	}
+	fileMode := info.Mode().Perm() &^ 0o111
+
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(basePath, cacheFile)
+	//nolint:gosec // G306: mode follows the store directory's permissions
+	if err = os.WriteFile(path, data, fileMode); err != nil {
+		return err
+	}
+	return os.Chmod(path, fileMode)
+}
+
+// Closed returns the set of dependencies known to be closed.
+func (c *Cache) Closed() map[string]bool {
+	closed := make(map[string]bool, len(c.Entries))
+	for dep, e := range c.Entries {
+		if e.Closed {
+			closed[dep] = true
+		}
+	}
+	return closed
+}
+
+// Refresh queries the state of each external dependency and records it.
+// Dependencies already known to be closed are not queried again.
+func (c *Cache) Refresh(ctx context.Context, checker *Checker, deps []string, now time.Time) error {
+	for _, dep := range deps {
+		ref, ok := ParseRef(dep)
+		if !ok || c.Entries[dep].Closed {
+			continue
+		}
+		closed, err := checker.Closed(ctx, ref)
+		if err != nil {
+			return err
+		}
+		c.Entries[dep] = Entry{Closed: closed, CheckedAt: now}
+	}
+	return nil
+}
@@ -0,0 +1,13 @@
+package external
+
+import "fmt"
+
+// StatusError indicates GitHub returned an unexpected response for a dependency.
+type StatusError struct {
+	Ref    string
+	Status string
+}
+
+func (e StatusError) Error() string {
+	return fmt.Sprintf("checking %s: %s", e.Ref, e.Status)
+}
@@ -0,0 +1,89 @@
+//nolint:testpackage // Tests require internal access for thorough testing
+package external
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestParseRef(t *testing.T) {
+	tests := []struct {
+		input string
+		want  Ref
+		ok    bool
+	}{
+		{"https://github.com/acme/api/issues/12", Ref{Owner: "acme", Repo: "api", Number: 12}, true},
+		{"https://github.com/acme/api/pull/34/", Ref{Owner: "acme", Repo: "api", Number: 34, Pull: true}, true},
+		{"https://github.com/acme/api/pull/34/files", Ref{}, false},
+		{"https://gitlab.com/acme/api/issues/12", Ref{}, false},
+		{"https://github.com/acme/api/issues/abc", Ref{}, false},
+		{"abc123", Ref{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := ParseRef(tt.input)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("ParseRef(%q) = %+v, %v; want %+v, %v", tt.input, got, ok, tt.want, tt.ok)
+		}
+	}
+
+	ref, _ := ParseRef("http://github.com/acme/api/pull/34/")
+	if got := ref.String(); got != "https://github.com/acme/api/pull/34" {
+		t.Errorf("String = %q", got)
+	}
+}
+
+func TestCacheRefresh(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			t.Errorf("Authorization = %q", r.Header.Get("Authorization"))
+		}
+		switch r.URL.Path {
+		case "/repos/acme/api/issues/12":
+			_, _ = w.Write([]byte(`{"state":"closed"}`))
+		case "/repos/acme/api/issues/34":
+			_, _ = w.Write([]byte(`{"state":"open"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	checker := &Checker{BaseURL: server.URL, Token: "secret", Client: server.Client()}
+	dir := t.TempDir()
+	now := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+	deps := []string{"https://github.com/acme/api/issues/12", "https://github.com/acme/api/pull/34", "abc123"}
+
+	cache := LoadCache(dir)
+	if err := cache.Refresh(context.Background(), checker, deps, now); err != nil {
+		t.Fatalf("Refresh failed: %v", err)
+	}
+	if err := cache.Save(dir); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	closed := LoadCache(dir).Closed()
+	if !closed[deps[0]] || closed[deps[1]] || len(closed) != 1 {
+		t.Errorf("Closed = %v, want only %s", closed, deps[0])
+	}
+
+	// Closed dependencies are not queried again
+	requests = 0
+	if err := LoadCache(dir).Refresh(context.Background(), checker, deps, now); err != nil {
+		t.Fatalf("second Refresh failed: %v", err)
+	}
+	if requests != 1 {
+		t.Errorf("second Refresh made %d requests, want 1", requests)
+	}
+
+	var statusErr StatusError
+	err := cache.Refresh(context.Background(), checker, []string{"https://github.com/acme/api/issues/99"}, now)
+	if !errors.As(err, &statusErr) {
+		t.Errorf("Refresh of missing issue = %v, want StatusError", err)
+	}
+}
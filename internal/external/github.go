@@ -0,0 +1,113 @@
+package external
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultAPIURL  = "https://api.github.com"
+	requestTimeout = 10 * time.Second
+)
+
+// Ref is a GitHub issue or pull request a task can depend on.
+type Ref struct {
+	Owner  string
+	Repo   string
+	Number int
+	Pull   bool
+}
+
+// ParseRef parses a GitHub issue or pull request URL such as
+// https://github.com/owner/repo/issues/12 or https://github.com/owner/repo/pull/34.
+func ParseRef(s string) (Ref, bool) {
+	u, err := url.Parse(s)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host != "github.com" {
+		return Ref{}, false
+	}
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(parts) != 4 || (parts[2] != "issues" && parts[2] != "pull") { //nolint:mnd // owner/repo/kind/number
+		return Ref{}, false
+	}
+	n, err := strconv.Atoi(parts[3])
+	if err != nil || n <= 0 {
+		return Ref{}, false
+	}
+	return Ref{Owner: parts[0], Repo: parts[1], Number: n, Pull: parts[2] == "pull"}, true
+}
+
+// IsRef reports whether a dependency entry refers to an external item rather
+// than a task ID.
+func IsRef(s string) bool {
+	_, ok := ParseRef(s)
+	return ok
+}
+
+// String returns the canonical URL of the issue or pull request.
+func (r Ref) String() string {
+	kind := "issues"
+	if r.Pull {
+		kind = "pull"
+	}
+	return fmt.Sprintf("https://github.com/%s/%s/%s/%d", r.Owner, r.Repo, kind, r.Number)
+}
+
+// Checker queries GitHub for the state of issues and pull requests.
+type Checker struct {
+	BaseURL string
+	Token   string
+	Client  *http.Client
+}
+
+// NewChecker creates a Checker for api.github.com, authenticating with
+// $GITHUB_TOKEN or $GH_TOKEN when set.
+func NewChecker() *Checker {
+	token := os.Getenv("GITHUB_TOKEN")
+	if token == "" {
+		token = os.Getenv("GH_TOKEN")
+	}
+	return &Checker{
+		BaseURL: defaultAPIURL,
+		Token:   token,
+		Client:  &http.Client{Timeout: requestTimeout},
+	}
+}
+
+// Closed reports whether the issue or pull request is closed. Merged pull
+// requests are closed.
+func (c *Checker) Closed(ctx context.Context, ref Ref) (bool, error) {
+	// The issues endpoint serves pull requests too and reports merged ones as closed
+	endpoint := fmt.Sprintf("%s/repos/%s/%s/issues/%d", c.BaseURL, ref.Owner, ref.Repo, ref.Number)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+
+	resp, err := c.Client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return false, StatusError{Ref: ref.String(), Status: resp.Status}
+	}
+
+	var body struct {
+		State string `json:"state"`
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return false, err
+	}
+	return body.State == "closed", nil
+}
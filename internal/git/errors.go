@@ -0,0 +1,24 @@
+package git
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CommandError indicates a git command failed.
+type CommandError struct {
+	Args   []string
+	Stderr string
+	Err    error
+}
+
+func (e CommandError) Error() string {
+	if e.Stderr != "" {
+		return fmt.Sprintf("git %s: %s", strings.Join(e.Args, " "), e.Stderr)
+	}
+	return fmt.Sprintf("git %s: %v", strings.Join(e.Args, " "), e.Err)
+}
+
+func (e CommandError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,131 @@
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"os/exec"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Commit message trailers bits recognizes.
+const (
+	TaskTrailer  = "Bits-Task"   // Links the commit to a task
+	CloseTrailer = "Closes-Bits" // Closes a task: "Closes-Bits: <id> [reason]"
+)
+
+// run executes git in dir and returns its trimmed stdout.
+func run(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...) //nolint:gosec // G204: arguments are built by this package
+	cmd.Dir = dir
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", CommandError{Args: args, Stderr: strings.TrimSpace(stderr.String()), Err: err}
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// AddTrailer appends a "key: value" trailer to the commit message in file,
+// unless the message already carries that exact trailer. Git handles comment
+// lines and existing trailer blocks.
+func AddTrailer(dir, file, key, value string) error {
+	_, err := run(dir, "interpret-trailers", "--in-place",
+		"--if-exists", "addIfDifferent", "--trailer", key+": "+value, file)
+	return err
+}
+
+// Trailers returns the values of every key trailer on the commit at rev.
+func Trailers(dir, rev, key string) ([]string, error) {
+	out, err := run(dir, "log", "-1", "--format=%(trailers:key="+key+",valueonly)", rev)
+	if err != nil {
+		return nil, err
+	}
+	var values []string
+	for line := range strings.SplitSeq(out, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			values = append(values, line)
+		}
+	}
+	return values, nil
+}
+
+// ResolveRev returns the full commit SHA for rev.
+func ResolveRev(dir, rev string) (string, error) {
+	return run(dir, "rev-parse", "--verify", rev+"^{commit}")
+}
+
+// HooksDir returns the directory git runs hooks from, honoring core.hooksPath.
+func HooksDir(dir string) (string, error) {
+	return run(dir, "rev-parse", "--path-format=absolute", "--git-path", "hooks")
+}
+
+// Files lists the files in the work tree at dir that git tracks or would
+// track, relative to dir with forward slashes: untracked files are included
+// unless ignored.
+func Files(dir string) ([]string, error) {
+	out, err := run(dir, "ls-files", "-z", "--cached", "--others", "--exclude-standard")
+	if err != nil || out == "" {
+		return nil, err
+	}
+	files := strings.Split(strings.TrimRight(out, "\x00"), "\x00")
+	slices.Sort(files)
+	return slices.Compact(files), nil // Unmerged files are listed once per stage
+}
+
+// MentionsTask reports whether a commit message refers to a task ID as a whole
+// word, in a trailer or anywhere in the text. Comment lines are ignored.
+func MentionsTask(message, id string) bool {
+	word := regexp.MustCompile(`(^|[^A-Za-z0-9])` + regexp.QuoteMeta(id) + `($|[^A-Za-z0-9])`)
+	for line := range strings.SplitSeq(message, "\n") {
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		if word.MatchString(line) {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckoutBranch checks out the named branch, creating it from HEAD if it
+// doesn't exist. It reports whether the branch was created.
+func CheckoutBranch(dir, name string) (bool, error) {
+	if _, err := run(dir, "rev-parse", "--verify", "--quiet", "refs/heads/"+name); err == nil {
+		_, err = run(dir, "checkout", "--quiet", name)
+		return false, err
+	}
+	_, err := run(dir, "checkout", "--quiet", "-b", name)
+	return err == nil, err
+}
+
+// PushedBranches parses the ref lines a pre-push hook reads on stdin
+// ("<local ref> <local sha> <remote ref> <remote sha>") and returns the local
+// branches being pushed. Tags and deletions are skipped.
+func PushedBranches(r io.Reader) ([]string, error) {
+	var branches []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || strings.Trim(fields[1], "0") == "" { // All zeros: ref deletion
+			continue
+		}
+		if branch, ok := strings.CutPrefix(fields[0], "refs/heads/"); ok {
+			branches = append(branches, branch)
+		}
+	}
+	return branches, scanner.Err()
+}
+
+// CommitTime returns the committer date of the commit at rev.
+func CommitTime(dir, rev string) (time.Time, error) {
+	out, err := run(dir, "log", "-1", "--format=%cI", rev)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Parse(time.RFC3339, out)
+}
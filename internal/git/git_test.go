@@ -0,0 +1,165 @@
+//nolint:testpackage // Tests require internal access for thorough testing
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// initRepo creates an empty repository with a committer identity.
+func initRepo(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"config", "user.email", "test@example.com"},
+		{"config", "user.name", "Test"},
+	} {
+		if _, err := run(dir, args...); err != nil {
+			t.Fatalf("git %v failed: %v", args, err)
+		}
+	}
+	return dir
+}
+
+func TestTrailerRoundTrip(t *testing.T) {
+	dir := initRepo(t)
+	msg := filepath.Join(dir, "MSG")
+	if err := os.WriteFile(msg, []byte("Fix login\n\n# comment\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+
+	// Adding the same trailer twice must not duplicate it
+	for range 2 {
+		if err := AddTrailer(dir, msg, TaskTrailer, "abc123"); err != nil {
+			t.Fatalf("AddTrailer failed: %v", err)
+		}
+	}
+
+	if _, err := run(dir, "commit", "-q", "--allow-empty", "--cleanup=strip", "-F", msg); err != nil {
+		t.Fatalf("commit failed: %v", err)
+	}
+	values, err := Trailers(dir, "HEAD", TaskTrailer)
+	if err != nil {
+		t.Fatalf("Trailers failed: %v", err)
+	}
+	if !slices.Equal(values, []string{"abc123"}) {
+		t.Errorf("Trailers = %v, want [abc123]", values)
+	}
+
+	sha, err := ResolveRev(dir, "HEAD")
+	if err != nil {
+		t.Fatalf("ResolveRev failed: %v", err)
+	}
+	if len(sha) != 40 {
+		t.Errorf("ResolveRev = %q, want a full SHA", sha)
+	}
+}
+
+func TestHooksDir(t *testing.T) {
+	dir := initRepo(t)
+	hooks, err := HooksDir(dir)
+	if err != nil {
+		t.Fatalf("HooksDir failed: %v", err)
+	}
+	resolved, _ := filepath.EvalSymlinks(dir)
+	if hooks != filepath.Join(resolved, ".git", "hooks") && hooks != filepath.Join(dir, ".git", "hooks") {
+		t.Errorf("HooksDir = %q, want .git/hooks in %s", hooks, dir)
+	}
+}
+
+func TestMentionsTask(t *testing.T) {
+	tests := []struct {
+		message string
+		want    bool
+	}{
+		{"Fix login\n\nBits-Task: abc123\n", true},
+		{"Fix login for abc123", true},
+		{"(abc123) Fix login", true},
+		{"Fix login\n# Bits-Task: abc123\n", false},
+		{"Fix login abc1234", false},
+		{"Fix login", false},
+	}
+
+	for _, tt := range tests {
+		if got := MentionsTask(tt.message, "abc123"); got != tt.want {
+			t.Errorf("MentionsTask(%q) = %v, want %v", tt.message, got, tt.want)
+		}
+	}
+}
+
+func TestCheckoutBranch(t *testing.T) {
+	dir := initRepo(t)
+	if _, err := run(dir, "commit", "-q", "--allow-empty", "-m", "root"); err != nil {
+		t.Fatalf("commit failed: %v", err)
+	}
+	base, err := run(dir, "branch", "--show-current")
+	if err != nil {
+		t.Fatalf("branch failed: %v", err)
+	}
+
+	created, err := CheckoutBranch(dir, "bits/abc123-fix")
+	if err != nil || !created {
+		t.Fatalf("CheckoutBranch = %v, %v; want created", created, err)
+	}
+	if _, err = run(dir, "checkout", "-q", base); err != nil {
+		t.Fatalf("checkout failed: %v", err)
+	}
+	created, err = CheckoutBranch(dir, "bits/abc123-fix")
+	if err != nil || created {
+		t.Fatalf("CheckoutBranch on existing branch = %v, %v; want switched", created, err)
+	}
+	if current, _ := run(dir, "branch", "--show-current"); current != "bits/abc123-fix" {
+		t.Errorf("current branch = %q, want bits/abc123-fix", current)
+	}
+}
+
+func TestPushedBranches(t *testing.T) {
+	input := strings.Join([]string{
+		"refs/heads/main 1111111111111111111111111111111111111111 refs/heads/main 2222222222222222222222222222222222222222",
+		"refs/tags/v1 3333333333333333333333333333333333333333 refs/tags/v1 0000000000000000000000000000000000000000",
+		"(delete) 0000000000000000000000000000000000000000 refs/heads/old 4444444444444444444444444444444444444444",
+		"refs/heads/bits/abc-fix 5555555555555555555555555555555555555555 refs/heads/bits/abc-fix 0000000000000000000000000000000000000000",
+	}, "\n")
+
+	branches, err := PushedBranches(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("PushedBranches failed: %v", err)
+	}
+	if !slices.Equal(branches, []string{"main", "bits/abc-fix"}) {
+		t.Errorf("PushedBranches = %v, want [main bits/abc-fix]", branches)
+	}
+}
+
+func TestFiles(t *testing.T) {
+	dir := initRepo(t)
+	for name, content := range map[string]string{
+		"main.go":        "package main\n",
+		"sub/util.go":    "package sub\n",
+		"build/out.bin":  "ignored\n",
+		".gitignore":     "build/\n",
+		"docs/notes.txt": "untracked\n",
+	} {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("MkdirAll failed: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("WriteFile failed: %v", err)
+		}
+	}
+	if _, err := run(dir, "add", "main.go", "sub/util.go", ".gitignore"); err != nil {
+		t.Fatalf("git add failed: %v", err)
+	}
+
+	files, err := Files(dir)
+	if err != nil {
+		t.Fatalf("Files failed: %v", err)
+	}
+	if want := []string{".gitignore", "docs/notes.txt", "main.go", "sub/util.go"}; !slices.Equal(files, want) {
+		t.Errorf("Files = %v, want %v", files, want)
+	}
+}
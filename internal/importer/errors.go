@@ -0,0 +1,68 @@
+package importer
+
+import "fmt"
+
+// InvalidMappingError indicates a --map entry that can't be used.
+type InvalidMappingError struct {
+	Spec   string
+	Reason string
+}
+
+func (e InvalidMappingError) Error() string {
+	return fmt.Sprintf("invalid mapping %q: %s (expected field=key, e.g. title=name)", e.Spec, e.Reason)
+}
+
+// FileError indicates a source file that couldn't be imported.
+type FileError struct {
+	Path string
+	Err  error
+}
+
+func (e FileError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Path, e.Err)
+}
+
+func (e FileError) Unwrap() error {
+	return e.Err
+}
+
+// InvalidValueError indicates a mapped value of the wrong shape.
+type InvalidValueError struct {
+	Field Field
+	Key   string
+	Value any
+}
+
+func (e InvalidValueError) Error() string {
+	return fmt.Sprintf("%s (from %s): can't use %v", e.Field, e.Key, e.Value)
+}
+
+// ItemError indicates a bad entry in a YAML file holding a list of tasks.
+type ItemError struct {
+	Item int
+	Err  error
+}
+
+func (e ItemError) Error() string {
+	return fmt.Sprintf("item %d: %v", e.Item, e.Err)
+}
+
+func (e ItemError) Unwrap() error {
+	return e.Err
+}
+
+// NotATaskError indicates YAML that isn't a task mapping.
+type NotATaskError struct {
+	Got string
+}
+
+func (e NotATaskError) Error() string {
+	return "expected a task mapping, got " + e.Got
+}
+
+// MissingTitleError indicates a YAML task with no mapped title.
+type MissingTitleError struct{}
+
+func (e MissingTitleError) Error() string {
+	return "no title (map one with --map title=<key>)"
+}
@@ -0,0 +1,267 @@
+package importer
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/abatilo/bits/internal/task"
+)
+
+// Record is a task read from a source file.
+type Record struct {
+	Path        string // Relative to the imported directory
+	Title       string
+	Description string
+	Status      task.Status // Open or closed; nothing is imported as claimed
+	Priority    task.Priority
+	Tags        []string
+	Due         *time.Time
+	Created     *time.Time
+}
+
+// ReadDir reads every markdown (.md, .markdown) and YAML (.yaml, .yml) file
+// under root, skipping hidden files and directories such as .obsidian, and
+// returns the records sorted by path. Markdown frontmatter supplies the
+// fields, the body the description, and the first heading (or file name) a
+// missing title. A YAML file holds one task or a list of them.
+func ReadDir(root string, m Mapping) ([]Record, error) {
+	var records []Record
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+		if path != root && strings.HasPrefix(d.Name(), ".") {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		var read []Record
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".md", ".markdown":
+			read, err = readMarkdown(path, rel, m)
+		case ".yaml", ".yml":
+			read, err = readYAML(path, rel, m)
+		default:
+			return nil
+		}
+		if err != nil {
+			return FileError{Path: rel, Err: err}
+		}
+		records = append(records, read...)
+		return nil
+	})
+	return records, err
+}
+
+// readMarkdown reads a note with optional YAML frontmatter.
+func readMarkdown(path, rel string, m Mapping) ([]Record, error) {
+	content, err := os.ReadFile(path) //nolint:gosec // G304: reading the directory the user asked to import
+	if err != nil {
+		return nil, err
+	}
+
+	values := map[string]any{}
+	body := string(content)
+	if rest, ok := strings.CutPrefix(body, "---\n"); ok {
+		if front, after, closed := strings.Cut(rest, "\n---"); closed {
+			if err = yaml.Unmarshal([]byte(front), &values); err != nil {
+				return nil, err
+			}
+			_, body, _ = strings.Cut(after, "\n")
+		}
+	}
+
+	r, err := newRecord(rel, values, m)
+	if err != nil {
+		return nil, err
+	}
+	body = strings.TrimSpace(body)
+	if r.Title == "" {
+		if heading, rest, ok := cutHeading(body); ok {
+			r.Title, body = heading, rest
+		} else {
+			r.Title = strings.TrimSuffix(filepath.Base(rel), filepath.Ext(rel))
+		}
+	}
+	if _, _, mapped := m.lookup(values, FieldDescription); !mapped && len(m[FieldDescription]) > 0 {
+		r.Description = body
+	}
+	return []Record{r}, nil
+}
+
+// cutHeading splits a leading "# Heading" line off body.
+func cutHeading(body string) (string, string, bool) {
+	line, rest, _ := strings.Cut(body, "\n")
+	heading, ok := strings.CutPrefix(line, "# ")
+	if !ok || strings.TrimSpace(heading) == "" {
+		return "", body, false
+	}
+	return strings.TrimSpace(heading), strings.TrimSpace(rest), true
+}
+
+// readYAML reads a file holding one task mapping or a list of them.
+func readYAML(path, rel string, m Mapping) ([]Record, error) {
+	content, err := os.ReadFile(path) //nolint:gosec // G304: reading the directory the user asked to import
+	if err != nil {
+		return nil, err
+	}
+	var doc any
+	if err = yaml.Unmarshal(content, &doc); err != nil {
+		return nil, err
+	}
+
+	var items []any
+	switch doc := doc.(type) {
+	case map[string]any:
+		items = []any{doc}
+	case []any:
+		items = doc
+	case nil:
+		return nil, nil
+	default:
+		return nil, NotATaskError{Got: fmt.Sprintf("%T", doc)}
+	}
+
+	records := make([]Record, 0, len(items))
+	for i, item := range items {
+		values, ok := item.(map[string]any)
+		if !ok {
+			return nil, ItemError{Item: i + 1, Err: NotATaskError{Got: fmt.Sprintf("%T", item)}}
+		}
+		r, recordErr := newRecord(rel, values, m)
+		if recordErr != nil {
+			return nil, ItemError{Item: i + 1, Err: recordErr}
+		}
+		if r.Title == "" {
+			return nil, ItemError{Item: i + 1, Err: MissingTitleError{}}
+		}
+		records = append(records, r)
+	}
+	return records, nil
+}
+
+// newRecord converts mapped values into a record.
+func newRecord(rel string, values map[string]any, m Mapping) (Record, error) {
+	r := Record{Path: rel, Status: task.StatusOpen, Priority: task.PriorityMedium}
+	var ok bool
+	if _, v, found := m.lookup(values, FieldTitle); found {
+		r.Title = strings.TrimSpace(fmt.Sprint(v))
+	}
+	if _, v, found := m.lookup(values, FieldDescription); found {
+		r.Description = strings.TrimSpace(fmt.Sprint(v))
+	}
+	if _, v, found := m.lookup(values, FieldStatus); found {
+		r.Status = status(v)
+	}
+	if _, v, found := m.lookup(values, FieldPriority); found {
+		r.Priority = priority(v)
+	}
+	if key, v, found := m.lookup(values, FieldTags); found {
+		if r.Tags, ok = tags(v); !ok {
+			return r, InvalidValueError{Field: FieldTags, Key: key, Value: v}
+		}
+	}
+	if key, v, found := m.lookup(values, FieldDue); found {
+		if r.Due, ok = timestamp(v); !ok {
+			return r, InvalidValueError{Field: FieldDue, Key: key, Value: v}
+		}
+	}
+	if key, v, found := m.lookup(values, FieldCreated); found {
+		if r.Created, ok = timestamp(v); !ok {
+			return r, InvalidValueError{Field: FieldCreated, Key: key, Value: v}
+		}
+	}
+	return r, nil
+}
+
+// status maps a source value to open or closed. Booleans (done: true) and
+// the usual words for finished work close the task; anything else is open.
+func status(v any) task.Status {
+	if done, ok := v.(bool); ok {
+		if done {
+			return task.StatusClosed
+		}
+		return task.StatusOpen
+	}
+	switch strings.ToLower(strings.TrimSpace(fmt.Sprint(v))) {
+	case "closed", "done", "complete", "completed", "finished", "resolved", "cancelled", "canceled", "x":
+		return task.StatusClosed
+	default:
+		return task.StatusOpen
+	}
+}
+
+// priority maps a source value to a priority, accepting bits' names, common
+// synonyms, and P0-P3 or 1-4 scales. Unrecognized values are medium.
+func priority(v any) task.Priority {
+	switch strings.ToLower(strings.TrimSpace(fmt.Sprint(v))) {
+	case "critical", "urgent", "highest", "blocker", "p0", "1":
+		return task.PriorityCritical
+	case "high", "important", "p1", "2":
+		return task.PriorityHigh
+	case "low", "lowest", "minor", "someday", "p3", "4":
+		return task.PriorityLow
+	default:
+		return task.PriorityMedium
+	}
+}
+
+// tags reads a list of tags or a comma- or space-separated string of them,
+// dropping any leading # as Obsidian writes them.
+func tags(v any) ([]string, bool) {
+	var raw []string
+	switch v := v.(type) {
+	case string:
+		raw = strings.FieldsFunc(v, func(r rune) bool { return r == ',' || r == ' ' })
+	case []any:
+		for _, item := range v {
+			raw = append(raw, fmt.Sprint(item))
+		}
+	default:
+		return nil, false
+	}
+
+	var out []string
+	for _, r := range raw {
+		if tag, ok := task.NormalizeTag(r); ok {
+			out = append(out, tag)
+		}
+	}
+	return out, true
+}
+
+// timestamp reads a YAML timestamp, a date string, or Unix seconds.
+func timestamp(v any) (*time.Time, bool) {
+	switch v := v.(type) {
+	case time.Time:
+		utc := v.UTC()
+		return &utc, true
+	case string:
+		for _, layout := range []string{time.RFC3339, "2006-01-02T15:04", "2006-01-02 15:04", time.DateOnly} {
+			if t, err := time.Parse(layout, strings.TrimSpace(v)); err == nil {
+				return &t, true
+			}
+		}
+		return nil, false
+	case int:
+		t := time.Unix(int64(v), 0).UTC()
+		return &t, true
+	default:
+		return nil, false
+	}
+}
@@ -0,0 +1,95 @@
+//nolint:testpackage // Tests require internal access for thorough testing
+package importer
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/abatilo/bits/internal/task"
+)
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("MkdirAll failed: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("WriteFile failed: %v", err)
+		}
+	}
+	return root
+}
+
+func TestReadDir(t *testing.T) {
+	root := writeFiles(t, map[string]string{
+		"report.md": "---\ntags: [work, \"#Urgent\"]\ndone: true\ndue: 2026-11-01\n---\n" +
+			"# Write the report\n\nThe Q3 numbers.\n",
+		"notes/plain.md":    "Just text\n",
+		"errands.yaml":      "- name: Buy milk\n  priority: P0\n- name: Call Bob\n  labels: home, errands\n",
+		".obsidian/app.md":  "# Settings\n",
+		"notes/picture.png": "binary",
+	})
+
+	records, err := ReadDir(root, DefaultMapping())
+	if err != nil {
+		t.Fatalf("ReadDir failed: %v", err)
+	}
+	var titles []string
+	for _, r := range records {
+		titles = append(titles, r.Title)
+	}
+	if !slices.Equal(titles, []string{"Buy milk", "Call Bob", "plain", "Write the report"}) {
+		t.Fatalf("titles = %v", titles)
+	}
+
+	report := records[3]
+	if report.Status != task.StatusClosed || report.Description != "The Q3 numbers." {
+		t.Errorf("report = %+v, want closed with the body as description", report)
+	}
+	if !slices.Equal(report.Tags, []string{"work", "urgent"}) || report.Due == nil || report.Due.Day() != 1 {
+		t.Errorf("report tags/due = %v/%v", report.Tags, report.Due)
+	}
+	if records[0].Priority != task.PriorityCritical || !slices.Equal(records[1].Tags, []string{"home", "errands"}) {
+		t.Errorf("errands = %+v, %+v", records[0], records[1])
+	}
+	if records[2].Description != "Just text" || records[2].Status != task.StatusOpen {
+		t.Errorf("plain = %+v, want open with its text", records[2])
+	}
+}
+
+func TestReadDirMapping(t *testing.T) {
+	root := writeFiles(t, map[string]string{
+		"todo.yaml": "task: Ship it\nwhen: 2026-12-24\ndescription: ignored\n",
+	})
+	m, err := ParseMapping([]string{"title=task", "due=when", "description=-"})
+	if err != nil {
+		t.Fatalf("ParseMapping failed: %v", err)
+	}
+	records, err := ReadDir(root, m)
+	if err != nil {
+		t.Fatalf("ReadDir failed: %v", err)
+	}
+	if len(records) != 1 || records[0].Title != "Ship it" || records[0].Due == nil || records[0].Description != "" {
+		t.Errorf("records = %+v", records)
+	}
+
+	if _, err = ReadDir(root, DefaultMapping()); !errors.As(err, new(MissingTitleError)) {
+		t.Errorf("ReadDir without a title mapping = %v, want MissingTitleError", err)
+	}
+	for _, spec := range []string{"title", "owner=who", "due="} {
+		if _, err = ParseMapping([]string{spec}); !errors.As(err, new(InvalidMappingError)) {
+			t.Errorf("ParseMapping(%q) = %v, want InvalidMappingError", spec, err)
+		}
+	}
+	bad := writeFiles(t, map[string]string{"bad.md": "---\ndue: someday\n---\n"})
+	var fileErr FileError
+	if _, err = ReadDir(bad, DefaultMapping()); !errors.As(err, &fileErr) || fileErr.Path != "bad.md" {
+		t.Errorf("ReadDir with a bad date = %v, want FileError for bad.md", err)
+	}
+}
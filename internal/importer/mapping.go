@@ -0,0 +1,73 @@
+package importer
+
+import (
+	"slices"
+	"strings"
+)
+
+// Field is a task field an import can fill.
+type Field string
+
+const (
+	FieldTitle       Field = "title"
+	FieldDescription Field = "description"
+	FieldStatus      Field = "status"
+	FieldPriority    Field = "priority"
+	FieldTags        Field = "tags"
+	FieldDue         Field = "due"
+	FieldCreated     Field = "created"
+)
+
+// Fields returns every field a mapping can set, in display order.
+func Fields() []Field {
+	return []Field{FieldTitle, FieldDescription, FieldStatus, FieldPriority, FieldTags, FieldDue, FieldCreated}
+}
+
+// Mapping names the source keys each task field is read from. The first key
+// a file has wins.
+type Mapping map[Field][]string
+
+// DefaultMapping returns the keys common task and note tools use.
+func DefaultMapping() Mapping {
+	return Mapping{
+		FieldTitle:       {"title", "name", "summary"},
+		FieldDescription: {"description", "body", "notes"},
+		FieldStatus:      {"status", "state", "done", "completed"},
+		FieldPriority:    {"priority"},
+		FieldTags:        {"tags", "labels"},
+		FieldDue:         {"due", "due_at", "due_date", "deadline", "scheduled"},
+		FieldCreated:     {"created", "created_at", "date"},
+	}
+}
+
+// ParseMapping overrides the default mapping with field=key entries. Each
+// entry replaces the field's candidate keys; a key of "-" stops the field
+// being imported.
+func ParseMapping(specs []string) (Mapping, error) {
+	m := DefaultMapping()
+	for _, spec := range specs {
+		field, key, ok := strings.Cut(spec, "=")
+		field, key = strings.TrimSpace(field), strings.TrimSpace(key)
+		switch {
+		case !ok || key == "":
+			return nil, InvalidMappingError{Spec: spec, Reason: "missing key"}
+		case !slices.Contains(Fields(), Field(field)):
+			return nil, InvalidMappingError{Spec: spec, Reason: "unknown field " + field}
+		case key == "-":
+			m[Field(field)] = nil
+		default:
+			m[Field(field)] = []string{key}
+		}
+	}
+	return m, nil
+}
+
+// lookup returns the first mapped key present in values.
+func (m Mapping) lookup(values map[string]any, field Field) (string, any, bool) {
+	for _, key := range m[field] {
+		if v, ok := values[key]; ok && v != nil {
+			return key, v, true
+		}
+	}
+	return "", nil, false
+}
@@ -0,0 +1,14 @@
+package jira
+
+// StatusError indicates Jira returned an unexpected response to a search.
+type StatusError struct {
+	Status  string
+	Message string // Jira's first error message, if it sent one
+}
+
+func (e StatusError) Error() string {
+	if e.Message == "" {
+		return "jira search: " + e.Status
+	}
+	return "jira search: " + e.Status + ": " + e.Message
+}
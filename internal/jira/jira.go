@@ -0,0 +1,222 @@
+package jira
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/abatilo/bits/internal/task"
+)
+
+const (
+	requestTimeout = 30 * time.Second
+	pageSize       = 100
+
+	// searchFields are the issue fields a search reads.
+	searchFields = "summary,description,priority,status,labels,issuelinks,parent"
+)
+
+// Issue is a Jira issue as returned by the v2 search API, whose descriptions
+// are plain text.
+type Issue struct {
+	Key    string `json:"key"` // Such as ENG-123
+	Fields struct {
+		Summary     string   `json:"summary"`
+		Description string   `json:"description"`
+		Labels      []string `json:"labels"`
+		Priority    *struct {
+			Name string `json:"name"`
+		} `json:"priority"`
+		Status struct {
+			StatusCategory struct {
+				Key string `json:"key"` // new, indeterminate, or done
+			} `json:"statusCategory"`
+		} `json:"status"`
+		Parent *struct {
+			Key string `json:"key"`
+		} `json:"parent"`
+		IssueLinks []Link `json:"issuelinks"`
+	} `json:"fields"`
+}
+
+// Link is one of an issue's links to another. Exactly one of InwardIssue and
+// OutwardIssue is set: the issue <outward> OutwardIssue, or the issue
+// <inward> InwardIssue, as in "blocks" and "is blocked by".
+type Link struct {
+	Type struct {
+		Name    string `json:"name"`
+		Inward  string `json:"inward"`
+		Outward string `json:"outward"`
+	} `json:"type"`
+	InwardIssue *struct {
+		Key string `json:"key"`
+	} `json:"inwardIssue"`
+	OutwardIssue *struct {
+		Key string `json:"key"`
+	} `json:"outwardIssue"`
+}
+
+// Edge is a link between two issues as bits records it: From depends on,
+// duplicates, or relates to To.
+type Edge struct {
+	From string
+	To   string
+	Type task.LinkType
+}
+
+// TaskPriority returns the bits priority of the issue, reading both Jira's
+// default scheme (Highest to Lowest) and the older one (Blocker to Trivial).
+// Issues without a priority are medium.
+func (i Issue) TaskPriority() task.Priority {
+	if i.Fields.Priority == nil {
+		return task.PriorityMedium
+	}
+	switch strings.ToLower(i.Fields.Priority.Name) {
+	case "highest", "blocker":
+		return task.PriorityCritical
+	case "high", "critical", "major":
+		return task.PriorityHigh
+	case "low", "lowest", "minor", "trivial":
+		return task.PriorityLow
+	default:
+		return task.PriorityMedium
+	}
+}
+
+// Done reports whether the issue's status is in the done category.
+func (i Issue) Done() bool {
+	return i.Fields.Status.StatusCategory.Key == "done"
+}
+
+// ParentKey returns the key of the issue's parent, such as its epic, or "".
+func (i Issue) ParentKey() string {
+	if i.Fields.Parent == nil {
+		return ""
+	}
+	return i.Fields.Parent.Key
+}
+
+// Edges returns the links between issues, once each even though Jira lists a
+// link on both of its issues. Blocking links become dependencies, duplicate
+// links duplicates, and any other kind relates-to.
+func Edges(issues []Issue) []Edge {
+	var edges []Edge
+	seen := make(map[Edge]bool)
+	for _, issue := range issues {
+		for _, l := range issue.Fields.IssueLinks {
+			var e Edge
+			switch {
+			case l.OutwardIssue != nil:
+				e = Edge{From: issue.Key, To: l.OutwardIssue.Key} // The issue <outward> the other
+			case l.InwardIssue != nil:
+				e = Edge{From: l.InwardIssue.Key, To: issue.Key} // The other <outward> the issue
+			default:
+				continue
+			}
+			switch {
+			case strings.EqualFold(l.Type.Name, "Blocks"):
+				e = Edge{From: e.To, To: e.From, Type: task.LinkDependsOn} // Blocked depends on blocker
+			case strings.EqualFold(l.Type.Name, "Duplicate"):
+				e.Type = task.LinkDuplicates
+			default:
+				e.Type = task.LinkRelatesTo
+				if e.From > e.To { // Goes both ways, so keep one
+					e.From, e.To = e.To, e.From
+				}
+			}
+			if !seen[e] {
+				seen[e] = true
+				edges = append(edges, e)
+			}
+		}
+	}
+	return edges
+}
+
+// Client searches a Jira site.
+type Client struct {
+	BaseURL string // Such as https://acme.atlassian.net
+	Email   string // With Token, for Jira Cloud's basic auth; without, Token is a bearer token
+	Token   string
+	Client  *http.Client
+}
+
+// NewClient creates a Client for the Jira site at baseURL.
+func NewClient(baseURL, email, token string) *Client {
+	return &Client{
+		BaseURL: strings.TrimRight(baseURL, "/"),
+		Email:   email,
+		Token:   token,
+		Client:  &http.Client{Timeout: requestTimeout},
+	}
+}
+
+// BrowseURL returns the web address of the issue with key.
+func (c *Client) BrowseURL(key string) string {
+	return c.BaseURL + "/browse/" + key
+}
+
+// Search returns every issue matching jql, in the order Jira returns them.
+func (c *Client) Search(ctx context.Context, jql string) ([]Issue, error) {
+	var issues []Issue
+	token := ""
+	for {
+		query := url.Values{
+			"jql":        {jql},
+			"fields":     {searchFields},
+			"maxResults": {strconv.Itoa(pageSize)},
+		}
+		if token != "" {
+			query.Set("nextPageToken", token)
+		}
+		var page struct {
+			Issues        []Issue `json:"issues"`
+			NextPageToken string  `json:"nextPageToken"`
+			IsLast        bool    `json:"isLast"`
+		}
+		if err := c.get(ctx, "/rest/api/2/search/jql?"+query.Encode(), &page); err != nil {
+			return nil, err
+		}
+		issues = append(issues, page.Issues...)
+		if page.IsLast || page.NextPageToken == "" {
+			return issues, nil
+		}
+		token = page.NextPageToken
+	}
+}
+
+// get fetches path from the site, decoding the JSON response into out.
+func (c *Client) get(ctx context.Context, path string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.BaseURL+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	switch {
+	case c.Email != "" && c.Token != "":
+		req.SetBasicAuth(c.Email, c.Token)
+	case c.Token != "":
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+
+	resp, err := c.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		var body struct {
+			ErrorMessages []string `json:"errorMessages"`
+		}
+		statusErr := StatusError{Status: resp.Status}
+		if json.NewDecoder(resp.Body).Decode(&body) == nil && len(body.ErrorMessages) > 0 {
+			statusErr.Message = body.ErrorMessages[0]
+		}
+		return statusErr
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
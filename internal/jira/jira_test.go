@@ -0,0 +1,96 @@
+//nolint:testpackage // Tests require internal access for thorough testing
+package jira
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/abatilo/bits/internal/task"
+)
+
+func TestSearch(t *testing.T) {
+	var pages int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, ok := r.BasicAuth(); !ok || user != "me@acme.com" || pass != "secret" {
+			t.Errorf("basic auth = %q, %q, %v", user, pass, ok)
+		}
+		if r.URL.Path != "/rest/api/2/search/jql" || r.URL.Query().Get("jql") != "project = ENG" {
+			t.Errorf("request = %s", r.URL)
+		}
+		pages++
+		if r.URL.Query().Get("nextPageToken") == "" {
+			_, _ = w.Write([]byte(`{"issues":[{"key":"ENG-1","fields":{"summary":"First",
+				"priority":{"name":"Highest"},"status":{"statusCategory":{"key":"done"}}}}],"nextPageToken":"p2"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"issues":[{"key":"ENG-2","fields":{"summary":"Second","parent":{"key":"ENG-1"}}}],
+			"isLast":true}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL+"/", "me@acme.com", "secret")
+	issues, err := client.Search(context.Background(), "project = ENG")
+	if err != nil {
+		t.Fatalf("Search failed: %v", err)
+	}
+	if pages != 2 || len(issues) != 2 {
+		t.Fatalf("Search = %+v after %d page(s)", issues, pages)
+	}
+	if !issues[0].Done() || issues[0].TaskPriority() != task.PriorityCritical {
+		t.Errorf("ENG-1 done %v, priority %s", issues[0].Done(), issues[0].TaskPriority())
+	}
+	if issues[1].Done() || issues[1].TaskPriority() != task.PriorityMedium || issues[1].ParentKey() != "ENG-1" {
+		t.Errorf("ENG-2 done %v, priority %s, parent %q",
+			issues[1].Done(), issues[1].TaskPriority(), issues[1].ParentKey())
+	}
+	if got := client.BrowseURL("ENG-2"); got != server.URL+"/browse/ENG-2" {
+		t.Errorf("BrowseURL = %q", got)
+	}
+}
+
+func TestSearchError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"errorMessages":["Error in the JQL Query"]}`))
+	}))
+	defer server.Close()
+
+	var statusErr StatusError
+	_, err := NewClient(server.URL, "", "").Search(context.Background(), "project = ")
+	if !errors.As(err, &statusErr) || statusErr.Message != "Error in the JQL Query" {
+		t.Errorf("Search error = %v, want a StatusError with Jira's message", err)
+	}
+}
+
+func TestEdges(t *testing.T) {
+	var issues []Issue
+	if err := json.Unmarshal([]byte(`[
+		{"key":"ENG-1","fields":{"issuelinks":[
+			{"type":{"name":"Blocks"},"outwardIssue":{"key":"ENG-2"}},
+			{"type":{"name":"Relates"},"inwardIssue":{"key":"ENG-3"}}
+		]}},
+		{"key":"ENG-2","fields":{"issuelinks":[
+			{"type":{"name":"Blocks"},"inwardIssue":{"key":"ENG-1"}},
+			{"type":{"name":"Duplicate"},"outwardIssue":{"key":"ENG-3"}}
+		]}},
+		{"key":"ENG-3","fields":{"issuelinks":[
+			{"type":{"name":"Relates"},"outwardIssue":{"key":"ENG-1"}}
+		]}}
+	]`), &issues); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Edge{
+		{From: "ENG-2", To: "ENG-1", Type: task.LinkDependsOn},
+		{From: "ENG-1", To: "ENG-3", Type: task.LinkRelatesTo},
+		{From: "ENG-2", To: "ENG-3", Type: task.LinkDuplicates},
+	}
+	if got := Edges(issues); !slices.Equal(got, want) {
+		t.Errorf("Edges = %+v, want %+v", got, want)
+	}
+}
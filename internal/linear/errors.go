@@ -0,0 +1,33 @@
+package linear
+
+import "fmt"
+
+// StatusError indicates the Linear API returned an unexpected HTTP response.
+type StatusError struct {
+	Status string
+}
+
+func (e StatusError) Error() string {
+	return "linear API: " + e.Status
+}
+
+// QueryError indicates the Linear API rejected a query, such as for a bad
+// API key or an issue that doesn't exist.
+type QueryError struct {
+	Message string
+}
+
+func (e QueryError) Error() string {
+	return "linear API: " + e.Message
+}
+
+// NoStateError indicates an issue's team has no workflow state of the kind a
+// task's status maps to.
+type NoStateError struct {
+	Issue string
+	Type  string
+}
+
+func (e NoStateError) Error() string {
+	return fmt.Sprintf("issue %s: team has no %s workflow state", e.Issue, e.Type)
+}
@@ -0,0 +1,247 @@
+package linear
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/abatilo/bits/internal/task"
+)
+
+const (
+	defaultAPIURL  = "https://api.linear.app/graphql"
+	requestTimeout = 30 * time.Second
+	pageSize       = 100
+)
+
+// Workflow state types, which every team's states are one of.
+const (
+	StateTriage    = "triage"
+	StateBacklog   = "backlog"
+	StateUnstarted = "unstarted"
+	StateStarted   = "started"
+	StateCompleted = "completed"
+	StateCanceled  = "canceled"
+)
+
+// Issue is a Linear issue, with its team's workflow states so its status can
+// be changed.
+type Issue struct {
+	ID          string    `json:"id"`
+	Identifier  string    `json:"identifier"` // Such as ENG-123
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	Priority    int       `json:"priority"` // 0 none, 1 urgent, 2 high, 3 normal, 4 low
+	URL         string    `json:"url"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+	State       State     `json:"state"`
+	Team        struct {
+		States struct {
+			Nodes []State `json:"nodes"`
+		} `json:"states"`
+	} `json:"team"`
+}
+
+// State is a workflow state of a team.
+type State struct {
+	ID       string  `json:"id"`
+	Name     string  `json:"name"`
+	Type     string  `json:"type"`
+	Position float64 `json:"position"`
+}
+
+// Status returns the bits status an issue in the state has: started issues
+// are active, completed and canceled ones closed, and the rest open.
+func (s State) Status() task.Status {
+	switch s.Type {
+	case StateStarted:
+		return task.StatusActive
+	case StateCompleted, StateCanceled:
+		return task.StatusClosed
+	default:
+		return task.StatusOpen
+	}
+}
+
+// TaskPriority returns the bits priority of the issue. Issues without a
+// priority are medium.
+func (i Issue) TaskPriority() task.Priority {
+	switch i.Priority {
+	case 1:
+		return task.PriorityCritical
+	case 2: //nolint:mnd // Linear's priority scale
+		return task.PriorityHigh
+	case 4: //nolint:mnd // Linear's priority scale
+		return task.PriorityLow
+	default:
+		return task.PriorityMedium
+	}
+}
+
+// StateFor returns the first of the team's workflow states, by position,
+// that gives the issue status: unstarted (or backlog) for open, started for
+// active, and completed for closed.
+func (i Issue) StateFor(status task.Status) (State, error) {
+	types := map[task.Status][]string{
+		task.StatusOpen:   {StateUnstarted, StateBacklog},
+		task.StatusActive: {StateStarted},
+		task.StatusClosed: {StateCompleted},
+	}[status]
+	states := slices.Clone(i.Team.States.Nodes)
+	slices.SortStableFunc(states, func(a, b State) int { return cmp.Compare(a.Position, b.Position) })
+	for _, typ := range types {
+		if idx := slices.IndexFunc(states, func(s State) bool { return s.Type == typ }); idx >= 0 {
+			return states[idx], nil
+		}
+	}
+	return State{}, NoStateError{Issue: i.Identifier, Type: types[0]}
+}
+
+// Client calls Linear's GraphQL API.
+type Client struct {
+	BaseURL string
+	Token   string // A personal API key
+	Client  *http.Client
+}
+
+// NewClient creates a Client for api.linear.app authenticating with token.
+func NewClient(token string) *Client {
+	return &Client{BaseURL: defaultAPIURL, Token: token, Client: &http.Client{Timeout: requestTimeout}}
+}
+
+// issueFields are the fields read for every issue.
+const issueFields = `id identifier title description priority url updatedAt
+state { id name type position }
+team { states { nodes { id name type position } } }`
+
+// AssignedIssues returns the unfinished issues assigned to the API key's user.
+func (c *Client) AssignedIssues(ctx context.Context) ([]Issue, error) {
+	query := `query($after: String) {
+  viewer {
+    assignedIssues(first: ` + strconv.Itoa(pageSize) + `, after: $after,
+      filter: { state: { type: { nin: ["completed", "canceled"] } } }) {
+      nodes { ` + issueFields + ` }
+      pageInfo { hasNextPage endCursor }
+    }
+  }
+}`
+	var issues []Issue
+	var after *string
+	for {
+		var data struct {
+			Viewer struct {
+				AssignedIssues issuePage `json:"assignedIssues"`
+			} `json:"viewer"`
+		}
+		if err := c.do(ctx, query, map[string]any{"after": after}, &data); err != nil {
+			return nil, err
+		}
+		page := data.Viewer.AssignedIssues
+		issues = append(issues, page.Nodes...)
+		if !page.PageInfo.HasNextPage {
+			return issues, nil
+		}
+		after = &page.PageInfo.EndCursor
+	}
+}
+
+// Issues returns the issues with the given IDs, whatever their state or
+// assignee. IDs that don't match an issue are left out.
+func (c *Client) Issues(ctx context.Context, ids []string) ([]Issue, error) {
+	query := `query($ids: [ID!], $after: String) {
+  issues(first: ` + strconv.Itoa(pageSize) + `, after: $after, filter: { id: { in: $ids } }) {
+    nodes { ` + issueFields + ` }
+    pageInfo { hasNextPage endCursor }
+  }
+}`
+	var issues []Issue
+	var after *string
+	for len(ids) > 0 {
+		var data struct {
+			Issues issuePage `json:"issues"`
+		}
+		if err := c.do(ctx, query, map[string]any{"ids": ids, "after": after}, &data); err != nil {
+			return nil, err
+		}
+		issues = append(issues, data.Issues.Nodes...)
+		if !data.Issues.PageInfo.HasNextPage {
+			break
+		}
+		after = &data.Issues.PageInfo.EndCursor
+	}
+	return issues, nil
+}
+
+// SetState moves the issue to the workflow state.
+func (c *Client) SetState(ctx context.Context, issueID, stateID string) error {
+	query := `mutation($id: String!, $stateId: String!) {
+  issueUpdate(id: $id, input: { stateId: $stateId }) { success }
+}`
+	var data struct {
+		IssueUpdate struct {
+			Success bool `json:"success"`
+		} `json:"issueUpdate"`
+	}
+	if err := c.do(ctx, query, map[string]any{"id": issueID, "stateId": stateID}, &data); err != nil {
+		return err
+	}
+	if !data.IssueUpdate.Success {
+		return QueryError{Message: "issueUpdate did not succeed for " + issueID}
+	}
+	return nil
+}
+
+// issuePage is one page of an issue connection.
+type issuePage struct {
+	Nodes    []Issue `json:"nodes"`
+	PageInfo struct {
+		HasNextPage bool   `json:"hasNextPage"`
+		EndCursor   string `json:"endCursor"`
+	} `json:"pageInfo"`
+}
+
+// do runs a GraphQL query, decoding its data into out.
+func (c *Client) do(ctx context.Context, query string, variables map[string]any, out any) error {
+	body, err := json.Marshal(map[string]any{"query": query, "variables": variables})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.BaseURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", c.Token)
+
+	resp, err := c.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	// GraphQL errors come with a 400 or 200; anything else without a body to read
+	if err = json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		if resp.StatusCode != http.StatusOK {
+			return StatusError{Status: resp.Status}
+		}
+		return err
+	}
+	if len(result.Errors) > 0 {
+		return QueryError{Message: result.Errors[0].Message}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return StatusError{Status: resp.Status}
+	}
+	return json.Unmarshal(result.Data, out)
+}
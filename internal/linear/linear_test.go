@@ -0,0 +1,101 @@
+//nolint:testpackage // Tests require internal access for thorough testing
+package linear
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/abatilo/bits/internal/task"
+)
+
+func TestAssignedIssues(t *testing.T) {
+	var pages int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "lin_api_secret" {
+			t.Errorf("Authorization = %q", r.Header.Get("Authorization"))
+		}
+		var req struct {
+			Query     string         `json:"query"`
+			Variables map[string]any `json:"variables"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("decoding request: %v", err)
+		}
+		pages++
+		if req.Variables["after"] == nil {
+			_, _ = w.Write([]byte(`{"data":{"viewer":{"assignedIssues":{
+				"nodes":[{"id":"u1","identifier":"ENG-1","title":"First","priority":1,"state":{"type":"started"}}],
+				"pageInfo":{"hasNextPage":true,"endCursor":"c1"}}}}}`))
+			return
+		}
+		if req.Variables["after"] != "c1" {
+			t.Errorf("after = %v, want c1", req.Variables["after"])
+		}
+		_, _ = w.Write([]byte(`{"data":{"viewer":{"assignedIssues":{
+			"nodes":[{"id":"u2","identifier":"ENG-2","title":"Second","state":{"type":"backlog"}}],
+			"pageInfo":{"hasNextPage":false}}}}}`))
+	}))
+	defer server.Close()
+
+	client := &Client{BaseURL: server.URL, Token: "lin_api_secret", Client: server.Client()}
+	issues, err := client.AssignedIssues(context.Background())
+	if err != nil {
+		t.Fatalf("AssignedIssues failed: %v", err)
+	}
+	if pages != 2 || len(issues) != 2 || issues[0].Identifier != "ENG-1" || issues[1].Identifier != "ENG-2" {
+		t.Fatalf("AssignedIssues = %+v after %d page(s)", issues, pages)
+	}
+	if issues[0].TaskPriority() != task.PriorityCritical || issues[1].TaskPriority() != task.PriorityMedium {
+		t.Errorf("priorities = %s, %s", issues[0].TaskPriority(), issues[1].TaskPriority())
+	}
+	if issues[0].State.Status() != task.StatusActive || issues[1].State.Status() != task.StatusOpen {
+		t.Errorf("statuses = %s, %s", issues[0].State.Status(), issues[1].State.Status())
+	}
+}
+
+func TestQueryError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"errors":[{"message":"Authentication required"}]}`))
+	}))
+	defer server.Close()
+
+	client := &Client{BaseURL: server.URL, Client: server.Client()}
+	var queryErr QueryError
+	err := client.SetState(context.Background(), "u1", "s1")
+	if !errors.As(err, &queryErr) || !strings.Contains(queryErr.Message, "Authentication") {
+		t.Errorf("SetState error = %v, want a QueryError", err)
+	}
+}
+
+func TestStateFor(t *testing.T) {
+	var issue Issue
+	issue.Identifier = "ENG-1"
+	issue.Team.States.Nodes = []State{
+		{ID: "done", Type: StateCompleted, Position: 3},
+		{ID: "todo", Type: StateUnstarted, Position: 2},
+		{ID: "backlog", Type: StateBacklog, Position: 1},
+		{ID: "review", Type: StateStarted, Position: 5},
+		{ID: "doing", Type: StateStarted, Position: 4},
+	}
+	for status, want := range map[task.Status]string{
+		task.StatusOpen:   "todo",
+		task.StatusActive: "doing",
+		task.StatusClosed: "done",
+	} {
+		if got, err := issue.StateFor(status); err != nil || got.ID != want {
+			t.Errorf("StateFor(%s) = %s, %v; want %s", status, got.ID, err, want)
+		}
+	}
+
+	issue.Team.States.Nodes = issue.Team.States.Nodes[:3]
+	var noState NoStateError
+	if _, err := issue.StateFor(task.StatusActive); !errors.As(err, &noState) {
+		t.Errorf("StateFor without a started state = %v, want a NoStateError", err)
+	}
+}
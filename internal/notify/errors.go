@@ -0,0 +1,28 @@
+package notify
+
+import "fmt"
+
+// CommandError indicates the notification command failed.
+type CommandError struct {
+	Command string
+	Err     error
+}
+
+func (e CommandError) Error() string {
+	return fmt.Sprintf("notify command %q failed: %v", e.Command, e.Err)
+}
+
+func (e CommandError) Unwrap() error {
+	return e.Err
+}
+
+// WebhookError indicates a webhook could not be delivered.
+type WebhookError struct {
+	URL      string
+	Attempts int
+	Reason   string
+}
+
+func (e WebhookError) Error() string {
+	return fmt.Sprintf("webhook %s failed after %d attempt(s): %s", e.URL, e.Attempts, e.Reason)
+}
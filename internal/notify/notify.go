@@ -0,0 +1,37 @@
+package notify
+
+import (
+	"context"
+	"os"
+
+	"github.com/abatilo/bits/internal/script"
+)
+
+// Notification is a message about a task.
+type Notification struct {
+	Kind    string // What triggered it, e.g. "reminder"
+	TaskID  string
+	Title   string
+	Message string
+}
+
+// Send runs command through the shell with the notification in BITS_NOTIFY_KIND,
+// BITS_TASK_ID, BITS_TASK_TITLE, and BITS_MESSAGE. An empty command does nothing.
+func Send(command string, n Notification) error {
+	if command == "" {
+		return nil
+	}
+	cmd := script.Command(context.Background(), command)
+	cmd.Env = append(os.Environ(),
+		"BITS_NOTIFY_KIND="+n.Kind,
+		"BITS_TASK_ID="+n.TaskID,
+		"BITS_TASK_TITLE="+n.Title,
+		"BITS_MESSAGE="+n.Message,
+	)
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return CommandError{Command: command, Err: err}
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+//nolint:testpackage // Tests require internal access for thorough testing
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSend(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "out")
+	t.Setenv("OUT", out)
+
+	n := Notification{Kind: "reminder", TaskID: "abc", Title: "Fix login", Message: "check CI"}
+	command := `printf '%s|%s|%s|%s' "$BITS_NOTIFY_KIND" "$BITS_TASK_ID" "$BITS_TASK_TITLE" "$BITS_MESSAGE" > "$OUT"`
+	if err := Send(command, n); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	got, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	if string(got) != "reminder|abc|Fix login|check CI" {
+		t.Errorf("command saw %q", got)
+	}
+
+	if err = Send("", n); err != nil {
+		t.Errorf("Send with no command = %v, want nil", err)
+	}
+	var cmdErr CommandError
+	if err = Send("exit 3", n); !errors.As(err, &cmdErr) {
+		t.Errorf("Send error = %v, want CommandError", err)
+	}
+}
+
+func TestWebhookPost(t *testing.T) {
+	var attempts int
+	var got map[string]string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if attempts < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("Content-Type = %q", r.Header.Get("Content-Type"))
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("decoding body: %v", err)
+		}
+	}))
+	defer srv.Close()
+
+	hook := NewWebhook(srv.URL, 2, time.Second)
+	hook.Backoff = time.Millisecond
+	if err := hook.Post(context.Background(), map[string]string{"type": "close"}); err != nil {
+		t.Fatalf("Post failed: %v", err)
+	}
+	if attempts != 3 || got["type"] != "close" {
+		t.Errorf("after %d attempt(s) the server got %v", attempts, got)
+	}
+
+	attempts = 0
+	hook.Retries = 1
+	var hookErr WebhookError
+	if err := hook.Post(context.Background(), nil); !errors.As(err, &hookErr) || hookErr.Attempts != 2 {
+		t.Errorf("Post with too few retries = %v, want a WebhookError after 2 attempts", err)
+	}
+}
+
+func TestWebhookPostClientError(t *testing.T) {
+	var attempts int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	if err := NewWebhook(srv.URL, 2, time.Second).Post(context.Background(), nil); err == nil || attempts != 1 {
+		t.Errorf("Post to a 404 = %v after %d attempt(s), want an error without retries", err, attempts)
+	}
+}
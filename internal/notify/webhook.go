@@ -0,0 +1,77 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// defaultBackoff is the wait before a webhook's first retry, doubled for each
+// one after.
+const defaultBackoff = time.Second
+
+// Webhook posts JSON payloads to a URL, retrying failed attempts.
+type Webhook struct {
+	URL     string
+	Client  *http.Client
+	Retries int           // Further attempts after a failed one
+	Backoff time.Duration // Wait before the first retry (default 1s)
+}
+
+// NewWebhook creates a Webhook for url whose attempts each time out after timeout.
+func NewWebhook(url string, retries int, timeout time.Duration) Webhook {
+	return Webhook{URL: url, Client: &http.Client{Timeout: timeout}, Retries: retries}
+}
+
+// Post sends payload as a JSON POST body. Network errors, 429s, and 5xx
+// responses are retried; any other response outside 2xx fails at once.
+func (w Webhook) Post(ctx context.Context, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	backoff := w.Backoff
+	if backoff == 0 {
+		backoff = defaultBackoff
+	}
+
+	for attempt := 0; ; attempt++ {
+		reason, retry := w.attempt(ctx, body)
+		if reason == "" {
+			return nil
+		}
+		if !retry || attempt >= w.Retries {
+			return WebhookError{URL: w.URL, Attempts: attempt + 1, Reason: reason}
+		}
+		select {
+		case <-ctx.Done():
+			return WebhookError{URL: w.URL, Attempts: attempt + 1, Reason: ctx.Err().Error()}
+		case <-time.After(backoff << attempt):
+		}
+	}
+}
+
+// attempt posts body once, returning why it failed, if it did, and whether
+// trying again might help.
+func (w Webhook) attempt(ctx context.Context, body []byte) (string, bool) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return err.Error(), false
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := w.Client.Do(req)
+	if err != nil {
+		return err.Error(), true
+	}
+	_ = resp.Body.Close()
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return "", false
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return resp.Status, true
+	default:
+		return resp.Status, false
+	}
+}
@@ -0,0 +1,144 @@
+package output
+
+import (
+	"bytes"
+	"encoding/csv"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/abatilo/bits/internal/report"
+	"github.com/abatilo/bits/internal/task"
+)
+
+// taskCSVColumns returns the columns FormatTasksCSV writes. Lists are
+// space-separated.
+func taskCSVColumns() []string {
+	return []string{
+		"id", "title", "status", "priority", "created_at", "claimed_at", "closed_at", "close_reason",
+		"due_at", "depends_on", "parent", "tags", "assignee", "description",
+	}
+}
+
+// FormatWorklogCSV formats work log entries as CSV with a header row.
+// Durations are in whole seconds.
+func FormatWorklogCSV(entries []report.WorkEntry) string {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	_ = w.Write([]string{"task_id", "title", "actor", "start", "end", "duration_seconds"})
+	for _, e := range entries {
+		_ = w.Write([]string{
+			e.TaskID,
+			e.Title,
+			e.Actor,
+			e.Start.Format(time.RFC3339),
+			e.End.Format(time.RFC3339),
+			strconv.FormatInt(int64(e.End.Sub(e.Start)/time.Second), 10),
+		})
+	}
+	w.Flush()
+	return buf.String()
+}
+
+// FormatTasksCSV formats tasks as CSV with a header row. It carries the fields
+// a spreadsheet or another tracker needs; JSON keeps everything else.
+func FormatTasksCSV(tasks []*task.Task) string {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	_ = w.Write(taskCSVColumns())
+	optional := func(at *time.Time) string {
+		if s := formatOptionalTime(at); s != nil {
+			return *s
+		}
+		return ""
+	}
+	for _, t := range tasks {
+		reason := ""
+		if t.CloseReason != nil {
+			reason = *t.CloseReason
+		}
+		_ = w.Write([]string{
+			t.ID,
+			t.Title,
+			string(t.Status),
+			string(t.Priority),
+			t.CreatedAt.Format(time.RFC3339),
+			optional(t.ClaimedAt),
+			optional(t.ClosedAt),
+			reason,
+			optional(t.DueAt),
+			strings.Join(t.DependsOn, " "),
+			t.Parent,
+			strings.Join(t.Tags, " "),
+			t.Assignee,
+			t.Description,
+		})
+	}
+	w.Flush()
+	return buf.String()
+}
+
+// ParseTasksCSV decodes tasks from CSV with a header row naming the columns
+// FormatTasksCSV writes, in any order. Only title is required, so files made
+// by other tools load too; unknown columns are ignored and missing ones left
+// zero.
+func ParseTasksCSV(r io.Reader) ([]*task.Task, error) {
+	rows, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+	cols := make(map[string]int, len(rows[0]))
+	for i, name := range rows[0] {
+		cols[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	if _, ok := cols["title"]; !ok {
+		return nil, InvalidCSVError{Reason: "no title column"}
+	}
+
+	tasks := make([]*task.Task, 0, len(rows)-1)
+	for n, row := range rows[1:] {
+		field := func(name string) string {
+			if i, ok := cols[name]; ok && i < len(row) {
+				return row[i]
+			}
+			return ""
+		}
+		t := &task.Task{
+			ID:          field("id"),
+			Title:       field("title"),
+			Status:      task.Status(field("status")),
+			Priority:    task.Priority(field("priority")),
+			DependsOn:   strings.Fields(field("depends_on")),
+			Parent:      field("parent"),
+			Tags:        strings.Fields(field("tags")),
+			Assignee:    field("assignee"),
+			Description: field("description"),
+		}
+		if reason := field("close_reason"); reason != "" {
+			t.CloseReason = &reason
+		}
+		if created := field("created_at"); created != "" {
+			if t.CreatedAt, err = time.Parse(time.RFC3339, created); err != nil {
+				return nil, InvalidCSVError{Row: n + 2, Reason: err.Error()}
+			}
+		}
+		for _, at := range []struct {
+			dst  **time.Time
+			name string
+		}{
+			{&t.ClaimedAt, "claimed_at"}, {&t.ClosedAt, "closed_at"}, {&t.DueAt, "due_at"},
+		} {
+			if value := field(at.name); value != "" {
+				if *at.dst, err = parseOptionalTime(&value); err != nil {
+					return nil, InvalidCSVError{Row: n + 2, Reason: err.Error()}
+				}
+			}
+		}
+		tasks = append(tasks, t)
+	}
+	return tasks, nil
+}
@@ -0,0 +1,27 @@
+package output
+
+import "fmt"
+
+// InvalidQueryError indicates a --query path that can't be parsed.
+type InvalidQueryError struct {
+	Query  string
+	Reason string
+}
+
+func (e InvalidQueryError) Error() string {
+	return fmt.Sprintf("invalid query %q: %s (expected a path like .[0].title or .[].id)", e.Query, e.Reason)
+}
+
+// InvalidCSVError indicates a CSV task file that can't be read. Row is 0 for
+// a problem with the header.
+type InvalidCSVError struct {
+	Row    int
+	Reason string
+}
+
+func (e InvalidCSVError) Error() string {
+	if e.Row == 0 {
+		return "invalid CSV header: " + e.Reason
+	}
+	return fmt.Sprintf("invalid CSV row %d: %s", e.Row, e.Reason)
+}
@@ -0,0 +1,129 @@
+package output
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/abatilo/bits/internal/task"
+)
+
+// graphEdge is a link between tasks: From depends on To, as one of an any-of
+// group when AnyOf is set, or has a non-blocking link of Type to it.
+type graphEdge struct {
+	From  string
+	To    string
+	Type  task.LinkType
+	AnyOf bool
+}
+
+// label returns how the edge is marked beside the task it leads to, empty
+// for a plain dependency.
+func (e graphEdge) label() string {
+	switch {
+	case e.AnyOf:
+		return "any of"
+	case e.Type != task.LinkDependsOn:
+		return string(e.Type)
+	default:
+		return ""
+	}
+}
+
+// graphEdges returns the dependencies and then the typed links of tasks, in
+// task order.
+func graphEdges(tasks []*task.Task) []graphEdge {
+	var edges []graphEdge
+	for _, t := range tasks {
+		edges = append(edges, taskEdges(t)...)
+	}
+	return edges
+}
+
+// taskEdges returns the dependencies and then the typed links of t.
+func taskEdges(t *task.Task) []graphEdge {
+	var edges []graphEdge
+	for _, dep := range t.DependsOn {
+		edges = append(edges, graphEdge{From: t.ID, To: dep, Type: task.LinkDependsOn})
+	}
+	for _, group := range t.DependsAny {
+		for _, dep := range group {
+			edges = append(edges, graphEdge{From: t.ID, To: dep, Type: task.LinkDependsOn, AnyOf: true})
+		}
+	}
+	for _, l := range t.Links {
+		edges = append(edges, graphEdge{From: t.ID, To: l.ID, Type: l.Type})
+	}
+	return edges
+}
+
+// graphRoots returns the tasks no other task in tasks depends on, the ends
+// of their dependency chains.
+func graphRoots(tasks []*task.Task) []*task.Task {
+	needed := make(map[string]bool)
+	for _, e := range graphEdges(tasks) {
+		needed[e.To] = true
+	}
+	return slices.DeleteFunc(slices.Clone(tasks), func(t *task.Task) bool { return needed[t.ID] })
+}
+
+// FormatMermaid formats the dependency graph of tasks as a fenced Mermaid
+// flowchart, prerequisites above the tasks they unblock, for pasting into
+// issues and notes. Any-of dependencies are dotted, and typed links dotted and
+// labeled. Anything else they reference, such as closed tasks or GitHub
+// issues, appears by ID or URL alone.
+func FormatMermaid(tasks []*task.Task) string {
+	var sb strings.Builder
+	sb.WriteString("```mermaid\nflowchart TD\n")
+	nodes := make(map[string]string)
+	node := func(id, label string) string {
+		if n, ok := nodes[id]; ok {
+			return n
+		}
+		n := id
+		if !task.IsValidID(id) || id == "end" { // URLs, and Mermaid's keyword
+			n = fmt.Sprintf("ref%d", len(nodes)+1)
+		}
+		nodes[id] = n
+		sb.WriteString(fmt.Sprintf("    %s[\"%s\"]\n", n, mermaidLabel(label)))
+		return n
+	}
+
+	byStatus := make(map[task.Status][]string)
+	for _, t := range tasks {
+		n := node(t.ID, fmt.Sprintf("[%s] %s", t.ID, t.Title))
+		byStatus[t.Status] = append(byStatus[t.Status], n)
+	}
+	for _, e := range graphEdges(tasks) {
+		var arrow string
+		switch {
+		case e.AnyOf:
+			arrow = "-.->"
+		case e.Type == task.LinkDependsOn:
+			arrow = "-->"
+		case e.Type == task.LinkRelatesTo: // Goes both ways
+			arrow = "-.-|" + string(e.Type) + "|"
+		default:
+			arrow = "-.->|" + string(e.Type) + "|"
+		}
+		if !e.Type.Blocking() { // Drawn from the linking task, not as a prerequisite
+			sb.WriteString(fmt.Sprintf("    %s %s %s\n", nodes[e.From], arrow, node(e.To, e.To)))
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("    %s %s %s\n", node(e.To, e.To), arrow, nodes[e.From]))
+	}
+	for _, s := range []task.Status{task.StatusActive, task.StatusClosed} {
+		if ns := byStatus[s]; len(ns) > 0 {
+			sb.WriteString(fmt.Sprintf("    class %s %s\n", strings.Join(ns, ","), s))
+		}
+	}
+	sb.WriteString("    classDef active stroke-width:3px\n")
+	sb.WriteString("    classDef closed stroke-dasharray:4 4,color:#888\n")
+	sb.WriteString("```\n")
+	return sb.String()
+}
+
+// mermaidLabel escapes text for a quoted Mermaid node label.
+func mermaidLabel(text string) string {
+	return strings.NewReplacer(`"`, "#quot;", "\n", " ").Replace(text)
+}
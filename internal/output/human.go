@@ -2,17 +2,112 @@ package output
 
 import (
 	"fmt"
+	"os"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
 
+	"github.com/abatilo/bits/internal/deps"
+	"github.com/abatilo/bits/internal/plan"
+	"github.com/abatilo/bits/internal/plugin"
+	"github.com/abatilo/bits/internal/report"
+	"github.com/abatilo/bits/internal/scan"
+	"github.com/abatilo/bits/internal/storage"
 	"github.com/abatilo/bits/internal/task"
 )
 
+const (
+	timeLayout     = "2006-01-02 15:04"
+	shortSHALength = 7
+)
+
+// HumanOptions configures a HumanFormatter.
+type HumanOptions struct {
+	// Absolute shows absolute timestamps instead of relative ages.
+	Absolute bool
+	// Now returns the reference time for relative ages. Defaults to time.Now.
+	Now func() time.Time
+	// StatusGlyphs and PriorityGlyphs override the default ASCII icons and marks.
+	StatusGlyphs   map[task.Status]string
+	PriorityGlyphs map[task.Priority]string
+	// ASCIIOnly ignores overrides containing non-ASCII characters, for
+	// terminals that can't render them.
+	ASCIIOnly bool
+	// Width is the terminal width to fit output to. Zero disables truncation
+	// and wrapping.
+	Width int
+	// SLA is the target time to close by priority; unfinished tasks past it
+	// are flagged on task lines.
+	SLA map[task.Priority]time.Duration
+}
+
 // HumanFormatter formats output for human-readable terminal display.
-type HumanFormatter struct{}
+type HumanFormatter struct {
+	absolute       bool
+	now            func() time.Time
+	statusGlyphs   map[task.Status]string
+	priorityGlyphs map[task.Priority]string
+	width          int
+	ellipsis       string
+	sla            map[task.Priority]time.Duration
+}
 
 // NewHumanFormatter creates a new HumanFormatter.
-func NewHumanFormatter() *HumanFormatter {
-	return &HumanFormatter{}
+func NewHumanFormatter(opts HumanOptions) *HumanFormatter {
+	now := opts.Now
+	if now == nil {
+		now = time.Now
+	}
+	marker := ellipsis
+	if opts.ASCIIOnly {
+		marker = asciiEllipsis
+	}
+	return &HumanFormatter{
+		absolute:       opts.Absolute,
+		now:            now,
+		statusGlyphs:   usableGlyphs(opts.StatusGlyphs, opts.ASCIIOnly),
+		priorityGlyphs: usableGlyphs(opts.PriorityGlyphs, opts.ASCIIOnly),
+		width:          opts.Width,
+		ellipsis:       marker,
+		sla:            opts.SLA,
+	}
+}
+
+// usableGlyphs drops empty overrides, and non-ASCII ones when asciiOnly is set,
+// so the default ASCII glyph is used in their place.
+func usableGlyphs[K comparable](glyphs map[K]string, asciiOnly bool) map[K]string {
+	usable := make(map[K]string, len(glyphs))
+	for key, glyph := range glyphs {
+		if glyph == "" || (asciiOnly && !isASCII(glyph)) {
+			continue
+		}
+		usable[key] = glyph
+	}
+	return usable
+}
+
+func isASCII(s string) bool {
+	for i := range len(s) {
+		if s[i] > unicode.MaxASCII {
+			return false
+		}
+	}
+	return true
+}
+
+// UnicodeSupported reports whether the locale environment (LC_ALL, LC_CTYPE,
+// then LANG) selects a UTF-8 encoding.
+func UnicodeSupported() bool {
+	for _, name := range []string{"LC_ALL", "LC_CTYPE", "LANG"} {
+		if value := os.Getenv(name); value != "" {
+			value = strings.ToLower(value)
+			return strings.Contains(value, "utf-8") || strings.Contains(value, "utf8")
+		}
+	}
+	return false
 }
 
 // FormatTask formats a single task for display.
@@ -22,22 +117,137 @@ func (f *HumanFormatter) FormatTask(t *task.Task) string {
 	sb.WriteString(fmt.Sprintf("[%s] %s\n", t.ID, t.Title))
 	sb.WriteString(fmt.Sprintf("  Status:   %s\n", t.Status))
 	sb.WriteString(fmt.Sprintf("  Priority: %s\n", t.Priority))
-	sb.WriteString(fmt.Sprintf("  Created:  %s\n", t.CreatedAt.Format("2006-01-02 15:04")))
+	sb.WriteString(fmt.Sprintf("  Created:  %s\n", f.timestamp(t.CreatedAt)))
 
+	if t.Status == task.StatusActive && t.ClaimedAt != nil {
+		claimed := f.claimedTimestamp(*t.ClaimedAt)
+		if claim := t.LastEvent(task.EventClaim); claim != nil {
+			if claim.Actor != "" {
+				claimed += " by " + claim.Actor
+			}
+			if claim.Note != "" {
+				claimed += " (" + claim.Note + ")"
+			}
+		}
+		sb.WriteString(fmt.Sprintf("  Claimed:  %s\n", claimed))
+	}
 	if t.ClosedAt != nil {
-		sb.WriteString(fmt.Sprintf("  Closed:   %s\n", t.ClosedAt.Format("2006-01-02 15:04")))
+		sb.WriteString(fmt.Sprintf("  Closed:   %s\n", f.timestamp(*t.ClosedAt)))
+	}
+	if t.DueAt != nil {
+		sb.WriteString(fmt.Sprintf("  Due:      %s\n", f.dueTimestamp(t)))
 	}
 	if t.CloseReason != nil && *t.CloseReason != "" {
 		sb.WriteString(fmt.Sprintf("  Reason:   %s\n", *t.CloseReason))
+		if closed := t.LastEvent(task.EventClose); closed != nil && closed.Note != "" {
+			sb.WriteString(fmt.Sprintf("  Checked:  %s\n", closed.Note))
+		}
 	}
 	if len(t.DependsOn) > 0 {
 		sb.WriteString(fmt.Sprintf("  Depends:  %s\n", strings.Join(t.DependsOn, ", ")))
 	}
+	for _, group := range t.DependsAny {
+		sb.WriteString(fmt.Sprintf("  Any of:   %s\n", strings.Join(group, ", ")))
+	}
+	if len(t.After) > 0 {
+		hints := make([]string, len(t.After))
+		for i, h := range t.After {
+			hints[i] = h.ID
+			if w := h.EffectiveWeight(); w > 1 {
+				hints[i] += fmt.Sprintf(" (weight %d)", w)
+			}
+		}
+		sb.WriteString(fmt.Sprintf("  After:    %s\n", strings.Join(hints, ", ")))
+	}
+	if len(t.Links) > 0 {
+		links := make([]string, len(t.Links))
+		for i, l := range t.Links {
+			links[i] = fmt.Sprintf("%s (%s)", l.ID, l.Type)
+		}
+		sb.WriteString(fmt.Sprintf("  Links:    %s\n", strings.Join(links, ", ")))
+	}
+	if t.Parent != "" {
+		sb.WriteString(fmt.Sprintf("  Parent:   %s\n", t.Parent))
+	}
+	if len(t.Children) > 0 {
+		sb.WriteString(fmt.Sprintf("  Children: %s\n", strings.Join(t.Children, ", ")))
+	}
+	if len(t.Tags) > 0 {
+		sb.WriteString(fmt.Sprintf("  Tags:     %s\n", strings.Join(t.Tags, ", ")))
+	}
+	if t.Branch != "" {
+		sb.WriteString(fmt.Sprintf("  Branch:   %s\n", t.Branch))
+	}
+	if t.Key != "" {
+		sb.WriteString(fmt.Sprintf("  Key:      %s\n", t.Key))
+	}
+	if t.Source.File != "" {
+		sb.WriteString(fmt.Sprintf("  Source:   %s\n", t.Source))
+	}
+	if t.Assignee != "" {
+		sb.WriteString(fmt.Sprintf("  Assignee: %s\n", t.Assignee))
+	}
+	if t.LinearID != "" {
+		sb.WriteString(fmt.Sprintf("  Linear:   %s\n", t.LinearID))
+	}
+	if t.Estimate > 0 {
+		sb.WriteString(fmt.Sprintf("  Estimate: %s\n", RelativeDuration(t.Estimate)))
+	}
+	if len(t.Attachments) > 0 {
+		sb.WriteString(fmt.Sprintf("  Attached: %s\n", strings.Join(t.Attachments, ", ")))
+	}
+	if t.Verify != "" {
+		sb.WriteString(fmt.Sprintf("  Verify:   %s\n", t.Verify))
+	}
+	if t.OnClaim != "" {
+		sb.WriteString(fmt.Sprintf("  OnClaim:  %s\n", t.OnClaim))
+	}
+	if t.OnClose != "" {
+		sb.WriteString(fmt.Sprintf("  OnClose:  %s\n", t.OnClose))
+	}
+	for i, c := range t.Acceptance {
+		check := " "
+		if c.Done {
+			check = "x"
+		}
+		sb.WriteString(fmt.Sprintf("  Accept:   [%s] %d. %s\n", check, i+1, c.Text))
+	}
+	if len(t.Commits) > 0 {
+		short := make([]string, len(t.Commits))
+		for i, sha := range t.Commits {
+			short[i] = sha[:min(len(sha), shortSHALength)]
+		}
+		sb.WriteString(fmt.Sprintf("  Commits:  %s\n", strings.Join(short, ", ")))
+	}
+	for _, r := range t.Reminders {
+		remind := f.upcoming(r.At)
+		if r.Note != "" {
+			remind += ": " + r.Note
+		}
+		sb.WriteString(fmt.Sprintf("  Remind:   %s\n", remind))
+	}
 	if t.Description != "" {
 		sb.WriteString("\n")
-		sb.WriteString(t.Description)
+		if f.width > 0 {
+			sb.WriteString(wrap(t.Description, f.width))
+		} else {
+			sb.WriteString(t.Description)
+		}
 		sb.WriteString("\n")
 	}
+	if len(t.Notes) > 0 {
+		sb.WriteString("\nNotes:\n")
+		for _, n := range t.Notes {
+			header := f.timestamp(n.At)
+			if n.Actor != "" {
+				header += " by " + n.Actor
+			}
+			sb.WriteString("  " + header + "\n")
+			for _, line := range strings.Split(n.Text, "\n") {
+				sb.WriteString("    " + line + "\n")
+			}
+		}
+	}
 
 	return sb.String()
 }
@@ -55,18 +265,158 @@ func (f *HumanFormatter) FormatTaskList(tasks []*task.Task) string {
 	return sb.String()
 }
 
-// formatTaskLine formats a single task as a compact one-liner.
+// formatTaskLine formats a single task as a compact one-liner. When a width
+// is set, the dependency list collapses to a count and then the title is
+// ellipsized so the line fits.
 func (f *HumanFormatter) formatTaskLine(t *task.Task) string {
-	statusIcon := f.statusIcon(t.Status)
-	priorityMark := f.priorityMark(t.Priority)
+	prefix := fmt.Sprintf("%s %s [%s] ", f.statusIcon(t.Status), f.priorityMark(t.Priority), t.ID)
+	age := f.ageSuffix(t) + f.dueSuffix(t) + f.slaSuffix(t)
+	title := t.Title
+	if t.Assignee != "" {
+		age = " @" + t.Assignee + age
+	}
 	deps := ""
-	if len(t.DependsOn) > 0 {
-		deps = fmt.Sprintf(" [blocked by: %s]", strings.Join(t.DependsOn, ", "))
+	labels := dependencyLabels(t)
+	if len(labels) > 0 {
+		deps = fmt.Sprintf(" [blocked by: %s]", strings.Join(labels, ", "))
+	}
+
+	if f.width > 0 {
+		room := f.width - utf8.RuneCountInString(prefix) - utf8.RuneCountInString(age)
+		if utf8.RuneCountInString(title)+utf8.RuneCountInString(deps) > room && len(labels) > 1 {
+			deps = fmt.Sprintf(" [blocked by %d]", len(labels))
+		}
+		title = truncate(title, max(room-utf8.RuneCountInString(deps), minTitleWidth), f.ellipsis)
+	}
+	return prefix + title + deps + age + "\n"
+}
+
+// dependencyLabels lists a task's dependencies for a task line, with each
+// any-of group written as its members joined by "|".
+func dependencyLabels(t *task.Task) []string {
+	labels := slices.Clone(t.DependsOn)
+	for _, group := range t.DependsAny {
+		labels = append(labels, strings.Join(group, "|"))
+	}
+	return labels
+}
+
+// ageSuffix returns the relative age shown at the end of a task line: how long
+// an active task has been claimed, how long ago a closed task was closed, and
+// otherwise how long ago the task was created. It is empty in absolute mode.
+func (f *HumanFormatter) ageSuffix(t *task.Task) string {
+	if f.absolute {
+		return ""
+	}
+	switch {
+	case t.Status == task.StatusActive && t.ClaimedAt != nil:
+		return fmt.Sprintf(" (active %s)", RelativeDuration(f.now().Sub(*t.ClaimedAt)))
+	case t.Status == task.StatusClosed && t.ClosedAt != nil:
+		return fmt.Sprintf(" (closed %s ago)", RelativeDuration(f.now().Sub(*t.ClosedAt)))
+	case t.CreatedAt.IsZero():
+		return ""
+	default:
+		return fmt.Sprintf(" (%s ago)", RelativeDuration(f.now().Sub(t.CreatedAt)))
+	}
+}
+
+// dueSuffix returns the due marker shown at the end of an unfinished task's
+// line: how far off the due date is, or how long ago it passed.
+func (f *HumanFormatter) dueSuffix(t *task.Task) string {
+	if t.DueAt == nil || t.Status == task.StatusClosed {
+		return ""
+	}
+	switch {
+	case f.absolute:
+		return fmt.Sprintf(" (due %s)", t.DueAt.Format(timeLayout))
+	case t.IsOverdue(f.now()):
+		return fmt.Sprintf(" (overdue %s)", RelativeDuration(f.now().Sub(*t.DueAt)))
+	default:
+		return fmt.Sprintf(" (due in %s)", RelativeDuration(t.DueAt.Sub(f.now())))
+	}
+}
+
+// slaSuffix returns the marker shown at the end of an unfinished task's line
+// once it is past its priority's target time to close.
+func (f *HumanFormatter) slaSuffix(t *task.Task) string {
+	target := f.sla[t.Priority]
+	if t.Status == task.StatusClosed || !t.BreachesSLA(target, f.now()) {
+		return ""
+	}
+	return fmt.Sprintf(" (SLA breached %s ago)", RelativeDuration(f.now().Sub(t.CreatedAt.Add(target))))
+}
+
+// dueTimestamp formats a due date relative to now followed by the absolute
+// time, or only the absolute time in absolute mode or once the task is closed.
+func (f *HumanFormatter) dueTimestamp(t *task.Task) string {
+	due := t.DueAt.Format(timeLayout)
+	switch {
+	case f.absolute || t.Status == task.StatusClosed:
+		return due
+	case t.IsOverdue(f.now()):
+		return fmt.Sprintf("overdue by %s (%s)", RelativeDuration(f.now().Sub(*t.DueAt)), due)
+	default:
+		return f.upcoming(*t.DueAt)
+	}
+}
+
+// upcoming formats a future time as how far off it is followed by the
+// absolute time, or only the absolute time in absolute mode.
+func (f *HumanFormatter) upcoming(t time.Time) string {
+	if f.absolute {
+		return t.Format(timeLayout)
+	}
+	return fmt.Sprintf("in %s (%s)", RelativeDuration(t.Sub(f.now())), t.Format(timeLayout))
+}
+
+// timestamp formats a past time as a relative age followed by the absolute
+// time, or only the absolute time in absolute mode.
+func (f *HumanFormatter) timestamp(t time.Time) string {
+	if f.absolute {
+		return t.Format(timeLayout)
+	}
+	return fmt.Sprintf("%s ago (%s)", RelativeDuration(f.now().Sub(t)), t.Format(timeLayout))
+}
+
+// claimedTimestamp formats the claim time of an active task as how long it
+// has been active.
+func (f *HumanFormatter) claimedTimestamp(t time.Time) string {
+	if f.absolute {
+		return t.Format(timeLayout)
+	}
+	return fmt.Sprintf("active for %s (%s)", RelativeDuration(f.now().Sub(t)), t.Format(timeLayout))
+}
+
+// RelativeDuration renders a duration in its largest whole unit, e.g. "45s",
+// "3m", "2h", "5d", "4mo", or "1y". Negative durations (clock skew) render as "0s".
+func RelativeDuration(d time.Duration) string {
+	const (
+		day   = 24 * time.Hour
+		month = 30 * day
+		year  = 365 * day
+	)
+	switch {
+	case d < 0:
+		return "0s"
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d/time.Second))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d/time.Minute))
+	case d < day:
+		return fmt.Sprintf("%dh", int(d/time.Hour))
+	case d < month:
+		return fmt.Sprintf("%dd", int(d/day))
+	case d < year:
+		return fmt.Sprintf("%dmo", int(d/month))
+	default:
+		return fmt.Sprintf("%dy", int(d/year))
 	}
-	return fmt.Sprintf("%s %s [%s] %s%s\n", statusIcon, priorityMark, t.ID, t.Title, deps)
 }
 
 func (f *HumanFormatter) statusIcon(s task.Status) string {
+	if glyph, ok := f.statusGlyphs[s]; ok {
+		return glyph
+	}
 	switch s {
 	case task.StatusOpen:
 		return "[ ]"
@@ -80,6 +430,9 @@ func (f *HumanFormatter) statusIcon(s task.Status) string {
 }
 
 func (f *HumanFormatter) priorityMark(p task.Priority) string {
+	if glyph, ok := f.priorityGlyphs[p]; ok {
+		return glyph
+	}
 	switch p {
 	case task.PriorityCritical:
 		return "P0"
@@ -94,6 +447,554 @@ func (f *HumanFormatter) priorityMark(p task.Priority) string {
 	}
 }
 
+// FormatTaskLog formats a task's creation and lifecycle events, oldest
+// first, one per line.
+func (f *HumanFormatter) FormatTaskLog(t *task.Task) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("[%s] %s\n", t.ID, t.Title))
+	sb.WriteString(fmt.Sprintf("  %s  %s\n", t.CreatedAt.Format(timeLayout), logCreate))
+	for _, e := range t.History {
+		line := fmt.Sprintf("  %s  %-8s", e.At.Format(timeLayout), e.Action)
+		if e.Actor != "" {
+			line += " by " + e.Actor
+		}
+		if e.Note != "" {
+			line += " (" + e.Note + ")"
+		}
+		sb.WriteString(strings.TrimRight(line, " ") + "\n")
+	}
+	return sb.String()
+}
+
+// FormatGraph formats the dependency graph of tasks as indented trees: each
+// task nothing else needs, then what it depends on or links to beneath it,
+// labeled with the link type. A task shown once already is marked rather than
+// expanded again.
+func (f *HumanFormatter) FormatGraph(tasks []*task.Task) string {
+	if len(tasks) == 0 {
+		return "No tasks found.\n"
+	}
+
+	byID := make(map[string]*task.Task, len(tasks))
+	for _, t := range tasks {
+		byID[t.ID] = t
+	}
+	var sb strings.Builder
+	shown := make(map[string]bool)
+	var walk func(id string, depth int, label string)
+	walk = func(id string, depth int, label string) {
+		indent := strings.Repeat("  ", depth)
+		if label != "" {
+			label = " (" + label + ")"
+		}
+		t := byID[id]
+		if t == nil {
+			sb.WriteString(fmt.Sprintf("%s%s%s\n", indent, id, label))
+			return
+		}
+		line := fmt.Sprintf("%s%s [%s] %s%s", indent, f.statusIcon(t.Status), t.ID, t.Title, label)
+		if shown[id] {
+			sb.WriteString(line + " (see above)\n")
+			return
+		}
+		shown[id] = true
+		sb.WriteString(line + "\n")
+		for _, e := range taskEdges(t) {
+			walk(e.To, depth+1, e.label())
+		}
+	}
+	for _, t := range graphRoots(tasks) {
+		walk(t.ID, 0, "")
+	}
+	for _, t := range tasks { // Only reachable through a cycle
+		if !shown[t.ID] {
+			walk(t.ID, 0, "")
+		}
+	}
+	return sb.String()
+}
+
+// FormatBlocked formats blocked tasks for display, each followed by its
+// blockers.
+func (f *HumanFormatter) FormatBlocked(blocked []deps.BlockedTask) string {
+	if len(blocked) == 0 {
+		return "No blocked tasks.\n"
+	}
+
+	var sb strings.Builder
+	for _, bt := range blocked {
+		sb.WriteString(fmt.Sprintf("%s %s [%s] %s\n", f.statusIcon(bt.Task.Status), f.priorityMark(bt.Task.Priority),
+			bt.Task.ID, bt.Task.Title))
+		for _, b := range bt.Blockers {
+			if b.External {
+				sb.WriteString(fmt.Sprintf("    blocked by %s (external)\n", b.ID))
+			} else {
+				sb.WriteString(fmt.Sprintf("    blocked by [%s] %s (%s)\n", b.ID, b.Title, b.Status))
+			}
+		}
+	}
+	return sb.String()
+}
+
+// FormatIssues formats store integrity issues for display.
+func (f *HumanFormatter) FormatIssues(issues []storage.Issue) string {
+	if len(issues) == 0 {
+		return "No issues found.\n"
+	}
+
+	var sb strings.Builder
+	for _, issue := range issues {
+		sb.WriteString(fmt.Sprintf("[%s] %s: %s\n", issue.ID, issue.Kind, issue.Detail))
+		sb.WriteString(fmt.Sprintf("  %s\n", issue.File))
+	}
+	return sb.String()
+}
+
+// FormatSnapshots formats a list of snapshots for display.
+func (f *HumanFormatter) FormatSnapshots(snapshots []storage.Snapshot) string {
+	if len(snapshots) == 0 {
+		return "No snapshots found.\n"
+	}
+
+	var sb strings.Builder
+	for _, snap := range snapshots {
+		sb.WriteString(fmt.Sprintf("%s  %s  %d task(s)\n", snap.Name, snap.CreatedAt.Format(timeLayout), snap.Tasks))
+	}
+	return sb.String()
+}
+
+// FormatJournal formats journal entries for display, one per line.
+func (f *HumanFormatter) FormatJournal(entries []storage.JournalEntry) string {
+	if len(entries) == 0 {
+		return "No history.\n"
+	}
+
+	var sb strings.Builder
+	for _, e := range entries {
+		op := e.Op
+		if e.Undoes > 0 {
+			op = fmt.Sprintf("%s #%d", op, e.Undoes)
+		}
+		sb.WriteString(fmt.Sprintf("#%-4d %s  %-12s %s", e.Seq, e.At.Local().Format(timeLayout), op,
+			strings.Join(e.TaskIDs(), ", ")))
+		if e.Actor != "" {
+			sb.WriteString("  by " + e.Actor)
+		}
+		if e.Undone {
+			sb.WriteString("  (undone)")
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// FormatSummary formats task counts as a compact prompt segment such as
+// "3o/1a abc123", listing active task IDs after the counts.
+func (f *HumanFormatter) FormatSummary(summary storage.Summary) string {
+	segment := fmt.Sprintf("%do/%da", summary.Open, summary.Active)
+	if len(summary.ActiveIDs) > 0 {
+		segment += " " + strings.Join(summary.ActiveIDs, ",")
+	}
+	return segment + "\n"
+}
+
+// FormatChangelog formats changelog sections as Markdown.
+func (f *HumanFormatter) FormatChangelog(sections []report.Section) string {
+	if len(sections) == 0 {
+		return "No changes.\n"
+	}
+
+	var sb strings.Builder
+	for i, section := range sections {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		sb.WriteString(fmt.Sprintf("## %s\n\n", section.Title))
+		for _, t := range section.Tasks {
+			sb.WriteString(fmt.Sprintf("- %s (%s)\n", t.Title, t.ID))
+		}
+	}
+	return sb.String()
+}
+
+// FormatWeekly formats a weekly report as a Markdown document.
+func (f *HumanFormatter) FormatWeekly(r report.WeeklyReport) string {
+	const dateLayout = "2006-01-02"
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("# Weekly report: %s to %s\n\n",
+		r.Start.Format(dateLayout), r.End.Format(dateLayout)))
+
+	sb.WriteString("| | This week | Last week | Change |\n|---|---:|---:|---:|\n")
+	count := func(name string, this, last int) {
+		sb.WriteString(fmt.Sprintf("| %s | %d | %d | %+d |\n", name, this, last, this-last))
+	}
+	median := func(name string, this, last report.Distribution) {
+		cell := func(d report.Distribution) string {
+			if d.Count == 0 {
+				return "-"
+			}
+			return RelativeDuration(d.P50)
+		}
+		change := "-"
+		if this.Count > 0 && last.Count > 0 {
+			delta := this.P50 - last.P50
+			change = "+" + RelativeDuration(delta)
+			if delta < 0 {
+				change = "-" + RelativeDuration(-delta)
+			}
+		}
+		sb.WriteString(fmt.Sprintf("| %s | %s | %s | %s |\n", name, cell(this), cell(last), change))
+	}
+	count("Closed", r.This.Closed, r.Previous.Closed)
+	count("Created", r.This.Created, r.Previous.Created)
+	median("Lead time (p50)", r.This.LeadTime, r.Previous.LeadTime)
+	median("Cycle time (p50)", r.This.CycleTime, r.Previous.CycleTime)
+
+	section := func(title string, n int) {
+		sb.WriteString(fmt.Sprintf("\n## %s (%d)\n\n", title, n))
+		if n == 0 {
+			sb.WriteString("None.\n")
+		}
+	}
+	section("Closed", len(r.Closed))
+	for _, t := range r.Closed {
+		sb.WriteString(fmt.Sprintf("- %s (%s)\n", t.Title, t.ID))
+	}
+	section("New", len(r.Created))
+	for _, t := range r.Created {
+		sb.WriteString(fmt.Sprintf("- %s (%s, %s)\n", t.Title, t.ID, t.Priority))
+	}
+	section("Aging blockers", len(r.Blockers))
+	for _, b := range r.Blockers {
+		sb.WriteString(fmt.Sprintf("- %s (%s): %s for %s, blocking %d\n",
+			b.Task.Title, b.Task.ID, b.Task.Status, RelativeDuration(b.Age), b.Blocking))
+	}
+	return sb.String()
+}
+
+// FormatReasons formats grouped close reasons as a table, with the tasks
+// per priority and tag for each reason.
+func (f *HumanFormatter) FormatReasons(groups []report.ReasonGroup) string {
+	if len(groups) == 0 {
+		return "No closed tasks found.\n"
+	}
+
+	join := func(counts []report.Count) string {
+		if len(counts) == 0 {
+			return "-"
+		}
+		parts := make([]string, len(counts))
+		for i, c := range counts {
+			parts[i] = fmt.Sprintf("%s:%d", c.Name, c.Tasks)
+		}
+		return strings.Join(parts, " ")
+	}
+
+	priorityWidth, tagWidth := len("PRIORITY"), len("TAGS")
+	for _, g := range groups {
+		priorityWidth = max(priorityWidth, utf8.RuneCountInString(join(g.Priorities)))
+		tagWidth = max(tagWidth, utf8.RuneCountInString(join(g.Tags)))
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%5s  %-*s  %-*s  %s\n", "TASKS", priorityWidth, "PRIORITY", tagWidth, "TAGS", "REASON"))
+	for _, g := range groups {
+		reason := g.Reason
+		if reason == "" {
+			reason = "(none)"
+		}
+		sb.WriteString(fmt.Sprintf("%5d  %-*s  %-*s  %s\n",
+			len(g.Tasks), priorityWidth, join(g.Priorities), tagWidth, join(g.Tags), reason))
+	}
+	return sb.String()
+}
+
+// quadrantTitle heads a matrix quadrant in the terminal grid.
+func quadrantTitle(q report.Quadrant) string {
+	switch {
+	case q.Urgent && q.Important:
+		return "DO FIRST: urgent, important"
+	case q.Important:
+		return "SCHEDULE: important, not urgent"
+	case q.Urgent:
+		return "DELEGATE: urgent, not important"
+	default:
+		return "LATER: neither"
+	}
+}
+
+// Matrix cell widths when the terminal width is unknown or narrow.
+const (
+	defaultCellWidth = 40
+	minCellWidth     = 24
+	cellGap          = "  "
+)
+
+// FormatMatrix formats the urgency/importance matrix as a 2x2 grid, the
+// quadrants side by side in pairs.
+func (f *HumanFormatter) FormatMatrix(quadrants []report.Quadrant) string {
+	width := defaultCellWidth
+	if f.width > 0 {
+		width = max((f.width-len(cellGap))/2, minCellWidth) //nolint:mnd // Two columns
+	}
+
+	cell := func(q report.Quadrant) []string {
+		lines := []string{truncate(fmt.Sprintf("%s (%d)", quadrantTitle(q), len(q.Tasks)), width, f.ellipsis)}
+		for _, t := range q.Tasks {
+			line := fmt.Sprintf("%s [%s] %s%s", f.priorityMark(t.Priority), t.ID, t.Title, f.dueSuffix(t))
+			lines = append(lines, truncate(line, width, f.ellipsis))
+		}
+		if len(q.Tasks) == 0 {
+			lines = append(lines, "(none)")
+		}
+		return lines
+	}
+
+	var sb strings.Builder
+	for i := 0; i+1 < len(quadrants); i += 2 {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		left, right := cell(quadrants[i]), cell(quadrants[i+1])
+		for row := range max(len(left), len(right)) {
+			var l, r string
+			if row < len(left) {
+				l = left[row]
+			}
+			if row < len(right) {
+				r = right[row]
+			}
+			sb.WriteString(strings.TrimRight(fmt.Sprintf("%-*s%s%s", width, l, cellGap, r), " ") + "\n")
+		}
+	}
+	return sb.String()
+}
+
+// FormatContributors formats per-actor throughput as a table.
+func (f *HumanFormatter) FormatContributors(contributors []report.Contributor) string {
+	if len(contributors) == 0 {
+		return "No activity found.\n"
+	}
+
+	width := len("ACTOR")
+	for _, c := range contributors {
+		width = max(width, utf8.RuneCountInString(c.Actor))
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%-*s  %6s  %8s  %12s\n", width, "ACTOR", "CLOSED", "ACTIVE", "AVG TO CLOSE"))
+	for _, c := range contributors {
+		toClose := "-"
+		if c.Closed > 0 {
+			toClose = RelativeDuration(c.TimeToClose)
+		}
+		sb.WriteString(fmt.Sprintf("%-*s  %6d  %8s  %12s\n",
+			width, c.Actor, c.Closed, RelativeDuration(c.ActiveTime), toClose))
+	}
+	return sb.String()
+}
+
+// FormatWorklog formats work log entries as a table.
+func (f *HumanFormatter) FormatWorklog(entries []report.WorkEntry) string {
+	if len(entries) == 0 {
+		return "No activity found.\n"
+	}
+
+	idWidth, actorWidth := len("TASK"), len("ACTOR")
+	for _, e := range entries {
+		idWidth = max(idWidth, len(e.TaskID))
+		actorWidth = max(actorWidth, utf8.RuneCountInString(e.Actor))
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%-*s  %-*s  %-16s  %-16s  %8s\n",
+		idWidth, "TASK", actorWidth, "ACTOR", "START", "END", "DURATION"))
+	for _, e := range entries {
+		sb.WriteString(fmt.Sprintf("%-*s  %-*s  %-16s  %-16s  %8s\n", idWidth, e.TaskID, actorWidth, e.Actor,
+			e.Start.Format(timeLayout), e.End.Format(timeLayout), RelativeDuration(e.End.Sub(e.Start))))
+	}
+	return sb.String()
+}
+
+// FormatCapacity formats per-assignee backlog projections as a table.
+func (f *HumanFormatter) FormatCapacity(capacities []report.Capacity) string {
+	if len(capacities) == 0 {
+		return "No assigned tasks found.\n"
+	}
+
+	width := len("ASSIGNEE")
+	for _, c := range capacities {
+		width = max(width, utf8.RuneCountInString(c.Assignee))
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%-*s  %5s  %8s  %3s  %10s  %s\n",
+		width, "ASSIGNEE", "TASKS", "ESTIMATE", "WIP", "CLOSED/DAY", "PROJECTED"))
+	for _, c := range capacities {
+		estimate, wip := "-", "-"
+		if c.Estimated > 0 {
+			estimate = RelativeDuration(c.Estimate)
+		}
+		if c.WIPLimit > 0 {
+			wip = strconv.Itoa(c.WIPLimit)
+		}
+		projected := RelativeDuration(c.Projection)
+		switch {
+		case c.Unprojected > 0 && c.Projection == 0:
+			projected = fmt.Sprintf("unknown (%d unestimated)", c.Unprojected)
+		case c.Unprojected > 0:
+			projected += fmt.Sprintf(" + %d unestimated", c.Unprojected)
+		}
+		sb.WriteString(fmt.Sprintf("%-*s  %5d  %8s  %3s  %10.2f  %s\n",
+			width, c.Assignee, c.Unfinished, estimate, wip, c.CloseRate, projected))
+	}
+	return sb.String()
+}
+
+// FormatFlow formats lead and cycle time percentiles as a table.
+func (f *HumanFormatter) FormatFlow(groups []report.FlowGroup) string {
+	if len(groups) == 0 {
+		return "No closed tasks found.\n"
+	}
+
+	width := len("GROUP")
+	for _, g := range groups {
+		width = max(width, utf8.RuneCountInString(g.Name))
+	}
+
+	percentiles := func(d report.Distribution) string {
+		if d.Count == 0 {
+			return fmt.Sprintf("%5s  %5s  %5s", "-", "-", "-")
+		}
+		return fmt.Sprintf("%5s  %5s  %5s", RelativeDuration(d.P50), RelativeDuration(d.P90), RelativeDuration(d.P95))
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%-*s  %6s  %-19s  %s\n", width, "", "", "LEAD TIME", "CYCLE TIME"))
+	sb.WriteString(fmt.Sprintf("%-*s  %6s  %5s  %5s  %5s  %7s  %5s  %5s  %5s  %4s\n",
+		width, "GROUP", "CLOSED", "P50", "P90", "P95", "CLAIMED", "P50", "P90", "P95", "SLA"))
+	for _, g := range groups {
+		sla := "-"
+		if attainment, ok := g.SLAAttainment(); ok {
+			sla = fmt.Sprintf("%.0f%%", attainment*100) //nolint:mnd // Percentage
+		}
+		sb.WriteString(fmt.Sprintf("%-*s  %6d  %s  %7d  %s  %4s\n", width, g.Name,
+			g.LeadTime.Count, percentiles(g.LeadTime), g.CycleTime.Count, percentiles(g.CycleTime), sla))
+	}
+	return sb.String()
+}
+
+// FormatPlugins formats discovered plugins as a table.
+func (f *HumanFormatter) FormatPlugins(plugins []plugin.Plugin) string {
+	if len(plugins) == 0 {
+		return "No plugins found.\n"
+	}
+
+	nameWidth := len("COMMAND")
+	for _, p := range plugins {
+		nameWidth = max(nameWidth, utf8.RuneCountInString(p.Name))
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%-*s  %s\n", nameWidth, "COMMAND", "PATH"))
+	for _, p := range plugins {
+		sb.WriteString(fmt.Sprintf("%-*s  %s\n", nameWidth, p.Name, p.Path))
+	}
+	return sb.String()
+}
+
+// planSymbol marks a kind of plan change, as in a diff.
+func planSymbol(k plan.Kind) string {
+	switch k {
+	case plan.KindCreate:
+		return "+"
+	case plan.KindClose:
+		return "-"
+	default:
+		return "~"
+	}
+}
+
+// FormatPlan formats plan changes as a diff-like list with a summary line.
+func (f *HumanFormatter) FormatPlan(changes []plan.Change) string {
+	if len(changes) == 0 {
+		return "No changes. The store matches the plan.\n"
+	}
+
+	var sb strings.Builder
+	counts := make(map[plan.Kind]int)
+	for _, c := range changes {
+		counts[c.Kind]++
+		id := ""
+		if c.ID != "" {
+			id = " [" + c.ID + "]"
+		}
+		sb.WriteString(fmt.Sprintf("%s %s%s %s", planSymbol(c.Kind), c.Name, id, c.Title))
+		if c.Reason != "" {
+			sb.WriteString(fmt.Sprintf(" (%s)", strings.ToLower(c.Reason)))
+		}
+		sb.WriteString("\n")
+		for _, field := range c.Fields {
+			from, to := field.From, field.To
+			if from == "" {
+				from = "(none)"
+			}
+			if to == "" {
+				to = "(none)"
+			}
+			if field.Field == "description" {
+				from, to = fmt.Sprintf("%d chars", len(field.From)), fmt.Sprintf("%d chars", len(field.To))
+			}
+			sb.WriteString(fmt.Sprintf("    %s: %s -> %s\n", field.Field, from, to))
+		}
+	}
+	sb.WriteString(fmt.Sprintf("%d to create, %d to update, %d to close.\n",
+		counts[plan.KindCreate], counts[plan.KindUpdate], counts[plan.KindClose]))
+	return sb.String()
+}
+
+// FormatScan formats scan changes as a diff-like list with a summary line.
+func (f *HumanFormatter) FormatScan(changes []scan.Change) string {
+	if len(changes) == 0 {
+		return "No changes. Every TODO(bits) and FIXME comment is tracked.\n"
+	}
+
+	var sb strings.Builder
+	counts := make(map[scan.Kind]int)
+	for _, c := range changes {
+		counts[c.Kind]++
+		id := ""
+		if c.ID != "" {
+			id = "[" + c.ID + "] "
+		}
+		var where string
+		switch c.Kind {
+		case scan.KindMove:
+			where = fmt.Sprintf("%s:%d -> %d", c.File, c.FromLine, c.Line)
+		case scan.KindClose:
+			where = fmt.Sprintf("%s:%d, %s", c.File, c.Line, strings.ToLower(scan.ReasonRemoved))
+		default:
+			where = fmt.Sprintf("%s:%d", c.File, c.Line)
+		}
+		sb.WriteString(fmt.Sprintf("%s %s%s (%s)\n", scanSymbol(c.Kind), id, c.Title, where))
+	}
+	sb.WriteString(fmt.Sprintf("%d to create, %d to move, %d to close.\n",
+		counts[scan.KindCreate], counts[scan.KindMove], counts[scan.KindClose]))
+	return sb.String()
+}
+
+// scanSymbol marks a scan change like planSymbol does a plan change.
+func scanSymbol(k scan.Kind) string {
+	switch k {
+	case scan.KindCreate:
+		return "+"
+	case scan.KindClose:
+		return "-"
+	default:
+		return "~"
+	}
+}
+
 // FormatError formats an error for display.
 func (f *HumanFormatter) FormatError(err error) string {
 	return fmt.Sprintf("Error: %s\n", err.Error())
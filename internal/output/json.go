@@ -4,11 +4,19 @@ import (
 	"encoding/json"
 	"time"
 
+	"github.com/abatilo/bits/internal/deps"
+	"github.com/abatilo/bits/internal/plan"
+	"github.com/abatilo/bits/internal/plugin"
+	"github.com/abatilo/bits/internal/report"
+	"github.com/abatilo/bits/internal/scan"
+	"github.com/abatilo/bits/internal/storage"
 	"github.com/abatilo/bits/internal/task"
 )
 
 // JSONFormatter formats output as JSON.
-type JSONFormatter struct{}
+type JSONFormatter struct {
+	projection *Projection
+}
 
 // marshalJSON marshals a value to indented JSON with a trailing newline.
 func marshalJSON(v any) string {
@@ -21,17 +29,61 @@ func NewJSONFormatter() *JSONFormatter {
 	return &JSONFormatter{}
 }
 
+// Project narrows every document except errors and messages with p.
+func (f *JSONFormatter) Project(p *Projection) *JSONFormatter {
+	f.projection = p
+	return f
+}
+
+// marshal marshals a document, applying the formatter's projection.
+func (f *JSONFormatter) marshal(v any) string {
+	doc := marshalJSON(v)
+	if f.projection.Empty() {
+		return doc
+	}
+	projected, err := f.projection.Apply([]byte(doc))
+	if err != nil {
+		return doc
+	}
+	return string(projected) + "\n"
+}
+
 // taskJSON is the JSON representation of a task.
 type taskJSON struct {
-	ID          string   `json:"id"`
-	Title       string   `json:"title"`
-	Status      string   `json:"status"`
-	Priority    string   `json:"priority"`
-	CreatedAt   string   `json:"created_at"`
-	ClosedAt    *string  `json:"closed_at,omitempty"`
-	CloseReason *string  `json:"close_reason,omitempty"`
-	DependsOn   []string `json:"depends_on,omitempty"`
-	Description string   `json:"description,omitempty"`
+	ID          string          `json:"id"`
+	Title       string          `json:"title"`
+	Status      string          `json:"status"`
+	Priority    string          `json:"priority"`
+	CreatedAt   string          `json:"created_at"`
+	UpdatedAt   *string         `json:"updated_at,omitempty"`
+	ClaimedAt   *string         `json:"claimed_at,omitempty"`
+	ClosedAt    *string         `json:"closed_at,omitempty"`
+	CloseReason *string         `json:"close_reason,omitempty"`
+	DueAt       *string         `json:"due_at,omitempty"`
+	Estimate    float64         `json:"estimate_seconds,omitempty"`
+	SLABreached bool            `json:"sla_breached,omitempty"`
+	DependsOn   []string        `json:"depends_on,omitempty"`
+	DependsAny  [][]string      `json:"depends_on_any,omitempty"`
+	After       []hintJSON      `json:"after,omitempty"`
+	Links       []linkJSON      `json:"links,omitempty"`
+	Parent      string          `json:"parent,omitempty"`
+	Children    []string        `json:"children,omitempty"`
+	Verify      string          `json:"verify,omitempty"`
+	Acceptance  []criterionJSON `json:"acceptance,omitempty"`
+	OnClaim     string          `json:"on_claim,omitempty"`
+	OnClose     string          `json:"on_close,omitempty"`
+	Tags        []string        `json:"tags,omitempty"`
+	Commits     []string        `json:"commits,omitempty"`
+	Branch      string          `json:"branch,omitempty"`
+	Key         string          `json:"key,omitempty"`
+	Source      *sourceJSON     `json:"source,omitempty"`
+	Assignee    string          `json:"assignee,omitempty"`
+	LinearID    string          `json:"linear_id,omitempty"`
+	Attachments []string        `json:"attachments,omitempty"`
+	History     []eventJSON     `json:"history,omitempty"`
+	Reminders   []reminderJSON  `json:"reminders,omitempty"`
+	Description string          `json:"description,omitempty"`
+	Notes       []noteJSON      `json:"notes,omitempty"`
 }
 
 func toTaskJSON(t *task.Task) taskJSON {
@@ -41,20 +93,242 @@ func toTaskJSON(t *task.Task) taskJSON {
 		Status:      string(t.Status),
 		Priority:    string(t.Priority),
 		CreatedAt:   t.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:   formatOptionalTime(t.UpdatedAt),
+		ClaimedAt:   formatOptionalTime(t.ClaimedAt),
+		ClosedAt:    formatOptionalTime(t.ClosedAt),
 		CloseReason: t.CloseReason,
+		DueAt:       formatOptionalTime(t.DueAt),
+		Estimate:    t.Estimate.Seconds(),
+		SLABreached: t.SLABreached,
 		DependsOn:   t.DependsOn,
+		DependsAny:  t.DependsAny,
+		Parent:      t.Parent,
+		Children:    t.Children,
+		Tags:        t.Tags,
+		Commits:     t.Commits,
+		Branch:      t.Branch,
+		Key:         t.Key,
+		Assignee:    t.Assignee,
+		LinearID:    t.LinearID,
+		Attachments: t.Attachments,
+		Verify:      t.Verify,
+		OnClaim:     t.OnClaim,
+		OnClose:     t.OnClose,
 		Description: t.Description,
 	}
-	if t.ClosedAt != nil {
-		s := t.ClosedAt.Format(time.RFC3339)
-		tj.ClosedAt = &s
+	for _, e := range t.History {
+		tj.History = append(tj.History, eventJSON{
+			At:     e.At.Format(time.RFC3339),
+			Action: string(e.Action),
+			Actor:  e.Actor,
+			Note:   e.Note,
+		})
+	}
+	for _, h := range t.After {
+		tj.After = append(tj.After, hintJSON{ID: h.ID, Weight: h.EffectiveWeight()})
+	}
+	for _, l := range t.Links {
+		tj.Links = append(tj.Links, linkJSON{ID: l.ID, Type: string(l.Type)})
+	}
+	for _, c := range t.Acceptance {
+		tj.Acceptance = append(tj.Acceptance, criterionJSON{Text: c.Text, Done: c.Done})
+	}
+	for _, r := range t.Reminders {
+		tj.Reminders = append(tj.Reminders, reminderJSON{At: r.At.Format(time.RFC3339), Note: r.Note})
+	}
+	for _, n := range t.Notes {
+		tj.Notes = append(tj.Notes, noteJSON{At: n.At.Format(time.RFC3339), Actor: n.Actor, Text: n.Text})
+	}
+	if t.Source.File != "" {
+		tj.Source = &sourceJSON{File: t.Source.File, Line: t.Source.Line, Text: t.Source.Text}
 	}
 	return tj
 }
 
+// fromTaskJSON converts the JSON representation back into a task.
+func fromTaskJSON(tj taskJSON) (*task.Task, error) {
+	t := &task.Task{
+		ID:          tj.ID,
+		Title:       tj.Title,
+		Status:      task.Status(tj.Status),
+		Priority:    task.Priority(tj.Priority),
+		CloseReason: tj.CloseReason,
+		Estimate:    time.Duration(tj.Estimate * float64(time.Second)),
+		SLABreached: tj.SLABreached,
+		DependsOn:   tj.DependsOn,
+		DependsAny:  tj.DependsAny,
+		Parent:      tj.Parent,
+		Children:    tj.Children,
+		Verify:      tj.Verify,
+		OnClaim:     tj.OnClaim,
+		OnClose:     tj.OnClose,
+		Tags:        tj.Tags,
+		Commits:     tj.Commits,
+		Branch:      tj.Branch,
+		Key:         tj.Key,
+		Assignee:    tj.Assignee,
+		LinearID:    tj.LinearID,
+		Attachments: tj.Attachments,
+		Description: tj.Description,
+	}
+
+	var err error
+	if tj.CreatedAt != "" { // Optional in files written by other tools
+		if t.CreatedAt, err = time.Parse(time.RFC3339, tj.CreatedAt); err != nil {
+			return nil, err
+		}
+	}
+	for _, field := range []struct {
+		dst **time.Time
+		src *string
+	}{
+		{&t.UpdatedAt, tj.UpdatedAt}, {&t.ClaimedAt, tj.ClaimedAt}, {&t.ClosedAt, tj.ClosedAt}, {&t.DueAt, tj.DueAt},
+	} {
+		if *field.dst, err = parseOptionalTime(field.src); err != nil {
+			return nil, err
+		}
+	}
+	for _, e := range tj.History {
+		at, parseErr := time.Parse(time.RFC3339, e.At)
+		if parseErr != nil {
+			return nil, parseErr
+		}
+		t.History = append(t.History, task.Event{
+			At:     at,
+			Action: task.EventAction(e.Action),
+			Actor:  e.Actor,
+			Note:   e.Note,
+		})
+	}
+	for _, h := range tj.After {
+		hint := task.Hint{ID: h.ID}
+		if h.Weight > 1 {
+			hint.Weight = h.Weight
+		}
+		t.After = append(t.After, hint)
+	}
+	for _, l := range tj.Links {
+		t.Links = append(t.Links, task.Link{ID: l.ID, Type: task.LinkType(l.Type)})
+	}
+	for _, c := range tj.Acceptance {
+		t.Acceptance = append(t.Acceptance, task.Criterion{Text: c.Text, Done: c.Done})
+	}
+	for _, r := range tj.Reminders {
+		at, parseErr := time.Parse(time.RFC3339, r.At)
+		if parseErr != nil {
+			return nil, parseErr
+		}
+		t.Reminders = append(t.Reminders, task.Reminder{At: at, Note: r.Note})
+	}
+	for _, n := range tj.Notes {
+		at, parseErr := time.Parse(time.RFC3339, n.At)
+		if parseErr != nil {
+			return nil, parseErr
+		}
+		t.Notes = append(t.Notes, task.Note{At: at, Actor: n.Actor, Text: n.Text})
+	}
+	if tj.Source != nil {
+		t.Source = task.Source{File: tj.Source.File, Line: tj.Source.Line, Text: tj.Source.Text}
+	}
+	return t, nil
+}
+
+// ParseTask decodes a task from the JSON written by FormatTask.
+func ParseTask(data []byte) (*task.Task, error) {
+	var tj taskJSON
+	if err := json.Unmarshal(data, &tj); err != nil {
+		return nil, err
+	}
+	return fromTaskJSON(tj)
+}
+
+// ParseTaskList decodes tasks from the JSON written by FormatTaskList.
+func ParseTaskList(data []byte) ([]*task.Task, error) {
+	var list []taskJSON
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, err
+	}
+	tasks := make([]*task.Task, len(list))
+	for i, tj := range list {
+		t, err := fromTaskJSON(tj)
+		if err != nil {
+			return nil, err
+		}
+		tasks[i] = t
+	}
+	return tasks, nil
+}
+
+// linkJSON is the JSON representation of a typed link.
+type linkJSON struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+}
+
+// hintJSON is the JSON representation of an ordering hint.
+type hintJSON struct {
+	ID     string `json:"id"`
+	Weight int    `json:"weight"`
+}
+
+// criterionJSON is the JSON representation of an acceptance criterion.
+type criterionJSON struct {
+	Text string `json:"text"`
+	Done bool   `json:"done"`
+}
+
+// reminderJSON is the JSON representation of a scheduled reminder.
+type reminderJSON struct {
+	At   string `json:"at"`
+	Note string `json:"note,omitempty"`
+}
+
+// noteJSON is the JSON representation of a task note.
+type noteJSON struct {
+	At    string `json:"at"`
+	Actor string `json:"actor,omitempty"`
+	Text  string `json:"text"`
+}
+
+// sourceJSON is the JSON representation of a task's source comment.
+type sourceJSON struct {
+	File string `json:"file"`
+	Line int    `json:"line"`
+	Text string `json:"text,omitempty"`
+}
+
+// eventJSON is the JSON representation of a history event.
+type eventJSON struct {
+	At     string `json:"at"`
+	Action string `json:"action"`
+	Actor  string `json:"actor,omitempty"`
+	Note   string `json:"note,omitempty"`
+}
+
+// formatOptionalTime formats an optional timestamp as RFC3339, returning nil if unset.
+func formatOptionalTime(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+	s := t.Format(time.RFC3339)
+	return &s
+}
+
+// parseOptionalTime parses an optional RFC3339 timestamp, returning nil if unset.
+func parseOptionalTime(s *string) (*time.Time, error) {
+	if s == nil {
+		return nil, nil //nolint:nilnil // An unset timestamp is not an error
+	}
+	t, err := time.Parse(time.RFC3339, *s)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
 // FormatTask formats a single task as JSON.
 func (f *JSONFormatter) FormatTask(t *task.Task) string {
-	return marshalJSON(toTaskJSON(t))
+	return f.marshal(toTaskJSON(t))
 }
 
 // FormatTaskList formats a list of tasks as JSON.
@@ -63,7 +337,426 @@ func (f *JSONFormatter) FormatTaskList(tasks []*task.Task) string {
 	for i, t := range tasks {
 		jsonTasks[i] = toTaskJSON(t)
 	}
-	return marshalJSON(jsonTasks)
+	return f.marshal(jsonTasks)
+}
+
+// FormatTaskLog formats a task's creation and lifecycle events as JSON,
+// oldest first.
+func (f *JSONFormatter) FormatTaskLog(t *task.Task) string {
+	events := []eventJSON{{At: t.CreatedAt.Format(time.RFC3339), Action: logCreate}}
+	events = append(events, toTaskJSON(t).History...)
+	return f.marshal(events)
+}
+
+// graphJSON is a dependency graph: its tasks, and edges from each task to
+// what it depends on or links to.
+type graphJSON struct {
+	Nodes []graphNodeJSON `json:"nodes"`
+	Edges []graphEdgeJSON `json:"edges"`
+}
+
+type graphNodeJSON struct {
+	ID       string `json:"id"`
+	Title    string `json:"title"`
+	Status   string `json:"status"`
+	Priority string `json:"priority"`
+}
+
+type graphEdgeJSON struct {
+	From  string `json:"from"`
+	To    string `json:"to"`
+	Type  string `json:"type"`
+	AnyOf bool   `json:"any_of,omitempty"`
+}
+
+// FormatGraph formats the dependency graph of tasks as JSON.
+func (f *JSONFormatter) FormatGraph(tasks []*task.Task) string {
+	g := graphJSON{Nodes: make([]graphNodeJSON, len(tasks)), Edges: []graphEdgeJSON{}}
+	for i, t := range tasks {
+		g.Nodes[i] = graphNodeJSON{ID: t.ID, Title: t.Title, Status: string(t.Status), Priority: string(t.Priority)}
+	}
+	for _, e := range graphEdges(tasks) {
+		g.Edges = append(g.Edges, graphEdgeJSON{From: e.From, To: e.To, Type: string(e.Type), AnyOf: e.AnyOf})
+	}
+	return f.marshal(g)
+}
+
+// blockedJSON is an open task and its blockers.
+type blockedJSON struct {
+	ID       string          `json:"id"`
+	Title    string          `json:"title"`
+	Priority string          `json:"priority"`
+	Blockers []blockedByJSON `json:"blockers"`
+}
+
+type blockedByJSON struct {
+	ID       string `json:"id"`
+	Title    string `json:"title,omitempty"`
+	Status   string `json:"status,omitempty"` // Empty for an external blocker
+	External bool   `json:"external,omitempty"`
+}
+
+// FormatBlocked formats blocked tasks and their blockers as JSON.
+func (f *JSONFormatter) FormatBlocked(blocked []deps.BlockedTask) string {
+	jsonBlocked := make([]blockedJSON, len(blocked))
+	for i, bt := range blocked {
+		jb := blockedJSON{ID: bt.Task.ID, Title: bt.Task.Title, Priority: string(bt.Task.Priority)}
+		for _, b := range bt.Blockers {
+			jb.Blockers = append(jb.Blockers, blockedByJSON{
+				ID: b.ID, Title: b.Title, Status: string(b.Status), External: b.External,
+			})
+		}
+		jsonBlocked[i] = jb
+	}
+	return f.marshal(jsonBlocked)
+}
+
+// FormatIssues formats store integrity issues as JSON.
+func (f *JSONFormatter) FormatIssues(issues []storage.Issue) string {
+	if issues == nil {
+		issues = []storage.Issue{}
+	}
+	return f.marshal(issues)
+}
+
+// FormatSnapshots formats a list of snapshots as JSON.
+func (f *JSONFormatter) FormatSnapshots(snapshots []storage.Snapshot) string {
+	if snapshots == nil {
+		snapshots = []storage.Snapshot{}
+	}
+	return f.marshal(snapshots)
+}
+
+// journalJSON is a journal entry without the task files it keeps.
+type journalJSON struct {
+	Seq    int       `json:"seq"`
+	At     time.Time `json:"at"`
+	Op     string    `json:"op"`
+	Actor  string    `json:"actor,omitempty"`
+	Tasks  []string  `json:"tasks"`
+	Undoes int       `json:"undoes,omitempty"`
+	Undone bool      `json:"undone"`
+}
+
+// FormatJournal formats journal entries as JSON.
+func (f *JSONFormatter) FormatJournal(entries []storage.JournalEntry) string {
+	jsonEntries := make([]journalJSON, len(entries))
+	for i, e := range entries {
+		jsonEntries[i] = journalJSON{
+			Seq: e.Seq, At: e.At, Op: e.Op, Actor: e.Actor, Tasks: e.TaskIDs(), Undoes: e.Undoes, Undone: e.Undone,
+		}
+	}
+	return f.marshal(jsonEntries)
+}
+
+// FormatSummary formats task counts as JSON.
+func (f *JSONFormatter) FormatSummary(summary storage.Summary) string {
+	if summary.ActiveIDs == nil {
+		summary.ActiveIDs = []string{}
+	}
+	return f.marshal(summary)
+}
+
+// changelogJSON is the JSON representation of a changelog.
+type changelogJSON struct {
+	Sections []sectionJSON `json:"sections"`
+}
+
+// sectionJSON is the JSON representation of a report section.
+type sectionJSON struct {
+	Title string     `json:"title"`
+	Tasks []taskJSON `json:"tasks"`
+}
+
+// FormatChangelog formats changelog sections as JSON.
+func (f *JSONFormatter) FormatChangelog(sections []report.Section) string {
+	out := changelogJSON{Sections: make([]sectionJSON, len(sections))}
+	for i, section := range sections {
+		out.Sections[i] = sectionJSON{Title: section.Title, Tasks: make([]taskJSON, len(section.Tasks))}
+		for j, t := range section.Tasks {
+			out.Sections[i].Tasks[j] = toTaskJSON(t)
+		}
+	}
+	return f.marshal(out)
+}
+
+// contributorJSON is the JSON representation of a contributor summary.
+// Durations are in seconds.
+type contributorJSON struct {
+	Actor       string  `json:"actor"`
+	Closed      int     `json:"closed"`
+	ActiveTime  float64 `json:"active_seconds"`
+	TimeToClose float64 `json:"avg_time_to_close_seconds"`
+}
+
+// FormatContributors formats per-actor throughput as JSON.
+func (f *JSONFormatter) FormatContributors(contributors []report.Contributor) string {
+	out := make([]contributorJSON, len(contributors))
+	for i, c := range contributors {
+		out[i] = contributorJSON{
+			Actor:       c.Actor,
+			Closed:      c.Closed,
+			ActiveTime:  c.ActiveTime.Seconds(),
+			TimeToClose: c.TimeToClose.Seconds(),
+		}
+	}
+	return f.marshal(out)
+}
+
+// workEntryJSON is the JSON representation of a work log entry.
+type workEntryJSON struct {
+	TaskID   string  `json:"task_id"`
+	Title    string  `json:"title"`
+	Actor    string  `json:"actor"`
+	Start    string  `json:"start"`
+	End      string  `json:"end"`
+	Duration float64 `json:"duration_seconds"`
+}
+
+// FormatWorklog formats work log entries as JSON.
+func (f *JSONFormatter) FormatWorklog(entries []report.WorkEntry) string {
+	out := make([]workEntryJSON, len(entries))
+	for i, e := range entries {
+		out[i] = workEntryJSON{
+			TaskID:   e.TaskID,
+			Title:    e.Title,
+			Actor:    e.Actor,
+			Start:    e.Start.Format(time.RFC3339),
+			End:      e.End.Format(time.RFC3339),
+			Duration: e.End.Sub(e.Start).Seconds(),
+		}
+	}
+	return f.marshal(out)
+}
+
+// capacityJSON is the JSON representation of an assignee's backlog
+// projection. Durations are in seconds; a WIP limit of 0 means none.
+type capacityJSON struct {
+	Assignee    string  `json:"assignee"`
+	Unfinished  int     `json:"unfinished"`
+	Estimated   int     `json:"estimated"`
+	Estimate    float64 `json:"estimate_seconds"`
+	WIPLimit    int     `json:"wip_limit"`
+	Closed      int     `json:"closed"`
+	CloseRate   float64 `json:"closed_per_day"`
+	Projection  float64 `json:"projected_seconds"`
+	Unprojected int     `json:"unprojected"`
+}
+
+// FormatCapacity formats per-assignee backlog projections as JSON.
+func (f *JSONFormatter) FormatCapacity(capacities []report.Capacity) string {
+	out := make([]capacityJSON, len(capacities))
+	for i, c := range capacities {
+		out[i] = capacityJSON{
+			Assignee:    c.Assignee,
+			Unfinished:  c.Unfinished,
+			Estimated:   c.Estimated,
+			Estimate:    c.Estimate.Seconds(),
+			WIPLimit:    c.WIPLimit,
+			Closed:      c.Closed,
+			CloseRate:   c.CloseRate,
+			Projection:  c.Projection.Seconds(),
+			Unprojected: c.Unprojected,
+		}
+	}
+	return f.marshal(out)
+}
+
+// distributionJSON is the JSON representation of a duration distribution,
+// in seconds.
+type distributionJSON struct {
+	Count int     `json:"count"`
+	Mean  float64 `json:"mean_seconds"`
+	P50   float64 `json:"p50_seconds"`
+	P90   float64 `json:"p90_seconds"`
+	P95   float64 `json:"p95_seconds"`
+	Max   float64 `json:"max_seconds"`
+}
+
+func toDistributionJSON(d report.Distribution) distributionJSON {
+	return distributionJSON{
+		Count: d.Count,
+		Mean:  d.Mean.Seconds(),
+		P50:   d.P50.Seconds(),
+		P90:   d.P90.Seconds(),
+		P95:   d.P95.Seconds(),
+		Max:   d.Max.Seconds(),
+	}
+}
+
+// flowGroupJSON is the JSON representation of lead and cycle time for a group.
+type flowGroupJSON struct {
+	Group     string           `json:"group"`
+	LeadTime  distributionJSON `json:"lead_time"`
+	CycleTime distributionJSON `json:"cycle_time"`
+	SLA       *slaJSON         `json:"sla,omitempty"`
+}
+
+// slaJSON is the JSON representation of SLA attainment for a group.
+type slaJSON struct {
+	Tasks      int     `json:"tasks"`
+	Met        int     `json:"met"`
+	Attainment float64 `json:"attainment"`
+}
+
+// FormatFlow formats lead and cycle time statistics as JSON.
+func (f *JSONFormatter) FormatFlow(groups []report.FlowGroup) string {
+	out := make([]flowGroupJSON, len(groups))
+	for i, g := range groups {
+		out[i] = flowGroupJSON{
+			Group:     g.Name,
+			LeadTime:  toDistributionJSON(g.LeadTime),
+			CycleTime: toDistributionJSON(g.CycleTime),
+		}
+		if attainment, ok := g.SLAAttainment(); ok {
+			out[i].SLA = &slaJSON{Tasks: g.SLATasks, Met: g.SLAMet, Attainment: attainment}
+		}
+	}
+	return f.marshal(out)
+}
+
+// weekStatsJSON is the JSON representation of one week's counts and flow.
+type weekStatsJSON struct {
+	Closed    int              `json:"closed"`
+	Created   int              `json:"created"`
+	LeadTime  distributionJSON `json:"lead_time"`
+	CycleTime distributionJSON `json:"cycle_time"`
+}
+
+func toWeekStatsJSON(s report.WeekStats) weekStatsJSON {
+	return weekStatsJSON{
+		Closed:    s.Closed,
+		Created:   s.Created,
+		LeadTime:  toDistributionJSON(s.LeadTime),
+		CycleTime: toDistributionJSON(s.CycleTime),
+	}
+}
+
+// blockerJSON is the JSON representation of an aging blocker.
+type blockerJSON struct {
+	Task     taskJSON `json:"task"`
+	Blocking int      `json:"blocking"`
+	Age      float64  `json:"age_seconds"`
+}
+
+// weeklyJSON is the JSON representation of a weekly report.
+type weeklyJSON struct {
+	Start    string        `json:"start"`
+	End      string        `json:"end"`
+	This     weekStatsJSON `json:"this_week"`
+	Previous weekStatsJSON `json:"previous_week"`
+	Closed   []taskJSON    `json:"closed"`
+	Created  []taskJSON    `json:"created"`
+	Blockers []blockerJSON `json:"aging_blockers"`
+}
+
+// FormatWeekly formats a weekly report as JSON.
+func (f *JSONFormatter) FormatWeekly(r report.WeeklyReport) string {
+	out := weeklyJSON{
+		Start:    r.Start.Format(time.RFC3339),
+		End:      r.End.Format(time.RFC3339),
+		This:     toWeekStatsJSON(r.This),
+		Previous: toWeekStatsJSON(r.Previous),
+		Closed:   make([]taskJSON, len(r.Closed)),
+		Created:  make([]taskJSON, len(r.Created)),
+		Blockers: make([]blockerJSON, len(r.Blockers)),
+	}
+	for i, t := range r.Closed {
+		out.Closed[i] = toTaskJSON(t)
+	}
+	for i, t := range r.Created {
+		out.Created[i] = toTaskJSON(t)
+	}
+	for i, b := range r.Blockers {
+		out.Blockers[i] = blockerJSON{Task: toTaskJSON(b.Task), Blocking: b.Blocking, Age: b.Age.Seconds()}
+	}
+	return f.marshal(out)
+}
+
+// countJSON is the JSON representation of a per-priority or per-tag count.
+type countJSON struct {
+	Name  string `json:"name"`
+	Tasks int    `json:"tasks"`
+}
+
+// reasonGroupJSON is the JSON representation of a close reason group.
+type reasonGroupJSON struct {
+	Reason     string      `json:"reason"`
+	Count      int         `json:"count"`
+	TaskIDs    []string    `json:"task_ids"`
+	Priorities []countJSON `json:"priorities"`
+	Tags       []countJSON `json:"tags"`
+}
+
+func toCountsJSON(counts []report.Count) []countJSON {
+	out := make([]countJSON, len(counts))
+	for i, c := range counts {
+		out[i] = countJSON{Name: c.Name, Tasks: c.Tasks}
+	}
+	return out
+}
+
+// FormatReasons formats grouped close reasons as JSON.
+func (f *JSONFormatter) FormatReasons(groups []report.ReasonGroup) string {
+	out := make([]reasonGroupJSON, len(groups))
+	for i, g := range groups {
+		ids := make([]string, len(g.Tasks))
+		for j, t := range g.Tasks {
+			ids[j] = t.ID
+		}
+		out[i] = reasonGroupJSON{
+			Reason:     g.Reason,
+			Count:      len(g.Tasks),
+			TaskIDs:    ids,
+			Priorities: toCountsJSON(g.Priorities),
+			Tags:       toCountsJSON(g.Tags),
+		}
+	}
+	return f.marshal(out)
+}
+
+// quadrantJSON is the JSON representation of a matrix quadrant.
+type quadrantJSON struct {
+	Quadrant  string     `json:"quadrant"`
+	Urgent    bool       `json:"urgent"`
+	Important bool       `json:"important"`
+	Tasks     []taskJSON `json:"tasks"`
+}
+
+// FormatMatrix formats the urgency/importance matrix as JSON.
+func (f *JSONFormatter) FormatMatrix(quadrants []report.Quadrant) string {
+	out := make([]quadrantJSON, len(quadrants))
+	for i, q := range quadrants {
+		out[i] = quadrantJSON{Quadrant: q.Name, Urgent: q.Urgent, Important: q.Important, Tasks: []taskJSON{}}
+		for _, t := range q.Tasks {
+			out[i].Tasks = append(out[i].Tasks, toTaskJSON(t))
+		}
+	}
+	return f.marshal(out)
+}
+
+// FormatPlugins formats discovered plugins as JSON.
+func (f *JSONFormatter) FormatPlugins(plugins []plugin.Plugin) string {
+	if plugins == nil {
+		plugins = []plugin.Plugin{}
+	}
+	return f.marshal(plugins)
+}
+
+// FormatPlan formats plan changes as JSON.
+func (f *JSONFormatter) FormatPlan(changes []plan.Change) string {
+	if changes == nil {
+		changes = []plan.Change{}
+	}
+	return f.marshal(changes)
+}
+
+// FormatScan formats scan changes as JSON.
+func (f *JSONFormatter) FormatScan(changes []scan.Change) string {
+	if changes == nil {
+		changes = []scan.Change{}
+	}
+	return f.marshal(changes)
 }
 
 // errorJSON is the JSON representation of an error.
@@ -1,11 +1,41 @@
 package output
 
-import "github.com/abatilo/bits/internal/task"
+import (
+	"github.com/abatilo/bits/internal/deps"
+	"github.com/abatilo/bits/internal/plan"
+	"github.com/abatilo/bits/internal/plugin"
+	"github.com/abatilo/bits/internal/report"
+	"github.com/abatilo/bits/internal/scan"
+	"github.com/abatilo/bits/internal/storage"
+	"github.com/abatilo/bits/internal/task"
+)
 
 // Formatter defines the interface for output formatting.
 type Formatter interface {
 	FormatTask(t *task.Task) string
 	FormatTaskList(tasks []*task.Task) string
+	FormatTaskLog(t *task.Task) string
+	FormatGraph(tasks []*task.Task) string
+	FormatBlocked(blocked []deps.BlockedTask) string
+	FormatIssues(issues []storage.Issue) string
+	FormatSnapshots(snapshots []storage.Snapshot) string
+	FormatJournal(entries []storage.JournalEntry) string
+	FormatSummary(summary storage.Summary) string
+	FormatChangelog(sections []report.Section) string
+	FormatContributors(contributors []report.Contributor) string
+	FormatWorklog(entries []report.WorkEntry) string
+	FormatCapacity(capacities []report.Capacity) string
+	FormatFlow(groups []report.FlowGroup) string
+	FormatWeekly(r report.WeeklyReport) string
+	FormatReasons(groups []report.ReasonGroup) string
+	FormatMatrix(quadrants []report.Quadrant) string
+	FormatPlugins(plugins []plugin.Plugin) string
+	FormatPlan(changes []plan.Change) string
+	FormatScan(changes []scan.Change) string
 	FormatError(err error) string
 	FormatMessage(msg string) string
 }
+
+// logCreate labels a task's creation in its log, ahead of the lifecycle
+// events its history records.
+const logCreate = "create"
@@ -0,0 +1,421 @@
+//nolint:testpackage // Tests require internal access for thorough testing
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/abatilo/bits/internal/plan"
+	"github.com/abatilo/bits/internal/plugin"
+	"github.com/abatilo/bits/internal/report"
+	"github.com/abatilo/bits/internal/scan"
+	"github.com/abatilo/bits/internal/task"
+)
+
+func TestRelativeDuration(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{-time.Minute, "0s"},
+		{45 * time.Second, "45s"},
+		{3 * time.Minute, "3m"},
+		{2*time.Hour + 59*time.Minute, "2h"},
+		{3 * 24 * time.Hour, "3d"},
+		{65 * 24 * time.Hour, "2mo"},
+		{400 * 24 * time.Hour, "1y"},
+	}
+
+	for _, tt := range tests {
+		if got := RelativeDuration(tt.d); got != tt.want {
+			t.Errorf("RelativeDuration(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
+
+func TestHumanFormatterAges(t *testing.T) {
+	now := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+	claimed := now.Add(-2 * time.Hour)
+	closed := now.Add(-5 * time.Hour)
+	reason := "done"
+
+	tasks := []*task.Task{
+		{ID: "open01", Title: "Open", Status: task.StatusOpen, Priority: task.PriorityMedium,
+			CreatedAt: now.Add(-3 * 24 * time.Hour)},
+		{ID: "act001", Title: "Active", Status: task.StatusActive, Priority: task.PriorityHigh,
+			CreatedAt: now.Add(-24 * time.Hour), ClaimedAt: &claimed},
+		{ID: "done01", Title: "Closed", Status: task.StatusClosed, Priority: task.PriorityLow,
+			CreatedAt: now.Add(-24 * time.Hour), ClosedAt: &closed, CloseReason: &reason},
+	}
+
+	f := NewHumanFormatter(HumanOptions{Now: func() time.Time { return now }})
+	list := f.FormatTaskList(tasks)
+	for _, want := range []string{"Open (3d ago)", "Active (active 2h)", "Closed (closed 5h ago)"} {
+		if !strings.Contains(list, want) {
+			t.Errorf("FormatTaskList missing %q:\n%s", want, list)
+		}
+	}
+
+	show := f.FormatTask(tasks[1])
+	if !strings.Contains(show, "Claimed:  active for 2h (2024-01-15 10:00)") {
+		t.Errorf("FormatTask missing active duration:\n%s", show)
+	}
+
+	abs := NewHumanFormatter(HumanOptions{Absolute: true, Now: func() time.Time { return now }})
+	if list = abs.FormatTaskList(tasks); strings.Contains(list, "ago") || strings.Contains(list, "active 2h") {
+		t.Errorf("absolute FormatTaskList should not show ages:\n%s", list)
+	}
+	if show = abs.FormatTask(tasks[2]); !strings.Contains(show, "Closed:   2024-01-15 07:00\n") {
+		t.Errorf("absolute FormatTask should show closed timestamp:\n%s", show)
+	}
+}
+
+func TestHumanFormatterDue(t *testing.T) {
+	now := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+	past, future := now.Add(-2*24*time.Hour), now.Add(3*24*time.Hour)
+	overdue := &task.Task{ID: "late01", Title: "Late", Status: task.StatusOpen, CreatedAt: now, DueAt: &past}
+	upcoming := &task.Task{ID: "soon01", Title: "Soon", Status: task.StatusOpen, CreatedAt: now, DueAt: &future}
+
+	f := NewHumanFormatter(HumanOptions{Now: func() time.Time { return now }})
+	list := f.FormatTaskList([]*task.Task{overdue, upcoming})
+	for _, want := range []string{"Late (0s ago) (overdue 2d)", "Soon (0s ago) (due in 3d)"} {
+		if !strings.Contains(list, want) {
+			t.Errorf("FormatTaskList missing %q:\n%s", want, list)
+		}
+	}
+	if show := f.FormatTask(overdue); !strings.Contains(show, "Due:      overdue by 2d (2024-01-13 12:00)") {
+		t.Errorf("FormatTask missing due date:\n%s", show)
+	}
+}
+
+func TestHumanFormatterGlyphs(t *testing.T) {
+	tk := &task.Task{ID: "abc123", Title: "Fix", Status: task.StatusClosed, Priority: task.PriorityCritical}
+	opts := HumanOptions{
+		Absolute:       true,
+		StatusGlyphs:   map[task.Status]string{task.StatusClosed: "✅"},
+		PriorityGlyphs: map[task.Priority]string{task.PriorityCritical: "!!", task.PriorityLow: ""},
+	}
+
+	if got := NewHumanFormatter(opts).FormatTaskList([]*task.Task{tk}); got != "✅ !! [abc123] Fix\n" {
+		t.Errorf("FormatTaskList = %q, want glyph overrides", got)
+	}
+
+	opts.ASCIIOnly = true
+	if got := NewHumanFormatter(opts).FormatTaskList([]*task.Task{tk}); got != "[X] !! [abc123] Fix\n" {
+		t.Errorf("FormatTaskList = %q, want ASCII fallback for non-ASCII glyph", got)
+	}
+}
+
+func TestUnicodeSupported(t *testing.T) {
+	t.Setenv("LC_ALL", "")
+	t.Setenv("LC_CTYPE", "")
+	t.Setenv("LANG", "en_US.UTF-8")
+	if !UnicodeSupported() {
+		t.Error("UnicodeSupported() = false for en_US.UTF-8")
+	}
+
+	t.Setenv("LC_ALL", "C")
+	if UnicodeSupported() {
+		t.Error("UnicodeSupported() = true for LC_ALL=C")
+	}
+}
+
+func TestYAMLFormatter(t *testing.T) {
+	created := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
+	tk := &task.Task{
+		ID:        "abc123",
+		Title:     "Fix: login",
+		Status:    task.StatusOpen,
+		Priority:  task.PriorityHigh,
+		CreatedAt: created,
+		DependsOn: []string{"xyz789"},
+	}
+
+	want := `id: abc123
+title: 'Fix: login'
+status: open
+priority: high
+created_at: "2024-01-15T10:30:00Z"
+depends_on:
+    - xyz789
+`
+	if got := NewYAMLFormatter().FormatTask(tk); got != want {
+		t.Errorf("FormatTask =\n%s\nwant\n%s", got, want)
+	}
+	if got := NewYAMLFormatter().FormatTaskList(nil); got != "[]\n" {
+		t.Errorf("FormatTaskList(nil) = %q, want %q", got, "[]\n")
+	}
+}
+
+func TestHumanFormatterWidth(t *testing.T) {
+	tk := &task.Task{
+		ID:        "abc123",
+		Title:     "Refactor the storage layer to stream task files",
+		Status:    task.StatusOpen,
+		Priority:  task.PriorityMedium,
+		DependsOn: []string{"dep001", "dep002", "dep003"},
+	}
+
+	f := NewHumanFormatter(HumanOptions{Absolute: true, Width: 50})
+	want := "[ ] P2 [abc123] Refactor the stora… [blocked by 3]\n"
+	if got := f.FormatTaskList([]*task.Task{tk}); got != want {
+		t.Errorf("FormatTaskList = %q, want %q", got, want)
+	}
+
+	wide := NewHumanFormatter(HumanOptions{Absolute: true})
+	full := tk.Title + " [blocked by: dep001, dep002, dep003]"
+	if got := wide.FormatTaskList([]*task.Task{tk}); !strings.Contains(got, full) {
+		t.Errorf("FormatTaskList without width = %q, want full line", got)
+	}
+}
+
+func TestWrap(t *testing.T) {
+	text := "one two three four five\n    indented line that is long\nshort"
+	want := "one two\nthree four\nfive\n    indented line that is long\nshort"
+	if got := wrap(text, 10); got != want {
+		t.Errorf("wrap = %q, want %q", got, want)
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	if got := truncate("hello world", 8, "..."); got != "hello..." {
+		t.Errorf("truncate = %q, want %q", got, "hello...")
+	}
+	if got := truncate("short", 8, "..."); got != "short" {
+		t.Errorf("truncate = %q, want %q", got, "short")
+	}
+}
+
+func TestFormatWorklogCSV(t *testing.T) {
+	start := time.Date(2024, 1, 15, 9, 0, 0, 0, time.UTC)
+	entries := []report.WorkEntry{
+		{TaskID: "abc", Title: "Fix login, again", Actor: "user:alice", Start: start, End: start.Add(90 * time.Minute)},
+	}
+
+	want := "task_id,title,actor,start,end,duration_seconds\n" +
+		"abc,\"Fix login, again\",user:alice,2024-01-15T09:00:00Z,2024-01-15T10:30:00Z,5400\n"
+	if got := FormatWorklogCSV(entries); got != want {
+		t.Errorf("FormatWorklogCSV =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestFormatPlugins(t *testing.T) {
+	plugins := []plugin.Plugin{{Name: "import-jira", Path: "/usr/local/bin/bits-import-jira"}}
+
+	human := NewHumanFormatter(HumanOptions{}).FormatPlugins(plugins)
+	want := "COMMAND      PATH\nimport-jira  /usr/local/bin/bits-import-jira\n"
+	if human != want {
+		t.Errorf("FormatPlugins =\n%s\nwant\n%s", human, want)
+	}
+	if got := NewJSONFormatter().FormatPlugins(nil); got != "[]\n" {
+		t.Errorf("JSON FormatPlugins(nil) = %q, want []", got)
+	}
+}
+
+func TestFormatTaskLog(t *testing.T) {
+	created := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
+	tk := &task.Task{ID: "abc", Title: "Fix login", CreatedAt: created, History: []task.Event{
+		{At: created.Add(time.Hour), Action: task.EventClaim, Actor: "session:s1", Note: "override: wip"},
+		{At: created.Add(2 * time.Hour), Action: task.EventClose},
+	}}
+
+	human := NewHumanFormatter(HumanOptions{}).FormatTaskLog(tk)
+	want := "[abc] Fix login\n" +
+		"  2024-01-15 10:30  create\n" +
+		"  2024-01-15 11:30  claim    by session:s1 (override: wip)\n" +
+		"  2024-01-15 12:30  close\n"
+	if human != want {
+		t.Errorf("FormatTaskLog =\n%s\nwant\n%s", human, want)
+	}
+	if got := NewJSONFormatter().FormatTaskLog(tk); !strings.Contains(got, `"action": "create"`) {
+		t.Errorf("JSON FormatTaskLog = %s, want a create event first", got)
+	}
+}
+
+func TestFormatGraph(t *testing.T) {
+	tasks := []*task.Task{
+		{ID: "a", Title: "Schema", Status: task.StatusActive, Links: []task.Link{{ID: "b", Type: task.LinkRelatesTo}}},
+		{ID: "b", Title: `Backend "api"`, Status: task.StatusOpen, DependsOn: []string{"a"}},
+		{ID: "end", Title: "Ship", Status: task.StatusOpen, DependsOn: []string{"b", "a"},
+			DependsAny: [][]string{{"https://github.com/o/r/issues/1"}}},
+	}
+
+	human := NewHumanFormatter(HumanOptions{}).FormatGraph(tasks)
+	want := "[ ] [end] Ship\n" +
+		"  [ ] [b] Backend \"api\"\n" +
+		"    [*] [a] Schema\n" +
+		"      [ ] [b] Backend \"api\" (relates-to) (see above)\n" +
+		"  [*] [a] Schema (see above)\n" +
+		"  https://github.com/o/r/issues/1 (any of)\n"
+	if human != want {
+		t.Errorf("FormatGraph =\n%s\nwant\n%s", human, want)
+	}
+
+	mermaid := FormatMermaid(tasks)
+	for _, line := range []string{
+		"flowchart TD\n",
+		`    b["[b] Backend #quot;api#quot;"]` + "\n",
+		`    ref3["[end] Ship"]` + "\n",
+		"    b --> ref3\n",
+		`    ref4["https://github.com/o/r/issues/1"]` + "\n    ref4 -.-> ref3\n",
+		"    class a active\n",
+		"    a -.-|relates-to| b\n",
+	} {
+		if !strings.Contains(mermaid, line) {
+			t.Errorf("FormatMermaid =\n%s\nwant it to contain %q", mermaid, line)
+		}
+	}
+}
+
+func TestFormatPlan(t *testing.T) {
+	changes := []plan.Change{
+		{Kind: plan.KindCreate, Name: "ui", Title: "Build the UI"},
+		{Kind: plan.KindUpdate, Name: "api", ID: "a1", Title: "Build the API", Fields: []plan.FieldChange{
+			{Field: "depends_on", From: "", To: "db"},
+		}},
+		{Kind: plan.KindClose, Name: "old", ID: "o1", Title: "Old", Reason: plan.ReasonRemoved},
+	}
+
+	human := NewHumanFormatter(HumanOptions{}).FormatPlan(changes)
+	want := "+ ui Build the UI\n" +
+		"~ api [a1] Build the API\n" +
+		"    depends_on: (none) -> db\n" +
+		"- old [o1] Old (removed from plan)\n" +
+		"1 to create, 1 to update, 1 to close.\n"
+	if human != want {
+		t.Errorf("FormatPlan =\n%s\nwant\n%s", human, want)
+	}
+	if got := NewJSONFormatter().FormatPlan(nil); got != "[]\n" {
+		t.Errorf("JSON FormatPlan(nil) = %q, want []", got)
+	}
+}
+
+func TestFormatScan(t *testing.T) {
+	changes := []scan.Change{
+		{Kind: scan.KindCreate, ID: "n1", Title: "Retry uploads", File: "up.go", Line: 3},
+		{Kind: scan.KindMove, ID: "m1", Title: "Handle empty", File: "cfg.go", Line: 9, FromLine: 7},
+		{Kind: scan.KindClose, ID: "o1", Title: "Old", File: "old.go", Line: 1},
+	}
+
+	human := NewHumanFormatter(HumanOptions{}).FormatScan(changes)
+	want := "+ [n1] Retry uploads (up.go:3)\n" +
+		"~ [m1] Handle empty (cfg.go:7 -> 9)\n" +
+		"- [o1] Old (old.go:1, comment removed)\n" +
+		"1 to create, 1 to move, 1 to close.\n"
+	if human != want {
+		t.Errorf("FormatScan =\n%s\nwant\n%s", human, want)
+	}
+	if got := NewJSONFormatter().FormatScan(nil); got != "[]\n" {
+		t.Errorf("JSON FormatScan(nil) = %q, want []", got)
+	}
+}
+
+func TestProjection(t *testing.T) {
+	tasks := []*task.Task{
+		{ID: "a1", Title: "First", Status: task.StatusOpen, Priority: task.PriorityHigh},
+		{ID: "b2", Title: "Second", Status: task.StatusActive, Priority: task.PriorityLow},
+	}
+	tests := []struct {
+		query  string
+		fields []string
+		want   string
+	}{
+		{"", []string{"title", "id"}, `[{"title":"First","id":"a1"},{"title":"Second","id":"b2"}]`},
+		{".[].id", nil, `["a1","b2"]`},
+		{".[-1]", []string{"status", "missing"}, `{"status":"active"}`},
+		{".[5].id", nil, `null`},
+		{".[0].id.deeper", nil, `null`},
+		{".[]", []string{"priority"}, `[{"priority":"high"},{"priority":"low"}]`},
+	}
+	for _, tt := range tests {
+		p, err := NewProjection(tt.query, tt.fields)
+		if err != nil {
+			t.Fatalf("NewProjection(%q) failed: %v", tt.query, err)
+		}
+		var got bytes.Buffer
+		if err = json.Compact(&got, []byte(NewJSONFormatter().Project(p).FormatTaskList(tasks))); err != nil {
+			t.Fatalf("query %q produced invalid JSON: %v", tt.query, err)
+		}
+		if got.String() != tt.want {
+			t.Errorf("query %q fields %v = %s, want %s", tt.query, tt.fields, got.String(), tt.want)
+		}
+	}
+
+	for _, query := range []string{"id", ".[1", ".[x]", ".a..b"} {
+		if _, err := NewProjection(query, nil); err == nil {
+			t.Errorf("NewProjection(%q) succeeded, want error", query)
+		}
+	}
+	p, _ := NewProjection(".id", nil)
+	if got := NewJSONFormatter().Project(p).FormatError(errors.New("boom")); !strings.Contains(got, "boom") {
+		t.Errorf("FormatError was projected: %q", got)
+	}
+}
+
+func TestTasksCSVRoundTrip(t *testing.T) {
+	created := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
+	closed := created.Add(time.Hour)
+	reason := "done, finally"
+	want := []*task.Task{
+		{ID: "abc", Title: "Open", Status: task.StatusOpen, Priority: task.PriorityLow, CreatedAt: created,
+			DependsOn: []string{"def", "ghi"}, Tags: []string{"bug", "ui"}, Description: "Two\nlines"},
+		{ID: "def", Title: "Closed", Status: task.StatusClosed, Priority: task.PriorityHigh, CreatedAt: created,
+			ClosedAt: &closed, CloseReason: &reason, Parent: "abc"},
+	}
+
+	got, err := ParseTasksCSV(strings.NewReader(FormatTasksCSV(want)))
+	if err != nil {
+		t.Fatalf("ParseTasksCSV failed: %v", err)
+	}
+	f := NewJSONFormatter()
+	if f.FormatTaskList(got) != f.FormatTaskList(want) {
+		t.Errorf("CSV round trip =\n%s\nwant\n%s", f.FormatTaskList(got), f.FormatTaskList(want))
+	}
+
+	// Other tools' files need only a title, in any column order
+	got, err = ParseTasksCSV(strings.NewReader("Extra,Title\nx,Plan it\n"))
+	if err != nil || len(got) != 1 || got[0].Title != "Plan it" || got[0].ID != "" {
+		t.Errorf("ParseTasksCSV(title only) = %v, %v; want one task without an ID", got, err)
+	}
+	if _, err = ParseTasksCSV(strings.NewReader("id,name\nabc,x\n")); err == nil {
+		t.Error("ParseTasksCSV without a title column succeeded, want error")
+	}
+}
+
+func TestParseTaskRoundTrip(t *testing.T) {
+	created := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
+	closed := created.Add(time.Hour)
+	reason := "done"
+	want := &task.Task{
+		ID:          "abc",
+		Title:       "Round trip",
+		Status:      task.StatusClosed,
+		Priority:    task.PriorityHigh,
+		CreatedAt:   created,
+		ClosedAt:    &closed,
+		CloseReason: &reason,
+		DependsOn:   []string{"def"},
+		After:       []task.Hint{{ID: "ghi", Weight: 3}},
+		Acceptance:  []task.Criterion{{Text: "tests", Done: true}},
+		History:     []task.Event{{At: closed, Action: task.EventClose, Actor: "user:alice", Note: "verified"}},
+		Tags:        []string{"bug"},
+	}
+
+	f := NewJSONFormatter()
+	got, err := ParseTask([]byte(f.FormatTask(want)))
+	if err != nil {
+		t.Fatalf("ParseTask failed: %v", err)
+	}
+	if f.FormatTask(got) != f.FormatTask(want) {
+		t.Errorf("ParseTask round trip =\n%s\nwant\n%s", f.FormatTask(got), f.FormatTask(want))
+	}
+
+	list, err := ParseTaskList([]byte(f.FormatTaskList([]*task.Task{want, want})))
+	if err != nil || len(list) != 2 {
+		t.Errorf("ParseTaskList = %d tasks, %v; want 2", len(list), err)
+	}
+}
@@ -0,0 +1,257 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+// Projection narrows JSON output to the parts a caller asked for, like a tiny
+// jq, so agents can request just the fields they need.
+type Projection struct {
+	fields []string
+	steps  []step
+}
+
+// step is one segment of a query path.
+type step struct {
+	key   string // .key
+	index int    // [n]; negative counts from the end
+	kind  stepKind
+}
+
+type stepKind int
+
+const (
+	stepKey stepKind = iota
+	stepIndex
+	stepIterate // []
+)
+
+// NewProjection parses a query path and a field list. The query selects part
+// of the document: ".tasks", ".[0]", ".[-1].history", ".[].id" ("[]" maps
+// over an array, collecting the results into one). fields then keeps only
+// those keys, in that order, of the selected object or of each object in the
+// selected array. Either may be empty.
+func NewProjection(query string, fields []string) (*Projection, error) {
+	p := &Projection{}
+	for _, field := range fields {
+		if field = strings.TrimSpace(field); field != "" {
+			p.fields = append(p.fields, field)
+		}
+	}
+
+	rest := strings.TrimSpace(query)
+	if rest == "" || rest == "." {
+		return p, nil
+	}
+	if !strings.HasPrefix(rest, ".") {
+		return nil, InvalidQueryError{Query: query, Reason: "must start with ."}
+	}
+	for rest != "" {
+		switch {
+		case strings.HasPrefix(rest, "[]"):
+			p.steps = append(p.steps, step{kind: stepIterate})
+			rest = rest[2:]
+		case strings.HasPrefix(rest, "["):
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, InvalidQueryError{Query: query, Reason: "unclosed ["}
+			}
+			n, err := strconv.Atoi(rest[1:end])
+			if err != nil {
+				return nil, InvalidQueryError{Query: query, Reason: "index " + strconv.Quote(rest[1:end]) + " is not a number"}
+			}
+			p.steps = append(p.steps, step{kind: stepIndex, index: n})
+			rest = rest[end+1:]
+		case strings.HasPrefix(rest, "."):
+			rest = rest[1:]
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			if end > 0 {
+				p.steps = append(p.steps, step{kind: stepKey, key: rest[:end]})
+			} else if len(p.steps) > 0 || rest == "" || rest[0] != '[' {
+				return nil, InvalidQueryError{Query: query, Reason: "empty key"}
+			}
+			rest = rest[end:]
+		default:
+			return nil, InvalidQueryError{Query: query, Reason: "unexpected " + strconv.Quote(rest)}
+		}
+	}
+	return p, nil
+}
+
+// Empty reports whether the projection leaves documents unchanged.
+func (p *Projection) Empty() bool {
+	return p == nil || (len(p.fields) == 0 && len(p.steps) == 0)
+}
+
+// Apply projects a JSON document, returning it re-encoded.
+func (p *Projection) Apply(doc []byte) ([]byte, error) {
+	if p.Empty() {
+		return doc, nil
+	}
+	root, err := decodeOrdered(json.NewDecoder(bytes.NewReader(doc)))
+	if err != nil {
+		return nil, err
+	}
+
+	values, iterated := []any{root}, false
+	for _, s := range p.steps {
+		var next []any
+		for _, v := range values {
+			switch s.kind {
+			case stepKey:
+				obj, _ := v.(object) // keys of non-objects are null
+				next = append(next, obj.get(s.key))
+			case stepIndex:
+				next = append(next, index(v, s.index))
+			case stepIterate:
+				if arr, ok := v.([]any); ok {
+					next = append(next, arr...)
+				}
+			}
+		}
+		values = next
+		iterated = iterated || s.kind == stepIterate
+	}
+
+	var result any
+	if iterated {
+		result = values
+		if values == nil {
+			result = []any{}
+		}
+	} else {
+		result = values[0]
+	}
+	return json.MarshalIndent(p.pick(result), "", "  ")
+}
+
+// pick keeps the projection's fields of an object or of each object in an
+// array.
+func (p *Projection) pick(v any) any {
+	if len(p.fields) == 0 {
+		return v
+	}
+	switch v := v.(type) {
+	case object:
+		picked := object{}
+		for _, field := range p.fields {
+			if value, ok := v.lookup(field); ok {
+				picked = append(picked, member{key: field, value: value})
+			}
+		}
+		return picked
+	case []any:
+		out := make([]any, len(v))
+		for i, item := range v {
+			out[i] = p.pick(item)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+// index returns element n of an array, counting from the end if negative.
+func index(v any, n int) any {
+	arr, ok := v.([]any)
+	if !ok {
+		return nil
+	}
+	if n < 0 {
+		n += len(arr)
+	}
+	if n < 0 || n >= len(arr) {
+		return nil
+	}
+	return arr[n]
+}
+
+// object is a JSON object that keeps its key order.
+type object []member
+
+type member struct {
+	key   string
+	value any
+}
+
+// lookup returns the value of key.
+func (o object) lookup(key string) (any, bool) {
+	for _, m := range o {
+		if m.key == key {
+			return m.value, true
+		}
+	}
+	return nil, false
+}
+
+// get returns the value of key, or nil.
+func (o object) get(key string) any {
+	v, _ := o.lookup(key)
+	return v
+}
+
+// MarshalJSON encodes the object with its keys in order.
+func (o object) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, m := range o {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, _ := json.Marshal(m.key)
+		value, err := json.Marshal(m.value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// decodeOrdered decodes the next JSON value, keeping object key order.
+func decodeOrdered(dec *json.Decoder) (any, error) {
+	dec.UseNumber()
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch tok {
+	case json.Delim('{'):
+		obj := object{}
+		for dec.More() {
+			keyTok, keyErr := dec.Token()
+			if keyErr != nil {
+				return nil, keyErr
+			}
+			value, valueErr := decodeOrdered(dec)
+			if valueErr != nil {
+				return nil, valueErr
+			}
+			obj = append(obj, member{key: keyTok.(string), value: value}) //nolint:forcetypeassert // keys are strings
+		}
+		_, err = dec.Token() // }
+		return obj, err
+	case json.Delim('['):
+		arr := []any{}
+		for dec.More() {
+			value, valueErr := decodeOrdered(dec)
+			if valueErr != nil {
+				return nil, valueErr
+			}
+			arr = append(arr, value)
+		}
+		_, err = dec.Token() // ]
+		return arr, err
+	default:
+		return tok, nil
+	}
+}
@@ -0,0 +1,65 @@
+package output
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	ellipsis      = "…"
+	asciiEllipsis = "..."
+	minTitleWidth = 10 // Titles are never truncated below this many characters
+)
+
+// TerminalWidth returns the width of the terminal attached to stdout. COLUMNS
+// takes precedence when set. It returns 0 when stdout is not a terminal, which
+// disables width-aware formatting.
+func TerminalWidth() int {
+	if columns, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && columns > 0 {
+		return columns
+	}
+	return stdoutWidth()
+}
+
+// truncate shortens s to at most width characters, ending it with marker.
+func truncate(s string, width int, marker string) string {
+	if utf8.RuneCountInString(s) <= width {
+		return s
+	}
+	keep := width - utf8.RuneCountInString(marker)
+	if keep <= 0 {
+		return marker
+	}
+	runes := []rune(s)
+	return strings.TrimRight(string(runes[:keep]), " ") + marker
+}
+
+// wrap word-wraps text to width characters. Indented lines (code, lists
+// continuation) and lines without room to break are left as they are.
+func wrap(text string, width int) string {
+	lines := strings.Split(text, "\n")
+	wrapped := make([]string, 0, len(lines))
+	for _, line := range lines {
+		if utf8.RuneCountInString(line) <= width || strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t") {
+			wrapped = append(wrapped, line)
+			continue
+		}
+
+		current := ""
+		for _, word := range strings.Fields(line) {
+			switch {
+			case current == "":
+				current = word
+			case utf8.RuneCountInString(current)+1+utf8.RuneCountInString(word) > width:
+				wrapped = append(wrapped, current)
+				current = word
+			default:
+				current += " " + word
+			}
+		}
+		wrapped = append(wrapped, current)
+	}
+	return strings.Join(wrapped, "\n")
+}
@@ -0,0 +1,9 @@
+//go:build !unix
+
+package output
+
+// stdoutWidth returns 0 on platforms without terminal size detection, which
+// disables truncation unless COLUMNS is set.
+func stdoutWidth() int {
+	return 0
+}
@@ -0,0 +1,18 @@
+//go:build unix
+
+package output
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// stdoutWidth returns the column count of the terminal on stdout, or 0.
+func stdoutWidth() int {
+	ws, err := unix.IoctlGetWinsize(int(os.Stdout.Fd()), unix.TIOCGWINSZ) //nolint:gosec // G115: file descriptors fit in an int
+	if err != nil {
+		return 0
+	}
+	return int(ws.Col)
+}
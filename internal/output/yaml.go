@@ -0,0 +1,164 @@
+package output
+
+import (
+	"gopkg.in/yaml.v3"
+
+	"github.com/abatilo/bits/internal/deps"
+	"github.com/abatilo/bits/internal/plan"
+	"github.com/abatilo/bits/internal/plugin"
+	"github.com/abatilo/bits/internal/report"
+	"github.com/abatilo/bits/internal/scan"
+	"github.com/abatilo/bits/internal/storage"
+	"github.com/abatilo/bits/internal/task"
+)
+
+// YAMLFormatter formats output as YAML. Documents have the same fields, in the
+// same order, as the JSON output.
+type YAMLFormatter struct {
+	json *JSONFormatter
+}
+
+// NewYAMLFormatter creates a new YAMLFormatter.
+func NewYAMLFormatter() *YAMLFormatter {
+	return &YAMLFormatter{json: NewJSONFormatter()}
+}
+
+// Project narrows every document except errors and messages with p.
+func (f *YAMLFormatter) Project(p *Projection) *YAMLFormatter {
+	f.json.Project(p)
+	return f
+}
+
+// jsonToYAML re-encodes a JSON document as block-style YAML. YAML is a
+// superset of JSON, so decoding into a node tree keeps the key order.
+func jsonToYAML(doc string) string {
+	var node yaml.Node
+	if err := yaml.Unmarshal([]byte(doc), &node); err != nil {
+		return doc
+	}
+	clearStyle(&node)
+	data, err := yaml.Marshal(&node)
+	if err != nil {
+		return doc
+	}
+	return string(data)
+}
+
+// clearStyle resets the flow and quoting styles inherited from JSON so the
+// encoder picks idiomatic YAML styles.
+func clearStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		clearStyle(child)
+	}
+}
+
+// FormatTask formats a single task as YAML.
+func (f *YAMLFormatter) FormatTask(t *task.Task) string {
+	return jsonToYAML(f.json.FormatTask(t))
+}
+
+// FormatTaskList formats a list of tasks as YAML.
+func (f *YAMLFormatter) FormatTaskList(tasks []*task.Task) string {
+	return jsonToYAML(f.json.FormatTaskList(tasks))
+}
+
+// FormatIssues formats store integrity issues as YAML.
+func (f *YAMLFormatter) FormatIssues(issues []storage.Issue) string {
+	return jsonToYAML(f.json.FormatIssues(issues))
+}
+
+// FormatJournal formats journal entries as YAML.
+func (f *YAMLFormatter) FormatJournal(entries []storage.JournalEntry) string {
+	return jsonToYAML(f.json.FormatJournal(entries))
+}
+
+// FormatTaskLog formats a task's creation and lifecycle events as YAML.
+func (f *YAMLFormatter) FormatTaskLog(t *task.Task) string {
+	return jsonToYAML(f.json.FormatTaskLog(t))
+}
+
+// FormatGraph formats the dependency graph of tasks as YAML.
+func (f *YAMLFormatter) FormatGraph(tasks []*task.Task) string {
+	return jsonToYAML(f.json.FormatGraph(tasks))
+}
+
+// FormatBlocked formats blocked tasks and their blockers as YAML.
+func (f *YAMLFormatter) FormatBlocked(blocked []deps.BlockedTask) string {
+	return jsonToYAML(f.json.FormatBlocked(blocked))
+}
+
+// FormatSnapshots formats a list of snapshots as YAML.
+func (f *YAMLFormatter) FormatSnapshots(snapshots []storage.Snapshot) string {
+	return jsonToYAML(f.json.FormatSnapshots(snapshots))
+}
+
+// FormatSummary formats task counts as YAML.
+func (f *YAMLFormatter) FormatSummary(summary storage.Summary) string {
+	return jsonToYAML(f.json.FormatSummary(summary))
+}
+
+// FormatChangelog formats changelog sections as YAML.
+func (f *YAMLFormatter) FormatChangelog(sections []report.Section) string {
+	return jsonToYAML(f.json.FormatChangelog(sections))
+}
+
+// FormatWorklog formats work log entries as YAML.
+func (f *YAMLFormatter) FormatWorklog(entries []report.WorkEntry) string {
+	return jsonToYAML(f.json.FormatWorklog(entries))
+}
+
+// FormatCapacity formats per-assignee backlog projections as YAML.
+func (f *YAMLFormatter) FormatCapacity(capacities []report.Capacity) string {
+	return jsonToYAML(f.json.FormatCapacity(capacities))
+}
+
+// FormatFlow formats lead and cycle time statistics as YAML.
+func (f *YAMLFormatter) FormatFlow(groups []report.FlowGroup) string {
+	return jsonToYAML(f.json.FormatFlow(groups))
+}
+
+// FormatWeekly formats a weekly report as YAML.
+func (f *YAMLFormatter) FormatWeekly(r report.WeeklyReport) string {
+	return jsonToYAML(f.json.FormatWeekly(r))
+}
+
+// FormatReasons formats grouped close reasons as YAML.
+func (f *YAMLFormatter) FormatReasons(groups []report.ReasonGroup) string {
+	return jsonToYAML(f.json.FormatReasons(groups))
+}
+
+// FormatMatrix formats the urgency/importance matrix as YAML.
+func (f *YAMLFormatter) FormatMatrix(quadrants []report.Quadrant) string {
+	return jsonToYAML(f.json.FormatMatrix(quadrants))
+}
+
+// FormatPlan formats plan changes as YAML.
+func (f *YAMLFormatter) FormatPlan(changes []plan.Change) string {
+	return jsonToYAML(f.json.FormatPlan(changes))
+}
+
+// FormatScan formats scan changes as YAML.
+func (f *YAMLFormatter) FormatScan(changes []scan.Change) string {
+	return jsonToYAML(f.json.FormatScan(changes))
+}
+
+// FormatPlugins formats discovered plugins as YAML.
+func (f *YAMLFormatter) FormatPlugins(plugins []plugin.Plugin) string {
+	return jsonToYAML(f.json.FormatPlugins(plugins))
+}
+
+// FormatContributors formats per-actor throughput as YAML.
+func (f *YAMLFormatter) FormatContributors(contributors []report.Contributor) string {
+	return jsonToYAML(f.json.FormatContributors(contributors))
+}
+
+// FormatError formats an error as YAML.
+func (f *YAMLFormatter) FormatError(err error) string {
+	return jsonToYAML(f.json.FormatError(err))
+}
+
+// FormatMessage formats a simple message as YAML.
+func (f *YAMLFormatter) FormatMessage(msg string) string {
+	return jsonToYAML(f.json.FormatMessage(msg))
+}
@@ -0,0 +1,143 @@
+package plan
+
+import (
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/abatilo/bits/internal/task"
+)
+
+// Kind is what a change does to a task.
+type Kind string
+
+const (
+	KindCreate Kind = "create"
+	KindUpdate Kind = "update"
+	KindClose  Kind = "close"
+)
+
+// Close reasons recorded for tasks closed by apply.
+const (
+	ReasonClosed  = "Closed in plan"
+	ReasonRemoved = "Removed from plan"
+)
+
+// Change is one step toward converging a store on a plan.
+type Change struct {
+	Kind   Kind          `json:"kind"`
+	Name   string        `json:"name"`
+	ID     string        `json:"id,omitempty"` // Empty for tasks not yet created
+	Title  string        `json:"title"`
+	Fields []FieldChange `json:"fields,omitempty"` // Planned fields that differ
+	Reason string        `json:"reason,omitempty"` // Close reason
+}
+
+// FieldChange is a planned field whose value differs from the task's.
+type FieldChange struct {
+	Field string `json:"field"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+}
+
+// Keys maps the key of every keyed task to its ID.
+func Keys(tasks []*task.Task) map[string]string {
+	ids := make(map[string]string)
+	for _, t := range tasks {
+		if t.Key != "" {
+			ids[t.Key] = t.ID
+		}
+	}
+	return ids
+}
+
+// Diff returns the changes that converge tasks on p, in plan order followed by
+// tasks removed from the plan:
+//   - a create for each plan task with no task of that key, unless it is
+//     marked closed;
+//   - an update listing the planned fields that differ; a closed task is
+//     updated but never reopened;
+//   - a close for each unfinished task marked closed in the plan (with any
+//     field updates), or whose key is no longer in the plan.
+//
+// Tasks without a key are not managed by plans and are never changed.
+func Diff(p *Plan, tasks []*task.Task) []Change {
+	byKey := make(map[string]*task.Task)
+	labels := make(map[string]string) // Task ID -> key, for showing dependencies
+	for _, t := range tasks {
+		if t.Key != "" {
+			byKey[t.Key] = t
+			labels[t.ID] = t.Key
+		}
+	}
+	ids := Keys(tasks)
+	for _, pt := range p.Tasks {
+		if _, ok := byKey[pt.Name]; !ok && !pt.Closed {
+			ids[pt.Name] = pt.Name // Created by apply; shown by name until then
+		}
+	}
+
+	var changes []Change
+	for i := range p.Tasks {
+		pt := &p.Tasks[i]
+		t, ok := byKey[pt.Name]
+		if !ok {
+			if !pt.Closed {
+				changes = append(changes, Change{Kind: KindCreate, Name: pt.Name, Title: pt.Title})
+			}
+			continue
+		}
+
+		want := *t
+		pt.Apply(&want, ids)
+		c := Change{Kind: KindUpdate, Name: pt.Name, ID: t.ID, Title: pt.Title, Fields: fieldChanges(t, &want, labels)}
+		if pt.Closed && t.Status != task.StatusClosed {
+			c.Kind, c.Reason = KindClose, ReasonClosed
+		}
+		if c.Kind == KindClose || len(c.Fields) > 0 {
+			changes = append(changes, c)
+		}
+	}
+
+	var removed []*task.Task
+	for key, t := range byKey {
+		if _, ok := p.Find(key); !ok && t.Status != task.StatusClosed {
+			removed = append(removed, t)
+		}
+	}
+	sort.Slice(removed, func(i, j int) bool { return removed[i].Key < removed[j].Key })
+	for _, t := range removed {
+		changes = append(changes, Change{Kind: KindClose, Name: t.Key, ID: t.ID, Title: t.Title, Reason: ReasonRemoved})
+	}
+	return changes
+}
+
+// fieldChanges lists the planned fields of want that differ from have.
+func fieldChanges(have, want *task.Task, labels map[string]string) []FieldChange {
+	var fields []FieldChange
+	add := func(field, from, to string) {
+		if from != to {
+			fields = append(fields, FieldChange{Field: field, From: from, To: to})
+		}
+	}
+	add("title", have.Title, want.Title)
+	add("description", have.Description, want.Description)
+	add("priority", string(have.Priority), string(want.Priority))
+	add("tags", joinSorted(have.Tags, nil), joinSorted(want.Tags, nil))
+	add("depends_on", joinSorted(have.DependsOn, labels), joinSorted(want.DependsOn, labels))
+	return fields
+}
+
+// joinSorted joins values, replaced by their labels where known, in sorted
+// order so that reordering alone is not a change.
+func joinSorted(values []string, labels map[string]string) string {
+	out := make([]string, len(values))
+	for i, v := range values {
+		if label, ok := labels[v]; ok {
+			v = label
+		}
+		out[i] = v
+	}
+	slices.Sort(out)
+	return strings.Join(out, ", ")
+}
@@ -0,0 +1,13 @@
+package plan
+
+import "fmt"
+
+// InvalidPlanError indicates a plan task that can't be applied.
+type InvalidPlanError struct {
+	Name   string // Plan task name, or the index if it has none
+	Reason string
+}
+
+func (e InvalidPlanError) Error() string {
+	return fmt.Sprintf("invalid plan task %s: %s", e.Name, e.Reason)
+}
@@ -0,0 +1,172 @@
+package plan
+
+import (
+	"bytes"
+	"slices"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/abatilo/bits/internal/external"
+	"github.com/abatilo/bits/internal/task"
+)
+
+// Plan is a declarative description of the tasks a store should hold, applied
+// with 'bits apply'.
+type Plan struct {
+	Tasks []Task `yaml:"tasks"`
+}
+
+// Task is one desired task. Name identifies it across applies and is what
+// other plan tasks depend on; it is stored as the task's key.
+type Task struct {
+	Name        string        `yaml:"name"`
+	Title       string        `yaml:"title"`
+	Description string        `yaml:"description,omitempty"`
+	Priority    task.Priority `yaml:"priority,omitempty"` // Default: medium
+	Tags        []string      `yaml:"tags,omitempty"`
+	DependsOn   []string      `yaml:"depends_on,omitempty"` // Plan task names or GitHub URLs
+	Closed      bool          `yaml:"closed,omitempty"`
+}
+
+// Parse decodes and validates a YAML (or JSON) plan, normalizing priorities,
+// tags, and GitHub URLs.
+func Parse(data []byte) (*Plan, error) {
+	var p Plan
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&p); err != nil {
+		return nil, err
+	}
+	if err := p.normalize(); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// Find returns the plan task with the given name.
+func (p *Plan) Find(name string) (*Task, bool) {
+	i := slices.IndexFunc(p.Tasks, func(t Task) bool { return t.Name == name })
+	if i < 0 {
+		return nil, false
+	}
+	return &p.Tasks[i], true
+}
+
+// normalize validates every task and rewrites fields to their canonical form.
+func (p *Plan) normalize() error {
+	names := make(map[string]bool, len(p.Tasks))
+	for i := range p.Tasks {
+		pt := &p.Tasks[i]
+		label := pt.Name
+		if label == "" {
+			label = "#" + strconv.Itoa(i+1)
+		}
+		invalid := func(reason string) error { return InvalidPlanError{Name: label, Reason: reason} }
+
+		switch {
+		case pt.Name == "":
+			return invalid("name is required")
+		case names[pt.Name]:
+			return invalid("name is used more than once")
+		case pt.Title == "":
+			return invalid("title is required")
+		}
+		names[pt.Name] = true
+
+		if pt.Priority == "" {
+			pt.Priority = task.PriorityMedium
+		}
+		if !task.IsValidPriority(pt.Priority) {
+			return invalid("invalid priority " + strconv.Quote(string(pt.Priority)))
+		}
+
+		var tags []string
+		for _, raw := range pt.Tags {
+			tag, ok := task.NormalizeTag(raw)
+			if !ok {
+				return invalid("invalid tag " + strconv.Quote(raw))
+			}
+			if !slices.Contains(tags, tag) {
+				tags = append(tags, tag)
+			}
+		}
+		pt.Tags = tags
+
+		var depends []string
+		for _, dep := range pt.DependsOn {
+			if ref, ok := external.ParseRef(dep); ok {
+				dep = ref.String()
+			}
+			if !slices.Contains(depends, dep) {
+				depends = append(depends, dep)
+			}
+		}
+		pt.DependsOn = depends
+	}
+
+	for _, pt := range p.Tasks {
+		for _, dep := range pt.DependsOn {
+			if !names[dep] && !external.IsRef(dep) {
+				return InvalidPlanError{Name: pt.Name, Reason: "depends on unknown task " + strconv.Quote(dep)}
+			}
+		}
+	}
+	if name, ok := p.cycle(); ok {
+		return InvalidPlanError{Name: name, Reason: "dependencies form a cycle"}
+	}
+	return nil
+}
+
+// cycle reports a task whose dependencies lead back to itself.
+func (p *Plan) cycle() (string, bool) {
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	state := make(map[string]int, len(p.Tasks))
+	var visit func(name string) bool
+	visit = func(name string) bool {
+		switch state[name] {
+		case visiting:
+			return true
+		case done:
+			return false
+		}
+		state[name] = visiting
+		if pt, ok := p.Find(name); ok {
+			for _, dep := range pt.DependsOn {
+				if visit(dep) {
+					return true
+				}
+			}
+		}
+		state[name] = done
+		return false
+	}
+	for _, pt := range p.Tasks {
+		if state[pt.Name] == unvisited && visit(pt.Name) {
+			return pt.Name, true
+		}
+	}
+	return "", false
+}
+
+// Apply sets t's planned fields to match pt. ids maps plan names to task IDs;
+// dependencies on names missing from it, such as closed tasks that were never
+// created, are dropped.
+func (pt *Task) Apply(t *task.Task, ids map[string]string) {
+	t.Title = pt.Title
+	t.Description = pt.Description
+	t.Priority = pt.Priority
+	t.Tags = slices.Clone(pt.Tags)
+	t.DependsOn = nil
+	for _, dep := range pt.DependsOn {
+		if id, ok := ids[dep]; ok {
+			t.DependsOn = append(t.DependsOn, id)
+		} else if external.IsRef(dep) {
+			t.DependsOn = append(t.DependsOn, dep)
+		}
+	}
+}
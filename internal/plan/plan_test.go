@@ -0,0 +1,162 @@
+//nolint:testpackage // Tests require internal access for thorough testing
+package plan
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/abatilo/bits/internal/task"
+)
+
+func TestParse(t *testing.T) {
+	p, err := Parse([]byte(`tasks:
+  - name: api
+    title: Build the API
+    tags: ["#Feature", feature]
+    depends_on: [db, "https://github.com/o/r/issues/1"]
+  - name: db
+    title: Set up the database
+    priority: high
+`))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	api, ok := p.Find("api")
+	if !ok {
+		t.Fatal("Find(api) found nothing")
+	}
+	if api.Priority != task.PriorityMedium {
+		t.Errorf("Priority = %q, want medium default", api.Priority)
+	}
+	if !slices.Equal(api.Tags, []string{"feature"}) {
+		t.Errorf("Tags = %v, want [feature]", api.Tags)
+	}
+	if len(api.DependsOn) != 2 || api.DependsOn[0] != "db" {
+		t.Errorf("DependsOn = %v, want db and the issue", api.DependsOn)
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	tests := map[string]string{
+		"missing name":   "tasks:\n  - title: A\n",
+		"duplicate name": "tasks:\n  - {name: a, title: A}\n  - {name: a, title: B}\n",
+		"missing title":  "tasks:\n  - name: a\n",
+		"bad priority":   "tasks:\n  - {name: a, title: A, priority: urgent}\n",
+		"bad tag":        "tasks:\n  - {name: a, title: A, tags: [\"two words\"]}\n",
+		"unknown dep":    "tasks:\n  - {name: a, title: A, depends_on: [b]}\n",
+		"cycle": "tasks:\n  - {name: a, title: A, depends_on: [b]}\n" +
+			"  - {name: b, title: B, depends_on: [a]}\n",
+	}
+	for name, content := range tests {
+		var invalid InvalidPlanError
+		if _, err := Parse([]byte(content)); !errors.As(err, &invalid) {
+			t.Errorf("%s: Parse error = %v, want InvalidPlanError", name, err)
+		}
+	}
+
+	if _, err := Parse([]byte("tasks:\n  - {name: a, title: A, owner: me}\n")); err == nil {
+		t.Error("Parse accepted an unknown field")
+	}
+}
+
+func TestDiff(t *testing.T) {
+	now := time.Now().UTC()
+	db := &task.Task{ID: "d1", Key: "db", Title: "Database", Status: task.StatusOpen, Priority: task.PriorityHigh}
+	api := &task.Task{ID: "a1", Key: "api", Title: "API", Status: task.StatusOpen, Priority: task.PriorityMedium}
+	old := &task.Task{ID: "o1", Key: "old", Title: "Old", Status: task.StatusActive, Priority: task.PriorityLow}
+	done := &task.Task{ID: "x1", Key: "done", Title: "Done", Status: task.StatusClosed, ClosedAt: &now}
+	gone := &task.Task{ID: "g1", Key: "gone", Title: "Gone", Status: task.StatusClosed, ClosedAt: &now}
+	manual := &task.Task{ID: "m1", Title: "Added by hand", Status: task.StatusOpen}
+	tasks := []*task.Task{db, api, old, done, gone, manual}
+
+	p, err := Parse([]byte(`tasks:
+  - {name: db, title: Database, priority: high, closed: true}
+  - {name: api, title: API, depends_on: [db, ui]}
+  - {name: ui, title: UI}
+  - {name: done, title: Done again}
+  - {name: skipped, title: Never needed, closed: true}
+`))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	changes := Diff(p, tasks)
+	got := make([]string, len(changes))
+	for i, c := range changes {
+		got[i] = string(c.Kind) + " " + c.Name
+	}
+	want := []string{"close db", "update api", "create ui", "update done", "close old"}
+	if !slices.Equal(got, want) {
+		t.Fatalf("Diff = %v, want %v", got, want)
+	}
+
+	if fields := changes[0].Fields; len(fields) != 0 || changes[0].Reason != ReasonClosed {
+		t.Errorf("close db = %+v, want no field changes and reason %q", changes[0], ReasonClosed)
+	}
+	if fields := changes[1].Fields; len(fields) != 1 || fields[0].Field != "depends_on" || fields[0].To != "db, ui" {
+		t.Errorf("update api fields = %+v, want depends_on -> db, ui", fields)
+	}
+	if changes[4].Reason != ReasonRemoved || changes[4].ID != "o1" {
+		t.Errorf("close old = %+v, want removed o1", changes[4])
+	}
+
+	// Applying the planned fields converges the store
+	ids := Keys(tasks)
+	ids["ui"] = "u1"
+	ui := &task.Task{ID: "u1", Key: "ui", Status: task.StatusOpen}
+	for _, c := range changes {
+		if pt, ok := p.Find(c.Name); ok {
+			switch c.Name {
+			case "api":
+				pt.Apply(api, ids)
+			case "ui":
+				pt.Apply(ui, ids)
+			case "done":
+				pt.Apply(done, ids)
+			}
+		}
+	}
+	db.Status, old.Status = task.StatusClosed, task.StatusClosed
+	if again := Diff(p, append(tasks, ui)); len(again) != 0 {
+		t.Errorf("Diff after applying = %+v, want none", again)
+	}
+}
+
+func TestFromTasks(t *testing.T) {
+	api := &task.Task{ID: "a1", Title: "Build the API!", Status: task.StatusOpen, Priority: task.PriorityHigh}
+	dup := &task.Task{ID: "a2", Title: "build the api", Status: task.StatusOpen, Priority: task.PriorityMedium}
+	ui := &task.Task{
+		ID: "u1", Key: "ui", Title: "Build the UI", Status: task.StatusActive, Priority: task.PriorityMedium,
+		DependsOn: []string{"a1", "gone", "https://github.com/o/r/pull/2"},
+	}
+
+	p := FromTasks([]*task.Task{api, dup, ui})
+	names := make([]string, len(p.Tasks))
+	for i, pt := range p.Tasks {
+		names[i] = pt.Name
+	}
+	if want := []string{"build-the-api", "build-the-api-2", "ui"}; !slices.Equal(names, want) {
+		t.Errorf("names = %v, want %v", names, want)
+	}
+	if p.Tasks[0].Priority != task.PriorityHigh || p.Tasks[1].Priority != "" {
+		t.Errorf("priorities = %q, %q; want high and the default omitted", p.Tasks[0].Priority, p.Tasks[1].Priority)
+	}
+	if want := []string{"build-the-api", "https://github.com/o/r/pull/2"}; !slices.Equal(p.Tasks[2].DependsOn, want) {
+		t.Errorf("DependsOn = %v, want %v", p.Tasks[2].DependsOn, want)
+	}
+
+	// The seed parses back and creates every task in an empty store
+	data, err := p.Marshal()
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	seed, err := Parse(data)
+	if err != nil {
+		t.Fatalf("Parse(seed) failed: %v\n%s", err, data)
+	}
+	if changes := Diff(seed, nil); len(changes) != 3 || changes[2].Kind != KindCreate {
+		t.Errorf("Diff(seed, empty) = %+v, want 3 creates", changes)
+	}
+}
@@ -0,0 +1,102 @@
+package plan
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/abatilo/bits/internal/external"
+	"github.com/abatilo/bits/internal/task"
+)
+
+const maxNameLength = 40
+
+// FromTasks returns a plan that recreates tasks in a fresh store, with
+// dependencies between them written by name instead of ID. A task's key is
+// kept as its name; other names are derived from titles. Dependencies on
+// tasks outside the set are dropped, except GitHub URLs. Any-of groups and
+// ordering hints have no plan equivalent and are left out.
+func FromTasks(tasks []*task.Task) *Plan {
+	names := make(map[string]string, len(tasks)) // Task ID -> name
+	used := make(map[string]bool, len(tasks))
+	for _, t := range tasks {
+		if t.Key != "" {
+			names[t.ID] = t.Key
+			used[t.Key] = true
+		}
+	}
+	for _, t := range tasks {
+		if t.Key != "" {
+			continue
+		}
+		base := slugify(t.Title)
+		name := base
+		for n := 2; used[name]; n++ {
+			name = base + "-" + strconv.Itoa(n)
+		}
+		names[t.ID] = name
+		used[name] = true
+	}
+
+	p := &Plan{Tasks: make([]Task, 0, len(tasks))}
+	for _, t := range tasks {
+		pt := Task{
+			Name:        names[t.ID],
+			Title:       t.Title,
+			Description: t.Description,
+			Tags:        t.Tags,
+			Closed:      t.Status == task.StatusClosed,
+		}
+		if t.Priority != task.PriorityMedium {
+			pt.Priority = t.Priority
+		}
+		for _, dep := range t.DependsOn {
+			if name, ok := names[dep]; ok {
+				pt.DependsOn = append(pt.DependsOn, name)
+			} else if external.IsRef(dep) {
+				pt.DependsOn = append(pt.DependsOn, dep)
+			}
+		}
+		p.Tasks = append(p.Tasks, pt)
+	}
+	return p
+}
+
+// Marshal encodes the plan as YAML, in the format Parse reads.
+func (p *Plan) Marshal() ([]byte, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2) //nolint:mnd // Standard YAML indentation
+	if err := enc.Encode(p); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// slugify derives a plan name from a title: lowercase words joined by
+// hyphens, cut at a word boundary to a readable length.
+func slugify(title string) string {
+	words := strings.FieldsFunc(strings.ToLower(title), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	var name string
+	for _, w := range words {
+		if name != "" && len(name)+1+len(w) > maxNameLength {
+			break
+		}
+		if name != "" {
+			name += "-"
+		}
+		name += w
+	}
+	if name == "" {
+		return "task"
+	}
+	return name
+}
@@ -0,0 +1,101 @@
+package plugin
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// Prefix is prepended to a subcommand name to find the executable providing it.
+const Prefix = "bits-"
+
+// Plugin is an executable on PATH that provides a bits subcommand.
+type Plugin struct {
+	Name string `json:"name" yaml:"name"`
+	Path string `json:"path" yaml:"path"`
+}
+
+// Find returns the path of the executable providing subcommand name. Names
+// containing a path separator are never plugins.
+func Find(name string) (string, error) {
+	if name == "" || strings.ContainsAny(name, `/\`) {
+		return "", exec.ErrNotFound
+	}
+	return exec.LookPath(Prefix + name)
+}
+
+// List returns the plugins found in the directories of pathList (formatted
+// like $PATH), sorted by name. As with command lookup, a plugin in an earlier
+// directory shadows one of the same name in a later directory.
+func List(pathList string) []Plugin {
+	seen := make(map[string]bool)
+	var plugins []Plugin
+	for _, dir := range filepath.SplitList(pathList) {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			name, ok := pluginName(entry.Name())
+			if !ok || seen[name] {
+				continue
+			}
+			path := filepath.Join(dir, entry.Name())
+			if !isExecutable(path) {
+				continue
+			}
+			seen[name] = true
+			plugins = append(plugins, Plugin{Name: name, Path: path})
+		}
+	}
+	sort.Slice(plugins, func(i, j int) bool {
+		return plugins[i].Name < plugins[j].Name
+	})
+	return plugins
+}
+
+// pluginName returns the subcommand a file name provides, if it is a plugin.
+func pluginName(file string) (string, bool) {
+	name, ok := strings.CutPrefix(file, Prefix)
+	if runtime.GOOS == "windows" {
+		name = strings.TrimSuffix(name, filepath.Ext(name))
+	}
+	return name, ok && name != ""
+}
+
+// isExecutable reports whether path is a regular file the user may execute.
+func isExecutable(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil || !info.Mode().IsRegular() {
+		return false
+	}
+	if runtime.GOOS == "windows" {
+		return true // LookPath decides by extension
+	}
+	return info.Mode().Perm()&0o111 != 0
+}
+
+// Run executes the plugin at path with args and extra environment variables,
+// connected to the current stdin, stdout, and stderr. It returns the plugin's
+// exit code; an error means the plugin could not be started.
+func Run(path string, args, env []string) (int, error) {
+	cmd := exec.Command(path, args...) //nolint:gosec // G204: plugins are executables the user put on PATH
+	cmd.Env = append(os.Environ(), env...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode(), nil
+	}
+	if err != nil {
+		return 1, err
+	}
+	return 0, nil
+}
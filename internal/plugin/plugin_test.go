@@ -0,0 +1,67 @@
+//nolint:testpackage // Tests require internal access for thorough testing
+package plugin
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func writePlugin(t *testing.T, dir, name, body string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+body+"\n"), 0o755); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	return path
+}
+
+func TestList(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("plugins are shell scripts")
+	}
+	first, second := t.TempDir(), t.TempDir()
+	importer := writePlugin(t, first, "bits-import", "true")
+	writePlugin(t, second, "bits-import", "true") // Shadowed by first
+	report := writePlugin(t, second, "bits-report", "true")
+	writePlugin(t, second, "bits-", "true")
+	if err := os.WriteFile(filepath.Join(second, "bits-notes"), nil, 0o644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+
+	got := List(first + string(os.PathListSeparator) + second)
+	want := []Plugin{{Name: "import", Path: importer}, {Name: "report", Path: report}}
+	if len(got) != len(want) {
+		t.Fatalf("List = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("List[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestFindAndRun(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("plugins are shell scripts")
+	}
+	dir := t.TempDir()
+	writePlugin(t, dir, "bits-hello", `[ "$1" = world ] && [ "$BITS_STORE" = /store ] || exit 3`)
+	t.Setenv("PATH", dir)
+
+	path, err := Find("hello")
+	if err != nil {
+		t.Fatalf("Find failed: %v", err)
+	}
+	if _, err = Find("../hello"); err == nil {
+		t.Error("Find accepted a name with a path separator")
+	}
+
+	if code, runErr := Run(path, []string{"world"}, []string{"BITS_STORE=/store"}); runErr != nil || code != 0 {
+		t.Errorf("Run = %d, %v; want 0", code, runErr)
+	}
+	if code, runErr := Run(path, []string{"moon"}, nil); runErr != nil || code != 3 {
+		t.Errorf("Run = %d, %v; want 3", code, runErr)
+	}
+}
@@ -0,0 +1,97 @@
+package report
+
+import (
+	"sort"
+	"time"
+
+	"github.com/abatilo/bits/internal/task"
+)
+
+// Capacity projects how long one assignee's unfinished work will take.
+type Capacity struct {
+	Assignee    string
+	Unfinished  int           // Open and active tasks assigned
+	Estimated   int           // Unfinished tasks with an estimate
+	Estimate    time.Duration // Sum of those estimates
+	WIPLimit    int           // Tasks the assignee may work on at once, zero for no limit
+	Closed      int           // Tasks closed within the window
+	CloseRate   float64       // Tasks closed per day within the window
+	Projection  time.Duration // Time to finish the unfinished tasks
+	Unprojected int           // Unestimated tasks left out of Projection for lack of a close rate
+}
+
+// Capacities projects each assignee's unfinished work from estimates, the
+// WIP limit wipLimit returns for the assignee, and the rate they closed tasks
+// at between since and now. Estimated work is spread across as many tasks as
+// the limit lets the assignee work on at once; unestimated tasks take as long
+// as the close rate says. A closed task counts for its assignee, or for the
+// actor who closed it if it had none. Capacities are ordered longest
+// projection first.
+func Capacities(tasks []*task.Task, wipLimit func(assignee string) int, since, now time.Time) []Capacity {
+	byAssignee := make(map[string]*Capacity)
+	get := func(assignee string) *Capacity {
+		c, ok := byAssignee[assignee]
+		if !ok {
+			c = &Capacity{Assignee: assignee, WIPLimit: max(wipLimit(assignee), 0)}
+			byAssignee[assignee] = c
+		}
+		return c
+	}
+
+	for _, t := range tasks {
+		if t.Status != task.StatusClosed {
+			if t.Assignee == "" {
+				continue
+			}
+			c := get(t.Assignee)
+			c.Unfinished++
+			if t.Estimate > 0 {
+				c.Estimated++
+				c.Estimate += t.Estimate
+			}
+			continue
+		}
+		if t.ClosedAt == nil || t.ClosedAt.Before(since) {
+			continue
+		}
+		closer := t.Assignee
+		if e := t.LastEvent(task.EventClose); closer == "" && e != nil {
+			closer = e.Actor
+		}
+		if closer != "" {
+			get(closer).Closed++
+		}
+	}
+
+	days := max(float64(now.Sub(since))/float64(day), 1)
+	capacities := make([]Capacity, 0, len(byAssignee))
+	for _, c := range byAssignee {
+		if c.Unfinished == 0 {
+			continue
+		}
+		c.CloseRate = float64(c.Closed) / days
+		if c.Estimated > 0 {
+			parallel := c.Estimated
+			if c.WIPLimit > 0 {
+				parallel = min(c.WIPLimit, parallel)
+			}
+			c.Projection = c.Estimate / time.Duration(parallel)
+		}
+		if unestimated := c.Unfinished - c.Estimated; unestimated > 0 {
+			if c.CloseRate > 0 {
+				c.Projection += time.Duration(float64(unestimated) / c.CloseRate * float64(day))
+			} else {
+				c.Unprojected = unestimated
+			}
+		}
+		capacities = append(capacities, *c)
+	}
+	sort.Slice(capacities, func(i, j int) bool {
+		a, b := capacities[i], capacities[j]
+		if a.Projection != b.Projection {
+			return a.Projection > b.Projection
+		}
+		return a.Assignee < b.Assignee
+	})
+	return capacities
+}
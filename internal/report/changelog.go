@@ -0,0 +1,55 @@
+package report
+
+import (
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/abatilo/bits/internal/config"
+	"github.com/abatilo/bits/internal/task"
+)
+
+// Section is a titled group of tasks in a report.
+type Section struct {
+	Title string
+	Tasks []*task.Task
+}
+
+// Changelog groups tasks closed at or after since into the configured
+// sections, in closing order. A task goes in the first section sharing one of
+// its tags; untagged and unmatched tasks go in the Other section, last.
+// Empty sections are omitted. A zero since includes every closed task.
+func Changelog(tasks []*task.Task, cfg config.Changelog, since time.Time) []Section {
+	closed := make([]*task.Task, 0, len(tasks))
+	for _, t := range tasks {
+		if t.Status == task.StatusClosed && t.ClosedAt != nil && !t.ClosedAt.Before(since) {
+			closed = append(closed, t)
+		}
+	}
+	sort.SliceStable(closed, func(i, j int) bool {
+		return closed[i].ClosedAt.Before(*closed[j].ClosedAt)
+	})
+
+	rules := cfg.ResolvedSections()
+	sections := make([]Section, len(rules)+1)
+	for i, rule := range rules {
+		sections[i].Title = rule.Title
+	}
+	sections[len(rules)].Title = cfg.OtherTitle()
+
+	for _, t := range closed {
+		i := slices.IndexFunc(rules, func(rule config.ChangelogSection) bool {
+			return slices.ContainsFunc(t.Tags, func(tag string) bool {
+				return slices.Contains(rule.Tags, tag)
+			})
+		})
+		if i < 0 {
+			i = len(rules)
+		}
+		sections[i].Tasks = append(sections[i].Tasks, t)
+	}
+
+	return slices.DeleteFunc(sections, func(s Section) bool {
+		return len(s.Tasks) == 0
+	})
+}
@@ -0,0 +1,81 @@
+package report
+
+import (
+	"sort"
+	"time"
+
+	"github.com/abatilo/bits/internal/task"
+)
+
+const unknownActor = "unknown"
+
+// Contributor summarizes one actor's work over a reporting window.
+type Contributor struct {
+	Actor       string
+	Closed      int
+	ActiveTime  time.Duration // Time spent with tasks claimed, within the window
+	TimeToClose time.Duration // Average time from creation to close, zero if nothing closed
+}
+
+// Contributors summarizes work per actor from task histories: tasks closed at
+// or after since, active time clipped to [since, now], and the average age of
+// closed tasks. Events without an actor are attributed to "unknown".
+// Contributors are ordered by tasks closed, then active time.
+func Contributors(tasks []*task.Task, since, now time.Time) []Contributor {
+	byActor := make(map[string]*Contributor)
+	get := func(actor string) *Contributor {
+		if actor == "" {
+			actor = unknownActor
+		}
+		c, ok := byActor[actor]
+		if !ok {
+			c = &Contributor{Actor: actor}
+			byActor[actor] = c
+		}
+		return c
+	}
+
+	totalToClose := make(map[string]time.Duration)
+	for _, t := range tasks {
+		for _, interval := range t.ActiveIntervals(now) {
+			start := latest(interval.Start, since)
+			if interval.End.After(start) {
+				get(interval.Actor).ActiveTime += interval.End.Sub(start)
+			}
+		}
+		for _, e := range t.History {
+			if e.Action != task.EventClose || e.At.Before(since) {
+				continue
+			}
+			c := get(e.Actor)
+			c.Closed++
+			totalToClose[c.Actor] += e.At.Sub(t.CreatedAt)
+		}
+	}
+
+	contributors := make([]Contributor, 0, len(byActor))
+	for _, c := range byActor {
+		if c.Closed > 0 {
+			c.TimeToClose = totalToClose[c.Actor] / time.Duration(c.Closed)
+		}
+		contributors = append(contributors, *c)
+	}
+	sort.Slice(contributors, func(i, j int) bool {
+		a, b := contributors[i], contributors[j]
+		if a.Closed != b.Closed {
+			return a.Closed > b.Closed
+		}
+		if a.ActiveTime != b.ActiveTime {
+			return a.ActiveTime > b.ActiveTime
+		}
+		return a.Actor < b.Actor
+	})
+	return contributors
+}
+
+func latest(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
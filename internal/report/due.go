@@ -0,0 +1,28 @@
+package report
+
+import (
+	"sort"
+	"time"
+
+	"github.com/abatilo/bits/internal/task"
+)
+
+// Due returns the unfinished tasks due before now+within, overdue ones
+// included, ordered by due date and then priority.
+func Due(tasks []*task.Task, now time.Time, within time.Duration) []*task.Task {
+	cutoff := now.Add(within)
+	var due []*task.Task
+	for _, t := range tasks {
+		if t.Status != task.StatusClosed && t.DueAt != nil && !t.DueAt.After(cutoff) {
+			due = append(due, t)
+		}
+	}
+	sort.SliceStable(due, func(i, j int) bool {
+		a, b := due[i], due[j]
+		if !a.DueAt.Equal(*b.DueAt) {
+			return a.DueAt.Before(*b.DueAt)
+		}
+		return task.PriorityOrder(a.Priority) < task.PriorityOrder(b.Priority)
+	})
+	return due
+}
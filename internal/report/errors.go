@@ -0,0 +1,30 @@
+package report
+
+import "fmt"
+
+// InvalidSinceError indicates a report start time could not be parsed.
+type InvalidSinceError struct {
+	Value string
+}
+
+func (e InvalidSinceError) Error() string {
+	return fmt.Sprintf("invalid since: %s (expected a date like 2006-01-02 or an age like 7d)", e.Value)
+}
+
+// InvalidDueError indicates a due date could not be parsed.
+type InvalidDueError struct {
+	Value string
+}
+
+func (e InvalidDueError) Error() string {
+	return fmt.Sprintf("invalid due date: %s (expected a date like 2006-01-02 or a duration like 3d)", e.Value)
+}
+
+// InvalidAgeError indicates a duration could not be parsed.
+type InvalidAgeError struct {
+	Value string
+}
+
+func (e InvalidAgeError) Error() string {
+	return fmt.Sprintf("invalid duration: %s (expected e.g. 36h, 7d, or 2w)", e.Value)
+}
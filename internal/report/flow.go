@@ -0,0 +1,142 @@
+package report
+
+import (
+	"maps"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/abatilo/bits/internal/task"
+)
+
+// Distribution summarizes a set of durations. Percentiles use the nearest
+// rank, so each is one of the observed durations.
+type Distribution struct {
+	Count int
+	Mean  time.Duration
+	P50   time.Duration
+	P90   time.Duration
+	P95   time.Duration
+	Max   time.Duration
+}
+
+// NewDistribution summarizes durations; it is zero for none.
+func NewDistribution(durations []time.Duration) Distribution {
+	if len(durations) == 0 {
+		return Distribution{}
+	}
+	sorted := slices.Sorted(slices.Values(durations))
+	var total time.Duration
+	for _, d := range sorted {
+		total += d
+	}
+	rank := func(p int) time.Duration {
+		i := (p*len(sorted)+99)/100 - 1 //nolint:mnd // Nearest-rank percentile
+		return sorted[max(i, 0)]
+	}
+	return Distribution{
+		Count: len(sorted),
+		Mean:  total / time.Duration(len(sorted)),
+		P50:   rank(50), //nolint:mnd // Median
+		P90:   rank(90), //nolint:mnd // 90th percentile
+		P95:   rank(95), //nolint:mnd // 95th percentile
+		Max:   sorted[len(sorted)-1],
+	}
+}
+
+// FlowGroup holds lead and cycle time for a set of closed tasks: lead time
+// from creation to close, cycle time from first claim to close.
+type FlowGroup struct {
+	Name      string // "all", "priority:<priority>", or "tag:<tag>"
+	LeadTime  Distribution
+	CycleTime Distribution
+	SLATasks  int // Tasks whose priority has an SLA target
+	SLAMet    int // Those closed within it
+}
+
+// SLAAttainment returns the fraction of tasks with an SLA target that met it,
+// reporting false if none had one.
+func (g FlowGroup) SLAAttainment() (float64, bool) {
+	if g.SLATasks == 0 {
+		return 0, false
+	}
+	return float64(g.SLAMet) / float64(g.SLATasks), true
+}
+
+// Flow summarizes lead and cycle time for tasks closed at or after since:
+// overall first, then by priority from critical down, then by tag in name
+// order. Tasks closed without a claim count toward lead time only. SLA
+// attainment counts tasks closed within the target for their priority.
+func Flow(tasks []*task.Task, since time.Time, sla map[task.Priority]time.Duration) []FlowGroup {
+	type samples struct {
+		lead, cycle []time.Duration
+		slaTasks    int
+		slaMet      int
+	}
+	all := &samples{}
+	byPriority := make(map[task.Priority]*samples)
+	byTag := make(map[string]*samples)
+	add := func(s *samples, t *task.Task, lead, cycle time.Duration, claimed bool) {
+		s.lead = append(s.lead, lead)
+		if claimed {
+			s.cycle = append(s.cycle, cycle)
+		}
+		if target, ok := sla[t.Priority]; ok {
+			s.slaTasks++
+			if !t.BreachesSLA(target, *t.ClosedAt) {
+				s.slaMet++
+			}
+		}
+	}
+
+	for _, t := range tasks {
+		lead, ok := t.LeadTime()
+		if !ok || t.ClosedAt.Before(since) {
+			continue
+		}
+		cycle, claimed := t.CycleTime()
+		add(all, t, lead, cycle, claimed)
+		if byPriority[t.Priority] == nil {
+			byPriority[t.Priority] = &samples{}
+		}
+		add(byPriority[t.Priority], t, lead, cycle, claimed)
+		for _, tag := range t.Tags {
+			if byTag[tag] == nil {
+				byTag[tag] = &samples{}
+			}
+			add(byTag[tag], t, lead, cycle, claimed)
+		}
+	}
+	if len(all.lead) == 0 {
+		return nil
+	}
+
+	group := func(name string, s *samples) FlowGroup {
+		return FlowGroup{
+			Name:      name,
+			LeadTime:  NewDistribution(s.lead),
+			CycleTime: NewDistribution(s.cycle),
+			SLATasks:  s.slaTasks,
+			SLAMet:    s.slaMet,
+		}
+	}
+	groups := []FlowGroup{group("all", all)}
+	priorities := make([]task.Priority, 0, len(byPriority))
+	for p := range byPriority {
+		priorities = append(priorities, p)
+	}
+	sort.Slice(priorities, func(i, j int) bool {
+		a, b := task.PriorityOrder(priorities[i]), task.PriorityOrder(priorities[j])
+		if a != b {
+			return a < b
+		}
+		return priorities[i] < priorities[j]
+	})
+	for _, p := range priorities {
+		groups = append(groups, group("priority:"+string(p), byPriority[p]))
+	}
+	for _, tag := range slices.Sorted(maps.Keys(byTag)) {
+		groups = append(groups, group("tag:"+tag, byTag[tag]))
+	}
+	return groups
+}
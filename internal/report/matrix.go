@@ -0,0 +1,46 @@
+package report
+
+import (
+	"time"
+
+	"github.com/abatilo/bits/internal/task"
+)
+
+// Quadrant is one cell of the urgency/importance matrix.
+type Quadrant struct {
+	Name      string // "do-first", "schedule", "delegate", or "later"
+	Urgent    bool
+	Important bool
+	Tasks     []*task.Task
+}
+
+// Matrix sorts open tasks into an Eisenhower matrix, returning the do-first
+// (urgent and important), schedule (important), delegate (urgent), and later
+// quadrants in that order. Critical and high priority tasks are important. A
+// task is urgent when it is overdue or due within dueWithin, or, without a
+// due date, when it was created more than oldAfter ago. Each quadrant keeps
+// the order of tasks.
+func Matrix(tasks []*task.Task, now time.Time, dueWithin, oldAfter time.Duration) []Quadrant {
+	quadrants := []Quadrant{
+		{Name: "do-first", Urgent: true, Important: true},
+		{Name: "schedule", Important: true},
+		{Name: "delegate", Urgent: true},
+		{Name: "later"},
+	}
+	for _, t := range tasks {
+		if t.Status != task.StatusOpen {
+			continue
+		}
+		important := task.PriorityOrder(t.Priority) <= task.PriorityOrder(task.PriorityHigh)
+		urgent := now.Sub(t.CreatedAt) > oldAfter
+		if t.DueAt != nil {
+			urgent = !t.DueAt.After(now.Add(dueWithin))
+		}
+		for i := range quadrants {
+			if quadrants[i].Urgent == urgent && quadrants[i].Important == important {
+				quadrants[i].Tasks = append(quadrants[i].Tasks, t)
+			}
+		}
+	}
+	return quadrants
+}
@@ -0,0 +1,99 @@
+package report
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/abatilo/bits/internal/task"
+)
+
+// Count is a number of tasks sharing a priority or tag.
+type Count struct {
+	Name  string
+	Tasks int
+}
+
+// ReasonGroup is a close reason shared by one or more closed tasks. Reasons
+// worded alike (see task.SimilarTitles) count as one.
+type ReasonGroup struct {
+	Reason     string       // Wording of the most recent close
+	Tasks      []*task.Task // Closed with the reason, most recent first
+	Priorities []Count      // Tasks per priority, from critical down
+	Tags       []Count      // Tasks per tag, most common first
+}
+
+// Reasons groups the close reasons of tasks closed at or after since, most
+// common first.
+func Reasons(tasks []*task.Task, since time.Time) []ReasonGroup {
+	var closed []*task.Task
+	for _, t := range tasks {
+		if t.Status == task.StatusClosed && t.ClosedAt != nil && !t.ClosedAt.Before(since) {
+			closed = append(closed, t)
+		}
+	}
+	sort.SliceStable(closed, func(i, j int) bool { return closed[i].ClosedAt.After(*closed[j].ClosedAt) })
+
+	var groups []ReasonGroup
+	for _, t := range closed {
+		reason := closeReason(t)
+		i := len(groups)
+		for j := range groups {
+			if strings.EqualFold(groups[j].Reason, reason) || task.SimilarTitles(groups[j].Reason, reason) {
+				i = j
+				break
+			}
+		}
+		if i == len(groups) {
+			groups = append(groups, ReasonGroup{Reason: reason})
+		}
+		groups[i].Tasks = append(groups[i].Tasks, t)
+	}
+
+	for i := range groups {
+		groups[i].Priorities, groups[i].Tags = breakdown(groups[i].Tasks)
+	}
+	sort.SliceStable(groups, func(i, j int) bool { return len(groups[i].Tasks) > len(groups[j].Tasks) })
+	return groups
+}
+
+// closeReason returns a task's trimmed close reason, or "" if it has none.
+func closeReason(t *task.Task) string {
+	if t.CloseReason == nil {
+		return ""
+	}
+	return strings.TrimSpace(*t.CloseReason)
+}
+
+// breakdown counts tasks by priority and by tag.
+func breakdown(tasks []*task.Task) ([]Count, []Count) {
+	byPriority := make(map[string]int)
+	byTag := make(map[string]int)
+	for _, t := range tasks {
+		byPriority[string(t.Priority)]++
+		for _, tag := range t.Tags {
+			byTag[tag]++
+		}
+	}
+	priorities, tags := counts(byPriority), counts(byTag)
+	sort.SliceStable(priorities, func(i, j int) bool {
+		a, b := task.Priority(priorities[i].Name), task.Priority(priorities[j].Name)
+		return task.PriorityOrder(a) < task.PriorityOrder(b)
+	})
+	return priorities, tags
+}
+
+// counts flattens a tally, most common first, then by name.
+func counts(tally map[string]int) []Count {
+	out := make([]Count, 0, len(tally))
+	for name, n := range tally {
+		out = append(out, Count{Name: name, Tasks: n})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Tasks != out[j].Tasks {
+			return out[i].Tasks > out[j].Tasks
+		}
+		return out[i].Name < out[j].Name
+	})
+	return out
+}
@@ -0,0 +1,393 @@
+//nolint:testpackage // Tests require internal access for thorough testing
+package report
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/abatilo/bits/internal/config"
+	"github.com/abatilo/bits/internal/deps"
+	"github.com/abatilo/bits/internal/task"
+)
+
+func closedTask(id string, closedAt time.Time, tags ...string) *task.Task {
+	return &task.Task{ID: id, Title: id, Status: task.StatusClosed, ClosedAt: &closedAt, Tags: tags}
+}
+
+func TestChangelog(t *testing.T) {
+	base := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	tasks := []*task.Task{
+		closedTask("fix2", base.Add(3*time.Hour), "bug"),
+		closedTask("feat", base.Add(2*time.Hour), "ui", "feature"),
+		closedTask("fix1", base.Add(1*time.Hour), "fix"),
+		closedTask("misc", base.Add(4*time.Hour)),
+		closedTask("old", base.Add(-time.Hour), "bug"),
+		{ID: "open", Status: task.StatusOpen, Tags: []string{"bug"}},
+	}
+
+	sections := Changelog(tasks, config.Changelog{}, base)
+
+	want := map[string][]string{
+		"Features": {"feat"},
+		"Fixes":    {"fix1", "fix2"},
+		"Other":    {"misc"},
+	}
+	order := []string{"Features", "Fixes", "Other"}
+	if len(sections) != len(order) {
+		t.Fatalf("Changelog returned %d sections, want %d", len(sections), len(order))
+	}
+	for i, section := range sections {
+		if section.Title != order[i] {
+			t.Errorf("section %d = %q, want %q", i, section.Title, order[i])
+		}
+		var ids []string
+		for _, tk := range section.Tasks {
+			ids = append(ids, tk.ID)
+		}
+		if len(ids) != len(want[section.Title]) {
+			t.Errorf("section %q = %v, want %v", section.Title, ids, want[section.Title])
+			continue
+		}
+		for j := range ids {
+			if ids[j] != want[section.Title][j] {
+				t.Errorf("section %q = %v, want %v", section.Title, ids, want[section.Title])
+			}
+		}
+	}
+}
+
+func TestChangelogCustomSections(t *testing.T) {
+	closed := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	cfg := config.Changelog{
+		Sections: []config.ChangelogSection{{Title: "Security", Tags: []string{"security"}}},
+		Other:    "Everything else",
+	}
+
+	sections := Changelog([]*task.Task{
+		closedTask("sec", closed, "security"),
+		closedTask("feat", closed, "feature"),
+	}, cfg, time.Time{})
+
+	if len(sections) != 2 || sections[0].Title != "Security" || sections[1].Title != "Everything else" {
+		t.Errorf("Changelog sections = %+v, want Security then Everything else", sections)
+	}
+}
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		in   string
+		want time.Time
+	}{
+		{"2024-01-01", time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"2024-01-10T08:00:00Z", time.Date(2024, 1, 10, 8, 0, 0, 0, time.UTC)},
+		{"36h", now.Add(-36 * time.Hour)},
+		{"7d", now.Add(-7 * 24 * time.Hour)},
+		{"2w", now.Add(-14 * 24 * time.Hour)},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseSince(tt.in, now)
+		if err != nil {
+			t.Errorf("ParseSince(%q) failed: %v", tt.in, err)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("ParseSince(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+
+	for _, bad := range []string{"yesterday", "-3d", ""} {
+		if _, err := ParseSince(bad, now); err == nil {
+			t.Errorf("ParseSince(%q) succeeded, want error", bad)
+		}
+	}
+}
+
+func TestContributors(t *testing.T) {
+	start := time.Date(2024, 1, 15, 9, 0, 0, 0, time.UTC)
+
+	a := &task.Task{ID: "a", Status: task.StatusOpen, CreatedAt: start}
+	a.Claim(start.Add(time.Hour), "session:s1")
+	a.Close(start.Add(3*time.Hour), "done", "session:s1")
+
+	b := &task.Task{ID: "b", Status: task.StatusOpen, CreatedAt: start}
+	b.Claim(start.Add(time.Hour), "user:alice")
+	b.Release(start.Add(2*time.Hour), "user:alice")
+	b.Claim(start.Add(4*time.Hour), "")
+
+	now := start.Add(5 * time.Hour)
+	got := Contributors([]*task.Task{a, b}, start.Add(2*time.Hour), now)
+
+	want := []Contributor{
+		{Actor: "session:s1", Closed: 1, ActiveTime: time.Hour, TimeToClose: 3 * time.Hour},
+		{Actor: "unknown", ActiveTime: time.Hour},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Contributors = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Contributors[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestWorklog(t *testing.T) {
+	start := time.Date(2024, 1, 15, 9, 0, 0, 0, time.UTC)
+
+	a := &task.Task{ID: "a", Title: "A", Status: task.StatusOpen, CreatedAt: start}
+	a.Claim(start, "user:alice")
+	a.Release(start.Add(time.Hour), "user:alice")
+
+	b := &task.Task{ID: "b", Title: "B", Status: task.StatusOpen, CreatedAt: start}
+	b.Claim(start.Add(90*time.Minute), "session:s1")
+
+	since := start.Add(30 * time.Minute)
+	now := start.Add(2 * time.Hour)
+	got := Worklog([]*task.Task{b, a}, since, now)
+
+	want := []WorkEntry{
+		{TaskID: "a", Title: "A", Actor: "user:alice", Start: since, End: start.Add(time.Hour)},
+		{TaskID: "b", Title: "B", Actor: "session:s1", Start: start.Add(90 * time.Minute), End: now},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Worklog = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Worklog[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+
+	if got = Worklog([]*task.Task{a}, now, now); len(got) != 0 {
+		t.Errorf("Worklog after the last interval = %+v, want none", got)
+	}
+}
+
+func TestDue(t *testing.T) {
+	now := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+	dueTask := func(id string, in time.Duration, p task.Priority, s task.Status) *task.Task {
+		due := now.Add(in)
+		return &task.Task{ID: id, Status: s, Priority: p, DueAt: &due}
+	}
+	tasks := []*task.Task{
+		dueTask("later", 10*day, task.PriorityHigh, task.StatusOpen),
+		dueTask("soon-low", 2*day, task.PriorityLow, task.StatusOpen),
+		dueTask("soon-high", 2*day, task.PriorityHigh, task.StatusActive),
+		dueTask("overdue", -day, task.PriorityMedium, task.StatusOpen),
+		dueTask("done", -day, task.PriorityMedium, task.StatusClosed),
+		{ID: "undated", Status: task.StatusOpen},
+	}
+
+	var ids []string
+	for _, t := range Due(tasks, now, 7*day) {
+		ids = append(ids, t.ID)
+	}
+	want := []string{"overdue", "soon-high", "soon-low"}
+	if strings.Join(ids, ",") != strings.Join(want, ",") {
+		t.Errorf("Due = %v, want %v", ids, want)
+	}
+}
+
+func TestParseDue(t *testing.T) {
+	now := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+	for input, want := range map[string]time.Time{
+		"2024-02-01":           time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+		"2024-02-01T09:00:00Z": time.Date(2024, 2, 1, 9, 0, 0, 0, time.UTC),
+		"3d":                   now.Add(3 * day),
+	} {
+		got, err := ParseDue(input, now)
+		if err != nil || !got.Equal(want) {
+			t.Errorf("ParseDue(%q) = %v, %v; want %v", input, got, err, want)
+		}
+	}
+	if _, err := ParseDue("tomorrow", now); err == nil {
+		t.Error("ParseDue accepted an unparseable value")
+	}
+}
+
+func TestCapacities(t *testing.T) {
+	now := time.Date(2024, 1, 15, 9, 0, 0, 0, time.UTC)
+	var tasks []*task.Task
+	for range 5 {
+		done := closedTask("done", now.Add(-day))
+		done.Assignee = "ui"
+		tasks = append(tasks, done)
+	}
+	tasks = append(tasks,
+		closedTask("old", now.Add(-30*day)),
+		&task.Task{ID: "e1", Status: task.StatusActive, Assignee: "ui", Estimate: 4 * time.Hour},
+		&task.Task{ID: "e2", Status: task.StatusOpen, Assignee: "ui", Estimate: 8 * time.Hour},
+		&task.Task{ID: "u1", Status: task.StatusOpen, Assignee: "ui"},
+		&task.Task{ID: "u2", Status: task.StatusOpen, Assignee: "user:alice"},
+		&task.Task{ID: "none", Status: task.StatusOpen},
+	)
+	wipLimit := func(assignee string) int {
+		if assignee == "ui" {
+			return 1
+		}
+		return 0
+	}
+
+	got := Capacities(tasks, wipLimit, now.Add(-10*day), now)
+	want := []Capacity{
+		{
+			Assignee: "ui", Unfinished: 3, Estimated: 2, Estimate: 12 * time.Hour, WIPLimit: 1,
+			Closed: 5, CloseRate: 0.5, Projection: 12*time.Hour + 2*day,
+		},
+		{Assignee: "user:alice", Unfinished: 1, Unprojected: 1},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Capacities = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Capacities[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestNewDistribution(t *testing.T) {
+	var durations []time.Duration
+	for i := 20; i >= 1; i-- {
+		durations = append(durations, time.Duration(i)*time.Hour)
+	}
+	got := NewDistribution(durations)
+	want := Distribution{
+		Count: 20, Mean: 10*time.Hour + 30*time.Minute,
+		P50: 10 * time.Hour, P90: 18 * time.Hour, P95: 19 * time.Hour, Max: 20 * time.Hour,
+	}
+	if got != want {
+		t.Errorf("NewDistribution = %+v, want %+v", got, want)
+	}
+	if got = NewDistribution(nil); got != (Distribution{}) {
+		t.Errorf("NewDistribution(nil) = %+v, want zero", got)
+	}
+}
+
+func TestFlow(t *testing.T) {
+	start := time.Date(2024, 1, 15, 9, 0, 0, 0, time.UTC)
+
+	a := &task.Task{
+		ID: "a", Status: task.StatusOpen, Priority: task.PriorityHigh, CreatedAt: start, Tags: []string{"ui"},
+	}
+	a.Claim(start.Add(time.Hour), "session:s1")
+	a.Release(start.Add(2*time.Hour), "session:s1")
+	a.Claim(start.Add(3*time.Hour), "session:s2")
+	a.Close(start.Add(5*time.Hour), "done", "session:s2")
+
+	b := &task.Task{ID: "b", Status: task.StatusOpen, Priority: task.PriorityLow, CreatedAt: start}
+	b.Close(start.Add(2*time.Hour), "obsolete", "")
+
+	old := &task.Task{ID: "old", Status: task.StatusOpen, CreatedAt: start.Add(-48 * time.Hour)}
+	old.Close(start.Add(-24*time.Hour), "done", "")
+
+	sla := map[task.Priority]time.Duration{task.PriorityHigh: 4 * time.Hour, task.PriorityLow: 4 * time.Hour}
+	groups := Flow([]*task.Task{b, a, old, {ID: "open", Status: task.StatusOpen}}, start, sla)
+
+	names := make([]string, len(groups))
+	for i, g := range groups {
+		names[i] = g.Name
+	}
+	if want := "all priority:high priority:low tag:ui"; strings.Join(names, " ") != want {
+		t.Fatalf("Flow groups = %v, want %s", names, want)
+	}
+	if all := groups[0]; all.LeadTime.Count != 2 || all.LeadTime.Max != 5*time.Hour || all.CycleTime.Count != 1 {
+		t.Errorf("all = %+v, want 2 lead times up to 5h and 1 cycle time", all)
+	}
+	if ui := groups[3]; ui.CycleTime.P50 != 4*time.Hour {
+		t.Errorf("tag:ui cycle time = %v, want 4h from the first claim", ui.CycleTime.P50)
+	}
+	if got, ok := groups[0].SLAAttainment(); !ok || got != 0.5 {
+		t.Errorf("SLAAttainment = %v, %v, want 0.5 (b within 4h, a over)", got, ok)
+	}
+}
+
+func TestWeekly(t *testing.T) {
+	now := time.Date(2024, 1, 15, 9, 0, 0, 0, time.UTC)
+
+	blocker := &task.Task{ID: "blocker", Status: task.StatusOpen, CreatedAt: now.Add(-3 * week)}
+	waiting := &task.Task{ID: "waiting", Status: task.StatusOpen, CreatedAt: now.Add(-day)}
+	waiting.DependsOn = []string{"blocker"}
+	fresh := &task.Task{ID: "fresh", Status: task.StatusOpen, CreatedAt: now.Add(-2 * day)}
+	recent := &task.Task{ID: "recent", Status: task.StatusOpen, CreatedAt: now.Add(-2 * day)}
+	recent.DependsOn = []string{"fresh"}
+	done := &task.Task{ID: "done", Status: task.StatusOpen, CreatedAt: now.Add(-10 * day)}
+	done.Claim(now.Add(-3*day), "session:s1")
+	done.Close(now.Add(-day), "done", "session:s1")
+	earlier := &task.Task{ID: "earlier", Status: task.StatusOpen, CreatedAt: now.Add(-9 * day)}
+	earlier.Close(now.Add(-8*day), "done", "")
+
+	tasks := []*task.Task{blocker, waiting, fresh, recent, done, earlier}
+	r := Weekly(tasks, deps.NewGraph(tasks), now)
+
+	if len(r.Closed) != 1 || r.Closed[0].ID != "done" {
+		t.Errorf("Closed = %v, want [done]", r.Closed)
+	}
+	if len(r.Created) != 3 {
+		t.Errorf("Created %d tasks, want 3", len(r.Created))
+	}
+	if len(r.Blockers) != 1 || r.Blockers[0].Task.ID != "blocker" || r.Blockers[0].Blocking != 1 {
+		t.Errorf("Blockers = %+v, want blocker holding up 1 task", r.Blockers)
+	}
+	if r.This.Closed != 1 || r.This.CycleTime.P50 != 2*day || r.Previous.Closed != 1 || r.Previous.Created != 2 {
+		t.Errorf("This = %+v, Previous = %+v", r.This, r.Previous)
+	}
+}
+
+func TestReasons(t *testing.T) {
+	now := time.Date(2024, 1, 15, 9, 0, 0, 0, time.UTC)
+	closeWith := func(id, reason string, at time.Time, p task.Priority, tags ...string) *task.Task {
+		tk := &task.Task{ID: id, Status: task.StatusOpen, Priority: p, Tags: tags}
+		tk.Close(at, reason, "")
+		return tk
+	}
+	tasks := []*task.Task{
+		closeWith("a", "Couldn't reproduce", now.Add(-3*day), task.PriorityHigh, "bug"),
+		closeWith("b", "Fixed in commit 1a2b3c", now.Add(-2*day), task.PriorityMedium),
+		closeWith("c", "couldn't reproduce.", now.Add(-day), task.PriorityMedium, "bug", "ui"),
+		closeWith("old", "couldn't reproduce", now.Add(-60*day), task.PriorityLow),
+	}
+
+	groups := Reasons(tasks, now.Add(-30*day))
+	if len(groups) != 2 {
+		t.Fatalf("Reasons returned %d groups, want 2: %+v", len(groups), groups)
+	}
+	repro := groups[0]
+	if repro.Reason != "couldn't reproduce." || len(repro.Tasks) != 2 || repro.Tasks[0].ID != "c" {
+		t.Errorf("groups[0] = %+v, want 2 couldn't reproduce closes, newest first", repro)
+	}
+	wantPriorities := []Count{{Name: "high", Tasks: 1}, {Name: "medium", Tasks: 1}}
+	if !slices.Equal(repro.Priorities, wantPriorities) {
+		t.Errorf("Priorities = %+v, want %+v", repro.Priorities, wantPriorities)
+	}
+	if wantTags := []Count{{Name: "bug", Tasks: 2}, {Name: "ui", Tasks: 1}}; !slices.Equal(repro.Tags, wantTags) {
+		t.Errorf("Tags = %+v, want %+v", repro.Tags, wantTags)
+	}
+}
+
+func TestMatrix(t *testing.T) {
+	now := time.Date(2024, 1, 15, 9, 0, 0, 0, time.UTC)
+	soon, later := now.Add(2*day), now.Add(30*day)
+	tasks := []*task.Task{
+		{ID: "fire", Status: task.StatusOpen, Priority: task.PriorityCritical, CreatedAt: now, DueAt: &soon},
+		{ID: "plan", Status: task.StatusOpen, Priority: task.PriorityHigh, CreatedAt: now.Add(-day), DueAt: &later},
+		{ID: "stale", Status: task.StatusOpen, Priority: task.PriorityLow, CreatedAt: now.Add(-30 * day)},
+		{ID: "idea", Status: task.StatusOpen, Priority: task.PriorityMedium, CreatedAt: now.Add(-day)},
+		{ID: "busy", Status: task.StatusActive, Priority: task.PriorityCritical, CreatedAt: now},
+	}
+
+	quadrants := Matrix(tasks, now, 7*day, 14*day)
+	want := map[string]string{"do-first": "fire", "schedule": "plan", "delegate": "stale", "later": "idea"}
+	if len(quadrants) != len(want) {
+		t.Fatalf("Matrix returned %d quadrants, want %d", len(quadrants), len(want))
+	}
+	for _, q := range quadrants {
+		if len(q.Tasks) != 1 || q.Tasks[0].ID != want[q.Name] {
+			t.Errorf("quadrant %s = %v, want [%s]", q.Name, q.Tasks, want[q.Name])
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package report
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	day  = 24 * time.Hour
+	week = 7 * day
+)
+
+// ParseSince parses a report start time: a date (2006-01-02), an RFC3339
+// timestamp, or an age relative to now such as "36h", "7d", or "2w".
+func ParseSince(s string, now time.Time) (time.Time, error) {
+	if t, err := time.Parse(time.DateOnly, s); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	d, err := ParseAge(s)
+	if err != nil {
+		return time.Time{}, InvalidSinceError{Value: s}
+	}
+	return now.Add(-d), nil
+}
+
+// ParseDue parses a due date: a date (2006-01-02, midnight UTC), an RFC3339
+// timestamp, or a duration from now such as "36h", "3d", or "2w".
+func ParseDue(s string, now time.Time) (time.Time, error) {
+	if t, err := time.Parse(time.DateOnly, s); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	d, err := ParseAge(s)
+	if err != nil {
+		return time.Time{}, InvalidDueError{Value: s}
+	}
+	return now.Add(d), nil
+}
+
+// ParseAge parses a duration that may use day ("d") and week ("w") units in
+// addition to those accepted by time.ParseDuration.
+func ParseAge(s string) (time.Duration, error) {
+	for suffix, unit := range map[string]time.Duration{"d": day, "w": week} {
+		if n, ok := strings.CutSuffix(s, suffix); ok {
+			v, err := strconv.ParseFloat(n, 64)
+			if err != nil || v < 0 {
+				return 0, InvalidAgeError{Value: s}
+			}
+			return time.Duration(v * float64(unit)), nil
+		}
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, InvalidAgeError{Value: s}
+	}
+	return d, nil
+}
@@ -0,0 +1,109 @@
+package report
+
+import (
+	"sort"
+	"time"
+
+	"github.com/abatilo/bits/internal/deps"
+	"github.com/abatilo/bits/internal/task"
+)
+
+// WeekStats holds the counts and flow metrics for one week.
+type WeekStats struct {
+	Closed    int
+	Created   int
+	LeadTime  Distribution
+	CycleTime Distribution
+}
+
+// Blocker is an unfinished task holding up other unfinished tasks.
+type Blocker struct {
+	Task     *task.Task
+	Blocking int           // Unfinished tasks it blocks
+	Age      time.Duration // Time since it was created
+}
+
+// WeeklyReport summarizes the week ending at End.
+type WeeklyReport struct {
+	Start    time.Time
+	End      time.Time
+	Closed   []*task.Task // Closed during the week, in close order
+	Created  []*task.Task // Created during the week, in creation order
+	Blockers []Blocker    // Blockers older than a week, oldest first
+	This     WeekStats
+	Previous WeekStats // The week before, for comparison
+}
+
+// Weekly compiles the report for the week ending at now: the tasks closed and
+// created, blockers created before the week began that still hold up other
+// work, and this week's counts and flow metrics next to the previous week's.
+func Weekly(tasks []*task.Task, graph *deps.Graph, now time.Time) WeeklyReport {
+	start := now.Add(-week)
+	r := WeeklyReport{
+		Start:    start,
+		End:      now,
+		This:     weekStats(tasks, start, now),
+		Previous: weekStats(tasks, start.Add(-week), start),
+	}
+
+	blocking := make(map[string]int)
+	for _, t := range tasks {
+		if t.Status == task.StatusClosed {
+			if t.ClosedAt != nil && within(*t.ClosedAt, start, now) {
+				r.Closed = append(r.Closed, t)
+			}
+		} else {
+			for _, id := range graph.BlockedBy(t.ID) {
+				blocking[id]++
+			}
+		}
+		if within(t.CreatedAt, start, now) {
+			r.Created = append(r.Created, t)
+		}
+	}
+	for id, n := range blocking {
+		if t := graph.Get(id); t != nil && t.CreatedAt.Before(start) {
+			r.Blockers = append(r.Blockers, Blocker{Task: t, Blocking: n, Age: now.Sub(t.CreatedAt)})
+		}
+	}
+
+	sort.SliceStable(r.Closed, func(i, j int) bool { return r.Closed[i].ClosedAt.Before(*r.Closed[j].ClosedAt) })
+	sort.SliceStable(r.Created, func(i, j int) bool { return r.Created[i].CreatedAt.Before(r.Created[j].CreatedAt) })
+	sort.Slice(r.Blockers, func(i, j int) bool {
+		a, b := r.Blockers[i], r.Blockers[j]
+		if a.Age != b.Age {
+			return a.Age > b.Age
+		}
+		return a.Task.ID < b.Task.ID
+	})
+	return r
+}
+
+// weekStats counts tasks created and closed in [from, to) and the lead and
+// cycle times of those closed.
+func weekStats(tasks []*task.Task, from, to time.Time) WeekStats {
+	var stats WeekStats
+	var lead, cycle []time.Duration
+	for _, t := range tasks {
+		if within(t.CreatedAt, from, to) {
+			stats.Created++
+		}
+		d, ok := t.LeadTime()
+		if !ok || !within(*t.ClosedAt, from, to) {
+			continue
+		}
+		stats.Closed++
+		lead = append(lead, d)
+		if d, ok = t.CycleTime(); ok {
+			cycle = append(cycle, d)
+		}
+	}
+	stats.LeadTime = NewDistribution(lead)
+	stats.CycleTime = NewDistribution(cycle)
+	return stats
+}
+
+// within reports whether t falls in [from, to).
+func within(t, from, to time.Time) bool {
+	return !t.Before(from) && t.Before(to)
+}
@@ -0,0 +1,46 @@
+package report
+
+import (
+	"sort"
+	"time"
+
+	"github.com/abatilo/bits/internal/task"
+)
+
+// WorkEntry is one span of time an actor had a task claimed.
+type WorkEntry struct {
+	TaskID string
+	Title  string
+	Actor  string
+	Start  time.Time
+	End    time.Time
+}
+
+// Worklog returns the active intervals of all tasks, clipped to [since, now]
+// and ordered by start time. Intervals still open run until now.
+func Worklog(tasks []*task.Task, since, now time.Time) []WorkEntry {
+	var entries []WorkEntry
+	for _, t := range tasks {
+		for _, interval := range t.ActiveIntervals(now) {
+			start := latest(interval.Start, since)
+			if !interval.End.After(start) {
+				continue
+			}
+			actor := interval.Actor
+			if actor == "" {
+				actor = unknownActor
+			}
+			entries = append(entries, WorkEntry{
+				TaskID: t.ID,
+				Title:  t.Title,
+				Actor:  actor,
+				Start:  start,
+				End:    interval.End,
+			})
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Start.Before(entries[j].Start)
+	})
+	return entries
+}
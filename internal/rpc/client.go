@@ -0,0 +1,85 @@
+package rpc
+
+import (
+	"bufio"
+	"encoding/json"
+	"net"
+	"strconv"
+	"time"
+)
+
+// Client sends requests to a Server over a connection, one at a time.
+type Client struct {
+	conn   net.Conn
+	reader *bufio.Reader
+	nextID int
+}
+
+// Dial connects to a Server listening on a unix socket.
+func Dial(socket string, timeout time.Duration) (*Client, error) {
+	conn, err := net.DialTimeout("unix", socket, timeout)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{conn: conn, reader: bufio.NewReaderSize(conn, bufio.MaxScanTokenSize)}, nil
+}
+
+// Call invokes method with params and decodes the result into result (if
+// non-nil). An error returned by the method is an Error. Notifications that
+// arrive before the response are discarded.
+func (c *Client) Call(method string, params, result any) error {
+	raw, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	c.nextID++
+	id := json.RawMessage(strconv.Itoa(c.nextID))
+	req := Request{JSONRPC: Version, ID: id, Method: method, Params: raw}
+	if err = json.NewEncoder(c.conn).Encode(req); err != nil {
+		return err
+	}
+
+	var resp Response
+	for {
+		line, readErr := c.reader.ReadBytes('\n')
+		if readErr != nil {
+			return readErr
+		}
+		var msg struct {
+			Response
+			Method string `json:"method"`
+		}
+		if err = json.Unmarshal(line, &msg); err != nil {
+			return err
+		}
+		if msg.Method == "" {
+			resp = msg.Response
+			break
+		}
+		// Skip notifications that arrive while waiting for the response
+	}
+	if resp.Error != nil {
+		return *resp.Error
+	}
+	if result == nil {
+		return nil
+	}
+	return json.Unmarshal(resp.Result, result)
+}
+
+// Receive reads the next notification pushed by the server, such as a
+// broadcast after a subscribe call.
+func (c *Client) Receive() (Request, error) {
+	var n Request
+	line, err := c.reader.ReadBytes('\n')
+	if err != nil {
+		return n, err
+	}
+	err = json.Unmarshal(line, &n)
+	return n, err
+}
+
+// Close closes the connection.
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
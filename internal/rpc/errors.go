@@ -0,0 +1,11 @@
+package rpc
+
+// Error is a JSON-RPC error object. Handlers may return one to pick the code.
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e Error) Error() string {
+	return e.Message
+}
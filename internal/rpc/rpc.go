@@ -0,0 +1,251 @@
+package rpc
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"sync"
+)
+
+// Version is the JSON-RPC protocol version spoken by Server.
+const Version = "2.0"
+
+// Standard JSON-RPC error codes.
+const (
+	CodeParseError     = -32700
+	CodeInvalidRequest = -32600
+	CodeMethodNotFound = -32601
+	CodeInvalidParams  = -32602
+	CodeServerError    = -32000 // Errors returned by a handler
+)
+
+// maxLineSize bounds a single request line.
+const maxLineSize = 16 << 20
+
+// SubscribeMethod is the built-in method a client calls to receive every
+// notification sent with Broadcast on its connection.
+const SubscribeMethod = "subscribe"
+
+// subscriberBuffer is how many outgoing messages a connection may have queued
+// before broadcasts to it are dropped.
+const subscriberBuffer = 64
+
+// Request is a JSON-RPC request. A request without an ID is a notification
+// and gets no response.
+type Request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// Response is a JSON-RPC response, carrying either a result or an error.
+type Response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+}
+
+// Handler runs a method with its raw params, returning a result to encode as JSON.
+type Handler func(params json.RawMessage) (any, error)
+
+// Server dispatches newline-delimited JSON-RPC requests to handlers. Requests
+// are handled one at a time, even across connections, so handlers need no
+// locking of their own.
+type Server struct {
+	mu       sync.Mutex
+	handlers map[string]Handler
+
+	subMu       sync.Mutex
+	subscribers map[*conn]bool
+	listeners   map[chan Request]bool // See Subscribe
+}
+
+// conn is the outgoing side of one client connection.
+type conn struct {
+	out chan any
+}
+
+// NewServer creates a Server with no methods.
+func NewServer() *Server {
+	return &Server{
+		handlers:    make(map[string]Handler),
+		subscribers: make(map[*conn]bool),
+		listeners:   make(map[chan Request]bool),
+	}
+}
+
+// Handle registers the handler for method.
+func (s *Server) Handle(method string, h Handler) {
+	s.handlers[method] = h
+}
+
+// Serve reads one request per line from r and writes one response per line to
+// w, until r is exhausted. Requests are handled in order. After a subscribe
+// request, broadcast notifications are interleaved with the responses.
+func (s *Server) Serve(r io.Reader, w io.Writer) error {
+	c := &conn{out: make(chan any, subscriberBuffer)}
+	written := make(chan error, 1)
+	go func() {
+		enc := json.NewEncoder(w)
+		var err error
+		for msg := range c.out {
+			if err == nil {
+				err = enc.Encode(msg) // After a failure, keep draining so Serve never blocks
+			}
+		}
+		written <- err
+	}()
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), maxLineSize)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		if resp, ok := s.handle(c, line); ok {
+			c.out <- resp
+		}
+	}
+
+	s.subMu.Lock()
+	delete(s.subscribers, c)
+	s.subMu.Unlock()
+	close(c.out)
+	if err := <-written; err != nil {
+		return err
+	}
+	return scanner.Err()
+}
+
+// Broadcast sends a notification to every subscribed connection. A
+// connection too far behind to queue it misses the notification.
+func (s *Server) Broadcast(method string, params any) error {
+	raw, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	notification := Request{JSONRPC: Version, Method: method, Params: raw}
+
+	s.subMu.Lock()
+	defer s.subMu.Unlock()
+	for c := range s.subscribers {
+		select {
+		case c.out <- notification:
+		default:
+		}
+	}
+	for ch := range s.listeners {
+		select {
+		case ch <- notification:
+		default:
+		}
+	}
+	return nil
+}
+
+// Subscribe receives every notification sent with Broadcast in process, as a
+// subscribed connection would, so another server can relay them. The returned
+// function unsubscribes and closes the channel.
+func (s *Server) Subscribe() (<-chan Request, func()) {
+	ch := make(chan Request, subscriberBuffer)
+	s.subMu.Lock()
+	s.listeners[ch] = true
+	s.subMu.Unlock()
+	return ch, func() {
+		s.subMu.Lock()
+		delete(s.listeners, ch)
+		s.subMu.Unlock()
+		close(ch)
+	}
+}
+
+// ServeListener serves each connection accepted from l until l is closed.
+func (s *Server) ServeListener(l net.Listener) error {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		go func() {
+			defer conn.Close()
+			_ = s.Serve(conn, conn) // A broken connection only affects its client
+		}()
+	}
+}
+
+// handle runs one request line from c, returning its response unless it was a
+// notification.
+func (s *Server) handle(c *conn, line []byte) (Response, bool) {
+	resp := Response{JSONRPC: Version, ID: json.RawMessage("null")}
+
+	var req Request
+	if err := json.Unmarshal(line, &req); err != nil {
+		resp.Error = &Error{Code: CodeParseError, Message: "parse error: " + err.Error()}
+		return resp, true
+	}
+	notification := len(req.ID) == 0
+	if !notification {
+		resp.ID = req.ID
+	}
+	if req.JSONRPC != Version || req.Method == "" {
+		resp.Error = &Error{Code: CodeInvalidRequest, Message: `invalid request: need "jsonrpc": "2.0" and a method`}
+		return resp, !notification
+	}
+
+	if req.Method == SubscribeMethod {
+		s.subMu.Lock()
+		s.subscribers[c] = true
+		s.subMu.Unlock()
+		resp.Result = json.RawMessage("true")
+		return resp, !notification
+	}
+
+	result, err := s.Call(req.Method, req.Params)
+	if err == nil {
+		resp.Result, err = json.Marshal(result)
+	}
+	if err != nil {
+		var rpcErr Error
+		if !errors.As(err, &rpcErr) {
+			rpcErr = Error{Code: CodeServerError, Message: err.Error()}
+		}
+		resp.Result = nil
+		resp.Error = &rpcErr
+	}
+	return resp, !notification
+}
+
+// Call runs the handler for method with params, in turn with requests from
+// every connection, so another server can be built on top of s's methods.
+func (s *Server) Call(method string, params json.RawMessage) (any, error) {
+	h, ok := s.handlers[method]
+	if !ok {
+		return nil, Error{Code: CodeMethodNotFound, Message: "method not found: " + method}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return h(params)
+}
+
+// DecodeParams decodes params into v, rejecting unknown fields. Missing
+// params leave v unchanged.
+func DecodeParams(params json.RawMessage, v any) error {
+	if len(params) == 0 || string(params) == "null" {
+		return nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(params))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return Error{Code: CodeInvalidParams, Message: "invalid params: " + err.Error()}
+	}
+	return nil
+}
@@ -0,0 +1,181 @@
+//nolint:testpackage // Tests require internal access for thorough testing
+package rpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestServe(t *testing.T) {
+	s := NewServer()
+	s.Handle("echo", func(params json.RawMessage) (any, error) {
+		var p struct {
+			Text string `json:"text"`
+		}
+		if err := DecodeParams(params, &p); err != nil {
+			return nil, err
+		}
+		return p.Text, nil
+	})
+	s.Handle("fail", func(json.RawMessage) (any, error) {
+		return nil, errors.New("boom")
+	})
+
+	in := strings.Join([]string{
+		`{"jsonrpc":"2.0","id":1,"method":"echo","params":{"text":"hi"}}`,
+		``,
+		`{"jsonrpc":"2.0","method":"echo"}`,
+		`{"jsonrpc":"2.0","id":"b","method":"fail"}`,
+		`{"jsonrpc":"2.0","id":3,"method":"missing"}`,
+		`{"jsonrpc":"2.0","id":4,"method":"echo","params":{"txt":"hi"}}`,
+		`{"id":5,"method":"echo"}`,
+		`not json`,
+	}, "\n")
+	var out bytes.Buffer
+	if err := s.Serve(strings.NewReader(in), &out); err != nil {
+		t.Fatalf("Serve failed: %v", err)
+	}
+
+	want := []string{
+		`{"jsonrpc":"2.0","id":1,"result":"hi"}`,
+		`{"jsonrpc":"2.0","id":"b","error":{"code":-32000,"message":"boom"}}`,
+		`{"jsonrpc":"2.0","id":3,"error":{"code":-32601,"message":"method not found: missing"}}`,
+		`{"jsonrpc":"2.0","id":4,"error":{"code":-32602,"message":"invalid params: json: unknown field \"txt\""}}`,
+	}
+	got := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(got) != len(want)+2 {
+		t.Fatalf("Serve wrote %d responses, want %d:\n%s", len(got), len(want)+2, out.String())
+	}
+	for i, line := range want {
+		if got[i] != line {
+			t.Errorf("response %d = %s, want %s", i, got[i], line)
+		}
+	}
+	codes := []int{CodeInvalidRequest, CodeParseError}
+	for i, code := range codes {
+		line := got[len(want)+i]
+		var resp Response
+		if err := json.Unmarshal([]byte(line), &resp); err != nil || resp.Error == nil || resp.Error.Code != code {
+			t.Errorf("response %d = %s, want error code %d", len(want)+i, line, code)
+		}
+	}
+}
+
+func TestClient(t *testing.T) {
+	s := NewServer()
+	s.Handle("add", func(params json.RawMessage) (any, error) {
+		var p [2]int
+		if err := DecodeParams(params, &p); err != nil {
+			return nil, err
+		}
+		return p[0] + p[1], nil
+	})
+
+	socket := filepath.Join(t.TempDir(), "rpc.sock")
+	l, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+	defer l.Close()
+	go func() { _ = s.ServeListener(l) }()
+
+	client, err := Dial(socket, time.Second)
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	defer client.Close()
+
+	var sum int
+	for range 2 {
+		if err = client.Call("add", [2]int{2, 3}, &sum); err != nil || sum != 5 {
+			t.Errorf("Call(add) = %d, %v; want 5", sum, err)
+		}
+	}
+	var rpcErr Error
+	if err = client.Call("missing", nil, nil); !errors.As(err, &rpcErr) || rpcErr.Code != CodeMethodNotFound {
+		t.Errorf("Call(missing) error = %v, want method not found", err)
+	}
+}
+
+func TestBroadcast(t *testing.T) {
+	s := NewServer()
+	s.Handle("ping", func(json.RawMessage) (any, error) {
+		return "pong", s.Broadcast("event", map[string]string{"type": "ping"})
+	})
+
+	socket := filepath.Join(t.TempDir(), "rpc.sock")
+	l, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatalf("Listen failed: %v", err)
+	}
+	defer l.Close()
+	go func() { _ = s.ServeListener(l) }()
+
+	subscriber, err := Dial(socket, time.Second)
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	defer subscriber.Close()
+	if err = subscriber.Call(SubscribeMethod, nil, nil); err != nil {
+		t.Fatalf("subscribe failed: %v", err)
+	}
+
+	caller, err := Dial(socket, time.Second)
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	defer caller.Close()
+	var reply string
+	if err = caller.Call("ping", nil, &reply); err != nil || reply != "pong" {
+		t.Fatalf("Call(ping) = %q, %v", reply, err)
+	}
+
+	n, err := subscriber.Receive()
+	if err != nil {
+		t.Fatalf("Receive failed: %v", err)
+	}
+	if n.Method != "event" || string(n.Params) != `{"type":"ping"}` {
+		t.Errorf("notification = %s %s, want event {\"type\":\"ping\"}", n.Method, n.Params)
+	}
+}
+
+func TestSubscribe(t *testing.T) {
+	s := NewServer()
+	events, unsubscribe := s.Subscribe()
+	if err := s.Broadcast("event", map[string]string{"type": "ping"}); err != nil {
+		t.Fatalf("Broadcast failed: %v", err)
+	}
+	if n := <-events; n.Method != "event" || string(n.Params) != `{"type":"ping"}` {
+		t.Errorf("notification = %s %s, want event {\"type\":\"ping\"}", n.Method, n.Params)
+	}
+
+	unsubscribe()
+	if _, open := <-events; open {
+		t.Error("channel still open after unsubscribing")
+	}
+	if err := s.Broadcast("event", nil); err != nil {
+		t.Errorf("Broadcast after unsubscribing failed: %v", err)
+	}
+}
+
+func TestCall(t *testing.T) {
+	s := NewServer()
+	s.Handle("echo", func(params json.RawMessage) (any, error) {
+		return string(params), nil
+	})
+
+	got, err := s.Call("echo", json.RawMessage(`{"text":"hi"}`))
+	if err != nil || got != `{"text":"hi"}` {
+		t.Errorf("Call(echo) = %v, %v; want the params back", got, err)
+	}
+	var rpcErr Error
+	if _, err = s.Call("missing", nil); !errors.As(err, &rpcErr) || rpcErr.Code != CodeMethodNotFound {
+		t.Errorf("Call(missing) error = %v, want code %d", err, CodeMethodNotFound)
+	}
+}
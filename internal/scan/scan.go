@@ -0,0 +1,173 @@
+// Package scan finds TODO(bits) and FIXME comments in source files and works
+// out the task changes that keep a store tracking them.
+package scan
+
+import (
+	"bytes"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/abatilo/bits/internal/task"
+)
+
+// Kind is what a change does to a task.
+type Kind string
+
+const (
+	KindCreate Kind = "create" // A comment no task tracks yet
+	KindMove   Kind = "move"   // A tracked comment now on another line
+	KindClose  Kind = "close"  // A tracked comment that is gone
+)
+
+// ReasonRemoved is the close reason recorded for tasks whose comment is gone.
+const ReasonRemoved = "Comment removed"
+
+// Marker names.
+const (
+	MarkerTodo  = "TODO"
+	MarkerFixme = "FIXME"
+)
+
+// binarySniffLen is how much of a file is checked for a NUL byte before it is
+// skipped as binary.
+const binarySniffLen = 8000
+
+// markerRe matches TODO(bits) and FIXME, optionally with an owner in
+// parentheses, followed by an optional colon and the comment text.
+var markerRe = regexp.MustCompile(`\b(TODO\(bits\)|FIXME(?:\([^)]*\))?)(?::|\s|$)\s*(.*)`)
+
+// Comment is a TODO(bits) or FIXME comment in a file.
+type Comment struct {
+	Marker string // MarkerTodo or MarkerFixme
+	task.Source
+}
+
+// Title returns the title for the comment's task: its text, or the marker and
+// file for a bare marker.
+func (c Comment) Title() string {
+	if c.Text != "" {
+		return c.Text
+	}
+	return c.Marker + " in " + c.File
+}
+
+// Find returns the comments in content, read from file. Binary files have
+// none.
+func Find(file string, content []byte) []Comment {
+	if bytes.IndexByte(content[:min(len(content), binarySniffLen)], 0) >= 0 {
+		return nil
+	}
+	var comments []Comment
+	for i, line := range strings.Split(string(content), "\n") {
+		m := markerRe.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		marker := MarkerFixme
+		if strings.HasPrefix(m[1], MarkerTodo) {
+			marker = MarkerTodo
+		}
+		comments = append(comments, Comment{
+			Marker: marker,
+			Source: task.Source{File: file, Line: i + 1, Text: commentText(m[2])},
+		})
+	}
+	return comments
+}
+
+// commentText trims the closing delimiters of block comments from text.
+func commentText(text string) string {
+	text = strings.TrimSpace(text)
+	for _, closer := range []string{"*/", "-->", "#}", "%>"} {
+		text = strings.TrimSpace(strings.TrimSuffix(text, closer))
+	}
+	return text
+}
+
+// Change is one step toward the store tracking exactly the comments found.
+type Change struct {
+	Kind     Kind   `json:"kind"`
+	ID       string `json:"id,omitempty"` // Empty for tasks not yet created
+	Title    string `json:"title"`
+	Marker   string `json:"marker,omitempty"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	Text     string `json:"text,omitempty"`
+	FromLine int    `json:"from_line,omitempty"` // Where a moved comment was
+}
+
+// Source returns where the change's comment is.
+func (c Change) Source() task.Source {
+	return task.Source{File: c.File, Line: c.Line, Text: c.Text}
+}
+
+// sourceKey identifies a comment across scans: its file and text, since lines
+// shift as code around it changes.
+type sourceKey struct {
+	file string
+	text string
+}
+
+// Reconcile returns the changes that make tasks track comments, in file and
+// line order followed by closes sorted by ID:
+//   - a create for each comment no task tracks;
+//   - a move for each unfinished task whose comment changed line;
+//   - a close for each unfinished task whose comment is gone.
+//
+// A task tracks the comment with the same file and text as its source; a file
+// repeating a comment matches them up in line order. Closed tasks still
+// track their comment, so closing one by hand keeps it from being recreated.
+func Reconcile(comments []Comment, tasks []*task.Task) []Change {
+	tracked := make(map[sourceKey][]*task.Task)
+	for _, t := range tasks {
+		if t.Source.File != "" {
+			k := sourceKey{t.Source.File, t.Source.Text}
+			tracked[k] = append(tracked[k], t)
+		}
+	}
+	for _, queue := range tracked {
+		sort.SliceStable(queue, func(i, j int) bool { return queue[i].Source.Line < queue[j].Source.Line })
+	}
+
+	comments = append([]Comment(nil), comments...)
+	sort.SliceStable(comments, func(i, j int) bool {
+		if comments[i].File != comments[j].File {
+			return comments[i].File < comments[j].File
+		}
+		return comments[i].Line < comments[j].Line
+	})
+
+	var changes []Change
+	for _, c := range comments {
+		k := sourceKey{c.File, c.Text}
+		change := Change{Title: c.Title(), Marker: c.Marker, File: c.File, Line: c.Line, Text: c.Text}
+		queue := tracked[k]
+		if len(queue) == 0 {
+			change.Kind = KindCreate
+			changes = append(changes, change)
+			continue
+		}
+		t := queue[0]
+		tracked[k] = queue[1:]
+		if t.Source.Line != c.Line && t.Status != task.StatusClosed {
+			change.Kind, change.ID, change.Title, change.FromLine = KindMove, t.ID, t.Title, t.Source.Line
+			changes = append(changes, change)
+		}
+	}
+
+	var closes []Change
+	for _, queue := range tracked {
+		for _, t := range queue {
+			if t.Status == task.StatusClosed {
+				continue
+			}
+			closes = append(closes, Change{
+				Kind: KindClose, ID: t.ID, Title: t.Title,
+				File: t.Source.File, Line: t.Source.Line, Text: t.Source.Text,
+			})
+		}
+	}
+	sort.Slice(closes, func(i, j int) bool { return closes[i].ID < closes[j].ID })
+	return append(changes, closes...)
+}
@@ -0,0 +1,85 @@
+//nolint:testpackage // Tests require internal access for thorough testing
+package scan
+
+import (
+	"testing"
+
+	"github.com/abatilo/bits/internal/task"
+)
+
+func TestFind(t *testing.T) {
+	content := []byte(`package main
+
+// TODO(bits): retry when the upload times out
+# FIXME(alice) handle an empty config
+/* FIXME: close the file */
+// TODO: not tracked without (bits)
+// TODO(bits)
+var prefixFIXME = 1
+`)
+	got := Find("main.go", content)
+	want := []Comment{
+		{Marker: MarkerTodo, Source: task.Source{File: "main.go", Line: 3, Text: "retry when the upload times out"}},
+		{Marker: MarkerFixme, Source: task.Source{File: "main.go", Line: 4, Text: "handle an empty config"}},
+		{Marker: MarkerFixme, Source: task.Source{File: "main.go", Line: 5, Text: "close the file"}},
+		{Marker: MarkerTodo, Source: task.Source{File: "main.go", Line: 7}},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Find = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Find[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+	if title := got[3].Title(); title != "TODO in main.go" {
+		t.Errorf("Title of a bare marker = %q, want %q", title, "TODO in main.go")
+	}
+
+	if got := Find("blob.bin", []byte("FIXME\x00")); got != nil {
+		t.Errorf("Find in a binary file = %+v, want none", got)
+	}
+}
+
+func TestReconcile(t *testing.T) {
+	tracked := func(id, file string, line int, text string, status task.Status) *task.Task {
+		return &task.Task{ID: id, Title: text, Status: status, Source: task.Source{File: file, Line: line, Text: text}}
+	}
+	tasks := []*task.Task{
+		tracked("same", "a.go", 1, "kept", task.StatusOpen),
+		tracked("moved", "a.go", 2, "moved", task.StatusOpen),
+		tracked("gone", "b.go", 4, "removed", task.StatusActive),
+		tracked("done", "b.go", 8, "closed by hand", task.StatusClosed),
+		{ID: "plain", Title: "Not from a comment", Status: task.StatusOpen},
+	}
+	comment := func(file string, line int, text string) Comment {
+		return Comment{Marker: MarkerTodo, Source: task.Source{File: file, Line: line, Text: text}}
+	}
+	comments := []Comment{
+		comment("b.go", 9, "closed by hand"),
+		comment("a.go", 1, "kept"),
+		comment("a.go", 5, "moved"),
+		comment("a.go", 6, "new"),
+	}
+
+	changes := Reconcile(comments, tasks)
+	want := []Change{
+		{Kind: KindMove, ID: "moved", Title: "moved", Marker: MarkerTodo, File: "a.go", Line: 5, Text: "moved", FromLine: 2},
+		{Kind: KindCreate, Title: "new", Marker: MarkerTodo, File: "a.go", Line: 6, Text: "new"},
+		{Kind: KindClose, ID: "gone", Title: "removed", File: "b.go", Line: 4, Text: "removed"},
+	}
+	if len(changes) != len(want) {
+		t.Fatalf("Reconcile = %+v, want %+v", changes, want)
+	}
+	for i := range want {
+		if changes[i] != want[i] {
+			t.Errorf("Reconcile[%d] = %+v, want %+v", i, changes[i], want[i])
+		}
+	}
+
+	// A file repeating a comment needs a task per copy
+	repeated := Reconcile([]Comment{comment("a.go", 1, "kept"), comment("a.go", 3, "kept")}, tasks[:1])
+	if len(repeated) != 1 || repeated[0].Kind != KindCreate || repeated[0].Line != 3 {
+		t.Errorf("Reconcile of a repeated comment = %+v, want one create at line 3", repeated)
+	}
+}
@@ -0,0 +1,12 @@
+package script
+
+import "fmt"
+
+// TimeoutError indicates a command was killed for running past its deadline.
+type TimeoutError struct {
+	Command string
+}
+
+func (e TimeoutError) Error() string {
+	return fmt.Sprintf("command `%s` timed out", e.Command)
+}
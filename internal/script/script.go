@@ -0,0 +1,78 @@
+package script
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// waitDelay bounds how long Run waits for output after the command is killed,
+// in case it left children holding the pipes open.
+const waitDelay = time.Second
+
+// Result is the outcome of a shell command.
+type Result struct {
+	Command  string
+	ExitCode int
+	Output   string // Combined stdout and stderr
+}
+
+// Command returns a command that runs command through the shell: sh, or
+// %ComSpec% on Windows. args are passed after it as separate arguments, so
+// they need no quoting.
+func Command(ctx context.Context, command string, args ...string) *exec.Cmd {
+	return shellCommand(ctx, command, args)
+}
+
+// Run executes command through the shell (see Command) in dir with extra environment variables,
+// capturing its combined output and also copying it to progress (if non-nil).
+// A non-zero exit is reported in the result, not as an error. The command is
+// killed when ctx is done, returning a TimeoutError if its deadline passed.
+func Run(ctx context.Context, dir, command string, env []string, progress io.Writer) (Result, error) {
+	var out bytes.Buffer
+	var w io.Writer = &out
+	if progress != nil {
+		w = io.MultiWriter(&out, progress)
+	}
+
+	cmd := Command(ctx, command)
+	cmd.WaitDelay = waitDelay
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), env...)
+	cmd.Stdout = w
+	cmd.Stderr = w
+
+	result := Result{Command: command}
+	err := cmd.Run()
+	result.Output = out.String()
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return result, TimeoutError{Command: command}
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		result.ExitCode = exitErr.ExitCode()
+		return result, nil
+	}
+	return result, err
+}
+
+// OK reports whether the command exited 0.
+func (r Result) OK() bool {
+	return r.ExitCode == 0
+}
+
+// Summary returns the last n non-empty lines of output joined with " / ".
+func (r Result) Summary(n int) string {
+	var lines []string
+	for line := range strings.SplitSeq(r.Output, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines[max(len(lines)-n, 0):], " / ")
+}
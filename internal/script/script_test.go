@@ -0,0 +1,50 @@
+//nolint:testpackage // Tests require internal access for thorough testing
+package script
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRun(t *testing.T) {
+	var progress bytes.Buffer
+	command := `echo one; echo; echo "two $EXTRA" >&2; exit 3`
+	result, err := Run(t.Context(), t.TempDir(), command, []string{"EXTRA=x"}, &progress)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if result.OK() || result.ExitCode != 3 {
+		t.Errorf("ExitCode = %d, want 3", result.ExitCode)
+	}
+	if progress.String() != result.Output {
+		t.Errorf("progress = %q, want a copy of output %q", progress.String(), result.Output)
+	}
+	if got := result.Summary(5); got != "one / two x" {
+		t.Errorf("Summary(5) = %q", got)
+	}
+	if got := result.Summary(1); got != "two x" {
+		t.Errorf("Summary(1) = %q", got)
+	}
+
+	if result, err = Run(t.Context(), "", "true", nil, nil); err != nil || !result.OK() {
+		t.Errorf("Run(true) = %+v, %v", result, err)
+	}
+}
+
+func TestRunTimeout(t *testing.T) {
+	ctx, cancel := context.WithTimeout(t.Context(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := Run(ctx, "", "sleep 5", nil, nil)
+	var timeout TimeoutError
+	if !errors.As(err, &timeout) {
+		t.Fatalf("Run error = %v, want TimeoutError", err)
+	}
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Errorf("Run took %s after the deadline", elapsed)
+	}
+}
@@ -0,0 +1,17 @@
+//go:build !windows
+
+package script
+
+import (
+	"context"
+	"os/exec"
+)
+
+// shellCommand runs command through sh, passing args as "$@".
+func shellCommand(ctx context.Context, command string, args []string) *exec.Cmd {
+	if len(args) > 0 {
+		command += ` "$@"`
+	}
+	//nolint:gosec // G204: commands come from the user's config, tasks, or $EDITOR
+	return exec.CommandContext(ctx, "sh", append([]string{"-c", command, "sh"}, args...)...)
+}
@@ -0,0 +1,16 @@
+//go:build !windows
+
+//nolint:testpackage // Tests require internal access for thorough testing
+package script
+
+import "testing"
+
+func TestCommandArgs(t *testing.T) {
+	out, err := Command(t.Context(), "printf '%s|'", "two words", `it's "quoted"`, "$HOME").Output()
+	if err != nil {
+		t.Fatalf("Command failed: %v", err)
+	}
+	if want := `two words|it's "quoted"|$HOME|`; string(out) != want {
+		t.Errorf("output = %q, want %q", out, want)
+	}
+}
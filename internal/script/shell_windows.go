@@ -0,0 +1,32 @@
+//go:build windows
+
+package script
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+)
+
+// shellCommand runs command through %ComSpec% (cmd.exe), with args quoted
+// after it. The command line is built by hand because cmd.exe doesn't parse
+// its arguments the way exec quotes them; /S makes it strip only the outer
+// quotes around the rest of the line.
+func shellCommand(ctx context.Context, command string, args []string) *exec.Cmd {
+	comspec := os.Getenv("ComSpec")
+	if comspec == "" {
+		comspec = "cmd.exe"
+	}
+	line := []string{command}
+	for _, arg := range args {
+		line = append(line, syscall.EscapeArg(arg))
+	}
+
+	cmd := exec.CommandContext(ctx, comspec)
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		CmdLine: syscall.EscapeArg(comspec) + ` /S /C "` + strings.Join(line, " ") + `"`,
+	}
+	return cmd
+}
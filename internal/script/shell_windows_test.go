@@ -0,0 +1,23 @@
+//go:build windows
+
+//nolint:testpackage // Tests require internal access for thorough testing
+package script
+
+import "testing"
+
+func TestCommandLine(t *testing.T) {
+	t.Setenv("ComSpec", `C:\Windows\system32\cmd.exe`)
+	cmd := Command(t.Context(), "notepad", `C:\Users\me\My Tasks\abc.md`)
+	want := `C:\Windows\system32\cmd.exe /S /C "notepad "C:\Users\me\My Tasks\abc.md""`
+	if got := cmd.SysProcAttr.CmdLine; got != want {
+		t.Errorf("CmdLine = %q, want %q", got, want)
+	}
+
+	out, err := Command(t.Context(), "echo", "two words").Output()
+	if err != nil {
+		t.Fatalf("Command failed: %v", err)
+	}
+	if want = `"two words"` + "\r\n"; string(out) != want {
+		t.Errorf("output = %q, want %q", out, want)
+	}
+}
@@ -75,20 +75,30 @@ func Load(basePath string) (*Session, error) {
 	return &s, nil
 }
 
-// Save writes the session to disk.
+// Save writes the session to disk. The file inherits the permissions of the
+// store directory, minus execute bits, so a private store keeps a private session.
 func Save(basePath string, s *Session) error {
 	//nolint:gosec // G301: 0755 is appropriate for user-accessible session directory
 	if mkdirErr := os.MkdirAll(basePath, 0o755); mkdirErr != nil {
 		return mkdirErr
 	}
+	info, err := os.Stat(basePath)
+	if err != nil {
+		return err
+	}
+	fileMode := info.Mode().Perm() &^ 0o111
 
 	data, err := json.MarshalIndent(s, "", "  ")
 	if err != nil {
 		return err
 	}
 
-	//nolint:gosec // G306: 0644 is appropriate for user-readable session files
-	return os.WriteFile(sessionPath(basePath), data, 0o644)
+	path := sessionPath(basePath)
+	//nolint:gosec // G306: mode follows the store directory's permissions
+	if err = os.WriteFile(path, data, fileMode); err != nil {
+		return err
+	}
+	return os.Chmod(path, fileMode)
 }
 
 // Delete removes the session file.
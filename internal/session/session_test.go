@@ -4,6 +4,7 @@ package session
 import (
 	"os"
 	"path/filepath"
+	"runtime"
 	"testing"
 )
 
@@ -298,3 +299,25 @@ func TestLoadNonExistent(t *testing.T) {
 		t.Errorf("Load should return os.IsNotExist error, got: %v", err)
 	}
 }
+
+func TestSessionSaveFollowsDirectoryMode(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Windows keeps only the read-only bit of a file mode")
+	}
+	tmpDir := t.TempDir()
+	if err := os.Chmod(tmpDir, 0o700); err != nil {
+		t.Fatalf("Chmod failed: %v", err)
+	}
+
+	if err := Save(tmpDir, &Session{SessionID: "s1"}); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	info, err := os.Stat(filepath.Join(tmpDir, sessionFile))
+	if err != nil {
+		t.Fatalf("Stat failed: %v", err)
+	}
+	if got := info.Mode().Perm(); got != 0o600 {
+		t.Errorf("Session file mode = %o, want 600", got)
+	}
+}
@@ -0,0 +1,197 @@
+package storage
+
+import (
+	"bytes"
+	"maps"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/abatilo/bits/internal/task"
+)
+
+const (
+	archiveDir         = "archive"
+	archiveMonthLayout = "2006-01"
+	archiveMarker      = "<!-- bits:task " // Followed by the task ID and " -->"
+)
+
+// Compact moves closed tasks that were closed before cutoff out of the store
+// into one archive bundle per month of closing, archive/YYYY-MM.md, so the
+// number of files stays bounded over years of use. A bundle is the task files
+// concatenated, each introduced by a <!-- bits:task ID --> line, and can be
+// read back with Archived. Attachments stay where they are. Returns the
+// archived tasks sorted by ID.
+func (s *Store) Compact(cutoff time.Time) ([]*task.Task, error) {
+	tasks, err := s.List(StatusFilter{Closed: true})
+	if err != nil {
+		return nil, err
+	}
+
+	months := make(map[string][]*task.Task)
+	var archived []*task.Task
+	for _, t := range tasks {
+		if t.ClosedAt == nil || !t.ClosedAt.Before(cutoff) {
+			continue
+		}
+		month := t.ClosedAt.UTC().Format(archiveMonthLayout)
+		months[month] = append(months[month], t)
+		archived = append(archived, t)
+	}
+	sort.Slice(archived, func(i, j int) bool { return archived[i].ID < archived[j].ID })
+
+	for month, monthTasks := range months {
+		if s.DryRun() {
+			s.skip("archive %d task(s) into %s", len(monthTasks), s.archivePath(month))
+			continue
+		}
+		if err = s.appendArchive(month, monthTasks); err != nil {
+			return nil, err
+		}
+	}
+	// Bundles are written before any task file is removed, so an interrupted
+	// compaction leaves tasks in both places rather than in neither. Undoing
+	// the removals would leave them in both too, so they aren't journaled.
+	journal := s.journal
+	s.journal = nil
+	defer func() { s.journal = journal }()
+	for _, t := range archived {
+		if err = s.deleteTask(t.ID, false); err != nil {
+			return nil, err
+		}
+	}
+	return archived, nil
+}
+
+// Archived returns every task in the archive bundles, sorted by ID.
+func (s *Store) Archived() ([]*task.Task, error) {
+	files, err := s.archiveFiles()
+	if err != nil {
+		return nil, err
+	}
+	tasks := make([]*task.Task, 0, len(files))
+	for _, content := range files {
+		t, parseErr := ParseMarkdown(content)
+		if parseErr != nil {
+			continue // Skip malformed entries
+		}
+		tasks = append(tasks, t)
+	}
+	sort.Slice(tasks, func(i, j int) bool { return tasks[i].ID < tasks[j].ID })
+	return tasks, nil
+}
+
+// ArchivedIDs returns the set of IDs in the archive bundles.
+func (s *Store) ArchivedIDs() (map[string]bool, error) {
+	return s.archivedIDs()
+}
+
+// archivedIDs returns the IDs in the archive bundles, so new tasks don't
+// reuse them and dependencies on them aren't reported missing.
+func (s *Store) archivedIDs() (map[string]bool, error) {
+	files, err := s.archiveFiles()
+	if err != nil {
+		return nil, err
+	}
+	ids := make(map[string]bool, len(files))
+	for id := range files {
+		ids[id] = true
+	}
+	return ids, nil
+}
+
+// archiveFiles returns the task files in every bundle keyed by ID.
+func (s *Store) archiveFiles() (map[string][]byte, error) {
+	bundles, err := filepath.Glob(filepath.Join(s.basePath, archiveDir, "*"+fileExt))
+	if err != nil {
+		return nil, err
+	}
+	files := make(map[string][]byte)
+	for _, path := range bundles {
+		bundle, readErr := readArchive(path)
+		if readErr != nil {
+			return nil, readErr
+		}
+		maps.Copy(files, bundle)
+	}
+	return files, nil
+}
+
+// archivePath returns the bundle for a month.
+func (s *Store) archivePath(month string) string {
+	return filepath.Join(s.basePath, archiveDir, month+fileExt)
+}
+
+// appendArchive adds tasks' files to a month's bundle, replacing any entries
+// with the same IDs, and rewrites it atomically.
+func (s *Store) appendArchive(month string, tasks []*task.Task) error {
+	path := s.archivePath(month)
+	files, err := readArchive(path)
+	if os.IsNotExist(err) {
+		files, err = make(map[string][]byte), nil
+	}
+	if err != nil {
+		return err
+	}
+	for _, t := range tasks {
+		content, readErr := s.fullContent(s.taskPath(t.ID))
+		if readErr != nil {
+			return readErr
+		}
+		files[t.ID] = content
+		if s.taken != nil {
+			s.taken[t.ID] = true // Its task file is about to go
+		}
+	}
+
+	ids := make([]string, 0, len(files))
+	for id := range files {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	var buf bytes.Buffer
+	for _, id := range ids {
+		buf.WriteString(archiveMarker + id + " -->\n")
+		buf.Write(files[id])
+	}
+
+	if err = s.mkdirAll(filepath.Dir(path)); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err = s.writeFile(tmp, buf.Bytes()); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// readArchive splits a bundle into task files keyed by ID.
+func readArchive(path string) (map[string][]byte, error) {
+	content, err := os.ReadFile(path) //nolint:gosec // G304: path is within the store
+	if err != nil {
+		return nil, err
+	}
+	files := make(map[string][]byte)
+	var id string
+	var body bytes.Buffer
+	flush := func() {
+		if id != "" {
+			files[id] = bytes.Clone(body.Bytes())
+		}
+		body.Reset()
+	}
+	for _, line := range strings.SplitAfter(string(content), "\n") {
+		if rest, ok := strings.CutPrefix(strings.TrimRight(line, "\n"), archiveMarker); ok {
+			if next, closed := strings.CutSuffix(rest, " -->"); closed {
+				flush()
+				id = next
+				continue
+			}
+		}
+		body.WriteString(line)
+	}
+	flush()
+	return files, nil
+}
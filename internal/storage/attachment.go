@@ -0,0 +1,73 @@
+package storage
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/abatilo/bits/internal/task"
+)
+
+const attachmentsDir = "attachments"
+
+// attachmentDir returns the directory holding a task's attachments.
+func (s *Store) attachmentDir(id string) string {
+	return filepath.Join(s.basePath, attachmentsDir, id)
+}
+
+// AttachmentPath returns the absolute path of one of a task's attachments.
+func (s *Store) AttachmentPath(id, name string) (string, error) {
+	return filepath.Abs(filepath.Join(s.attachmentDir(id), filepath.Base(name)))
+}
+
+// Attach copies the file at src into the task's attachments directory under
+// its base name, records the name on the task, and saves it. An attachment
+// with the same name is refused rather than overwritten.
+func (s *Store) Attach(t *task.Task, src string) (string, error) {
+	name := filepath.Base(src)
+	info, err := os.Stat(src)
+	if err != nil {
+		return "", err
+	}
+	if !info.Mode().IsRegular() {
+		return "", NotAFileError{Path: src}
+	}
+	if slices.Contains(t.Attachments, name) {
+		return "", AttachmentExistsError{ID: t.ID, Name: name}
+	}
+
+	dst, err := s.AttachmentPath(t.ID, name)
+	if err != nil {
+		return "", err
+	}
+	if s.DryRun() {
+		s.skip("copy %s to %s", src, dst)
+	} else if err = s.copyAttachment(src, dst); err != nil {
+		return "", err
+	}
+	t.Attachments = append(t.Attachments, name)
+	return dst, s.Save(t)
+}
+
+// copyAttachment copies src to dst, creating the attachments directory.
+func (s *Store) copyAttachment(src, dst string) error {
+	if err := s.mkdirAll(filepath.Dir(dst)); err != nil {
+		return err
+	}
+	in, err := os.Open(src) //nolint:gosec // G304: the user chose the file to attach
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := s.createFile(dst)
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(out, in); err != nil {
+		_ = out.Close()
+		return err
+	}
+	return out.Close()
+}
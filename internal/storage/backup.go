@@ -0,0 +1,398 @@
+package storage
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	backupDir    = ".backups"
+	backupPrefix = "bits-"
+	backupExt    = ".tar.gz"
+	backupReason = "backup"
+)
+
+// DefaultBackupDir returns the default backup location for the store:
+// $XDG_STATE_HOME/bits/backups/<store> when XDG_STATE_HOME is set, otherwise a
+// directory beside the store rather than inside it, so deleting the task
+// directory doesn't take its backups with it. An in-repo store's backups are
+// kept under the data root, named like the project's store there, so they
+// stay out of the repository.
+func (s *Store) DefaultBackupDir() string {
+	parent, name := filepath.Dir(s.basePath), filepath.Base(s.basePath)
+	if s.local {
+		name = StoreKey(parent)
+		if root, err := DataRoot(); err == nil {
+			parent = root
+		}
+	}
+	if state := os.Getenv("XDG_STATE_HOME"); state != "" {
+		return filepath.Join(state, xdgAppDir, "backups", name)
+	}
+	return filepath.Join(parent, backupDir, name)
+}
+
+// Backup writes a compressed archive of the store's task files, their
+// attachments, and the bundles Compact archived old tasks to into dir and
+// removes the oldest archives so at most keep remain (keep <= 0 keeps all). In
+// dry-run mode it only describes the backup it would write.
+func (s *Store) Backup(dir string, keep int) (*Snapshot, error) {
+	if err := s.EnsureInitialized(); err != nil {
+		return nil, err
+	}
+	ids, err := s.AllIDs()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC()
+	name := backupPrefix + now.Format(snapshotTimeLayout) + backupExt
+	backup := &Snapshot{Name: name, Reason: backupReason, CreatedAt: now, Tasks: len(ids)}
+	if s.DryRun() {
+		s.skip("back up %d task(s) to %s", len(ids), filepath.Join(dir, name))
+		return backup, nil
+	}
+	if err = s.mkdirAll(dir); err != nil {
+		return nil, err
+	}
+	if err = s.writeBackup(filepath.Join(dir, name), ids); err != nil {
+		return nil, err
+	}
+
+	if keep > 0 {
+		var backups []Snapshot
+		backups, err = ListBackups(dir)
+		if err != nil {
+			return nil, err
+		}
+		for i := keep; i < len(backups); i++ {
+			if err = os.Remove(filepath.Join(dir, backups[i].Name)); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return backup, nil
+}
+
+// writeBackup creates the tar.gz archive at path, writing to a temporary file
+// first so an interrupted backup never leaves a truncated archive behind.
+func (s *Store) writeBackup(path string, ids map[string]bool) error {
+	tmp := path + ".tmp"
+	f, err := s.createFile(tmp)
+	if err != nil {
+		return err
+	}
+	gz := gzip.NewWriter(f)
+	tw := tar.NewWriter(gz)
+
+	names := make([]string, 0, len(ids))
+	for id := range ids {
+		names = append(names, id+fileExt)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		id := strings.TrimSuffix(name, fileExt)
+		var content []byte
+		if content, err = s.fullContent(s.taskPath(id)); err != nil {
+			break
+		}
+		if err = addTarFile(tw, name, content, s.fileMode); err != nil {
+			break
+		}
+		if err = s.addAttachments(tw, id, content); err != nil {
+			break
+		}
+	}
+	if err == nil {
+		err = s.addArchive(tw)
+	}
+	if err == nil {
+		err = tw.Close()
+	}
+	if err == nil {
+		err = gz.Close()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// addAttachments writes a task's attachments to the archive under
+// attachments/<id>/.
+func (s *Store) addAttachments(tw *tar.Writer, id string, content []byte) error {
+	t, err := ParseMarkdown(content)
+	if err != nil {
+		return err
+	}
+	for _, name := range t.Attachments {
+		data, readErr := os.ReadFile(filepath.Join(s.attachmentDir(id), name))
+		if os.IsNotExist(readErr) {
+			continue
+		}
+		if readErr != nil {
+			return readErr
+		}
+		if err = addTarFile(tw, path.Join(attachmentsDir, id, name), data, s.fileMode); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addArchive writes the archive bundles to the archive under archive/.
+func (s *Store) addArchive(tw *tar.Writer) error {
+	bundles, err := filepath.Glob(filepath.Join(s.basePath, archiveDir, "*"+fileExt))
+	if err != nil {
+		return err
+	}
+	sort.Strings(bundles)
+	for _, bundle := range bundles {
+		data, readErr := os.ReadFile(bundle) //nolint:gosec // G304: path is within the store
+		if readErr != nil {
+			return readErr
+		}
+		if err = addTarFile(tw, path.Join(archiveDir, filepath.Base(bundle)), data, s.fileMode); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func addTarFile(tw *tar.Writer, name string, content []byte, mode fs.FileMode) error {
+	hdr := &tar.Header{
+		Name:    name,
+		Mode:    int64(mode),
+		Size:    int64(len(content)),
+		ModTime: time.Now().UTC(),
+	}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	_, err := tw.Write(content)
+	return err
+}
+
+// ListBackups returns the backups in dir, newest first.
+func ListBackups(dir string) ([]Snapshot, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var backups []Snapshot
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, backupPrefix) || !strings.HasSuffix(name, backupExt) {
+			continue
+		}
+		stamp := strings.TrimSuffix(strings.TrimPrefix(name, backupPrefix), backupExt)
+		createdAt, parseErr := time.Parse(snapshotTimeLayout, stamp)
+		if parseErr != nil {
+			continue
+		}
+		files, readErr := readBackup(filepath.Join(dir, name))
+		if readErr != nil {
+			return nil, readErr
+		}
+		tasks := 0
+		for file := range files {
+			if isBackupTask(file) {
+				tasks++
+			}
+		}
+		backups = append(backups, Snapshot{Name: name, Reason: backupReason, CreatedAt: createdAt, Tasks: tasks})
+	}
+
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].Name > backups[j].Name
+	})
+	return backups, nil
+}
+
+// RestoreBackup replaces the store's tasks and their attachments with the
+// contents of a backup archive, and puts back the archive bundles it holds.
+// Bundles the backup doesn't have are kept, so restoring never loses history.
+// The current state is snapshotted first.
+func (s *Store) RestoreBackup(dir, name string) error {
+	files, err := readBackup(filepath.Join(dir, filepath.Base(name)))
+	if os.IsNotExist(err) {
+		return BackupNotFoundError{Name: name}
+	}
+	if err != nil {
+		return err
+	}
+
+	if _, err = s.Snapshot("backup-restore"); err != nil {
+		return err
+	}
+
+	restored := make(map[string]bool)
+	for file, content := range files {
+		if !isBackupTask(file) {
+			continue
+		}
+		t, parseErr := ParseMarkdown(content)
+		if parseErr != nil {
+			return parseErr
+		}
+		if t.ID+fileExt != file {
+			continue // Its ID would put the task, and its attachments, somewhere else
+		}
+		if err = s.Rewrite(t); err != nil {
+			return err
+		}
+		restored[t.ID] = true
+	}
+
+	ids, err := s.AllIDs()
+	if err != nil {
+		return err
+	}
+	for id := range ids {
+		if !restored[id] {
+			if err = s.Delete(id); err != nil {
+				return err
+			}
+		}
+	}
+	if err = s.restoreAttachments(files, restored); err != nil {
+		return err
+	}
+	return s.restoreArchive(files)
+}
+
+// restoreArchive writes the archive bundles in the backup over the store's.
+func (s *Store) restoreArchive(files map[string][]byte) error {
+	for file, content := range files {
+		dir, name := path.Split(file)
+		if dir != archiveDir+"/" || !strings.HasSuffix(name, fileExt) {
+			continue
+		}
+		if s.DryRun() {
+			s.skip("restore archive bundle %s", name)
+			continue
+		}
+		if err := s.mkdirAll(filepath.Join(s.basePath, archiveDir)); err != nil {
+			return err
+		}
+		if err := s.writeFile(filepath.Join(s.basePath, archiveDir, name), content); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// restoreAttachments replaces the attachments of restored tasks with the ones
+// in the backup.
+func (s *Store) restoreAttachments(files map[string][]byte, restored map[string]bool) error {
+	if s.DryRun() {
+		s.skip("restore attachments of %d task(s)", len(restored))
+		return nil
+	}
+	for id := range restored {
+		if err := os.RemoveAll(s.attachmentDir(id)); err != nil {
+			return err
+		}
+	}
+	for file, content := range files {
+		// Attachments are stored as attachments/<id>/<name>
+		parts := strings.Split(file, "/")
+		if len(parts) != 3 || parts[0] != attachmentsDir || !restored[parts[1]] { //nolint:mnd // three path parts
+			continue
+		}
+		dir := s.attachmentDir(parts[1])
+		target := filepath.Join(dir, parts[2])
+		if !within(dir, target) {
+			continue
+		}
+		if err := s.mkdirAll(dir); err != nil {
+			return err
+		}
+		if err := s.writeFile(target, content); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// within reports whether target, once cleaned, is a file inside dir.
+func within(dir, target string) bool {
+	rel, err := filepath.Rel(dir, target)
+	return err == nil && rel != "." && filepath.IsLocal(rel)
+}
+
+// isBackupTask reports whether an archive entry is a task file rather than an
+// attachment.
+func isBackupTask(name string) bool {
+	return !strings.Contains(name, "/") && strings.HasSuffix(name, fileExt)
+}
+
+// readBackup returns the files in a backup archive keyed by their cleaned
+// slash-separated path. Entries that would escape the store are dropped.
+func readBackup(archive string) (map[string][]byte, error) {
+	f, err := os.Open(archive) //nolint:gosec // G304: path is derived from the backup directory
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+
+	files := make(map[string][]byte)
+	tr := tar.NewReader(gz)
+	for {
+		hdr, nextErr := tr.Next()
+		if errors.Is(nextErr, io.EOF) {
+			return files, nil
+		}
+		if nextErr != nil {
+			return nil, nextErr
+		}
+		name, ok := backupEntryName(hdr.Name)
+		if hdr.Typeflag != tar.TypeReg || !ok {
+			continue
+		}
+		content, readErr := io.ReadAll(tr)
+		if readErr != nil {
+			return nil, readErr
+		}
+		files[name] = content
+	}
+}
+
+// backupEntryName returns the cleaned name of a backup archive entry, or
+// false if the entry is absolute, has a ".." part, or uses backslashes, which
+// Windows would take as separators.
+func backupEntryName(raw string) (string, bool) {
+	name := strings.TrimPrefix(raw, "./")
+	if name == "" || path.IsAbs(name) || filepath.IsAbs(name) || strings.Contains(name, `\`) {
+		return "", false
+	}
+	if slices.Contains(strings.Split(name, "/"), "..") {
+		return "", false
+	}
+	return path.Clean(name), true
+}
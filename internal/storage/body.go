@@ -0,0 +1,84 @@
+package storage
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+
+	"github.com/abatilo/bits/internal/task"
+)
+
+const (
+	bodiesDir         = "bodies"
+	bodyFileThreshold = 64 << 10 // Descriptions larger than this (64 KiB) move to a sidecar
+)
+
+// bodyPath returns the sidecar holding a task's oversized description.
+func (s *Store) bodyPath(id string) string {
+	return filepath.Join(s.basePath, bodiesDir, id+fileExt)
+}
+
+// splitBody returns the version of t to write to its task file. A description
+// over the threshold is written to a sidecar and replaced by a body_file
+// reference, so the task file stays small and quick to parse. A smaller
+// description goes back inline and any sidecar is removed. A task read without
+// its sidecar (BodyFile set, no description) keeps its reference.
+func (s *Store) splitBody(t *task.Task) (*task.Task, error) {
+	if t.Description == "" && t.BodyFile != "" {
+		return t, nil
+	}
+	if len(t.Description) <= bodyFileThreshold {
+		if err := os.Remove(s.bodyPath(t.ID)); err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		if t.BodyFile == "" {
+			return t, nil
+		}
+		inline := *t
+		inline.BodyFile = ""
+		return &inline, nil
+	}
+
+	if err := s.mkdirAll(filepath.Join(s.basePath, bodiesDir)); err != nil {
+		return nil, err
+	}
+	if err := s.writeFile(s.bodyPath(t.ID), []byte(t.Description+"\n")); err != nil {
+		return nil, err
+	}
+	split := *t
+	split.BodyFile = bodiesDir + "/" + t.ID + fileExt
+	split.Description = ""
+	return &split, nil
+}
+
+// readBody fills in the description of a task read from a file that
+// references a sidecar.
+func (s *Store) readBody(t *task.Task) error {
+	if t.BodyFile == "" {
+		return nil
+	}
+	body, err := os.ReadFile(filepath.Join(s.basePath, bodiesDir, filepath.Base(t.BodyFile)))
+	if err != nil {
+		return err
+	}
+	t.Description = string(bytes.TrimSpace(body))
+	t.BodyFile = ""
+	return nil
+}
+
+// fullContent returns a task file with any sidecar description inlined, for
+// copies (snapshots, backups, archives, sync) that must stand on their own.
+func (s *Store) fullContent(path string) ([]byte, error) {
+	content, err := os.ReadFile(path) //nolint:gosec // G304: path is within the store
+	if err != nil || !bytes.Contains(content, []byte("\nbody_file: ")) {
+		return content, err
+	}
+	t, err := ParseMarkdown(content)
+	if err != nil || t.BodyFile == "" {
+		return content, nil //nolint:nilerr // Malformed files are copied as they are
+	}
+	if err = s.readBody(t); err != nil {
+		return nil, err
+	}
+	return SerializeMarkdown(t)
+}
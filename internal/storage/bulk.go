@@ -0,0 +1,106 @@
+package storage
+
+import (
+	"errors"
+	"slices"
+
+	"github.com/abatilo/bits/internal/task"
+)
+
+// SelectTasks picks the tasks a bulk command acts on from tasks, keyed by ID:
+// those named by ids, in order and without repeats, or with a match function
+// every task in statuses it matches, most urgent first.
+func SelectTasks(
+	tasks map[string]*task.Task, ids []string, statuses StatusFilter, match func(*task.Task) bool,
+) ([]*task.Task, error) {
+	var picked []*task.Task
+	if match == nil {
+		for _, id := range ids {
+			t, ok := tasks[id]
+			if !ok {
+				return nil, TaskNotFoundError{ID: id}
+			}
+			if !slices.Contains(picked, t) {
+				picked = append(picked, t)
+			}
+		}
+		return picked, nil
+	}
+
+	for _, t := range tasks {
+		if statuses.Matches(t.Status) && match(t) {
+			picked = append(picked, t)
+		}
+	}
+	sortByPriority(picked)
+	return picked, nil
+}
+
+// SaveAll saves tasks together, all or nothing: a snapshot taken first under
+// reason is restored if a save fails, and the journal entry being recorded
+// drops the batch's changes. The index is written once for the lot.
+func (s *Store) SaveAll(reason string, tasks []*task.Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+	snap, err := s.Snapshot(reason)
+	if err != nil {
+		return err
+	}
+	journaled := s.journalChanges()
+	err = s.batchWrites(func() error {
+		for _, t := range tasks {
+			if saveErr := s.Save(t); saveErr != nil {
+				return saveErr
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return errors.Join(err, s.rollback(snap.Name, journaled))
+	}
+	return nil
+}
+
+// RemoveAll removes tasks and their references together, all or nothing: a
+// snapshot taken first is restored if a removal fails, as in SaveAll. The
+// index is written once for the lot.
+func (s *Store) RemoveAll(ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	snap, err := s.Snapshot("rm")
+	if err != nil {
+		return err
+	}
+	journaled := s.journalChanges()
+	err = s.batchWrites(func() error {
+		for _, id := range ids {
+			if rmErr := s.RemoveDependency(id); rmErr != nil {
+				return rmErr
+			}
+			if rmErr := s.Delete(id); rmErr != nil {
+				return rmErr
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return errors.Join(err, s.rollback(snap.Name, journaled))
+	}
+	return nil
+}
+
+// rollback restores the snapshot a failed batch took and puts back the
+// journal changes recorded before the batch began, so the entry doesn't
+// carry the batch's writes or the restore's. If the restore fails too, the
+// entry keeps them, since the store still has them.
+func (s *Store) rollback(snap string, journaled []JournalChange) error {
+	if err := s.RestoreSnapshot(snap); err != nil {
+		return err
+	}
+	if s.journal != nil {
+		s.journal.Changes = journaled
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package storage
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/abatilo/bits/internal/task"
+)
+
+// CloseFromTrailers closes, as actor, every open or active task named in the
+// Closes-Bits trailer values of the commit sha, "<id> [reason]" each, and
+// records the commit on it. A value without a reason closes the task with
+// "Closed by commit <sha>"; values naming closed or unknown tasks, which may
+// belong to another store, are skipped. A non-nil check gates each close:
+// a task it returns an error for is left alone, and the note it returns is
+// recorded with the close. It returns the tasks closed.
+func (s *Store) CloseFromTrailers(
+	sha string, values []string, actor string, check func(*task.Task) (string, error),
+) ([]*task.Task, error) {
+	var closed []*task.Task
+	for _, value := range values {
+		id, reason, _ := strings.Cut(value, " ")
+		t, err := s.Load(id)
+		if err != nil || t.Status == task.StatusClosed {
+			continue
+		}
+
+		var note string
+		if check != nil {
+			if note, err = check(t); err != nil {
+				continue
+			}
+		}
+
+		reason = strings.TrimSpace(reason)
+		if reason == "" {
+			reason = fmt.Sprintf("Closed by commit %.7s", sha)
+		}
+		t.Close(time.Now().UTC(), reason, actor)
+		t.Annotate(note)
+		if !slices.Contains(t.Commits, sha) {
+			t.Commits = append(t.Commits, sha)
+		}
+		if err = s.Save(t); err != nil {
+			return closed, err
+		}
+		closed = append(closed, t)
+	}
+	return closed, nil
+}
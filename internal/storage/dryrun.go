@@ -0,0 +1,23 @@
+package storage
+
+import (
+	"fmt"
+	"io"
+)
+
+// SetDryRun makes the store skip writes to tasks and snapshots, describing
+// each one to w instead. Reads and validation are unaffected, so commands fail
+// exactly as they would for real. A nil writer turns dry-run off.
+func (s *Store) SetDryRun(w io.Writer) {
+	s.dryRun = w
+}
+
+// DryRun reports whether the store is skipping writes.
+func (s *Store) DryRun() bool {
+	return s.dryRun != nil
+}
+
+// skip reports a write that dry-run mode skipped.
+func (s *Store) skip(format string, args ...any) {
+	_, _ = fmt.Fprintf(s.dryRun, "Dry run: would "+format+"\n", args...)
+}
@@ -0,0 +1,29 @@
+package storage
+
+import "github.com/abatilo/bits/internal/task"
+
+// CheckEdit reports a ReadOnlyFieldError if edited has a different ID or
+// status than orig: the ID names the file, and claim, release, and close are
+// the only ways to change the status.
+func CheckEdit(orig, edited *task.Task) error {
+	if edited.ID != orig.ID {
+		return ReadOnlyFieldError{Field: "id"}
+	}
+	if edited.Status != orig.Status {
+		return ReadOnlyFieldError{Field: "status"}
+	}
+	return nil
+}
+
+// Update saves edited in place of the stored task id, checked against it as
+// CheckEdit does.
+func (s *Store) Update(id string, edited *task.Task) error {
+	orig, err := s.Load(id)
+	if err != nil {
+		return err
+	}
+	if err = CheckEdit(orig, edited); err != nil {
+		return err
+	}
+	return s.Save(edited)
+}
@@ -17,3 +17,154 @@ type NotInRepoError struct{}
 func (e NotInRepoError) Error() string {
 	return "not in a git repository (bits requires a project root)"
 }
+
+// SyncNotConfiguredError indicates sync was requested without a remote.
+type SyncNotConfiguredError struct{}
+
+func (e SyncNotConfiguredError) Error() string {
+	return "sync remote not configured (run 'bits sync --remote <path>')"
+}
+
+// SyncUnreachableError indicates the sync remote could not be reached.
+// Queued operations are kept and replayed on the next sync.
+type SyncUnreachableError struct {
+	Remote string
+	Queued int
+}
+
+func (e SyncUnreachableError) Error() string {
+	return fmt.Sprintf("sync remote %s is unreachable; %d operation(s) queued", e.Remote, e.Queued)
+}
+
+// SnapshotNotFoundError indicates the named snapshot doesn't exist.
+type SnapshotNotFoundError struct {
+	Name string
+}
+
+func (e SnapshotNotFoundError) Error() string {
+	return fmt.Sprintf("snapshot not found: %s", e.Name)
+}
+
+// BackupNotFoundError indicates the named backup archive doesn't exist.
+type BackupNotFoundError struct {
+	Name string
+}
+
+func (e BackupNotFoundError) Error() string {
+	return fmt.Sprintf("backup not found: %s", e.Name)
+}
+
+// NothingToMigrateError indicates there is no legacy data directory to migrate.
+type NothingToMigrateError struct {
+	Path string
+}
+
+func (e NothingToMigrateError) Error() string {
+	return fmt.Sprintf("nothing to migrate: %s does not exist", e.Path)
+}
+
+// MigrationTargetExistsError indicates the migration destination already exists.
+type MigrationTargetExistsError struct {
+	Path string
+}
+
+func (e MigrationTargetExistsError) Error() string {
+	return fmt.Sprintf("migration target %s already exists; merge it manually", e.Path)
+}
+
+// UnsupportedSchemaError indicates a task file written by a newer bits.
+type UnsupportedSchemaError struct {
+	Schema    int
+	Supported int
+}
+
+func (e UnsupportedSchemaError) Error() string {
+	return fmt.Sprintf("task file schema %d is newer than this bits supports (%d); upgrade bits", e.Schema, e.Supported)
+}
+
+// NotAFileError indicates an attachment source that isn't a regular file.
+type NotAFileError struct {
+	Path string
+}
+
+func (e NotAFileError) Error() string {
+	return fmt.Sprintf("%s is not a regular file", e.Path)
+}
+
+// AttachmentExistsError indicates the task already has an attachment with the name.
+type AttachmentExistsError struct {
+	ID   string
+	Name string
+}
+
+func (e AttachmentExistsError) Error() string {
+	return fmt.Sprintf("task %s already has an attachment named %s", e.ID, e.Name)
+}
+
+// NotInitializedError indicates an operation that needs an existing store.
+type NotInitializedError struct {
+	Path string
+}
+
+func (e NotInitializedError) Error() string {
+	return fmt.Sprintf("no bits store at %s", e.Path)
+}
+
+// NothingToUndoError indicates the journal has no entry left to undo.
+type NothingToUndoError struct{}
+
+func (e NothingToUndoError) Error() string {
+	return "nothing to undo"
+}
+
+// JournalEntryNotFoundError indicates no journal entry has the number.
+type JournalEntryNotFoundError struct {
+	Seq int
+}
+
+func (e JournalEntryNotFoundError) Error() string {
+	return fmt.Sprintf("no journal entry #%d (see 'bits history')", e.Seq)
+}
+
+// AlreadyUndoneError indicates a journal entry was undone already.
+type AlreadyUndoneError struct {
+	Seq int
+}
+
+func (e AlreadyUndoneError) Error() string {
+	return fmt.Sprintf("journal entry #%d was already undone", e.Seq)
+}
+
+// UndoConflictError indicates a task changed after the entry being undone.
+type UndoConflictError struct {
+	Seq int
+	ID  string
+}
+
+func (e UndoConflictError) Error() string {
+	return fmt.Sprintf("task %s changed after journal entry #%d; undo the later entries first or use --force",
+		e.ID, e.Seq)
+}
+
+// ReadOnlyFieldError indicates an edit changed a field that can't be edited.
+type ReadOnlyFieldError struct {
+	Field string
+}
+
+func (e ReadOnlyFieldError) Error() string {
+	return e.Field + " cannot be edited"
+}
+
+// InvalidPatternError indicates a search query isn't a valid regular expression.
+type InvalidPatternError struct {
+	Pattern string
+	Err     error
+}
+
+func (e InvalidPatternError) Error() string {
+	return fmt.Sprintf("invalid regular expression %q: %v", e.Pattern, e.Err)
+}
+
+func (e InvalidPatternError) Unwrap() error {
+	return e.Err
+}
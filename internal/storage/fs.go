@@ -0,0 +1,59 @@
+package storage
+
+import (
+	"io/fs"
+	"os"
+)
+
+// SetModes sets the permissions used for directories and files the store creates.
+func (s *Store) SetModes(dirMode, fileMode fs.FileMode) {
+	s.dirMode = dirMode
+	s.fileMode = fileMode
+}
+
+// mkdirAll creates a directory with the store's directory mode. The mode is
+// applied explicitly so the process umask can't widen or narrow it.
+func (s *Store) mkdirAll(path string) error {
+	if err := os.MkdirAll(path, s.dirMode); err != nil { //nolint:gosec // G301: mode is user-configurable
+		return err
+	}
+	return os.Chmod(path, s.dirMode)
+}
+
+// writeFile writes a file with the store's file mode, applying the mode even
+// when the file already existed or the umask masked it on creation.
+func (s *Store) writeFile(path string, content []byte) error {
+	if err := os.WriteFile(path, content, s.fileMode); err != nil { //nolint:gosec // G306: mode is user-configurable
+		return err
+	}
+	return os.Chmod(path, s.fileMode)
+}
+
+// appendFile appends content to a file, creating it with the store's file mode.
+func (s *Store) appendFile(path string, content []byte) error {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, s.fileMode) //nolint:gosec // G302,G304: store path, configurable mode
+	if err != nil {
+		return err
+	}
+	if _, err = f.Write(content); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+	return os.Chmod(path, s.fileMode)
+}
+
+// createFile creates (or truncates) a file for writing with the store's file mode.
+func (s *Store) createFile(path string) (*os.File, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, s.fileMode) //nolint:gosec // G302,G304: store path, configurable mode
+	if err != nil {
+		return nil, err
+	}
+	if err = f.Chmod(s.fileMode); err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	return f, nil
+}
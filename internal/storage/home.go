@@ -0,0 +1,125 @@
+package storage
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+const xdgAppDir = "bits"
+
+// LegacyDataRoot returns the original data root, ~/.bits.
+func LegacyDataRoot() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, bitsDir), nil
+}
+
+// XDGDataRoot returns the XDG data root: $XDG_DATA_HOME/bits, falling back
+// to ~/.local/share/bits.
+func XDGDataRoot() (string, error) {
+	if xdg := os.Getenv("XDG_DATA_HOME"); xdg != "" {
+		return filepath.Join(xdg, xdgAppDir), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".local", "share", xdgAppDir), nil
+}
+
+// DataRoot returns the directory holding every project's task directory.
+// An existing XDG data root wins, then an existing ~/.bits (until it is moved
+// with MigrateHome). New installs use the XDG root when XDG_DATA_HOME is set
+// and ~/.bits otherwise.
+func DataRoot() (string, error) {
+	xdgRoot, err := XDGDataRoot()
+	if err != nil {
+		return "", err
+	}
+	legacy, err := LegacyDataRoot()
+	if err != nil {
+		return "", err
+	}
+
+	switch {
+	case dirExists(xdgRoot):
+		return xdgRoot, nil
+	case dirExists(legacy):
+		return legacy, nil
+	case os.Getenv("XDG_DATA_HOME") != "":
+		return xdgRoot, nil
+	default:
+		return legacy, nil
+	}
+}
+
+// MigrateHome moves ~/.bits to the XDG data root. It returns the source and
+// destination, and refuses to overwrite an existing destination.
+func MigrateHome() (string, string, error) {
+	legacy, err := LegacyDataRoot()
+	if err != nil {
+		return "", "", err
+	}
+	xdgRoot, err := XDGDataRoot()
+	if err != nil {
+		return "", "", err
+	}
+	if !dirExists(legacy) {
+		return legacy, xdgRoot, NothingToMigrateError{Path: legacy}
+	}
+	if _, statErr := os.Stat(xdgRoot); statErr == nil {
+		return legacy, xdgRoot, MigrationTargetExistsError{Path: xdgRoot}
+	}
+
+	//nolint:gosec // G301: 0755 is appropriate for the XDG data directory
+	if err = os.MkdirAll(filepath.Dir(xdgRoot), 0o755); err != nil {
+		return legacy, xdgRoot, err
+	}
+	if err = os.Rename(legacy, xdgRoot); err == nil {
+		return legacy, xdgRoot, nil
+	}
+
+	// Rename fails across filesystems; fall back to copy and remove
+	if err = copyTree(legacy, xdgRoot); err != nil {
+		return legacy, xdgRoot, err
+	}
+	return legacy, xdgRoot, os.RemoveAll(legacy)
+}
+
+// copyTree recursively copies a directory, preserving permissions.
+func copyTree(src, dst string) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return os.MkdirAll(target, info.Mode().Perm())
+		}
+		if !d.Type().IsRegular() {
+			return nil // Sockets such as a daemon's are not data
+		}
+		content, err := os.ReadFile(path) //nolint:gosec // G304: path is within the data root
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(target, content, info.Mode().Perm())
+	})
+}
+
+func dirExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
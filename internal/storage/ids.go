@@ -0,0 +1,39 @@
+package storage
+
+// SetMinIDLength sets how many characters new task IDs start with; 0 uses
+// task.MinIDLength. IDs still grow when shorter ones are taken.
+func (s *Store) SetMinIDLength(n int) {
+	s.minIDLength = n
+}
+
+// takenIDs returns the IDs a new task must avoid besides those with a task
+// file, which are checked with a stat rather than by listing the store: the
+// archived tasks, and every ID NewTask has handed out, so tasks staged
+// together by a batch or import never share one. The set is built once per
+// store, making each add constant time however large the store grows.
+func (s *Store) takenIDs() (map[string]bool, error) {
+	if s.taken != nil {
+		return s.taken, nil
+	}
+	taken, err := s.archivedIDs()
+	if err != nil {
+		return nil, err
+	}
+	s.taken = taken
+	return taken, nil
+}
+
+// ReserveID reports whether id is free for a task, neither in the store, the
+// archive, nor handed out already, and if so takes it so nothing else does.
+// Importers use it to keep the IDs of the tasks they recreate.
+func (s *Store) ReserveID(id string) (bool, error) {
+	taken, err := s.takenIDs()
+	if err != nil {
+		return false, err
+	}
+	if taken[id] || s.Exists(id) {
+		return false, nil
+	}
+	taken[id] = true
+	return true, nil
+}
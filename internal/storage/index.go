@@ -0,0 +1,398 @@
+package storage
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/abatilo/bits/internal/task"
+)
+
+const indexFile = "index.json"
+
+// index is the persisted dependency index for a store.
+// It records each task's outgoing edges so the reverse mapping can be
+// maintained incrementally on save and delete without rescanning the store,
+// and each task's status so counts don't require parsing task files. Files
+// stamps every task file as the index last saw it, so changes made outside
+// bits (hand edits, git pulls, syncs) are noticed and the index rebuilt. Dirs
+// stamps the two task directories, which is all an unchanged store needs to
+// check.
+type index struct {
+	Tasks      map[string]indexEntry `json:"tasks"`
+	Dependents map[string][]string   `json:"dependents"`
+	Files      map[string]fileStamp  `json:"files"`
+	Dirs       map[string]fileStamp  `json:"dirs,omitempty"`
+}
+
+// indexEntry is the per-task portion of the index.
+type indexEntry struct {
+	Status    task.Status `json:"status"`
+	DependsOn []string    `json:"depends_on,omitempty"` // Dependencies, then typed links
+	Parent    string      `json:"parent,omitempty"`
+	Checksum  string      `json:"checksum"`
+}
+
+// fileStamp is the size and modification time of a task file.
+type fileStamp struct {
+	Size    int64 `json:"size"`
+	ModTime int64 `json:"mtime"` // Unix nanoseconds
+}
+
+func newIndex() *index {
+	return &index{
+		Tasks:      make(map[string]indexEntry),
+		Dependents: make(map[string][]string),
+		Files:      make(map[string]fileStamp),
+	}
+}
+
+// checksum returns the hex-encoded SHA-256 of a task file's content.
+func checksum(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// indexPath returns the full path to the index file.
+func (s *Store) indexPath() string {
+	return filepath.Join(s.basePath, indexFile)
+}
+
+// loadIndex returns the cached index, reading it from disk. It is rebuilt
+// from the task files when it is missing or unreadable, or when the files
+// have changed since it was saved; the rebuilt index keeps the checksums of
+// the old one, so Verify still reports the changes. The files are only
+// stamped one by one when a task directory has changed.
+func (s *Store) loadIndex() (*index, error) {
+	if s.idx != nil {
+		return s.idx, nil
+	}
+
+	idx := s.readIndex()
+	if idx != nil {
+		dirs, err := s.dirStamps()
+		if err != nil {
+			return nil, err
+		}
+		if idx.Dirs != nil && maps.Equal(dirs, idx.Dirs) {
+			s.idx = idx
+			return idx, nil
+		}
+		files, err := s.taskFiles()
+		if err != nil {
+			return nil, err
+		}
+		if maps.Equal(files, idx.Files) {
+			idx.Dirs = dirs
+			s.idx = idx
+			return idx, s.saveIndex()
+		}
+	}
+	return s.rebuildIndex(idx)
+}
+
+// readIndex returns the index saved on disk, or nil if it is missing,
+// unreadable, or incomplete.
+func (s *Store) readIndex() *index {
+	data, err := os.ReadFile(s.indexPath())
+	if err != nil {
+		return nil
+	}
+	idx := newIndex()
+	if json.Unmarshal(data, idx) != nil || idx.Tasks == nil || !idx.complete() {
+		return nil
+	}
+	if idx.Dependents == nil {
+		idx.Dependents = make(map[string][]string)
+	}
+	if idx.Files == nil {
+		idx.Files = make(map[string]fileStamp) // Saved before files were stamped
+	}
+	return idx
+}
+
+// RebuildIndex regenerates the index from the task files on disk, accepting
+// their current content as the baseline Verify compares against.
+func (s *Store) RebuildIndex() error {
+	_, err := s.rebuildIndex(nil)
+	return err
+}
+
+// rebuildIndex regenerates the index from the task files. With a baseline,
+// each task keeps the checksum the baseline recorded, and tasks it doesn't
+// know get none, so they stay modified or untracked until accepted.
+func (s *Store) rebuildIndex(baseline *index) (*index, error) {
+	dirs, err := s.dirStamps() // Stamped before reading, so a racing write is caught next time
+	if err != nil {
+		return nil, err
+	}
+	files, err := s.taskFiles()
+	if err != nil {
+		return nil, err
+	}
+	ids, err := s.AllIDs()
+	if err != nil {
+		return nil, err
+	}
+
+	idx := newIndex()
+	idx.Files = files
+	idx.Dirs = dirs
+	for id := range ids {
+		content, readErr := os.ReadFile(s.taskPath(id))
+		if readErr != nil {
+			return nil, readErr
+		}
+		t, parseErr := ParseMarkdown(content)
+		if parseErr != nil {
+			continue // Skip malformed files
+		}
+		sum := checksum(content)
+		if baseline != nil {
+			sum = baseline.Tasks[id].Checksum
+		}
+		idx.set(t, sum)
+	}
+
+	s.idx = idx
+	if err = s.saveIndex(); err != nil {
+		return nil, err
+	}
+	return idx, nil
+}
+
+// saveIndex writes the cached index to disk.
+func (s *Store) saveIndex() error {
+	data, err := json.MarshalIndent(s.idx, "", "  ")
+	if err != nil {
+		return err
+	}
+	return s.writeFile(s.indexPath(), data)
+}
+
+// indexChanged writes the cached index after a save or delete, or during
+// batchWrites leaves it for the end of the batch.
+func (s *Store) indexChanged() error {
+	if s.batch != nil {
+		s.batch.dirty = true
+		return nil
+	}
+	return s.saveIndex()
+}
+
+// writeBatch is the state of a batchWrites call.
+type writeBatch struct {
+	dirty  bool   // The index has changes to write
+	remote string // SyncRemote when the batch began
+}
+
+// batchWrites runs fn, which saves or deletes many tasks, writing the index
+// once at the end instead of after every task and reading the sync config
+// once. The index is written even if fn fails, so it matches the files fn
+// got to.
+func (s *Store) batchWrites(fn func() error) error {
+	if s.batch != nil {
+		return fn()
+	}
+	s.batch = &writeBatch{remote: s.SyncRemote()}
+	err := fn()
+	dirty := s.batch.dirty
+	s.batch = nil
+	if dirty && s.idx != nil {
+		err = errors.Join(err, s.saveIndex())
+	}
+	return err
+}
+
+// taskFiles stamps every task file in the store, keyed by its slash-separated
+// path within the store.
+func (s *Store) taskFiles() (map[string]fileStamp, error) {
+	files := make(map[string]fileStamp)
+	for _, dir := range []string{"", closedDir} {
+		entries, err := os.ReadDir(filepath.Join(s.basePath, dir))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			if entry.IsDir() || !strings.HasSuffix(entry.Name(), fileExt) {
+				continue
+			}
+			info, infoErr := entry.Info()
+			if os.IsNotExist(infoErr) {
+				continue // Removed since the directory was read
+			}
+			if infoErr != nil {
+				return nil, infoErr
+			}
+			files[filepath.ToSlash(filepath.Join(dir, entry.Name()))] = stampOf(info)
+		}
+	}
+	return files, nil
+}
+
+// dirStamps stamps the store's task directories, keyed like taskFiles. A
+// directory's stamp changes when a file in it is created, removed, or renamed
+// over, as git and most editors write; a file rewritten in place leaves it
+// alone.
+func (s *Store) dirStamps() (map[string]fileStamp, error) {
+	dirs := make(map[string]fileStamp)
+	for _, dir := range []string{"", closedDir} {
+		info, err := os.Stat(filepath.Join(s.basePath, dir))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		dirs[dir] = stampOf(info)
+	}
+	return dirs, nil
+}
+
+// stampOf returns the stamp of a file.
+func stampOf(info fs.FileInfo) fileStamp {
+	return fileStamp{Size: info.Size(), ModTime: info.ModTime().UnixNano()}
+}
+
+// restamp records the files a task now has, and the directories holding them,
+// after bits wrote or removed them.
+func (s *Store) restamp(idx *index, id string) {
+	if dirs, err := s.dirStamps(); err == nil {
+		idx.Dirs = dirs
+	}
+	for _, dir := range []string{"", closedDir} {
+		name := filepath.Join(dir, id+fileExt)
+		delete(idx.Files, filepath.ToSlash(name))
+		if info, err := os.Stat(filepath.Join(s.basePath, name)); err == nil {
+			idx.Files[filepath.ToSlash(name)] = stampOf(info)
+		}
+	}
+}
+
+// complete reports whether every entry has a status; indexes written before
+// statuses were recorded are rebuilt.
+func (idx *index) complete() bool {
+	for _, entry := range idx.Tasks {
+		if entry.Status == "" {
+			return false
+		}
+	}
+	return true
+}
+
+// set records the status, outgoing edges, and content checksum of a task,
+// updating the reverse mapping. Typed links count as edges, so removing a
+// task cleans them up like dependencies.
+func (idx *index) set(t *task.Task, sum string) {
+	id := t.ID
+	idx.unlink(id)
+	deps := t.Dependencies()
+	for _, l := range t.Links {
+		if !slices.Contains(deps, l.ID) {
+			deps = append(deps, l.ID)
+		}
+	}
+	idx.Tasks[id] = indexEntry{Status: t.Status, DependsOn: deps, Parent: t.Parent, Checksum: sum}
+	for _, depID := range deps {
+		if !slices.Contains(idx.Dependents[depID], id) {
+			idx.Dependents[depID] = append(idx.Dependents[depID], id)
+		}
+	}
+}
+
+// remove drops a task and its outgoing edges from the index.
+func (idx *index) remove(id string) {
+	idx.unlink(id)
+	delete(idx.Tasks, id)
+}
+
+// unlink removes a task's outgoing edges from the reverse mapping.
+func (idx *index) unlink(id string) {
+	for _, depID := range idx.Tasks[id].DependsOn {
+		idx.Dependents[depID] = slices.DeleteFunc(idx.Dependents[depID], func(d string) bool {
+			return d == id
+		})
+		if len(idx.Dependents[depID]) == 0 {
+			delete(idx.Dependents, depID)
+		}
+	}
+}
+
+// Dependents returns the IDs of tasks that depend on the given task, using the index.
+func (s *Store) Dependents(id string) ([]string, error) {
+	if err := s.EnsureInitialized(); err != nil {
+		return nil, err
+	}
+	idx, err := s.loadIndex()
+	if err != nil {
+		return nil, err
+	}
+	dependents := slices.Clone(idx.Dependents[id])
+	slices.Sort(dependents)
+	return dependents, nil
+}
+
+// Children returns the IDs of the subtasks of the given task whose status
+// matches filter, using the index.
+func (s *Store) Children(id string, filter StatusFilter) ([]string, error) {
+	if err := s.EnsureInitialized(); err != nil {
+		return nil, err
+	}
+	idx, err := s.loadIndex()
+	if err != nil {
+		return nil, err
+	}
+	var children []string
+	for childID, entry := range idx.Tasks {
+		if entry.Parent == id && filter.Matches(entry.Status) {
+			children = append(children, childID)
+		}
+	}
+	slices.Sort(children)
+	return children, nil
+}
+
+// Summary counts tasks by status without parsing task files.
+type Summary struct {
+	Open      int      `json:"open"`
+	Active    int      `json:"active"`
+	Closed    int      `json:"closed"`
+	ActiveIDs []string `json:"active_ids"`
+}
+
+// Summary returns task counts by status from the index.
+func (s *Store) Summary() (Summary, error) {
+	var summary Summary
+	if err := s.EnsureInitialized(); err != nil {
+		return summary, err
+	}
+	idx, err := s.loadIndex()
+	if err != nil {
+		return summary, err
+	}
+
+	summary.ActiveIDs = []string{}
+	for id, entry := range idx.Tasks {
+		switch entry.Status {
+		case task.StatusOpen:
+			summary.Open++
+		case task.StatusActive:
+			summary.Active++
+			summary.ActiveIDs = append(summary.ActiveIDs, id)
+		case task.StatusClosed:
+			summary.Closed++
+		}
+	}
+	slices.Sort(summary.ActiveIDs)
+	return summary, nil
+}
@@ -0,0 +1,241 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+)
+
+const (
+	journalFile      = "journal.jsonl"
+	journalRetention = 100 // Most recent entries kept once the journal is trimmed
+)
+
+// JournalEntry records one command's changes to the store, so it can be
+// inspected and undone. Each change keeps the task file before and after,
+// with any sidecar description inline.
+type JournalEntry struct {
+	Seq     int             `json:"seq"`
+	At      time.Time       `json:"at"`
+	Op      string          `json:"op"` // The command, such as "close" or "dep"
+	Actor   string          `json:"actor,omitempty"`
+	Undoes  int             `json:"undoes,omitempty"` // Seq of the entry an undo reverted
+	Changes []JournalChange `json:"changes"`
+	Undone  bool            `json:"-"` // Reverted by a later undo; filled in by Journal
+}
+
+// JournalChange is one task's part in a journal entry.
+type JournalChange struct {
+	ID     string `json:"id"`
+	Before string `json:"before,omitempty"` // Empty when the entry created the task
+	After  string `json:"after,omitempty"`  // Empty when the entry deleted the task
+}
+
+// TaskIDs returns the IDs of the tasks the entry changed, in order.
+func (e JournalEntry) TaskIDs() []string {
+	ids := make([]string, len(e.Changes))
+	for i, c := range e.Changes {
+		ids[i] = c.ID
+	}
+	return ids
+}
+
+func (s *Store) journalPath() string {
+	return filepath.Join(s.basePath, journalFile)
+}
+
+// BeginOp starts recording the store's changes as one journal entry for op,
+// written by EndOp. Commands call it once they hold the lock.
+func (s *Store) BeginOp(op, actor string) {
+	s.journal = &JournalEntry{Op: op, Actor: actor}
+}
+
+// EndOp appends the entry started by BeginOp to the journal, unless nothing
+// changed.
+func (s *Store) EndOp() error {
+	entry := s.journal
+	s.journal = nil
+	if entry == nil {
+		return nil
+	}
+	entry.Changes = slices.DeleteFunc(entry.Changes, func(c JournalChange) bool { return c.Before == c.After })
+	if len(entry.Changes) == 0 {
+		return nil
+	}
+
+	entries, err := s.readJournal()
+	if err != nil {
+		return err
+	}
+	entry.Seq = 1
+	if len(entries) > 0 {
+		entry.Seq = entries[len(entries)-1].Seq + 1
+	}
+	entry.At = time.Now().UTC().Truncate(time.Second)
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	if len(entries) < 2*journalRetention {
+		return s.appendFile(s.journalPath(), append(line, '\n'))
+	}
+
+	// Trim in bulk, so the journal is rewritten once per retention's worth
+	var buf bytes.Buffer
+	for _, e := range append(entries[len(entries)-journalRetention+1:], *entry) {
+		data, marshalErr := json.Marshal(e)
+		if marshalErr != nil {
+			return marshalErr
+		}
+		buf.Write(data)
+		buf.WriteByte('\n')
+	}
+	return s.writeFile(s.journalPath(), buf.Bytes())
+}
+
+// recordChange notes in the current journal entry that the task id is about
+// to become after (nil for a deletion), keeping the file it had before the
+// entry's first change to it.
+func (s *Store) recordChange(id string, after []byte) error {
+	if s.journal == nil {
+		return nil
+	}
+	for i := range s.journal.Changes {
+		if s.journal.Changes[i].ID == id {
+			s.journal.Changes[i].After = string(after)
+			return nil
+		}
+	}
+	change := JournalChange{ID: id, After: string(after)}
+	if s.Exists(id) {
+		before, err := s.fullContent(s.taskPath(id))
+		if err != nil {
+			return err
+		}
+		change.Before = string(before)
+	}
+	s.journal.Changes = append(s.journal.Changes, change)
+	return nil
+}
+
+// journalChanges returns a copy of the changes the current journal entry has
+// recorded so far, or nil outside BeginOp and EndOp.
+func (s *Store) journalChanges() []JournalChange {
+	if s.journal == nil {
+		return nil
+	}
+	return slices.Clone(s.journal.Changes)
+}
+
+// Journal returns the journal's entries, newest first, marking those a later
+// undo reverted.
+func (s *Store) Journal() ([]JournalEntry, error) {
+	entries, err := s.readJournal()
+	if err != nil {
+		return nil, err
+	}
+	undone := make(map[int]bool)
+	for _, e := range entries {
+		if e.Undoes > 0 {
+			undone[e.Undoes] = true
+		}
+	}
+	for i := range entries {
+		entries[i].Undone = undone[entries[i].Seq]
+	}
+	slices.Reverse(entries)
+	return entries, nil
+}
+
+// readJournal returns the journal's entries, oldest first. A missing journal
+// has none, and lines that don't parse, such as one cut short by a crash, are
+// skipped.
+func (s *Store) readJournal() ([]JournalEntry, error) {
+	f, err := os.Open(s.journalPath())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []JournalEntry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 64<<20) // Entries carry whole task files
+	for scanner.Scan() {
+		var e JournalEntry
+		if json.Unmarshal(scanner.Bytes(), &e) == nil {
+			entries = append(entries, e)
+		}
+	}
+	return entries, scanner.Err()
+}
+
+// Undo reverts the journal entry numbered seq, or with seq 0 the newest one
+// that isn't an undo and hasn't been undone, restoring each task it changed.
+// Unless force is set it refuses when a task has changed since, rather than
+// discard the later work. Run it inside BeginOp and EndOp, so the undo is
+// journaled too; returns the entry undone.
+func (s *Store) Undo(seq int, force bool) (*JournalEntry, error) {
+	entries, err := s.Journal()
+	if err != nil {
+		return nil, err
+	}
+	var target *JournalEntry
+	for i := range entries {
+		e := &entries[i]
+		if (seq == 0 && e.Undoes == 0 && !e.Undone) || (seq != 0 && e.Seq == seq) {
+			target = e
+			break
+		}
+	}
+	switch {
+	case target == nil && seq == 0:
+		return nil, NothingToUndoError{}
+	case target == nil:
+		return nil, JournalEntryNotFoundError{Seq: seq}
+	case target.Undone:
+		return nil, AlreadyUndoneError{Seq: target.Seq}
+	}
+
+	if !force {
+		for _, c := range target.Changes {
+			var current []byte
+			if s.Exists(c.ID) {
+				if current, err = s.fullContent(s.taskPath(c.ID)); err != nil {
+					return nil, err
+				}
+			}
+			if string(current) != c.After {
+				return nil, UndoConflictError{Seq: target.Seq, ID: c.ID}
+			}
+		}
+	}
+
+	for _, c := range slices.Backward(target.Changes) {
+		if c.Before == "" {
+			if s.Exists(c.ID) {
+				if err = s.Delete(c.ID); err != nil {
+					return nil, err
+				}
+			}
+			continue
+		}
+		t, parseErr := ParseMarkdown([]byte(c.Before))
+		if parseErr != nil {
+			return nil, parseErr
+		}
+		if err = s.Rewrite(t); err != nil {
+			return nil, err
+		}
+	}
+	if s.journal != nil {
+		s.journal.Undoes = target.Seq
+	}
+	return target, nil
+}
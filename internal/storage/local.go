@@ -0,0 +1,77 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// localGitignore is written into an in-repo store so only the task files are
+// committed: locks, the index, snapshots, sessions, and sockets are specific
+// to each clone.
+const localGitignore = `# Written by bits init --local: share the tasks, not this clone's state
+/*
+!/.gitignore
+!/*` + fileExt + `
+!/` + closedDir + `/
+!/` + archiveDir + `/
+!/` + bodiesDir + `/
+!/` + attachmentsDir + `/
+`
+
+// LocalStorePath returns where the project at projectRoot keeps an in-repo
+// store, <projectRoot>/.bits, so its tasks can be committed with the code.
+func LocalStorePath(projectRoot string) string {
+	return filepath.Join(projectRoot, bitsDir)
+}
+
+// NewLocalStore creates a Store for the current project's in-repo directory,
+// whether or not it exists yet; InitLocal creates it.
+func NewLocalStore() (*Store, error) {
+	projectRoot, err := FindProjectRoot()
+	if err != nil {
+		return nil, err
+	}
+	s := NewStoreWithPath(LocalStorePath(projectRoot))
+	s.local = true
+	return s, nil
+}
+
+// findLocalStore returns the in-repo store of the project at projectRoot, or
+// nil if it has none. A project in the home directory has ~/.bits as the
+// legacy data root rather than as its own store.
+func findLocalStore(projectRoot string) *Store {
+	path := LocalStorePath(projectRoot)
+	if legacy, err := LegacyDataRoot(); err == nil {
+		if resolved, evalErr := filepath.EvalSymlinks(legacy); evalErr == nil {
+			legacy = resolved // Project roots have their symlinks resolved
+		}
+		if legacy == path {
+			return nil
+		}
+	}
+	if !dirExists(path) {
+		return nil
+	}
+	s := NewStoreWithPath(path)
+	s.local = true
+	return s
+}
+
+// IsLocal reports whether the store lives inside its project, as set up by
+// InitLocal, rather than under the data root.
+func (s *Store) IsLocal() bool {
+	return s.local
+}
+
+// InitLocal creates the in-repo store directory along with a .gitignore that
+// keeps per-clone state out of commits. An existing .gitignore is left alone.
+func (s *Store) InitLocal() error {
+	if err := s.EnsureInitialized(); err != nil {
+		return err
+	}
+	path := filepath.Join(s.basePath, ".gitignore")
+	if _, err := os.Stat(path); err == nil || !os.IsNotExist(err) {
+		return err
+	}
+	return s.writeFile(path, []byte(localGitignore))
+}
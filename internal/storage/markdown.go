@@ -14,21 +14,53 @@ const frontmatterDelimiter = "---"
 
 // taskFrontmatter is the YAML-serializable portion of a task.
 type taskFrontmatter struct {
-	ID          string        `yaml:"id"`
-	Title       string        `yaml:"title"`
-	Status      task.Status   `yaml:"status"`
-	Priority    task.Priority `yaml:"priority"`
-	CreatedAt   string        `yaml:"created_at"`
-	ClosedAt    *string       `yaml:"closed_at,omitempty"`
-	CloseReason *string       `yaml:"close_reason,omitempty"`
-	DependsOn   []string      `yaml:"depends_on,omitempty"`
+	Schema      int              `yaml:"schema"`
+	ID          string           `yaml:"id"`
+	Title       string           `yaml:"title"`
+	Status      task.Status      `yaml:"status"`
+	Priority    task.Priority    `yaml:"priority"`
+	CreatedAt   string           `yaml:"created_at"`
+	UpdatedAt   *string          `yaml:"updated_at,omitempty"`
+	ClaimedAt   *string          `yaml:"claimed_at,omitempty"`
+	ClosedAt    *string          `yaml:"closed_at,omitempty"`
+	CloseReason *string          `yaml:"close_reason,omitempty"`
+	DueAt       *string          `yaml:"due_at,omitempty"`
+	Estimate    string           `yaml:"estimate,omitempty"`
+	SLABreached bool             `yaml:"sla_breached,omitempty"`
+	DependsOn   []string         `yaml:"depends_on,omitempty"`
+	DependsAny  [][]string       `yaml:"depends_on_any,omitempty,flow"`
+	After       []task.Hint      `yaml:"after,omitempty"`
+	Links       []task.Link      `yaml:"links,omitempty"`
+	Parent      string           `yaml:"parent,omitempty"`
+	Verify      string           `yaml:"verify,omitempty"`
+	Acceptance  []task.Criterion `yaml:"acceptance,omitempty"`
+	OnClaim     string           `yaml:"on_claim,omitempty"`
+	OnClose     string           `yaml:"on_close,omitempty"`
+	Tags        []string         `yaml:"tags,omitempty"`
+	Commits     []string         `yaml:"commits,omitempty"`
+	Branch      string           `yaml:"branch,omitempty"`
+	Key         string           `yaml:"key,omitempty"`
+	Source      task.Source      `yaml:"source,omitempty"`
+	Assignee    string           `yaml:"assignee,omitempty"`
+	LinearID    string           `yaml:"linear_id,omitempty"`
+	Attachments []string         `yaml:"attachments,omitempty"`
+	History     []task.Event     `yaml:"history,omitempty"`
+	Reminders   []task.Reminder  `yaml:"reminders,omitempty"`
+	BodyFile    string           `yaml:"body_file,omitempty"`
 }
 
-// ParseMarkdown parses a markdown file with YAML frontmatter into a Task.
+// ParseMarkdown parses a markdown file with YAML frontmatter into a Task,
+// migrating frontmatter written with an older schema.
 func ParseMarkdown(content []byte) (*task.Task, error) {
+	t, _, err := parseMarkdown(content)
+	return t, err
+}
+
+// parseMarkdown parses a task file, also returning the schema it was written with.
+func parseMarkdown(content []byte) (*task.Task, int, error) {
 	lines := strings.Split(string(content), "\n")
 	if len(lines) < 2 || strings.TrimSpace(lines[0]) != frontmatterDelimiter {
-		return nil, &parseError{"missing YAML frontmatter"}
+		return nil, 0, &parseError{"missing YAML frontmatter"}
 	}
 
 	// Find closing delimiter
@@ -40,36 +72,66 @@ func ParseMarkdown(content []byte) (*task.Task, error) {
 		}
 	}
 	if frontmatterEnd == 0 {
-		return nil, &parseError{"unclosed YAML frontmatter"}
+		return nil, 0, &parseError{"unclosed YAML frontmatter"}
 	}
 
-	// Parse YAML
+	// Parse YAML, upgrading older schemas before decoding
 	yamlContent := strings.Join(lines[1:frontmatterEnd], "\n")
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(yamlContent), &doc); err != nil {
+		return nil, 0, &parseError{"invalid YAML: " + err.Error()}
+	}
 	var fm taskFrontmatter
-	if err := yaml.Unmarshal([]byte(yamlContent), &fm); err != nil {
-		return nil, &parseError{"invalid YAML: " + err.Error()}
+	var schema int
+	if len(doc.Content) > 0 {
+		var err error
+		if schema, err = frontmatterSchema(doc.Content[0]); err != nil {
+			return nil, 0, err
+		}
+		migrate(doc.Content[0], schema, migrations())
+		if err = doc.Content[0].Decode(&fm); err != nil {
+			return nil, 0, &parseError{"invalid YAML: " + err.Error()}
+		}
 	}
 
 	// Parse timestamps
 	createdAt, err := parseTime(fm.CreatedAt)
 	if err != nil {
-		return nil, &parseError{"invalid created_at: " + err.Error()}
+		return nil, 0, &parseError{"invalid created_at: " + err.Error()}
+	}
+
+	updatedAt, err := parseOptionalTime(fm.UpdatedAt)
+	if err != nil {
+		return nil, 0, &parseError{"invalid updated_at: " + err.Error()}
+	}
+
+	claimedAt, err := parseOptionalTime(fm.ClaimedAt)
+	if err != nil {
+		return nil, 0, &parseError{"invalid claimed_at: " + err.Error()}
+	}
+
+	closedAt, err := parseOptionalTime(fm.ClosedAt)
+	if err != nil {
+		return nil, 0, &parseError{"invalid closed_at: " + err.Error()}
+	}
+
+	dueAt, err := parseOptionalTime(fm.DueAt)
+	if err != nil {
+		return nil, 0, &parseError{"invalid due_at: " + err.Error()}
 	}
 
-	var closedAt *time.Time
-	if fm.ClosedAt != nil {
-		var parsedClosedAt time.Time
-		parsedClosedAt, err = parseTime(*fm.ClosedAt)
-		if err != nil {
-			return nil, &parseError{"invalid closed_at: " + err.Error()}
+	var estimate time.Duration
+	if fm.Estimate != "" {
+		if estimate, err = time.ParseDuration(fm.Estimate); err != nil {
+			return nil, 0, &parseError{"invalid estimate: " + err.Error()}
 		}
-		closedAt = &parsedClosedAt
 	}
 
-	// Extract description (everything after frontmatter)
+	// Extract description and notes (everything after frontmatter)
 	var description string
+	var notes []task.Note
 	if frontmatterEnd+1 < len(lines) {
-		description = strings.TrimSpace(strings.Join(lines[frontmatterEnd+1:], "\n"))
+		description, notes = splitNotes(strings.TrimSpace(strings.Join(lines[frontmatterEnd+1:], "\n")))
 	}
 
 	return &task.Task{
@@ -78,27 +140,74 @@ func ParseMarkdown(content []byte) (*task.Task, error) {
 		Status:      fm.Status,
 		Priority:    fm.Priority,
 		CreatedAt:   createdAt,
+		UpdatedAt:   updatedAt,
+		ClaimedAt:   claimedAt,
 		ClosedAt:    closedAt,
 		CloseReason: fm.CloseReason,
+		DueAt:       dueAt,
+		Estimate:    estimate,
+		SLABreached: fm.SLABreached,
 		DependsOn:   fm.DependsOn,
+		DependsAny:  fm.DependsAny,
+		After:       fm.After,
+		Links:       fm.Links,
+		Parent:      fm.Parent,
+		Verify:      fm.Verify,
+		Acceptance:  fm.Acceptance,
+		OnClaim:     fm.OnClaim,
+		OnClose:     fm.OnClose,
+		Tags:        fm.Tags,
+		Commits:     fm.Commits,
+		Branch:      fm.Branch,
+		Key:         fm.Key,
+		Source:      fm.Source,
+		Assignee:    fm.Assignee,
+		LinearID:    fm.LinearID,
+		Attachments: fm.Attachments,
+		History:     fm.History,
+		Reminders:   fm.Reminders,
+		BodyFile:    fm.BodyFile,
 		Description: description,
-	}, nil
+		Notes:       notes,
+	}, schema, nil
 }
 
 // SerializeMarkdown converts a Task to markdown with YAML frontmatter.
 func SerializeMarkdown(t *task.Task) ([]byte, error) {
 	fm := taskFrontmatter{
+		Schema:      SchemaVersion,
 		ID:          t.ID,
 		Title:       t.Title,
 		Status:      t.Status,
 		Priority:    t.Priority,
 		CreatedAt:   t.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:   formatOptionalTime(t.UpdatedAt),
+		ClaimedAt:   formatOptionalTime(t.ClaimedAt),
+		ClosedAt:    formatOptionalTime(t.ClosedAt),
 		CloseReason: t.CloseReason,
+		DueAt:       formatOptionalTime(t.DueAt),
+		Estimate:    formatEstimate(t.Estimate),
+		SLABreached: t.SLABreached,
 		DependsOn:   t.DependsOn,
-	}
-	if t.ClosedAt != nil {
-		s := t.ClosedAt.Format(time.RFC3339)
-		fm.ClosedAt = &s
+		DependsAny:  t.DependsAny,
+		After:       t.After,
+		Links:       t.Links,
+		Parent:      t.Parent,
+		Verify:      t.Verify,
+		Acceptance:  t.Acceptance,
+		OnClaim:     t.OnClaim,
+		OnClose:     t.OnClose,
+		Tags:        t.Tags,
+		Commits:     t.Commits,
+		Branch:      t.Branch,
+		Key:         t.Key,
+		Source:      t.Source,
+		Assignee:    t.Assignee,
+		LinearID:    t.LinearID,
+		Attachments: t.Attachments,
+		History:     t.History,
+		Reminders:   t.Reminders,
+		BodyFile:    t.BodyFile,
 	}
 
 	var buf bytes.Buffer
@@ -120,6 +229,10 @@ func SerializeMarkdown(t *task.Task) ([]byte, error) {
 		buf.WriteString(t.Description)
 		buf.WriteString("\n")
 	}
+	if len(t.Notes) > 0 {
+		buf.WriteString("\n")
+		buf.WriteString(formatNotes(t.Notes))
+	}
 
 	return buf.Bytes(), nil
 }
@@ -133,6 +246,43 @@ func (e *parseError) Error() string {
 	return e.msg
 }
 
+// parseOptionalTime parses an optional timestamp, returning nil if unset.
+func parseOptionalTime(s *string) (*time.Time, error) {
+	if s == nil {
+		return nil, nil //nolint:nilnil // An unset timestamp is not an error
+	}
+	t, err := parseTime(*s)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// formatOptionalTime formats an optional timestamp as RFC3339, returning nil if unset.
+func formatOptionalTime(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+	s := t.Format(time.RFC3339)
+	return &s
+}
+
+// formatEstimate formats an estimate as a Go duration without trailing zero
+// units ("1h30m" rather than "1h30m0s"), returning "" if unset.
+func formatEstimate(d time.Duration) string {
+	if d == 0 {
+		return ""
+	}
+	s := d.String()
+	if strings.HasSuffix(s, "m0s") {
+		s = strings.TrimSuffix(s, "0s")
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+	return s
+}
+
 // parseTime tries to parse a time string in common formats.
 func parseTime(s string) (time.Time, error) {
 	formats := []string{
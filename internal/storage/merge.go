@@ -0,0 +1,179 @@
+package storage
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/abatilo/bits/internal/task"
+)
+
+// statusRank orders statuses by how far along the lifecycle they are.
+// When both sides of a merge change the status, the more advanced one wins.
+func statusRank(s task.Status) int {
+	switch s {
+	case task.StatusOpen:
+		return 0
+	case task.StatusActive:
+		return 1
+	case task.StatusClosed:
+		return 2 //nolint:mnd // Closed is the final lifecycle stage
+	default:
+		return -1
+	}
+}
+
+// MergeTasks performs a field-by-field three-way merge of a task.
+// base is the common ancestor (nil if both sides created the task), ours and
+// theirs are the two concurrent versions. A field changed on only one side
+// takes that side's value. When both sides changed a field, the status
+// furthest along the lifecycle wins (carrying its close metadata with it),
+// dependency lists are merged as sets, and other fields prefer ours.
+func MergeTasks(base, ours, theirs *task.Task) *task.Task {
+	if base == nil {
+		base = &task.Task{}
+	}
+
+	merged := *ours
+	merged.Title = mergeField(base.Title, ours.Title, theirs.Title)
+	merged.Priority = mergeField(base.Priority, ours.Priority, theirs.Priority)
+	merged.Description = mergeField(base.Description, ours.Description, theirs.Description)
+	merged.Branch = mergeField(base.Branch, ours.Branch, theirs.Branch)
+	merged.Verify = mergeField(base.Verify, ours.Verify, theirs.Verify)
+	merged.OnClaim = mergeField(base.OnClaim, ours.OnClaim, theirs.OnClaim)
+	merged.OnClose = mergeField(base.OnClose, ours.OnClose, theirs.OnClose)
+	merged.Key = mergeField(base.Key, ours.Key, theirs.Key)
+	merged.Parent = mergeField(base.Parent, ours.Parent, theirs.Parent)
+	merged.Source = mergeField(base.Source, ours.Source, theirs.Source)
+	merged.Assignee = mergeField(base.Assignee, ours.Assignee, theirs.Assignee)
+	merged.LinearID = mergeField(base.LinearID, ours.LinearID, theirs.LinearID)
+	merged.Estimate = mergeField(base.Estimate, ours.Estimate, theirs.Estimate)
+	merged.BodyFile = mergeField(base.BodyFile, ours.BodyFile, theirs.BodyFile)
+	merged.Acceptance = mergeCriteria(base.Acceptance, ours.Acceptance, theirs.Acceptance)
+	merged.DueAt = mergeOptionalTime(base.DueAt, ours.DueAt, theirs.DueAt)
+	merged.DependsOn = mergeSet(base.DependsOn, ours.DependsOn, theirs.DependsOn)
+	merged.DependsAny = mergeGroups(base.DependsAny, ours.DependsAny, theirs.DependsAny)
+	merged.After = mergeSet(base.After, ours.After, theirs.After)
+	merged.Links = mergeSet(base.Links, ours.Links, theirs.Links)
+	merged.Tags = mergeSet(base.Tags, ours.Tags, theirs.Tags)
+	merged.Commits = mergeSet(base.Commits, ours.Commits, theirs.Commits)
+	merged.Attachments = mergeSet(base.Attachments, ours.Attachments, theirs.Attachments)
+	merged.History = task.MergeHistory(ours.History, theirs.History)
+	merged.Notes = task.MergeNotes(ours.Notes, theirs.Notes)
+	merged.SLABreached = ours.SLABreached || theirs.SLABreached
+	merged.Reminders = mergeSet(base.Reminders, ours.Reminders, theirs.Reminders)
+
+	if ours.CreatedAt.IsZero() || (!theirs.CreatedAt.IsZero() && theirs.CreatedAt.Before(ours.CreatedAt)) {
+		merged.CreatedAt = theirs.CreatedAt
+	}
+	if ours.UpdatedAt == nil || (theirs.UpdatedAt != nil && theirs.UpdatedAt.After(*ours.UpdatedAt)) {
+		merged.UpdatedAt = theirs.UpdatedAt
+	}
+
+	statusSide := ours
+	switch {
+	case ours.Status == base.Status:
+		statusSide = theirs
+	case theirs.Status == base.Status:
+		statusSide = ours
+	case statusRank(theirs.Status) > statusRank(ours.Status):
+		statusSide = theirs
+	}
+	merged.Status = statusSide.Status
+	merged.ClaimedAt = statusSide.ClaimedAt
+	merged.ClosedAt = statusSide.ClosedAt
+	merged.CloseReason = statusSide.CloseReason
+
+	return &merged
+}
+
+// mergeField returns the three-way merge of a comparable field, preferring ours on conflict.
+func mergeField[T comparable](base, ours, theirs T) T {
+	if ours == base {
+		return theirs
+	}
+	return ours
+}
+
+// mergeOptionalTime is mergeField for optional timestamps, compared by value.
+func mergeOptionalTime(base, ours, theirs *time.Time) *time.Time {
+	same := func(a, b *time.Time) bool {
+		return a == b || (a != nil && b != nil && a.Equal(*b))
+	}
+	if same(ours, base) {
+		return theirs
+	}
+	return ours
+}
+
+// mergeGroups merges any-of dependency groups as a set of groups, where two
+// groups are the same if they have the same members in any order.
+func mergeGroups(base, ours, theirs [][]string) [][]string {
+	groups := make(map[string][]string)
+	keys := func(side [][]string) []string {
+		out := make([]string, len(side))
+		for i, group := range side {
+			out[i] = strings.Join(slices.Sorted(slices.Values(group)), "\x00")
+			groups[out[i]] = group
+		}
+		return out
+	}
+
+	var merged [][]string
+	for _, k := range mergeSet(keys(base), keys(ours), keys(theirs)) {
+		merged = append(merged, groups[k])
+	}
+	return merged
+}
+
+// mergeCriteria merges acceptance criteria as a set of texts, in ours' order,
+// and merges each criterion's done flag like any other field.
+func mergeCriteria(base, ours, theirs []task.Criterion) []task.Criterion {
+	texts := func(side []task.Criterion) []string {
+		out := make([]string, len(side))
+		for i, c := range side {
+			out[i] = c.Text
+		}
+		return out
+	}
+	done := func(side []task.Criterion, text string) (bool, bool) {
+		for _, c := range side {
+			if c.Text == text {
+				return c.Done, true
+			}
+		}
+		return false, false
+	}
+
+	var merged []task.Criterion
+	for _, text := range mergeSet(texts(base), texts(ours), texts(theirs)) {
+		baseDone, _ := done(base, text)
+		oursDone, inOurs := done(ours, text)
+		theirsDone, inTheirs := done(theirs, text)
+		if !inOurs {
+			oursDone = baseDone
+		}
+		if !inTheirs {
+			theirsDone = baseDone
+		}
+		merged = append(merged, task.Criterion{Text: text, Done: mergeField(baseDone, oursDone, theirsDone)})
+	}
+	return merged
+}
+
+// mergeSet merges string sets: an item survives if neither side removed it
+// from base, and items added by either side are kept.
+func mergeSet[T comparable](base, ours, theirs []T) []T {
+	var merged []T
+	for _, item := range slices.Concat(ours, theirs) {
+		if slices.Contains(merged, item) {
+			continue
+		}
+		inBase := slices.Contains(base, item)
+		removed := inBase && (!slices.Contains(ours, item) || !slices.Contains(theirs, item))
+		if !removed {
+			merged = append(merged, item)
+		}
+	}
+	return merged
+}
@@ -0,0 +1,19 @@
+//go:build darwin
+
+package storage
+
+import "golang.org/x/sys/unix"
+
+// onNetworkFS reports whether path is on an NFS, SMB, AFP, or WebDAV mount.
+func onNetworkFS(path string) bool {
+	var st unix.Statfs_t
+	if unix.Statfs(path, &st) != nil {
+		return false
+	}
+	switch unix.ByteSliceToString(st.Fstypename[:]) {
+	case "nfs", "smbfs", "afpfs", "webdav":
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,19 @@
+//go:build linux
+
+package storage
+
+import "golang.org/x/sys/unix"
+
+// onNetworkFS reports whether path is on an NFS, SMB, or AFS mount.
+func onNetworkFS(path string) bool {
+	var st unix.Statfs_t
+	if unix.Statfs(path, &st) != nil {
+		return false
+	}
+	switch uint32(st.Type) { //nolint:gosec // G115: filesystem magic numbers are 32 bits
+	case unix.NFS_SUPER_MAGIC, unix.SMB_SUPER_MAGIC, unix.SMB2_SUPER_MAGIC, unix.CIFS_SUPER_MAGIC, unix.AFS_SUPER_MAGIC:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,93 @@
+package storage
+
+import (
+	"strings"
+	"time"
+
+	"github.com/abatilo/bits/internal/task"
+)
+
+// Notes follow the description in the markdown body, under their own heading:
+//
+//	## Notes
+//
+//	### 2025-01-19T10:30:00Z alice
+//
+//	Reproduced on staging.
+const (
+	notesHeading = "## Notes"
+	notePrefix   = "### "
+)
+
+// splitNotes separates a markdown body into the description and the notes
+// section at its end. A body without a well-formed notes section is all
+// description.
+func splitNotes(body string) (string, []task.Note) {
+	lines := strings.Split(body, "\n")
+	start := -1
+	for i := len(lines) - 1; i >= 0; i-- {
+		if strings.TrimSpace(lines[i]) == notesHeading {
+			start = i
+			break
+		}
+	}
+	if start < 0 {
+		return body, nil
+	}
+
+	var notes []task.Note
+	var text []string
+	flush := func() {
+		if len(notes) > 0 {
+			notes[len(notes)-1].Text = strings.TrimSpace(strings.Join(text, "\n"))
+		}
+		text = nil
+	}
+	for _, line := range lines[start+1:] {
+		if n, ok := parseNoteHeader(line); ok {
+			flush()
+			notes = append(notes, n)
+			continue
+		}
+		if len(notes) == 0 && strings.TrimSpace(line) != "" {
+			return body, nil // Text before the first note: an ordinary heading
+		}
+		text = append(text, line)
+	}
+	flush()
+	if len(notes) == 0 {
+		return body, nil
+	}
+	return strings.TrimSpace(strings.Join(lines[:start], "\n")), notes
+}
+
+// parseNoteHeader parses a note's "### <RFC3339 time> [actor]" line.
+func parseNoteHeader(line string) (task.Note, bool) {
+	rest, ok := strings.CutPrefix(strings.TrimSpace(line), notePrefix)
+	if !ok {
+		return task.Note{}, false
+	}
+	stamp, actor, _ := strings.Cut(strings.TrimSpace(rest), " ")
+	at, err := time.Parse(time.RFC3339, stamp)
+	if err != nil {
+		return task.Note{}, false
+	}
+	return task.Note{At: at, Actor: strings.TrimSpace(actor)}, true
+}
+
+// formatNotes renders notes as the section splitNotes reads.
+func formatNotes(notes []task.Note) string {
+	var sb strings.Builder
+	sb.WriteString(notesHeading + "\n")
+	for _, n := range notes {
+		sb.WriteString("\n" + notePrefix + n.At.UTC().Format(time.RFC3339))
+		if n.Actor != "" {
+			sb.WriteString(" " + n.Actor)
+		}
+		sb.WriteString("\n")
+		if n.Text != "" {
+			sb.WriteString("\n" + n.Text + "\n")
+		}
+	}
+	return sb.String()
+}
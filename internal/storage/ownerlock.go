@@ -0,0 +1,105 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	ownerLockName = ".lock.owner"
+
+	// lockHeartbeat is how often a held owner file is refreshed.
+	lockHeartbeat = 5 * time.Second
+	// lockStaleAfter is how long an owner file can go unrefreshed before
+	// waiters treat its holder as gone.
+	lockStaleAfter = 30 * time.Second
+	// lockPollInterval is how often a waiter retries a held owner file.
+	lockPollInterval = 100 * time.Millisecond
+)
+
+// lockOwner is the content of an owner file.
+type lockOwner struct {
+	Host     string    `json:"host"`
+	PID      int       `json:"pid"`
+	Acquired time.Time `json:"acquired"`
+}
+
+// ownerLock locks the store by creating its owner file exclusively, which NFS
+// and SMB honor where they don't reliably honor flock. While the lock is held
+// a heartbeat refreshes the file's modification time, and waiters remove a
+// file whose heartbeat stopped or whose owner on this host no longer runs.
+// Hosts sharing a store need clocks within lockStaleAfter of each other.
+func (s *Store) ownerLock() (func(), error) {
+	path := filepath.Join(s.basePath, ownerLockName)
+	host, _ := os.Hostname()
+	content, err := json.Marshal(lockOwner{Host: host, PID: os.Getpid(), Acquired: time.Now().UTC()})
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		//nolint:gosec // G302,G304: store path, configurable mode
+		f, createErr := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, s.fileMode)
+		if createErr == nil {
+			_, err = f.Write(content)
+			if err = errors.Join(err, f.Close()); err != nil {
+				_ = os.Remove(path)
+				return nil, err
+			}
+			return heartbeat(path), nil
+		}
+		if !os.IsExist(createErr) {
+			return nil, createErr
+		}
+		removeStaleLock(path, host)
+		time.Sleep(lockPollInterval)
+	}
+}
+
+// heartbeat refreshes the owner file at path until the returned function
+// stops it and removes the file.
+func heartbeat(path string) func() {
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(lockHeartbeat)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case now := <-ticker.C:
+				_ = os.Chtimes(path, now, now)
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		<-stopped
+		_ = os.Remove(path)
+	}
+}
+
+// removeStaleLock removes the owner file at path if its holder is gone: the
+// heartbeat is older than lockStaleAfter, or the file names a process on this
+// host that isn't running. A file still being written is never stale.
+func removeStaleLock(path, host string) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return
+	}
+	stale := time.Since(info.ModTime()) > lockStaleAfter
+	if !stale {
+		var owner lockOwner
+		data, readErr := os.ReadFile(path) //nolint:gosec // G304: store path
+		stale = readErr == nil && json.Unmarshal(data, &owner) == nil &&
+			owner.Host == host && !processAlive(owner.PID)
+	}
+	if stale {
+		_ = os.Remove(path)
+	}
+}
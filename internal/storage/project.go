@@ -1,17 +1,38 @@
 package storage
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"os"
 	"path/filepath"
 	"regexp"
 	"strings"
 )
 
+// storeKeyHashBytes is how much of the path's hash a store key carries.
+const storeKeyHashBytes = 4
+
 var nonAlphanumericRe = regexp.MustCompile(`[^a-zA-Z0-9]+`)
 
+// Environment variables that override where the store is, for CI jobs and
+// test harnesses.
+const (
+	DirEnv         = "BITS_DIR"          // The task directory itself
+	ProjectRootEnv = "BITS_PROJECT_ROOT" // The project root, in place of the enclosing repository
+)
+
 // FindProjectRoot walks up from cwd looking for .git directory.
 // Returns the directory containing .git, or error if not found.
+// $BITS_PROJECT_ROOT, when set, is used instead, repository or not.
 func FindProjectRoot() (string, error) {
+	if env := os.Getenv(ProjectRootEnv); env != "" {
+		abs, err := filepath.Abs(env)
+		if err != nil {
+			return "", err
+		}
+		return filepath.EvalSymlinks(abs)
+	}
+
 	cwd, err := os.Getwd()
 	if err != nil {
 		return "", err
@@ -41,16 +62,73 @@ func FindProjectRoot() (string, error) {
 }
 
 // SanitizePath converts an absolute path to a safe directory name.
-// "/Users/abatilo/myproject" -> "Users-abatilo-myproject".
+// "/Users/abatilo/myproject" -> "Users-abatilo-myproject", and on Windows
+// "C:\Users\abatilo\myproject" -> "C-Users-abatilo-myproject".
 func SanitizePath(path string) string {
-	// Remove leading slash
-	result := strings.TrimPrefix(path, "/")
-
-	// Replace non-alphanumeric chars with dash
-	result = nonAlphanumericRe.ReplaceAllString(result, "-")
+	// Replace separators, drive colons, and other non-alphanumeric chars with dash
+	result := nonAlphanumericRe.ReplaceAllString(path, "-")
 
-	// Trim leading/trailing dashes
+	// Trim the dashes left by a leading slash, UNC prefix, or trailing separator
 	result = strings.Trim(result, "-")
 
 	return result
 }
+
+// StoreKey returns the name of a project's task directory: the sanitized path,
+// for reading at a glance, followed by a hash of the path itself, so projects
+// whose sanitized paths collide ("/a/b-c" and "/a/b/c") get separate stores.
+func StoreKey(path string) string {
+	sum := sha256.Sum256([]byte(path))
+	hash := hex.EncodeToString(sum[:storeKeyHashBytes])
+	if name := SanitizePath(path); name != "" {
+		return name + "-" + hash
+	}
+	return hash
+}
+
+// projectStorePath returns where the task directory of the project at
+// projectRoot lives under root. A directory named by the sanitized path alone,
+// as older versions created, keeps being used until moved with
+// MigrateStoreKey.
+func projectStorePath(root, projectRoot string) string {
+	keyed := filepath.Join(root, StoreKey(projectRoot))
+	if legacy := filepath.Join(root, SanitizePath(projectRoot)); !dirExists(keyed) && dirExists(legacy) {
+		return legacy
+	}
+	return keyed
+}
+
+// MigrateStoreKey renames the current project's task directory from its
+// sanitized-path name to its StoreKey, along with the backups kept beside it.
+// It returns the source and destination, and refuses to overwrite an existing
+// destination. With check set, it stops after validating the move.
+func MigrateStoreKey(check bool) (string, string, error) {
+	projectRoot, err := FindProjectRoot()
+	if err != nil {
+		return "", "", err
+	}
+	root, err := DataRoot()
+	if err != nil {
+		return "", "", err
+	}
+	legacy := NewStoreWithPath(filepath.Join(root, SanitizePath(projectRoot)))
+	keyed := NewStoreWithPath(filepath.Join(root, StoreKey(projectRoot)))
+	if !legacy.IsInitialized() {
+		return legacy.basePath, keyed.basePath, NothingToMigrateError{Path: legacy.basePath}
+	}
+	if _, statErr := os.Stat(keyed.basePath); statErr == nil {
+		return legacy.basePath, keyed.basePath, MigrationTargetExistsError{Path: keyed.basePath}
+	}
+	if check {
+		return legacy.basePath, keyed.basePath, nil
+	}
+
+	if err = os.Rename(legacy.basePath, keyed.basePath); err != nil {
+		return legacy.basePath, keyed.basePath, err
+	}
+	from, to := legacy.DefaultBackupDir(), keyed.DefaultBackupDir()
+	if _, statErr := os.Stat(to); dirExists(from) && os.IsNotExist(statErr) {
+		err = os.Rename(from, to)
+	}
+	return legacy.basePath, keyed.basePath, err
+}
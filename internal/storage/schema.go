@@ -0,0 +1,105 @@
+package storage
+
+import (
+	"os"
+	"sort"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/abatilo/bits/internal/task"
+)
+
+// SchemaVersion is the task file format this build writes. Files without a
+// schema field predate versioning and are schema 0.
+const SchemaVersion = 1
+
+// migration upgrades a frontmatter mapping by one schema version in place.
+type migration func(fm *yaml.Node)
+
+// migrations returns the upgrade steps, where step i takes schema i to i+1.
+// When the file format changes, bump SchemaVersion and append a step that
+// rewrites older frontmatter (adding defaults, renaming keys) so ParseMarkdown
+// keeps reading every store ever written.
+func migrations() []migration {
+	return []migration{
+		// 0 -> 1: introduces the schema field; the format is otherwise unchanged
+		func(*yaml.Node) {},
+	}
+}
+
+// migrate upgrades frontmatter from schema from to the end of steps.
+func migrate(fm *yaml.Node, from int, steps []migration) {
+	for _, step := range steps[from:] {
+		step(fm)
+	}
+}
+
+// frontmatterSchema returns the schema field of a frontmatter mapping.
+func frontmatterSchema(fm *yaml.Node) (int, error) {
+	value := mappingValue(fm, "schema")
+	if value == nil {
+		return 0, nil
+	}
+	schema, err := strconv.Atoi(value.Value)
+	if err != nil || schema < 0 {
+		return 0, &parseError{"invalid schema: " + strconv.Quote(value.Value)}
+	}
+	if schema > SchemaVersion {
+		return 0, UnsupportedSchemaError{Schema: schema, Supported: SchemaVersion}
+	}
+	return schema, nil
+}
+
+// mappingValue returns the value of key in a YAML mapping, or nil.
+func mappingValue(m *yaml.Node, key string) *yaml.Node {
+	if m.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			return m.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// renameKey renames a key in a YAML mapping, for migrations that rename
+// fields. The old key is left alone if the new one is already present.
+func renameKey(m *yaml.Node, from, to string) {
+	if mappingValue(m, to) != nil {
+		return
+	}
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == from {
+			m.Content[i].Value = to
+		}
+	}
+}
+
+// Outdated returns the tasks whose files were written with an older schema or
+// sit in the wrong directory for their status (closed tasks saved before they
+// moved to closed/), sorted by ID. Saving them rewrites them in place.
+func (s *Store) Outdated() ([]*task.Task, error) {
+	if err := s.EnsureInitialized(); err != nil {
+		return nil, err
+	}
+	ids, err := s.AllIDs()
+	if err != nil {
+		return nil, err
+	}
+
+	var outdated []*task.Task
+	for id := range ids {
+		content, readErr := os.ReadFile(s.taskPath(id))
+		if readErr != nil {
+			return nil, readErr
+		}
+		t, schema, parseErr := parseMarkdown(content)
+		if parseErr == nil && (schema < SchemaVersion || !s.placed(t)) { // Malformed files are left for 'bits verify'
+			outdated = append(outdated, t)
+		}
+	}
+	sort.Slice(outdated, func(i, j int) bool { return outdated[i].ID < outdated[j].ID })
+	return outdated, nil
+}
@@ -0,0 +1,68 @@
+package storage
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/abatilo/bits/internal/task"
+)
+
+// TextMatcher returns a function reporting whether text matches query, as a
+// substring or, with regex, a regular expression.
+func TextMatcher(query string, regex, caseSensitive bool) (func(string) bool, error) {
+	if regex {
+		re, err := regexp.Compile(query)
+		if err != nil {
+			return nil, InvalidPatternError{Pattern: query, Err: err}
+		}
+		if !caseSensitive {
+			re = regexp.MustCompile("(?i)" + query) // Valid, since query is
+		}
+		return re.MatchString, nil
+	}
+	if caseSensitive {
+		return func(text string) bool { return strings.Contains(text, query) }, nil
+	}
+	query = strings.ToLower(query)
+	return func(text string) bool { return strings.Contains(strings.ToLower(text), query) }, nil
+}
+
+// MatchesText reports whether a task's title, description, or close reason
+// matches.
+func MatchesText(t *task.Task, match func(string) bool) bool {
+	fields := []string{t.Title, t.Description}
+	if t.CloseReason != nil {
+		fields = append(fields, *t.CloseReason)
+	}
+	for _, field := range fields {
+		if match(field) {
+			return true
+		}
+	}
+	return false
+}
+
+// Search returns the tasks whose text matches, most urgent first. With
+// archived, tasks moved out by Compact are searched too.
+func (s *Store) Search(match func(string) bool, archived bool) ([]*task.Task, error) {
+	tasks, err := s.List(StatusFilter{})
+	if err != nil {
+		return nil, err
+	}
+	if archived {
+		old, archiveErr := s.Archived()
+		if archiveErr != nil {
+			return nil, archiveErr
+		}
+		tasks = append(tasks, old...)
+	}
+
+	var matches []*task.Task
+	for _, t := range tasks {
+		if MatchesText(t, match) {
+			matches = append(matches, t)
+		}
+	}
+	sortByPriority(matches)
+	return matches, nil
+}
@@ -0,0 +1,184 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	snapshotDir        = ".snapshots"
+	snapshotRetention  = 10 // Most recent snapshots kept; older ones are pruned
+	snapshotTimeLayout = "20060102-150405.000000"
+)
+
+// Snapshot describes a point-in-time copy of the store's task files.
+type Snapshot struct {
+	Name      string    `json:"name"`
+	Reason    string    `json:"reason"`
+	CreatedAt time.Time `json:"created_at"`
+	Tasks     int       `json:"tasks"`
+}
+
+func (s *Store) snapshotRoot() string {
+	return filepath.Join(s.basePath, snapshotDir)
+}
+
+// Snapshot copies every task file into a timestamped directory under the store
+// and prunes snapshots beyond the retention limit. The reason (e.g. "prune")
+// is recorded in the snapshot name.
+func (s *Store) Snapshot(reason string) (*Snapshot, error) {
+	if err := s.EnsureInitialized(); err != nil {
+		return nil, err
+	}
+	ids, err := s.AllIDs()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	name := now.Format(snapshotTimeLayout) + "-" + reason
+	if s.DryRun() {
+		s.skip("snapshot %d task(s) as %s", len(ids), name)
+		return &Snapshot{Name: name, Reason: reason, CreatedAt: now, Tasks: len(ids)}, nil
+	}
+	dir := filepath.Join(s.snapshotRoot(), name)
+	if err = s.mkdirAll(dir); err != nil {
+		return nil, err
+	}
+
+	for id := range ids {
+		var content []byte
+		content, err = s.fullContent(s.taskPath(id))
+		if err != nil {
+			return nil, err
+		}
+		if err = s.writeFile(filepath.Join(dir, id+fileExt), content); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = s.pruneSnapshots(); err != nil {
+		return nil, err
+	}
+	return &Snapshot{Name: name, Reason: reason, CreatedAt: now, Tasks: len(ids)}, nil
+}
+
+// Snapshots returns the available snapshots, newest first.
+func (s *Store) Snapshots() ([]Snapshot, error) {
+	entries, err := os.ReadDir(s.snapshotRoot())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var snapshots []Snapshot
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		snap, ok := parseSnapshotName(entry.Name())
+		if !ok {
+			continue
+		}
+		files, readErr := os.ReadDir(filepath.Join(s.snapshotRoot(), entry.Name()))
+		if readErr != nil {
+			return nil, readErr
+		}
+		for _, f := range files {
+			if strings.HasSuffix(f.Name(), fileExt) {
+				snap.Tasks++
+			}
+		}
+		snapshots = append(snapshots, snap)
+	}
+
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].Name > snapshots[j].Name
+	})
+	return snapshots, nil
+}
+
+// RestoreSnapshot replaces the store's tasks with the contents of a snapshot.
+// The current state is snapshotted first so a restore can itself be undone.
+func (s *Store) RestoreSnapshot(name string) error {
+	dir := filepath.Join(s.snapshotRoot(), filepath.Base(name))
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return SnapshotNotFoundError{Name: name}
+	}
+	if err != nil {
+		return err
+	}
+
+	if _, err = s.Snapshot("restore"); err != nil {
+		return err
+	}
+
+	restored := make(map[string]bool)
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), fileExt) {
+			continue
+		}
+		content, readErr := os.ReadFile(filepath.Join(dir, entry.Name())) //nolint:gosec // G304: path is within the store
+		if readErr != nil {
+			return readErr
+		}
+		t, parseErr := ParseMarkdown(content)
+		if parseErr != nil {
+			return parseErr
+		}
+		if err = s.Rewrite(t); err != nil {
+			return err
+		}
+		restored[t.ID] = true
+	}
+
+	ids, err := s.AllIDs()
+	if err != nil {
+		return err
+	}
+	for id := range ids {
+		if restored[id] {
+			continue
+		}
+		if err = s.Delete(id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pruneSnapshots removes the oldest snapshots beyond the retention limit.
+func (s *Store) pruneSnapshots() error {
+	snapshots, err := s.Snapshots()
+	if err != nil {
+		return err
+	}
+	for i := snapshotRetention; i < len(snapshots); i++ {
+		if err = os.RemoveAll(filepath.Join(s.snapshotRoot(), snapshots[i].Name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseSnapshotName extracts the timestamp and reason from a snapshot directory name.
+func parseSnapshotName(name string) (Snapshot, bool) {
+	if len(name) <= len(snapshotTimeLayout) {
+		return Snapshot{}, false
+	}
+	createdAt, err := time.Parse(snapshotTimeLayout, name[:len(snapshotTimeLayout)])
+	if err != nil {
+		return Snapshot{}, false
+	}
+	return Snapshot{
+		Name:      name,
+		Reason:    strings.TrimPrefix(name[len(snapshotTimeLayout):], "-"),
+		CreatedAt: createdAt,
+	}, true
+}
@@ -1,46 +1,125 @@
 package storage
 
 import (
+	"errors"
+	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
-	"slices"
 	"sort"
 	"strings"
 	"time"
 
 	"github.com/abatilo/bits/internal/task"
+	"github.com/abatilo/bits/internal/telemetry"
 )
 
 const (
-	bitsDir = ".bits"
-	fileExt = ".md"
+	bitsDir       = ".bits"
+	closedDir     = "closed" // Closed tasks live here, out of the way of walks for live work
+	fileExt       = ".md"
+	walkBatchSize = 256 // Directory entries read per batch while walking
+
+	defaultDirMode  fs.FileMode = 0o755
+	defaultFileMode fs.FileMode = 0o644
 )
 
 // Store handles task file operations.
 type Store struct {
-	basePath string
+	basePath     string
+	dirMode      fs.FileMode
+	fileMode     fs.FileMode
+	idx          *index
+	tracer       *telemetry.Tracer
+	dryRun       io.Writer
+	release      func() // Set while Lock is held
+	wip          WIPLimits
+	lockStrategy LockStrategy
+	minIDLength  int
+	taken        map[string]bool // See takenIDs
+	local        bool            // Inside the project; see InitLocal
+	journal      *JournalEntry   // Being recorded; see BeginOp
+	batch        *writeBatch     // Set during batchWrites
 }
 
-// NewStore creates a Store with a project-scoped path (~/.bits/<sanitized-project-root>/).
+// NewStore creates a Store with a project-scoped path (<data-root>/<sanitized-project-root>/),
+// or the project's in-repo <project>/.bits when it has one (see InitLocal).
+// See DataRoot for how the data root is chosen. $BITS_DIR, when set, is the
+// path outright.
 func NewStore() (*Store, error) {
+	return NewStoreUnder("")
+}
+
+// NewStoreUnder is NewStore with root, when set, as the data root in place of
+// DataRoot.
+func NewStoreUnder(root string) (*Store, error) {
+	return NewStoreWithMode(root, StoreAuto)
+}
+
+// StoreMode picks which of a project's two stores NewStoreWithMode opens.
+type StoreMode string
+
+// Store modes.
+const (
+	StoreAuto  StoreMode = ""      // The in-repo store if the project has one
+	StoreHome  StoreMode = "home"  // The project's store under the data root
+	StoreLocal StoreMode = "local" // The in-repo store, whether or not it exists yet
+)
+
+// NewStoreWithMode is NewStoreUnder with mode choosing between the project's
+// in-repo store and its store under the data root. $BITS_DIR still wins.
+func NewStoreWithMode(root string, mode StoreMode) (*Store, error) {
+	if dir := os.Getenv(DirEnv); dir != "" {
+		abs, err := filepath.Abs(dir)
+		if err != nil {
+			return nil, err
+		}
+		return NewStoreWithPath(abs), nil
+	}
+
 	projectRoot, err := FindProjectRoot()
 	if err != nil {
 		return nil, err
 	}
+	switch mode {
+	case StoreLocal:
+		s := NewStoreWithPath(LocalStorePath(projectRoot))
+		s.local = true
+		return s, nil
+	case StoreAuto:
+		if local := findLocalStore(projectRoot); local != nil {
+			return local, nil
+		}
+	}
 
-	home, err := os.UserHomeDir()
-	if err != nil {
-		return nil, err
+	if root == "" {
+		if root, err = DataRoot(); err != nil {
+			return nil, err
+		}
 	}
 
-	sanitized := SanitizePath(projectRoot)
-	basePath := filepath.Join(home, bitsDir, sanitized)
-	return &Store{basePath: basePath}, nil
+	return NewStoreWithPath(projectStorePath(root, projectRoot)), nil
 }
 
 // NewStoreWithPath creates a Store with a custom base path.
 func NewStoreWithPath(path string) *Store {
-	return &Store{basePath: path}
+	return &Store{basePath: path, dirMode: defaultDirMode, fileMode: defaultFileMode, wip: DefaultWIPLimits()}
+}
+
+// SetTracer records spans for storage operations on tracer (nil to stop).
+func (s *Store) SetTracer(tracer *telemetry.Tracer) {
+	s.tracer = tracer
+}
+
+// loadTimings splits the time spent loading tasks into file reads and parsing.
+type loadTimings struct {
+	read  time.Duration
+	parse time.Duration
+}
+
+// attrs returns the timings as span attributes.
+func (lt loadTimings) attrs() []telemetry.Attr {
+	return []telemetry.Attr{telemetry.Duration("bits.read_ms", lt.read), telemetry.Duration("bits.parse_ms", lt.parse)}
 }
 
 // BasePath returns the base path of the store.
@@ -59,101 +138,376 @@ func (s *Store) EnsureInitialized() error {
 	if s.IsInitialized() {
 		return nil
 	}
-	//nolint:gosec // G301: 0755 is appropriate for user-accessible task directory
-	return os.MkdirAll(s.basePath, 0o755)
+	return s.mkdirAll(s.basePath)
 }
 
-// Init initializes the bits directory. With force=true, it wipes and recreates.
+// Init initializes the bits directory. With force=true, it wipes and recreates
+// everything except the safety snapshots; in dry-run mode it only describes the
+// wipe.
 func (s *Store) Init(force bool) error {
+	if force && s.DryRun() {
+		s.skip("wipe %s", s.basePath)
+		return nil
+	}
 	if force {
-		if err := os.RemoveAll(s.basePath); err != nil {
+		entries, err := os.ReadDir(s.basePath)
+		if err != nil && !os.IsNotExist(err) {
 			return err
 		}
+		for _, entry := range entries {
+			switch entry.Name() {
+			case snapshotDir:
+				continue
+			case lockFileName, ownerLockName:
+				continue // Removing a held lock would let another writer in
+			}
+			if err = os.RemoveAll(filepath.Join(s.basePath, entry.Name())); err != nil {
+				return err
+			}
+		}
+		s.idx = nil
 	}
-	//nolint:gosec // G301: 0755 is appropriate for user-accessible task directory
-	return os.MkdirAll(s.basePath, 0o755)
+	return s.mkdirAll(s.basePath)
 }
 
-// taskPath returns the full path for a task file.
-func (s *Store) taskPath(id string) string {
+// Destroy deletes the store's task directory, snapshots included. Backups
+// written beside it are left alone. In dry-run mode it only describes the
+// deletion.
+func (s *Store) Destroy() error {
+	if !s.IsInitialized() {
+		return NotInitializedError{Path: s.basePath}
+	}
+	if s.DryRun() {
+		s.skip("delete %s", s.basePath)
+		return nil
+	}
+	s.idx = nil
+	s.journal = nil // Nothing is left to journal into
+	s.unlock()      // Windows refuses to remove an open file
+	return os.RemoveAll(s.basePath)
+}
+
+// livePath returns where an open or active task's file lives.
+func (s *Store) livePath(id string) string {
 	return filepath.Join(s.basePath, id+fileExt)
 }
 
+// closedPath returns where a closed task's file lives.
+func (s *Store) closedPath(id string) string {
+	return filepath.Join(s.basePath, closedDir, id+fileExt)
+}
+
+// taskPath returns the path of a task's file, checking first where the index
+// expects it. Either directory is accepted, so closed tasks saved before they
+// were moved to closed/ are still found. A missing task gets its live path.
+func (s *Store) taskPath(id string) string {
+	first, second := s.livePath(id), s.closedPath(id)
+	if s.idx != nil && s.idx.Tasks[id].Status == task.StatusClosed {
+		first, second = second, first
+	}
+	if _, err := os.Stat(first); err == nil {
+		return first
+	}
+	if _, err := os.Stat(second); err == nil {
+		return second
+	}
+	return s.livePath(id)
+}
+
+// placed reports whether a task's file is in the directory for its status.
+func (s *Store) placed(t *task.Task) bool {
+	_, err := os.Stat(s.pathFor(t.ID, t.Status))
+	return err == nil
+}
+
+// pathFor returns the path a task's file belongs at for its status.
+func (s *Store) pathFor(id string, status task.Status) string {
+	if status == task.StatusClosed {
+		return s.closedPath(id)
+	}
+	return s.livePath(id)
+}
+
+// writeTask writes a task file into the directory for its status, then
+// removes the copy from the other directory if the status changed.
+func (s *Store) writeTask(id string, status task.Status, content []byte) error {
+	path := s.pathFor(id, status)
+	stale := s.livePath(id)
+	if path == stale {
+		stale = s.closedPath(id)
+	} else if err := s.mkdirAll(filepath.Dir(path)); err != nil {
+		return err
+	}
+	if err := s.writeFile(path, content); err != nil {
+		return err
+	}
+	if err := os.Remove(stale); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// TaskPath returns the absolute path of an existing task's file.
+func (s *Store) TaskPath(id string) (string, error) {
+	if err := s.EnsureInitialized(); err != nil {
+		return "", err
+	}
+	if !s.Exists(id) {
+		return "", TaskNotFoundError{ID: id}
+	}
+	return filepath.Abs(s.taskPath(id))
+}
+
 // Exists checks if a task with the given ID exists.
 func (s *Store) Exists(id string) bool {
 	_, err := os.Stat(s.taskPath(id))
 	return err == nil
 }
 
-// Save writes a task to disk.
+// Save records a change to a task, stamping its updated_at, and writes it to
+// disk.
 func (s *Store) Save(t *task.Task) error {
-	if err := s.EnsureInitialized(); err != nil {
+	now := time.Now().UTC().Truncate(time.Second)
+	t.UpdatedAt = &now
+	return s.Rewrite(t)
+}
+
+// Rewrite writes a task to disk as is, leaving updated_at alone, for writes
+// that aren't activity on the task: migrations, restores, and bookkeeping.
+func (s *Store) Rewrite(t *task.Task) error {
+	span := s.tracer.Start("storage.save", telemetry.String("bits.task.id", t.ID))
+	var err error // Every failure below is assigned here for the span
+	defer func() {
+		span.RecordError(err)
+		span.End()
+	}()
+
+	content, err := SerializeMarkdown(t)
+	if err != nil {
 		return err
 	}
-	content, err := SerializeMarkdown(t)
+	if s.DryRun() {
+		if s.Exists(t.ID) {
+			s.skip("update task %s (%s)", t.ID, t.Status)
+		} else {
+			s.skip("create task %s (%s)", t.ID, t.Status)
+		}
+		return nil
+	}
+	if err = s.EnsureInitialized(); err != nil {
+		return err
+	}
+	if err = s.recordChange(t.ID, content); err != nil {
+		return err
+	}
+	idx, err := s.loadIndex() // Before writing, so the write isn't mistaken for someone else's
+	if err != nil {
+		return err
+	}
+	file := content
+	split, err := s.splitBody(t)
 	if err != nil {
 		return err
 	}
-	//nolint:gosec // G306: 0644 is appropriate for user-readable task files
-	return os.WriteFile(s.taskPath(t.ID), content, 0o644)
+	if split != t {
+		if file, err = SerializeMarkdown(split); err != nil {
+			return err
+		}
+	}
+	if err = s.writeTask(t.ID, t.Status, file); err != nil {
+		return err
+	}
+
+	idx.set(t, checksum(file))
+	s.restamp(idx, t.ID)
+	if err = s.indexChanged(); err != nil {
+		return err
+	}
+	err = s.recordSync(syncOpSave, t.ID, content) // Remotes get the description inline
+	return err
 }
 
 // Load reads a task from disk.
 func (s *Store) Load(id string) (*task.Task, error) {
+	span := s.tracer.Start("storage.load", telemetry.String("bits.task.id", id))
+	defer span.End()
+
+	var timings loadTimings
+	t, err := s.load(id, &timings)
+	span.SetAttributes(timings.attrs()...)
+	span.RecordError(err)
+	return t, err
+}
+
+// load reads and parses a task, adding the time spent to timings.
+func (s *Store) load(id string, timings *loadTimings) (*task.Task, error) {
 	if err := s.EnsureInitialized(); err != nil {
 		return nil, err
 	}
-	content, err := os.ReadFile(s.taskPath(id))
+	t, err := s.readTask(s.taskPath(id), timings)
 	if os.IsNotExist(err) {
 		return nil, TaskNotFoundError{ID: id}
 	}
+	return t, err
+}
+
+// readTask reads and parses a task file, adding the time spent to timings.
+func (s *Store) readTask(path string, timings *loadTimings) (*task.Task, error) {
+	start := time.Now()
+	content, err := os.ReadFile(path) //nolint:gosec // G304: path is within the store
+	timings.read += time.Since(start)
+	if err != nil {
+		return nil, err
+	}
+
+	start = time.Now()
+	t, err := ParseMarkdown(content)
+	timings.parse += time.Since(start)
 	if err != nil {
 		return nil, err
 	}
-	return ParseMarkdown(content)
+	return t, s.readBody(t)
 }
 
-// Delete removes a task file.
+// Delete removes a task file along with its attachments.
 func (s *Store) Delete(id string) error {
+	return s.deleteTask(id, true)
+}
+
+// deleteTask removes a task file, and its attachments unless the task lives
+// on in an archive.
+func (s *Store) deleteTask(id string, attachments bool) error {
+	if s.DryRun() {
+		if !s.Exists(id) {
+			return TaskNotFoundError{ID: id}
+		}
+		s.skip("delete task %s", id)
+		return nil
+	}
 	if err := s.EnsureInitialized(); err != nil {
 		return err
 	}
-	err := os.Remove(s.taskPath(id))
+	if s.Exists(id) {
+		if err := s.recordChange(id, nil); err != nil {
+			return err
+		}
+	}
+	idx, err := s.loadIndex() // Before removing, so the removal isn't mistaken for someone else's
+	if err != nil {
+		return err
+	}
+	err = os.Remove(s.taskPath(id))
 	if os.IsNotExist(err) {
 		return TaskNotFoundError{ID: id}
 	}
-	return err
+	if err != nil {
+		return err
+	}
+	if err = os.Remove(s.bodyPath(id)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if attachments {
+		if err = os.RemoveAll(s.attachmentDir(id)); err != nil {
+			return err
+		}
+	}
+
+	idx.remove(id)
+	s.restamp(idx, id)
+	if err = s.indexChanged(); err != nil {
+		return err
+	}
+	return s.recordSync(syncOpDelete, id, nil)
 }
 
-// List returns all tasks, optionally filtered and sorted.
-func (s *Store) List(filter StatusFilter) ([]*task.Task, error) {
+// Walk calls fn for every task matching the filter without materializing the
+// full task list. Tasks are visited in directory order, and closed/ is only
+// read when the filter includes closed tasks. Walking stops at the first error
+// returned by fn, which is returned to the caller.
+func (s *Store) Walk(filter StatusFilter, fn func(*task.Task) error) error {
+	span := s.tracer.Start("storage.walk")
+	defer span.End()
+	var timings loadTimings
+	var loaded int
+	defer func() {
+		span.SetAttributes(append(timings.attrs(), telemetry.Int("bits.tasks", loaded))...)
+	}()
+
 	if err := s.EnsureInitialized(); err != nil {
-		return nil, err
+		return err
 	}
 
-	entries, err := os.ReadDir(s.basePath)
+	visit := func(path string) error {
+		t, loadErr := s.readTask(path, &timings)
+		if loadErr != nil {
+			return nil // Skip malformed files
+		}
+		loaded++
+		if !filter.Matches(t.Status) {
+			return nil
+		}
+		return fn(t)
+	}
+	if err := s.walkDir(s.basePath, visit); err != nil {
+		return err
+	}
+	if !filter.Matches(task.StatusClosed) {
+		return nil
+	}
+	err := s.walkDir(filepath.Join(s.basePath, closedDir), visit)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+// walkDir calls fn with the path of each task file in dir, reading the
+// directory in batches.
+func (s *Store) walkDir(dir string, fn func(path string) error) error {
+	f, err := os.Open(dir) //nolint:gosec // G304: dir is within the store
 	if err != nil {
-		return nil, err
+		return err
 	}
+	defer f.Close()
 
-	var tasks []*task.Task
-	for _, entry := range entries {
-		if entry.IsDir() || !strings.HasSuffix(entry.Name(), fileExt) {
-			continue
+	for {
+		entries, readErr := f.ReadDir(walkBatchSize)
+		for _, entry := range entries {
+			if entry.IsDir() || !strings.HasSuffix(entry.Name(), fileExt) {
+				continue
+			}
+			if err = fn(filepath.Join(dir, entry.Name())); err != nil {
+				return err
+			}
 		}
-		id := strings.TrimSuffix(entry.Name(), fileExt)
-		var t *task.Task
-		t, err = s.Load(id)
-		if err != nil {
-			continue // Skip malformed files
+		if errors.Is(readErr, io.EOF) {
+			return nil
 		}
-		if filter.Matches(t.Status) {
-			tasks = append(tasks, t)
+		if readErr != nil {
+			return readErr
 		}
 	}
+}
 
-	// Sort by priority (highest first), then by created_at (oldest first)
-	sort.Slice(tasks, func(i, j int) bool {
+// List returns all tasks, optionally filtered and sorted.
+func (s *Store) List(filter StatusFilter) ([]*task.Task, error) {
+	var tasks []*task.Task
+	err := s.Walk(filter, func(t *task.Task) error {
+		tasks = append(tasks, t)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sortByPriority(tasks)
+	return tasks, nil
+}
+
+// sortByPriority sorts tasks by priority (highest first), then by created_at
+// (oldest first).
+func sortByPriority(tasks []*task.Task) {
+	sort.SliceStable(tasks, func(i, j int) bool {
 		pi := task.PriorityOrder(tasks[i].Priority)
 		pj := task.PriorityOrder(tasks[j].Priority)
 		if pi != pj {
@@ -161,8 +515,6 @@ func (s *Store) List(filter StatusFilter) ([]*task.Task, error) {
 		}
 		return tasks[i].CreatedAt.Before(tasks[j].CreatedAt)
 	})
-
-	return tasks, nil
 }
 
 // AllIDs returns all task IDs (for ID generation collision checking).
@@ -171,35 +523,55 @@ func (s *Store) AllIDs() (map[string]bool, error) {
 		return nil, err
 	}
 
-	entries, err := os.ReadDir(s.basePath)
-	if err != nil {
-		return nil, err
-	}
-
 	ids := make(map[string]bool)
-	for _, entry := range entries {
-		if entry.IsDir() || !strings.HasSuffix(entry.Name(), fileExt) {
+	for _, dir := range []string{s.basePath, filepath.Join(s.basePath, closedDir)} {
+		entries, err := os.ReadDir(dir)
+		if os.IsNotExist(err) {
 			continue
 		}
-		id := strings.TrimSuffix(entry.Name(), fileExt)
-		ids[id] = true
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range entries {
+			if entry.IsDir() || !strings.HasSuffix(entry.Name(), fileExt) {
+				continue
+			}
+			ids[strings.TrimSuffix(entry.Name(), fileExt)] = true
+		}
 	}
 	return ids, nil
 }
 
-// RemoveDependency removes a dependency from all tasks that reference it.
+// RemoveDependency removes a dependency from all tasks that reference it,
+// and makes its subtasks top-level tasks. Only the referrers recorded in the
+// index are loaded and rewritten.
 func (s *Store) RemoveDependency(depID string) error {
-	tasks, err := s.List(StatusFilter{})
+	dependents, err := s.Dependents(depID)
 	if err != nil {
 		return err
 	}
+	children, err := s.Children(depID, StatusFilter{})
+	if err != nil {
+		return err
+	}
+	for _, id := range children {
+		var t *task.Task
+		if t, err = s.Load(id); err != nil {
+			return err
+		}
+		t.Parent = ""
+		if err = s.Save(t); err != nil {
+			return err
+		}
+	}
 
-	for _, t := range tasks {
-		originalLen := len(t.DependsOn)
-		t.DependsOn = slices.DeleteFunc(t.DependsOn, func(d string) bool {
-			return d == depID
-		})
-		if len(t.DependsOn) != originalLen {
+	for _, id := range dependents {
+		var t *task.Task
+		t, err = s.Load(id)
+		if err != nil {
+			return err
+		}
+		if t.RemoveDependency(depID) {
 			if err = s.Save(t); err != nil {
 				return err
 			}
@@ -210,6 +582,19 @@ func (s *Store) RemoveDependency(depID string) error {
 
 // CreateTask creates a new task with generated ID.
 func (s *Store) CreateTask(title, description string, priority task.Priority) (*task.Task, error) {
+	t, err := s.NewTask(title, description, priority)
+	if err != nil {
+		return nil, err
+	}
+	if err = s.Save(t); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// NewTask returns an open task with a fresh unique ID without saving it, so
+// callers can set further fields before the first Save.
+func (s *Store) NewTask(title, description string, priority task.Priority) (*task.Task, error) {
 	if err := s.EnsureInitialized(); err != nil {
 		return nil, err
 	}
@@ -217,28 +602,23 @@ func (s *Store) CreateTask(title, description string, priority task.Priority) (*
 	createdAt := time.Now().UTC()
 
 	// Generate unique ID
-	existingIDs, err := s.AllIDs()
+	taken, err := s.takenIDs()
 	if err != nil {
 		return nil, err
 	}
-	existsFn := func(id string) bool {
-		return existingIDs[id]
-	}
-	id := task.GenerateID(title, createdAt, existsFn)
+	id := task.GenerateID(title, createdAt, s.minIDLength, func(id string) bool {
+		return taken[id] || s.Exists(id)
+	})
+	taken[id] = true
 
-	t := &task.Task{
+	return &task.Task{
 		ID:          id,
 		Title:       title,
 		Status:      task.StatusOpen,
 		Priority:    priority,
 		CreatedAt:   createdAt,
 		Description: description,
-	}
-
-	if err = s.Save(t); err != nil {
-		return nil, err
-	}
-	return t, nil
+	}, nil
 }
 
 // StatusFilter controls which statuses to include in list results.
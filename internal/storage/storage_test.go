@@ -2,12 +2,26 @@
 package storage
 
 import (
+	"archive/tar"
+	"bytes"
+	"cmp"
+	"compress/gzip"
+	"encoding/json"
 	"errors"
+	"io"
+	"io/fs"
+	"maps"
+	"math"
 	"os"
 	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 
+	"gopkg.in/yaml.v3"
+
 	"github.com/abatilo/bits/internal/task"
 )
 
@@ -71,6 +85,60 @@ depends_on:
 	}
 }
 
+func TestAnyOfDependenciesRoundTrip(t *testing.T) {
+	original := &task.Task{
+		ID:         "abc123",
+		Title:      "Test task",
+		Status:     task.StatusOpen,
+		Priority:   task.PriorityMedium,
+		CreatedAt:  time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC),
+		DependsAny: [][]string{{"def456", "ghi789"}},
+	}
+
+	content, err := SerializeMarkdown(original)
+	if err != nil {
+		t.Fatalf("SerializeMarkdown failed: %v", err)
+	}
+	if !strings.Contains(string(content), "depends_on_any: [[def456, ghi789]]") {
+		t.Errorf("frontmatter missing any-of group:\n%s", content)
+	}
+
+	parsed, err := ParseMarkdown(content)
+	if err != nil {
+		t.Fatalf("ParseMarkdown failed: %v", err)
+	}
+	if len(parsed.DependsAny) != 1 || len(parsed.DependsAny[0]) != 2 || parsed.DependsAny[0][1] != "ghi789" {
+		t.Errorf("DependsAny = %v, want [[def456 ghi789]]", parsed.DependsAny)
+	}
+}
+
+func TestEstimateRoundTrip(t *testing.T) {
+	original := &task.Task{
+		ID:        "abc123",
+		Title:     "Test task",
+		Status:    task.StatusOpen,
+		Priority:  task.PriorityMedium,
+		CreatedAt: time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC),
+		Estimate:  90 * time.Minute,
+	}
+
+	content, err := SerializeMarkdown(original)
+	if err != nil {
+		t.Fatalf("SerializeMarkdown failed: %v", err)
+	}
+	if !strings.Contains(string(content), "estimate: 1h30m\n") {
+		t.Errorf("frontmatter missing estimate:\n%s", content)
+	}
+
+	parsed, err := ParseMarkdown(content)
+	if err != nil {
+		t.Fatalf("ParseMarkdown failed: %v", err)
+	}
+	if parsed.Estimate != original.Estimate {
+		t.Errorf("Estimate = %v, want %v", parsed.Estimate, original.Estimate)
+	}
+}
+
 func TestSerializeMarkdown(t *testing.T) {
 	now := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
 	task := &task.Task{
@@ -79,8 +147,10 @@ func TestSerializeMarkdown(t *testing.T) {
 		Status:      "open",
 		Priority:    "high",
 		CreatedAt:   now,
+		ClaimedAt:   &now,
 		Description: "Description here",
 	}
+	task.Claim(now, "user:alice")
 
 	data, err := SerializeMarkdown(task)
 	if err != nil {
@@ -102,6 +172,12 @@ func TestSerializeMarkdown(t *testing.T) {
 	if parsed.Description != task.Description {
 		t.Errorf("Round-trip Description = %q, want %q", parsed.Description, task.Description)
 	}
+	if parsed.ClaimedAt == nil || !parsed.ClaimedAt.Equal(now) {
+		t.Errorf("Round-trip ClaimedAt = %v, want %v", parsed.ClaimedAt, now)
+	}
+	if len(parsed.History) != 1 || parsed.History[0].Actor != "user:alice" || !parsed.History[0].At.Equal(now) {
+		t.Errorf("Round-trip History = %+v, want one claim by user:alice", parsed.History)
+	}
 }
 
 func TestStoreOperations(t *testing.T) {
@@ -141,6 +217,19 @@ func TestStoreOperations(t *testing.T) {
 		t.Errorf("Loaded title = %q, want %q", loaded.Title, tk.Title)
 	}
 
+	// Test path
+	path, err := store.TaskPath(tk.ID)
+	if err != nil {
+		t.Fatalf("TaskPath failed: %v", err)
+	}
+	if want := filepath.Join(basePath, tk.ID+fileExt); path != want {
+		t.Errorf("TaskPath = %q, want %q", path, want)
+	}
+	var notFound TaskNotFoundError
+	if _, err = store.TaskPath("missing"); !errors.As(err, &notFound) {
+		t.Errorf("TaskPath(missing) error = %v, want TaskNotFoundError", err)
+	}
+
 	// Test list
 	tasks, err := store.List(StatusFilter{})
 	if err != nil {
@@ -267,6 +356,10 @@ func TestSanitizePath(t *testing.T) {
 		{"root path", "/", ""},
 		{"nested path", "/a/b/c/d/e", "a-b-c-d-e"},
 		{"trailing slash", "/Users/abatilo/project/", "Users-abatilo-project"},
+		{"windows drive path", `C:\Users\abatilo\myproject`, "C-Users-abatilo-myproject"},
+		{"windows path with spaces", `D:\Users\john doe\my project\`, "D-Users-john-doe-my-project"},
+		{"windows drive root", `C:\`, "C"},
+		{"windows UNC path", `\\server\share\project`, "server-share-project"},
 	}
 
 	for _, tt := range tests {
@@ -278,6 +371,217 @@ func TestSanitizePath(t *testing.T) {
 	}
 }
 
+func TestStoreKey(t *testing.T) {
+	dash, nested := StoreKey("/a/b-c"), StoreKey("/a/b/c")
+	if dash == nested {
+		t.Errorf("StoreKey collides for /a/b-c and /a/b/c: %q", dash)
+	}
+	if !strings.HasPrefix(dash, "a-b-c-") || !strings.HasPrefix(nested, "a-b-c-") {
+		t.Errorf("StoreKey = %q, %q, want the sanitized path as a prefix", dash, nested)
+	}
+	if got := StoreKey("/a/b-c"); got != dash {
+		t.Errorf("StoreKey is not stable: %q then %q", dash, got)
+	}
+	if got := StoreKey("/"); strings.HasPrefix(got, "-") {
+		t.Errorf("StoreKey(/) = %q, want no leading dash", got)
+	}
+	if StoreKey(`C:\a\b-c`) == StoreKey(`C:\a\b\c`) {
+		t.Error(`StoreKey collides for C:\a\b-c and C:\a\b\c`)
+	}
+	if StoreKey(`C:\a\b`) == StoreKey(`D:\a\b`) {
+		t.Error("StoreKey collides for the same path on different drives")
+	}
+}
+
+func TestMigrateStoreKey(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_DATA_HOME", "")
+	t.Setenv("XDG_STATE_HOME", "")
+	project := filepath.Join(t.TempDir(), "proj")
+	if err := os.MkdirAll(filepath.Join(project, ".git"), 0o755); err != nil {
+		t.Fatalf("MkdirAll failed: %v", err)
+	}
+	t.Chdir(project)
+	projectRoot, err := FindProjectRoot()
+	if err != nil {
+		t.Fatalf("FindProjectRoot failed: %v", err)
+	}
+	root := filepath.Join(home, ".bits")
+	legacy := NewStoreWithPath(filepath.Join(root, SanitizePath(projectRoot)))
+	tk, err := legacy.CreateTask("Old", "", task.PriorityMedium)
+	if err != nil {
+		t.Fatalf("CreateTask failed: %v", err)
+	}
+	if _, err = legacy.Backup(legacy.DefaultBackupDir(), 0); err != nil {
+		t.Fatalf("Backup failed: %v", err)
+	}
+
+	store, err := NewStore()
+	if err != nil {
+		t.Fatalf("NewStore failed: %v", err)
+	}
+	if store.BasePath() != legacy.BasePath() {
+		t.Errorf("NewStore before migrating = %q, want the existing %q", store.BasePath(), legacy.BasePath())
+	}
+
+	if _, _, err = MigrateStoreKey(true); err != nil {
+		t.Fatalf("MigrateStoreKey(check) failed: %v", err)
+	}
+	if !legacy.IsInitialized() {
+		t.Fatal("MigrateStoreKey(check) moved the store")
+	}
+
+	from, to, err := MigrateStoreKey(false)
+	if err != nil {
+		t.Fatalf("MigrateStoreKey failed: %v", err)
+	}
+	if from != legacy.BasePath() || to != filepath.Join(root, StoreKey(projectRoot)) {
+		t.Errorf("MigrateStoreKey = (%q, %q)", from, to)
+	}
+	if store, err = NewStore(); err != nil || store.BasePath() != to {
+		t.Fatalf("NewStore after migrating = %v, %v; want %q", store, err, to)
+	}
+	if _, err = store.Load(tk.ID); err != nil {
+		t.Errorf("Migrated task missing: %v", err)
+	}
+	if backups, _ := ListBackups(store.DefaultBackupDir()); len(backups) != 1 {
+		t.Errorf("Migrated backups = %d, want 1", len(backups))
+	}
+
+	var nothing NothingToMigrateError
+	if _, _, err = MigrateStoreKey(false); !errors.As(err, &nothing) {
+		t.Errorf("Second MigrateStoreKey error = %v, want NothingToMigrateError", err)
+	}
+}
+
+func TestLocalStore(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_DATA_HOME", "")
+	t.Setenv("XDG_STATE_HOME", "")
+	project := filepath.Join(t.TempDir(), "proj")
+	if err := os.MkdirAll(filepath.Join(project, ".git"), 0o755); err != nil {
+		t.Fatalf("MkdirAll failed: %v", err)
+	}
+	t.Chdir(project)
+	projectRoot, err := FindProjectRoot()
+	if err != nil {
+		t.Fatalf("FindProjectRoot failed: %v", err)
+	}
+
+	store, err := NewStore()
+	if err != nil || store.IsLocal() {
+		t.Fatalf("NewStore without .bits = %v, %v; want the data root store", store, err)
+	}
+
+	local, err := NewLocalStore()
+	if err != nil {
+		t.Fatalf("NewLocalStore failed: %v", err)
+	}
+	if err = local.InitLocal(); err != nil {
+		t.Fatalf("InitLocal failed: %v", err)
+	}
+	if _, err = os.Stat(filepath.Join(projectRoot, ".bits", ".gitignore")); err != nil {
+		t.Errorf("InitLocal wrote no .gitignore: %v", err)
+	}
+
+	if store, err = NewStore(); err != nil || !store.IsLocal() || store.BasePath() != LocalStorePath(projectRoot) {
+		t.Fatalf("NewStore with .bits = %v, %v; want %q", store, err, LocalStorePath(projectRoot))
+	}
+	if dir := store.DefaultBackupDir(); strings.HasPrefix(dir, projectRoot) {
+		t.Errorf("DefaultBackupDir = %q, want it outside the project", dir)
+	}
+
+	// The legacy data root in a repository at home isn't an in-repo store
+	if err = os.MkdirAll(filepath.Join(home, ".git"), 0o755); err != nil {
+		t.Fatalf("MkdirAll failed: %v", err)
+	}
+	if err = os.MkdirAll(filepath.Join(home, ".bits"), 0o755); err != nil {
+		t.Fatalf("MkdirAll failed: %v", err)
+	}
+	t.Chdir(home)
+	if store, err = NewStore(); err != nil || store.IsLocal() {
+		t.Errorf("NewStore at home = %v, %v; want the data root store", store, err)
+	}
+}
+
+func TestNewStoreWithMode(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_DATA_HOME", "")
+	project := filepath.Join(t.TempDir(), "proj")
+	if err := os.MkdirAll(filepath.Join(project, ".git"), 0o755); err != nil {
+		t.Fatalf("MkdirAll failed: %v", err)
+	}
+	t.Chdir(project)
+	projectRoot, err := FindProjectRoot()
+	if err != nil {
+		t.Fatalf("FindProjectRoot failed: %v", err)
+	}
+	root := t.TempDir()
+	homePath := projectStorePath(root, projectRoot)
+
+	tests := []struct {
+		name     string
+		hasLocal bool
+		mode     StoreMode
+		want     string
+	}{
+		{name: "auto without .bits", mode: StoreAuto, want: homePath},
+		{name: "local without .bits", mode: StoreLocal, want: LocalStorePath(projectRoot)},
+		{name: "home without .bits", mode: StoreHome, want: homePath},
+		{name: "auto with .bits", hasLocal: true, mode: StoreAuto, want: LocalStorePath(projectRoot)},
+		{name: "local with .bits", hasLocal: true, mode: StoreLocal, want: LocalStorePath(projectRoot)},
+		{name: "home with .bits", hasLocal: true, mode: StoreHome, want: homePath},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.hasLocal {
+				if err := os.MkdirAll(LocalStorePath(projectRoot), 0o755); err != nil {
+					t.Fatalf("MkdirAll failed: %v", err)
+				}
+			}
+			store, err := NewStoreWithMode(root, tt.mode)
+			if err != nil {
+				t.Fatalf("NewStoreWithMode failed: %v", err)
+			}
+			if store.BasePath() != tt.want || store.IsLocal() != (tt.want == LocalStorePath(projectRoot)) {
+				t.Errorf("NewStoreWithMode(%q) = %q (local %v), want %q",
+					tt.mode, store.BasePath(), store.IsLocal(), tt.want)
+			}
+		})
+	}
+}
+
+func TestStoreEnvOverrides(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_DATA_HOME", "")
+	t.Chdir(t.TempDir()) // Not a repository
+
+	if _, err := NewStore(); !errors.As(err, new(NotInRepoError)) {
+		t.Fatalf("NewStore outside a repository = %v, want NotInRepoError", err)
+	}
+
+	project, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatalf("EvalSymlinks failed: %v", err)
+	}
+	t.Setenv(ProjectRootEnv, project)
+	if root, _ := FindProjectRoot(); root != project {
+		t.Errorf("FindProjectRoot with %s = %q, want %q", ProjectRootEnv, root, project)
+	}
+	store, err := NewStore()
+	if err != nil || !strings.HasSuffix(store.BasePath(), StoreKey(project)) {
+		t.Errorf("NewStore with %s = %v, %v; want a store keyed by %q", ProjectRootEnv, store, err, project)
+	}
+
+	dir := filepath.Join(t.TempDir(), "scratch")
+	t.Setenv(DirEnv, dir)
+	if store, err = NewStore(); err != nil || store.BasePath() != dir {
+		t.Errorf("NewStore with %s = %v, %v; want %q", DirEnv, store, err, dir)
+	}
+}
+
 //nolint:gocognit // Test setup/teardown requires multiple nested subtests
 func TestFindProjectRoot(t *testing.T) {
 	// Create temp directory structure
@@ -352,6 +656,9 @@ func TestFindProjectRoot(t *testing.T) {
 		// Create a symlink pointing to the real directory
 		symlinkDir := filepath.Join(tmpDir, "symlink-project")
 		if err := os.Symlink(realDir, symlinkDir); err != nil { //nolint:govet // Intentional shadow in subtest
+			if runtime.GOOS == "windows" {
+				t.Skipf("Creating symlinks needs Developer Mode on Windows: %v", err)
+			}
 			t.Fatalf("Failed to create symlink: %v", err)
 		}
 		defer os.Remove(symlinkDir)
@@ -394,3 +701,2238 @@ func TestFindProjectRoot(t *testing.T) {
 		}
 	})
 }
+
+func TestMarkdownNotes(t *testing.T) {
+	now := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
+	tk := &task.Task{ID: "abc123", Title: "Test", Status: "open", Priority: "medium", CreatedAt: now}
+	tk.Description = "Description here"
+	tk.AddNote(now, "user:alice", "Reproduced\non staging")
+	tk.AddNote(now.Add(time.Hour), "", "Fixed")
+
+	data, err := SerializeMarkdown(tk)
+	if err != nil {
+		t.Fatalf("SerializeMarkdown failed: %v", err)
+	}
+	parsed, err := ParseMarkdown(data)
+	if err != nil {
+		t.Fatalf("ParseMarkdown failed: %v", err)
+	}
+	if parsed.Description != tk.Description {
+		t.Errorf("Round-trip Description = %q, want %q", parsed.Description, tk.Description)
+	}
+	if len(parsed.Notes) != 2 {
+		t.Fatalf("Round-trip Notes = %+v, want 2", parsed.Notes)
+	}
+	for i, n := range tk.Notes {
+		if got := parsed.Notes[i]; !got.At.Equal(n.At) || got.Actor != n.Actor || got.Text != n.Text {
+			t.Errorf("Round-trip Notes[%d] = %+v, want %+v", i, got, n)
+		}
+	}
+
+	// A "## Notes" heading of the description's own stays in the description
+	body := "Plan\n\n## Notes\n\nSee the design doc."
+	if desc, notes := splitNotes(body); desc != body || notes != nil {
+		t.Errorf("splitNotes(%q) = %q, %+v; want it all as description", body, desc, notes)
+	}
+}
+
+func TestDependentsIndex(t *testing.T) {
+	store := NewStoreWithPath(filepath.Join(t.TempDir(), ".bits"))
+
+	a, err := store.CreateTask("A", "", task.PriorityMedium)
+	if err != nil {
+		t.Fatalf("CreateTask failed: %v", err)
+	}
+	b, _ := store.CreateTask("B", "", task.PriorityMedium)
+	c, _ := store.CreateTask("C", "", task.PriorityMedium)
+
+	b.DependsOn = []string{a.ID}
+	c.DependsOn = []string{a.ID, b.ID}
+	if err = store.Save(b); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if err = store.Save(c); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	dependents, err := store.Dependents(a.ID)
+	if err != nil {
+		t.Fatalf("Dependents failed: %v", err)
+	}
+	if len(dependents) != 2 {
+		t.Errorf("Dependents(a) = %v, want 2 entries", dependents)
+	}
+
+	// Dropping an edge updates the reverse mapping
+	c.DependsOn = []string{b.ID}
+	if err = store.Save(c); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	dependents, _ = store.Dependents(a.ID)
+	if len(dependents) != 1 || dependents[0] != b.ID {
+		t.Errorf("Dependents(a) = %v, want [%s]", dependents, b.ID)
+	}
+
+	// A fresh store rebuilds the index when the file is missing
+	if err = os.Remove(filepath.Join(store.BasePath(), indexFile)); err != nil {
+		t.Fatalf("Remove index failed: %v", err)
+	}
+	fresh := NewStoreWithPath(store.BasePath())
+	dependents, err = fresh.Dependents(b.ID)
+	if err != nil {
+		t.Fatalf("Dependents after rebuild failed: %v", err)
+	}
+	if len(dependents) != 1 || dependents[0] != c.ID {
+		t.Errorf("Dependents(b) after rebuild = %v, want [%s]", dependents, c.ID)
+	}
+
+	// Deleting a task removes its outgoing edges
+	if err = fresh.Delete(c.ID); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	dependents, _ = fresh.Dependents(b.ID)
+	if len(dependents) != 0 {
+		t.Errorf("Dependents(b) after delete = %v, want none", dependents)
+	}
+}
+
+func TestIndexNoticesOutsideChanges(t *testing.T) {
+	store := NewStoreWithPath(filepath.Join(t.TempDir(), ".bits"))
+	a, _ := store.CreateTask("A", "", task.PriorityMedium)
+	b, _ := store.CreateTask("B", "", task.PriorityMedium)
+	b.DependsOn = []string{a.ID}
+	if err := store.Save(b); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if _, err := NewStoreWithPath(store.BasePath()).Dependents(a.ID); err != nil {
+		t.Fatalf("Dependents failed: %v", err)
+	}
+
+	// A git pull drops B's dependency and brings in C, which depends on A.
+	write := func(tk *task.Task) {
+		content, err := SerializeMarkdown(tk)
+		if err != nil {
+			t.Fatalf("SerializeMarkdown failed: %v", err)
+		}
+		if err = os.WriteFile(store.livePath(tk.ID), content, 0o644); err != nil {
+			t.Fatalf("WriteFile failed: %v", err)
+		}
+	}
+	b.DependsOn = nil
+	write(b)
+	c := &task.Task{
+		ID:        "zzz",
+		Title:     "C",
+		Status:    task.StatusOpen,
+		Priority:  task.PriorityMedium,
+		CreatedAt: time.Now().UTC(),
+		DependsOn: []string{a.ID},
+	}
+	write(c)
+
+	fresh := NewStoreWithPath(store.BasePath())
+	dependents, err := fresh.Dependents(a.ID)
+	if err != nil {
+		t.Fatalf("Dependents failed: %v", err)
+	}
+	if !slices.Equal(dependents, []string{c.ID}) {
+		t.Errorf("Dependents(a) after pull = %v, want [%s]", dependents, c.ID)
+	}
+
+	// The rebuilt index still reports the changes until they are accepted.
+	kinds := make(map[string]IssueKind)
+	issues, err := NewStoreWithPath(store.BasePath()).Verify()
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	for _, issue := range issues {
+		kinds[issue.ID] = issue.Kind
+	}
+	if kinds[b.ID] != IssueModified || kinds[c.ID] != IssueUntracked {
+		t.Errorf("Verify after pull = %+v, want B modified and C untracked", issues)
+	}
+	if err = fresh.RebuildIndex(); err != nil {
+		t.Fatalf("RebuildIndex failed: %v", err)
+	}
+	if issues, _ = fresh.Verify(); len(issues) != 0 {
+		t.Errorf("Verify after RebuildIndex = %+v, want none", issues)
+	}
+}
+
+func TestIndexChecksDirsFirst(t *testing.T) {
+	store := NewStoreWithPath(filepath.Join(t.TempDir(), ".bits"))
+	a, _ := store.CreateTask("A", "", task.PriorityMedium)
+
+	// Stale file stamps go unchecked while the directories are unchanged.
+	idx := store.readIndex()
+	idx.Files = map[string]fileStamp{}
+	data, _ := json.Marshal(idx)
+	if err := os.WriteFile(store.indexPath(), data, 0o644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	loaded, err := NewStoreWithPath(store.BasePath()).loadIndex()
+	if err != nil {
+		t.Fatalf("loadIndex failed: %v", err)
+	}
+	if len(loaded.Files) != 0 {
+		t.Errorf("index rebuilt although no directory changed: %v", loaded.Files)
+	}
+
+	content, _ := os.ReadFile(store.livePath(a.ID))
+	if err = os.WriteFile(store.livePath("zzz"), bytes.Replace(content, []byte(a.ID), []byte("zzz"), 1),
+		0o644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	loaded, err = NewStoreWithPath(store.BasePath()).loadIndex()
+	if err != nil {
+		t.Fatalf("loadIndex failed: %v", err)
+	}
+	if len(loaded.Files) != 2 || loaded.Tasks["zzz"].Status != task.StatusOpen {
+		t.Errorf("index after adding a file stamps %v, want it rebuilt with zzz", loaded.Files)
+	}
+}
+
+func TestRemoveDependency(t *testing.T) {
+	store := NewStoreWithPath(filepath.Join(t.TempDir(), ".bits"))
+
+	a, _ := store.CreateTask("A", "", task.PriorityMedium)
+	b, _ := store.CreateTask("B", "", task.PriorityMedium)
+	b.DependsOn = []string{a.ID}
+	if err := store.Save(b); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	if err := store.RemoveDependency(a.ID); err != nil {
+		t.Fatalf("RemoveDependency failed: %v", err)
+	}
+
+	loaded, err := store.Load(b.ID)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(loaded.DependsOn) != 0 {
+		t.Errorf("DependsOn = %v, want empty", loaded.DependsOn)
+	}
+	dependents, _ := store.Dependents(a.ID)
+	if len(dependents) != 0 {
+		t.Errorf("Dependents(a) = %v, want none", dependents)
+	}
+}
+
+func TestChildren(t *testing.T) {
+	store := NewStoreWithPath(filepath.Join(t.TempDir(), ".bits"))
+
+	parent, _ := store.CreateTask("Parent", "", task.PriorityMedium)
+	open, _ := store.CreateTask("Open child", "", task.PriorityMedium)
+	done, _ := store.CreateTask("Closed child", "", task.PriorityMedium)
+	open.Parent = parent.ID
+	done.Parent = parent.ID
+	done.Status = task.StatusClosed
+	for _, tk := range []*task.Task{open, done} {
+		if err := store.Save(tk); err != nil {
+			t.Fatalf("Save failed: %v", err)
+		}
+	}
+
+	children, err := store.Children(parent.ID, StatusFilter{})
+	if err != nil {
+		t.Fatalf("Children failed: %v", err)
+	}
+	if len(children) != 2 {
+		t.Errorf("Children = %v, want both subtasks", children)
+	}
+	unfinished, _ := store.Children(parent.ID, StatusFilter{Open: true, Active: true})
+	if len(unfinished) != 1 || unfinished[0] != open.ID {
+		t.Errorf("unfinished Children = %v, want [%s]", unfinished, open.ID)
+	}
+
+	// Removing the parent makes its subtasks top-level
+	if err = store.RemoveDependency(parent.ID); err != nil {
+		t.Fatalf("RemoveDependency failed: %v", err)
+	}
+	loaded, err := store.Load(open.ID)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if loaded.Parent != "" {
+		t.Errorf("Parent = %q after removing it, want empty", loaded.Parent)
+	}
+	if children, _ = store.Children(parent.ID, StatusFilter{}); len(children) != 0 {
+		t.Errorf("Children after RemoveDependency = %v, want none", children)
+	}
+}
+
+func TestWalk(t *testing.T) {
+	store := NewStoreWithPath(filepath.Join(t.TempDir(), ".bits"))
+
+	for _, title := range []string{"A", "B", "C"} {
+		if _, err := store.CreateTask(title, "", task.PriorityMedium); err != nil {
+			t.Fatalf("CreateTask failed: %v", err)
+		}
+	}
+	closed, _ := store.CreateTask("D", "", task.PriorityMedium)
+	closed.Status = task.StatusClosed
+	if err := store.Save(closed); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	count := 0
+	err := store.Walk(StatusFilter{Open: true}, func(tk *task.Task) error {
+		if tk.Status != task.StatusOpen {
+			t.Errorf("Walk visited %s with status %s", tk.ID, tk.Status)
+		}
+		count++
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Walk failed: %v", err)
+	}
+	if count != 3 {
+		t.Errorf("Walk visited %d tasks, want 3", count)
+	}
+
+	// An error from fn stops the walk and is returned
+	errStop := errors.New("stop")
+	visited := 0
+	err = store.Walk(StatusFilter{}, func(_ *task.Task) error {
+		visited++
+		return errStop
+	})
+	if !errors.Is(err, errStop) {
+		t.Errorf("Walk error = %v, want %v", err, errStop)
+	}
+	if visited != 1 {
+		t.Errorf("Walk visited %d tasks after stop, want 1", visited)
+	}
+}
+
+func TestMergeTasks(t *testing.T) {
+	created := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
+	closedAt := created.Add(time.Hour)
+	reason := "done"
+
+	base := &task.Task{
+		ID:        "abc",
+		Title:     "Original",
+		Status:    task.StatusOpen,
+		Priority:  task.PriorityMedium,
+		CreatedAt: created,
+		DependsOn: []string{"d1", "d2"},
+	}
+
+	ours := *base
+	ours.Title = "Renamed"
+	ours.DependsOn = []string{"d1", "d2", "d3"}
+
+	theirs := *base
+	theirs.Status = task.StatusClosed
+	theirs.ClosedAt = &closedAt
+	theirs.CloseReason = &reason
+	theirs.Priority = task.PriorityHigh
+	theirs.DependsOn = []string{"d1"}
+	due := created.Add(48 * time.Hour)
+	theirs.DueAt = &due
+	theirs.DependsAny = [][]string{{"e1", "e2"}}
+
+	merged := MergeTasks(base, &ours, &theirs)
+
+	if merged.Title != "Renamed" {
+		t.Errorf("Title = %q, want %q", merged.Title, "Renamed")
+	}
+	if merged.Priority != task.PriorityHigh {
+		t.Errorf("Priority = %q, want %q", merged.Priority, task.PriorityHigh)
+	}
+	if merged.Status != task.StatusClosed {
+		t.Errorf("Status = %q, want %q", merged.Status, task.StatusClosed)
+	}
+	if merged.CloseReason == nil || *merged.CloseReason != reason {
+		t.Errorf("CloseReason = %v, want %q", merged.CloseReason, reason)
+	}
+	if len(merged.DependsAny) != 1 || len(merged.DependsAny[0]) != 2 {
+		t.Errorf("DependsAny = %v, want [[e1 e2]]", merged.DependsAny)
+	}
+	if merged.DueAt == nil || !merged.DueAt.Equal(due) {
+		t.Errorf("DueAt = %v, want %v", merged.DueAt, due)
+	}
+	// d2 removed by theirs, d3 added by ours
+	if len(merged.DependsOn) != 2 || merged.DependsOn[0] != "d1" || merged.DependsOn[1] != "d3" {
+		t.Errorf("DependsOn = %v, want [d1 d3]", merged.DependsOn)
+	}
+}
+
+func TestMergeTasksConflictingStatus(t *testing.T) {
+	base := &task.Task{ID: "abc", Status: task.StatusOpen}
+	ours := &task.Task{ID: "abc", Status: task.StatusActive}
+	theirs := &task.Task{ID: "abc", Status: task.StatusClosed}
+
+	if got := MergeTasks(base, ours, theirs).Status; got != task.StatusClosed {
+		t.Errorf("Status = %q, want %q", got, task.StatusClosed)
+	}
+	if got := MergeTasks(base, theirs, ours).Status; got != task.StatusClosed {
+		t.Errorf("Status (swapped) = %q, want %q", got, task.StatusClosed)
+	}
+
+	// Without a common ancestor, dependency lists are unioned
+	ours.DependsOn = []string{"a"}
+	theirs.DependsOn = []string{"b"}
+	if got := MergeTasks(nil, ours, theirs).DependsOn; len(got) != 2 {
+		t.Errorf("DependsOn without base = %v, want [a b]", got)
+	}
+}
+
+func TestMergeTasksAcceptance(t *testing.T) {
+	base := &task.Task{ID: "abc", Acceptance: []task.Criterion{{Text: "tests"}, {Text: "docs"}}}
+	ours := &task.Task{ID: "abc", Acceptance: []task.Criterion{{Text: "tests", Done: true}, {Text: "docs"}}}
+	theirs := &task.Task{ID: "abc", Acceptance: []task.Criterion{{Text: "tests"}, {Text: "docs"}, {Text: "demo"}}}
+
+	got := MergeTasks(base, ours, theirs).Acceptance
+	want := []task.Criterion{{Text: "tests", Done: true}, {Text: "docs"}, {Text: "demo"}}
+	if !slices.Equal(got, want) {
+		t.Errorf("Acceptance = %+v, want %+v", got, want)
+	}
+}
+
+func TestSyncOfflineQueue(t *testing.T) {
+	tmpDir := t.TempDir()
+	remote := filepath.Join(tmpDir, "remote")
+	store := NewStoreWithPath(filepath.Join(tmpDir, "local"))
+
+	existing, err := store.CreateTask("Existing", "", task.PriorityMedium)
+	if err != nil {
+		t.Fatalf("CreateTask failed: %v", err)
+	}
+
+	// Remote does not exist yet: configuring queues existing tasks
+	if err = store.SetSyncRemote(remote); err != nil {
+		t.Fatalf("SetSyncRemote failed: %v", err)
+	}
+	queued, _ := store.CreateTask("Queued while offline", "", task.PriorityHigh)
+
+	_, err = store.Sync()
+	var unreachable SyncUnreachableError
+	if !errors.As(err, &unreachable) {
+		t.Fatalf("Sync error = %v, want SyncUnreachableError", err)
+	}
+	if unreachable.Queued != 2 {
+		t.Errorf("Queued = %d, want 2", unreachable.Queued)
+	}
+
+	// Remote comes back: queue is replayed
+	if err = os.MkdirAll(remote, 0o755); err != nil {
+		t.Fatalf("MkdirAll failed: %v", err)
+	}
+	result, err := store.Sync()
+	if err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	if result.Pushed != 2 {
+		t.Errorf("Pushed = %d, want 2", result.Pushed)
+	}
+	for _, id := range []string{existing.ID, queued.ID} {
+		if _, err = os.Stat(filepath.Join(remote, id+fileExt)); err != nil {
+			t.Errorf("Remote missing task %s: %v", id, err)
+		}
+	}
+	if pending, _ := store.PendingSyncOps(); pending != 0 {
+		t.Errorf("PendingSyncOps = %d, want 0", pending)
+	}
+
+	// A second store sharing the remote pulls the tasks and deletions propagate
+	other := NewStoreWithPath(filepath.Join(tmpDir, "other"))
+	if err = other.SetSyncRemote(remote); err != nil {
+		t.Fatalf("SetSyncRemote failed: %v", err)
+	}
+	if _, err = other.Sync(); err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	if !other.Exists(queued.ID) {
+		t.Error("Other store should have pulled queued task")
+	}
+
+	if err = store.Delete(existing.ID); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, err = store.Sync(); err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	if _, err = other.Sync(); err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	if other.Exists(existing.ID) {
+		t.Error("Deletion should propagate to other store")
+	}
+}
+
+func TestSyncKeepsLocalOnlyTasks(t *testing.T) {
+	tmpDir := t.TempDir()
+	remote := filepath.Join(tmpDir, "remote")
+	if err := os.MkdirAll(remote, 0o755); err != nil {
+		t.Fatalf("MkdirAll failed: %v", err)
+	}
+	store := NewStoreWithPath(filepath.Join(tmpDir, "local"))
+	if err := store.SetSyncRemote(remote); err != nil {
+		t.Fatalf("SetSyncRemote failed: %v", err)
+	}
+	synced, _ := store.CreateTask("Synced", "", task.PriorityMedium)
+	if _, err := store.Sync(); err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+
+	// A task whose save never reached the outbox, and a synced one deleted remotely
+	local, _ := store.CreateTask("Local only", "", task.PriorityMedium)
+	if err := os.Remove(store.outboxPath()); err != nil {
+		t.Fatalf("Remove outbox failed: %v", err)
+	}
+	if err := os.Remove(filepath.Join(remote, synced.ID+fileExt)); err != nil {
+		t.Fatalf("Remove remote task failed: %v", err)
+	}
+
+	result, err := store.Sync()
+	if err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	if store.Exists(synced.ID) {
+		t.Error("task deleted remotely should be deleted locally")
+	}
+	if !store.Exists(local.ID) {
+		t.Fatal("local-only task was deleted")
+	}
+	if result.Queued != 1 {
+		t.Errorf("Queued = %d, want the local-only task queued", result.Queued)
+	}
+
+	if _, err = store.Sync(); err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	if _, err = os.Stat(filepath.Join(remote, local.ID+fileExt)); err != nil {
+		t.Errorf("local-only task not pushed by the next sync: %v", err)
+	}
+	if !store.Exists(local.ID) {
+		t.Error("local-only task deleted by the next sync")
+	}
+}
+
+func TestSyncReplayIsIdempotent(t *testing.T) {
+	tmpDir := t.TempDir()
+	remote := filepath.Join(tmpDir, "remote")
+	if err := os.MkdirAll(remote, 0o755); err != nil {
+		t.Fatalf("MkdirAll failed: %v", err)
+	}
+	store := NewStoreWithPath(filepath.Join(tmpDir, "local"))
+	if err := store.SetSyncRemote(remote); err != nil {
+		t.Fatalf("SetSyncRemote failed: %v", err)
+	}
+	if _, err := store.CreateTask("Task", "", task.PriorityMedium); err != nil {
+		t.Fatalf("CreateTask failed: %v", err)
+	}
+
+	// Simulate a sync interrupted after pushing but before clearing the outbox
+	outbox, err := os.ReadFile(store.outboxPath())
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	if _, err = store.Sync(); err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	if err = os.WriteFile(store.outboxPath(), outbox, 0o644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+
+	result, err := store.Sync()
+	if err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	if result.Pushed != 0 {
+		t.Errorf("Replayed Pushed = %d, want 0", result.Pushed)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	store := NewStoreWithPath(filepath.Join(t.TempDir(), ".bits"))
+
+	a, err := store.CreateTask("A", "", task.PriorityMedium)
+	if err != nil {
+		t.Fatalf("CreateTask failed: %v", err)
+	}
+	b, _ := store.CreateTask("B", "", task.PriorityMedium)
+
+	issues, err := store.Verify()
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if len(issues) != 0 {
+		t.Fatalf("Verify on clean store = %v, want none", issues)
+	}
+
+	// Out-of-band edit that also breaks an invariant
+	content, _ := os.ReadFile(store.taskPath(a.ID))
+	edited := append(content, []byte("\nedited by hand\n")...)
+	edited = []byte(strings.Replace(string(edited), "priority: medium", "priority: urgent", 1))
+	if err = os.WriteFile(store.taskPath(a.ID), edited, 0o644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	// File removed behind bits' back
+	if err = os.Remove(store.taskPath(b.ID)); err != nil {
+		t.Fatalf("Remove failed: %v", err)
+	}
+
+	issues, err = store.Verify()
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	kinds := make(map[string][]IssueKind)
+	for _, issue := range issues {
+		kinds[issue.ID] = append(kinds[issue.ID], issue.Kind)
+	}
+	if !slices.Contains(kinds[a.ID], IssueModified) || !slices.Contains(kinds[a.ID], IssueInvalid) {
+		t.Errorf("Issues for %s = %v, want modified and invalid", a.ID, kinds[a.ID])
+	}
+	if !slices.Contains(kinds[b.ID], IssueMissing) {
+		t.Errorf("Issues for %s = %v, want missing", b.ID, kinds[b.ID])
+	}
+
+	// Rebuilding the index accepts the edit; the invariant violation remains
+	if err = store.RebuildIndex(); err != nil {
+		t.Fatalf("RebuildIndex failed: %v", err)
+	}
+	issues, _ = store.Verify()
+	if len(issues) != 1 || issues[0].Kind != IssueInvalid {
+		t.Errorf("Verify after rebuild = %v, want single invalid issue", issues)
+	}
+}
+
+func TestSnapshotRestore(t *testing.T) {
+	store := NewStoreWithPath(filepath.Join(t.TempDir(), ".bits"))
+
+	a, err := store.CreateTask("A", "", task.PriorityMedium)
+	if err != nil {
+		t.Fatalf("CreateTask failed: %v", err)
+	}
+	b, _ := store.CreateTask("B", "", task.PriorityMedium)
+
+	snap, err := store.Snapshot("prune")
+	if err != nil {
+		t.Fatalf("Snapshot failed: %v", err)
+	}
+	if snap.Tasks != 2 || snap.Reason != "prune" {
+		t.Errorf("Snapshot = %+v, want 2 tasks with reason prune", snap)
+	}
+
+	// Wipe everything; snapshots survive a forced init
+	if err = store.Init(true); err != nil {
+		t.Fatalf("Init(true) failed: %v", err)
+	}
+	c, _ := store.CreateTask("C", "", task.PriorityMedium)
+
+	snapshots, err := store.Snapshots()
+	if err != nil {
+		t.Fatalf("Snapshots failed: %v", err)
+	}
+	if len(snapshots) != 1 || snapshots[0].Name != snap.Name {
+		t.Fatalf("Snapshots = %v, want [%s]", snapshots, snap.Name)
+	}
+
+	if err = store.RestoreSnapshot(snap.Name); err != nil {
+		t.Fatalf("RestoreSnapshot failed: %v", err)
+	}
+	if !store.Exists(a.ID) || !store.Exists(b.ID) {
+		t.Error("Restored store should contain snapshotted tasks")
+	}
+	if store.Exists(c.ID) {
+		t.Error("Restored store should not contain tasks created after the snapshot")
+	}
+
+	// Restoring snapshots the pre-restore state first
+	snapshots, _ = store.Snapshots()
+	if len(snapshots) != 2 || snapshots[0].Reason != "restore" {
+		t.Errorf("Snapshots after restore = %v, want restore snapshot first", snapshots)
+	}
+
+	var notFound SnapshotNotFoundError
+	if err = store.RestoreSnapshot("nope"); !errors.As(err, &notFound) {
+		t.Errorf("RestoreSnapshot(nope) error = %v, want SnapshotNotFoundError", err)
+	}
+}
+
+func TestSelectTasks(t *testing.T) {
+	created := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	newTask := func(id, title string, status task.Status, priority task.Priority, age time.Duration) *task.Task {
+		return &task.Task{ID: id, Title: title, Status: status, Priority: priority, CreatedAt: created.Add(-age)}
+	}
+	tasks := map[string]*task.Task{
+		"aaa": newTask("aaa", "Fix login", task.StatusOpen, task.PriorityLow, 0),
+		"bbb": newTask("bbb", "Login page", task.StatusOpen, task.PriorityHigh, 0),
+		"ccc": newTask("ccc", "Fix signup", task.StatusActive, task.PriorityHigh, 0),
+		"ddd": newTask("ddd", "Fix logout", task.StatusClosed, task.PriorityCritical, 0),
+		"eee": newTask("eee", "Fix header", task.StatusOpen, task.PriorityHigh, time.Hour),
+	}
+	fix := func(t *task.Task) bool { return strings.HasPrefix(t.Title, "Fix") }
+
+	tests := []struct {
+		name     string
+		ids      []string
+		statuses StatusFilter
+		match    func(*task.Task) bool
+		want     []string
+	}{
+		{"ids in order", []string{"ccc", "aaa"}, StatusFilter{}, nil, []string{"ccc", "aaa"}},
+		{"repeated ids", []string{"aaa", "bbb", "aaa"}, StatusFilter{}, nil, []string{"aaa", "bbb"}},
+		{"ids ignore statuses", []string{"ddd"}, StatusFilter{Open: true}, nil, []string{"ddd"}},
+		{"match by priority", nil, StatusFilter{Open: true, Active: true}, fix, []string{"eee", "ccc", "aaa"}},
+		{"match in statuses", nil, StatusFilter{Closed: true}, fix, []string{"ddd"}},
+		{"match every status", nil, StatusFilter{}, fix, []string{"ddd", "eee", "ccc", "aaa"}},
+		{"match nothing", nil, StatusFilter{Open: true}, func(*task.Task) bool { return false }, nil},
+	}
+	for _, tt := range tests {
+		picked, err := SelectTasks(tasks, tt.ids, tt.statuses, tt.match)
+		if err != nil {
+			t.Errorf("%s: SelectTasks failed: %v", tt.name, err)
+			continue
+		}
+		var got []string
+		for _, p := range picked {
+			got = append(got, p.ID)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%s: SelectTasks = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	var notFound TaskNotFoundError
+	if _, err := SelectTasks(tasks, []string{"aaa", "zzz"}, StatusFilter{}, nil); !errors.As(err, &notFound) {
+		t.Errorf("SelectTasks with an unknown ID = %v, want TaskNotFoundError", err)
+	}
+}
+
+func TestSaveAllRollsBack(t *testing.T) {
+	store := NewStoreWithPath(filepath.Join(t.TempDir(), ".bits"))
+	a, _ := store.CreateTask("A", "", task.PriorityMedium)
+	b, _ := store.CreateTask("B", "", task.PriorityMedium)
+
+	// closed/ can't be created, so closing B fails after A is saved
+	if err := os.WriteFile(filepath.Join(store.BasePath(), closedDir), nil, 0o644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	a.Title = "A renamed"
+	b.Close(time.Now().UTC(), "Done", "tester")
+	if err := store.SaveAll("batch", []*task.Task{a, b}); err == nil {
+		t.Fatal("SaveAll succeeded although closing B couldn't be saved")
+	}
+
+	if loaded, _ := store.Load(a.ID); loaded.Title != "A" {
+		t.Errorf("A title = %q after a failed SaveAll, want it rolled back", loaded.Title)
+	}
+	if loaded, _ := store.Load(b.ID); loaded.Status != task.StatusOpen {
+		t.Errorf("B status = %s after a failed SaveAll, want open", loaded.Status)
+	}
+}
+
+func TestSaveAllRollbackLeavesJournal(t *testing.T) {
+	store := NewStoreWithPath(filepath.Join(t.TempDir(), ".bits"))
+	a, _ := store.CreateTask("A", "", task.PriorityMedium)
+	b, _ := store.CreateTask("B", "", task.PriorityMedium)
+
+	store.BeginOp("batch", "tester")
+	c, err := store.CreateTask("C", "", task.PriorityMedium)
+	if err != nil {
+		t.Fatalf("CreateTask failed: %v", err)
+	}
+	if err = os.WriteFile(filepath.Join(store.BasePath(), closedDir), nil, 0o644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	a.Title = "A renamed"
+	b.Close(time.Now().UTC(), "Done", "tester")
+	if err = store.SaveAll("batch", []*task.Task{a, b}); err == nil {
+		t.Fatal("SaveAll succeeded although closing B couldn't be saved")
+	}
+	if err = store.EndOp(); err != nil {
+		t.Fatalf("EndOp failed: %v", err)
+	}
+
+	entries, err := store.Journal()
+	if err != nil {
+		t.Fatalf("Journal failed: %v", err)
+	}
+	if len(entries) != 1 || !slices.Equal(entries[0].TaskIDs(), []string{c.ID}) {
+		t.Fatalf("journal = %+v, want one entry creating only C", entries)
+	}
+	if entries[0].Changes[0].Before != "" {
+		t.Errorf("C's change has a before, want a creation")
+	}
+}
+
+func TestBatchWritesIndexOnce(t *testing.T) {
+	store := NewStoreWithPath(filepath.Join(t.TempDir(), ".bits"))
+	var tasks []*task.Task
+	for _, title := range []string{"A", "B", "C"} {
+		tk, _ := store.CreateTask(title, "", task.PriorityMedium)
+		tasks = append(tasks, tk)
+	}
+	before, err := os.ReadFile(store.indexPath())
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+
+	err = store.batchWrites(func() error {
+		for _, tk := range tasks {
+			tk.Close(time.Now().UTC(), "Done", "tester")
+			if saveErr := store.Save(tk); saveErr != nil {
+				return saveErr
+			}
+			if during, _ := os.ReadFile(store.indexPath()); !bytes.Equal(during, before) {
+				t.Errorf("index written after saving %s, before the batch ended", tk.Title)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("batchWrites failed: %v", err)
+	}
+
+	saved := NewStoreWithPath(store.BasePath()).readIndex()
+	if saved == nil {
+		t.Fatal("index unreadable after the batch")
+	}
+	files, err := store.taskFiles()
+	if err != nil {
+		t.Fatalf("taskFiles failed: %v", err)
+	}
+	if !maps.Equal(saved.Files, files) {
+		t.Errorf("saved index stamps %v, want %v", saved.Files, files)
+	}
+	for _, tk := range tasks {
+		if saved.Tasks[tk.ID].Status != task.StatusClosed {
+			t.Errorf("saved index has %s %s, want closed", tk.Title, saved.Tasks[tk.ID].Status)
+		}
+	}
+}
+
+func TestRemoveAllRollsBack(t *testing.T) {
+	store := NewStoreWithPath(filepath.Join(t.TempDir(), ".bits"))
+	a, _ := store.CreateTask("A", "", task.PriorityMedium)
+	b, _ := store.CreateTask("B", "", task.PriorityMedium)
+	b.DependsOn = []string{a.ID}
+	if err := store.Save(b); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	var notFound TaskNotFoundError
+	if err := store.RemoveAll([]string{a.ID, "zzz"}); !errors.As(err, &notFound) {
+		t.Fatalf("RemoveAll with an unknown ID = %v, want TaskNotFoundError", err)
+	}
+	if !store.Exists(a.ID) {
+		t.Error("A was removed although RemoveAll failed")
+	}
+	if loaded, _ := store.Load(b.ID); !slices.Equal(loaded.DependsOn, []string{a.ID}) {
+		t.Errorf("B DependsOn = %v after a failed RemoveAll, want [%s]", loaded.DependsOn, a.ID)
+	}
+
+	if err := store.RemoveAll([]string{a.ID, b.ID}); err != nil {
+		t.Fatalf("RemoveAll failed: %v", err)
+	}
+	if store.Exists(a.ID) || store.Exists(b.ID) {
+		t.Error("RemoveAll left tasks behind")
+	}
+}
+
+func TestSnapshotRetention(t *testing.T) {
+	store := NewStoreWithPath(filepath.Join(t.TempDir(), ".bits"))
+	for range snapshotRetention + 3 {
+		if _, err := store.Snapshot("rm"); err != nil {
+			t.Fatalf("Snapshot failed: %v", err)
+		}
+	}
+	snapshots, err := store.Snapshots()
+	if err != nil {
+		t.Fatalf("Snapshots failed: %v", err)
+	}
+	if len(snapshots) != snapshotRetention {
+		t.Errorf("Snapshots = %d, want %d", len(snapshots), snapshotRetention)
+	}
+}
+
+func TestDestroy(t *testing.T) {
+	tmpDir := t.TempDir()
+	store := NewStoreWithPath(filepath.Join(tmpDir, "store"))
+
+	var notInit NotInitializedError
+	if err := store.Destroy(); !errors.As(err, &notInit) {
+		t.Fatalf("Destroy on a missing store = %v, want NotInitializedError", err)
+	}
+
+	if _, err := store.CreateTask("A", "Body", task.PriorityHigh); err != nil {
+		t.Fatalf("CreateTask failed: %v", err)
+	}
+	if _, err := store.Snapshot("test"); err != nil {
+		t.Fatalf("Snapshot failed: %v", err)
+	}
+
+	store.SetDryRun(io.Discard)
+	if err := store.Destroy(); err != nil {
+		t.Fatalf("dry-run Destroy failed: %v", err)
+	}
+	if !store.IsInitialized() {
+		t.Fatal("dry-run Destroy deleted the store")
+	}
+
+	store.SetDryRun(nil)
+	if err := store.Destroy(); err != nil {
+		t.Fatalf("Destroy failed: %v", err)
+	}
+	if _, err := os.Stat(store.BasePath()); !os.IsNotExist(err) {
+		t.Errorf("store directory still exists after Destroy: %v", err)
+	}
+}
+
+func TestBackupRotationAndRestore(t *testing.T) {
+	tmpDir := t.TempDir()
+	store := NewStoreWithPath(filepath.Join(tmpDir, "store"))
+	dir := filepath.Join(tmpDir, "backups")
+
+	a, err := store.CreateTask("A", "Body", task.PriorityHigh)
+	if err != nil {
+		t.Fatalf("CreateTask failed: %v", err)
+	}
+
+	first, err := store.Backup(dir, 2)
+	if err != nil {
+		t.Fatalf("Backup failed: %v", err)
+	}
+	if first.Tasks != 1 {
+		t.Errorf("Backup tasks = %d, want 1", first.Tasks)
+	}
+	for range 3 {
+		if _, err = store.Backup(dir, 2); err != nil {
+			t.Fatalf("Backup failed: %v", err)
+		}
+	}
+
+	backups, err := ListBackups(dir)
+	if err != nil {
+		t.Fatalf("ListBackups failed: %v", err)
+	}
+	if len(backups) != 2 {
+		t.Fatalf("ListBackups = %d, want 2 after rotation", len(backups))
+	}
+
+	if err = store.Delete(a.ID); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if err = store.RestoreBackup(dir, backups[0].Name); err != nil {
+		t.Fatalf("RestoreBackup failed: %v", err)
+	}
+	restored, err := store.Load(a.ID)
+	if err != nil {
+		t.Fatalf("Load after restore failed: %v", err)
+	}
+	if restored.Description != "Body" {
+		t.Errorf("Restored description = %q, want %q", restored.Description, "Body")
+	}
+
+	var notFound BackupNotFoundError
+	if err = store.RestoreBackup(dir, "bits-missing.tar.gz"); !errors.As(err, &notFound) {
+		t.Errorf("RestoreBackup(missing) error = %v, want BackupNotFoundError", err)
+	}
+}
+
+func TestBackupKeepsArchive(t *testing.T) {
+	tmpDir := t.TempDir()
+	store := NewStoreWithPath(filepath.Join(tmpDir, "store"))
+	dir := filepath.Join(tmpDir, "backups")
+	old, _ := store.CreateTask("Old", "Long done", task.PriorityMedium)
+	old.Close(time.Now().UTC().AddDate(-1, 0, 0), "done", "tester")
+	if err := store.Save(old); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	live, _ := store.CreateTask("Live", "", task.PriorityMedium)
+	if archived, err := store.Compact(time.Now().UTC().AddDate(0, 0, -1)); err != nil || len(archived) != 1 {
+		t.Fatalf("Compact = %d task(s), %v; want Old archived", len(archived), err)
+	}
+
+	backup, err := store.Backup(dir, 0)
+	if err != nil {
+		t.Fatalf("Backup failed: %v", err)
+	}
+	if err = os.RemoveAll(store.BasePath()); err != nil {
+		t.Fatalf("RemoveAll failed: %v", err)
+	}
+	if err = store.RestoreBackup(dir, backup.Name); err != nil {
+		t.Fatalf("RestoreBackup failed: %v", err)
+	}
+
+	if !store.Exists(live.ID) {
+		t.Error("live task missing after restore")
+	}
+	archived, err := store.Archived()
+	if err != nil {
+		t.Fatalf("Archived failed: %v", err)
+	}
+	if len(archived) != 1 || archived[0].ID != old.ID || archived[0].Description != "Long done" {
+		t.Errorf("Archived after restore = %v, want Old with its description", archived)
+	}
+}
+
+func TestRestoreBackupRejectsEscapes(t *testing.T) {
+	tmpDir := t.TempDir()
+	store := NewStoreWithPath(filepath.Join(tmpDir, "store"))
+	dir := filepath.Join(tmpDir, "backups")
+	good, _ := store.CreateTask("Good", "", task.PriorityMedium)
+	content, err := os.ReadFile(store.livePath(good.ID))
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	evil := bytes.Replace(content, []byte("id: "+good.ID), []byte("id: ../evil"), 1)
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, data := range map[string][]byte{
+		good.ID + ".md": content,
+		"../evil.md":    content,
+		"/evil.md":      content,
+		"attachments/" + good.ID + "/../../../evil": []byte("x"),
+		"attachments/" + good.ID + `/..\..\evil`:    []byte("x"),
+		"zzz.md":                                    evil, // Its ID doesn't match its name
+	} {
+		if err = tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(data))}); err != nil {
+			t.Fatalf("WriteHeader failed: %v", err)
+		}
+		if _, err = tw.Write(data); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+	if err = tw.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if err = gz.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if err = os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatalf("MkdirAll failed: %v", err)
+	}
+	if err = os.WriteFile(filepath.Join(dir, "bits-evil.tar.gz"), buf.Bytes(), 0o644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+
+	if err = store.RestoreBackup(dir, "bits-evil.tar.gz"); err != nil {
+		t.Fatalf("RestoreBackup failed: %v", err)
+	}
+	ids, err := store.AllIDs()
+	if err != nil {
+		t.Fatalf("AllIDs failed: %v", err)
+	}
+	if !ids[good.ID] || len(ids) != 1 {
+		t.Errorf("tasks after restore = %v, want only %s", ids, good.ID)
+	}
+	err = filepath.WalkDir(tmpDir, func(path string, _ fs.DirEntry, walkErr error) error {
+		if strings.Contains(filepath.Base(path), "evil") && !strings.HasSuffix(path, ".tar.gz") {
+			t.Errorf("restore wrote %s", path)
+		}
+		return walkErr
+	})
+	if err != nil {
+		t.Fatalf("WalkDir failed: %v", err)
+	}
+}
+
+func TestDryRunInitAndBackup(t *testing.T) {
+	tmpDir := t.TempDir()
+	store := NewStoreWithPath(filepath.Join(tmpDir, "store"))
+	dir := filepath.Join(tmpDir, "backups")
+	a, err := store.CreateTask("A", "", task.PriorityMedium)
+	if err != nil {
+		t.Fatalf("CreateTask failed: %v", err)
+	}
+	for range 2 {
+		if _, err = store.Backup(dir, 0); err != nil {
+			t.Fatalf("Backup failed: %v", err)
+		}
+	}
+
+	var report strings.Builder
+	store.SetDryRun(&report)
+	if err = store.Init(true); err != nil {
+		t.Fatalf("dry-run Init failed: %v", err)
+	}
+	backup, err := store.Backup(dir, 1)
+	if err != nil {
+		t.Fatalf("dry-run Backup failed: %v", err)
+	}
+	if backup.Tasks != 1 {
+		t.Errorf("dry-run Backup tasks = %d, want 1", backup.Tasks)
+	}
+	for _, want := range []string{"would wipe " + store.BasePath(), "would back up 1 task(s)"} {
+		if !strings.Contains(report.String(), want) {
+			t.Errorf("report = %q, want it to mention %q", report.String(), want)
+		}
+	}
+
+	store.SetDryRun(nil)
+	if !store.Exists(a.ID) {
+		t.Error("dry-run Init deleted tasks")
+	}
+	if backups, _ := ListBackups(dir); len(backups) != 2 {
+		t.Errorf("ListBackups = %d after a dry-run Backup, want the 2 left alone", len(backups))
+	}
+}
+
+func TestInitWithSeed(t *testing.T) {
+	// The steps of 'bits init [--force] --from plan': snapshot, wipe, then
+	// write the plan's tasks together.
+	tests := []struct {
+		name       string
+		existing   bool
+		force      bool
+		dryRun     bool
+		wantTitles []string
+	}{
+		{name: "new store", wantTitles: []string{"Seed A", "Seed B"}},
+		{name: "added to existing", existing: true, wantTitles: []string{"Old", "Seed A", "Seed B"}},
+		{name: "force replaces existing", existing: true, force: true, wantTitles: []string{"Seed A", "Seed B"}},
+		{name: "dry-run force", existing: true, force: true, dryRun: true, wantTitles: []string{"Old"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := NewStoreWithPath(filepath.Join(t.TempDir(), ".bits"))
+			if tt.existing {
+				if _, err := store.CreateTask("Old", "", task.PriorityLow); err != nil {
+					t.Fatalf("CreateTask failed: %v", err)
+				}
+			}
+			if tt.dryRun {
+				store.SetDryRun(io.Discard)
+			}
+
+			var snap *Snapshot
+			var err error
+			if tt.force && store.IsInitialized() {
+				if snap, err = store.Snapshot("init"); err != nil {
+					t.Fatalf("Snapshot failed: %v", err)
+				}
+			}
+			if err = store.Init(tt.force); err != nil {
+				t.Fatalf("Init failed: %v", err)
+			}
+			var seed []*task.Task
+			for _, key := range []string{"a", "b"} {
+				st, newErr := store.NewTask("Seed "+strings.ToUpper(key), "", task.PriorityMedium)
+				if newErr != nil {
+					t.Fatalf("NewTask failed: %v", newErr)
+				}
+				st.Key = key
+				seed = append(seed, st)
+			}
+			if err = store.SaveAll("apply", seed); err != nil {
+				t.Fatalf("SaveAll failed: %v", err)
+			}
+
+			store.SetDryRun(nil)
+			tasks, err := store.List(StatusFilter{})
+			if err != nil {
+				t.Fatalf("List failed: %v", err)
+			}
+			var titles []string
+			for _, tk := range tasks {
+				titles = append(titles, tk.Title)
+				if strings.HasPrefix(tk.Title, "Seed") && tk.Key == "" {
+					t.Errorf("%s lost its plan key", tk.Title)
+				}
+			}
+			slices.Sort(titles)
+			if !slices.Equal(titles, tt.wantTitles) {
+				t.Errorf("tasks = %q, want %q", titles, tt.wantTitles)
+			}
+
+			// The snapshot taken before the wipe brings the old tasks back
+			if snap != nil && !tt.dryRun {
+				if err = store.RestoreSnapshot(snap.Name); err != nil {
+					t.Fatalf("RestoreSnapshot failed: %v", err)
+				}
+				if restored, _ := store.List(StatusFilter{}); len(restored) != 1 || restored[0].Title != "Old" {
+					t.Errorf("restored %d task(s), want just Old", len(restored))
+				}
+			}
+		})
+	}
+}
+
+func TestSetModes(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Windows keeps only the read-only bit of a file mode")
+	}
+	store := NewStoreWithPath(filepath.Join(t.TempDir(), ".bits"))
+	store.SetModes(0o700, 0o600)
+
+	tk, err := store.CreateTask("Private", "", task.PriorityMedium)
+	if err != nil {
+		t.Fatalf("CreateTask failed: %v", err)
+	}
+
+	dirInfo, err := os.Stat(store.BasePath())
+	if err != nil {
+		t.Fatalf("Stat failed: %v", err)
+	}
+	if got := dirInfo.Mode().Perm(); got != 0o700 {
+		t.Errorf("Dir mode = %o, want 700", got)
+	}
+
+	for _, path := range []string{store.taskPath(tk.ID), store.indexPath()} {
+		info, statErr := os.Stat(path)
+		if statErr != nil {
+			t.Fatalf("Stat failed: %v", statErr)
+		}
+		if got := info.Mode().Perm(); got != 0o600 {
+			t.Errorf("%s mode = %o, want 600", filepath.Base(path), got)
+		}
+	}
+}
+
+func TestDataRoot(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	legacy := filepath.Join(home, ".bits")
+	xdg := filepath.Join(home, "xdg-data")
+
+	t.Run("defaults to legacy without XDG_DATA_HOME", func(t *testing.T) {
+		t.Setenv("XDG_DATA_HOME", "")
+		root, err := DataRoot()
+		if err != nil {
+			t.Fatalf("DataRoot failed: %v", err)
+		}
+		if root != legacy {
+			t.Errorf("DataRoot = %q, want %q", root, legacy)
+		}
+	})
+
+	t.Run("uses XDG_DATA_HOME for new installs", func(t *testing.T) {
+		t.Setenv("XDG_DATA_HOME", xdg)
+		root, _ := DataRoot()
+		if want := filepath.Join(xdg, "bits"); root != want {
+			t.Errorf("DataRoot = %q, want %q", root, want)
+		}
+	})
+
+	t.Run("keeps existing legacy root until migrated", func(t *testing.T) {
+		t.Setenv("XDG_DATA_HOME", xdg)
+		if err := os.MkdirAll(filepath.Join(legacy, "proj"), 0o755); err != nil {
+			t.Fatalf("MkdirAll failed: %v", err)
+		}
+		root, _ := DataRoot()
+		if root != legacy {
+			t.Errorf("DataRoot = %q, want %q", root, legacy)
+		}
+
+		from, to, err := MigrateHome()
+		if err != nil {
+			t.Fatalf("MigrateHome failed: %v", err)
+		}
+		if from != legacy || to != filepath.Join(xdg, "bits") {
+			t.Errorf("MigrateHome = (%q, %q)", from, to)
+		}
+		if _, err = os.Stat(filepath.Join(to, "proj")); err != nil {
+			t.Errorf("Migrated project missing: %v", err)
+		}
+		root, _ = DataRoot()
+		if root != to {
+			t.Errorf("DataRoot after migration = %q, want %q", root, to)
+		}
+
+		var nothing NothingToMigrateError
+		if _, _, err = MigrateHome(); !errors.As(err, &nothing) {
+			t.Errorf("Second MigrateHome error = %v, want NothingToMigrateError", err)
+		}
+	})
+}
+
+func TestSummary(t *testing.T) {
+	store := NewStoreWithPath(filepath.Join(t.TempDir(), ".bits"))
+
+	if _, err := store.CreateTask("Open", "", task.PriorityMedium); err != nil {
+		t.Fatalf("CreateTask failed: %v", err)
+	}
+	active, err := store.CreateTask("Active", "", task.PriorityMedium)
+	if err != nil {
+		t.Fatalf("CreateTask failed: %v", err)
+	}
+	active.Status = task.StatusActive
+	if err = store.Save(active); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	// A fresh store must read the persisted statuses
+	summary, err := NewStoreWithPath(store.BasePath()).Summary()
+	if err != nil {
+		t.Fatalf("Summary failed: %v", err)
+	}
+	if summary.Open != 1 || summary.Active != 1 || summary.Closed != 0 {
+		t.Errorf("Summary = %+v, want 1 open and 1 active", summary)
+	}
+	if !slices.Equal(summary.ActiveIDs, []string{active.ID}) {
+		t.Errorf("ActiveIDs = %v, want [%s]", summary.ActiveIDs, active.ID)
+	}
+}
+
+func TestDryRun(t *testing.T) {
+	store := NewStoreWithPath(filepath.Join(t.TempDir(), ".bits"))
+	a, _ := store.CreateTask("A", "", task.PriorityMedium)
+	b, _ := store.CreateTask("B", "", task.PriorityMedium)
+	b.DependsOn = []string{a.ID}
+	if err := store.Save(b); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	var report strings.Builder
+	store.SetDryRun(&report)
+
+	c, err := store.NewTask("C", "", task.PriorityLow)
+	if err != nil {
+		t.Fatalf("NewTask failed: %v", err)
+	}
+	if err = store.Save(c); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if _, err = store.Snapshot("rm"); err != nil {
+		t.Fatalf("Snapshot failed: %v", err)
+	}
+	if err = store.RemoveDependency(a.ID); err != nil {
+		t.Fatalf("RemoveDependency failed: %v", err)
+	}
+	if err = store.Delete(a.ID); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	var notFound TaskNotFoundError
+	if err = store.Delete("nope"); !errors.As(err, &notFound) {
+		t.Errorf("Delete(nope) error = %v, want TaskNotFoundError", err)
+	}
+
+	for _, want := range []string{
+		"would create task " + c.ID,
+		"would snapshot 2 task(s)",
+		"would update task " + b.ID,
+		"would delete task " + a.ID,
+	} {
+		if !strings.Contains(report.String(), want) {
+			t.Errorf("report = %q, want it to mention %q", report.String(), want)
+		}
+	}
+
+	store.SetDryRun(nil)
+	if store.Exists(c.ID) || !store.Exists(a.ID) {
+		t.Error("dry run changed which tasks exist")
+	}
+	if loaded, _ := store.Load(b.ID); len(loaded.DependsOn) != 1 {
+		t.Errorf("DependsOn = %v, want the dependency kept", loaded.DependsOn)
+	}
+	if snapshots, _ := store.Snapshots(); len(snapshots) != 0 {
+		t.Errorf("Snapshots = %v, want none", snapshots)
+	}
+}
+
+func TestLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".bits")
+	first := NewStoreWithPath(path)
+
+	unlock, err := first.Lock()
+	if err != nil {
+		t.Fatalf("Lock failed: %v", err)
+	}
+	again, err := first.Lock()
+	if err != nil {
+		t.Fatalf("relocking the same store failed: %v", err)
+	}
+	again()
+
+	acquired := make(chan struct{})
+	go func() {
+		unlockSecond, lockErr := NewStoreWithPath(path).Lock()
+		if lockErr != nil {
+			t.Errorf("second Lock failed: %v", lockErr)
+		} else {
+			unlockSecond()
+		}
+		close(acquired)
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("second store took the lock while the first held it")
+	case <-time.After(50 * time.Millisecond):
+	}
+	unlock()
+	select {
+	case <-acquired:
+	case <-time.After(time.Second):
+		t.Fatal("second store never got the lock after it was released")
+	}
+}
+
+func TestLockReloadsIndex(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".bits")
+	server := NewStoreWithPath(path)
+	a, err := server.CreateTask("A", "", task.PriorityMedium)
+	if err != nil {
+		t.Fatalf("CreateTask failed: %v", err)
+	}
+
+	// Another process adds a task depending on A while the server's index is cached.
+	b, err := NewStoreWithPath(path).CreateTask("B", "", task.PriorityMedium)
+	if err != nil {
+		t.Fatalf("CreateTask failed: %v", err)
+	}
+	b.DependsOn = []string{a.ID}
+	if err = NewStoreWithPath(path).Save(b); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	unlock, err := server.Lock()
+	if err != nil {
+		t.Fatalf("Lock failed: %v", err)
+	}
+	c, err := server.CreateTask("C", "", task.PriorityMedium)
+	if err != nil {
+		t.Fatalf("CreateTask failed: %v", err)
+	}
+	if c.ID == b.ID {
+		t.Errorf("C reused B's ID %s", b.ID)
+	}
+	if err = server.RemoveDependency(a.ID); err != nil {
+		t.Fatalf("RemoveDependency failed: %v", err)
+	}
+	unlock()
+
+	if loaded, _ := server.Load(b.ID); len(loaded.DependsOn) != 0 {
+		t.Errorf("B DependsOn = %v, want A removed", loaded.DependsOn)
+	}
+	issues, err := NewStoreWithPath(path).Verify()
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if len(issues) != 0 {
+		t.Errorf("Verify = %+v, want the index to track every task", issues)
+	}
+}
+
+func TestOwnerFileLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".bits")
+	first := NewStoreWithPath(path)
+	first.SetLockStrategy(LockOwnerFile)
+	if got := first.LockMethod(); got != LockOwnerFile {
+		t.Fatalf("LockMethod = %q, want file", got)
+	}
+
+	unlock, err := first.Lock()
+	if err != nil {
+		t.Fatalf("Lock failed: %v", err)
+	}
+	owner := filepath.Join(path, ownerLockName)
+	if _, err = os.Stat(owner); err != nil {
+		t.Fatalf("owner file missing while locked: %v", err)
+	}
+
+	acquired := make(chan struct{})
+	go func() {
+		second := NewStoreWithPath(path)
+		second.SetLockStrategy(LockOwnerFile)
+		unlockSecond, lockErr := second.Lock()
+		if lockErr != nil {
+			t.Errorf("second Lock failed: %v", lockErr)
+		} else {
+			unlockSecond()
+		}
+		close(acquired)
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("second store took the lock while the first held it")
+	case <-time.After(3 * lockPollInterval):
+	}
+	unlock()
+	select {
+	case <-acquired:
+	case <-time.After(time.Second):
+		t.Fatal("second store never got the lock after it was released")
+	}
+	if _, err = os.Stat(owner); !os.IsNotExist(err) {
+		t.Errorf("owner file left behind after unlock: %v", err)
+	}
+}
+
+func TestOwnerFileLockStale(t *testing.T) {
+	host, _ := os.Hostname()
+	tests := []struct {
+		name  string
+		owner lockOwner
+		age   time.Duration
+	}{
+		{"heartbeat stopped", lockOwner{Host: "elsewhere", PID: os.Getpid()}, 2 * lockStaleAfter},
+		{"owner process gone", lockOwner{Host: host, PID: math.MaxInt32}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := NewStoreWithPath(filepath.Join(t.TempDir(), ".bits"))
+			store.SetLockStrategy(LockOwnerFile)
+			if err := store.EnsureInitialized(); err != nil {
+				t.Fatalf("EnsureInitialized failed: %v", err)
+			}
+			owner := filepath.Join(store.BasePath(), ownerLockName)
+			content, _ := json.Marshal(tt.owner)
+			if err := os.WriteFile(owner, content, 0o644); err != nil {
+				t.Fatalf("WriteFile failed: %v", err)
+			}
+			then := time.Now().Add(-tt.age)
+			if err := os.Chtimes(owner, then, then); err != nil {
+				t.Fatalf("Chtimes failed: %v", err)
+			}
+
+			done := make(chan struct{})
+			go func() {
+				if unlock, err := store.Lock(); err != nil {
+					t.Errorf("Lock failed: %v", err)
+				} else {
+					unlock()
+				}
+				close(done)
+			}()
+			select {
+			case <-done:
+			case <-time.After(time.Second):
+				t.Fatal("Lock never took over the stale owner file")
+			}
+		})
+	}
+}
+
+func TestSchemaMigration(t *testing.T) {
+	store := NewStoreWithPath(filepath.Join(t.TempDir(), ".bits"))
+	current, err := store.CreateTask("Current", "", task.PriorityMedium)
+	if err != nil {
+		t.Fatalf("CreateTask failed: %v", err)
+	}
+	unversioned := []byte("---\nid: old\ntitle: Old\nstatus: open\npriority: low\n" +
+		"created_at: 2024-01-15T10:30:00Z\n---\n")
+	if err = os.WriteFile(store.taskPath("old"), unversioned, 0o644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+
+	outdated, err := store.Outdated()
+	if err != nil {
+		t.Fatalf("Outdated failed: %v", err)
+	}
+	if len(outdated) != 1 || outdated[0].ID != "old" || outdated[0].Priority != task.PriorityLow {
+		t.Fatalf("Outdated = %v, want only old (skipping %s)", outdated, current.ID)
+	}
+	if err = store.Save(outdated[0]); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if outdated, _ = store.Outdated(); len(outdated) != 0 {
+		t.Errorf("Outdated after save = %v, want none", outdated)
+	}
+	content, _ := os.ReadFile(store.taskPath("old"))
+	if !strings.HasPrefix(string(content), "---\nschema: 1\n") {
+		t.Errorf("saved file does not lead with the schema:\n%s", content)
+	}
+
+	newer := strings.Replace(string(content), "schema: 1", "schema: 99", 1)
+	var schemaErr UnsupportedSchemaError
+	if _, err = ParseMarkdown([]byte(newer)); !errors.As(err, &schemaErr) || schemaErr.Schema != 99 {
+		t.Errorf("ParseMarkdown(schema 99) error = %v, want UnsupportedSchemaError", err)
+	}
+
+	// A step that renames a key runs only for files older than it
+	var fm yaml.Node
+	_ = yaml.Unmarshal([]byte("summary: Renamed\nstatus: open\n"), &fm)
+	steps := []migration{func(*yaml.Node) {}, func(m *yaml.Node) { renameKey(m, "summary", "title") }}
+	migrate(fm.Content[0], 1, steps)
+	if v := mappingValue(fm.Content[0], "title"); v == nil || v.Value != "Renamed" {
+		t.Errorf("migrate from 1 did not rename summary to title")
+	}
+}
+
+func TestClosedTasksMoveToClosedDir(t *testing.T) {
+	store := NewStoreWithPath(filepath.Join(t.TempDir(), ".bits"))
+	live, err := store.CreateTask("Live", "", task.PriorityMedium)
+	if err != nil {
+		t.Fatalf("CreateTask failed: %v", err)
+	}
+	done, _ := store.CreateTask("Done", "", task.PriorityMedium)
+	done.Close(time.Now().UTC(), "finished", "tester")
+	if err = store.Save(done); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	if _, err = os.Stat(store.closedPath(done.ID)); err != nil {
+		t.Errorf("closed task is not in closed/: %v", err)
+	}
+	if _, err = os.Stat(store.livePath(done.ID)); !os.IsNotExist(err) {
+		t.Errorf("closed task left a copy in the store root: %v", err)
+	}
+	if loaded, loadErr := store.Load(done.ID); loadErr != nil || loaded.Status != task.StatusClosed {
+		t.Errorf("Load(closed) = %v, %v", loaded, loadErr)
+	}
+
+	// Walks for live work don't read closed/; unfiltered walks do
+	if err = os.WriteFile(filepath.Join(store.basePath, closedDir, "bad.md"), []byte("garbage"), 0o644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	liveTasks, _ := store.List(StatusFilter{Open: true, Active: true})
+	if len(liveTasks) != 1 || liveTasks[0].ID != live.ID {
+		t.Errorf("live List = %v, want only %s", liveTasks, live.ID)
+	}
+	if all, _ := store.List(StatusFilter{}); len(all) != 2 {
+		t.Errorf("List = %d tasks, want 2", len(all))
+	}
+	if ids, _ := store.AllIDs(); !ids[done.ID] || !ids[live.ID] {
+		t.Errorf("AllIDs = %v, want both tasks", ids)
+	}
+
+	// Reopening moves the file back
+	done.Status, done.ClosedAt = task.StatusOpen, nil
+	if err = store.Save(done); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if _, err = os.Stat(store.livePath(done.ID)); err != nil {
+		t.Errorf("reopened task is not in the store root: %v", err)
+	}
+
+	// A closed task saved before segregation is found where it is and
+	// reported as outdated until it is saved again
+	done.Close(time.Now().UTC(), "finished", "tester")
+	content, _ := SerializeMarkdown(done)
+	if err = os.WriteFile(store.livePath(done.ID), content, 0o644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	if loaded, loadErr := store.Load(done.ID); loadErr != nil || loaded.Status != task.StatusClosed {
+		t.Errorf("Load(unmoved closed) = %v, %v", loaded, loadErr)
+	}
+	if outdated, _ := store.Outdated(); len(outdated) != 1 || outdated[0].ID != done.ID {
+		t.Errorf("Outdated = %v, want %s", outdated, done.ID)
+	}
+}
+
+func TestNewTaskIDs(t *testing.T) {
+	store := NewStoreWithPath(filepath.Join(t.TempDir(), ".bits"))
+	store.SetMinIDLength(4)
+
+	saved, err := store.CreateTask("Saved", "", task.PriorityMedium)
+	if err != nil {
+		t.Fatalf("CreateTask failed: %v", err)
+	}
+	seen := map[string]bool{saved.ID: true}
+	// Staged like a batch: none of these are saved, yet none may collide
+	for range 2000 {
+		tk, newErr := store.NewTask("Same title", "", task.PriorityMedium)
+		if newErr != nil {
+			t.Fatalf("NewTask failed: %v", newErr)
+		}
+		if seen[tk.ID] {
+			t.Fatalf("NewTask reused ID %s", tk.ID)
+		}
+		if len(tk.ID) < 4 {
+			t.Fatalf("NewTask ID %q is shorter than the minimum length 4", tk.ID)
+		}
+		seen[tk.ID] = true
+	}
+}
+
+func TestCompact(t *testing.T) {
+	store := NewStoreWithPath(filepath.Join(t.TempDir(), ".bits"))
+	jan := time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC)
+	closeAt := func(title string, at time.Time) *task.Task {
+		tk, err := store.NewTask(title, "Details for "+title, task.PriorityMedium)
+		if err != nil {
+			t.Fatalf("NewTask failed: %v", err)
+		}
+		tk.Close(at, "done", "tester")
+		if err = store.Save(tk); err != nil {
+			t.Fatalf("Save failed: %v", err)
+		}
+		return tk
+	}
+	a := closeAt("January A", jan)
+	b := closeAt("January B", jan.Add(48*time.Hour))
+	c := closeAt("March", jan.AddDate(0, 2, 0))
+	recent := closeAt("Recent", time.Now().UTC())
+	open, _ := store.CreateTask("Open", "", task.PriorityMedium)
+	open.DependsOn = []string{a.ID}
+	if err := store.Save(open); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	archived, err := store.Compact(time.Now().UTC().AddDate(0, 0, -1))
+	if err != nil {
+		t.Fatalf("Compact failed: %v", err)
+	}
+	if len(archived) != 3 {
+		t.Fatalf("Compact archived %d tasks, want 3", len(archived))
+	}
+	for _, month := range []string{"2025-01", "2025-03"} {
+		if _, err = os.Stat(store.archivePath(month)); err != nil {
+			t.Errorf("bundle %s: %v", month, err)
+		}
+	}
+	if store.Exists(a.ID) || !store.Exists(recent.ID) {
+		t.Errorf("after Compact: old kept = %v, recent kept = %v", store.Exists(a.ID), store.Exists(recent.ID))
+	}
+
+	// A second compaction into the same month keeps the earlier entries
+	d := closeAt("January D", jan.Add(72*time.Hour))
+	if archived, err = store.Compact(time.Now().UTC().AddDate(0, 0, -1)); err != nil || len(archived) != 1 {
+		t.Fatalf("second Compact = %v, %v", archived, err)
+	}
+
+	all, err := store.Archived()
+	if err != nil {
+		t.Fatalf("Archived failed: %v", err)
+	}
+	want := []string{a.ID, b.ID, c.ID, d.ID}
+	slices.Sort(want)
+	var got []string
+	for _, tk := range all {
+		got = append(got, tk.ID)
+		if tk.Description != "Details for "+tk.Title {
+			t.Errorf("archived %s description = %q", tk.ID, tk.Description)
+		}
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("Archived = %v, want %v", got, want)
+	}
+
+	// Dependencies on archived tasks aren't missing
+	if issues, _ := store.Verify(); len(issues) != 0 {
+		t.Errorf("Verify after Compact = %v, want none", issues)
+	}
+}
+
+func TestSearch(t *testing.T) {
+	store := NewStoreWithPath(filepath.Join(t.TempDir(), ".bits"))
+	closeTask := func(tk *task.Task, at time.Time, reason string) {
+		tk.Close(at, reason, "tester")
+		if err := store.Save(tk); err != nil {
+			t.Fatalf("Save failed: %v", err)
+		}
+	}
+	_, _ = store.CreateTask("Fix login timeout", "", task.PriorityMedium)
+	_, _ = store.CreateTask("Docs", "Explain Timeouts", task.PriorityLow)
+	crash, _ := store.CreateTask("Crash", "", task.PriorityHigh)
+	closeTask(crash, time.Now().UTC(), "fixed the timeout race")
+	old, _ := store.CreateTask("Old timeout", "", task.PriorityCritical)
+	closeTask(old, time.Now().UTC().AddDate(-1, 0, 0), "done")
+	if _, err := store.Compact(time.Now().UTC().AddDate(0, 0, -1)); err != nil {
+		t.Fatalf("Compact failed: %v", err)
+	}
+
+	tests := []struct {
+		name          string
+		query         string
+		regex         bool
+		caseSensitive bool
+		archived      bool
+		want          []string
+		wantErr       bool
+	}{
+		{name: "ignoring case", query: "TIMEOUT", want: []string{"Crash", "Fix login timeout", "Docs"}},
+		{name: "case-sensitive", query: "timeout", caseSensitive: true, want: []string{"Crash", "Fix login timeout"}},
+		{name: "regex", query: "^fix", regex: true, want: []string{"Crash", "Fix login timeout"}},
+		{name: "case-sensitive regex", query: "^Ex", regex: true, caseSensitive: true, want: []string{"Docs"}},
+		{
+			name:     "archived",
+			query:    "timeout",
+			archived: true,
+			want:     []string{"Old timeout", "Crash", "Fix login timeout", "Docs"},
+		},
+		{name: "no match", query: "nothing"},
+		{name: "invalid regex", query: "(", regex: true, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match, err := TextMatcher(tt.query, tt.regex, tt.caseSensitive)
+			if tt.wantErr {
+				if !errors.As(err, new(InvalidPatternError)) {
+					t.Errorf("TextMatcher(%q) = %v, want InvalidPatternError", tt.query, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("TextMatcher failed: %v", err)
+			}
+			matches, err := store.Search(match, tt.archived)
+			if err != nil {
+				t.Fatalf("Search failed: %v", err)
+			}
+			var titles []string
+			for _, m := range matches {
+				titles = append(titles, m.Title)
+			}
+			if !slices.Equal(titles, tt.want) {
+				t.Errorf("Search(%q) = %q, want %q", tt.query, titles, tt.want)
+			}
+		})
+	}
+}
+
+func TestBodyFile(t *testing.T) {
+	store := NewStoreWithPath(filepath.Join(t.TempDir(), ".bits"))
+	huge := strings.Repeat("log line\n", bodyFileThreshold/8)
+	tk, err := store.CreateTask("Huge", strings.TrimSpace(huge), task.PriorityMedium)
+	if err != nil {
+		t.Fatalf("CreateTask failed: %v", err)
+	}
+
+	content, _ := os.ReadFile(store.taskPath(tk.ID))
+	if len(content) > 1024 || !strings.Contains(string(content), "body_file: bodies/"+tk.ID+".md") {
+		t.Errorf("task file wasn't split (%d bytes):\n%.300s", len(content), content)
+	}
+	loaded, err := store.Load(tk.ID)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if loaded.Description != tk.Description || loaded.BodyFile != "" {
+		t.Errorf("Load didn't read the sidecar: %d bytes, BodyFile %q", len(loaded.Description), loaded.BodyFile)
+	}
+	if listed, _ := store.List(StatusFilter{}); len(listed) != 1 || listed[0].Description != tk.Description {
+		t.Error("List didn't read the sidecar")
+	}
+
+	// Snapshots carry the description inline, so restoring after a delete works
+	snap, err := store.Snapshot("test")
+	if err != nil {
+		t.Fatalf("Snapshot failed: %v", err)
+	}
+	if err = store.Delete(tk.ID); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, err = os.Stat(store.bodyPath(tk.ID)); !os.IsNotExist(err) {
+		t.Errorf("Delete left the sidecar: %v", err)
+	}
+	if err = store.RestoreSnapshot(snap.Name); err != nil {
+		t.Fatalf("RestoreSnapshot failed: %v", err)
+	}
+	if loaded, err = store.Load(tk.ID); err != nil || loaded.Description != tk.Description {
+		t.Fatalf("Load after restore = %v", err)
+	}
+
+	// Shrinking the description moves it back inline
+	loaded.Description = "short now"
+	if err = store.Save(loaded); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if _, err = os.Stat(store.bodyPath(tk.ID)); !os.IsNotExist(err) {
+		t.Errorf("sidecar kept after shrinking: %v", err)
+	}
+	if loaded, _ = store.Load(tk.ID); loaded.Description != "short now" {
+		t.Errorf("Description = %q, want short now", loaded.Description)
+	}
+}
+
+func TestAttachments(t *testing.T) {
+	dir := t.TempDir()
+	store := NewStoreWithPath(filepath.Join(dir, ".bits"))
+	src := filepath.Join(dir, "trace.log")
+	if err := os.WriteFile(src, []byte("panic: boom"), 0o600); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	tk, _ := store.CreateTask("Crash", "", task.PriorityMedium)
+
+	path, err := store.Attach(tk, src)
+	if err != nil {
+		t.Fatalf("Attach failed: %v", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "panic: boom" {
+		t.Errorf("attachment content = %q", data)
+	}
+	if loaded, _ := store.Load(tk.ID); !slices.Equal(loaded.Attachments, []string{"trace.log"}) {
+		t.Errorf("Attachments = %v, want [trace.log]", loaded.Attachments)
+	}
+	var exists AttachmentExistsError
+	if _, err = store.Attach(tk, src); !errors.As(err, &exists) {
+		t.Errorf("second Attach error = %v, want AttachmentExistsError", err)
+	}
+	var notFile NotAFileError
+	if _, err = store.Attach(tk, dir); !errors.As(err, &notFile) {
+		t.Errorf("Attach(dir) error = %v, want NotAFileError", err)
+	}
+
+	// Backups carry attachments and restore them
+	backups := filepath.Join(dir, "backups")
+	backup, err := store.Backup(backups, 0)
+	if err != nil {
+		t.Fatalf("Backup failed: %v", err)
+	}
+	if err = store.Delete(tk.ID); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, err = os.Stat(store.attachmentDir(tk.ID)); !os.IsNotExist(err) {
+		t.Errorf("Delete left the attachments: %v", err)
+	}
+	if err = store.RestoreBackup(backups, backup.Name); err != nil {
+		t.Fatalf("RestoreBackup failed: %v", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "panic: boom" {
+		t.Errorf("restored attachment content = %q", data)
+	}
+
+	// Compacting keeps attachments, since archived tasks stay searchable
+	tk, _ = store.Load(tk.ID)
+	tk.Close(time.Now().UTC().AddDate(-1, 0, 0), "fixed", "tester")
+	if err = store.Save(tk); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if _, err = store.Compact(time.Now().UTC()); err != nil {
+		t.Fatalf("Compact failed: %v", err)
+	}
+	if _, err = os.Stat(path); err != nil {
+		t.Errorf("Compact removed the attachment: %v", err)
+	}
+}
+
+func TestSaveStampsUpdatedAt(t *testing.T) {
+	store := NewStoreWithPath(filepath.Join(t.TempDir(), ".bits"))
+	tk, _ := store.CreateTask("Task", "", task.PriorityMedium)
+	if tk.UpdatedAt == nil {
+		t.Fatal("CreateTask didn't set updated_at")
+	}
+
+	old := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	tk.UpdatedAt = &old
+	if err := store.Rewrite(tk); err != nil {
+		t.Fatalf("Rewrite failed: %v", err)
+	}
+	if loaded, _ := store.Load(tk.ID); loaded.UpdatedAt == nil || !loaded.UpdatedAt.Equal(old) {
+		t.Errorf("Rewrite changed updated_at to %v", loaded.UpdatedAt)
+	}
+	if err := store.Save(tk); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if loaded, _ := store.Load(tk.ID); !loaded.UpdatedAt.After(old) {
+		t.Errorf("Save left updated_at at %v", loaded.UpdatedAt)
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	tests := []struct {
+		name    string
+		id      string
+		edit    func(*task.Task)
+		wantErr error
+	}{
+		{name: "title", edit: func(t *task.Task) { t.Title = "Renamed" }},
+		{name: "priority", edit: func(t *task.Task) { t.Priority = task.PriorityCritical }},
+		{name: "id", edit: func(t *task.Task) { t.ID = "zzz" }, wantErr: ReadOnlyFieldError{Field: "id"}},
+		{
+			name:    "status",
+			edit:    func(t *task.Task) { t.Status = task.StatusClosed },
+			wantErr: ReadOnlyFieldError{Field: "status"},
+		},
+		{name: "unknown task", id: "zzz", edit: func(*task.Task) {}, wantErr: TaskNotFoundError{ID: "zzz"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := NewStoreWithPath(filepath.Join(t.TempDir(), ".bits"))
+			orig, err := store.CreateTask("Original", "", task.PriorityMedium)
+			if err != nil {
+				t.Fatalf("CreateTask failed: %v", err)
+			}
+			id := cmp.Or(tt.id, orig.ID)
+			edited := *orig
+			tt.edit(&edited)
+
+			err = store.Update(id, &edited)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Update = %v, want %v", err, tt.wantErr)
+			}
+			loaded, _ := store.Load(orig.ID)
+			want := orig
+			if err == nil {
+				want = &edited
+			}
+			if loaded.Title != want.Title || loaded.Priority != want.Priority || loaded.Status != want.Status {
+				t.Errorf("stored task = %q %s %s, want %q %s %s", loaded.Title, loaded.Priority, loaded.Status,
+					want.Title, want.Priority, want.Status)
+			}
+		})
+	}
+}
+
+func TestVerifyWIPLimit(t *testing.T) {
+	store := NewStoreWithPath(filepath.Join(t.TempDir(), ".bits"))
+	claim := func(title, actor string) {
+		tk, _ := store.NewTask(title, "", task.PriorityMedium)
+		tk.Claim(time.Now().UTC(), actor)
+		if err := store.Save(tk); err != nil {
+			t.Fatalf("Save failed: %v", err)
+		}
+	}
+	claim("A", "user:alice")
+	claim("B", "agent-1")
+	claim("C", "agent-2")
+
+	if issues, _ := store.Verify(); len(issues) != 0 {
+		t.Errorf("Verify with one task per holder = %v, want none", issues)
+	}
+
+	claim("D", "user:alice")
+	claim("E", "agent-1")
+	issues, _ := store.Verify()
+	if len(issues) != 4 {
+		t.Errorf("Verify with two anonymous and two agent-1 tasks = %v, want four invalid", issues)
+	}
+
+	store.SetWIPLimits(WIPLimits{Total: 2, PerAgent: 2})
+	if issues, _ = store.Verify(); len(issues) != 0 {
+		t.Errorf("Verify within limits of 2 = %v, want none", issues)
+	}
+	store.SetWIPLimits(WIPLimits{})
+	if issues, _ = store.Verify(); len(issues) != 0 {
+		t.Errorf("Verify with no WIP limits = %v, want none", issues)
+	}
+}
+
+func TestWIPLimitsExceeded(t *testing.T) {
+	active := func(id, actor string) *task.Task {
+		tk := &task.Task{ID: id, Status: task.StatusOpen}
+		tk.Claim(time.Now().UTC(), actor)
+		return tk
+	}
+	tasks := []*task.Task{active("a", "agent-1"), {ID: "b", Status: task.StatusOpen}}
+	limits := DefaultWIPLimits()
+
+	if limit, counted, exceeded := limits.Exceeded(tasks, "agent-1"); !exceeded || limit != 1 || len(counted) != 1 {
+		t.Errorf("Exceeded(agent-1) = %d, %v, %v; want the per-agent limit", limit, counted, exceeded)
+	}
+	if _, _, exceeded := limits.Exceeded(tasks, "agent-2"); exceeded {
+		t.Error("agent-2 was held to agent-1's task")
+	}
+	if _, _, exceeded := limits.Exceeded(tasks, "user:alice"); !exceeded {
+		t.Error("an anonymous claim ignored the store-wide limit")
+	}
+}
+
+func TestNextTask(t *testing.T) {
+	mine := &task.Task{ID: "aaa", Assignee: "agent:me"}
+	theirs := &task.Task{ID: "bbb", Assignee: "agent:them"}
+	free := &task.Task{ID: "ccc"}
+
+	tests := []struct {
+		name  string
+		ready []*task.Task
+		actor string
+		want  *task.Task
+	}{
+		{name: "first ready", ready: []*task.Task{free, mine}, actor: "agent:me", want: free},
+		{name: "assigned to the actor", ready: []*task.Task{mine, free}, actor: "agent:me", want: mine},
+		{name: "skips another's", ready: []*task.Task{theirs, free}, actor: "agent:me", want: free},
+		{name: "only another's", ready: []*task.Task{theirs}, actor: "agent:me"},
+		{name: "nothing ready", actor: "agent:me"},
+	}
+	for _, tt := range tests {
+		if got := NextTask(tt.ready, tt.actor); got != tt.want {
+			t.Errorf("%s: NextTask = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestNextTaskUnderLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".bits")
+	for _, title := range []string{"A", "B"} {
+		if _, err := NewStoreWithPath(path).CreateTask(title, "", task.PriorityMedium); err != nil {
+			t.Fatalf("CreateTask failed: %v", err)
+		}
+	}
+
+	// Two agents with their own stores claim the next task in turn, as two
+	// 'bits next --claim' processes do; the second must see the first's claim.
+	claimed := make(map[string]string)
+	for _, actor := range []string{"agent:one", "agent:two"} {
+		store := NewStoreWithPath(path)
+		unlock, err := store.Lock()
+		if err != nil {
+			t.Fatalf("Lock failed: %v", err)
+		}
+		open, err := store.List(StatusFilter{Open: true})
+		if err != nil {
+			t.Fatalf("List failed: %v", err)
+		}
+		next := NextTask(open, actor)
+		if next == nil {
+			t.Fatalf("NextTask for %s = nil, want a task", actor)
+		}
+		next.Claim(time.Now().UTC(), actor)
+		if err = store.Save(next); err != nil {
+			t.Fatalf("Save failed: %v", err)
+		}
+		unlock()
+		if other, ok := claimed[next.ID]; ok {
+			t.Errorf("%s and %s both claimed %s", other, actor, next.ID)
+		}
+		claimed[next.ID] = actor
+	}
+}
+
+func TestCloseFromTrailers(t *testing.T) {
+	const sha = "0123456789abcdef0123456789abcdef01234567"
+	tests := []struct {
+		name       string
+		status     task.Status
+		commits    []string
+		value      func(id string) string
+		wantReason string // "" when the task is left alone
+	}{
+		{
+			name:       "with reason",
+			status:     task.StatusActive,
+			value:      func(id string) string { return id + " Fixed it" },
+			wantReason: "Fixed it",
+		},
+		{
+			name:       "without reason",
+			status:     task.StatusOpen,
+			value:      func(id string) string { return id },
+			wantReason: "Closed by commit 0123456",
+		},
+		{
+			name:       "commit already linked",
+			status:     task.StatusOpen,
+			commits:    []string{sha},
+			value:      func(id string) string { return id + "  Done " },
+			wantReason: "Done",
+		},
+		{name: "already closed", status: task.StatusClosed, value: func(id string) string { return id + " Again" }},
+		{name: "unknown task", status: task.StatusOpen, value: func(string) string { return "zzz Elsewhere" }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := NewStoreWithPath(filepath.Join(t.TempDir(), ".bits"))
+			tk, _ := store.CreateTask("Task", "", task.PriorityMedium)
+			tk.Commits = tt.commits
+			switch tt.status {
+			case task.StatusActive:
+				tk.Claim(time.Now().UTC(), "tester")
+			case task.StatusClosed:
+				tk.Close(time.Now().UTC(), "Earlier", "tester")
+			}
+			if err := store.Save(tk); err != nil {
+				t.Fatalf("Save failed: %v", err)
+			}
+
+			closed, err := store.CloseFromTrailers(sha, []string{tt.value(tk.ID)}, "tester", nil)
+			if err != nil {
+				t.Fatalf("CloseFromTrailers failed: %v", err)
+			}
+			loaded, _ := store.Load(tk.ID)
+			if tt.wantReason == "" {
+				if len(closed) != 0 || loaded.Status != tt.status {
+					t.Errorf("closed %d task(s), status %s; want the task left %s",
+						len(closed), loaded.Status, tt.status)
+				}
+				return
+			}
+			if len(closed) != 1 || loaded.Status != task.StatusClosed || *loaded.CloseReason != tt.wantReason {
+				t.Fatalf("closed %d task(s), status %s; want it closed with %q",
+					len(closed), loaded.Status, tt.wantReason)
+			}
+			if !slices.Equal(loaded.Commits, []string{sha}) {
+				t.Errorf("Commits = %v, want [%s]", loaded.Commits, sha)
+			}
+		})
+	}
+}
+
+func TestCloseFromTrailersCheck(t *testing.T) {
+	const sha = "0123456789abcdef0123456789abcdef01234567"
+	store := NewStoreWithPath(filepath.Join(t.TempDir(), ".bits"))
+	pass, _ := store.CreateTask("Pass", "", task.PriorityMedium)
+	fail, _ := store.CreateTask("Fail", "", task.PriorityMedium)
+	check := func(t *task.Task) (string, error) {
+		if t.ID == fail.ID {
+			return "", errors.New("gate failed")
+		}
+		return "Checked", nil
+	}
+
+	closed, err := store.CloseFromTrailers(sha, []string{pass.ID, fail.ID}, "tester", check)
+	if err != nil {
+		t.Fatalf("CloseFromTrailers failed: %v", err)
+	}
+	if len(closed) != 1 || closed[0].ID != pass.ID {
+		t.Fatalf("closed %v, want only %s", closed, pass.ID)
+	}
+	if loaded, _ := store.Load(fail.ID); loaded.Status != task.StatusOpen {
+		t.Errorf("failing task status = %s, want open", loaded.Status)
+	}
+	loaded, _ := store.Load(pass.ID)
+	if last := loaded.History[len(loaded.History)-1]; last.Note != "Checked" {
+		t.Errorf("close note = %q, want %q", last.Note, "Checked")
+	}
+}
+
+func TestJournalUndo(t *testing.T) {
+	store := NewStoreWithPath(filepath.Join(t.TempDir(), ".bits"))
+
+	store.BeginOp("add", "alice")
+	a, err := store.CreateTask("A", "", task.PriorityMedium)
+	if err != nil {
+		t.Fatalf("CreateTask failed: %v", err)
+	}
+	if err = store.EndOp(); err != nil {
+		t.Fatalf("EndOp failed: %v", err)
+	}
+
+	store.BeginOp("claim", "alice")
+	a.Status = task.StatusActive
+	if err = store.Save(a); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if err = store.EndOp(); err != nil {
+		t.Fatalf("EndOp failed: %v", err)
+	}
+
+	// A command that changes nothing leaves no entry
+	store.BeginOp("show", "alice")
+	if err = store.EndOp(); err != nil {
+		t.Fatalf("EndOp failed: %v", err)
+	}
+	entries, err := store.Journal()
+	if err != nil {
+		t.Fatalf("Journal failed: %v", err)
+	}
+	if len(entries) != 2 || entries[0].Op != "claim" || entries[0].Seq != 2 {
+		t.Fatalf("Journal = %+v, want claim #2 then add #1", entries)
+	}
+
+	store.BeginOp("undo", "alice")
+	undone, err := store.Undo(0, false)
+	if err != nil {
+		t.Fatalf("Undo failed: %v", err)
+	}
+	if err = store.EndOp(); err != nil {
+		t.Fatalf("EndOp failed: %v", err)
+	}
+	if undone.Seq != 2 {
+		t.Errorf("Undo reverted #%d, want #2", undone.Seq)
+	}
+	if loaded, _ := store.Load(a.ID); loaded.Status != task.StatusOpen {
+		t.Errorf("status after undo = %s, want open", loaded.Status)
+	}
+	if _, err = store.Undo(2, false); !errors.As(err, &AlreadyUndoneError{}) {
+		t.Errorf("Undo(2) again = %v, want AlreadyUndoneError", err)
+	}
+
+	// Undoing the creation is refused once the task has changed since
+	a.Title = "Renamed"
+	if err = store.Save(a); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if _, err = store.Undo(0, false); !errors.As(err, &UndoConflictError{}) {
+		t.Fatalf("Undo after a later change = %v, want UndoConflictError", err)
+	}
+	if _, err = store.Undo(0, true); err != nil {
+		t.Fatalf("forced Undo failed: %v", err)
+	}
+	if store.Exists(a.ID) {
+		t.Errorf("task %s still exists after undoing its creation", a.ID)
+	}
+	if _, err = store.Undo(9, false); !errors.As(err, &JournalEntryNotFoundError{}) {
+		t.Errorf("Undo(9) = %v, want JournalEntryNotFoundError", err)
+	}
+}
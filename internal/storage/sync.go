@@ -0,0 +1,381 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/abatilo/bits/internal/task"
+)
+
+const (
+	syncConfigFile = "sync.json"
+	outboxFile     = "outbox.jsonl"
+	syncBaseDir    = "sync-base"
+	appliedOpsFile = ".applied-ops"
+	opIDSize       = 16
+)
+
+// Sync operation kinds recorded in the outbox.
+const (
+	syncOpSave   = "save"
+	syncOpDelete = "delete"
+)
+
+// syncConfig is the persisted sync configuration for a store.
+type syncConfig struct {
+	Remote string `json:"remote"`
+}
+
+// syncOp is a mutation queued in the outbox until it reaches the remote.
+// OpID makes replay idempotent: the remote records every applied ID.
+type syncOp struct {
+	OpID    string    `json:"op_id"`
+	Op      string    `json:"op"`
+	TaskID  string    `json:"task_id"`
+	Content string    `json:"content,omitempty"`
+	At      time.Time `json:"at"`
+}
+
+// SyncResult summarizes a sync run.
+type SyncResult struct {
+	Pushed int
+	Pulled int
+	Queued int // Left in the outbox, such as local tasks the remote never had
+}
+
+func (s *Store) syncConfigPath() string {
+	return filepath.Join(s.basePath, syncConfigFile)
+}
+
+func (s *Store) outboxPath() string {
+	return filepath.Join(s.basePath, outboxFile)
+}
+
+// SyncRemote returns the configured sync remote, or "" if sync is not configured.
+func (s *Store) SyncRemote() string {
+	data, err := os.ReadFile(s.syncConfigPath())
+	if err != nil {
+		return ""
+	}
+	var cfg syncConfig
+	if json.Unmarshal(data, &cfg) != nil {
+		return ""
+	}
+	return cfg.Remote
+}
+
+// SetSyncRemote configures the sync remote and queues every existing task so
+// the first sync publishes the whole store.
+func (s *Store) SetSyncRemote(remote string) error {
+	if err := s.EnsureInitialized(); err != nil {
+		return err
+	}
+	abs, err := filepath.Abs(remote)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(syncConfig{Remote: abs}, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err = s.writeFile(s.syncConfigPath(), data); err != nil {
+		return err
+	}
+
+	ids, err := s.AllIDs()
+	if err != nil {
+		return err
+	}
+	for id := range ids {
+		var content []byte
+		content, err = s.fullContent(s.taskPath(id))
+		if err != nil {
+			return err
+		}
+		if err = s.enqueueSync(syncOpSave, id, content); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PendingSyncOps returns the number of operations waiting in the outbox.
+func (s *Store) PendingSyncOps() (int, error) {
+	ops, err := s.readOutbox()
+	return len(ops), err
+}
+
+// recordSync queues a mutation in the outbox when a sync remote is configured.
+func (s *Store) recordSync(op, id string, content []byte) error {
+	var remote string
+	if s.batch != nil {
+		remote = s.batch.remote
+	} else {
+		remote = s.SyncRemote()
+	}
+	if remote == "" {
+		return nil
+	}
+	return s.enqueueSync(op, id, content)
+}
+
+func (s *Store) enqueueSync(op, id string, content []byte) error {
+	nonce := make([]byte, opIDSize)
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+	data, err := json.Marshal(syncOp{
+		OpID:    hex.EncodeToString(nonce),
+		Op:      op,
+		TaskID:  id,
+		Content: string(content),
+		At:      time.Now().UTC(),
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.appendFile(s.outboxPath(), append(data, '\n'))
+}
+
+func (s *Store) readOutbox() ([]syncOp, error) {
+	data, err := os.ReadFile(s.outboxPath())
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var ops []syncOp
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, len(data)+1)
+	for scanner.Scan() {
+		var op syncOp
+		if json.Unmarshal(scanner.Bytes(), &op) != nil {
+			continue // Skip torn writes
+		}
+		ops = append(ops, op)
+	}
+	return ops, scanner.Err()
+}
+
+// Sync replays queued mutations to the remote and pulls remote changes.
+// If the remote is unreachable, the outbox is left intact and a
+// SyncUnreachableError reports how many operations remain queued.
+func (s *Store) Sync() (SyncResult, error) {
+	var result SyncResult
+
+	remote := s.SyncRemote()
+	if remote == "" {
+		return result, SyncNotConfiguredError{}
+	}
+
+	ops, err := s.readOutbox()
+	if err != nil {
+		return result, err
+	}
+	result.Queued = len(ops)
+
+	if info, statErr := os.Stat(remote); statErr != nil || !info.IsDir() {
+		return result, SyncUnreachableError{Remote: remote, Queued: len(ops)}
+	}
+
+	if result.Pushed, err = s.pushOps(remote, ops); err != nil {
+		return result, err
+	}
+	if err = os.Remove(s.outboxPath()); err != nil && !os.IsNotExist(err) {
+		return result, err
+	}
+	result.Queued = 0
+
+	if result.Pulled, result.Queued, err = s.pull(remote); err != nil {
+		return result, err
+	}
+	return result, s.RebuildIndex()
+}
+
+// pushOps applies queued operations to the remote, skipping any whose ID the
+// remote has already applied. Saves are three-way merged against the last
+// synced base when the remote copy changed concurrently.
+func (s *Store) pushOps(remote string, ops []syncOp) (int, error) {
+	appliedPath := filepath.Join(remote, appliedOpsFile)
+	applied := make(map[string]bool)
+	if data, err := os.ReadFile(appliedPath); err == nil { //nolint:gosec // G304: remote path is user-configured
+		for line := range strings.SplitSeq(string(data), "\n") {
+			applied[line] = true
+		}
+	}
+
+	pushed := 0
+	for _, op := range ops {
+		if applied[op.OpID] {
+			continue
+		}
+		remotePath := filepath.Join(remote, op.TaskID+fileExt)
+
+		switch op.Op {
+		case syncOpDelete:
+			if err := os.Remove(remotePath); err != nil && !os.IsNotExist(err) {
+				return pushed, err
+			}
+		case syncOpSave:
+			content, err := s.mergeWithRemote(op, remotePath)
+			if err != nil {
+				return pushed, err
+			}
+			if err = s.writeFile(remotePath, content); err != nil {
+				return pushed, err
+			}
+		}
+
+		if err := s.appendFile(appliedPath, []byte(op.OpID+"\n")); err != nil {
+			return pushed, err
+		}
+		applied[op.OpID] = true
+		pushed++
+	}
+	return pushed, nil
+}
+
+// mergeWithRemote returns the content to write for a queued save, merging
+// with the remote copy if it diverged from the last synced base.
+func (s *Store) mergeWithRemote(op syncOp, remotePath string) ([]byte, error) {
+	ours := []byte(op.Content)
+	theirs, err := os.ReadFile(remotePath) //nolint:gosec // G304: remote path is user-configured
+	if os.IsNotExist(err) {
+		return ours, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	basePath := filepath.Join(s.basePath, syncBaseDir, op.TaskID+fileExt)
+	baseContent, err := os.ReadFile(basePath) //nolint:gosec // G304: path is within the store
+	if err == nil && bytes.Equal(baseContent, theirs) {
+		return ours, nil // Remote unchanged since last sync
+	}
+
+	oursTask, err := ParseMarkdown(ours)
+	if err != nil {
+		return nil, err
+	}
+	theirsTask, err := ParseMarkdown(theirs)
+	if err != nil {
+		return ours, nil //nolint:nilerr // An unparseable remote copy is overwritten
+	}
+	baseTask, baseErr := ParseMarkdown(baseContent)
+	if baseErr != nil {
+		baseTask = nil
+	}
+	return SerializeMarkdown(MergeTasks(baseTask, oursTask, theirsTask))
+}
+
+// pull mirrors the remote task files into the store and records them as the
+// new sync base. Local tasks missing from the remote were deleted remotely if
+// the last sync base had them; the rest never reached the remote, so they are
+// kept and queued for the next sync. It returns how many tasks were pulled
+// and how many were queued.
+func (s *Store) pull(remote string) (int, int, error) {
+	entries, err := os.ReadDir(remote)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	baseDir := filepath.Join(s.basePath, syncBaseDir)
+	synced, err := syncedIDs(baseDir)
+	if err != nil {
+		return 0, 0, err
+	}
+	if err = os.RemoveAll(baseDir); err != nil {
+		return 0, 0, err
+	}
+	if err = s.mkdirAll(baseDir); err != nil {
+		return 0, 0, err
+	}
+
+	pulled := 0
+	remoteIDs := make(map[string]bool)
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), fileExt) {
+			continue
+		}
+		id := strings.TrimSuffix(entry.Name(), fileExt)
+		remoteIDs[id] = true
+
+		var content []byte
+		content, err = os.ReadFile(filepath.Join(remote, entry.Name())) //nolint:gosec // G304: remote path is user-configured
+		if err != nil {
+			return pulled, 0, err
+		}
+		if err = s.writeFile(filepath.Join(baseDir, entry.Name()), content); err != nil {
+			return pulled, 0, err
+		}
+
+		local, readErr := os.ReadFile(s.taskPath(id))
+		if readErr == nil && bytes.Equal(local, content) {
+			continue
+		}
+		status := task.StatusOpen
+		if t, parseErr := ParseMarkdown(content); parseErr == nil {
+			status = t.Status
+		}
+		if err = s.writeTask(id, status, content); err != nil {
+			return pulled, 0, err
+		}
+		pulled++
+	}
+
+	localIDs, err := s.AllIDs()
+	if err != nil {
+		return pulled, 0, err
+	}
+	queued := 0
+	for id := range localIDs {
+		if remoteIDs[id] {
+			continue
+		}
+		if !synced[id] {
+			var content []byte
+			if content, err = s.fullContent(s.taskPath(id)); err != nil {
+				return pulled, queued, err
+			}
+			if err = s.enqueueSync(syncOpSave, id, content); err != nil {
+				return pulled, queued, err
+			}
+			queued++
+			continue
+		}
+		if err = os.Remove(s.taskPath(id)); err != nil && !os.IsNotExist(err) {
+			return pulled, queued, err
+		}
+		pulled++
+	}
+	return pulled, queued, nil
+}
+
+// syncedIDs returns the IDs of the tasks in the sync base, which the remote
+// had as of the last sync.
+func syncedIDs(baseDir string) (map[string]bool, error) {
+	entries, err := os.ReadDir(baseDir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	ids := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), fileExt) {
+			ids[strings.TrimSuffix(entry.Name(), fileExt)] = true
+		}
+	}
+	return ids, nil
+}
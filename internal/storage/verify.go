@@ -0,0 +1,152 @@
+package storage
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"sort"
+
+	"github.com/abatilo/bits/internal/external"
+	"github.com/abatilo/bits/internal/task"
+)
+
+// IssueKind classifies a problem found by Verify.
+type IssueKind string
+
+const (
+	IssueModified  IssueKind = "modified"  // Content changed outside bits
+	IssueMissing   IssueKind = "missing"   // Indexed task file no longer exists
+	IssueUntracked IssueKind = "untracked" // Task file not recorded in the index
+	IssueCorrupt   IssueKind = "corrupt"   // File cannot be parsed
+	IssueInvalid   IssueKind = "invalid"   // File parses but breaks an invariant
+	IssueOversize  IssueKind = "oversize"  // Content over a configured limit (a doctor warning)
+)
+
+// Issue describes a single integrity problem in the store.
+type Issue struct {
+	ID     string    `json:"id"`
+	File   string    `json:"file"`
+	Kind   IssueKind `json:"kind"`
+	Detail string    `json:"detail"`
+}
+
+// Verify compares every task file against the checksums recorded in the index
+// and checks task invariants, returning the problems found sorted by ID.
+func (s *Store) Verify() ([]Issue, error) {
+	if err := s.EnsureInitialized(); err != nil {
+		return nil, err
+	}
+	idx := s.idx
+	if idx == nil {
+		idx = s.readIndex() // As saved, so changes since are reported rather than absorbed
+	}
+	if idx == nil {
+		var err error
+		if idx, err = s.rebuildIndex(nil); err != nil {
+			return nil, err
+		}
+	}
+	ids, err := s.AllIDs()
+	if err != nil {
+		return nil, err
+	}
+
+	var issues []Issue
+	add := func(id string, kind IssueKind, detail string) {
+		issues = append(issues, Issue{ID: id, File: s.taskPath(id), Kind: kind, Detail: detail})
+	}
+
+	tasks := make(map[string]*task.Task)
+	for id := range ids {
+		content, readErr := os.ReadFile(s.taskPath(id))
+		if readErr != nil {
+			return nil, readErr
+		}
+
+		entry, indexed := idx.Tasks[id]
+		switch {
+		case !indexed || entry.Checksum == "":
+			add(id, IssueUntracked, "file is not recorded in the index")
+		case entry.Checksum != checksum(content):
+			add(id, IssueModified, "content changed outside bits")
+		}
+
+		t, parseErr := ParseMarkdown(content)
+		if parseErr != nil {
+			add(id, IssueCorrupt, parseErr.Error())
+			continue
+		}
+		if bodyErr := s.readBody(t); bodyErr != nil {
+			add(id, IssueCorrupt, "unreadable body_file: "+bodyErr.Error())
+		}
+		tasks[id] = t
+	}
+
+	for id := range idx.Tasks {
+		if !ids[id] {
+			add(id, IssueMissing, "indexed task file no longer exists")
+		}
+	}
+
+	archived, err := s.archivedIDs()
+	if err != nil {
+		return nil, err
+	}
+	for id, t := range tasks {
+		for _, detail := range invariantViolations(id, t, tasks, archived) {
+			add(id, IssueInvalid, detail)
+		}
+	}
+	for id, detail := range s.wip.wipViolations(slices.Collect(maps.Values(tasks))) {
+		add(id, IssueInvalid, detail)
+	}
+
+	sort.SliceStable(issues, func(i, j int) bool {
+		if issues[i].ID != issues[j].ID {
+			return issues[i].ID < issues[j].ID
+		}
+		return issues[i].Kind < issues[j].Kind
+	})
+	return issues, nil
+}
+
+// invariantViolations returns descriptions of the invariants a task breaks.
+// Dependencies on archived tasks are satisfied.
+func invariantViolations(id string, t *task.Task, tasks map[string]*task.Task, archived map[string]bool) []string {
+	var violations []string
+	if t.ID != id {
+		violations = append(violations, fmt.Sprintf("id %q does not match file name", t.ID))
+	}
+	if !task.IsValidStatus(t.Status) {
+		violations = append(violations, fmt.Sprintf("invalid status %q", t.Status))
+	}
+	if !task.IsValidPriority(t.Priority) {
+		violations = append(violations, fmt.Sprintf("invalid priority %q", t.Priority))
+	}
+	if t.Status == task.StatusClosed && t.ClosedAt == nil {
+		violations = append(violations, "closed without closed_at")
+	}
+	if t.Status != task.StatusClosed && t.ClosedAt != nil {
+		violations = append(violations, "closed_at set on unclosed task")
+	}
+	for _, depID := range t.Dependencies() {
+		if depID == id {
+			violations = append(violations, "depends on itself")
+		} else if tasks[depID] == nil && !archived[depID] && !external.IsRef(depID) {
+			violations = append(violations, fmt.Sprintf("depends on missing task %s", depID))
+		}
+	}
+	if len(slices.Compact(slices.Sorted(slices.Values(t.DependsOn)))) != len(t.DependsOn) {
+		violations = append(violations, "duplicate dependency")
+	}
+	for _, l := range t.Links {
+		switch {
+		case !task.IsValidLinkType(l.Type) || l.Type.Blocking(): // Blocking links belong in depends_on
+			violations = append(violations, fmt.Sprintf("invalid link type %q", l.Type))
+		case tasks[l.ID] == nil && !archived[l.ID]:
+			violations = append(violations, fmt.Sprintf("links to missing task %s", l.ID))
+		}
+	}
+	return violations
+}
@@ -0,0 +1,103 @@
+package storage
+
+import (
+	"fmt"
+
+	"github.com/abatilo/bits/internal/task"
+)
+
+// WIPLimits bounds how many tasks may be active at once; zero means no limit.
+// A claim by an agent (see task.IsAgentActor) counts the tasks active under
+// that agent against PerAgent, so several agents can work side by side. Any
+// other claim counts every active task against Total.
+type WIPLimits struct {
+	Total    int
+	PerAgent int
+}
+
+// DefaultWIPLimits allows one active task, and one per agent.
+func DefaultWIPLimits() WIPLimits {
+	return WIPLimits{Total: 1, PerAgent: 1}
+}
+
+// SetWIPLimits sets the limits claims and Verify enforce.
+func (s *Store) SetWIPLimits(limits WIPLimits) {
+	s.wip = limits
+}
+
+// WIPLimits returns the limits claims and Verify enforce.
+func (s *Store) WIPLimits() WIPLimits {
+	return s.wip
+}
+
+// For returns the limit a claim by actor counts against.
+func (l WIPLimits) For(actor string) int {
+	if task.IsAgentActor(actor) {
+		return l.PerAgent
+	}
+	return l.Total
+}
+
+// Exceeded reports whether actor claiming another task would break a limit,
+// returning the limit and the active tasks counted against it.
+func (l WIPLimits) Exceeded(tasks []*task.Task, actor string) (int, []*task.Task, bool) {
+	limit, counted := l.For(actor), activeTasks(tasks, "")
+	if task.IsAgentActor(actor) {
+		counted = activeTasks(tasks, actor)
+	}
+	return limit, counted, limit > 0 && len(counted) >= limit
+}
+
+// NextTask returns the first of the ready tasks actor may take: one not
+// queued on another actor by an assignment. It returns nil if there's none.
+func NextTask(ready []*task.Task, actor string) *task.Task {
+	for _, t := range ready {
+		if t.Assignee == "" || t.Assignee == actor {
+			return t
+		}
+	}
+	return nil
+}
+
+// activeTasks returns the active tasks, only those held by holder if set.
+func activeTasks(tasks []*task.Task, holder string) []*task.Task {
+	var active []*task.Task
+	for _, t := range tasks {
+		if t.Status == task.StatusActive && (holder == "" || t.Holder() == holder) {
+			active = append(active, t)
+		}
+	}
+	return active
+}
+
+// wipViolations describes the active tasks a store breaks its limits with:
+// those of agents holding more than PerAgent, and those claimed without an
+// agent identity when there are more than Total of them.
+func (l WIPLimits) wipViolations(tasks []*task.Task) map[string]string {
+	byHolder := make(map[string][]*task.Task)
+	for _, t := range activeTasks(tasks, "") {
+		holder := t.Holder()
+		if !task.IsAgentActor(holder) {
+			holder = ""
+		}
+		byHolder[holder] = append(byHolder[holder], t)
+	}
+
+	violations := make(map[string]string)
+	for holder, held := range byHolder {
+		var detail string
+		switch {
+		case holder == "" && l.Total > 0 && len(held) > l.Total:
+			detail = fmt.Sprintf("one of %d active tasks (WIP limit %d)", len(held), l.Total)
+		case holder != "" && l.PerAgent > 0 && len(held) > l.PerAgent:
+			detail = fmt.Sprintf("one of %d tasks active under %s (per-agent WIP limit %d)",
+				len(held), holder, l.PerAgent)
+		default:
+			continue
+		}
+		for _, t := range held {
+			violations[t.ID] = detail
+		}
+	}
+	return violations
+}
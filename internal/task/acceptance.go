@@ -0,0 +1,33 @@
+package task
+
+// Criterion is one acceptance criterion, checked off with 'bits accept'.
+type Criterion struct {
+	Text string `yaml:"text"`
+	Done bool   `yaml:"done,omitempty"`
+}
+
+// AddCriterion appends an unchecked acceptance criterion.
+func (t *Task) AddCriterion(text string) {
+	t.Acceptance = append(t.Acceptance, Criterion{Text: text})
+}
+
+// SetCriterion marks the nth (1-based) acceptance criterion done or not done.
+// It returns false if there is no such criterion.
+func (t *Task) SetCriterion(n int, done bool) bool {
+	if n < 1 || n > len(t.Acceptance) {
+		return false
+	}
+	t.Acceptance[n-1].Done = done
+	return true
+}
+
+// UncheckedCriteria returns the 1-based numbers of criteria not yet done.
+func (t *Task) UncheckedCriteria() []int {
+	var unchecked []int
+	for i, c := range t.Acceptance {
+		if !c.Done {
+			unchecked = append(unchecked, i+1)
+		}
+	}
+	return unchecked
+}
@@ -0,0 +1,178 @@
+package task
+
+import (
+	"slices"
+	"strings"
+	"time"
+)
+
+// EventAction identifies a lifecycle transition recorded in a task's history.
+type EventAction string
+
+const (
+	EventClaim   EventAction = "claim"
+	EventRelease EventAction = "release"
+	EventClose   EventAction = "close"
+)
+
+// UserActorPrefix marks an actor that fell back to the OS user because no
+// agent identity (--actor, $BITS_ACTOR, or a session) was given.
+const UserActorPrefix = "user:"
+
+// IsAgentActor reports whether actor identifies an agent or worker rather
+// than an anonymous OS user.
+func IsAgentActor(actor string) bool {
+	return actor != "" && !strings.HasPrefix(actor, UserActorPrefix)
+}
+
+// Event records who moved a task through its lifecycle and when.
+type Event struct {
+	At     time.Time   `yaml:"at"`
+	Action EventAction `yaml:"action"`
+	Actor  string      `yaml:"actor,omitempty"`
+	Note   string      `yaml:"note,omitempty"` // Extra context, e.g. constraints a claim overrode
+}
+
+// Claim marks the task active and records the claim.
+func (t *Task) Claim(now time.Time, actor string) {
+	t.Status = StatusActive
+	t.ClaimedAt = &now
+	t.record(now, EventClaim, actor)
+}
+
+// ForceClaim claims the task despite failed checks, recording which
+// constraints were overridden on the claim event.
+func (t *Task) ForceClaim(now time.Time, actor, overridden string) {
+	t.Claim(now, actor)
+	t.Annotate("override: " + overridden)
+}
+
+// Annotate sets the note on the most recent history event.
+func (t *Task) Annotate(note string) {
+	if len(t.History) > 0 {
+		t.History[len(t.History)-1].Note = note
+	}
+}
+
+// Release returns the task to open and records the release.
+func (t *Task) Release(now time.Time, actor string) {
+	t.Status = StatusOpen
+	t.ClaimedAt = nil
+	t.record(now, EventRelease, actor)
+}
+
+// Close marks the task closed with a reason and records the close.
+func (t *Task) Close(now time.Time, reason, actor string) {
+	t.Status = StatusClosed
+	t.ClosedAt = &now
+	t.CloseReason = &reason
+	t.record(now, EventClose, actor)
+}
+
+// record appends an event, at second precision like the other timestamps.
+func (t *Task) record(at time.Time, action EventAction, actor string) {
+	t.History = append(t.History, Event{At: at.Truncate(time.Second), Action: action, Actor: actor})
+}
+
+// Holder returns the actor of the claim that made the task active, or "" if
+// it isn't active.
+func (t *Task) Holder() string {
+	if t.Status != StatusActive {
+		return ""
+	}
+	if claim := t.LastEvent(EventClaim); claim != nil {
+		return claim.Actor
+	}
+	return ""
+}
+
+// LastEvent returns the most recent event with the given action, or nil.
+func (t *Task) LastEvent(action EventAction) *Event {
+	for i := len(t.History) - 1; i >= 0; i-- {
+		if t.History[i].Action == action {
+			return &t.History[i]
+		}
+	}
+	return nil
+}
+
+// FirstEvent returns the earliest event with the given action, or nil.
+func (t *Task) FirstEvent(action EventAction) *Event {
+	for i := range t.History {
+		if t.History[i].Action == action {
+			return &t.History[i]
+		}
+	}
+	return nil
+}
+
+// LeadTime returns how long a closed task took from creation to close.
+func (t *Task) LeadTime() (time.Duration, bool) {
+	if t.Status != StatusClosed || t.ClosedAt == nil {
+		return 0, false
+	}
+	return max(t.ClosedAt.Sub(t.CreatedAt), 0), true
+}
+
+// CycleTime returns how long a closed task took from its first claim to
+// close, reporting false for tasks closed without being claimed.
+func (t *Task) CycleTime() (time.Duration, bool) {
+	if t.Status != StatusClosed || t.ClosedAt == nil {
+		return 0, false
+	}
+	start := t.ClaimedAt
+	if claim := t.FirstEvent(EventClaim); claim != nil {
+		start = &claim.At
+	}
+	if start == nil {
+		return 0, false
+	}
+	return max(t.ClosedAt.Sub(*start), 0), true
+}
+
+// Interval is a span of time a task was active under one claim.
+type Interval struct {
+	Actor string
+	Start time.Time
+	End   time.Time
+}
+
+// ActiveIntervals pairs each claim in the history with the release or close
+// that ended it. A claim still open at the end of the history runs until now.
+func (t *Task) ActiveIntervals(now time.Time) []Interval {
+	var intervals []Interval
+	var open *Event
+	for i := range t.History {
+		e := &t.History[i]
+		switch e.Action {
+		case EventClaim:
+			open = e
+		case EventRelease, EventClose:
+			if open != nil {
+				intervals = append(intervals, Interval{Actor: open.Actor, Start: open.At, End: e.At})
+				open = nil
+			}
+		}
+	}
+	if open != nil && t.Status == StatusActive {
+		intervals = append(intervals, Interval{Actor: open.Actor, Start: open.At, End: now})
+	}
+	return intervals
+}
+
+// MergeHistory returns the union of two histories in time order, dropping
+// events present in both.
+func MergeHistory(a, b []Event) []Event {
+	merged := slices.Clone(a)
+	for _, e := range b {
+		if !slices.ContainsFunc(merged, func(m Event) bool {
+			return m.At.Equal(e.At) && m.Action == e.Action && m.Actor == e.Actor
+		}) {
+			merged = append(merged, e)
+		}
+	}
+	slices.SortStableFunc(merged, func(x, y Event) int {
+		return x.At.Compare(y.At)
+	})
+	return merged
+}
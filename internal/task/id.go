@@ -9,16 +9,26 @@ import (
 	"time"
 )
 
+// Bounds on the length of generated task IDs.
+const (
+	MinIDLength = 3
+	MaxIDLength = 8
+)
+
 const (
-	minIDLength  = 3
-	maxIDLength  = 8
 	nonceSize    = 16 // 128 bits of entropy
 	hexChunkSize = 4  // Process 4 hex chars (16 bits) at a time for base36 conversion
 )
 
 // GenerateID creates a unique task ID using hash-based generation with adaptive length.
-// It starts with minIDLength characters and grows up to maxIDLength to avoid collisions.
-func GenerateID(title string, createdAt time.Time, existsFn func(string) bool) string {
+// It starts with minLength characters (MinIDLength when 0) and grows up to
+// MaxIDLength to avoid collisions.
+func GenerateID(title string, createdAt time.Time, minLength int, existsFn func(string) bool) string {
+	if minLength <= 0 {
+		minLength = MinIDLength
+	}
+	minLength = min(minLength, MaxIDLength)
+
 	// Generate random nonce
 	nonce := make([]byte, nonceSize)
 	if _, err := rand.Read(nonce); err != nil {
@@ -36,7 +46,7 @@ func GenerateID(title string, createdAt time.Time, existsFn func(string) bool) s
 	base36 := hexToBase36(hex.EncodeToString(hash))
 
 	// Try progressively longer prefixes until we find a unique one
-	for length := minIDLength; length <= maxIDLength; length++ {
+	for length := minLength; length <= MaxIDLength; length++ {
 		if length > len(base36) {
 			break
 		}
@@ -47,7 +57,49 @@ func GenerateID(title string, createdAt time.Time, existsFn func(string) bool) s
 	}
 
 	// Fallback: use full hash (extremely unlikely to reach here)
-	return base36[:maxIDLength]
+	return base36[:MaxIDLength]
+}
+
+// IsValidID reports whether id has the shape of a generated task ID: up to
+// MaxIDLength lowercase letters and digits.
+func IsValidID(id string) bool {
+	if id == "" || len(id) > MaxIDLength {
+		return false
+	}
+	for _, r := range id {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// RemapIDs rewrites t's references to other tasks, its dependencies, hints,
+// links, and parent, through ids. References not in ids are left alone.
+func (t *Task) RemapIDs(ids map[string]string) {
+	remap := func(id string) string {
+		if to, ok := ids[id]; ok {
+			return to
+		}
+		return id
+	}
+	for i, id := range t.DependsOn {
+		t.DependsOn[i] = remap(id)
+	}
+	for _, group := range t.DependsAny {
+		for i, id := range group {
+			group[i] = remap(id)
+		}
+	}
+	for i := range t.After {
+		t.After[i].ID = remap(t.After[i].ID)
+	}
+	for i := range t.Links {
+		t.Links[i].ID = remap(t.Links[i].ID)
+	}
+	if t.Parent != "" {
+		t.Parent = remap(t.Parent)
+	}
 }
 
 // hexToBase36 converts a hex string to base36.
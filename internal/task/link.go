@@ -0,0 +1,61 @@
+package task
+
+import "slices"
+
+// LinkType names how one task relates to another.
+type LinkType string
+
+const (
+	LinkDependsOn  LinkType = "depends-on" // The task can't start until the other closes
+	LinkBlocks     LinkType = "blocks"     // The other task can't start until this one closes
+	LinkRelatesTo  LinkType = "relates-to"
+	LinkDuplicates LinkType = "duplicates"
+)
+
+// IsValidLinkType checks if a link type string is valid.
+func IsValidLinkType(l LinkType) bool {
+	switch l {
+	case LinkDependsOn, LinkBlocks, LinkRelatesTo, LinkDuplicates:
+		return true
+	default:
+		return false
+	}
+}
+
+// Blocking reports whether links of this type hold a task back. Blocking
+// links are stored as dependencies; the others are kept in Links and never
+// affect readiness.
+func (l LinkType) Blocking() bool {
+	return l == LinkDependsOn || l == LinkBlocks
+}
+
+// Link is a non-blocking, typed edge to another task.
+type Link struct {
+	ID   string   `yaml:"id"`
+	Type LinkType `yaml:"type"`
+}
+
+// AddLinks adds a link of the given type to each of ids, reporting whether
+// the task changed.
+func (t *Task) AddLinks(ids []string, l LinkType) bool {
+	changed := false
+	for _, id := range ids {
+		link := Link{ID: id, Type: l}
+		if !slices.Contains(t.Links, link) {
+			t.Links = append(t.Links, link)
+			changed = true
+		}
+	}
+	return changed
+}
+
+// LinksOf returns the IDs the task links to with the given type, in order.
+func (t *Task) LinksOf(l LinkType) []string {
+	var ids []string
+	for _, link := range t.Links {
+		if link.Type == l {
+			ids = append(ids, link.ID)
+		}
+	}
+	return ids
+}
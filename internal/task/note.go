@@ -0,0 +1,35 @@
+package task
+
+import (
+	"slices"
+	"time"
+)
+
+// Note is a timestamped entry in a task's notes, such as a progress update.
+type Note struct {
+	At    time.Time
+	Actor string // Empty when no actor was known
+	Text  string
+}
+
+// AddNote appends a note, recorded at the given time by actor.
+func (t *Task) AddNote(at time.Time, actor, text string) {
+	t.Notes = append(t.Notes, Note{At: at.Truncate(time.Second), Actor: actor, Text: text})
+}
+
+// MergeNotes combines the notes of two versions of a task, dropping
+// duplicates and ordering by time.
+func MergeNotes(a, b []Note) []Note {
+	merged := slices.Clone(a)
+	for _, n := range b {
+		if !slices.ContainsFunc(merged, func(m Note) bool {
+			return m.At.Equal(n.At) && m.Actor == n.Actor && m.Text == n.Text
+		}) {
+			merged = append(merged, n)
+		}
+	}
+	slices.SortStableFunc(merged, func(x, y Note) int {
+		return x.At.Compare(y.At)
+	})
+	return merged
+}
@@ -0,0 +1,34 @@
+package task
+
+import (
+	"sort"
+	"time"
+)
+
+// Reminder is a scheduled nudge about a task, emitted by 'bits remind fire'.
+type Reminder struct {
+	At   time.Time `yaml:"at"`
+	Note string    `yaml:"note,omitempty"`
+}
+
+// AddReminder schedules a reminder, keeping reminders ordered by time.
+func (t *Task) AddReminder(at time.Time, note string) {
+	t.Reminders = append(t.Reminders, Reminder{At: at.Truncate(time.Second), Note: note})
+	sort.SliceStable(t.Reminders, func(i, j int) bool {
+		return t.Reminders[i].At.Before(t.Reminders[j].At)
+	})
+}
+
+// TakeDueReminders removes and returns the reminders scheduled at or before now.
+func (t *Task) TakeDueReminders(now time.Time) []Reminder {
+	var due, pending []Reminder
+	for _, r := range t.Reminders {
+		if r.At.After(now) {
+			pending = append(pending, r)
+		} else {
+			due = append(due, r)
+		}
+	}
+	t.Reminders = pending
+	return due
+}
@@ -0,0 +1,115 @@
+package task
+
+import (
+	"strings"
+	"unicode"
+)
+
+// similarTitleThreshold is the word overlap at which two titles count as
+// near-duplicates: "Fix login bug" and "Fix the login bug" score 0.86.
+const similarTitleThreshold = 0.8
+
+// SimilarTitles reports whether two titles use nearly the same words,
+// ignoring case, punctuation, and word order.
+func SimilarTitles(a, b string) bool {
+	return TitleSimilarity(a, b) >= similarTitleThreshold
+}
+
+// TitleSimilarity scores the word overlap of two titles from 0 (no words in
+// common) to 1 (the same words), as the Dice coefficient of their word sets.
+func TitleSimilarity(a, b string) float64 {
+	wa, wb := titleWords(a), titleWords(b)
+	if len(wa) == 0 || len(wb) == 0 {
+		return 0
+	}
+	shared := 0
+	for w := range wa {
+		if wb[w] {
+			shared++
+		}
+	}
+	return float64(2*shared) / float64(len(wa)+len(wb)) //nolint:mnd // Dice coefficient
+}
+
+// titleWords returns the set of lowercased words in a title.
+func titleWords(title string) map[string]bool {
+	words := make(map[string]bool)
+	for _, w := range strings.FieldsFunc(strings.ToLower(title), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		words[w] = true
+	}
+	return words
+}
+
+// Fuzzy match scoring: each query rune found earns a point, with bonuses for
+// runs of consecutive matches and matches at the start of a word, and a small
+// penalty for every skipped rune.
+const (
+	fuzzyConsecutiveBonus = 5
+	fuzzyWordStartBonus   = 3
+	fuzzyGapPenalty       = 1
+	fuzzyMaxGapPenalty    = 5
+)
+
+// FuzzyScore matches query against text the way file finders do: every word
+// of the query must appear in text as a case-insensitive subsequence, so "lgn
+// bug" matches "Fix the login bug". It returns the match's score, higher for
+// tighter matches, and false if text doesn't match.
+func FuzzyScore(query, text string) (int, bool) {
+	words := strings.Fields(strings.ToLower(query))
+	if len(words) == 0 {
+		return 0, false
+	}
+	runes := []rune(strings.ToLower(text))
+	total := 0
+	for _, w := range words {
+		score, ok := fuzzyWord([]rune(w), runes)
+		if !ok {
+			return 0, false
+		}
+		total += score
+	}
+	return total, true
+}
+
+// fuzzyWord scores the best match of one query word, trying each place its
+// first rune occurs so "bug" in "build a bug fix" prefers the whole word.
+func fuzzyWord(word, text []rune) (int, bool) {
+	best, found := 0, false
+	for start, r := range text {
+		if r != word[0] {
+			continue
+		}
+		if score, ok := fuzzyFrom(word, text, start); ok && (!found || score > best) {
+			best, found = score, true
+		}
+	}
+	return best, found
+}
+
+// fuzzyFrom greedily matches word against text with its first rune at start.
+func fuzzyFrom(word, text []rune, start int) (int, bool) {
+	score, prev := 0, -1
+	pos := start
+	for _, r := range word {
+		for pos < len(text) && text[pos] != r {
+			pos++
+		}
+		if pos == len(text) {
+			return 0, false
+		}
+		score++
+		if prev >= 0 && pos == prev+1 {
+			score += fuzzyConsecutiveBonus
+		} else if prev >= 0 {
+			score -= min(pos-prev-1, fuzzyMaxGapPenalty) * fuzzyGapPenalty
+		}
+		if pos == 0 || !unicode.IsLetter(text[pos-1]) && !unicode.IsDigit(text[pos-1]) {
+			score += fuzzyWordStartBonus
+		}
+		prev = pos
+		pos++
+	}
+	return score, true
+}
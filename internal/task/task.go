@@ -1,6 +1,12 @@
 package task
 
-import "time"
+import (
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
 
 // Status represents the current state of a task.
 type Status string
@@ -48,15 +54,41 @@ func PriorityOrder(p Priority) int {
 
 // Task represents a tracked work item.
 type Task struct {
-	ID          string     `yaml:"id"`
-	Title       string     `yaml:"title"`
-	Status      Status     `yaml:"status"`
-	Priority    Priority   `yaml:"priority"`
-	CreatedAt   time.Time  `yaml:"created_at"`
-	ClosedAt    *time.Time `yaml:"closed_at,omitempty"`
-	CloseReason *string    `yaml:"close_reason,omitempty"`
-	DependsOn   []string   `yaml:"depends_on,omitempty"`
-	Description string     `yaml:"-"` // Stored as markdown body, not frontmatter
+	ID          string        `yaml:"id"`
+	Title       string        `yaml:"title"`
+	Status      Status        `yaml:"status"`
+	Priority    Priority      `yaml:"priority"`
+	CreatedAt   time.Time     `yaml:"created_at"`
+	UpdatedAt   *time.Time    `yaml:"updated_at,omitempty"` // Last saved by a change to the task
+	ClaimedAt   *time.Time    `yaml:"claimed_at,omitempty"`
+	ClosedAt    *time.Time    `yaml:"closed_at,omitempty"`
+	CloseReason *string       `yaml:"close_reason,omitempty"`
+	DueAt       *time.Time    `yaml:"due_at,omitempty"`
+	Estimate    time.Duration `yaml:"estimate,omitempty"`     // Expected working time, zero if unestimated
+	SLABreached bool          `yaml:"sla_breached,omitempty"` // A breach of the SLA target was notified
+	DependsOn   []string      `yaml:"depends_on,omitempty"`
+	DependsAny  [][]string    `yaml:"depends_on_any,omitempty"` // Groups satisfied when any member is closed
+	After       []Hint        `yaml:"after,omitempty"`          // Ordering-only hints; never block
+	Links       []Link        `yaml:"links,omitempty"`          // Typed links that never block, like relates-to
+	Parent      string        `yaml:"parent,omitempty"`         // Task this one is a subtask of
+	Children    []string      `yaml:"-"`                        // Subtasks, filled in from their parents for display
+	Verify      string        `yaml:"verify,omitempty"`         // Shell command that must pass to close
+	Acceptance  []Criterion   `yaml:"acceptance,omitempty"`     // Criteria to check off before closing
+	OnClaim     string        `yaml:"on_claim,omitempty"`       // Shell command run after the task is claimed
+	OnClose     string        `yaml:"on_close,omitempty"`       // Shell command run after the task is closed
+	Tags        []string      `yaml:"tags,omitempty"`
+	Commits     []string      `yaml:"commits,omitempty"` // SHAs of commits linked via trailer
+	Branch      string        `yaml:"branch,omitempty"`
+	Key         string        `yaml:"key,omitempty"`         // Plan name the task is managed under by bits apply
+	Source      Source        `yaml:"source,omitempty"`      // Code comment the task tracks, set by bits scan
+	Assignee    string        `yaml:"assignee,omitempty"`    // Agent or person queued to work on the task
+	LinearID    string        `yaml:"linear_id,omitempty"`   // Linear issue the task mirrors, set by bits sync linear
+	Attachments []string      `yaml:"attachments,omitempty"` // File names in the task's attachments directory
+	History     []Event       `yaml:"history,omitempty"`     // Lifecycle transitions, oldest first
+	Reminders   []Reminder    `yaml:"reminders,omitempty"`
+	BodyFile    string        `yaml:"body_file,omitempty"` // Sidecar with an oversized description, until read in
+	Description string        `yaml:"-"`                   // Stored as markdown body, not frontmatter
+	Notes       []Note        `yaml:"-"`                   // Stored as a section after the description
 }
 
 // IsValidStatus checks if a status string is valid.
@@ -88,3 +120,201 @@ func FindActive(tasks []*Task) *Task {
 	}
 	return nil
 }
+
+// Source is the code comment a task was created from by 'bits scan'.
+type Source struct {
+	File string `yaml:"file"`           // Relative to the project root, with forward slashes
+	Line int    `yaml:"line"`           // 1-based
+	Text string `yaml:"text,omitempty"` // The comment after its marker, which identifies it across scans
+}
+
+// String formats the source as file:line.
+func (s Source) String() string {
+	return s.File + ":" + strconv.Itoa(s.Line)
+}
+
+// Hint is an ordering-only edge: the task should preferably be worked on
+// after the task ID. A higher weight is a stronger preference.
+type Hint struct {
+	ID     string `yaml:"id"`
+	Weight int    `yaml:"weight,omitempty"` // Zero means 1
+}
+
+// EffectiveWeight returns the hint's weight, treating an unset weight as 1.
+func (h Hint) EffectiveWeight() int {
+	return max(h.Weight, 1)
+}
+
+// Dependencies returns everything the task depends on: its DependsOn entries
+// followed by the members of its any-of groups, without duplicates.
+func (t *Task) Dependencies() []string {
+	deps := slices.Clone(t.DependsOn)
+	for _, group := range t.DependsAny {
+		for _, id := range group {
+			if !slices.Contains(deps, id) {
+				deps = append(deps, id)
+			}
+		}
+	}
+	return deps
+}
+
+// RemoveDependency drops id from DependsOn, every any-of group, the ordering
+// hints, and the typed links, discarding groups left empty. It reports whether anything
+// was removed.
+func (t *Task) RemoveDependency(id string) bool {
+	isID := func(d string) bool { return d == id }
+	changed := slices.Contains(t.DependsOn, id)
+	t.DependsOn = slices.DeleteFunc(t.DependsOn, isID)
+
+	hints := len(t.After)
+	t.After = slices.DeleteFunc(t.After, func(h Hint) bool { return h.ID == id })
+	changed = changed || len(t.After) != hints
+
+	links := len(t.Links)
+	t.Links = slices.DeleteFunc(t.Links, func(l Link) bool { return l.ID == id })
+	changed = changed || len(t.Links) != links
+
+	groups := t.DependsAny[:0]
+	for _, group := range t.DependsAny {
+		if slices.Contains(group, id) {
+			changed = true
+			group = slices.DeleteFunc(group, isID)
+		}
+		if len(group) > 0 {
+			groups = append(groups, group)
+		}
+	}
+	t.DependsAny = groups
+	if len(t.DependsAny) == 0 {
+		t.DependsAny = nil
+	}
+	return changed
+}
+
+// IsOverdue reports whether an unfinished task is past its due date.
+func (t *Task) IsOverdue(now time.Time) bool {
+	return t.Status != StatusClosed && t.DueAt != nil && t.DueAt.Before(now)
+}
+
+// BreachesSLA reports whether a task missed a target time to close: it was
+// closed, or is still unfinished at now, more than target after creation.
+// A zero target never breaches.
+func (t *Task) BreachesSLA(target time.Duration, now time.Time) bool {
+	if target <= 0 {
+		return false
+	}
+	end := now
+	if t.Status == StatusClosed && t.ClosedAt != nil {
+		end = *t.ClosedAt
+	}
+	return end.Sub(t.CreatedAt) > target
+}
+
+// Escalate raises an overdue task's priority to at least target, reporting
+// whether it changed.
+func (t *Task) Escalate(now time.Time, target Priority) bool {
+	if !t.IsOverdue(now) || PriorityOrder(t.Priority) <= PriorityOrder(target) {
+		return false
+	}
+	t.Priority = target
+	return true
+}
+
+// Scope returns the lower-cased scope a title names in conventional-commit
+// style: "ui" for both "fix(ui): ..." and "ui: ...". It is empty when the
+// title has none.
+func (t *Task) Scope() string {
+	head, _, found := strings.Cut(t.Title, ":")
+	if !found {
+		return ""
+	}
+	if open := strings.Index(head, "("); open >= 0 && strings.HasSuffix(head, ")") {
+		head = head[open+1 : len(head)-1]
+	}
+	head = strings.TrimSpace(head)
+	if head == "" || strings.ContainsAny(head, " \t") {
+		return ""
+	}
+	return strings.ToLower(head)
+}
+
+// LastActivity returns when the task last changed: its updated_at, or for a
+// task last saved before bits recorded that, the latest of its creation and
+// history events.
+func (t *Task) LastActivity() time.Time {
+	if t.UpdatedAt != nil {
+		return *t.UpdatedAt
+	}
+	last := t.CreatedAt
+	for _, e := range t.History {
+		if e.At.After(last) {
+			last = e.At
+		}
+	}
+	return last
+}
+
+// IsStale reports whether an unfinished task has seen no activity since cutoff.
+func (t *Task) IsStale(cutoff time.Time) bool {
+	return t.Status != StatusClosed && t.LastActivity().Before(cutoff)
+}
+
+// CountByStatus returns the number of tasks in a slice with the given status.
+func CountByStatus(tasks []*Task, s Status) int {
+	count := 0
+	for _, t := range tasks {
+		if t.Status == s {
+			count++
+		}
+	}
+	return count
+}
+
+const maxSlugLength = 40
+
+// Slug converts a title into a lowercase, dash-separated string suitable for
+// branch names, truncated at a word boundary to at most 40 characters.
+func Slug(title string) string {
+	var sb strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(title) {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			sb.WriteRune(r)
+			dash = false
+		} else if !dash && sb.Len() > 0 {
+			sb.WriteByte('-')
+			dash = true
+		}
+	}
+
+	slug := strings.TrimSuffix(sb.String(), "-")
+	if len(slug) > maxSlugLength {
+		slug = slug[:maxSlugLength]
+		if i := strings.LastIndexByte(slug, '-'); i > 0 {
+			slug = slug[:i]
+		}
+	}
+	return slug
+}
+
+// HasTags reports whether the task has every one of tags, which must be
+// normalized.
+func (t *Task) HasTags(tags []string) bool {
+	for _, tag := range tags {
+		if !slices.Contains(t.Tags, tag) {
+			return false
+		}
+	}
+	return true
+}
+
+// NormalizeTag lowercases a tag and strips a leading '#'. It reports false for
+// tags that are empty or contain whitespace or commas.
+func NormalizeTag(tag string) (string, bool) {
+	tag = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(tag), "#"))
+	if tag == "" || strings.ContainsFunc(tag, func(r rune) bool { return unicode.IsSpace(r) || r == ',' }) {
+		return "", false
+	}
+	return tag, true
+}
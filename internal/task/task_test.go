@@ -2,6 +2,8 @@
 package task
 
 import (
+	"fmt"
+	"slices"
 	"testing"
 	"time"
 )
@@ -129,7 +131,7 @@ func TestGenerateID(t *testing.T) {
 	now := time.Now()
 
 	// Should generate a unique ID
-	id := GenerateID("Test task", now, func(_ string) bool { return false })
+	id := GenerateID("Test task", now, 0, func(_ string) bool { return false })
 	if len(id) < 3 {
 		t.Errorf("ID too short: %s", id)
 	}
@@ -143,12 +145,439 @@ func TestGenerateID(t *testing.T) {
 		return existingIDs[id]
 	}
 
-	id1 := GenerateID("Test", now, existsFn)
+	id1 := GenerateID("Test", now, 0, existsFn)
 	existingIDs[id1] = true
 
 	// Different title should generate different ID
-	id2 := GenerateID("Different", now, existsFn)
+	id2 := GenerateID("Different", now, 0, existsFn)
 	if id1 == id2 {
 		t.Error("Expected different IDs for different titles")
 	}
+
+	if id = GenerateID("Longer", now, 5, existsFn); len(id) != 5 {
+		t.Errorf("GenerateID(minLength 5) = %q, want 5 characters", id)
+	}
+	if id = GenerateID("Capped", now, 20, existsFn); len(id) != MaxIDLength {
+		t.Errorf("GenerateID(minLength 20) = %q, want %d characters", id, MaxIDLength)
+	}
+}
+
+func TestIsValidID(t *testing.T) {
+	for id, want := range map[string]bool{
+		"a1b": true, "abcd1234": true, "": false, "abcd12345": false, "API-1": false, "../x": false,
+	} {
+		if got := IsValidID(id); got != want {
+			t.Errorf("IsValidID(%q) = %v, want %v", id, got, want)
+		}
+	}
+}
+
+func TestRemapIDs(t *testing.T) {
+	tk := &Task{
+		DependsOn:  []string{"a", "b"},
+		DependsAny: [][]string{{"a", "c"}},
+		After:      []Hint{{ID: "a", Weight: 2}},
+		Parent:     "a",
+	}
+	tk.RemapIDs(map[string]string{"a": "x"})
+
+	got := fmt.Sprintf("%v %v %s %s", tk.DependsOn, tk.DependsAny, tk.After[0].ID, tk.Parent)
+	if want := "[x b] [[x c]] x x"; got != want {
+		t.Errorf("after RemapIDs = %s, want %s", got, want)
+	}
+	if tk.After[0].Weight != 2 {
+		t.Errorf("After weight = %d, want 2", tk.After[0].Weight)
+	}
+}
+
+func TestCountByStatus(t *testing.T) {
+	tasks := []*Task{
+		{ID: "t1", Status: StatusOpen},
+		{ID: "t2", Status: StatusActive},
+		{ID: "t3", Status: StatusOpen},
+		{ID: "t4", Status: StatusClosed},
+	}
+
+	tests := []struct {
+		status Status
+		want   int
+	}{
+		{StatusOpen, 2},
+		{StatusActive, 1},
+		{StatusClosed, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.status), func(t *testing.T) {
+			if got := CountByStatus(tasks, tt.status); got != tt.want {
+				t.Errorf("CountByStatus(%q) = %d, want %d", tt.status, got, tt.want)
+			}
+		})
+	}
+
+	if got := CountByStatus(nil, StatusOpen); got != 0 {
+		t.Errorf("CountByStatus(nil) = %d, want 0", got)
+	}
+}
+
+func TestSlug(t *testing.T) {
+	tests := []struct {
+		title string
+		want  string
+	}{
+		{"Fix the login bug", "fix-the-login-bug"},
+		{"  Add --json output (v2)! ", "add-json-output-v2"},
+		{"Café résumé", "caf-r-sum"},
+		{"Refactor the storage layer to stream task files lazily", "refactor-the-storage-layer-to-stream"},
+		{"!!!", ""},
+	}
+
+	for _, tt := range tests {
+		if got := Slug(tt.title); got != tt.want {
+			t.Errorf("Slug(%q) = %q, want %q", tt.title, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeTag(t *testing.T) {
+	tests := []struct {
+		tag  string
+		want string
+		ok   bool
+	}{
+		{"Bug", "bug", true},
+		{" #feature ", "feature", true},
+		{"ui/ux", "ui/ux", true},
+		{"", "", false},
+		{"two words", "", false},
+		{"a,b", "", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := NormalizeTag(tt.tag)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("NormalizeTag(%q) = %q, %v; want %q, %v", tt.tag, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestHasTags(t *testing.T) {
+	tk := &Task{Tags: []string{"backend", "bug"}}
+	tests := []struct {
+		tags []string
+		want bool
+	}{
+		{nil, true},
+		{[]string{"bug"}, true},
+		{[]string{"bug", "backend"}, true},
+		{[]string{"bug", "docs"}, false},
+	}
+
+	for _, tt := range tests {
+		if got := tk.HasTags(tt.tags); got != tt.want {
+			t.Errorf("HasTags(%v) = %v, want %v", tt.tags, got, tt.want)
+		}
+	}
+}
+
+func TestLifecycleHistory(t *testing.T) {
+	start := time.Date(2024, 1, 15, 9, 0, 0, 0, time.UTC)
+	tk := &Task{ID: "abc123", Status: StatusOpen, CreatedAt: start}
+
+	tk.Claim(start.Add(time.Hour), "user:alice")
+	tk.Release(start.Add(2*time.Hour), "user:alice")
+	tk.Claim(start.Add(3*time.Hour), "session:s1")
+
+	if tk.Status != StatusActive || tk.ClaimedAt == nil || !tk.ClaimedAt.Equal(start.Add(3*time.Hour)) {
+		t.Fatalf("after Claim: status=%s claimed_at=%v", tk.Status, tk.ClaimedAt)
+	}
+
+	now := start.Add(4 * time.Hour)
+	intervals := tk.ActiveIntervals(now)
+	if len(intervals) != 2 || intervals[1].Actor != "session:s1" || !intervals[1].End.Equal(now) {
+		t.Errorf("ActiveIntervals = %+v, want an open interval for session:s1 ending now", intervals)
+	}
+
+	tk.Close(start.Add(5*time.Hour), "done", "session:s1")
+	if tk.Status != StatusClosed || *tk.CloseReason != "done" {
+		t.Errorf("after Close: status=%s reason=%v", tk.Status, tk.CloseReason)
+	}
+	if last := tk.LastEvent(EventClaim); last == nil || last.Actor != "session:s1" {
+		t.Errorf("LastEvent(claim) = %+v, want session:s1", last)
+	}
+	intervals = tk.ActiveIntervals(start.Add(10 * time.Hour))
+	if got := intervals[1].End.Sub(intervals[1].Start); got != 2*time.Hour {
+		t.Errorf("closed interval = %v, want 2h", got)
+	}
+}
+
+func TestMergeHistory(t *testing.T) {
+	at := time.Date(2024, 1, 15, 9, 0, 0, 0, time.UTC)
+	shared := Event{At: at, Action: EventClaim, Actor: "a"}
+	ours := []Event{shared, {At: at.Add(2 * time.Hour), Action: EventClose, Actor: "a"}}
+	theirs := []Event{shared, {At: at.Add(time.Hour), Action: EventRelease, Actor: "b"}}
+
+	merged := MergeHistory(ours, theirs)
+	want := []EventAction{EventClaim, EventRelease, EventClose}
+	if len(merged) != len(want) {
+		t.Fatalf("MergeHistory = %+v, want %d events", merged, len(want))
+	}
+	for i, e := range merged {
+		if e.Action != want[i] {
+			t.Errorf("merged[%d] = %s, want %s", i, e.Action, want[i])
+		}
+	}
+}
+
+func TestReminders(t *testing.T) {
+	now := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+	tk := &Task{ID: "abc"}
+	tk.AddReminder(now.Add(time.Hour), "later")
+	tk.AddReminder(now.Add(-time.Minute), "past")
+	tk.AddReminder(now, "now")
+
+	due := tk.TakeDueReminders(now)
+	if len(due) != 2 || due[0].Note != "past" || due[1].Note != "now" {
+		t.Errorf("TakeDueReminders = %+v, want past and now", due)
+	}
+	if len(tk.Reminders) != 1 || tk.Reminders[0].Note != "later" {
+		t.Errorf("Reminders after take = %+v, want only later", tk.Reminders)
+	}
+}
+
+func TestEscalate(t *testing.T) {
+	now := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+	past, future := now.Add(-time.Hour), now.Add(time.Hour)
+
+	tests := []struct {
+		name     string
+		task     Task
+		want     bool
+		priority Priority
+	}{
+		{"overdue low", Task{Status: StatusOpen, Priority: PriorityLow, DueAt: &past}, true, PriorityHigh},
+		{
+			"overdue critical",
+			Task{Status: StatusOpen, Priority: PriorityCritical, DueAt: &past},
+			false,
+			PriorityCritical,
+		},
+		{"not yet due", Task{Status: StatusOpen, Priority: PriorityLow, DueAt: &future}, false, PriorityLow},
+		{"closed", Task{Status: StatusClosed, Priority: PriorityLow, DueAt: &past}, false, PriorityLow},
+		{"no due date", Task{Status: StatusOpen, Priority: PriorityLow}, false, PriorityLow},
+	}
+	for _, tt := range tests {
+		tk := tt.task
+		if got := tk.Escalate(now, PriorityHigh); got != tt.want || tk.Priority != tt.priority {
+			t.Errorf("%s: Escalate = %v, priority %s; want %v, %s", tt.name, got, tk.Priority, tt.want, tt.priority)
+		}
+	}
+}
+
+func TestRemoveDependency(t *testing.T) {
+	tk := &Task{DependsOn: []string{"a", "b"}, DependsAny: [][]string{{"a", "c"}, {"a"}}}
+
+	if !tk.RemoveDependency("a") {
+		t.Fatal("RemoveDependency(a) = false, want true")
+	}
+	if len(tk.DependsOn) != 1 || tk.DependsOn[0] != "b" {
+		t.Errorf("DependsOn = %v, want [b]", tk.DependsOn)
+	}
+	if len(tk.DependsAny) != 1 || len(tk.DependsAny[0]) != 1 || tk.DependsAny[0][0] != "c" {
+		t.Errorf("DependsAny = %v, want [[c]]", tk.DependsAny)
+	}
+	if deps := tk.Dependencies(); len(deps) != 2 {
+		t.Errorf("Dependencies = %v, want [b c]", deps)
+	}
+	if tk.RemoveDependency("missing") {
+		t.Error("RemoveDependency(missing) = true, want false")
+	}
+}
+
+func TestLinks(t *testing.T) {
+	tk := &Task{DependsOn: []string{"a"}}
+
+	if !tk.AddLinks([]string{"b", "c"}, LinkRelatesTo) || !tk.AddLinks([]string{"b"}, LinkDuplicates) {
+		t.Fatal("AddLinks = false, want true")
+	}
+	if tk.AddLinks([]string{"c"}, LinkRelatesTo) {
+		t.Error("AddLinks of an existing link = true, want false")
+	}
+	if got := tk.LinksOf(LinkRelatesTo); len(got) != 2 || got[0] != "b" || got[1] != "c" {
+		t.Errorf("LinksOf(relates-to) = %v, want [b c]", got)
+	}
+	if deps := tk.Dependencies(); len(deps) != 1 {
+		t.Errorf("Dependencies = %v, want only [a]; links never block", deps)
+	}
+	if !tk.RemoveDependency("b") || len(tk.Links) != 1 || tk.Links[0].ID != "c" {
+		t.Errorf("Links after RemoveDependency(b) = %v, want only c", tk.Links)
+	}
+	if LinkRelatesTo.Blocking() || !LinkBlocks.Blocking() || IsValidLinkType("parent-of") {
+		t.Error("link type checks are wrong")
+	}
+}
+
+func TestForceClaim(t *testing.T) {
+	now := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+	tk := &Task{ID: "abc", Status: StatusOpen}
+	tk.ForceClaim(now, "user:alice", "blocked by def")
+
+	if tk.Status != StatusActive {
+		t.Errorf("Status = %s, want active", tk.Status)
+	}
+	claim := tk.LastEvent(EventClaim)
+	if claim == nil || claim.Actor != "user:alice" || claim.Note != "override: blocked by def" {
+		t.Errorf("claim event = %+v, want override note", claim)
+	}
+}
+
+func TestAnnotate(t *testing.T) {
+	now := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+	tk := &Task{ID: "abc", Status: StatusActive}
+	tk.Annotate("ignored")
+	tk.Close(now, "done", "user:alice")
+	tk.Annotate("`go test` passed")
+
+	closed := tk.LastEvent(EventClose)
+	if closed == nil || closed.Note != "`go test` passed" {
+		t.Errorf("close event = %+v, want verify note", closed)
+	}
+}
+
+func TestAcceptanceCriteria(t *testing.T) {
+	tk := &Task{ID: "abc"}
+	tk.AddCriterion("tests pass")
+	tk.AddCriterion("docs updated")
+
+	if !tk.SetCriterion(2, true) {
+		t.Fatal("SetCriterion(2) = false, want true")
+	}
+	if tk.SetCriterion(3, true) || tk.SetCriterion(0, true) {
+		t.Error("SetCriterion accepted an out-of-range criterion")
+	}
+	if got := tk.UncheckedCriteria(); !slices.Equal(got, []int{1}) {
+		t.Errorf("UncheckedCriteria = %v, want [1]", got)
+	}
+}
+
+func TestSimilarTitles(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"Fix the login bug", "fix login bug!", true},
+		{"Add retry to webhooks", "webhooks: add retry", true},
+		{"Fix login bug", "Fix signup bug", false},
+		{"Write docs", "Write tests", false},
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		if got := SimilarTitles(tt.a, tt.b); got != tt.want {
+			score := TitleSimilarity(tt.a, tt.b)
+			t.Errorf("SimilarTitles(%q, %q) = %v (%.2f), want %v", tt.a, tt.b, got, score, tt.want)
+		}
+	}
+}
+
+func TestFuzzyScore(t *testing.T) {
+	if _, ok := FuzzyScore("lgn bug", "Fix the login bug"); !ok {
+		t.Error(`"lgn bug" didn't match "Fix the login bug"`)
+	}
+	if _, ok := FuzzyScore("bug lgn", "Fix the login bug"); !ok {
+		t.Error("query word order mattered")
+	}
+	if _, ok := FuzzyScore("signup", "Fix the login bug"); ok {
+		t.Error(`"signup" matched "Fix the login bug"`)
+	}
+	if _, ok := FuzzyScore("  ", "Anything"); ok {
+		t.Error("an empty query matched")
+	}
+
+	tight, _ := FuzzyScore("api", "Build the API")
+	loose, _ := FuzzyScore("api", "Add pagination")
+	if tight <= loose {
+		t.Errorf("whole-word match scored %d, scattered match %d; want the whole word higher", tight, loose)
+	}
+	start, _ := FuzzyScore("bug", "debug bugs")
+	mid, _ := FuzzyScore("bug", "debug")
+	if start <= mid {
+		t.Errorf("word-start match scored %d, mid-word %d; want word start higher", start, mid)
+	}
+}
+
+func TestLastActivity(t *testing.T) {
+	created := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	tk := &Task{ID: "abc", Status: StatusOpen, CreatedAt: created}
+	if got := tk.LastActivity(); !got.Equal(created) {
+		t.Errorf("LastActivity = %v, want creation time", got)
+	}
+
+	claimed := created.AddDate(0, 0, 5)
+	tk.Claim(claimed, "tester")
+	if got := tk.LastActivity(); !got.Equal(claimed) {
+		t.Errorf("LastActivity without updated_at = %v, want the claim", got)
+	}
+
+	updated := created.AddDate(0, 0, 10)
+	tk.UpdatedAt = &updated
+	if got := tk.LastActivity(); !got.Equal(updated) {
+		t.Errorf("LastActivity = %v, want updated_at", got)
+	}
+	if !tk.IsStale(updated.Add(time.Hour)) || tk.IsStale(updated) {
+		t.Error("IsStale disagrees with updated_at")
+	}
+	tk.Close(updated, "done", "tester")
+	if tk.IsStale(updated.AddDate(1, 0, 0)) {
+		t.Error("closed task reported stale")
+	}
+}
+
+func TestHolder(t *testing.T) {
+	tk := &Task{ID: "abc", Status: StatusOpen}
+	if tk.Holder() != "" {
+		t.Errorf("open task Holder = %q", tk.Holder())
+	}
+	tk.Claim(time.Now().UTC(), "agent-1")
+	if tk.Holder() != "agent-1" || !IsAgentActor(tk.Holder()) {
+		t.Errorf("Holder = %q, want agent agent-1", tk.Holder())
+	}
+	if IsAgentActor(UserActorPrefix+"alice") || IsAgentActor("") {
+		t.Error("an OS user or empty actor counted as an agent")
+	}
+}
+
+func TestScope(t *testing.T) {
+	tests := map[string]string{
+		"fix(UI): broken nav":       "ui",
+		"frontend: add dark mode":   "frontend",
+		"feat( api ): paginate":     "api",
+		"Note to self: rotate keys": "",
+		"Plain title":               "",
+		": empty scope":             "",
+	}
+	for title, want := range tests {
+		if got := (&Task{Title: title}).Scope(); got != want {
+			t.Errorf("Scope(%q) = %q, want %q", title, got, want)
+		}
+	}
+}
+
+func TestBreachesSLA(t *testing.T) {
+	created := time.Date(2024, 1, 15, 9, 0, 0, 0, time.UTC)
+	tk := &Task{ID: "a", Status: StatusOpen, CreatedAt: created}
+
+	if tk.BreachesSLA(24*time.Hour, created.Add(23*time.Hour)) {
+		t.Error("open task breached its SLA before the target")
+	}
+	if !tk.BreachesSLA(24*time.Hour, created.Add(25*time.Hour)) {
+		t.Error("open task past the target did not breach")
+	}
+	if tk.BreachesSLA(0, created.Add(time.Hour*1000)) {
+		t.Error("task breached without a target")
+	}
+
+	tk.Close(created.Add(20*time.Hour), "done", "")
+	if tk.BreachesSLA(24*time.Hour, created.Add(48*time.Hour)) {
+		t.Error("task closed within the target breached later")
+	}
 }
@@ -0,0 +1,13 @@
+package telemetry
+
+import "fmt"
+
+// ExportError indicates the collector rejected an export.
+type ExportError struct {
+	Endpoint string
+	Status   string
+}
+
+func (e ExportError) Error() string {
+	return fmt.Sprintf("exporting spans to %s: %s", e.Endpoint, e.Status)
+}
@@ -0,0 +1,182 @@
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	tracesPath       = "/v1/traces"
+	exportTimeout    = 5 * time.Second
+	spanKindInternal = 1
+	statusCodeError  = 2
+)
+
+// Exporter sends spans to an OTLP/HTTP collector using the JSON encoding.
+type Exporter struct {
+	Endpoint string            // Full traces URL, e.g. http://localhost:4318/v1/traces
+	Headers  map[string]string // Extra request headers, e.g. for authentication
+	Client   *http.Client
+}
+
+// ExporterFromEnv builds an exporter from the standard OpenTelemetry
+// environment variables, falling back to endpoint (a collector base URL such
+// as http://localhost:4318) when they are unset. It reports false if no
+// endpoint is configured.
+func ExporterFromEnv(endpoint string) (*Exporter, bool) {
+	url := os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT")
+	if url == "" {
+		if base := os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT"); base != "" {
+			endpoint = base
+		}
+		if endpoint == "" {
+			return nil, false
+		}
+		url = strings.TrimSuffix(endpoint, "/") + tracesPath
+	}
+
+	headers := make(map[string]string)
+	for _, name := range []string{"OTEL_EXPORTER_OTLP_HEADERS", "OTEL_EXPORTER_OTLP_TRACES_HEADERS"} {
+		for pair := range strings.SplitSeq(os.Getenv(name), ",") {
+			if key, value, ok := strings.Cut(pair, "="); ok {
+				headers[strings.TrimSpace(key)] = strings.TrimSpace(value)
+			}
+		}
+	}
+	return &Exporter{Endpoint: url, Headers: headers, Client: &http.Client{Timeout: exportTimeout}}, true
+}
+
+// Flush exports and forgets the spans t has finished. Nothing is sent if
+// there are none.
+func (e *Exporter) Flush(ctx context.Context, t *Tracer) error {
+	if t == nil {
+		return nil
+	}
+	spans := t.takeFinished()
+	if len(spans) == 0 {
+		return nil
+	}
+
+	body, err := json.Marshal(encodeSpans(t.service, spans))
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range e.Headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := e.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 { //nolint:mnd // any 2xx is success
+		return ExportError{Endpoint: e.Endpoint, Status: resp.Status}
+	}
+	return nil
+}
+
+// The OTLP/JSON request shape, trimmed to what bits sends.
+type (
+	exportRequest struct {
+		ResourceSpans []resourceSpans `json:"resourceSpans"`
+	}
+	resourceSpans struct {
+		Resource   resource     `json:"resource"`
+		ScopeSpans []scopeSpans `json:"scopeSpans"`
+	}
+	resource struct {
+		Attributes []keyValue `json:"attributes"`
+	}
+	scopeSpans struct {
+		Scope scope      `json:"scope"`
+		Spans []spanJSON `json:"spans"`
+	}
+	scope struct {
+		Name string `json:"name"`
+	}
+	spanJSON struct {
+		TraceID      string     `json:"traceId"`
+		SpanID       string     `json:"spanId"`
+		ParentSpanID string     `json:"parentSpanId,omitempty"`
+		Name         string     `json:"name"`
+		Kind         int        `json:"kind"`
+		Start        string     `json:"startTimeUnixNano"`
+		End          string     `json:"endTimeUnixNano"`
+		Attributes   []keyValue `json:"attributes,omitempty"`
+		Status       *status    `json:"status,omitempty"`
+	}
+	status struct {
+		Code    int    `json:"code"`
+		Message string `json:"message,omitempty"`
+	}
+	keyValue struct {
+		Key   string   `json:"key"`
+		Value anyValue `json:"value"`
+	}
+	anyValue struct {
+		String *string  `json:"stringValue,omitempty"`
+		Int    *string  `json:"intValue,omitempty"` // int64 is a string in proto3 JSON
+		Double *float64 `json:"doubleValue,omitempty"`
+	}
+)
+
+// encodeSpans builds the OTLP/JSON export request for spans.
+func encodeSpans(service string, spans []*Span) exportRequest {
+	out := make([]spanJSON, len(spans))
+	for i, s := range spans {
+		out[i] = spanJSON{
+			TraceID:      s.traceID,
+			SpanID:       s.id,
+			ParentSpanID: s.parentID,
+			Name:         s.name,
+			Kind:         spanKindInternal,
+			Start:        strconv.FormatInt(s.start.UnixNano(), 10),
+			End:          strconv.FormatInt(s.end.UnixNano(), 10),
+			Attributes:   encodeAttrs(s.attrs),
+		}
+		if s.err != "" {
+			out[i].Status = &status{Code: statusCodeError, Message: s.err}
+		}
+	}
+	return exportRequest{ResourceSpans: []resourceSpans{{
+		Resource:   resource{Attributes: encodeAttrs([]Attr{String("service.name", service)})},
+		ScopeSpans: []scopeSpans{{Scope: scope{Name: service}, Spans: out}},
+	}}}
+}
+
+// encodeAttrs converts attributes to OTLP key-values, stringifying unknown types.
+func encodeAttrs(attrs []Attr) []keyValue {
+	out := make([]keyValue, 0, len(attrs))
+	for _, a := range attrs {
+		var v anyValue
+		switch value := a.Value.(type) {
+		case string:
+			v.String = &value
+		case int64:
+			s := strconv.FormatInt(value, 10)
+			v.Int = &s
+		case float64:
+			v.Double = &value
+		default:
+			s := ""
+			if b, err := json.Marshal(value); err == nil {
+				s = string(b)
+			}
+			v.String = &s
+		}
+		out = append(out, keyValue{Key: a.Key, Value: v})
+	}
+	return out
+}
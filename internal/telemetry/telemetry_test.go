@@ -0,0 +1,153 @@
+//nolint:testpackage // Tests require internal access for thorough testing
+package telemetry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSpanNesting(t *testing.T) {
+	tracer := NewTracer("bits")
+
+	root := tracer.Start("command")
+	child := tracer.Start("storage.load", String("bits.task.id", "abc"))
+	tracer.Start("storage.parse") // left open; ending root closes it
+	root.End()
+	child.End() // already ended with root
+
+	spans := tracer.takeFinished()
+	if len(spans) != 3 {
+		t.Fatalf("finished %d spans, want 3", len(spans))
+	}
+	byName := make(map[string]*Span)
+	for _, s := range spans {
+		byName[s.name] = s
+		if s.traceID != root.traceID {
+			t.Errorf("span %s has trace %s, want %s", s.name, s.traceID, root.traceID)
+		}
+		if s.end.IsZero() {
+			t.Errorf("span %s was not ended", s.name)
+		}
+	}
+	if byName["command"].parentID != "" {
+		t.Errorf("root span has parent %q", byName["command"].parentID)
+	}
+	if byName["storage.load"].parentID != root.id {
+		t.Errorf("storage.load parent = %q, want %q", byName["storage.load"].parentID, root.id)
+	}
+	if byName["storage.parse"].parentID != child.id {
+		t.Errorf("storage.parse parent = %q, want %q", byName["storage.parse"].parentID, child.id)
+	}
+
+	next := tracer.Start("command")
+	next.End()
+	if next.traceID == root.traceID {
+		t.Error("a span started with none open reused the previous trace")
+	}
+}
+
+func TestNilTracer(t *testing.T) {
+	var tracer *Tracer
+	span := tracer.Start("command")
+	span.SetAttributes(Int("bits.tasks", 1))
+	span.RecordError(errors.New("boom"))
+	span.End()
+
+	if err := (&Exporter{}).Flush(context.Background(), tracer); err != nil {
+		t.Errorf("Flush(nil) = %v, want nil", err)
+	}
+}
+
+func TestFlush(t *testing.T) {
+	var requests []exportRequest
+	var header string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Get("X-Token")
+		var req exportRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decode export request: %v", err)
+		}
+		requests = append(requests, req)
+	}))
+	defer srv.Close()
+
+	exporter := &Exporter{Endpoint: srv.URL, Headers: map[string]string{"X-Token": "secret"}, Client: srv.Client()}
+	tracer := NewTracer("bits")
+	span := tracer.Start("storage.walk", Int("bits.tasks", 3), String("bits.store", "/tmp"))
+	span.RecordError(errors.New("walk failed"))
+	span.End()
+
+	if err := exporter.Flush(context.Background(), tracer); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+	if err := exporter.Flush(context.Background(), tracer); err != nil {
+		t.Fatalf("second Flush failed: %v", err)
+	}
+	if len(requests) != 1 {
+		t.Fatalf("collector got %d requests, want 1 (empty flushes send nothing)", len(requests))
+	}
+	if header != "secret" {
+		t.Errorf("X-Token header = %q, want secret", header)
+	}
+
+	spans := requests[0].ResourceSpans[0].ScopeSpans[0].Spans
+	if len(spans) != 1 || spans[0].Name != "storage.walk" {
+		t.Fatalf("exported spans = %+v, want storage.walk", spans)
+	}
+	got := spans[0]
+	if got.Status == nil || got.Status.Code != statusCodeError || got.Status.Message != "walk failed" {
+		t.Errorf("Status = %+v, want error walk failed", got.Status)
+	}
+	if len(got.Attributes) != 2 || got.Attributes[0].Value.Int == nil || *got.Attributes[0].Value.Int != "3" {
+		t.Errorf("Attributes = %+v, want bits.tasks as intValue 3", got.Attributes)
+	}
+}
+
+func TestFlushRejected(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer srv.Close()
+
+	tracer := NewTracer("bits")
+	tracer.Start("command").End()
+
+	err := (&Exporter{Endpoint: srv.URL, Client: srv.Client()}).Flush(context.Background(), tracer)
+	var exportErr ExportError
+	if !errors.As(err, &exportErr) {
+		t.Errorf("Flush error = %v, want ExportError", err)
+	}
+}
+
+func TestExporterFromEnv(t *testing.T) {
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "")
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "")
+	t.Setenv("OTEL_EXPORTER_OTLP_HEADERS", "authorization=Bearer x, team = infra")
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_HEADERS", "")
+
+	if _, ok := ExporterFromEnv(""); ok {
+		t.Error("ExporterFromEnv enabled tracing with no endpoint")
+	}
+
+	exporter, ok := ExporterFromEnv("http://collector:4318/")
+	if !ok || exporter.Endpoint != "http://collector:4318/v1/traces" {
+		t.Errorf("config endpoint: Endpoint = %+v, want http://collector:4318/v1/traces", exporter)
+	}
+	if exporter.Headers["authorization"] != "Bearer x" || exporter.Headers["team"] != "infra" {
+		t.Errorf("Headers = %v", exporter.Headers)
+	}
+
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "http://env:4318")
+	if exporter, _ = ExporterFromEnv("http://collector:4318"); exporter.Endpoint != "http://env:4318/v1/traces" {
+		t.Errorf("OTEL_EXPORTER_OTLP_ENDPOINT: Endpoint = %q", exporter.Endpoint)
+	}
+
+	t.Setenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "http://traces:4318/custom")
+	if exporter, _ = ExporterFromEnv("http://collector:4318"); exporter.Endpoint != "http://traces:4318/custom" {
+		t.Errorf("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT: Endpoint = %q", exporter.Endpoint)
+	}
+}
@@ -0,0 +1,142 @@
+package telemetry
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+const (
+	traceIDBytes = 16
+	spanIDBytes  = 8
+)
+
+// Attr is a span attribute, built with String, Int, or Duration.
+type Attr struct {
+	Key   string
+	Value any
+}
+
+// String returns a string attribute.
+func String(key, value string) Attr {
+	return Attr{Key: key, Value: value}
+}
+
+// Int returns an integer attribute.
+func Int(key string, value int) Attr {
+	return Attr{Key: key, Value: int64(value)}
+}
+
+// Duration returns a duration attribute in milliseconds.
+func Duration(key string, d time.Duration) Attr {
+	return Attr{Key: key, Value: float64(d) / float64(time.Millisecond)}
+}
+
+// Tracer records spans for export. Spans started while another is open become
+// its children; a span started with none open begins a new trace. A nil
+// *Tracer records nothing, so instrumented code needs no checks.
+type Tracer struct {
+	mu       sync.Mutex
+	service  string
+	traceID  string
+	open     []*Span
+	finished []*Span
+}
+
+// Span is one timed operation.
+type Span struct {
+	tracer   *Tracer
+	traceID  string
+	id       string
+	parentID string
+	name     string
+	start    time.Time
+	end      time.Time
+	attrs    []Attr
+	err      string
+}
+
+// NewTracer creates a tracer whose spans belong to service.
+func NewTracer(service string) *Tracer {
+	return &Tracer{service: service}
+}
+
+// Start opens a span as a child of the innermost open span.
+func (t *Tracer) Start(name string, attrs ...Attr) *Span {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	s := &Span{tracer: t, id: randomID(spanIDBytes), name: name, start: time.Now(), attrs: attrs}
+	if len(t.open) == 0 {
+		t.traceID = randomID(traceIDBytes)
+	} else {
+		s.parentID = t.open[len(t.open)-1].id
+	}
+	s.traceID = t.traceID
+	t.open = append(t.open, s)
+	return s
+}
+
+// SetAttributes adds attributes to the span.
+func (s *Span) SetAttributes(attrs ...Attr) {
+	if s == nil {
+		return
+	}
+	s.tracer.mu.Lock()
+	defer s.tracer.mu.Unlock()
+	s.attrs = append(s.attrs, attrs...)
+}
+
+// RecordError marks the span as failed with err.
+func (s *Span) RecordError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.tracer.mu.Lock()
+	defer s.tracer.mu.Unlock()
+	s.err = err.Error()
+}
+
+// End closes the span, and any of its children still open.
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	t := s.tracer
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !s.end.IsZero() {
+		return
+	}
+
+	now := time.Now()
+	for i := len(t.open) - 1; i >= 0; i-- {
+		open := t.open[i]
+		open.end = now
+		t.finished = append(t.finished, open)
+		t.open = t.open[:i]
+		if open == s {
+			return
+		}
+	}
+}
+
+// takeFinished removes and returns the spans ended so far.
+func (t *Tracer) takeFinished() []*Span {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	spans := t.finished
+	t.finished = nil
+	return spans
+}
+
+// randomID returns n random bytes, hex-encoded.
+func randomID(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b) // crypto/rand.Read never fails
+	return hex.EncodeToString(b)
+}